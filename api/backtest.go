@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"nofx/backtest"
+	"nofx/kernel"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/provider/nofxos"
@@ -37,6 +39,9 @@ func (s *Server) registerBacktestRoutes(router *gin.RouterGroup) {
 	router.GET("/decisions", s.handleBacktestDecisions)
 	router.GET("/export", s.handleBacktestExport)
 	router.GET("/klines", s.handleBacktestKlines)
+	router.GET("/compare", s.handleBacktestCompare)
+	router.GET("/:id/montecarlo", s.handleBacktestMonteCarlo)
+	router.GET("/:id/drift", s.handleBacktestDrift)
 }
 
 type backtestStartRequest struct {
@@ -98,6 +103,17 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 			strategyConfig.CoinSource.UseOITop,
 			strategyConfig.CoinSource.StaticCoins)
 
+		// Strategies that trade their own symbols (DCA, pairs, baskets) ignore the coin source
+		symbols, timeframes := kernel.StrategyMarkets(&strategyConfig)
+		if len(cfg.Symbols) == 0 {
+			cfg.Symbols = symbols
+		}
+		for _, tf := range timeframes {
+			if !slices.Contains(cfg.Timeframes, tf) {
+				cfg.Timeframes = append(cfg.Timeframes, tf)
+			}
+		}
+
 		// If no symbols provided, fetch from strategy's coin source
 		if len(cfg.Symbols) == 0 {
 			symbols, err := s.resolveStrategyCoins(&strategyConfig)
@@ -110,9 +126,31 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 		}
 	}
 
-	if err := s.hydrateBacktestAIConfig(&cfg); err != nil {
-		SafeBadRequest(c, "Failed to configure AI model")
-		return
+	// Live replays execute the trader's recorded decisions instead of calling the AI
+	if cfg.ReplayTraderID != "" {
+		if _, err := s.store.Trader().GetFullConfig(c.GetString("user_id"), cfg.ReplayTraderID); err != nil {
+			SafeBadRequest(c, "Trader not found")
+			return
+		}
+		records, err := s.store.Decision().GetRecordsInRange(cfg.ReplayTraderID, time.Unix(cfg.StartTS, 0).UTC(), time.Unix(cfg.EndTS, 0).UTC())
+		if err != nil {
+			SafeInternalError(c, "Failed to load trader decisions", err)
+			return
+		}
+		if len(records) == 0 {
+			SafeBadRequest(c, "Trader has no recorded decisions in the backtest period")
+			return
+		}
+		cfg.SetLiveDecisions(records)
+		logger.Infof("📊 Backtest replaying %d live decisions of trader %s", len(records), cfg.ReplayTraderID)
+	}
+
+	// Strategies without AI (e.g. rule_based) are backtested without an AI model
+	if cfg.RequiresAI() {
+		if err := s.hydrateBacktestAIConfig(&cfg); err != nil {
+			SafeBadRequest(c, "Failed to configure AI model")
+			return
+		}
 	}
 
 	logger.Infof("📊 Starting backtest with final config: runID=%s, symbols=%v (count=%d), strategyID=%s",
@@ -410,6 +448,110 @@ func (s *Server) handleBacktestMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
+// handleBacktestCompare compares runs side by side (?run_ids=a,b,c, the first is the baseline):
+// equity on a common ?tf timeline, config diffs, metric deltas and diverging decisions
+func (s *Server) handleBacktestCompare(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+
+	var runIDs []string
+	for _, id := range strings.Split(c.Query("run_ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			runIDs = append(runIDs, id)
+		}
+	}
+	if len(runIDs) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run_ids needs at least two runs"})
+		return
+	}
+	for _, runID := range runIDs {
+		if _, err := s.ensureBacktestRunOwnership(runID, userID); writeBacktestAccessError(c, err) {
+			return
+		}
+	}
+
+	comparison, err := s.backtestManager.CompareRuns(runIDs, c.Query("tf"), queryInt(c, "limit", 1000))
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to compare backtests", err)
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+// handleBacktestMonteCarlo resamples the trades of a run (?method=shuffle|bootstrap, ?simulations,
+// ?skip_probability, ?ruin_pct, ?seed) and returns the final return, drawdown and ruin distributions
+func (s *Server) handleBacktestMonteCarlo(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	runID := c.Param("id")
+	if _, err := s.ensureBacktestRunOwnership(runID, userID); writeBacktestAccessError(c, err) {
+		return
+	}
+
+	cfg := backtest.MonteCarloConfig{
+		Simulations: queryInt(c, "simulations", 0),
+		Method:      c.Query("method"),
+		Seed:        int64(queryInt(c, "seed", 0)),
+	}
+	if v, err := strconv.ParseFloat(c.Query("skip_probability"), 64); err == nil {
+		cfg.SkipProbability = v
+	}
+	if v, err := strconv.ParseFloat(c.Query("ruin_pct"), 64); err == nil {
+		cfg.RuinThresholdPct = v
+	}
+	if err := cfg.Normalize(); err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+
+	result, err := s.backtestManager.MonteCarlo(runID, cfg)
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to run Monte Carlo analysis", err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// handleBacktestDrift compares a live replay run with the trades the live trader actually made
+func (s *Server) handleBacktestDrift(c *gin.Context) {
+	if s.backtestManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
+		return
+	}
+	userID := normalizeUserID(c.GetString("user_id"))
+	runID := c.Param("id")
+	if _, err := s.ensureBacktestRunOwnership(runID, userID); writeBacktestAccessError(c, err) {
+		return
+	}
+
+	report, err := s.backtestManager.ExecutionDrift(runID, func(traderID string, start, end time.Time) ([]*store.TraderPosition, error) {
+		if _, err := s.store.Trader().GetFullConfig(c.GetString("user_id"), traderID); err != nil {
+			return nil, fmt.Errorf("trader not found")
+		}
+		// Positions opened in the period may have closed after it
+		positions, err := s.store.Position().GetClosedPositionsInRange(traderID, start, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		open, err := s.store.Position().GetOpenPositions(traderID)
+		if err != nil {
+			return nil, err
+		}
+		return append(positions, open...), nil
+	})
+	if err != nil {
+		SafeError(c, http.StatusBadRequest, "Failed to compute execution drift", err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 func (s *Server) handleBacktestTrace(c *gin.Context) {
 	if s.backtestManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest manager unavailable"})
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"nofx/store"
+	"nofx/trader"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validateFollowConfig validates a copy trading configuration
+func validateFollowConfig(cfg *store.FollowConfig) error {
+	switch cfg.SizingMode {
+	case "", store.FollowSizingEquityRatio, store.FollowSizingMultiplier:
+	default:
+		return fmt.Errorf("sizing_mode must be '%s' or '%s'", store.FollowSizingEquityRatio, store.FollowSizingMultiplier)
+	}
+	if cfg.Multiplier < 0 || cfg.Multiplier > 100 {
+		return fmt.Errorf("multiplier must be between 0 and 100")
+	}
+	if cfg.SizingMode == store.FollowSizingMultiplier && cfg.Multiplier == 0 {
+		return fmt.Errorf("multiplier is required for sizing_mode '%s'", store.FollowSizingMultiplier)
+	}
+	if cfg.MaxPositionUSD < 0 {
+		return fmt.Errorf("max_position_usd cannot be negative")
+	}
+	if cfg.MaxLeverage < 0 || cfg.MaxLeverage > 125 {
+		return fmt.Errorf("max_leverage must be between 0 and 125")
+	}
+	if cfg.MaxMarginUsage < 0 || cfg.MaxMarginUsage > 1 {
+		return fmt.Errorf("max_margin_usage must be between 0 and 1")
+	}
+	if cfg.MaxDelaySeconds < 0 {
+		return fmt.Errorf("max_delay_seconds cannot be negative")
+	}
+	for from, to := range cfg.SymbolMap {
+		if from == "" || to == "" {
+			return fmt.Errorf("symbol_map entries cannot be empty")
+		}
+	}
+	return nil
+}
+
+// handleUpdateFollow Make a trader follow a leader trader (applies on the next trader start)
+func (s *Server) handleUpdateFollow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	var req struct {
+		LeaderTraderID string             `json:"leader_trader_id" binding:"required"`
+		Config         store.FollowConfig `json:"config"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	follower, err := s.store.Trader().GetFullConfig(userID, traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	if req.LeaderTraderID == traderID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a trader cannot follow itself"})
+		return
+	}
+	leader, err := s.store.Trader().GetFullConfig(userID, req.LeaderTraderID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "leader trader not found"})
+		return
+	}
+	if leader.Trader.LeaderTraderID != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the leader is itself a follower"})
+		return
+	}
+	if leader.Trader.ExchangeID == follower.Trader.ExchangeID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "follower and leader must use different exchange accounts"})
+		return
+	}
+	followers, err := s.store.Trader().ListFollowers(traderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get followers", err)
+		return
+	}
+	if len(followers) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a trader with followers cannot follow another trader"})
+		return
+	}
+	if err := validateFollowConfig(&req.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	configJSON, err := json.Marshal(req.Config)
+	if err != nil {
+		SafeInternalError(c, "Serialize configuration", err)
+		return
+	}
+	if err := s.store.Trader().UpdateFollow(userID, traderID, req.LeaderTraderID, string(configJSON)); err != nil {
+		SafeInternalError(c, "Failed to update follow configuration", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Follow configuration saved, restart the trader to apply"})
+}
+
+// handleDeleteFollow Stop following the leader (applies on the next trader start)
+func (s *Server) handleDeleteFollow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	if err := s.store.Trader().UpdateFollow(userID, traderID, "", ""); err != nil {
+		SafeInternalError(c, "Failed to update follow configuration", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Follow configuration removed, restart the trader to apply"})
+}
+
+// handleGetCopyTrading Get the follow configuration, recent order links and tracking error of a follower
+func (s *Server) handleGetCopyTrading(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	full, err := s.store.Trader().GetFullConfig(userID, traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	cfg, err := full.Trader.ParseFollowConfig()
+	if err != nil {
+		SafeInternalError(c, "Failed to parse follow configuration", err)
+		return
+	}
+	if cfg == nil {
+		c.JSON(http.StatusOK, gin.H{"following": false})
+		return
+	}
+	leaderID := full.Trader.LeaderTraderID
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	links, err := s.store.CopyTrade().GetLinks(traderID, limit)
+	if err != nil {
+		SafeInternalError(c, "Failed to get copy trade links", err)
+		return
+	}
+
+	// Tracking error over the latest equity snapshots of both traders
+	leaderCurve, err := s.store.Equity().GetLatest(leaderID, 500)
+	if err != nil {
+		SafeInternalError(c, "Failed to get leader equity", err)
+		return
+	}
+	followerCurve, err := s.store.Equity().GetLatest(traderID, 500)
+	if err != nil {
+		SafeInternalError(c, "Failed to get follower equity", err)
+		return
+	}
+	leaderPositions, err := s.store.Position().GetOpenPositions(leaderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get leader positions", err)
+		return
+	}
+	followerPositions, err := s.store.Position().GetOpenPositions(traderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get follower positions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following":        true,
+		"leader_trader_id": leaderID,
+		"config":           cfg,
+		"links":            links,
+		"tracking":         trader.CopyTracking(cfg, leaderCurve, followerCurve, leaderPositions, followerPositions),
+	})
+}
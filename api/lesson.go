@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"nofx/store"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// lessonStoreFromQuery resolves the trader from ?trader_id=xxx and returns its lesson store
+func (s *Server) lessonStoreFromQuery(c *gin.Context) (*store.LessonStore, string, bool) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		SafeBadRequest(c, "Invalid trader ID")
+		return nil, "", false
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeNotFound(c, "Trader")
+		return nil, "", false
+	}
+	return trader.GetStore().Lesson(), trader.GetID(), true
+}
+
+// parseLessonID parses the :id path parameter
+func parseLessonID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		SafeBadRequest(c, "Invalid lesson ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// handleListLessons Lessons of a trader (optional ?status=pending|active|superseded)
+func (s *Server) handleListLessons(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+
+	list, err := lessons.ListLessons(traderID, c.Query("status"))
+	if err != nil {
+		SafeInternalError(c, "Get lessons", err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// handleListTradeReviews Recent trade self-reviews of a trader
+func (s *Server) handleListTradeReviews(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	reviews, err := lessons.ListReviews(traderID, limit)
+	if err != nil {
+		SafeInternalError(c, "Get trade reviews", err)
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// handleLessonHistory All versions of a lesson
+func (s *Server) handleLessonHistory(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+	id, ok := parseLessonID(c)
+	if !ok {
+		return
+	}
+
+	lesson, err := lessons.GetLesson(traderID, id)
+	if err != nil {
+		SafeNotFound(c, "Lesson")
+		return
+	}
+	history, err := lessons.GetLessonHistory(traderID, lesson.LessonKey)
+	if err != nil {
+		SafeInternalError(c, "Get lesson history", err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// handleApproveLesson Activate a pending lesson so it is injected into the system prompt
+func (s *Server) handleApproveLesson(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+	id, ok := parseLessonID(c)
+	if !ok {
+		return
+	}
+
+	if err := lessons.ApproveLesson(traderID, id); err != nil {
+		SafeNotFound(c, "Pending lesson")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Lesson approved"})
+}
+
+// handleUpdateLesson Edit a lesson (creates a new version)
+func (s *Server) handleUpdateLesson(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+	id, ok := parseLessonID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Content  string `json:"content"`
+		Category string `json:"category"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		SafeBadRequest(c, "Lesson content is required")
+		return
+	}
+	if req.Category != "" && req.Category != store.LessonCategoryMistake && req.Category != store.LessonCategoryRule {
+		SafeBadRequest(c, "Invalid lesson category")
+		return
+	}
+
+	lesson, err := lessons.UpdateLesson(traderID, id, req.Content, req.Category, c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			SafeNotFound(c, "Lesson")
+			return
+		}
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, lesson)
+}
+
+// handleDeleteLesson Delete a lesson with all its versions
+func (s *Server) handleDeleteLesson(c *gin.Context) {
+	lessons, traderID, ok := s.lessonStoreFromQuery(c)
+	if !ok {
+		return
+	}
+	id, ok := parseLessonID(c)
+	if !ok {
+		return
+	}
+
+	if err := lessons.DeleteLesson(traderID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			SafeNotFound(c, "Lesson")
+			return
+		}
+		SafeInternalError(c, "Delete lesson", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Lesson deleted"})
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleListRebalances Get the rebalance history of a target-weight portfolio trader with its
+// turnover over the last `hours` hours (default 30 days)
+func (s *Server) handleListRebalances(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	hours := 30 * 24
+	if h, err := strconv.Atoi(c.Query("hours")); err == nil && h > 0 {
+		hours = h
+	}
+
+	runs, err := s.store.Rebalance().List(traderID, limit)
+	if err != nil {
+		SafeInternalError(c, "Failed to get rebalance history", err)
+		return
+	}
+	end := time.Now().UTC()
+	turnover, err := s.store.Rebalance().GetTurnover(traderID, end.Add(-time.Duration(hours)*time.Hour), end)
+	if err != nil {
+		SafeInternalError(c, "Failed to get rebalance turnover", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rebalances": runs, "turnover": turnover, "hours": hours})
+}
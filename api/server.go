@@ -13,6 +13,7 @@ import (
 	"nofx/logger"
 	"nofx/manager"
 	"nofx/market"
+	"nofx/performance"
 	"nofx/provider/alpaca"
 	"nofx/provider/coinank/coinank_api"
 	"nofx/provider/coinank/coinank_enum"
@@ -167,6 +168,24 @@ func (s *Server) setupRoutes() {
 			protected.POST("/traders/:id/close-position", s.handleClosePosition)
 			protected.PUT("/traders/:id/competition", s.handleToggleCompetition)
 			protected.GET("/traders/:id/grid-risk", s.handleGetGridRiskInfo)
+			protected.GET("/traders/:id/grid-ledger", s.handleGetGridLedger)
+			protected.GET("/traders/:id/performance", s.handleGetTraderPerformance)
+			protected.GET("/traders/:id/regime-switches", s.handleGetRegimeSwitches)
+			protected.GET("/traders/:id/sleeves", s.handleListSleeves)
+			protected.POST("/traders/:id/sleeves", s.handleCreateSleeve)
+			protected.PUT("/traders/:id/sleeves/:sleeveId", s.handleUpdateSleeve)
+			protected.DELETE("/traders/:id/sleeves/:sleeveId", s.handleDeleteSleeve)
+			protected.GET("/traders/:id/sleeves/:sleeveId/equity", s.handleGetSleeveEquity)
+			protected.PUT("/traders/:id/follow", s.handleUpdateFollow)
+			protected.DELETE("/traders/:id/follow", s.handleDeleteFollow)
+			protected.GET("/traders/:id/copy-trading", s.handleGetCopyTrading)
+			protected.GET("/traders/:id/rebalances", s.handleListRebalances)
+			protected.GET("/traders/:id/shadows", s.handleListShadows)
+			protected.POST("/traders/:id/shadows", s.handleCreateShadow)
+			protected.PUT("/traders/:id/shadows/:shadowId", s.handleUpdateShadow)
+			protected.DELETE("/traders/:id/shadows/:shadowId", s.handleDeleteShadow)
+			protected.GET("/traders/:id/shadows/:shadowId/decisions", s.handleGetShadowDecisions)
+			protected.GET("/traders/:id/shadows/:shadowId/compare", s.handleCompareShadow)
 
 			// AI model configuration
 			protected.GET("/models", s.handleGetModelConfigs)
@@ -184,6 +203,7 @@ func (s *Server) setupRoutes() {
 			protected.GET("/strategies/default-config", s.handleGetDefaultStrategyConfig)
 			protected.POST("/strategies/preview-prompt", s.handlePreviewPrompt)
 			protected.POST("/strategies/test-run", s.handleStrategyTestRun)
+			protected.POST("/strategies/build", s.handleBuildStrategy)
 			protected.GET("/strategies/:id", s.handleGetStrategy)
 			protected.POST("/strategies", s.handleCreateStrategy)
 			protected.PUT("/strategies/:id", s.handleUpdateStrategy)
@@ -216,6 +236,13 @@ func (s *Server) setupRoutes() {
 			protected.GET("/decisions", s.handleDecisions)
 			protected.GET("/decisions/latest", s.handleLatestDecisions)
 			protected.GET("/statistics", s.handleStatistics)
+			protected.GET("/decisions/calibration", s.handleDecisionCalibration)
+			protected.GET("/lessons", s.handleListLessons)
+			protected.GET("/lessons/reviews", s.handleListTradeReviews)
+			protected.GET("/lessons/:id/history", s.handleLessonHistory)
+			protected.POST("/lessons/:id/approve", s.handleApproveLesson)
+			protected.PUT("/lessons/:id", s.handleUpdateLesson)
+			protected.DELETE("/lessons/:id", s.handleDeleteLesson)
 
 			// Backtest routes
 			backtest := protected.Group("/backtest")
@@ -1133,6 +1160,86 @@ func (s *Server) handleGetGridRiskInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, riskInfo)
 }
 
+// handleGetGridLedger returns the round-trip ledger of a grid trader (profit per level and per day, grid APR)
+func (s *Server) handleGetGridLedger(c *gin.Context) {
+	traderID := c.Param("id")
+
+	autoTrader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	ledger, err := autoTrader.GetGridLedger()
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, ledger)
+}
+
+// handleGetTraderPerformance returns the performance report of a trader over the last ?days (default 90,
+// max 1095), computed by the metric engine backtests use, against buy-and-hold BTC
+func (s *Server) handleGetTraderPerformance(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	days := 90
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 && d <= 1095 {
+		days = d
+	}
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -days)
+
+	input, err := s.store.PerformanceInput(traderID, start, end)
+	if err != nil {
+		SafeInternalError(c, "Failed to load performance data", err)
+		return
+	}
+	if len(input.Equity) > 1 {
+		timeframe := "1h"
+		if days > 90 {
+			timeframe = "4h"
+		}
+		first, last := input.Equity[0].Time, input.Equity[len(input.Equity)-1].Time
+		if klines, err := market.GetKlinesRange(performance.BenchmarkSymbol, timeframe, first, last); err != nil {
+			logger.Warnf("⚠️ Failed to load %s benchmark for trader %s: %v", performance.BenchmarkSymbol, traderID, err)
+		} else {
+			for _, k := range klines {
+				input.Benchmark = append(input.Benchmark, performance.PricePoint{Time: time.UnixMilli(k.CloseTime).UTC(), Price: k.Close})
+			}
+		}
+	}
+	c.JSON(http.StatusOK, performance.Compute(input))
+}
+
+// handleGetRegimeSwitches returns the latest regime switches of a regime router trader
+func (s *Server) handleGetRegimeSwitches(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	events, err := s.store.Regime().LoadRecentSwitches(traderID, limit)
+	if err != nil {
+		SafeInternalError(c, "Failed to get regime switches", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"switches": events})
+}
+
 // handleSyncBalance Sync exchange balance to initial_balance (Option B: Manual Sync + Option C: Smart Detection)
 func (s *Server) handleSyncBalance(c *gin.Context) {
 	userID := c.GetString("user_id")
@@ -2282,11 +2389,15 @@ func (s *Server) handlePositionHistory(c *gin.Context) {
 	// Get direction stats
 	directionStats, _ := store.Position().GetDirectionStats(trader.GetID())
 
+	// Get multi-leg position stats (pairs, funding carry)
+	groupStats, _ := store.Position().GetGroupedPnL(trader.GetID(), limit)
+
 	c.JSON(http.StatusOK, gin.H{
 		"positions":       positions,
 		"stats":           stats,
 		"symbol_stats":    symbolStats,
 		"direction_stats": directionStats,
+		"group_stats":     groupStats,
 	})
 }
 
@@ -2920,6 +3031,38 @@ func (s *Server) handleStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// handleDecisionCalibration Decision outcome calibration report (hit rates by confidence, symbol, action and model)
+func (s *Server) handleDecisionCalibration(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		SafeBadRequest(c, "Invalid trader ID")
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	// Lookback window in days, default 14
+	days := 14
+	if daysStr := c.Query("days"); daysStr != "" {
+		if parsedDays, err := strconv.Atoi(daysStr); err == nil && parsedDays > 0 {
+			days = parsedDays
+		}
+	}
+	since := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour).UnixMilli()
+
+	report, err := trader.GetStore().DecisionScore().GetCalibrationReport(trader.GetID(), since)
+	if err != nil {
+		SafeInternalError(c, "Get calibration report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // handleCompetition Competition overview (compare all traders)
 func (s *Server) handleCompetition(c *gin.Context) {
 	userID := c.GetString("user_id")
@@ -3428,6 +3571,8 @@ func (s *Server) Start() error {
 	logger.Infof("  • GET  /api/decisions?trader_id=xxx  - Specified trader's decision log")
 	logger.Infof("  • GET  /api/decisions/latest?trader_id=xxx - Specified trader's latest decisions")
 	logger.Infof("  • GET  /api/statistics?trader_id=xxx - Specified trader's statistics")
+	logger.Infof("  • GET  /api/decisions/calibration?trader_id=xxx - Specified trader's decision accuracy calibration")
+	logger.Infof("  • GET  /api/lessons?trader_id=xxx    - Specified trader's lessons from trade self-reviews")
 	logger.Infof("  • GET  /api/performance?trader_id=xxx - Specified trader's AI learning performance analysis")
 	logger.Info()
 
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nofx/store"
+	"nofx/trader"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// handleListShadows List the shadow strategies of a trader
+func (s *Server) handleListShadows(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	shadows, err := s.store.Shadow().List(traderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get shadow strategies", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shadows": shadows})
+}
+
+// handleCreateShadow Run a candidate strategy in shadow next to the trader's live strategy
+// (applies on the next trader start)
+func (s *Server) handleCreateShadow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	full, err := s.store.Trader().GetFullConfig(userID, traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	var req struct {
+		StrategyID     string  `json:"strategy_id" binding:"required"`
+		Name           string  `json:"name"`
+		InitialBalance float64 `json:"initial_balance"` // Default: the trader's initial balance
+		FeeRate        float64 `json:"fee_rate"`        // Default: store.DefaultShadowFeeRate
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if req.InitialBalance < 0 || req.FeeRate < 0 || req.FeeRate > 0.01 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "initial_balance must be positive and fee_rate between 0 and 0.01"})
+		return
+	}
+
+	strategy, err := s.store.Strategy().Get(userID, req.StrategyID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy not found"})
+		return
+	}
+	config, err := strategy.ParseConfig()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy configuration is invalid"})
+		return
+	}
+	if !trader.DecisionStrategySupported(config.StrategyType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": config.StrategyType + " strategies cannot run in shadow"})
+		return
+	}
+
+	shadow := &store.ShadowStrategy{
+		ID:             uuid.New().String(),
+		TraderID:       traderID,
+		StrategyID:     req.StrategyID,
+		Name:           strings.TrimSpace(req.Name),
+		InitialBalance: req.InitialBalance,
+		FeeRate:        req.FeeRate,
+		Enabled:        true,
+	}
+	if shadow.Name == "" {
+		shadow.Name = strategy.Name
+	}
+	if shadow.InitialBalance == 0 {
+		shadow.InitialBalance = full.Trader.InitialBalance
+	}
+	if err := s.store.Shadow().Create(shadow); err != nil {
+		SafeInternalError(c, "Failed to create shadow strategy", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"shadow":  shadow,
+		"message": "Shadow strategy created, restart the trader to apply",
+	})
+}
+
+// handleUpdateShadow Pause or resume a shadow strategy (applies on the next trader start)
+func (s *Server) handleUpdateShadow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if _, err := s.store.Shadow().Get(traderID, c.Param("shadowId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "shadow strategy not found"})
+		return
+	}
+	if err := s.store.Shadow().SetEnabled(traderID, c.Param("shadowId"), req.Enabled); err != nil {
+		SafeInternalError(c, "Failed to update shadow strategy", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Shadow strategy updated, restart the trader to apply"})
+}
+
+// handleDeleteShadow Delete a shadow strategy and its simulated history
+func (s *Server) handleDeleteShadow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	if err := s.store.Shadow().Delete(traderID, c.Param("shadowId")); err != nil {
+		SafeInternalError(c, "Failed to delete shadow strategy", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Shadow strategy deleted"})
+}
+
+// handleGetShadowDecisions Get the latest simulated decision cycles of a shadow strategy
+func (s *Server) handleGetShadowDecisions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	shadow, err := s.store.Shadow().Get(traderID, c.Param("shadowId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "shadow strategy not found"})
+		return
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	decisions, err := s.store.Shadow().GetDecisions(shadow.ID, limit)
+	if err != nil {
+		SafeInternalError(c, "Failed to get shadow decisions", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shadow": shadow, "decisions": decisions})
+}
+
+// handleCompareShadow Compare a shadow strategy with the live strategy over the same period
+// (since the shadow was created, or the last `hours` hours)
+func (s *Server) handleCompareShadow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	shadow, err := s.store.Shadow().Get(traderID, c.Param("shadowId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "shadow strategy not found"})
+		return
+	}
+
+	end := time.Now().UTC()
+	start := shadow.CreatedAt.UTC()
+	if h, err := strconv.Atoi(c.Query("hours")); err == nil && h > 0 {
+		if since := end.Add(-time.Duration(h) * time.Hour); since.After(start) {
+			start = since
+		}
+	}
+
+	liveCurve, err := s.store.Equity().GetByTimeRange(traderID, start, end)
+	if err != nil {
+		SafeInternalError(c, "Failed to get live equity", err)
+		return
+	}
+	liveTrades, err := s.store.Position().GetClosedPositionsInRange(traderID, start, end)
+	if err != nil {
+		SafeInternalError(c, "Failed to get live trades", err)
+		return
+	}
+	shadowCurve, err := s.store.Shadow().GetEquityInRange(shadow.ID, start, end)
+	if err != nil {
+		SafeInternalError(c, "Failed to get shadow equity", err)
+		return
+	}
+	shadowTrades, err := s.store.Shadow().GetClosedTrades(shadow.ID, start, end)
+	if err != nil {
+		SafeInternalError(c, "Failed to get shadow trades", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shadow":     shadow,
+		"comparison": trader.CompareShadow(start, end, liveCurve, liveTrades, shadowCurve, shadowTrades),
+	})
+}
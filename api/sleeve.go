@@ -0,0 +1,245 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nofx/store"
+	"nofx/trader"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sleeveRequest request body for creating or updating a strategy sleeve
+type sleeveRequest struct {
+	StrategyID     string  `json:"strategy_id" binding:"required"`
+	Name           string  `json:"name"`
+	AllocationType string  `json:"allocation_type"` // usd/pct (default pct)
+	Allocation     float64 `json:"allocation"`
+	Enabled        *bool   `json:"enabled"`
+}
+
+// validateSleeveAllocations validates the allocations of all sleeves of a trader
+// (percentage allocations must not add up to more than the whole account)
+func validateSleeveAllocations(sleeves []*store.TraderSleeve) error {
+	var totalPct float64
+	for _, sleeve := range sleeves {
+		switch sleeve.AllocationType {
+		case store.SleeveAllocationUSD, store.SleeveAllocationPct:
+		default:
+			return fmt.Errorf("sleeve %s: allocation_type must be 'usd' or 'pct'", sleeve.Name)
+		}
+		if sleeve.Allocation <= 0 {
+			return fmt.Errorf("sleeve %s: allocation must be positive", sleeve.Name)
+		}
+		if sleeve.AllocationType == store.SleeveAllocationPct && sleeve.Enabled {
+			totalPct += sleeve.Allocation
+		}
+	}
+	if totalPct > 100 {
+		return fmt.Errorf("percentage allocations add up to %.1f%% (max 100%%)", totalPct)
+	}
+	return nil
+}
+
+// checkSleeveStrategy checks that a strategy of the user can run as a sleeve and returns its name
+func (s *Server) checkSleeveStrategy(userID, strategyID string) (string, error) {
+	strategy, err := s.store.Strategy().Get(userID, strategyID)
+	if err != nil {
+		return "", fmt.Errorf("strategy not found")
+	}
+	config, err := strategy.ParseConfig()
+	if err != nil {
+		return "", fmt.Errorf("strategy configuration is invalid")
+	}
+	if !trader.DecisionStrategySupported(config.StrategyType) {
+		return "", fmt.Errorf("%s strategies cannot run as a sleeve", config.StrategyType)
+	}
+	return strategy.Name, nil
+}
+
+// saveSleeve validates a created or updated sleeve against the trader's other sleeves and stores it
+func (s *Server) saveSleeve(c *gin.Context, sleeve *store.TraderSleeve, create bool) bool {
+	existing, err := s.store.Sleeve().List(sleeve.TraderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get sleeves", err)
+		return false
+	}
+	sleeves := []*store.TraderSleeve{sleeve}
+	for _, other := range existing {
+		if other.ID != sleeve.ID {
+			sleeves = append(sleeves, other)
+		}
+	}
+	if err := validateSleeveAllocations(sleeves); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if create {
+		err = s.store.Sleeve().Create(sleeve)
+	} else {
+		err = s.store.Sleeve().Update(sleeve)
+	}
+	if err != nil {
+		SafeInternalError(c, "Failed to save sleeve", err)
+		return false
+	}
+	return true
+}
+
+// handleListSleeves List the strategy sleeves of a trader
+func (s *Server) handleListSleeves(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	sleeves, err := s.store.Sleeve().List(traderID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get sleeves", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sleeves": sleeves})
+}
+
+// handleCreateSleeve Add a strategy sleeve to a trader (applies on the next trader start)
+func (s *Server) handleCreateSleeve(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+
+	var req sleeveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	strategyName, err := s.checkSleeveStrategy(userID, req.StrategyID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sleeve := &store.TraderSleeve{
+		ID:             uuid.New().String(),
+		TraderID:       traderID,
+		StrategyID:     req.StrategyID,
+		Name:           strings.TrimSpace(req.Name),
+		AllocationType: req.AllocationType,
+		Allocation:     req.Allocation,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if sleeve.Name == "" {
+		sleeve.Name = strategyName
+	}
+	if sleeve.AllocationType == "" {
+		sleeve.AllocationType = store.SleeveAllocationPct
+	}
+	if !s.saveSleeve(c, sleeve, true) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sleeve":  sleeve,
+		"message": "Sleeve created, restart the trader to apply",
+	})
+}
+
+// handleUpdateSleeve Update a strategy sleeve (applies on the next trader start)
+func (s *Server) handleUpdateSleeve(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	sleeve, err := s.store.Sleeve().Get(traderID, c.Param("sleeveId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sleeve not found"})
+		return
+	}
+
+	var req sleeveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if _, err := s.checkSleeveStrategy(userID, req.StrategyID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sleeve.StrategyID = req.StrategyID
+	if name := strings.TrimSpace(req.Name); name != "" {
+		sleeve.Name = name
+	}
+	if req.AllocationType != "" {
+		sleeve.AllocationType = req.AllocationType
+	}
+	sleeve.Allocation = req.Allocation
+	if req.Enabled != nil {
+		sleeve.Enabled = *req.Enabled
+	}
+	if !s.saveSleeve(c, sleeve, false) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sleeve":  sleeve,
+		"message": "Sleeve updated, restart the trader to apply",
+	})
+}
+
+// handleDeleteSleeve Delete a strategy sleeve and its equity history
+func (s *Server) handleDeleteSleeve(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	if err := s.store.Sleeve().Delete(traderID, c.Param("sleeveId")); err != nil {
+		SafeInternalError(c, "Failed to delete sleeve", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sleeve deleted, restart the trader to apply"})
+}
+
+// handleGetSleeveEquity Get the equity curve of a strategy sleeve
+func (s *Server) handleGetSleeveEquity(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader not found"})
+		return
+	}
+	sleeve, err := s.store.Sleeve().Get(traderID, c.Param("sleeveId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "sleeve not found"})
+		return
+	}
+
+	limit := 500
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 10000 {
+		limit = l
+	}
+	snapshots, err := s.store.Sleeve().GetEquityCurve(traderID, sleeve.ID, limit)
+	if err != nil {
+		SafeInternalError(c, "Failed to get sleeve equity", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sleeve": sleeve, "equity": snapshots})
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+
+	"nofx/store"
+)
+
+func TestValidateSleeveAllocations(t *testing.T) {
+	sleeve := func(allocType string, alloc float64, enabled bool) *store.TraderSleeve {
+		return &store.TraderSleeve{Name: "s", AllocationType: allocType, Allocation: alloc, Enabled: enabled}
+	}
+	tests := []struct {
+		name    string
+		sleeves []*store.TraderSleeve
+		wantErr bool
+	}{
+		{"mixed", []*store.TraderSleeve{sleeve("pct", 60, true), sleeve("usd", 500, true), sleeve("pct", 40, true)}, false},
+		{"over 100%", []*store.TraderSleeve{sleeve("pct", 60, true), sleeve("pct", 50, true)}, true},
+		{"disabled not counted", []*store.TraderSleeve{sleeve("pct", 60, true), sleeve("pct", 50, false)}, false},
+		{"zero allocation", []*store.TraderSleeve{sleeve("usd", 0, true)}, true},
+		{"unknown type", []*store.TraderSleeve{sleeve("ratio", 0.5, true)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSleeveAllocations(tt.sleeves)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
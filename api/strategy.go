@@ -26,6 +26,11 @@ func validateStrategyConfig(config *store.StrategyConfig) []string {
 		warnings = append(warnings, "NofxOS API key is not configured. NofxOS data sources may not work properly.")
 	}
 
+	// Each strategy type validates its own config section
+	if err := kernel.ValidateStrategy(config); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
 	return warnings
 }
 
@@ -583,18 +588,34 @@ func (s *Server) handleStrategyTestRun(c *gin.Context) {
 
 // runRealAITest Execute real AI test call
 func (s *Server) runRealAITest(userID, modelID, systemPrompt, userPrompt string) (string, error) {
+	aiClient, err := s.newUserAIClient(userID, modelID)
+	if err != nil {
+		return "", err
+	}
+
+	// Call AI API
+	response, err := aiClient.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return "", fmt.Errorf("AI API call failed: %w", err)
+	}
+
+	return response, nil
+}
+
+// newUserAIClient creates an AI client from the user's AI model configuration
+func (s *Server) newUserAIClient(userID, modelID string) (mcp.AIClient, error) {
 	// Get AI model configuration
 	model, err := s.store.AIModel().Get(userID, modelID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get AI model: %w", err)
+		return nil, fmt.Errorf("failed to get AI model: %w", err)
 	}
 
 	if !model.Enabled {
-		return "", fmt.Errorf("AI model %s is not enabled", model.Name)
+		return nil, fmt.Errorf("AI model %s is not enabled", model.Name)
 	}
 
 	if model.APIKey == "" {
-		return "", fmt.Errorf("AI model %s is missing API Key", model.Name)
+		return nil, fmt.Errorf("AI model %s is missing API Key", model.Name)
 	}
 
 	// Create AI client
@@ -606,37 +627,23 @@ func (s *Server) runRealAITest(userID, modelID, systemPrompt, userPrompt string)
 	switch provider {
 	case "qwen":
 		aiClient = mcp.NewQwenClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "deepseek":
 		aiClient = mcp.NewDeepSeekClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "claude":
 		aiClient = mcp.NewClaudeClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "kimi":
 		aiClient = mcp.NewKimiClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "gemini":
 		aiClient = mcp.NewGeminiClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "grok":
 		aiClient = mcp.NewGrokClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	case "openai":
 		aiClient = mcp.NewOpenAIClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	default:
 		// Use generic client
 		aiClient = mcp.NewClient()
-		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	}
+	aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 
-	// Call AI API
-	response, err := aiClient.CallWithMessages(systemPrompt, userPrompt)
-	if err != nil {
-		return "", fmt.Errorf("AI API call failed: %w", err)
-	}
-
-	return response, nil
+	return aiClient, nil
 }
-
@@ -0,0 +1,367 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// strategyBuilderMaxAttempts AI calls allowed to produce a valid config (first draft + fixes)
+const strategyBuilderMaxAttempts = 3
+
+// strategyBuilderField a config field the AI is allowed to set
+type strategyBuilderField struct {
+	Path        string
+	Description string
+}
+
+// strategyBuilderFields constrained schema exposed to the AI; any other field is rejected
+var strategyBuilderFields = []strategyBuilderField{
+	{"coin_source.source_type", `string, one of "static" | "ai500" | "oi_top" | "oi_low" | "mixed"`},
+	{"coin_source.static_coins", `string array, e.g. ["BTCUSDT", "ETHUSDT"] (required for "static")`},
+	{"coin_source.excluded_coins", "string array, coins never traded"},
+	{"coin_source.use_ai500", "bool, use AI500 coin pool"},
+	{"coin_source.ai500_limit", "int, max coins from AI500 (1-50)"},
+	{"coin_source.use_oi_top", "bool, use open interest increase ranking (long bias)"},
+	{"coin_source.oi_top_limit", "int, max coins from OI top"},
+	{"coin_source.use_oi_low", "bool, use open interest decrease ranking (short bias)"},
+	{"coin_source.oi_low_limit", "int, max coins from OI low"},
+	{"indicators.klines.primary_timeframe", `string, one of "1m" | "3m" | "5m" | "15m" | "30m" | "1h" | "2h" | "4h" | "1d"`},
+	{"indicators.klines.primary_count", "int, K-lines of the primary timeframe (10-200)"},
+	{"indicators.klines.selected_timeframes", "string array of timeframes analysed together"},
+	{"indicators.klines.enable_multi_timeframe", "bool, analyse several timeframes"},
+	{"indicators.enable_ema", "bool"},
+	{"indicators.enable_macd", "bool"},
+	{"indicators.enable_rsi", "bool"},
+	{"indicators.enable_adx", "bool"},
+	{"indicators.enable_atr", "bool"},
+	{"indicators.enable_boll", "bool, Bollinger Bands"},
+	{"indicators.enable_volume", "bool"},
+	{"indicators.enable_oi", "bool, open interest"},
+	{"indicators.enable_funding_rate", "bool"},
+	{"indicators.ema_periods", "int array, e.g. [20, 50]"},
+	{"indicators.rsi_periods", "int array, e.g. [7, 14]"},
+	{"risk_control.max_positions", "int, max coins held at the same time (>= 1)"},
+	{"risk_control.btc_eth_max_leverage", "int, 1-125"},
+	{"risk_control.altcoin_max_leverage", "int, 1-125"},
+	{"risk_control.btc_eth_max_position_value_ratio", "number, max BTC/ETH position value as multiple of equity (> 0)"},
+	{"risk_control.altcoin_max_position_value_ratio", "number, max altcoin position value as multiple of equity (> 0)"},
+	{"risk_control.max_margin_usage", "number in (0, 1], e.g. 0.9"},
+	{"risk_control.min_position_size", "number, min position size in USDT"},
+	{"risk_control.min_risk_reward_ratio", "number, min take-profit / stop-loss distance ratio"},
+	{"risk_control.min_confidence", "int 0-100, min AI confidence to open"},
+	{"prompt_sections.role_definition", "string, trader persona"},
+	{"prompt_sections.trading_frequency", "string, how often to trade"},
+	{"prompt_sections.entry_standards", "string, conditions required to enter"},
+	{"prompt_sections.decision_process", "string, step-by-step decision process"},
+	{"custom_prompt", "string, extra strategy rules appended to the system prompt"},
+}
+
+// strategyDraft AI-built strategy returned for review (not saved)
+type strategyDraft struct {
+	Name            string               `json:"name"`
+	Description     string               `json:"description"`
+	Config          store.StrategyConfig `json:"config"`
+	Notes           string               `json:"notes,omitempty"`    // AI explanation of its choices
+	DefaultedFields []defaultedField     `json:"defaulted_fields"`   // Schema fields not set by the AI
+	Valid           bool                 `json:"valid"`              // Passed validation
+	Problems        []string             `json:"problems,omitempty"` // Remaining validation problems
+	Attempts        int                  `json:"attempts"`           // AI calls used
+}
+
+// defaultedField a field filled from the default strategy config
+type defaultedField struct {
+	Field string `json:"field"`
+	Value any    `json:"value"`
+}
+
+// handleBuildStrategy Build a draft strategy config from a plain-language description
+func (s *Server) handleBuildStrategy(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		Description string `json:"description" binding:"required"`
+		AIModelID   string `json:"ai_model_id" binding:"required"`
+		Lang        string `json:"lang"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if req.Lang != "zh" {
+		req.Lang = "en"
+	}
+
+	aiClient, err := s.newUserAIClient(userID, req.AIModelID)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+
+	draft, err := buildStrategyDraft(aiClient, req.Description, req.Lang)
+	if err != nil {
+		SafeInternalError(c, "Build strategy", err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// buildStrategyDraft asks the AI for a config and feeds validation problems back until it passes
+func buildStrategyDraft(client mcp.AIClient, description, lang string) (*strategyDraft, error) {
+	builder := mcp.NewRequestBuilder().
+		WithSystemPrompt(strategyBuilderSystemPrompt()).
+		WithUserPrompt(description)
+
+	var draft *strategyDraft
+	for attempt := 1; attempt <= strategyBuilderMaxAttempts; attempt++ {
+		request, err := builder.Build()
+		if err != nil {
+			return nil, err
+		}
+		response, err := client.CallWithRequest(request)
+		if err != nil {
+			return nil, fmt.Errorf("AI call failed: %w", err)
+		}
+
+		next, problems := parseStrategyDraft(response, lang)
+		if next != nil {
+			draft = next
+			draft.Attempts = attempt
+			draft.Problems = problems
+			draft.Valid = len(problems) == 0
+		}
+		if draft != nil && draft.Valid {
+			return draft, nil
+		}
+
+		logger.Infof("⚠️ Strategy builder attempt %d has %d problems, asking AI to fix", attempt, len(problems))
+		builder.AddAssistantMessage(response).
+			AddUserMessage("The config has these problems, fix them and output the complete JSON again:\n- " + strings.Join(problems, "\n- "))
+	}
+
+	if draft == nil {
+		return nil, fmt.Errorf("AI did not produce a parsable config after %d attempts", strategyBuilderMaxAttempts)
+	}
+	return draft, nil
+}
+
+// strategyBuilderSystemPrompt describes the constrained schema and output format
+func strategyBuilderSystemPrompt() string {
+	var sb strings.Builder
+	sb.WriteString("You convert a plain-language trading strategy description into a JSON configuration for an automated crypto futures trading system.\n")
+	sb.WriteString("Only set fields the description implies; omitted fields keep safe defaults. Never invent API keys.\n\n")
+	sb.WriteString("## Allowed config fields (dot = nested object)\n")
+	for _, f := range strategyBuilderFields {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", f.Path, f.Description))
+	}
+	sb.WriteString("\n## Output\nOutput only one JSON object:\n")
+	sb.WriteString("```json\n")
+	sb.WriteString(`{"name": "short strategy name", "description": "one sentence", "notes": "why you chose these values", "config": {"coin_source": {"source_type": "static", "static_coins": ["BTCUSDT"]}, "risk_control": {"max_positions": 2}}}`)
+	sb.WriteString("\n```\n")
+	return sb.String()
+}
+
+// parseStrategyDraft overlays the AI config on the default config and returns the draft with
+// validation problems; the draft is nil when the response is not usable at all
+func parseStrategyDraft(response, lang string) (*strategyDraft, []string) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end <= start {
+		return nil, []string{"response does not contain a JSON object"}
+	}
+
+	var raw struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Notes       string          `json:"notes"`
+		Config      json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &raw); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if len(raw.Config) == 0 {
+		return nil, []string{`"config" object is missing`}
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(raw.Config, &fields); err != nil {
+		return nil, []string{fmt.Sprintf(`"config" must be an object: %v`, err)}
+	}
+
+	var problems []string
+	allowed := make(map[string]bool, len(strategyBuilderFields))
+	for _, f := range strategyBuilderFields {
+		allowed[f.Path] = true
+	}
+	set := make(map[string]bool)
+	// Only allowed fields are applied, so a draft returned with problems never carries the others
+	allowedFields := make(map[string]any)
+	for _, path := range flattenConfigPaths("", fields) {
+		if !allowed[path] {
+			problems = append(problems, fmt.Sprintf("field %s is not allowed", path))
+			continue
+		}
+		set[path] = true
+		setConfigPath(allowedFields, path, lookupConfigPath(fields, path))
+	}
+
+	config := store.GetDefaultStrategyConfig(lang)
+	data, err := json.Marshal(allowedFields)
+	if err == nil {
+		err = json.Unmarshal(data, &config)
+	}
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("config has wrong field types: %v", err))
+		config = store.GetDefaultStrategyConfig(lang)
+	}
+	config.Language = lang
+
+	if strings.TrimSpace(raw.Name) == "" {
+		problems = append(problems, `"name" is required`)
+	}
+	problems = append(problems, validateStrategyConfig(&config)...)
+	problems = append(problems, validateBuilderConfig(&config)...)
+
+	// Explain every schema field the AI left to defaults
+	var defaults map[string]any
+	if data, err := json.Marshal(config); err == nil {
+		_ = json.Unmarshal(data, &defaults)
+	}
+	var defaulted []defaultedField
+	for _, f := range strategyBuilderFields {
+		if !set[f.Path] {
+			defaulted = append(defaulted, defaultedField{Field: f.Path, Value: lookupConfigPath(defaults, f.Path)})
+		}
+	}
+
+	return &strategyDraft{
+		Name:            strings.TrimSpace(raw.Name),
+		Description:     strings.TrimSpace(raw.Description),
+		Config:          config,
+		Notes:           strings.TrimSpace(raw.Notes),
+		DefaultedFields: defaulted,
+	}, problems
+}
+
+// validateBuilderConfig checks the fields the builder lets the AI set, so a draft is only
+// reported valid when the AI strategy it describes can actually run
+func validateBuilderConfig(config *store.StrategyConfig) []string {
+	var warnings []string
+
+	// Coin source
+	coinSource := config.CoinSource
+	switch coinSource.SourceType {
+	case "static":
+		if len(coinSource.StaticCoins) == 0 {
+			warnings = append(warnings, "coin_source.static_coins is empty while source_type is 'static'.")
+		}
+	case "ai500", "oi_top", "oi_low":
+		sourceEnabled := map[string]bool{"ai500": coinSource.UseAI500, "oi_top": coinSource.UseOITop, "oi_low": coinSource.UseOILow}
+		if !sourceEnabled[coinSource.SourceType] && len(coinSource.StaticCoins) == 0 {
+			warnings = append(warnings, fmt.Sprintf("coin_source.source_type is '%s' but the source is disabled and no static coins are set.", coinSource.SourceType))
+		}
+	case "mixed":
+		if !coinSource.UseAI500 && !coinSource.UseOITop && !coinSource.UseOILow && !coinSource.UseBinanceTopVol && len(coinSource.StaticCoins) == 0 {
+			warnings = append(warnings, "coin_source.source_type is 'mixed' but no coin source is enabled.")
+		}
+	default:
+		warnings = append(warnings, fmt.Sprintf("coin_source.source_type '%s' is invalid (static, ai500, oi_top, oi_low, mixed).", coinSource.SourceType))
+	}
+
+	// K-lines
+	klines := config.Indicators.Klines
+	if _, err := market.TFDuration(klines.PrimaryTimeframe); err != nil {
+		warnings = append(warnings, fmt.Sprintf("indicators.klines.primary_timeframe '%s' is not supported.", klines.PrimaryTimeframe))
+	}
+	for _, tf := range klines.SelectedTimeframes {
+		if _, err := market.TFDuration(tf); err != nil {
+			warnings = append(warnings, fmt.Sprintf("indicators.klines.selected_timeframes contains unsupported timeframe '%s'.", tf))
+		}
+	}
+	if klines.PrimaryCount <= 0 || klines.PrimaryCount > 500 {
+		warnings = append(warnings, "indicators.klines.primary_count must be between 1 and 500.")
+	}
+
+	// Risk control
+	risk := config.RiskControl
+	if risk.MaxPositions <= 0 {
+		warnings = append(warnings, "risk_control.max_positions must be at least 1.")
+	}
+	if risk.BTCETHMaxLeverage < 1 || risk.BTCETHMaxLeverage > 125 {
+		warnings = append(warnings, "risk_control.btc_eth_max_leverage must be between 1 and 125.")
+	}
+	if risk.AltcoinMaxLeverage < 1 || risk.AltcoinMaxLeverage > 125 {
+		warnings = append(warnings, "risk_control.altcoin_max_leverage must be between 1 and 125.")
+	}
+	if risk.BTCETHMaxPositionValueRatio <= 0 || risk.AltcoinMaxPositionValueRatio <= 0 {
+		warnings = append(warnings, "risk_control position value ratios must be greater than 0.")
+	}
+	if risk.MaxMarginUsage <= 0 || risk.MaxMarginUsage > 1 {
+		warnings = append(warnings, "risk_control.max_margin_usage must be in (0, 1].")
+	}
+	if risk.MinConfidence < 0 || risk.MinConfidence > 100 {
+		warnings = append(warnings, "risk_control.min_confidence must be between 0 and 100.")
+	}
+	if risk.MinRiskRewardRatio < 0 {
+		warnings = append(warnings, "risk_control.min_risk_reward_ratio must not be negative.")
+	}
+
+	return warnings
+}
+
+// flattenConfigPaths returns the dotted paths of all leaf values (sorted)
+func flattenConfigPaths(prefix string, m map[string]any) []string {
+	var paths []string
+	for key, value := range m {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			paths = append(paths, flattenConfigPaths(path, nested)...)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// setConfigPath sets the value at a dotted path, creating intermediate objects
+func setConfigPath(m map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+}
+
+// lookupConfigPath returns the value at a dotted path (nil if absent)
+func lookupConfigPath(m map[string]any, path string) any {
+	var current any = m
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = obj[key]
+	}
+	return current
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"nofx/mcp"
+	"nofx/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedAIClient returns the scripted responses in order and records the requests
+type scriptedAIClient struct {
+	responses []string
+	requests  []*mcp.Request
+}
+
+func (c *scriptedAIClient) SetAPIKey(apiKey string, customURL string, customModel string) {}
+func (c *scriptedAIClient) SetTimeout(timeout time.Duration)                              {}
+func (c *scriptedAIClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return "", nil
+}
+func (c *scriptedAIClient) CallWithRequest(req *mcp.Request) (string, error) {
+	c.requests = append(c.requests, req)
+	response := c.responses[len(c.requests)-1]
+	return response, nil
+}
+
+func TestBuildStrategyDraftFixesProblems(t *testing.T) {
+	client := &scriptedAIClient{responses: []string{
+		// Static source without coins and an unknown field
+		`{"name": "BTC trend", "config": {"coin_source": {"source_type": "static", "static_coins": []}, "api_key": "x"}}`,
+		"```json\n" + `{"name": "BTC trend", "notes": "BTC only", "config": {"coin_source": {"source_type": "static", "static_coins": ["BTCUSDT"]}, "risk_control": {"max_positions": 1}}}` + "\n```",
+	}}
+
+	draft, err := buildStrategyDraft(client, "Trade only BTC, one position at a time", "en")
+	if err != nil {
+		t.Fatalf("buildStrategyDraft() error: %v", err)
+	}
+	if !draft.Valid || draft.Attempts != 2 {
+		t.Fatalf("draft valid=%v attempts=%d problems=%v, want valid after 2 attempts", draft.Valid, draft.Attempts, draft.Problems)
+	}
+
+	// The fix request carries the problems of the first draft
+	fix := client.requests[1].Messages
+	feedback := fix[len(fix)-1].Content
+	if !strings.Contains(feedback, "api_key") {
+		t.Errorf("fix request should mention the unknown field, got %q", feedback)
+	}
+
+	if got := draft.Config.CoinSource.StaticCoins; len(got) != 1 || got[0] != "BTCUSDT" {
+		t.Errorf("static coins = %v, want [BTCUSDT]", got)
+	}
+	if draft.Config.RiskControl.MaxPositions != 1 {
+		t.Errorf("max positions = %d, want 1", draft.Config.RiskControl.MaxPositions)
+	}
+
+	defaulted := make(map[string]any)
+	for _, f := range draft.DefaultedFields {
+		defaulted[f.Field] = f.Value
+	}
+	if _, ok := defaulted["risk_control.max_positions"]; ok {
+		t.Error("max_positions was set by the AI and should not be reported as defaulted")
+	}
+	if v, ok := defaulted["indicators.klines.primary_timeframe"]; !ok || v == nil {
+		t.Errorf("primary_timeframe should be reported as defaulted with its value, got %v", v)
+	}
+}
+
+func TestParseStrategyDraftInvalidResponse(t *testing.T) {
+	if draft, problems := parseStrategyDraft("no json here", "en"); draft != nil || len(problems) == 0 {
+		t.Errorf("parseStrategyDraft() = %v, %v, want nil draft with problems", draft, problems)
+	}
+	if draft, problems := parseStrategyDraft(`{"name": "x"}`, "en"); draft != nil || len(problems) == 0 {
+		t.Errorf("parseStrategyDraft() without config = %v, %v, want nil draft with problems", draft, problems)
+	}
+}
+
+func TestParseStrategyDraftDropsDisallowedFields(t *testing.T) {
+	draft, problems := parseStrategyDraft(`{"name": "Grid", "config": {"strategy_type": "grid_trading", "grid_config": {"symbol": "BTCUSDT"}, "risk_control": {"max_positions": 2}}}`, "en")
+	if draft == nil || len(problems) != 2 {
+		t.Fatalf("parseStrategyDraft() = %v, %v, want a draft with two field problems", draft, problems)
+	}
+	if draft.Config.StrategyType == "grid_trading" || draft.Config.GridConfig != nil {
+		t.Errorf("disallowed fields were applied: strategy_type=%q grid_config=%v", draft.Config.StrategyType, draft.Config.GridConfig)
+	}
+	if draft.Config.RiskControl.MaxPositions != 2 {
+		t.Errorf("max positions = %d, want 2", draft.Config.RiskControl.MaxPositions)
+	}
+}
+
+func TestValidateStrategyConfigSkipsBuilderChecks(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	config.CoinSource.SourceType = "static"
+	config.CoinSource.StaticCoins = nil
+	if warnings := validateStrategyConfig(&config); len(warnings) != 0 {
+		t.Errorf("create/update validation should not report builder checks, got %v", warnings)
+	}
+	if warnings := validateBuilderConfig(&config); len(warnings) == 0 {
+		t.Error("builder validation should report the empty static coin list")
+	}
+}
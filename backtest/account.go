@@ -25,9 +25,13 @@ type BacktestAccount struct {
 	initialBalance float64
 	cash           float64
 	feeRate        float64
+	makerFeeRate   float64 // Fee rate of filled limit orders
 	slippageRate   float64
 	positions      map[string]*position
 	realizedPnL    float64
+
+	orders      []LimitOrder // Resting limit orders (grid backtests)
+	nextOrderID int
 }
 
 func NewBacktestAccount(initialBalance, feeBps, slippageBps float64) *BacktestAccount {
@@ -35,11 +39,17 @@ func NewBacktestAccount(initialBalance, feeBps, slippageBps float64) *BacktestAc
 		initialBalance: initialBalance,
 		cash:           initialBalance,
 		feeRate:        feeBps / 10000.0,
+		makerFeeRate:   feeBps / 10000.0,
 		slippageRate:   slippageBps / 10000.0,
 		positions:      make(map[string]*position),
 	}
 }
 
+// SetMakerFee sets the fee rate charged on filled limit orders (defaults to the taker fee)
+func (acc *BacktestAccount) SetMakerFee(makerFeeBps float64) {
+	acc.makerFeeRate = makerFeeBps / 10000.0
+}
+
 func positionKey(symbol, side string) string {
 	return strings.ToUpper(symbol) + ":" + side
 }
@@ -68,12 +78,21 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 	}
 
 	execPrice := applySlippage(price, acc.slippageRate, side, true)
+	pos, fee, err := acc.openAt(symbol, side, quantity, leverage, execPrice, acc.feeRate, ts)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return pos, fee, execPrice, nil
+}
+
+// openAt opens or adds to a position at the execution price with the given fee rate
+func (acc *BacktestAccount) openAt(symbol, side string, quantity float64, leverage int, execPrice, feeRate float64, ts int64) (*position, float64, error) {
 	notional := execPrice * quantity
 	margin := notional / float64(leverage)
-	fee := notional * acc.feeRate
+	fee := notional * feeRate
 
 	if margin+fee > acc.cash+epsilon {
-		return nil, 0, 0, fmt.Errorf("insufficient cash: need %.2f", margin+fee)
+		return nil, 0, fmt.Errorf("insufficient cash: need %.2f", margin+fee)
 	}
 
 	acc.cash -= margin + fee
@@ -103,7 +122,7 @@ func (acc *BacktestAccount) Open(symbol, side string, quantity float64, leverage
 		pos.AccumulatedFee += fee // Add to accumulated fee for position additions
 	}
 
-	return pos, fee, execPrice, nil
+	return pos, fee, nil
 }
 
 func (acc *BacktestAccount) Close(symbol, side string, quantity float64, price float64) (float64, float64, float64, error) {
@@ -122,8 +141,15 @@ func (acc *BacktestAccount) Close(symbol, side string, quantity float64, price f
 	}
 
 	execPrice := applySlippage(price, acc.slippageRate, side, false)
+	realized, totalFee := acc.closeAt(pos, quantity, execPrice, acc.feeRate)
+	return realized, totalFee, execPrice, nil
+}
+
+// closeAt closes part of a position at the execution price with the given fee rate.
+// Returns the realized PnL and the total fee (opening portion + closing).
+func (acc *BacktestAccount) closeAt(pos *position, quantity, execPrice, feeRate float64) (float64, float64) {
 	closeNotional := execPrice * quantity // Notional at close price (for fee calculation)
-	closingFee := closeNotional * acc.feeRate
+	closingFee := closeNotional * feeRate
 
 	// Calculate proportional values based on the portion being closed
 	closePortion := quantity / pos.Quantity
@@ -152,7 +178,7 @@ func (acc *BacktestAccount) Close(symbol, side string, quantity float64, price f
 	}
 
 	// Return total fee (opening + closing) so caller can calculate accurate P&L
-	return realized, totalFee, execPrice, nil
+	return realized, totalFee
 }
 
 func (acc *BacktestAccount) TotalEquity(priceMap map[string]float64) (float64, float64, map[string]float64) {
@@ -231,6 +257,14 @@ func (acc *BacktestAccount) positionLeverage(symbol, side string) int {
 	return 0
 }
 
+// positionQuantity returns the open quantity of a symbol side (0 when flat)
+func (acc *BacktestAccount) positionQuantity(symbol, side string) float64 {
+	if pos, ok := acc.positions[positionKey(symbol, side)]; ok {
+		return pos.Quantity
+	}
+	return 0
+}
+
 func (acc *BacktestAccount) Cash() float64 {
 	return acc.cash
 }
@@ -0,0 +1,367 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nofx/store"
+)
+
+const (
+	maxCompareRuns      = 6
+	maxCompareDecisions = 100000 // Decision records loaded per run
+)
+
+// RunComparison side-by-side comparison of several backtest runs; the first run is the baseline
+type RunComparison struct {
+	RunIDs          []string              `json:"run_ids"`
+	Timeframe       string                `json:"timeframe"`
+	Equity          []ComparedEquityPoint `json:"equity"`
+	ConfigDiffs     []ConfigDiff          `json:"config_diffs"`
+	Metrics         map[string]*Metrics   `json:"metrics"`
+	MetricDeltas    []MetricDelta         `json:"metric_deltas"`
+	Divergences     []DecisionDivergence  `json:"divergences"`
+	DivergenceCount int                   `json:"divergence_count"` // All divergences, Divergences may be truncated
+	CommonCycles    int                   `json:"common_cycles"`    // Decision timestamps every run decided on
+}
+
+// ComparedEquityPoint equity and return of every run at one timestamp of the common timeline
+type ComparedEquityPoint struct {
+	Timestamp int64              `json:"ts"`
+	Equity    map[string]float64 `json:"equity"`
+	ReturnPct map[string]float64 `json:"return_pct"` // On each run's initial balance
+}
+
+// ConfigDiff one configuration field whose value differs between the runs
+type ConfigDiff struct {
+	Field  string            `json:"field"`
+	Values map[string]string `json:"values"`
+}
+
+// MetricDelta one metric of every run and its difference to the baseline run
+type MetricDelta struct {
+	Metric string             `json:"metric"`
+	Values map[string]float64 `json:"values"`
+	Deltas map[string]float64 `json:"deltas"`
+}
+
+// DecisionDivergence the runs decided differently for the same timestamp and symbol
+type DecisionDivergence struct {
+	Timestamp int64             `json:"ts"`
+	Symbol    string            `json:"symbol"`
+	Cycles    map[string]int    `json:"cycles"`
+	Actions   map[string]string `json:"actions"` // "hold" when the run took no action on the symbol
+}
+
+// comparedRun the persisted data of one run that takes part in a comparison
+type comparedRun struct {
+	id        string
+	cfg       *BacktestConfig
+	equity    []EquityPoint
+	metrics   *Metrics
+	decisions []*store.DecisionRecord
+}
+
+// CompareRuns compares the equity curves, configurations, metrics and decisions of several runs.
+// timeframe resamples the equity curves (default: decision timeframe of the first run), limit caps
+// the equity points and the listed divergences.
+func (m *Manager) CompareRuns(runIDs []string, timeframe string, limit int) (*RunComparison, error) {
+	if len(runIDs) < 2 || len(runIDs) > maxCompareRuns {
+		return nil, fmt.Errorf("compare between 2 and %d runs", maxCompareRuns)
+	}
+	runs := make([]comparedRun, 0, len(runIDs))
+	for _, runID := range runIDs {
+		cfg, err := LoadConfig(runID)
+		if err != nil {
+			return nil, fmt.Errorf("load config of %s: %w", runID, err)
+		}
+		equity, err := LoadEquityPoints(runID)
+		if err != nil {
+			return nil, fmt.Errorf("load equity of %s: %w", runID, err)
+		}
+		decisions, err := LoadDecisionRecords(runID, maxCompareDecisions, 0)
+		if err != nil {
+			return nil, fmt.Errorf("load decisions of %s: %w", runID, err)
+		}
+		metrics, err := m.GetMetrics(runID)
+		if err != nil {
+			metrics = nil // Not computed yet, the run is compared without metrics
+		}
+		runs = append(runs, comparedRun{id: runID, cfg: cfg, equity: equity, metrics: metrics, decisions: decisions})
+	}
+	return buildRunComparison(runs, timeframe, limit)
+}
+
+func buildRunComparison(runs []comparedRun, timeframe string, limit int) (*RunComparison, error) {
+	if timeframe == "" {
+		timeframe = runs[0].cfg.DecisionTimeframe
+	}
+	cmp := &RunComparison{
+		Timeframe:   timeframe,
+		Metrics:     make(map[string]*Metrics, len(runs)),
+		ConfigDiffs: diffRunConfigs(runs),
+	}
+	for _, run := range runs {
+		cmp.RunIDs = append(cmp.RunIDs, run.id)
+		cmp.Metrics[run.id] = run.metrics
+	}
+
+	equity, err := alignRunEquity(runs, timeframe)
+	if err != nil {
+		return nil, err
+	}
+	cmp.Equity = limitComparedEquity(equity, limit)
+	cmp.MetricDeltas = runMetricDeltas(runs)
+	cmp.Divergences, cmp.CommonCycles = decisionDivergences(runs)
+	cmp.DivergenceCount = len(cmp.Divergences)
+	if limit > 0 && len(cmp.Divergences) > limit {
+		cmp.Divergences = cmp.Divergences[:limit]
+	}
+	return cmp, nil
+}
+
+// alignRunEquity resamples every equity curve to the timeframe and merges them on the union of their
+// timestamps, carrying each run's last equity forward (runs are absent before their first point)
+func alignRunEquity(runs []comparedRun, timeframe string) ([]ComparedEquityPoint, error) {
+	curves := make([][]EquityPoint, len(runs))
+	var timeline []int64
+	seen := make(map[int64]bool)
+	for i, run := range runs {
+		points, err := ResampleEquity(run.equity, timeframe)
+		if err != nil {
+			return nil, err
+		}
+		curves[i] = AlignEquityTimestamps(points)
+		for _, pt := range curves[i] {
+			if !seen[pt.Timestamp] {
+				seen[pt.Timestamp] = true
+				timeline = append(timeline, pt.Timestamp)
+			}
+		}
+	}
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i] < timeline[j] })
+
+	aligned := make([]ComparedEquityPoint, 0, len(timeline))
+	next := make([]int, len(runs))
+	for _, ts := range timeline {
+		point := ComparedEquityPoint{
+			Timestamp: ts,
+			Equity:    make(map[string]float64, len(runs)),
+			ReturnPct: make(map[string]float64, len(runs)),
+		}
+		for i, run := range runs {
+			for next[i] < len(curves[i]) && curves[i][next[i]].Timestamp <= ts {
+				next[i]++
+			}
+			if next[i] == 0 {
+				continue
+			}
+			equity := curves[i][next[i]-1].Equity
+			point.Equity[run.id] = equity
+			if run.cfg.InitialBalance > 0 {
+				point.ReturnPct[run.id] = (equity - run.cfg.InitialBalance) / run.cfg.InitialBalance * 100
+			}
+		}
+		aligned = append(aligned, point)
+	}
+	return aligned, nil
+}
+
+// limitComparedEquity uniformly samples the aligned curve down to limit points (like LimitEquityPoints)
+func limitComparedEquity(points []ComparedEquityPoint, limit int) []ComparedEquityPoint {
+	if limit <= 0 || len(points) <= limit {
+		return points
+	}
+	step := float64(len(points)) / float64(limit)
+	result := make([]ComparedEquityPoint, 0, limit)
+	for i := 0; i < limit; i++ {
+		result = append(result, points[int(step*float64(i))])
+	}
+	result[len(result)-1] = points[len(points)-1]
+	return result
+}
+
+// diffRunConfigs lists the configuration fields whose value is not the same in every run
+func diffRunConfigs(runs []comparedRun) []ConfigDiff {
+	fields := []struct {
+		name  string
+		value func(cfg *BacktestConfig) string
+	}{
+		{"strategy_id", func(cfg *BacktestConfig) string { return cfg.StrategyID }},
+		{"prompt_variant", func(cfg *BacktestConfig) string { return cfg.PromptVariant }},
+		{"prompt_template", func(cfg *BacktestConfig) string { return cfg.PromptTemplate }},
+		{"custom_prompt", func(cfg *BacktestConfig) string { return cfg.CustomPrompt }},
+		{"override_prompt", func(cfg *BacktestConfig) string { return strconv.FormatBool(cfg.OverrideBasePrompt) }},
+		{"ai_model_id", func(cfg *BacktestConfig) string { return cfg.AIModelID }},
+		{"model", func(cfg *BacktestConfig) string { return strings.Trim(cfg.AICfg.Provider+"/"+cfg.AICfg.Model, "/") }},
+		{"btc_eth_leverage", func(cfg *BacktestConfig) string { return strconv.Itoa(cfg.Leverage.BTCETHLeverage) }},
+		{"altcoin_leverage", func(cfg *BacktestConfig) string { return strconv.Itoa(cfg.Leverage.AltcoinLeverage) }},
+		{"symbols", func(cfg *BacktestConfig) string { return strings.Join(cfg.Symbols, ",") }},
+		{"decision_timeframe", func(cfg *BacktestConfig) string { return cfg.DecisionTimeframe }},
+		{"decision_cadence_nbars", func(cfg *BacktestConfig) string { return strconv.Itoa(cfg.DecisionCadenceNBars) }},
+		{"start_ts", func(cfg *BacktestConfig) string { return strconv.FormatInt(cfg.StartTS, 10) }},
+		{"end_ts", func(cfg *BacktestConfig) string { return strconv.FormatInt(cfg.EndTS, 10) }},
+		{"initial_balance", func(cfg *BacktestConfig) string { return strconv.FormatFloat(cfg.InitialBalance, 'f', -1, 64) }},
+		{"fee_bps", func(cfg *BacktestConfig) string { return strconv.FormatFloat(cfg.FeeBps, 'f', -1, 64) }},
+		{"slippage_bps", func(cfg *BacktestConfig) string { return strconv.FormatFloat(cfg.SlippageBps, 'f', -1, 64) }},
+		{"fill_policy", func(cfg *BacktestConfig) string { return cfg.FillPolicy }},
+	}
+
+	diffs := []ConfigDiff{}
+	for _, field := range fields {
+		values := make(map[string]string, len(runs))
+		differs := false
+		for _, run := range runs {
+			values[run.id] = field.value(run.cfg)
+			if values[run.id] != values[runs[0].id] {
+				differs = true
+			}
+		}
+		if differs {
+			diffs = append(diffs, ConfigDiff{Field: field.name, Values: values})
+		}
+	}
+	return diffs
+}
+
+// runMetricDeltas lists the main metrics of every run with its difference to the baseline run
+func runMetricDeltas(runs []comparedRun) []MetricDelta {
+	baseline := runs[0].metrics
+	if baseline == nil {
+		return []MetricDelta{}
+	}
+	metrics := []struct {
+		name  string
+		value func(m *Metrics) float64
+	}{
+		{"total_return_pct", func(m *Metrics) float64 { return m.TotalReturnPct }},
+		{"annual_return_pct", func(m *Metrics) float64 { return m.AnnualReturnPct }},
+		{"max_drawdown_pct", func(m *Metrics) float64 { return m.MaxDrawdownPct }},
+		{"sharpe_ratio", func(m *Metrics) float64 { return m.SharpeRatio }},
+		{"sortino_ratio", func(m *Metrics) float64 { return m.SortinoRatio }},
+		{"calmar_ratio", func(m *Metrics) float64 { return m.CalmarRatio }},
+		{"profit_factor", func(m *Metrics) float64 { return m.ProfitFactor }},
+		{"win_rate", func(m *Metrics) float64 { return m.WinRate }},
+		{"trades", func(m *Metrics) float64 { return float64(m.Trades) }},
+		{"exposure_pct", func(m *Metrics) float64 { return m.ExposurePct }},
+		{"alpha", func(m *Metrics) float64 { return m.Alpha }},
+		{"beta", func(m *Metrics) float64 { return m.Beta }},
+	}
+
+	deltas := make([]MetricDelta, 0, len(metrics))
+	for _, metric := range metrics {
+		delta := MetricDelta{
+			Metric: metric.name,
+			Values: make(map[string]float64, len(runs)),
+			Deltas: make(map[string]float64, len(runs)),
+		}
+		base := metric.value(baseline)
+		for _, run := range runs {
+			if run.metrics == nil {
+				continue
+			}
+			v := metric.value(run.metrics)
+			delta.Values[run.id] = v
+			delta.Deltas[run.id] = v - base
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
+
+// decisionDivergences lists, for the decision timestamps every run decided on, the symbols on which
+// the runs took different actions. It also returns the number of those common timestamps.
+func decisionDivergences(runs []comparedRun) ([]DecisionDivergence, int) {
+	type cycleActions struct {
+		cycle   int
+		actions map[string]string // symbol → action
+	}
+	byRun := make([]map[int64]cycleActions, len(runs))
+	for i, run := range runs {
+		byRun[i] = make(map[int64]cycleActions, len(run.decisions))
+		for _, record := range run.decisions {
+			ca := cycleActions{cycle: record.CycleNumber, actions: make(map[string]string)}
+			for _, d := range record.Decisions {
+				if d.Symbol == "" {
+					continue
+				}
+				action := normalizeCompareAction(d.Action)
+				if prev, ok := ca.actions[d.Symbol]; ok && prev != "hold" {
+					if action == "hold" {
+						continue
+					}
+					action = prev + "+" + action
+				}
+				ca.actions[d.Symbol] = action
+			}
+			byRun[i][record.Timestamp.UnixMilli()] = ca
+		}
+	}
+
+	var common []int64
+	for ts := range byRun[0] {
+		inAll := true
+		for _, actions := range byRun[1:] {
+			if _, ok := actions[ts]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			common = append(common, ts)
+		}
+	}
+	sort.Slice(common, func(i, j int) bool { return common[i] < common[j] })
+
+	divergences := []DecisionDivergence{}
+	for _, ts := range common {
+		symbols := make(map[string]bool)
+		for _, actions := range byRun {
+			for symbol := range actions[ts].actions {
+				symbols[symbol] = true
+			}
+		}
+		sorted := make([]string, 0, len(symbols))
+		for symbol := range symbols {
+			sorted = append(sorted, symbol)
+		}
+		sort.Strings(sorted)
+
+		for _, symbol := range sorted {
+			div := DecisionDivergence{
+				Timestamp: ts,
+				Symbol:    symbol,
+				Cycles:    make(map[string]int, len(runs)),
+				Actions:   make(map[string]string, len(runs)),
+			}
+			diverged := false
+			for i, run := range runs {
+				ca := byRun[i][ts]
+				action, ok := ca.actions[symbol]
+				if !ok {
+					action = "hold"
+				}
+				div.Cycles[run.id] = ca.cycle
+				div.Actions[run.id] = action
+				if action != div.Actions[runs[0].id] {
+					diverged = true
+				}
+			}
+			if diverged {
+				divergences = append(divergences, div)
+			}
+		}
+	}
+	return divergences, len(common)
+}
+
+// normalizeCompareAction treats waiting and holding as the same decision
+func normalizeCompareAction(action string) string {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action == "" || action == "wait" {
+		return "hold"
+	}
+	return action
+}
@@ -0,0 +1,83 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func compareDecision(cycle int, ts int64, actions ...store.DecisionAction) *store.DecisionRecord {
+	return &store.DecisionRecord{CycleNumber: cycle, Timestamp: time.UnixMilli(ts).UTC(), Decisions: actions}
+}
+
+func TestBuildRunComparison(t *testing.T) {
+	const hour = int64(time.Hour / time.Millisecond)
+	base := comparedRun{
+		id:  "a",
+		cfg: &BacktestConfig{DecisionTimeframe: "1h", InitialBalance: 1000, PromptVariant: "baseline", Leverage: LeverageConfig{BTCETHLeverage: 5}},
+		equity: []EquityPoint{
+			{Timestamp: 0, Equity: 1000}, {Timestamp: hour, Equity: 1010}, {Timestamp: 2 * hour, Equity: 1050},
+		},
+		metrics: &Metrics{TotalReturnPct: 5, Trades: 4},
+		decisions: []*store.DecisionRecord{
+			compareDecision(1, 0, store.DecisionAction{Symbol: "BTCUSDT", Action: "open_long"}),
+			compareDecision(2, hour, store.DecisionAction{Symbol: "BTCUSDT", Action: "hold"}),
+			compareDecision(3, 2*hour, store.DecisionAction{Symbol: "ETHUSDT", Action: "open_short"}),
+		},
+	}
+	variant := comparedRun{
+		id:  "b",
+		cfg: &BacktestConfig{DecisionTimeframe: "1h", InitialBalance: 2000, PromptVariant: "aggressive", Leverage: LeverageConfig{BTCETHLeverage: 5}},
+		equity: []EquityPoint{
+			{Timestamp: hour + 60000, Equity: 1900}, {Timestamp: 2 * hour, Equity: 2100},
+		},
+		metrics: &Metrics{TotalReturnPct: 5, MaxDrawdownPct: 5, Trades: 6},
+		decisions: []*store.DecisionRecord{
+			compareDecision(1, 0, store.DecisionAction{Symbol: "BTCUSDT", Action: "open_long"}),
+			compareDecision(2, hour, store.DecisionAction{Symbol: "BTCUSDT", Action: "close_long"}),
+			compareDecision(3, 2*hour, store.DecisionAction{Symbol: "ETHUSDT", Action: "wait"}),
+		},
+	}
+
+	cmp, err := buildRunComparison([]comparedRun{base, variant}, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hourly timeline: b has no equity before its first point and 1900 for the 1h bucket
+	if len(cmp.Equity) != 3 || cmp.Timeframe != "1h" {
+		t.Fatalf("got %d aligned points on %s, want 3 on 1h", len(cmp.Equity), cmp.Timeframe)
+	}
+	if _, ok := cmp.Equity[0].Equity["b"]; ok {
+		t.Error("run b should be absent before its first equity point")
+	}
+	if p := cmp.Equity[1]; p.Equity["a"] != 1010 || p.Equity["b"] != 1900 || p.ReturnPct["b"] != -5 {
+		t.Errorf("unexpected aligned point: %+v", p)
+	}
+
+	if len(cmp.ConfigDiffs) != 2 || cmp.ConfigDiffs[0].Field != "prompt_variant" || cmp.ConfigDiffs[1].Field != "initial_balance" {
+		t.Errorf("config diffs = %+v, want prompt_variant and initial_balance", cmp.ConfigDiffs)
+	}
+
+	deltas := make(map[string]MetricDelta)
+	for _, d := range cmp.MetricDeltas {
+		deltas[d.Metric] = d
+	}
+	if deltas["trades"].Deltas["b"] != 2 || deltas["max_drawdown_pct"].Values["b"] != 5 || deltas["total_return_pct"].Deltas["b"] != 0 {
+		t.Errorf("unexpected metric deltas: %+v", cmp.MetricDeltas)
+	}
+
+	// Same open at cycle 1; hold vs close at cycle 2; open_short vs wait at cycle 3
+	if cmp.CommonCycles != 3 || cmp.DivergenceCount != 2 {
+		t.Fatalf("got %d divergences over %d cycles, want 2 over 3", cmp.DivergenceCount, cmp.CommonCycles)
+	}
+	div := cmp.Divergences[1]
+	if div.Symbol != "ETHUSDT" || div.Actions["a"] != "open_short" || div.Actions["b"] != "hold" || div.Cycles["b"] != 3 {
+		t.Errorf("unexpected divergence: %+v", div)
+	}
+
+	if limited, _ := buildRunComparison([]comparedRun{base, variant}, "", 1); len(limited.Divergences) != 1 || limited.DivergenceCount != 2 {
+		t.Error("limit should truncate the listed divergences but keep the count")
+	}
+}
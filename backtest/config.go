@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"nofx/kernel"
 	"nofx/market"
 	"nofx/store"
 )
@@ -38,6 +39,7 @@ type BacktestConfig struct {
 	EndTS                int64    `json:"end_ts"`
 	InitialBalance       float64  `json:"initial_balance"`
 	FeeBps               float64  `json:"fee_bps"`
+	MakerFeeBps          float64  `json:"maker_fee_bps,omitempty"` // Fee of filled limit orders (0 = fee_bps)
 	SlippageBps          float64  `json:"slippage_bps"`
 	FillPolicy           string   `json:"fill_policy"`
 	PromptVariant        string   `json:"prompt_variant"`
@@ -55,8 +57,13 @@ type BacktestConfig struct {
 	CheckpointIntervalSeconds int    `json:"checkpoint_interval_seconds,omitempty"`
 	ReplayDecisionDir         string `json:"replay_decision_dir,omitempty"`
 
+	// ReplayTraderID replays the recorded decisions of a live trader instead of calling the AI
+	ReplayTraderID string `json:"replay_trader_id,omitempty"`
+
 	// Internal: loaded strategy config (set by Manager when StrategyID is provided)
 	loadedStrategy *store.StrategyConfig `json:"-"`
+	// Internal: live decision records replayed for ReplayTraderID (oldest first)
+	liveDecisions []*store.DecisionRecord `json:"-"`
 }
 
 // Validate performs validity checks on the configuration and fills in default values.
@@ -73,7 +80,15 @@ func (cfg *BacktestConfig) Validate() error {
 		cfg.UserID = "default"
 	}
 	cfg.AIModelID = strings.TrimSpace(cfg.AIModelID)
+	cfg.ReplayTraderID = strings.TrimSpace(cfg.ReplayTraderID)
+	if cfg.ReplayTraderID != "" && cfg.ReplayOnly {
+		return fmt.Errorf("replay_trader_id cannot be combined with replay_only")
+	}
 
+	// Live replays default to the symbols the trader decided on
+	if len(cfg.Symbols) == 0 && cfg.ReplayTraderID != "" {
+		cfg.Symbols = liveDecisionSymbols(cfg.liveDecisions)
+	}
 	if len(cfg.Symbols) == 0 {
 		return fmt.Errorf("at least one symbol is required")
 	}
@@ -115,6 +130,13 @@ func (cfg *BacktestConfig) Validate() error {
 		cfg.InitialBalance = 1000
 	}
 
+	if cfg.MakerFeeBps < 0 {
+		return fmt.Errorf("maker_fee_bps cannot be negative")
+	}
+	if cfg.MakerFeeBps == 0 {
+		cfg.MakerFeeBps = cfg.FeeBps
+	}
+
 	if cfg.FillPolicy == "" {
 		cfg.FillPolicy = FillPolicyNextOpen
 	}
@@ -187,6 +209,11 @@ func (cfg *BacktestConfig) SetLoadedStrategy(strategy *store.StrategyConfig) {
 	cfg.loadedStrategy = strategy
 }
 
+// SetLiveDecisions sets the live decision records replayed for ReplayTraderID (oldest first).
+func (cfg *BacktestConfig) SetLiveDecisions(records []*store.DecisionRecord) {
+	cfg.liveDecisions = records
+}
+
 // ToStrategyConfig converts BacktestConfig to StrategyConfig for unified prompt generation.
 // This ensures backtest uses the same StrategyEngine logic as live trading.
 // If a strategy was loaded from database (via StrategyID), it will be used with overrides.
@@ -283,3 +310,18 @@ func (cfg *BacktestConfig) ToStrategyConfig() *store.StrategyConfig {
 		},
 	}
 }
+
+// RequiresAI reports whether the strategy type of this backtest calls the AI (unknown types do).
+// Grids depend on their mode: deterministic grids without re-tuning run without AI.
+// Live replays never call the AI.
+func (cfg *BacktestConfig) RequiresAI() bool {
+	if cfg.ReplayTraderID != "" {
+		return false
+	}
+	strategyConfig := cfg.ToStrategyConfig()
+	if strategyConfig.StrategyType == "grid_trading" && strategyConfig.GridConfig != nil {
+		return kernel.GridRequiresAI(strategyConfig.GridConfig)
+	}
+	reg, ok := kernel.LookupStrategy(strategyConfig.StrategyType)
+	return !ok || reg.RequiresAI
+}
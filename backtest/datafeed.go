@@ -5,7 +5,9 @@ import (
 	"sort"
 	"time"
 
+	"nofx/logger"
 	"nofx/market"
+	"nofx/performance"
 )
 
 type timeframeSeries struct {
@@ -26,8 +28,13 @@ type DataFeed struct {
 	decisionTimes []int64
 	primaryTF     string
 	longerTF      string
+	seriesBars    int                      // Indicator series bars attached to market data (0 = none, used by rule-based strategies)
+	benchmark     []performance.PricePoint // Buy-and-hold benchmark closes over the backtest window
 }
 
+// seriesLookback K-lines used to compute attached indicator series (bounds the per-bar cost)
+const seriesLookback = 300
+
 func NewDataFeed(cfg BacktestConfig) (*DataFeed, error) {
 	df := &DataFeed{
 		cfg:          cfg,
@@ -116,9 +123,41 @@ func (df *DataFeed) loadAll() error {
 	if len(df.decisionTimes) == 0 {
 		return fmt.Errorf("no decision bars in range")
 	}
+	df.loadBenchmark(start, end)
 	return nil
 }
 
+// loadBenchmark loads the benchmark closes of the decision timeframe (reusing the series when the
+// benchmark is a backtest symbol). Without a benchmark alpha and beta are simply not reported.
+func (df *DataFeed) loadBenchmark(start, end time.Time) {
+	var klines []market.Kline
+	if ss, ok := df.symbolSeries[performance.BenchmarkSymbol]; ok && ss.byTF[df.primaryTF] != nil {
+		klines = ss.byTF[df.primaryTF].klines
+	} else {
+		var err error
+		klines, err = market.GetKlinesRange(performance.BenchmarkSymbol, df.primaryTF, start, end)
+		if err != nil {
+			logger.Warnf("⚠️ Failed to load %s benchmark: %v", performance.BenchmarkSymbol, err)
+			return
+		}
+	}
+	startMs, endMs := start.UnixMilli(), end.UnixMilli()
+	for _, k := range klines {
+		if k.CloseTime < startMs || k.CloseTime > endMs {
+			continue
+		}
+		df.benchmark = append(df.benchmark, performance.PricePoint{Time: time.UnixMilli(k.CloseTime).UTC(), Price: k.Close})
+	}
+}
+
+// Benchmark returns the buy-and-hold benchmark closes over the backtest window
+func (df *DataFeed) Benchmark() []performance.PricePoint {
+	if df == nil {
+		return nil
+	}
+	return df.benchmark
+}
+
 func (df *DataFeed) DecisionBarCount() int {
 	return len(df.decisionTimes)
 }
@@ -177,6 +216,20 @@ func (df *DataFeed) BuildMarketData(ts int64) (map[string]*market.Data, map[stri
 		if _, ok := perTF[df.primaryTF]; !ok {
 			return nil, nil, fmt.Errorf("no primary data for %s at %d", symbol, ts)
 		}
+		if df.seriesBars > 0 {
+			timeframeData := make(map[string]*market.TimeframeSeriesData, len(df.timeframes))
+			for _, tf := range df.timeframes {
+				series := df.sliceUpTo(symbol, tf, ts)
+				if len(series) == 0 {
+					continue
+				}
+				if len(series) > seriesLookback {
+					series = series[len(series)-seriesLookback:]
+				}
+				timeframeData[tf] = market.BuildTimeframeSeries(series, tf, df.seriesBars)
+			}
+			result[symbol].TimeframeData = timeframeData
+		}
 		multi[symbol] = perTF
 	}
 	return result, multi, nil
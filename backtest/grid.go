@@ -0,0 +1,712 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+)
+
+// boxTimeframe timeframe of the klines the multi-period boxes are computed from (as in live trading)
+const boxTimeframe = "1h"
+
+// GridMetrics summarizes the grid activity of a backtest.
+type GridMetrics struct {
+	RoundTrips       int     `json:"round_trips"`     // Paired exits filled
+	GridProfit       float64 `json:"grid_profit"`     // Net profit of the round trips (after maker fees)
+	ProfitPerGrid    float64 `json:"profit_per_grid"` // Average net profit per round trip
+	Fills            int     `json:"fills"`           // Filled limit orders (entries and exits)
+	MakerFees        float64 `json:"maker_fees"`
+	StopLosses       int     `json:"stop_losses"`
+	Breakouts        int     `json:"breakouts"` // Range and confirmed box breakouts
+	Trails           int     `json:"trails"`
+	DirectionChanges int     `json:"direction_changes"`
+	BarsInRange      int     `json:"bars_in_range"`
+	PausedBars       int     `json:"paused_bars"`
+	TotalBars        int     `json:"total_bars"`
+	TimeInRangePct   float64 `json:"time_in_range_pct"` // Share of bars closing inside the grid bounds
+
+	// Round trips by level and by day, grid APR versus the unrealized inventory at the last price
+	Ledger *kernel.GridLedger `json:"ledger,omitempty"`
+}
+
+// GridSnapshot is the grid state of a backtest, saved with checkpoints.
+type GridSnapshot struct {
+	Levels      []kernel.GridLevelInfo `json:"levels"`
+	UpperPrice  float64                `json:"upper_price"`
+	LowerPrice  float64                `json:"lower_price"`
+	GridSpacing float64                `json:"grid_spacing"`
+	IsPaused    bool                   `json:"is_paused"`
+	Direction   string                 `json:"direction"`
+
+	PositionReductionPct float64 `json:"position_reduction_pct,omitempty"`
+	BreakoutLevel        string  `json:"breakout_level,omitempty"`
+	BreakoutDirection    string  `json:"breakout_direction,omitempty"`
+	BreakoutConfirmCount int     `json:"breakout_confirm_count,omitempty"`
+	LastTunedTS          int64   `json:"last_tuned_ts,omitempty"`
+
+	TotalProfit   float64     `json:"total_profit"`
+	TotalTrades   int         `json:"total_trades"`
+	WinningTrades int         `json:"winning_trades"`
+	Metrics       GridMetrics `json:"metrics"`
+
+	RoundTrips []kernel.GridRoundTrip `json:"round_trips,omitempty"`
+	StartTS    int64                  `json:"start_ts,omitempty"`
+	LastTS     int64                  `json:"last_ts,omitempty"`
+	LastPrice  float64                `json:"last_price,omitempty"`
+}
+
+// gridEngine replays a grid strategy over the decision bars. Grid orders rest in the simulated
+// account and fill when a bar crosses them; the range breakout, trailing, box breakout, direction
+// and stop-loss rules of the live grid trader run on every bar. Box rules need 1h klines in the
+// backtest timeframes.
+type gridEngine struct {
+	cfg      *store.GridStrategyConfig
+	symbol   string
+	leverage int
+	account  *BacktestAccount
+	feed     *DataFeed
+	state    GridSnapshot
+
+	// Ledger aggregates of the first ledgerTrips round trips (re-summarized when trips are added)
+	ledger      *kernel.GridLedger
+	ledgerTrips int
+}
+
+func newGridEngine(cfg *store.GridStrategyConfig, leverage int, account *BacktestAccount, feed *DataFeed) *gridEngine {
+	return &gridEngine{
+		cfg:      cfg,
+		symbol:   market.Normalize(cfg.Symbol),
+		leverage: leverage,
+		account:  account,
+		feed:     feed,
+		state:    GridSnapshot{Direction: string(market.GridDirectionNeutral)},
+	}
+}
+
+// initialize lays out the grid around the first price: ATR bounds, manual bounds or ±3% by default
+func (g *gridEngine) initialize(data *market.Data) {
+	price := data.CurrentPrice
+	switch {
+	case g.cfg.UseATRBounds:
+		atr := 0.0
+		if data.LongerTermContext != nil {
+			atr = data.LongerTermContext.ATR14
+		}
+		g.state.UpperPrice, g.state.LowerPrice = kernel.GridBounds(price, atr, g.cfg)
+	case g.cfg.UpperPrice > g.cfg.LowerPrice && g.cfg.LowerPrice > 0:
+		g.state.UpperPrice, g.state.LowerPrice = g.cfg.UpperPrice, g.cfg.LowerPrice
+	default:
+		g.state.UpperPrice, g.state.LowerPrice = kernel.GridBounds(price, 0, g.cfg)
+	}
+	g.regrid(price)
+
+	logger.Infof("📊 [Grid] Backtest grid initialized: %d levels, $%.2f - $%.2f, spacing $%.2f",
+		g.cfg.GridCount, g.state.LowerPrice, g.state.UpperPrice, g.state.GridSpacing)
+}
+
+// regrid rebuilds the levels over the current bounds, keeping filled positions on the closest level
+func (g *gridEngine) regrid(price float64) {
+	previous := g.state.Levels
+	g.state.GridSpacing = (g.state.UpperPrice - g.state.LowerPrice) / float64(g.cfg.GridCount-1)
+	g.state.Levels = kernel.NewGridLevels(g.state.LowerPrice, g.state.UpperPrice, price, g.cfg)
+	if g.cfg.EnableDirectionAdjust {
+		kernel.AssignGridSides(g.state.Levels, market.GridDirection(g.state.Direction), g.cfg.DirectionBiasRatio, price)
+	}
+	kernel.RestoreFilledLevels(g.state.Levels, previous)
+}
+
+// onBar processes one decision bar: fills of the resting orders, stop losses, range and box breakouts
+func (g *gridEngine) onBar(ts int64, bar *market.Kline, data *market.Data, cycle int) ([]TradeEvent, []string) {
+	if data == nil || data.CurrentPrice <= 0 {
+		return nil, nil
+	}
+	g.state.LastTS = ts
+	g.state.LastPrice = data.CurrentPrice
+	if len(g.state.Levels) == 0 {
+		g.state.StartTS = ts
+		g.initialize(data)
+		return nil, nil
+	}
+
+	var (
+		events []TradeEvent
+		logs   []string
+	)
+	if bar != nil {
+		for _, fill := range g.account.MatchOrders(g.symbol, *bar) {
+			evt, log := g.applyFill(fill, ts, cycle)
+			events = append(events, evt)
+			logs = append(logs, log)
+		}
+	}
+
+	price := data.CurrentPrice
+	stopEvents, stopLogs := g.checkStopLoss(price, ts, cycle)
+	events = append(events, stopEvents...)
+	logs = append(logs, stopLogs...)
+
+	logs = append(logs, g.checkRangeBreakout(price)...)
+	closeEvents, boxLogs := g.checkBoxes(ts, price, cycle)
+	events = append(events, closeEvents...)
+	logs = append(logs, boxLogs...)
+
+	m := &g.state.Metrics
+	m.TotalBars++
+	if price >= g.state.LowerPrice && price <= g.state.UpperPrice {
+		m.BarsInRange++
+	}
+	if g.state.IsPaused {
+		m.PausedBars++
+	}
+	return events, logs
+}
+
+// applyFill moves the level of a filled order forward: an entry fills the level, a paired exit
+// completes a round trip and frees the level
+func (g *gridEngine) applyFill(fill LimitFill, ts int64, cycle int) (TradeEvent, string) {
+	order := fill.Order
+	m := &g.state.Metrics
+	m.Fills++
+	m.MakerFees += order.Price * order.Quantity * g.account.makerFeeRate
+
+	side := "long"
+	if (order.Side == "sell") == (fill.Opened > 0) {
+		side = "short"
+	}
+	evt := TradeEvent{
+		Timestamp:     ts,
+		Symbol:        order.Symbol,
+		Action:        "limit_" + order.Side,
+		Side:          side,
+		Quantity:      order.Quantity,
+		Price:         order.Price,
+		Fee:           fill.Fee,
+		OrderValue:    order.Price * order.Quantity,
+		Leverage:      order.Leverage,
+		Cycle:         cycle,
+		PositionAfter: math.Abs(fill.PositionAfter),
+	}
+	if fill.Closed > 0 {
+		evt.RealizedPnL = fill.RealizedPnL - fill.Fee
+	}
+
+	for i := range g.state.Levels {
+		level := &g.state.Levels[i]
+		switch order.ID {
+		case level.OrderID:
+			if level.State != "pending" {
+				continue
+			}
+			if fill.Err != nil {
+				level.State = "empty"
+				level.OrderID = ""
+				level.OrderQuantity = 0
+				evt.Note = fmt.Sprintf("level %d entry rejected: %v", i, fill.Err)
+				return evt, fmt.Sprintf("⚠️ [Grid] Level %d entry at %.4f rejected: %v", i, order.Price, fill.Err)
+			}
+			level.State = "filled"
+			level.PositionEntry = order.Price
+			level.PositionSize = order.Quantity
+			level.FilledAt = ts
+			level.EntryFee = order.Price * order.Quantity * g.account.makerFeeRate
+			g.state.TotalTrades++
+			evt.Note = fmt.Sprintf("level %d entry", i)
+			return evt, fmt.Sprintf("✓ [Grid] Level %d %s filled at %.4f", i, order.Side, order.Price)
+
+		case level.ExitOrderID:
+			trip := kernel.CloseGridRoundTrip(g.symbol, *level, order.Price,
+				order.Price*level.PositionSize*g.account.makerFeeRate, kernel.GridExitPaired, time.UnixMilli(ts).UTC())
+			g.state.RoundTrips = append(g.state.RoundTrips, trip)
+			pnl := trip.NetPnL
+			g.state.TotalProfit += pnl
+			if pnl > 0 {
+				g.state.WinningTrades++
+			}
+			m.RoundTrips++
+			m.GridProfit += pnl
+
+			level.State = "empty"
+			level.OrderID = ""
+			level.OrderQuantity = 0
+			level.PositionSize = 0
+			level.PositionEntry = 0
+			level.FilledAt = 0
+			level.EntryFee = 0
+			level.UnrealizedPnL = 0
+			level.ExitOrderID = ""
+			level.ExitPrice = 0
+			evt.Note = fmt.Sprintf("level %d paired exit", i)
+			return evt, fmt.Sprintf("✓ [Grid] Level %d paired exit filled at %.4f, PnL %.4f", i, order.Price, pnl)
+		}
+	}
+	evt.Note = "untracked grid order"
+	return evt, fmt.Sprintf("✓ [Grid] Order %s filled at %.4f", order.ID, order.Price)
+}
+
+// checkStopLoss closes the position of every filled level whose loss reached the stop loss
+func (g *gridEngine) checkStopLoss(price float64, ts int64, cycle int) ([]TradeEvent, []string) {
+	if g.cfg.StopLossPct <= 0 {
+		return nil, nil
+	}
+
+	var (
+		events []TradeEvent
+		logs   []string
+	)
+	for i := range g.state.Levels {
+		level := &g.state.Levels[i]
+		if level.State != "filled" || level.PositionEntry <= 0 {
+			continue
+		}
+		lossPct := (level.PositionEntry - price) / level.PositionEntry * 100
+		posSide := "long"
+		if level.Side == "sell" {
+			lossPct = -lossPct
+			posSide = "short"
+		}
+		if lossPct < g.cfg.StopLossPct {
+			continue
+		}
+
+		if level.ExitOrderID != "" {
+			g.account.CancelOrder(level.ExitOrderID)
+			level.ExitOrderID = ""
+			level.ExitPrice = 0
+		}
+		exitPrice, exitFee := price, 0.0
+		qty := math.Min(level.PositionSize, g.account.positionQuantity(g.symbol, posSide))
+		if qty > epsilon {
+			lev := g.account.positionLeverage(g.symbol, posSide)
+			realized, fee, execPrice, err := g.account.Close(g.symbol, posSide, qty, price)
+			if err != nil {
+				logs = append(logs, fmt.Sprintf("❌ [Grid] Stop loss of level %d failed: %v", i, err))
+				continue
+			}
+			exitPrice, exitFee = execPrice, fee
+			events = append(events, TradeEvent{
+				Timestamp:     ts,
+				Symbol:        g.symbol,
+				Action:        "close_" + posSide,
+				Side:          posSide,
+				Quantity:      qty,
+				Price:         execPrice,
+				Fee:           fee,
+				OrderValue:    execPrice * qty,
+				RealizedPnL:   realized - fee,
+				Leverage:      lev,
+				Cycle:         cycle,
+				PositionAfter: g.account.positionQuantity(g.symbol, posSide),
+				Note:          fmt.Sprintf("grid stop loss level %d", i),
+			})
+		}
+
+		g.state.RoundTrips = append(g.state.RoundTrips,
+			kernel.CloseGridRoundTrip(g.symbol, *level, exitPrice, exitFee, kernel.GridExitStopLoss, time.UnixMilli(ts).UTC()))
+		realizedLoss := -lossPct * level.AllocatedUSD / 100
+		level.State = "stopped"
+		level.UnrealizedPnL = realizedLoss
+		g.state.TotalTrades++
+		g.state.TotalProfit += realizedLoss
+		g.state.Metrics.StopLosses++
+		logs = append(logs, fmt.Sprintf("⚠️ [Grid] Stop loss level %d: entry %.4f, price %.4f, loss %.2f%%",
+			i, level.PositionEntry, price, lossPct))
+	}
+	return events, logs
+}
+
+// checkRangeBreakout trails the grid when the price leaves the range, or pauses it on a breakout of 2% or more
+func (g *gridEngine) checkRangeBreakout(price float64) []string {
+	upper, lower := g.state.UpperPrice, g.state.LowerPrice
+	if upper <= 0 || lower <= 0 || (price >= lower && price <= upper) {
+		return nil
+	}
+
+	if g.cfg.Trailing {
+		newLower, newUpper, steps := kernel.GridTrailBounds(lower, upper, g.cfg.GridCount, kernel.IsGeometricGrid(g.cfg), price)
+		withinLimits := newLower > 0 &&
+			(g.cfg.TrailingUpperLimit <= 0 || newUpper <= g.cfg.TrailingUpperLimit) &&
+			(g.cfg.TrailingLowerLimit <= 0 || newLower >= g.cfg.TrailingLowerLimit)
+		if steps != 0 && withinLimits {
+			g.cancelAll()
+			g.state.LowerPrice, g.state.UpperPrice = newLower, newUpper
+			g.regrid(price)
+			g.state.Metrics.Trails++
+			return []string{fmt.Sprintf("🔁 [Grid] Trailed %+d steps: %.4f - %.4f", steps, newLower, newUpper)}
+		}
+	}
+
+	breakoutPct := (price - upper) / upper * 100
+	if price < lower {
+		breakoutPct = (lower - price) / lower * 100
+	}
+	if breakoutPct < 2.0 || g.state.IsPaused {
+		return nil
+	}
+	g.cancelAll()
+	g.state.IsPaused = true
+	g.state.Metrics.Breakouts++
+	return []string{fmt.Sprintf("⚠️ [Grid] Breakout %.2f%% beyond the range, grid paused", breakoutPct)}
+}
+
+// boxData computes the multi-period boxes from the 1h klines up to the bar (nil without 1h data)
+func (g *gridEngine) boxData(ts int64, price float64) *market.BoxData {
+	if g.feed == nil {
+		return nil
+	}
+	klines := g.feed.sliceUpTo(g.symbol, boxTimeframe, ts)
+	if len(klines) == 0 {
+		return nil
+	}
+	return market.ExportCalculateBoxData(klines, price)
+}
+
+// checkBoxes applies the confirmed box breakout actions and the false breakout recovery
+func (g *gridEngine) checkBoxes(ts int64, price float64, cycle int) ([]TradeEvent, []string) {
+	box := g.boxData(ts, price)
+	if box == nil {
+		return nil, nil
+	}
+
+	var (
+		events []TradeEvent
+		logs   []string
+	)
+	breakoutLevel, direction := kernel.DetectBoxBreakout(box)
+	state := &kernel.BoxBreakoutState{
+		Level:        market.BreakoutLevel(g.state.BreakoutLevel),
+		Direction:    g.state.BreakoutDirection,
+		ConfirmCount: g.state.BreakoutConfirmCount,
+	}
+	confirmed := kernel.ConfirmBoxBreakout(state, breakoutLevel, direction)
+	g.state.BreakoutLevel = string(state.Level)
+	g.state.BreakoutDirection = state.Direction
+	g.state.BreakoutConfirmCount = state.ConfirmCount
+
+	if confirmed {
+		g.state.Metrics.Breakouts++
+		logs = append(logs, fmt.Sprintf("⚠️ [Grid] %s box breakout %s confirmed", breakoutLevel, direction))
+		switch kernel.GetBoxBreakoutActionWithDirection(breakoutLevel, g.cfg.EnableDirectionAdjust) {
+		case kernel.BoxBreakoutActionAdjustDirection:
+			current := market.GridDirection(g.state.Direction)
+			if log := g.adjustDirection(kernel.DetermineGridDirection(box, current, breakoutLevel, direction), price); log != "" {
+				logs = append(logs, log)
+			}
+		case kernel.BoxBreakoutActionReducePosition:
+			g.state.PositionReductionPct = 50
+		case kernel.BoxBreakoutActionPauseGrid:
+			g.state.IsPaused = true
+			g.cancelAll()
+		case kernel.BoxBreakoutActionCloseAll:
+			g.state.IsPaused = true
+			g.cancelAll()
+			events = append(events, g.closeAll(price, ts, cycle)...)
+		}
+	}
+
+	// False breakout recovery: price back inside the long box
+	needsRecovery := g.state.BreakoutLevel != string(market.BreakoutNone) || g.state.PositionReductionPct != 0 || g.state.IsPaused
+	if needsRecovery && box.CurrentPrice >= box.LongLower && box.CurrentPrice <= box.LongUpper {
+		g.state.BreakoutLevel = string(market.BreakoutNone)
+		g.state.BreakoutDirection = ""
+		g.state.BreakoutConfirmCount = 0
+		g.state.PositionReductionPct = 50
+		if g.state.IsPaused {
+			logs = append(logs, "[Grid] Price back in the box, grid resumed at 50% size")
+		}
+		g.state.IsPaused = false
+	}
+	current := market.GridDirection(g.state.Direction)
+	if g.cfg.EnableDirectionAdjust && current != market.GridDirectionNeutral && kernel.ShouldRecoverDirection(box, current) {
+		if log := g.adjustDirection(kernel.DetermineRecoveryDirection(box.CurrentPrice, box, current), price); log != "" {
+			logs = append(logs, log)
+		}
+	}
+	return events, logs
+}
+
+// adjustDirection switches the grid direction and reassigns the sides of the empty levels
+func (g *gridEngine) adjustDirection(direction market.GridDirection, price float64) string {
+	old := market.GridDirection(g.state.Direction)
+	if direction == old {
+		return ""
+	}
+	g.cancelAll()
+	g.state.Direction = string(direction)
+	g.state.Metrics.DirectionChanges++
+	kernel.AssignGridSides(g.state.Levels, direction, g.cfg.DirectionBiasRatio, price)
+	return fmt.Sprintf("[Grid] Direction changed: %s → %s", old, direction)
+}
+
+// cancelAll cancels every grid order; filled levels keep their positions and lose their paired exit
+func (g *gridEngine) cancelAll() {
+	g.account.CancelOrders(g.symbol)
+	for i := range g.state.Levels {
+		level := &g.state.Levels[i]
+		if level.State == "pending" {
+			level.State = "empty"
+			level.OrderID = ""
+			level.OrderQuantity = 0
+		}
+		level.ExitOrderID = ""
+		level.ExitPrice = 0
+	}
+}
+
+// closeAll closes the grid positions at the price and empties the filled levels
+func (g *gridEngine) closeAll(price float64, ts int64, cycle int) []TradeEvent {
+	var events []TradeEvent
+	for _, side := range []string{"long", "short"} {
+		qty := g.account.positionQuantity(g.symbol, side)
+		if qty <= epsilon {
+			continue
+		}
+		lev := g.account.positionLeverage(g.symbol, side)
+		realized, fee, execPrice, err := g.account.Close(g.symbol, side, qty, price)
+		if err != nil {
+			logger.Warnf("[Grid] Backtest failed to close %s position: %v", side, err)
+			continue
+		}
+		events = append(events, TradeEvent{
+			Timestamp:   ts,
+			Symbol:      g.symbol,
+			Action:      "close_" + side,
+			Side:        side,
+			Quantity:    qty,
+			Price:       execPrice,
+			Fee:         fee,
+			OrderValue:  execPrice * qty,
+			RealizedPnL: realized - fee,
+			Leverage:    lev,
+			Cycle:       cycle,
+			Note:        "grid long box breakout",
+		})
+	}
+	for i := range g.state.Levels {
+		level := &g.state.Levels[i]
+		if level.State == "filled" {
+			level.State = "empty"
+			level.PositionSize = 0
+			level.PositionEntry = 0
+			level.UnrealizedPnL = 0
+		}
+	}
+	return events
+}
+
+// buildContext builds the grid context of a decision from the bar data and the simulated account
+func (g *gridEngine) buildContext(data *market.Data, ts int64, account kernel.AccountInfo) *kernel.GridContext {
+	ctx := kernel.BuildGridContextFromMarketData(data, g.cfg)
+	ctx.Symbol = g.symbol
+	ctx.Timestamp = time.UnixMilli(ts).UTC()
+	ctx.CurrentTime = ctx.Timestamp.Format("2006-01-02 15:04:05")
+	ctx.Levels = append([]kernel.GridLevelInfo(nil), g.state.Levels...)
+	ctx.UpperPrice = g.state.UpperPrice
+	ctx.LowerPrice = g.state.LowerPrice
+	ctx.GridSpacing = g.state.GridSpacing
+	ctx.IsPaused = g.state.IsPaused
+	ctx.TotalProfit = g.state.TotalProfit
+	ctx.TotalTrades = g.state.TotalTrades
+	ctx.WinningTrades = g.state.WinningTrades
+	ctx.CurrentDirection = g.state.Direction
+	ctx.PositionReductionPct = g.state.PositionReductionPct
+	if g.state.LastTunedTS > 0 {
+		ctx.LastTunedAt = time.UnixMilli(g.state.LastTunedTS).UTC()
+	}
+	for _, level := range g.state.Levels {
+		if level.State == "pending" {
+			ctx.ActiveOrderCount++
+		} else if level.State == "filled" {
+			ctx.FilledLevelCount++
+		}
+	}
+	ctx.TotalEquity = account.TotalEquity
+	ctx.AvailableBalance = account.AvailableBalance
+	ctx.CurrentPosition = g.account.netPosition(g.symbol)
+	ctx.BoxData = g.boxData(ts, data.CurrentPrice)
+	return ctx
+}
+
+// afterDecision marks a due AI re-tuning as done and applies the tuned bounds and direction
+func (g *gridEngine) afterDecision(ctx *kernel.GridContext, ts int64) string {
+	if interval := kernel.GridAITuneInterval(g.cfg); interval > 0 && ctx.Timestamp.Sub(ctx.LastTunedAt) >= interval {
+		g.state.LastTunedTS = ts
+	}
+	tuning := ctx.Tuning
+	if tuning == nil {
+		return ""
+	}
+	g.cancelAll()
+	g.state.UpperPrice, g.state.LowerPrice = tuning.UpperPrice, tuning.LowerPrice
+	g.regrid(ctx.CurrentPrice)
+	if tuning.Direction != "" {
+		g.adjustDirection(market.GridDirection(tuning.Direction), ctx.CurrentPrice)
+	}
+	return fmt.Sprintf("🤖 [Grid] AI re-tuned grid: %.4f - %.4f, direction %q", tuning.LowerPrice, tuning.UpperPrice, tuning.Direction)
+}
+
+// executeDecision applies a grid decision to the simulated account
+func (g *gridEngine) executeDecision(dec kernel.Decision, price float64, ts int64) (store.DecisionAction, []TradeEvent, string, error) {
+	actionRecord := store.DecisionAction{
+		Action:    dec.Action,
+		Symbol:    g.symbol,
+		Leverage:  g.leverage,
+		Price:     dec.Price,
+		Quantity:  dec.Quantity,
+		Timestamp: time.UnixMilli(ts).UTC(),
+	}
+
+	switch dec.Action {
+	case "place_buy_limit", "place_sell_limit":
+		side := strings.TrimSuffix(strings.TrimPrefix(dec.Action, "place_"), "_limit")
+		order, err := g.placeOrder(dec, side, ts)
+		if err != nil {
+			return actionRecord, nil, "", err
+		}
+		actionRecord.Quantity = order.Quantity
+		return actionRecord, nil, fmt.Sprintf("[Grid] Placed %s limit %s at %.4f, qty=%.4f, level=%d",
+			side, order.ID, order.Price, order.Quantity, dec.LevelIndex), nil
+
+	case "cancel_order":
+		if !g.account.CancelOrder(dec.OrderID) {
+			return actionRecord, nil, "", fmt.Errorf("order %s is not open", dec.OrderID)
+		}
+		for i := range g.state.Levels {
+			level := &g.state.Levels[i]
+			if level.ExitOrderID == dec.OrderID {
+				level.ExitOrderID = ""
+				level.ExitPrice = 0
+			} else if level.OrderID == dec.OrderID && level.State == "pending" {
+				level.State = "empty"
+				level.OrderID = ""
+				level.OrderQuantity = 0
+			}
+		}
+		return actionRecord, nil, "", nil
+
+	case "cancel_all_orders":
+		g.cancelAll()
+		return actionRecord, nil, "", nil
+
+	case "pause_grid":
+		g.cancelAll()
+		g.state.IsPaused = true
+		return actionRecord, nil, fmt.Sprintf("[Grid] Paused: %s", dec.Reasoning), nil
+
+	case "resume_grid":
+		g.state.IsPaused = false
+		return actionRecord, nil, "", nil
+
+	case "adjust_grid":
+		g.cancelAll()
+		g.regrid(price)
+		return actionRecord, nil, fmt.Sprintf("[Grid] Adjusted grid around %.4f", price), nil
+
+	case "hold":
+		return actionRecord, nil, fmt.Sprintf("hold: %s", dec.Reasoning), nil
+
+	default:
+		return actionRecord, nil, "", fmt.Errorf("unsupported grid action %s", dec.Action)
+	}
+}
+
+// placeOrder places the entry or paired exit order of a level. Entries are capped to the level
+// allocation and to total_investment × leverage across the position and the pending entries.
+func (g *gridEngine) placeOrder(dec kernel.Decision, side string, ts int64) (LimitOrder, error) {
+	if dec.LevelIndex < 0 || dec.LevelIndex >= len(g.state.Levels) {
+		return LimitOrder{}, fmt.Errorf("invalid level index %d", dec.LevelIndex)
+	}
+	level := &g.state.Levels[dec.LevelIndex]
+
+	// Paired exit of a filled level
+	if level.State == "filled" && level.Side != side {
+		if level.ExitOrderID != "" {
+			return LimitOrder{}, fmt.Errorf("level %d already has an exit order", dec.LevelIndex)
+		}
+		order, err := g.account.PlaceLimitOrder(g.symbol, side, dec.Price, dec.Quantity, g.leverage, ts)
+		if err != nil {
+			return LimitOrder{}, err
+		}
+		level.ExitOrderID = order.ID
+		level.ExitPrice = order.Price
+		return order, nil
+	}
+	if level.State != "empty" {
+		return LimitOrder{}, fmt.Errorf("level %d is %s", dec.LevelIndex, level.State)
+	}
+
+	quantity := dec.Quantity
+	if dec.Price > 0 && g.cfg.TotalInvestment > 0 {
+		maxMargin := g.cfg.TotalInvestment / float64(g.cfg.GridCount)
+		if level.AllocatedUSD > 0 && level.AllocatedUSD < maxMargin {
+			maxMargin = level.AllocatedUSD
+		}
+		if maxQuantity := maxMargin * float64(g.leverage) / dec.Price; quantity > maxQuantity {
+			quantity = maxQuantity
+		}
+
+		exposure := math.Abs(g.account.netPosition(g.symbol)) * dec.Price
+		for _, l := range g.state.Levels {
+			if l.State == "pending" {
+				exposure += l.OrderQuantity * l.Price
+			}
+		}
+		if limit := g.cfg.TotalInvestment * float64(g.leverage); exposure+quantity*dec.Price > limit {
+			return LimitOrder{}, fmt.Errorf("total position value $%.2f would exceed limit $%.2f", exposure+quantity*dec.Price, limit)
+		}
+	}
+
+	order, err := g.account.PlaceLimitOrder(g.symbol, side, dec.Price, quantity, g.leverage, ts)
+	if err != nil {
+		return LimitOrder{}, err
+	}
+	level.State = "pending"
+	level.Side = side
+	level.OrderID = order.ID
+	level.OrderQuantity = quantity
+	return order, nil
+}
+
+// metrics returns the grid metrics with the derived ratios filled in
+func (g *gridEngine) metrics() GridMetrics {
+	m := g.state.Metrics
+	if m.RoundTrips > 0 {
+		m.ProfitPerGrid = m.GridProfit / float64(m.RoundTrips)
+	}
+	if m.TotalBars > 0 {
+		m.TimeInRangePct = float64(m.BarsInRange) / float64(m.TotalBars) * 100
+	}
+	if g.state.StartTS > 0 {
+		if g.ledger == nil || g.ledgerTrips != len(g.state.RoundTrips) {
+			g.ledger = kernel.SummarizeGridLedger(g.state.RoundTrips, 0, 0, time.Time{}, time.Time{})
+			g.ledgerTrips = len(g.state.RoundTrips)
+		}
+		ledger := *g.ledger
+		ledger.Annualize(g.cfg.TotalInvestment, kernel.GridInventoryPnL(g.state.Levels, g.state.LastPrice),
+			time.UnixMilli(g.state.StartTS), time.UnixMilli(g.state.LastTS))
+		m.Ledger = &ledger
+	}
+	return m
+}
+
+// snapshot returns a copy of the grid state for checkpoints
+func (g *gridEngine) snapshot() *GridSnapshot {
+	snap := g.state
+	snap.Levels = append([]kernel.GridLevelInfo(nil), g.state.Levels...)
+	snap.RoundTrips = append([]kernel.GridRoundTrip(nil), g.state.RoundTrips...)
+	snap.Metrics = g.metrics()
+	return &snap
+}
+
+// restore resumes the grid state from a checkpoint
+func (g *gridEngine) restore(snap *GridSnapshot) {
+	g.state = *snap
+	g.state.Levels = append([]kernel.GridLevelInfo(nil), snap.Levels...)
+	g.state.RoundTrips = append([]kernel.GridRoundTrip(nil), snap.RoundTrips...)
+	g.ledger = nil
+}
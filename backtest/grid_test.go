@@ -0,0 +1,128 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// gridTestStep runs one bar through the engine and executes the deterministic decisions at its close
+func gridTestStep(t *testing.T, g *gridEngine, ts int64, bar market.Kline) []TradeEvent {
+	t.Helper()
+	data := &market.Data{Symbol: g.symbol, CurrentPrice: bar.Close}
+	events, _ := g.onBar(ts, &bar, data, 0)
+
+	ctx := g.buildContext(data, ts, kernel.AccountInfo{TotalEquity: 1000})
+	fd := kernel.GetDeterministicGridDecisions(ctx, g.cfg)
+	g.afterDecision(ctx, ts)
+	for _, dec := range fd.Decisions {
+		if _, _, _, err := g.executeDecision(dec, bar.Close, ts); err != nil {
+			t.Fatalf("execute %s at level %d: %v", dec.Action, dec.LevelIndex, err)
+		}
+	}
+	return events
+}
+
+func TestGridEngineRoundTrip(t *testing.T) {
+	cfg := &store.GridStrategyConfig{
+		Symbol: "BTCUSDT", GridCount: 5, TotalInvestment: 1000, Leverage: 2,
+		UpperPrice: 105, LowerPrice: 95, Mode: kernel.GridModeDeterministic,
+	}
+	acc := NewBacktestAccount(1000, 5, 0)
+	acc.SetMakerFee(2)
+	g := newGridEngine(cfg, 2, acc, nil)
+
+	// First bar lays out 95 / 97.5 / 100 / 102.5 / 105 and places the entries around 100
+	gridTestStep(t, g, 1, market.Kline{Open: 100, High: 100, Low: 100, Close: 100})
+	if len(g.state.Levels) != 5 || len(acc.OpenOrders("BTCUSDT")) != 4 {
+		t.Fatalf("got %d levels and %d orders, want 5 and 4", len(g.state.Levels), len(acc.OpenOrders("BTCUSDT")))
+	}
+
+	// The dip fills the 97.5 buy, its paired exit rests at 100
+	events := gridTestStep(t, g, 2, market.Kline{Open: 100, High: 100.5, Low: 97, Close: 98})
+	if len(events) != 1 || events[0].Action != "limit_buy" || g.state.Levels[1].State != "filled" {
+		t.Fatalf("expected the level 1 entry to fill: %+v", events)
+	}
+	if g.state.Levels[1].ExitOrderID == "" || g.state.Levels[1].ExitPrice != 100 {
+		t.Fatalf("paired exit not placed: %+v", g.state.Levels[1])
+	}
+
+	// The bounce fills the exit and completes the round trip
+	events = gridTestStep(t, g, 3, market.Kline{Open: 98, High: 100.2, Low: 97.8, Close: 100})
+	if len(events) != 1 || events[0].RealizedPnL <= 0 {
+		t.Fatalf("expected a profitable paired exit: %+v", events)
+	}
+	qty := 1000.0 / 5 * 2 / 97.5
+	wantProfit := 2.5*qty - (97.5+100)*qty*0.0002
+	m := g.metrics()
+	if m.RoundTrips != 1 || m.Fills != 2 || math.Abs(m.GridProfit-wantProfit) > 1e-9 || m.ProfitPerGrid != m.GridProfit {
+		t.Errorf("unexpected metrics after round trip: %+v (want profit %.6f)", m, wantProfit)
+	}
+	// The ledger matches the round trip at level 1 with its fills and fees
+	if m.Ledger == nil || m.Ledger.RoundTrips != 1 || len(m.Ledger.Levels) != 1 || m.Ledger.Levels[0].LevelIndex != 1 {
+		t.Fatalf("unexpected ledger: %+v", m.Ledger)
+	}
+	trip := g.state.RoundTrips[0]
+	if trip.EntryPrice != 97.5 || trip.ExitPrice != 100 || math.Abs(trip.NetPnL-wantProfit) > 1e-9 || trip.OpenedAt.UnixMilli() != 2 {
+		t.Errorf("unexpected round trip: %+v", trip)
+	}
+	if math.Abs(m.Ledger.NetPnL-m.GridProfit) > 1e-9 || math.Abs(m.Ledger.Fees-(97.5+100)*qty*0.0002) > 1e-9 {
+		t.Errorf("ledger totals %+v do not match the grid profit %.6f", m.Ledger, m.GridProfit)
+	}
+
+	// The freed level gets its entry again
+	if acc.netPosition("BTCUSDT") != 0 || g.state.Levels[1].State != "pending" {
+		t.Errorf("position should be flat and level 1 waiting again: %.4f, %s", acc.netPosition("BTCUSDT"), g.state.Levels[1].State)
+	}
+
+	// A close 4.8% above the range pauses the grid and cancels its orders
+	gridTestStep(t, g, 4, market.Kline{Open: 104, High: 110, Low: 104, Close: 110})
+	m = g.metrics()
+	if !g.state.IsPaused || m.Breakouts != 1 || len(acc.OpenOrders("")) != 0 {
+		t.Errorf("breakout should pause the grid: paused=%v breakouts=%d orders=%d", g.state.IsPaused, m.Breakouts, len(acc.OpenOrders("")))
+	}
+	if m.TotalBars != 3 || m.BarsInRange != 2 || math.Abs(m.TimeInRangePct-200.0/3) > 1e-9 || m.PausedBars != 1 {
+		t.Errorf("unexpected range metrics: %+v", m)
+	}
+
+	// Snapshots carry the state through checkpoints
+	restored := newGridEngine(cfg, 2, acc, nil)
+	restored.restore(g.snapshot())
+	if !restored.state.IsPaused || restored.metrics().RoundTrips != 1 || len(restored.state.Levels) != 5 ||
+		len(restored.state.RoundTrips) != 1 || restored.metrics().Ledger.RoundTrips != 1 {
+		t.Errorf("state not restored from snapshot: %+v", restored.state)
+	}
+}
+
+func TestGridEngineTrailingAndStopLoss(t *testing.T) {
+	cfg := &store.GridStrategyConfig{
+		Symbol: "BTCUSDT", GridCount: 5, TotalInvestment: 1000, Leverage: 2,
+		UpperPrice: 105, LowerPrice: 95, Mode: kernel.GridModeDeterministic,
+		Trailing: true, StopLossPct: 5,
+	}
+	acc := NewBacktestAccount(1000, 5, 0)
+	g := newGridEngine(cfg, 2, acc, nil)
+
+	gridTestStep(t, g, 1, market.Kline{Open: 100, High: 100, Low: 100, Close: 100})
+	gridTestStep(t, g, 2, market.Kline{Open: 100, High: 100, Low: 94, Close: 95.5})
+	if g.state.Levels[0].State != "filled" || g.state.Levels[1].State != "filled" {
+		t.Fatalf("expected the two lowest buys to fill: %+v", g.state.Levels)
+	}
+
+	// 91 is 6.7% below the 97.5 entry: that level is stopped, then the ladder trails down
+	events := gridTestStep(t, g, 3, market.Kline{Open: 95, High: 95, Low: 91, Close: 91})
+	m := g.metrics()
+	if m.StopLosses != 1 || len(events) == 0 || events[len(events)-1].Action != "close_long" {
+		t.Errorf("expected one stop loss: metrics %+v, events %+v", m, events)
+	}
+	if m.Ledger == nil || m.Ledger.StopLosses != 1 || m.Ledger.NetPnL >= 0 {
+		t.Errorf("stop loss not in the ledger: %+v", m.Ledger)
+	}
+	if m.Trails != 1 || g.state.LowerPrice != 90 || g.state.UpperPrice != 100 || g.state.IsPaused {
+		t.Errorf("expected the grid to trail to 90 - 100: %.2f - %.2f (trails %d, paused %v)",
+			g.state.LowerPrice, g.state.UpperPrice, m.Trails, g.state.IsPaused)
+	}
+}
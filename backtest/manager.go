@@ -44,8 +44,11 @@ func (m *Manager) Start(ctx context.Context, cfg BacktestConfig) (*Runner, error
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
-	if err := m.resolveAIConfig(&cfg); err != nil {
-		return nil, err
+	// Strategies without AI (e.g. rule_based) need no AI model
+	if cfg.RequiresAI() {
+		if err := m.resolveAIConfig(&cfg); err != nil {
+			return nil, err
+		}
 	}
 	if ctx == nil {
 		ctx = context.Background()
@@ -354,6 +357,19 @@ func (m *Manager) GetMetrics(runID string) (*Metrics, error) {
 	return LoadMetrics(runID)
 }
 
+// MonteCarlo runs a Monte Carlo robustness analysis over the persisted trade log of a run
+func (m *Manager) MonteCarlo(runID string, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	runCfg, err := LoadConfig(runID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := LoadTradeEvents(runID)
+	if err != nil {
+		return nil, err
+	}
+	return RunMonteCarlo(events, runCfg.InitialBalance, cfg)
+}
+
 func (m *Manager) Cleanup(runID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
+
+	"nofx/performance"
 )
 
 // CalculateMetrics reads existing logs and calculates summary metrics. state is optional, used to supplement information not yet persisted.
-func CalculateMetrics(runID string, cfg *BacktestConfig, state *BacktestState) (*Metrics, error) {
+// benchmark is the buy-and-hold benchmark over the backtest window (optional, used for alpha and beta).
+func CalculateMetrics(runID string, cfg *BacktestConfig, state *BacktestState, benchmark []performance.PricePoint) (*Metrics, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is nil")
 	}
@@ -41,11 +45,37 @@ func CalculateMetrics(runID string, cfg *BacktestConfig, state *BacktestState) (
 	}
 	metrics.TotalReturnPct = ((lastEquity - initialBalance) / initialBalance) * 100
 
-	metrics.MaxDrawdownPct = maxDrawdown(points, state)
-	metrics.SharpeRatio = sharpeRatio(points)
+	report := performance.Compute(performance.Input{
+		InitialEquity: initialBalance,
+		Equity:        performanceCurve(points),
+		Trades:        holdingPeriods(events),
+		Benchmark:     benchmark,
+	})
+	metrics.MaxDrawdownPct = report.MaxDrawdownPct
+	if state != nil && state.MaxDrawdownPct > metrics.MaxDrawdownPct {
+		metrics.MaxDrawdownPct = state.MaxDrawdownPct
+	}
+	metrics.SharpeRatio = report.SharpeRatio
+	metrics.AnnualReturnPct = report.AnnualReturnPct
+	metrics.SortinoRatio = report.SortinoRatio
+	metrics.CalmarRatio = report.CalmarRatio
+	metrics.MARRatio = report.MARRatio
+	metrics.UlcerIndex = report.UlcerIndex
+	metrics.ExposurePct = report.ExposurePct
+	metrics.AvgHoldingHours = report.AvgHoldingHours
+	metrics.Benchmark = report.Benchmark
+	metrics.BenchmarkReturnPct = report.BenchmarkReturnPct
+	metrics.Alpha = report.Alpha
+	metrics.Beta = report.Beta
+	metrics.MonthlyReturns = report.MonthlyReturns
 
 	fillTradeMetrics(metrics, events)
 
+	if state != nil && state.Grid != nil {
+		grid := *state.Grid
+		metrics.Grid = &grid
+	}
+
 	return metrics, nil
 }
 
@@ -61,94 +91,35 @@ func determineLiquidation(events []TradeEvent, state *BacktestState) bool {
 	return false
 }
 
-func maxDrawdown(points []EquityPoint, state *BacktestState) float64 {
-	if len(points) == 0 {
-		if state != nil {
-			return state.MaxDrawdownPct
-		}
-		return 0
-	}
-	peak := points[0].Equity
-	if peak <= 0 {
-		peak = 1
-	}
-	maxDD := 0.0
-	for _, pt := range points {
-		if pt.Equity > peak {
-			peak = pt.Equity
-		}
-		if peak <= 0 {
-			continue
-		}
-		dd := (peak - pt.Equity) / peak * 100
-		if dd > maxDD {
-			maxDD = dd
-		}
-	}
-	if state != nil && state.MaxDrawdownPct > maxDD {
-		maxDD = state.MaxDrawdownPct
+// performanceCurve converts the equity log into the metric engine equity curve
+func performanceCurve(points []EquityPoint) []performance.EquityPoint {
+	curve := make([]performance.EquityPoint, len(points))
+	for i, pt := range points {
+		curve[i] = performance.EquityPoint{Time: time.UnixMilli(pt.Timestamp).UTC(), Equity: pt.Equity}
 	}
-	return maxDD
+	return curve
 }
 
-// sharpeRatio calculates the Sharpe ratio from equity points.
-// Uses sample standard deviation (n-1) and annualizes assuming ~252 trading days.
-// Returns math.NaN() for edge cases (insufficient data, zero variance).
-func sharpeRatio(points []EquityPoint) float64 {
-	// Need at least 10 data points for meaningful Sharpe calculation
-	const minDataPoints = 10
-	if len(points) < minDataPoints {
-		return 0
-	}
-
-	returns := make([]float64, 0, len(points)-1)
-	prev := points[0].Equity
-	for i := 1; i < len(points); i++ {
-		curr := points[i].Equity
-		if prev <= 0 {
-			prev = curr
-			continue
+// holdingPeriods rebuilds the flat-to-flat holding periods of every symbol and side from the trade log
+func holdingPeriods(events []TradeEvent) []performance.Trade {
+	var trades []performance.Trade
+	opened := make(map[string]time.Time)
+	for _, evt := range events {
+		key := evt.Symbol + "_" + evt.Side
+		ts := time.UnixMilli(evt.Timestamp).UTC()
+		openedAt, open := opened[key]
+		switch {
+		case evt.PositionAfter > 1e-12 && !open:
+			opened[key] = ts
+		case evt.PositionAfter <= 1e-12 && open:
+			trades = append(trades, performance.Trade{OpenedAt: openedAt, ClosedAt: ts})
+			delete(opened, key)
 		}
-		ret := (curr - prev) / prev
-		returns = append(returns, ret)
-		prev = curr
-	}
-	if len(returns) < minDataPoints-1 {
-		return 0
 	}
-
-	// Calculate mean return
-	mean := 0.0
-	for _, r := range returns {
-		mean += r
-	}
-	mean /= float64(len(returns))
-
-	// Calculate sample variance (using n-1 for unbiased estimator)
-	variance := 0.0
-	for _, r := range returns {
-		diff := r - mean
-		variance += diff * diff
+	for _, openedAt := range opened {
+		trades = append(trades, performance.Trade{OpenedAt: openedAt})
 	}
-	if len(returns) > 1 {
-		variance /= float64(len(returns) - 1)
-	}
-
-	std := math.Sqrt(variance)
-	if std < 1e-10 {
-		// Zero or near-zero volatility - return 0 instead of infinity/NaN
-		return 0
-	}
-
-	// Calculate Sharpe ratio (assuming risk-free rate = 0 for crypto)
-	// Annualize by multiplying by sqrt(periods per year)
-	// Assuming each equity point represents ~1 hour, we have ~8760 periods/year
-	// For conservative estimate, use sqrt(252) as if daily returns
-	periodsPerYear := 252.0
-	annualizationFactor := math.Sqrt(periodsPerYear)
-
-	sharpe := (mean / std) * annualizationFactor
-	return sharpe
+	return trades
 }
 
 func fillTradeMetrics(metrics *Metrics, events []TradeEvent) {
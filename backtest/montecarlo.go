@@ -0,0 +1,271 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Monte Carlo resampling methods (MonteCarloConfig.Method)
+const (
+	MonteCarloShuffle   = "shuffle"   // Reorder the trades (without replacement)
+	MonteCarloBootstrap = "bootstrap" // Draw trades with replacement
+)
+
+const (
+	defaultMonteCarloSimulations = 1000
+	maxMonteCarloSimulations     = 10000
+	defaultRuinThresholdPct      = 50
+	monteCarloBandPoints         = 100 // Max trade steps reported in the equity bands
+)
+
+// MonteCarloConfig controls a Monte Carlo robustness analysis of a run's trade sequence
+type MonteCarloConfig struct {
+	Simulations      int     `json:"simulations"`
+	Method           string  `json:"method"`             // "shuffle" (default) or "bootstrap"
+	SkipProbability  float64 `json:"skip_probability"`   // Chance that each trade is not taken (0-0.9)
+	RuinThresholdPct float64 `json:"ruin_threshold_pct"` // Loss of initial balance counted as ruin (default 50%)
+	Seed             int64   `json:"seed"`               // Same seed and trades give the same distribution (0 = 1)
+}
+
+// Normalize fills in defaults and validates the configuration
+func (c *MonteCarloConfig) Normalize() error {
+	if c.Simulations <= 0 {
+		c.Simulations = defaultMonteCarloSimulations
+	}
+	if c.Simulations > maxMonteCarloSimulations {
+		return fmt.Errorf("simulations cannot exceed %d", maxMonteCarloSimulations)
+	}
+	c.Method = strings.ToLower(strings.TrimSpace(c.Method))
+	switch c.Method {
+	case "":
+		c.Method = MonteCarloShuffle
+	case MonteCarloShuffle, MonteCarloBootstrap:
+	default:
+		return fmt.Errorf("method must be '%s' or '%s'", MonteCarloShuffle, MonteCarloBootstrap)
+	}
+	if c.SkipProbability < 0 || c.SkipProbability > 0.9 {
+		return fmt.Errorf("skip_probability must be between 0 and 0.9")
+	}
+	if c.RuinThresholdPct == 0 {
+		c.RuinThresholdPct = defaultRuinThresholdPct
+	}
+	if c.RuinThresholdPct < 0 || c.RuinThresholdPct > 100 {
+		return fmt.Errorf("ruin_threshold_pct must be between 0 and 100")
+	}
+	if c.Seed == 0 {
+		c.Seed = 1
+	}
+	return nil
+}
+
+// MonteCarloDistribution summary and percentile bands of one simulated quantity
+type MonteCarloDistribution struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	P5   float64 `json:"p5"`
+	P25  float64 `json:"p25"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P95  float64 `json:"p95"`
+	Max  float64 `json:"max"`
+}
+
+// MonteCarloBand equity percentiles of all simulated paths after a number of trades
+type MonteCarloBand struct {
+	Trade int     `json:"trade"`
+	P5    float64 `json:"p5"`
+	P25   float64 `json:"p25"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P95   float64 `json:"p95"`
+}
+
+// MonteCarloResult distributions of final return, max drawdown and risk of ruin over the simulated paths
+type MonteCarloResult struct {
+	Config               MonteCarloConfig       `json:"config"`
+	Trades               int                    `json:"trades"`
+	InitialBalance       float64                `json:"initial_balance"`
+	ActualReturnPct      float64                `json:"actual_return_pct"` // The backtest's own trade order
+	ActualMaxDrawdownPct float64                `json:"actual_max_drawdown_pct"`
+	FinalReturnPct       MonteCarloDistribution `json:"final_return_pct"`
+	MaxDrawdownPct       MonteCarloDistribution `json:"max_drawdown_pct"`
+	RiskOfRuinPct        float64                `json:"risk_of_ruin_pct"` // Share of paths that lost RuinThresholdPct of the initial balance
+	ProbabilityOfLossPct float64                `json:"probability_of_loss_pct"`
+	EquityBands          []MonteCarloBand       `json:"equity_bands"`
+}
+
+// RunMonteCarlo resamples the trade results of a run's trade log and simulates the equity paths.
+// It runs offline on the persisted trades, never inside the simulation loop.
+func RunMonteCarlo(events []TradeEvent, initialBalance float64, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if err := cfg.Normalize(); err != nil {
+		return nil, err
+	}
+	if initialBalance <= 0 {
+		return nil, fmt.Errorf("initial balance must be positive")
+	}
+	trades := tradeResults(events)
+	if len(trades) == 0 {
+		return nil, fmt.Errorf("run has no closed trades")
+	}
+
+	result := &MonteCarloResult{
+		Config:         cfg,
+		Trades:         len(trades),
+		InitialBalance: initialBalance,
+	}
+	ruinEquity := initialBalance * (1 - cfg.RuinThresholdPct/100)
+	actual := simulateTradePath(trades, initialBalance, ruinEquity, nil)
+	result.ActualReturnPct = actual.returnPct
+	result.ActualMaxDrawdownPct = actual.maxDrawdownPct
+
+	// Equity band checkpoints: at most monteCarloBandPoints trade steps, always including the last
+	step := (len(trades) + monteCarloBandPoints - 1) / monteCarloBandPoints
+	var checkpoints []int
+	for n := step; n < len(trades); n += step {
+		checkpoints = append(checkpoints, n)
+	}
+	checkpoints = append(checkpoints, len(trades))
+	bandEquity := make([][]float64, len(checkpoints))
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	returns := make([]float64, cfg.Simulations)
+	drawdowns := make([]float64, cfg.Simulations)
+	sample := make([]float64, len(trades))
+	ruined, losses := 0, 0
+	for sim := 0; sim < cfg.Simulations; sim++ {
+		resampleTrades(rng, trades, sample, cfg)
+		path := simulateTradePath(sample, initialBalance, ruinEquity, checkpoints)
+		returns[sim] = path.returnPct
+		drawdowns[sim] = path.maxDrawdownPct
+		if path.ruined {
+			ruined++
+		}
+		if path.returnPct < 0 {
+			losses++
+		}
+		for i, equity := range path.checkpointEquity {
+			bandEquity[i] = append(bandEquity[i], equity)
+		}
+	}
+
+	result.FinalReturnPct = distribution(returns)
+	result.MaxDrawdownPct = distribution(drawdowns)
+	result.RiskOfRuinPct = float64(ruined) / float64(cfg.Simulations) * 100
+	result.ProbabilityOfLossPct = float64(losses) / float64(cfg.Simulations) * 100
+	result.EquityBands = make([]MonteCarloBand, len(checkpoints))
+	for i, n := range checkpoints {
+		sort.Float64s(bandEquity[i])
+		result.EquityBands[i] = MonteCarloBand{
+			Trade: n,
+			P5:    percentile(bandEquity[i], 5),
+			P25:   percentile(bandEquity[i], 25),
+			P50:   percentile(bandEquity[i], 50),
+			P75:   percentile(bandEquity[i], 75),
+			P95:   percentile(bandEquity[i], 95),
+		}
+	}
+	return result, nil
+}
+
+// tradeResults net result of every closed trade in the log. The realized PnL of a close is already
+// net of its fee, which includes the opening fee of the closed quantity.
+func tradeResults(events []TradeEvent) []float64 {
+	var results []float64
+	for _, evt := range events {
+		if evt.LiquidationFlag || strings.HasPrefix(evt.Action, "close") || evt.RealizedPnL != 0 {
+			results = append(results, evt.RealizedPnL)
+		}
+	}
+	return results
+}
+
+// resampleTrades fills sample with the trades reordered (shuffle) or drawn with replacement (bootstrap);
+// skipped trades contribute nothing
+func resampleTrades(rng *rand.Rand, trades, sample []float64, cfg MonteCarloConfig) {
+	if cfg.Method == MonteCarloBootstrap {
+		for i := range sample {
+			sample[i] = trades[rng.Intn(len(trades))]
+		}
+	} else {
+		copy(sample, trades)
+		rng.Shuffle(len(sample), func(i, j int) { sample[i], sample[j] = sample[j], sample[i] })
+	}
+	if cfg.SkipProbability > 0 {
+		for i := range sample {
+			if rng.Float64() < cfg.SkipProbability {
+				sample[i] = 0
+			}
+		}
+	}
+}
+
+type tradePath struct {
+	returnPct        float64
+	maxDrawdownPct   float64
+	ruined           bool
+	checkpointEquity []float64
+}
+
+// simulateTradePath applies the trade results in order from the initial balance. An account whose
+// equity reaches zero stops trading.
+func simulateTradePath(trades []float64, initialBalance, ruinEquity float64, checkpoints []int) tradePath {
+	path := tradePath{checkpointEquity: make([]float64, 0, len(checkpoints))}
+	equity, peak := initialBalance, initialBalance
+	next := 0
+	for i, pnl := range trades {
+		if equity > 0 {
+			equity = math.Max(equity+pnl, 0)
+		}
+		if equity > peak {
+			peak = equity
+		}
+		if dd := (peak - equity) / peak * 100; dd > path.maxDrawdownPct {
+			path.maxDrawdownPct = dd
+		}
+		if equity <= ruinEquity {
+			path.ruined = true
+		}
+		if next < len(checkpoints) && checkpoints[next] == i+1 {
+			path.checkpointEquity = append(path.checkpointEquity, equity)
+			next++
+		}
+	}
+	path.returnPct = (equity - initialBalance) / initialBalance * 100
+	return path
+}
+
+func distribution(values []float64) MonteCarloDistribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return MonteCarloDistribution{
+		Mean: sum / float64(len(sorted)),
+		Min:  sorted[0],
+		P5:   percentile(sorted, 5),
+		P25:  percentile(sorted, 25),
+		P50:  percentile(sorted, 50),
+		P75:  percentile(sorted, 75),
+		P95:  percentile(sorted, 95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// percentile linear interpolation percentile of sorted values
+func percentile(sorted []float64, pct float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := pct / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,75 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+)
+
+// monteCarloEvents opens and closes one position per trade result (1 USDT fee on each fill)
+func monteCarloEvents(results ...float64) []TradeEvent {
+	var events []TradeEvent
+	for i, pnl := range results {
+		ts := int64(i) * 2
+		events = append(events,
+			TradeEvent{Timestamp: ts, Symbol: "BTCUSDT", Action: "open_long", Side: "long", Fee: 1, PositionAfter: 1},
+			TradeEvent{Timestamp: ts + 1, Symbol: "BTCUSDT", Action: "close_long", Side: "long", Fee: 2, RealizedPnL: pnl},
+		)
+	}
+	return events
+}
+
+func TestMonteCarloShuffle(t *testing.T) {
+	events := monteCarloEvents(100, -300, 50, -300, 200, 150)
+	if got := tradeResults(events); len(got) != 6 || got[1] != -300 {
+		t.Fatalf("trade results should not count the opening fee twice: %v", got)
+	}
+
+	result, err := RunMonteCarlo(events, 1000, MonteCarloConfig{Simulations: 500, RuinThresholdPct: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reordering never changes the final return, only the path
+	for _, v := range []float64{result.FinalReturnPct.Min, result.FinalReturnPct.P50, result.FinalReturnPct.Max} {
+		if math.Abs(v+10) > 1e-9 {
+			t.Errorf("shuffled final return = %.4f, want -10", v)
+		}
+	}
+	if result.ActualMaxDrawdownPct <= 0 || result.MaxDrawdownPct.Min > result.ActualMaxDrawdownPct || result.MaxDrawdownPct.Max < result.ActualMaxDrawdownPct {
+		t.Errorf("actual drawdown %.2f outside the simulated range %+v", result.ActualMaxDrawdownPct, result.MaxDrawdownPct)
+	}
+	// Both -300 losses back to back from 1000 reach the 600 ruin line only in some orders
+	if result.RiskOfRuinPct <= 0 || result.RiskOfRuinPct >= 100 {
+		t.Errorf("risk of ruin = %.1f%%, want some but not all paths", result.RiskOfRuinPct)
+	}
+	last := result.EquityBands[len(result.EquityBands)-1]
+	if last.Trade != 6 || math.Abs(last.P5-900) > 1e-9 || math.Abs(last.P95-900) > 1e-9 {
+		t.Errorf("unexpected final band: %+v", last)
+	}
+
+	again, _ := RunMonteCarlo(events, 1000, MonteCarloConfig{Simulations: 500, RuinThresholdPct: 40})
+	if again.RiskOfRuinPct != result.RiskOfRuinPct || again.MaxDrawdownPct != result.MaxDrawdownPct {
+		t.Error("the same seed should give the same distribution")
+	}
+}
+
+func TestMonteCarloBootstrapAndSkip(t *testing.T) {
+	events := monteCarloEvents(100, -50, 80, -20, 60)
+	result, err := RunMonteCarlo(events, 1000, MonteCarloConfig{Simulations: 2000, Method: "bootstrap", SkipProbability: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FinalReturnPct.P5 >= result.FinalReturnPct.P95 {
+		t.Errorf("bootstrap should spread the final return: %+v", result.FinalReturnPct)
+	}
+	// Mean trade is +34: with half the trades skipped the expected return is 5 × 17 = 85 (8.5%)
+	if math.Abs(result.FinalReturnPct.Mean-8.5) > 1 {
+		t.Errorf("mean final return = %.2f%%, want about 8.5%%", result.FinalReturnPct.Mean)
+	}
+
+	if _, err := RunMonteCarlo(events, 1000, MonteCarloConfig{Method: "random"}); err == nil {
+		t.Error("expected an unknown method to be rejected")
+	}
+	if _, err := RunMonteCarlo(nil, 1000, MonteCarloConfig{}); err == nil {
+		t.Error("expected a run without trades to be rejected")
+	}
+}
@@ -0,0 +1,189 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"nofx/market"
+)
+
+// LimitOrder is a resting limit order of the simulated account (used by grid backtests).
+type LimitOrder struct {
+	ID        string  `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Side      string  `json:"side"` // "buy" or "sell"
+	Price     float64 `json:"price"`
+	Quantity  float64 `json:"quantity"`
+	Leverage  int     `json:"leverage"`
+	CreatedTS int64   `json:"created_ts"`
+}
+
+// LimitFill is the result of a limit order filled by a bar. Fills net against the opposite
+// position first (one-way mode), the remainder opens or adds to a position.
+type LimitFill struct {
+	Order         LimitOrder
+	Closed        float64 // Quantity that reduced the opposite position
+	Opened        float64 // Quantity that opened or added to a position
+	Fee           float64 // Maker fee (plus the opening fee portion of the closed quantity)
+	RealizedPnL   float64 // Realized PnL of the closed quantity, before fees
+	PositionAfter float64 // Signed net position after the fill (long > 0)
+	Err           error   // Set when the opening part could not be funded
+}
+
+// PlaceLimitOrder adds a resting limit order and returns it with its assigned ID
+func (acc *BacktestAccount) PlaceLimitOrder(symbol, side string, price, quantity float64, leverage int, ts int64) (LimitOrder, error) {
+	side = strings.ToLower(side)
+	if side != "buy" && side != "sell" {
+		return LimitOrder{}, fmt.Errorf("invalid order side: %s", side)
+	}
+	if price <= 0 || quantity <= 0 {
+		return LimitOrder{}, fmt.Errorf("limit order price and quantity must be positive")
+	}
+	if leverage <= 0 {
+		return LimitOrder{}, fmt.Errorf("leverage must be positive")
+	}
+
+	acc.nextOrderID++
+	order := LimitOrder{
+		ID:        fmt.Sprintf("bt-%d", acc.nextOrderID),
+		Symbol:    strings.ToUpper(symbol),
+		Side:      side,
+		Price:     price,
+		Quantity:  quantity,
+		Leverage:  leverage,
+		CreatedTS: ts,
+	}
+	acc.orders = append(acc.orders, order)
+	return order, nil
+}
+
+// CancelOrder removes a resting order, returns false if it is not open
+func (acc *BacktestAccount) CancelOrder(orderID string) bool {
+	for i, order := range acc.orders {
+		if order.ID == orderID {
+			acc.orders = append(acc.orders[:i], acc.orders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// CancelOrders removes all resting orders of a symbol and returns how many were cancelled
+func (acc *BacktestAccount) CancelOrders(symbol string) int {
+	symbol = strings.ToUpper(symbol)
+	kept := acc.orders[:0]
+	for _, order := range acc.orders {
+		if order.Symbol != symbol {
+			kept = append(kept, order)
+		}
+	}
+	cancelled := len(acc.orders) - len(kept)
+	acc.orders = kept
+	return cancelled
+}
+
+// OpenOrders returns the resting orders of a symbol (all symbols when empty)
+func (acc *BacktestAccount) OpenOrders(symbol string) []LimitOrder {
+	symbol = strings.ToUpper(symbol)
+	list := make([]LimitOrder, 0, len(acc.orders))
+	for _, order := range acc.orders {
+		if symbol == "" || order.Symbol == symbol {
+			list = append(list, order)
+		}
+	}
+	return list
+}
+
+// RestoreOrders replaces the resting orders (checkpoint recovery)
+func (acc *BacktestAccount) RestoreOrders(orders []LimitOrder) {
+	acc.orders = append([]LimitOrder(nil), orders...)
+	for _, order := range orders {
+		var n int
+		if _, err := fmt.Sscanf(order.ID, "bt-%d", &n); err == nil && n > acc.nextOrderID {
+			acc.nextOrderID = n
+		}
+	}
+}
+
+// MatchOrders fills the resting orders of a symbol crossed by a bar: a buy fills when the low reaches
+// its price, a sell when the high does. Fills happen at the limit price with the maker fee, in the
+// order the price most likely travelled: open → low → high → close for a bullish bar (buys first,
+// highest price first), open → high → low → close for a bearish bar.
+func (acc *BacktestAccount) MatchOrders(symbol string, bar market.Kline) []LimitFill {
+	symbol = strings.ToUpper(symbol)
+	var buys, sells []LimitOrder
+	remaining := acc.orders[:0]
+	for _, order := range acc.orders {
+		switch {
+		case order.Symbol != symbol:
+			remaining = append(remaining, order)
+		case order.Side == "buy" && bar.Low > 0 && bar.Low <= order.Price:
+			buys = append(buys, order)
+		case order.Side == "sell" && bar.High >= order.Price:
+			sells = append(sells, order)
+		default:
+			remaining = append(remaining, order)
+		}
+	}
+	acc.orders = remaining
+	if len(buys) == 0 && len(sells) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(buys, func(i, j int) bool { return buys[i].Price > buys[j].Price })
+	sort.SliceStable(sells, func(i, j int) bool { return sells[i].Price < sells[j].Price })
+	sequence := append(buys, sells...)
+	if bar.Close < bar.Open {
+		sequence = append(sells, buys...)
+	}
+
+	fills := make([]LimitFill, 0, len(sequence))
+	for _, order := range sequence {
+		fills = append(fills, acc.fillLimitOrder(order))
+	}
+	return fills
+}
+
+// fillLimitOrder executes a crossed limit order at its price: the opposite position is reduced
+// first, the rest opens or adds to the position on the order side
+func (acc *BacktestAccount) fillLimitOrder(order LimitOrder) LimitFill {
+	fill := LimitFill{Order: order}
+	openSide, closeSide := "long", "short"
+	if order.Side == "sell" {
+		openSide, closeSide = "short", "long"
+	}
+
+	quantity := order.Quantity
+	if pos, ok := acc.positions[positionKey(order.Symbol, closeSide)]; ok && pos.Quantity > epsilon {
+		closeQty := math.Min(quantity, pos.Quantity)
+		realized, fee := acc.closeAt(pos, closeQty, order.Price, acc.makerFeeRate)
+		fill.Closed = closeQty
+		fill.RealizedPnL = realized
+		fill.Fee += fee
+		quantity -= closeQty
+	}
+	if quantity > epsilon {
+		if _, fee, err := acc.openAt(order.Symbol, openSide, quantity, order.Leverage, order.Price, acc.makerFeeRate, order.CreatedTS); err != nil {
+			fill.Err = err
+		} else {
+			fill.Opened = quantity
+			fill.Fee += fee
+		}
+	}
+	fill.PositionAfter = acc.netPosition(order.Symbol)
+	return fill
+}
+
+// netPosition returns the signed position of a symbol (long > 0, short < 0)
+func (acc *BacktestAccount) netPosition(symbol string) float64 {
+	net := 0.0
+	if pos, ok := acc.positions[positionKey(symbol, "long")]; ok {
+		net += pos.Quantity
+	}
+	if pos, ok := acc.positions[positionKey(symbol, "short")]; ok {
+		net -= pos.Quantity
+	}
+	return net
+}
@@ -0,0 +1,84 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"nofx/market"
+)
+
+func TestMatchOrdersFollowsBarPath(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		bar       market.Kline
+		firstSide string
+		openSide  string
+	}{
+		{"bullish bar buys first", market.Kline{Open: 102, High: 106, Low: 99, Close: 105}, "buy", "long"},
+		{"bearish bar sells first", market.Kline{Open: 102, High: 106, Low: 99, Close: 100}, "sell", "short"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			acc := NewBacktestAccount(1000, 5, 10)
+			acc.SetMakerFee(2)
+			if _, err := acc.PlaceLimitOrder("BTCUSDT", "buy", 100, 1, 10, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := acc.PlaceLimitOrder("BTCUSDT", "sell", 105, 1, 10, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			fills := acc.MatchOrders("BTCUSDT", tc.bar)
+			if len(fills) != 2 || fills[0].Order.Side != tc.firstSide {
+				t.Fatalf("unexpected fills: %+v", fills)
+			}
+			if fills[0].Opened != 1 || acc.positionQuantity("BTCUSDT", tc.openSide) != 0 {
+				t.Errorf("first fill should open a %s that the second closes: %+v", tc.openSide, fills)
+			}
+			second := fills[1]
+			if second.Closed != 1 || second.Opened != 0 || second.PositionAfter != 0 {
+				t.Errorf("second fill should net the position: %+v", second)
+			}
+			// Limit fills ignore slippage and pay the maker fee on both sides
+			wantFee := (100 + 105) * 0.0002
+			if second.RealizedPnL != 5 || math.Abs(second.Fee-wantFee) > 1e-9 {
+				t.Errorf("realized %.4f fee %.6f, want 5 and %.6f", second.RealizedPnL, second.Fee, wantFee)
+			}
+			if math.Abs(acc.Cash()-(1000+5-wantFee)) > 1e-9 {
+				t.Errorf("cash = %.6f, want %.6f", acc.Cash(), 1000+5-wantFee)
+			}
+			if len(acc.OpenOrders("")) != 0 {
+				t.Errorf("filled orders still open: %+v", acc.OpenOrders(""))
+			}
+		})
+	}
+}
+
+func TestLimitOrderBook(t *testing.T) {
+	acc := NewBacktestAccount(100, 5, 0)
+	buy, _ := acc.PlaceLimitOrder("BTCUSDT", "buy", 90, 1, 1, 0)
+	acc.PlaceLimitOrder("ETHUSDT", "sell", 2000, 1, 1, 0)
+	if _, err := acc.PlaceLimitOrder("BTCUSDT", "long", 90, 1, 1, 0); err == nil {
+		t.Error("expected invalid side to be rejected")
+	}
+
+	if fills := acc.MatchOrders("BTCUSDT", market.Kline{Open: 95, High: 96, Low: 91, Close: 92}); len(fills) != 0 {
+		t.Errorf("untouched order filled: %+v", fills)
+	}
+
+	// 100 USDT covers one unleveraged buy, the second one cannot be funded
+	acc.PlaceLimitOrder("BTCUSDT", "buy", 89, 1, 1, 0)
+	fills := acc.MatchOrders("BTCUSDT", market.Kline{Open: 92, High: 92, Low: 88, Close: 88})
+	if len(fills) != 2 || fills[0].Order.ID != buy.ID || fills[0].Err != nil || fills[1].Err == nil {
+		t.Errorf("expected the higher buy to fill and the second to fail on margin: %+v", fills)
+	}
+
+	if n := acc.CancelOrders("ETHUSDT"); n != 1 || len(acc.OpenOrders("")) != 0 {
+		t.Errorf("cancelled %d orders, %d left", n, len(acc.OpenOrders("")))
+	}
+
+	acc.RestoreOrders([]LimitOrder{{ID: "bt-7", Symbol: "BTCUSDT", Side: "buy", Price: 80, Quantity: 0.1, Leverage: 1}})
+	next, _ := acc.PlaceLimitOrder("BTCUSDT", "buy", 79, 0.1, 1, 0)
+	if next.ID != "bt-8" || !acc.CancelOrder("bt-7") || acc.CancelOrder("bt-7") {
+		t.Errorf("restored order IDs not continued: %s", next.ID)
+	}
+}
@@ -0,0 +1,446 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// Trade drift status (TradeDrift.Status)
+const (
+	DriftMatched         = "matched"
+	DriftMissedLive      = "missed_live"      // Filled in the simulation, never live
+	DriftMissedSimulated = "missed_simulated" // Filled live, not in the simulation
+)
+
+// driftMatchBars live and simulated entries this many decision bars apart still belong to the same trade
+// (a recorded decision fills on the first decision bar at or after it)
+const driftMatchBars = 2
+
+// liveReplay serves the recorded decisions of a live trader in place of AI calls
+type liveReplay struct {
+	records []*store.DecisionRecord // Oldest first
+}
+
+func newLiveReplay(records []*store.DecisionRecord) *liveReplay {
+	sorted := append([]*store.DecisionRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	return &liveReplay{records: sorted}
+}
+
+// decisionsBetween merges the decisions recorded in (from, to] (Unix ms) into one cycle, keeping the
+// prompts of the latest record. Returns nil when nothing was decided in the window.
+func (lr *liveReplay) decisionsBetween(from, to int64) *kernel.FullDecision {
+	i := sort.Search(len(lr.records), func(i int) bool { return lr.records[i].Timestamp.UnixMilli() > from })
+	var full *kernel.FullDecision
+	for ; i < len(lr.records) && lr.records[i].Timestamp.UnixMilli() <= to; i++ {
+		rec := lr.records[i]
+		decisions := liveRecordDecisions(rec)
+		if len(decisions) == 0 {
+			continue
+		}
+		if full == nil {
+			full = &kernel.FullDecision{}
+		}
+		full.SystemPrompt = rec.SystemPrompt
+		full.UserPrompt = rec.InputPrompt
+		full.CoTTrace = rec.CoTTrace
+		full.RawResponse = rec.RawResponse
+		full.Timestamp = rec.Timestamp
+		full.Decisions = append(full.Decisions, decisions...)
+	}
+	return full
+}
+
+// liveRecordDecisions the AI decisions of a live record, rebuilt from its executed actions when the
+// decision JSON is missing
+func liveRecordDecisions(rec *store.DecisionRecord) []kernel.Decision {
+	var decisions []kernel.Decision
+	if rec.DecisionJSON != "" && json.Unmarshal([]byte(rec.DecisionJSON), &decisions) == nil && len(decisions) > 0 {
+		return decisions
+	}
+	decisions = decisions[:0]
+	for _, act := range rec.Decisions {
+		decisions = append(decisions, kernel.Decision{
+			Symbol:          act.Symbol,
+			Action:          act.Action,
+			Leverage:        act.Leverage,
+			PositionSizeUSD: act.Quantity * act.Price,
+			StopLoss:        act.StopLoss,
+			TakeProfit:      act.TakeProfit,
+			Confidence:      act.Confidence,
+			Reasoning:       act.Reasoning,
+		})
+	}
+	return decisions
+}
+
+// liveDecisionSymbols the sorted symbols traded by the decisions of live records
+func liveDecisionSymbols(records []*store.DecisionRecord) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, rec := range records {
+		for _, dec := range liveRecordDecisions(rec) {
+			if dec.Symbol == "" || dec.Action == "hold" || dec.Action == "wait" {
+				continue
+			}
+			if sym := market.Normalize(dec.Symbol); !seen[sym] {
+				seen[sym] = true
+				symbols = append(symbols, sym)
+			}
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// TradeDrift compares one live trade with its replay under the backtest fill model. Simulated amounts
+// are scaled to the live quantity, and price gaps are signed so that a positive gap cost the live trade.
+// Exit and PnL fields are only compared once both sides are closed.
+type TradeDrift struct {
+	Symbol           string  `json:"symbol"`
+	Side             string  `json:"side"`
+	Status           string  `json:"status"`
+	LiveEntryTime    int64   `json:"live_entry_time,omitempty"`
+	SimEntryTime     int64   `json:"sim_entry_time,omitempty"`
+	LiveExitTime     int64   `json:"live_exit_time,omitempty"`
+	SimExitTime      int64   `json:"sim_exit_time,omitempty"`
+	LiveQuantity     float64 `json:"live_quantity,omitempty"`
+	SimQuantity      float64 `json:"sim_quantity,omitempty"`
+	LiveEntryPrice   float64 `json:"live_entry_price,omitempty"`
+	SimEntryPrice    float64 `json:"sim_entry_price,omitempty"`
+	EntryGapBps      float64 `json:"entry_gap_bps"`
+	LiveExitPrice    float64 `json:"live_exit_price,omitempty"`
+	SimExitPrice     float64 `json:"sim_exit_price,omitempty"`
+	ExitGapBps       float64 `json:"exit_gap_bps"`
+	SlippageCost     float64 `json:"slippage_cost"` // Entry and exit price gaps × live quantity
+	LiveFee          float64 `json:"live_fee"`
+	SimFee           float64 `json:"sim_fee"`
+	FeeGap           float64 `json:"fee_gap"`
+	LivePnL          float64 `json:"live_pnl"` // Net of fees and funding
+	SimPnL           float64 `json:"sim_pnl"`  // Net of fees
+	PnLGap           float64 `json:"pnl_gap"`  // Live - simulated: negative when live did worse
+	LiveCloseReason  string  `json:"live_close_reason,omitempty"`
+	StopExit         bool    `json:"stop_exit"`                    // Closed live by a stop or take-profit order, which backtests do not place
+	ExitDelayMinutes float64 `json:"exit_delay_minutes,omitempty"` // Simulated exit - live exit
+	Open             bool    `json:"open"`                         // Still open on either side: exit and PnL not compared
+}
+
+// ExecutionDriftSummary totals of a live replay. The PnL gap of the matched trades splits into
+// price slippage, fees and the rest (exit timing, stop behaviour, funding):
+// ExecutionGap = -SlippageCost - FeeGap + OtherGap.
+type ExecutionDriftSummary struct {
+	LiveTrades         int     `json:"live_trades"`
+	SimulatedTrades    int     `json:"simulated_trades"`
+	Matched            int     `json:"matched"`
+	MissedLive         int     `json:"missed_live"`
+	MissedSimulated    int     `json:"missed_simulated"`
+	AvgEntryGapBps     float64 `json:"avg_entry_gap_bps"`
+	AvgExitGapBps      float64 `json:"avg_exit_gap_bps"`
+	LivePnL            float64 `json:"live_pnl"` // Matched closed trades
+	SimPnL             float64 `json:"sim_pnl"`
+	ExecutionGap       float64 `json:"execution_gap"`
+	SlippageCost       float64 `json:"slippage_cost"`
+	FeeGap             float64 `json:"fee_gap"`
+	OtherGap           float64 `json:"other_gap"`
+	StopExits          int     `json:"stop_exits"`
+	StopPnLGap         float64 `json:"stop_pnl_gap"`         // Share of ExecutionGap from live stop and take-profit exits
+	MissedLivePnL      float64 `json:"missed_live_pnl"`      // Simulated PnL of the fills live missed (simulation size)
+	MissedSimulatedPnL float64 `json:"missed_simulated_pnl"` // Live PnL of the trades the simulation did not take
+}
+
+// ExecutionDriftReport per-trade gap between a live trader and the replay of its recorded decisions
+type ExecutionDriftReport struct {
+	RunID    string                `json:"run_id"`
+	TraderID string                `json:"trader_id"`
+	Summary  ExecutionDriftSummary `json:"summary"`
+	Trades   []TradeDrift          `json:"trades"`
+}
+
+// LivePositionLoader loads the positions of a live trader that may have opened within [start, end]
+type LivePositionLoader func(traderID string, start, end time.Time) ([]*store.TraderPosition, error)
+
+// ExecutionDrift compares the trades of a live replay run with the live trader's actual positions
+func (m *Manager) ExecutionDrift(runID string, loadLive LivePositionLoader) (*ExecutionDriftReport, error) {
+	cfg, err := LoadConfig(runID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReplayTraderID == "" {
+		return nil, fmt.Errorf("run %s is not a live replay", runID)
+	}
+	events, err := LoadTradeEvents(runID)
+	if err != nil {
+		return nil, err
+	}
+	start, end := time.Unix(cfg.StartTS, 0).UTC(), time.Unix(cfg.EndTS, 0).UTC()
+	live, err := loadLive(cfg.ReplayTraderID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	barDuration, err := market.TFDuration(cfg.DecisionTimeframe)
+	if err != nil {
+		return nil, err
+	}
+
+	report := buildExecutionDrift(replayTrades(events), live, start.UnixMilli(), end.UnixMilli(), barDuration.Milliseconds()*driftMatchBars)
+	report.RunID = runID
+	report.TraderID = cfg.ReplayTraderID
+	return report, nil
+}
+
+// replayTrade one flat-to-flat simulated position
+type replayTrade struct {
+	symbol, side       string
+	openedAt, closedAt int64 // closedAt 0 = still open
+	qty, entryValue    float64
+	exitQty, exitValue float64
+	fees, netPnL       float64 // Closing fees include the opening fee of the closed quantity
+}
+
+func (t replayTrade) entryPrice() float64 {
+	if t.qty <= 0 {
+		return 0
+	}
+	return t.entryValue / t.qty
+}
+
+func (t replayTrade) exitPrice() float64 {
+	if t.exitQty <= 0 {
+		return 0
+	}
+	return t.exitValue / t.exitQty
+}
+
+// replayTrades rebuilds the flat-to-flat positions of every symbol and side from the trade log
+func replayTrades(events []TradeEvent) []replayTrade {
+	var trades []replayTrade
+	open := make(map[string]int)
+	for _, evt := range events {
+		key := evt.Symbol + "_" + evt.Side
+		closing := evt.LiquidationFlag || strings.HasPrefix(evt.Action, "close")
+		idx, ok := open[key]
+		if !ok {
+			if closing || evt.PositionAfter <= 1e-12 {
+				continue
+			}
+			trades = append(trades, replayTrade{symbol: evt.Symbol, side: evt.Side, openedAt: evt.Timestamp})
+			idx = len(trades) - 1
+			open[key] = idx
+		}
+		t := &trades[idx]
+		if closing {
+			t.exitQty += evt.Quantity
+			t.exitValue += evt.Price * evt.Quantity
+			t.fees += evt.Fee
+			t.netPnL += evt.RealizedPnL
+		} else {
+			t.qty += evt.Quantity
+			t.entryValue += evt.Price * evt.Quantity
+		}
+		if evt.PositionAfter <= 1e-12 {
+			t.closedAt = evt.Timestamp
+			delete(open, key)
+		}
+	}
+	return trades
+}
+
+// buildExecutionDrift matches each live position opened in [start, end] (Unix ms) to the closest
+// simulated trade of the same symbol and side opened within window
+func buildExecutionDrift(trades []replayTrade, live []*store.TraderPosition, start, end, window int64) *ExecutionDriftReport {
+	var positions []*store.TraderPosition
+	for _, pos := range live {
+		if pos.EntryTime >= start && pos.EntryTime <= end {
+			positions = append(positions, pos)
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool { return positions[i].EntryTime < positions[j].EntryTime })
+
+	report := &ExecutionDriftReport{Trades: make([]TradeDrift, 0, len(positions)+len(trades))}
+	summary := &report.Summary
+	summary.LiveTrades = len(positions)
+	summary.SimulatedTrades = len(trades)
+
+	matched := make([]bool, len(trades))
+	entryGaps, exitGaps := 0.0, 0.0
+	exitCompared := 0
+	for _, pos := range positions {
+		symbol, side := market.Normalize(pos.Symbol), strings.ToLower(pos.Side)
+		best := -1
+		for i, t := range trades {
+			if matched[i] || t.symbol != symbol || t.side != side {
+				continue
+			}
+			gap := absInt64(t.openedAt - pos.EntryTime)
+			if gap <= window && (best < 0 || gap < absInt64(trades[best].openedAt-pos.EntryTime)) {
+				best = i
+			}
+		}
+
+		livePnL := pos.RealizedPnL + pos.FundingPnL + pos.EstimatedFundingPnL - pos.Fee
+		if best < 0 {
+			report.Trades = append(report.Trades, TradeDrift{
+				Symbol:          symbol,
+				Side:            side,
+				Status:          DriftMissedSimulated,
+				LiveEntryTime:   pos.EntryTime,
+				LiveExitTime:    pos.ExitTime,
+				LiveQuantity:    liveQuantity(pos),
+				LiveEntryPrice:  pos.EntryPrice,
+				LiveExitPrice:   pos.ExitPrice,
+				LiveFee:         pos.Fee,
+				LivePnL:         livePnL,
+				LiveCloseReason: pos.CloseReason,
+				StopExit:        isStopExit(pos.CloseReason),
+				Open:            pos.Status != "CLOSED",
+			})
+			summary.MissedSimulated++
+			if pos.Status == "CLOSED" {
+				summary.MissedSimulatedPnL += livePnL
+			}
+			continue
+		}
+		matched[best] = true
+
+		drift := compareTrade(pos, trades[best])
+		report.Trades = append(report.Trades, drift)
+		summary.Matched++
+		entryGaps += drift.EntryGapBps
+		if drift.Open {
+			continue
+		}
+		exitCompared++
+		exitGaps += drift.ExitGapBps
+		summary.LivePnL += drift.LivePnL
+		summary.SimPnL += drift.SimPnL
+		summary.ExecutionGap += drift.PnLGap
+		summary.SlippageCost += drift.SlippageCost
+		summary.FeeGap += drift.FeeGap
+		if drift.StopExit {
+			summary.StopExits++
+			summary.StopPnLGap += drift.PnLGap
+		}
+	}
+
+	for i, t := range trades {
+		if matched[i] {
+			continue
+		}
+		drift := TradeDrift{
+			Symbol:        t.symbol,
+			Side:          t.side,
+			Status:        DriftMissedLive,
+			SimEntryTime:  t.openedAt,
+			SimExitTime:   t.closedAt,
+			SimQuantity:   t.qty,
+			SimEntryPrice: t.entryPrice(),
+			SimExitPrice:  t.exitPrice(),
+			SimFee:        t.fees,
+			SimPnL:        t.netPnL,
+			Open:          t.closedAt == 0,
+		}
+		report.Trades = append(report.Trades, drift)
+		summary.MissedLive++
+		if !drift.Open {
+			summary.MissedLivePnL += t.netPnL
+		}
+	}
+
+	if summary.Matched > 0 {
+		summary.AvgEntryGapBps = entryGaps / float64(summary.Matched)
+	}
+	if exitCompared > 0 {
+		summary.AvgExitGapBps = exitGaps / float64(exitCompared)
+	}
+	summary.OtherGap = summary.ExecutionGap + summary.SlippageCost + summary.FeeGap
+
+	sort.SliceStable(report.Trades, func(i, j int) bool { return driftEntryTime(report.Trades[i]) < driftEntryTime(report.Trades[j]) })
+	return report
+}
+
+// compareTrade the drift of a live position against its simulated trade
+func compareTrade(pos *store.TraderPosition, t replayTrade) TradeDrift {
+	qty := liveQuantity(pos)
+	drift := TradeDrift{
+		Symbol:          t.symbol,
+		Side:            t.side,
+		Status:          DriftMatched,
+		LiveEntryTime:   pos.EntryTime,
+		SimEntryTime:    t.openedAt,
+		LiveExitTime:    pos.ExitTime,
+		SimExitTime:     t.closedAt,
+		LiveQuantity:    qty,
+		SimQuantity:     t.qty,
+		LiveEntryPrice:  pos.EntryPrice,
+		SimEntryPrice:   t.entryPrice(),
+		LiveExitPrice:   pos.ExitPrice,
+		SimExitPrice:    t.exitPrice(),
+		LiveFee:         pos.Fee,
+		LiveCloseReason: pos.CloseReason,
+		StopExit:        isStopExit(pos.CloseReason),
+		Open:            pos.Status != "CLOSED" || t.closedAt == 0,
+	}
+
+	// Positive gaps cost the live trade: a long bought higher or sold lower than the simulation
+	sign := 1.0
+	if t.side == "short" {
+		sign = -1
+	}
+	if simEntry := drift.SimEntryPrice; simEntry > 0 && pos.EntryPrice > 0 {
+		drift.EntryGapBps = sign * (pos.EntryPrice - simEntry) / simEntry * 10000
+		drift.SlippageCost = sign * (pos.EntryPrice - simEntry) * qty
+	}
+	if drift.Open {
+		return drift
+	}
+
+	if simExit := drift.SimExitPrice; simExit > 0 && pos.ExitPrice > 0 {
+		drift.ExitGapBps = sign * (simExit - pos.ExitPrice) / simExit * 10000
+		drift.SlippageCost += sign * (simExit - pos.ExitPrice) * qty
+	}
+	scale := 0.0
+	if t.qty > 0 {
+		scale = qty / t.qty
+	}
+	drift.SimFee = t.fees * scale
+	drift.SimPnL = t.netPnL * scale
+	drift.FeeGap = pos.Fee - drift.SimFee
+	drift.LivePnL = pos.RealizedPnL + pos.FundingPnL + pos.EstimatedFundingPnL - pos.Fee
+	drift.PnLGap = drift.LivePnL - drift.SimPnL
+	drift.ExitDelayMinutes = float64(t.closedAt-pos.ExitTime) / float64(time.Minute/time.Millisecond)
+	return drift
+}
+
+func liveQuantity(pos *store.TraderPosition) float64 {
+	if pos.EntryQuantity > 0 {
+		return pos.EntryQuantity
+	}
+	return pos.Quantity
+}
+
+// isStopExit reports whether a live close reason is a protective order (stop loss, trailing stop, take profit)
+func isStopExit(reason string) bool {
+	reason = strings.ToLower(reason)
+	return strings.Contains(reason, "stop") || strings.Contains(reason, "take_profit") || strings.Contains(reason, "trailing")
+}
+
+func driftEntryTime(d TradeDrift) int64 {
+	if d.LiveEntryTime > 0 {
+		return d.LiveEntryTime
+	}
+	return d.SimEntryTime
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func TestLiveReplayDecisions(t *testing.T) {
+	records := []*store.DecisionRecord{
+		{Timestamp: time.UnixMilli(35).UTC(), DecisionJSON: `[{"symbol":"SOLUSDT","action":"close_long"}]`},
+		{Timestamp: time.UnixMilli(10).UTC(), InputPrompt: "first", DecisionJSON: `[{"symbol":"BTCUSDT","action":"open_long","position_size_usd":500}]`},
+		// Older records without decision JSON replay their executed actions
+		{Timestamp: time.UnixMilli(20).UTC(), InputPrompt: "second", Decisions: []store.DecisionAction{
+			{Symbol: "ETHUSDT", Action: "open_short", Quantity: 2, Price: 150, Leverage: 3},
+			{Symbol: "XRPUSDT", Action: "hold"},
+		}},
+	}
+	replay := newLiveReplay(records)
+
+	full := replay.decisionsBetween(0, 20)
+	if full == nil || len(full.Decisions) != 3 || full.UserPrompt != "second" {
+		t.Fatalf("expected both early records merged with the latest prompt, got %+v", full)
+	}
+	if eth := full.Decisions[1]; eth.Symbol != "ETHUSDT" || eth.PositionSizeUSD != 300 || eth.Leverage != 3 {
+		t.Errorf("unexpected decision rebuilt from actions: %+v", eth)
+	}
+	if replay.decisionsBetween(20, 30) != nil {
+		t.Error("no decision was recorded in (20, 30]")
+	}
+	if full := replay.decisionsBetween(30, 40); full == nil || full.Decisions[0].Symbol != "SOLUSDT" {
+		t.Errorf("expected the SOL close in (30, 40], got %+v", full)
+	}
+
+	symbols := liveDecisionSymbols(records)
+	if len(symbols) != 3 || symbols[0] != "BTCUSDT" || symbols[2] != "SOLUSDT" {
+		t.Errorf("symbols = %v, want BTC, ETH and SOL without the held XRP", symbols)
+	}
+}
+
+func TestBuildExecutionDrift(t *testing.T) {
+	events := []TradeEvent{
+		{Timestamp: 1000, Symbol: "BTCUSDT", Action: "open_long", Side: "long", Quantity: 1, Price: 100, Fee: 0.1, PositionAfter: 1},
+		{Timestamp: 2000, Symbol: "ETHUSDT", Action: "open_short", Side: "short", Quantity: 1, Price: 50, Fee: 0.05, PositionAfter: 1},
+		{Timestamp: 5000, Symbol: "BTCUSDT", Action: "close_long", Side: "long", Quantity: 1, Price: 110, Fee: 0.2, RealizedPnL: 9.8},
+	}
+	live := []*store.TraderPosition{
+		{Symbol: "BTCUSDT", Side: "LONG", EntryQuantity: 2, EntryPrice: 100.5, EntryTime: 900, ExitPrice: 109, ExitTime: 4000,
+			RealizedPnL: 17, Fee: 0.6, Status: "CLOSED", CloseReason: "stop_loss"},
+		{Symbol: "SOLUSDT", Side: "LONG", Quantity: 10, EntryPrice: 20, EntryTime: 3000, ExitPrice: 20.5, ExitTime: 6000,
+			RealizedPnL: 5, Fee: 1, Status: "CLOSED"},
+		{Symbol: "BTCUSDT", Side: "LONG", Quantity: 1, EntryPrice: 90, EntryTime: 100000, Status: "CLOSED"}, // After the replay period
+	}
+
+	report := buildExecutionDrift(replayTrades(events), live, 0, 10000, 200)
+	s := report.Summary
+	if s.LiveTrades != 2 || s.SimulatedTrades != 2 || s.Matched != 1 || s.MissedLive != 1 || s.MissedSimulated != 1 {
+		t.Fatalf("unexpected trade counts: %+v", s)
+	}
+	if len(report.Trades) != 3 || report.Trades[0].Status != DriftMatched || report.Trades[1].Status != DriftMissedLive {
+		t.Fatalf("trades should be ordered by entry: %+v", report.Trades)
+	}
+
+	btc := report.Trades[0]
+	// Live bought 50 bps higher and sold 1 below the simulated 110, on twice the simulated size
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"entry gap bps", btc.EntryGapBps, 50},
+		{"exit gap bps", btc.ExitGapBps, 1.0 / 110 * 10000},
+		{"slippage cost", btc.SlippageCost, 3},
+		{"sim fee", btc.SimFee, 0.4},
+		{"fee gap", btc.FeeGap, 0.2},
+		{"live pnl", btc.LivePnL, 16.4},
+		{"sim pnl", btc.SimPnL, 19.6},
+		{"pnl gap", btc.PnLGap, -3.2},
+		{"exit delay", btc.ExitDelayMinutes, 1000.0 / 60000},
+		{"other gap", s.OtherGap, 0},
+		{"missed simulated pnl", s.MissedSimulatedPnL, 4},
+		{"missed live pnl", s.MissedLivePnL, 0},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %.6f, want %.6f", c.name, c.got, c.want)
+		}
+	}
+	if !btc.StopExit || s.StopExits != 1 || math.Abs(s.StopPnLGap+3.2) > 1e-9 {
+		t.Errorf("the live stop-loss exit should be counted: %+v", s)
+	}
+	if eth := report.Trades[1]; !eth.Open || eth.Symbol != "ETHUSDT" {
+		t.Errorf("the simulated ETH short never closed: %+v", eth)
+	}
+}
@@ -27,6 +27,8 @@ var (
 const (
 	metricsWriteInterval = 5 * time.Second
 	aiDecisionMaxRetries = 3
+	// strategySeriesBars indicator series bars attached for non-AI strategies (latest + previous bar)
+	strategySeriesBars = 2
 )
 
 // Runner encapsulates the lifecycle of a single backtest run.
@@ -38,6 +40,10 @@ type Runner struct {
 
 	decisionLogDir string
 	mcpClient      mcp.AIClient
+	strategy       kernel.Strategy // Decision maker registered for the strategy type
+	requiresAI     bool            // Strategy calls the AI (enables AI cache and retries)
+	grid           *gridEngine     // Grid orders and rules (grid_trading only)
+	replay         *liveReplay     // Recorded live decisions replayed instead of AI calls (replay_trader_id only)
 
 	statusMu sync.RWMutex
 	status   RunState
@@ -76,6 +82,14 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		return nil, err
 	}
 
+	var replay *liveReplay
+	if cfg.ReplayTraderID != "" {
+		if len(cfg.liveDecisions) == 0 {
+			return nil, fmt.Errorf("no recorded decisions of trader %s to replay", cfg.ReplayTraderID)
+		}
+		replay = newLiveReplay(cfg.liveDecisions)
+	}
+
 	feed, err := NewDataFeed(cfg)
 	if err != nil {
 		return nil, err
@@ -87,6 +101,9 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 
 	dLogDir := decisionLogDir(cfg.RunID)
 	account := NewBacktestAccount(cfg.InitialBalance, cfg.FeeBps, cfg.SlippageBps)
+	if cfg.MakerFeeBps > 0 {
+		account.SetMakerFee(cfg.MakerFeeBps)
+	}
 
 	createdAt := time.Now().UTC()
 	state := &BacktestState{
@@ -120,6 +137,41 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 	// Create strategy engine from backtest config for unified prompt generation
 	strategyConfig := cfg.ToStrategyConfig()
 	strategyEngine := kernel.NewStrategyEngine(strategyConfig)
+	if err := kernel.CheckBacktest(strategyConfig); err != nil {
+		return nil, err
+	}
+	reg, ok := kernel.LookupStrategy(strategyConfig.StrategyType)
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy type: %s", strategyConfig.StrategyType)
+	}
+	strategy, err := kernel.NewStrategy(kernel.StrategyEnv{
+		Config:   strategyConfig,
+		Engine:   strategyEngine,
+		AIClient: client,
+		Variant:  cfg.PromptVariant,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := strategy.Init(); err != nil {
+		return nil, fmt.Errorf("strategy initialization failed: %w", err)
+	}
+	if strategyConfig.StrategyType == "grid_trading" {
+		gridSymbol := market.Normalize(strategyConfig.GridConfig.Symbol)
+		found := false
+		for _, sym := range cfg.Symbols {
+			found = found || sym == gridSymbol
+		}
+		if !found {
+			return nil, fmt.Errorf("grid symbol %s must be one of the backtest symbols", gridSymbol)
+		}
+	}
+	if !reg.RequiresAI {
+		feed.seriesBars = strategySeriesBars
+	}
+	if ss, ok := strategy.(kernel.SeriesStrategy); ok && ss.SeriesBars() > feed.seriesBars {
+		feed.seriesBars = ss.SeriesBars()
+	}
 
 	r := &Runner{
 		cfg:            cfg,
@@ -128,6 +180,9 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		strategyEngine: strategyEngine,
 		decisionLogDir: dLogDir,
 		mcpClient:      client,
+		strategy:       strategy,
+		requiresAI:     cfg.RequiresAI(),
+		replay:         replay,
 		status:         RunStateCreated,
 		state:          state,
 		pauseCh:        make(chan struct{}, 1),
@@ -139,6 +194,10 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		cachePath:      cachePath,
 	}
 
+	if gridCfg := strategyConfig.GridConfig; strategyConfig.StrategyType == "grid_trading" {
+		r.grid = newGridEngine(gridCfg, r.resolveLeverage(gridCfg.Leverage, market.Normalize(gridCfg.Symbol)), account, feed)
+	}
+
 	if err := r.initLock(); err != nil {
 		return nil, err
 	}
@@ -234,6 +293,11 @@ func (r *Runner) CurrentMetadata() *RunMetadata {
 
 func (r *Runner) loop(ctx context.Context) {
 	defer close(r.doneCh)
+	defer func() {
+		if err := r.strategy.Close(); err != nil {
+			logger.Infof("failed to close strategy for %s: %v", r.cfg.RunID, err)
+		}
+	}()
 
 	for {
 		select {
@@ -287,6 +351,17 @@ func (r *Runner) stepOnce() error {
 	callCount := state.DecisionCycle + 1
 	shouldDecide := r.shouldTriggerDecision(state.BarIndex)
 
+	// A live replay decides on the first decision bar at or after each recorded decision, whatever the cadence
+	var replayed *kernel.FullDecision
+	if r.replay != nil {
+		var from int64
+		if state.BarIndex > 0 {
+			from = r.feed.DecisionTimestamp(state.BarIndex - 1)
+		}
+		replayed = r.replay.decisionsBetween(from, ts)
+		shouldDecide = replayed != nil
+	}
+
 	var (
 		record          *store.DecisionRecord
 		decisionActions []store.DecisionAction
@@ -295,6 +370,14 @@ func (r *Runner) stepOnce() error {
 		hadError        bool
 	)
 
+	// Grid orders fill against this bar before the next decision
+	if r.grid != nil {
+		curr, _ := r.feed.decisionBarSnapshot(r.grid.symbol, ts)
+		events, logs := r.grid.onBar(ts, curr, marketData[r.grid.symbol], state.DecisionCycle)
+		tradeEvents = append(tradeEvents, events...)
+		execLog = append(execLog, logs...)
+	}
+
 	decisionAttempted := shouldDecide
 
 	if shouldDecide {
@@ -315,7 +398,10 @@ func (r *Runner) stepOnce() error {
 			fromCache    bool
 			cacheKey     string
 		)
-		if r.aiCache != nil {
+		if replayed != nil {
+			// The recorded live decision stands in for the AI call
+			fullDecision, fromCache = replayed, true
+		} else if r.aiCache != nil && r.requiresAI {
 			if key, err := computeCacheKey(ctx, r.cfg.PromptVariant, ts); err == nil {
 				cacheKey = key
 				if cached, ok := r.aiCache.Get(cacheKey); ok {
@@ -363,6 +449,11 @@ func (r *Runner) stepOnce() error {
 			if len(prevLogs) > 0 {
 				execLog = append(execLog, prevLogs...)
 			}
+			if r.grid != nil {
+				if logEntry := r.grid.afterDecision(ctx.Grid, ts); logEntry != "" {
+					execLog = append(execLog, logEntry)
+				}
+			}
 
 			for _, dec := range sorted {
 				actionRecord, trades, logEntry, execErr := r.executeDecision(dec, priceMap, ts, callCount)
@@ -522,6 +613,9 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 		AltcoinLeverage: r.cfg.Leverage.AltcoinLeverage,
 		Timeframes:      r.cfg.Timeframes,
 	}
+	if r.grid != nil {
+		ctx.Grid = r.grid.buildContext(marketData[r.grid.symbol], ts, accountInfo)
+	}
 
 	// Fetch quantitative data if enabled in strategy (uses current data as approximation)
 	strategyConfig := r.strategyEngine.GetConfig()
@@ -601,16 +695,24 @@ func (r *Runner) fillDecisionRecord(record *store.DecisionRecord, full *kernel.F
 }
 
 func (r *Runner) invokeAIWithRetry(ctx *kernel.Context) (*kernel.FullDecision, error) {
+	// A paused grid skips its cycle, as in live trading
+	if ctx.Grid != nil && ctx.Grid.IsPaused {
+		return &kernel.FullDecision{
+			Decisions: []kernel.Decision{{Symbol: ctx.Grid.Symbol, Action: "hold", Reasoning: "Grid is paused"}},
+			Timestamp: ctx.Grid.Timestamp,
+		}, nil
+	}
+
+	// Strategies without AI are deterministic: nothing to retry
+	if !r.requiresAI {
+		return kernel.RunStrategy(r.strategy, ctx)
+	}
+
 	var lastErr error
 	for attempt := 0; attempt < aiDecisionMaxRetries; attempt++ {
-		// Use GetFullDecisionWithStrategy with the pre-configured strategy engine
-		// This ensures backtest uses the same unified prompt generation as live trading
-		fd, err := kernel.GetFullDecisionWithStrategy(
-			ctx,
-			r.mcpClient,
-			r.strategyEngine,
-			r.cfg.PromptVariant,
-		)
+		// The strategy uses the pre-configured strategy engine, so backtests share
+		// the unified prompt generation of live trading
+		fd, err := kernel.RunStrategy(r.strategy, ctx)
 		if err == nil {
 			return fd, nil
 		}
@@ -622,6 +724,10 @@ func (r *Runner) invokeAIWithRetry(ctx *kernel.Context) (*kernel.FullDecision, e
 }
 
 func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float64, ts int64, cycle int) (store.DecisionAction, []TradeEvent, string, error) {
+	if r.grid != nil {
+		return r.grid.executeDecision(dec, priceMap[r.grid.symbol], ts)
+	}
+
 	symbol := dec.Symbol
 	if symbol == "" {
 		return store.DecisionAction{}, nil, "", fmt.Errorf("empty symbol in decision")
@@ -822,6 +928,9 @@ func (r *Runner) determineQuantity(dec kernel.Decision, price float64) float64 {
 func (r *Runner) determineCloseQuantity(symbol, side string, dec kernel.Decision) float64 {
 	for _, pos := range r.account.Positions() {
 		if pos.Symbol == strings.ToUpper(symbol) && pos.Side == side {
+			if dec.ClosePercentage > 0 && dec.ClosePercentage < 1 {
+				return pos.Quantity * dec.ClosePercentage
+			}
 			return pos.Quantity
 		}
 	}
@@ -990,6 +1099,10 @@ func (r *Runner) updateState(ts int64, equity, unrealized, marginUsed float64, p
 	r.state.UnrealizedPnL = unrealized
 	r.state.RealizedPnL = r.account.RealizedPnL()
 	r.state.Positions = positions
+	if r.grid != nil {
+		gridMetrics := r.grid.metrics()
+		r.state.Grid = &gridMetrics
+	}
 	r.state.LastUpdate = time.Now().UTC()
 }
 
@@ -1290,6 +1403,10 @@ func (r *Runner) snapshotState() BacktestState {
 	for k, v := range r.state.Positions {
 		copyState.Positions[k] = v
 	}
+	if r.state.Grid != nil {
+		gridMetrics := *r.state.Grid
+		copyState.Grid = &gridMetrics
+	}
 	return copyState
 }
 
@@ -1326,7 +1443,7 @@ func (r *Runner) persistMetrics(force bool) {
 	}
 
 	state := r.snapshotState()
-	metrics, err := CalculateMetrics(r.cfg.RunID, &r.cfg, &state)
+	metrics, err := CalculateMetrics(r.cfg.RunID, &r.cfg, &state, r.feed.Benchmark())
 	if err != nil {
 		logger.Infof("failed to compute metrics for %s: %v", r.cfg.RunID, err)
 		return
@@ -1402,7 +1519,7 @@ func progressPercent(state BacktestState, cfg BacktestConfig) float64 {
 }
 
 func (r *Runner) buildCheckpointFromState(state BacktestState) *Checkpoint {
-	return &Checkpoint{
+	ckpt := &Checkpoint{
 		BarIndex:        state.BarIndex,
 		BarTimestamp:    state.BarTimestamp,
 		Cash:            state.Cash,
@@ -1417,7 +1534,12 @@ func (r *Runner) buildCheckpointFromState(state BacktestState) *Checkpoint {
 		MinEquity:       state.MinEquity,
 		MaxDrawdownPct:  state.MaxDrawdownPct,
 		AICacheRef:      r.cachePath,
+		Orders:          r.account.OpenOrders(""),
+	}
+	if r.grid != nil {
+		ckpt.Grid = r.grid.snapshot()
 	}
+	return ckpt
 }
 
 func (r *Runner) saveCheckpoint(state BacktestState) error {
@@ -1452,6 +1574,10 @@ func (r *Runner) applyCheckpoint(ckpt *Checkpoint) error {
 		return fmt.Errorf("checkpoint is nil")
 	}
 	r.account.RestoreFromSnapshots(ckpt.Cash, ckpt.RealizedPnL, ckpt.Positions)
+	r.account.RestoreOrders(ckpt.Orders)
+	if r.grid != nil && ckpt.Grid != nil {
+		r.grid.restore(ckpt.Grid)
+	}
 	r.stateMu.Lock()
 	defer r.stateMu.Unlock()
 	r.state.BarIndex = ckpt.BarIndex
@@ -1467,6 +1593,10 @@ func (r *Runner) applyCheckpoint(ckpt *Checkpoint) error {
 	r.state.MinEquity = ckpt.MinEquity
 	r.state.MaxDrawdownPct = ckpt.MaxDrawdownPct
 	r.state.Positions = snapshotsToMap(ckpt.Positions)
+	if r.grid != nil {
+		gridMetrics := r.grid.metrics()
+		r.state.Grid = &gridMetrics
+	}
 	r.state.LastUpdate = time.Now().UTC()
 	r.lastCheckpoint = time.Now()
 	return nil
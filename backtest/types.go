@@ -1,6 +1,10 @@
 package backtest
 
-import "time"
+import (
+	"time"
+
+	"nofx/performance"
+)
 
 // RunState represents the current state of a backtest run.
 type RunState string
@@ -45,6 +49,7 @@ type BacktestState struct {
 	LastUpdate      time.Time
 	Liquidated      bool
 	LiquidationNote string
+	Grid            *GridMetrics // Grid strategy activity (grid backtests only)
 }
 
 // EquityPoint represents a single point on the equity curve.
@@ -91,6 +96,21 @@ type Metrics struct {
 	WorstSymbol    string                   `json:"worst_symbol"`
 	SymbolStats    map[string]SymbolMetrics `json:"symbol_stats"`
 	Liquidated     bool                     `json:"liquidated"`
+	Grid           *GridMetrics             `json:"grid,omitempty"` // Grid strategy backtests only
+
+	// Equity curve metrics from the metric engine shared with live traders (nofx/performance)
+	AnnualReturnPct    float64                     `json:"annual_return_pct"`
+	SortinoRatio       float64                     `json:"sortino_ratio"`
+	CalmarRatio        float64                     `json:"calmar_ratio"`
+	MARRatio           float64                     `json:"mar_ratio"`
+	UlcerIndex         float64                     `json:"ulcer_index"`
+	ExposurePct        float64                     `json:"exposure_pct"`
+	AvgHoldingHours    float64                     `json:"avg_holding_hours"`
+	Benchmark          string                      `json:"benchmark,omitempty"`
+	BenchmarkReturnPct float64                     `json:"benchmark_return_pct"` // Buy and hold of the benchmark over the backtest window
+	Alpha              float64                     `json:"alpha"`
+	Beta               float64                     `json:"beta"`
+	MonthlyReturns     []performance.MonthlyReturn `json:"monthly_returns,omitempty"`
 }
 
 // SymbolMetrics records performance for a single symbol.
@@ -121,6 +141,8 @@ type Checkpoint struct {
 	AICacheRef      string                    `json:"ai_cache_ref,omitempty"`
 	Liquidated      bool                      `json:"liquidated"`
 	LiquidationNote string                    `json:"liquidation_note,omitempty"`
+	Orders          []LimitOrder              `json:"orders,omitempty"` // Resting limit orders
+	Grid            *GridSnapshot             `json:"grid,omitempty"`   // Grid strategy state
 }
 
 // RunMetadata records the summary required for run.json.
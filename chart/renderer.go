@@ -0,0 +1,405 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"nofx/market"
+)
+
+// ============================================================================
+// Candlestick chart renderer (pure Go, PNG output) for multimodal prompts
+// ============================================================================
+
+// Default chart size and layout
+const (
+	DefaultWidth   = 800
+	DefaultHeight  = 480
+	DefaultMaxBars = 80
+
+	textScale    = 2
+	marginTop    = 26
+	marginBottom = 8
+	marginLeft   = 8
+	marginRight  = 84
+	volumeRatio  = 0.2 // Share of the plot height used by the volume panel
+	panelGap     = 6
+)
+
+// Chart colors (dark theme)
+var (
+	ColorBackground = color.RGBA{19, 23, 34, 255}
+	ColorGrid       = color.RGBA{42, 46, 57, 255}
+	ColorText       = color.RGBA{200, 204, 214, 255}
+	ColorUp         = color.RGBA{38, 166, 154, 255}
+	ColorDown       = color.RGBA{239, 83, 80, 255}
+	ColorEMA20      = color.RGBA{255, 152, 0, 255}
+	ColorEMA50      = color.RGBA{41, 98, 255, 255}
+	ColorBOLL       = color.RGBA{150, 150, 160, 255}
+	ColorEntry      = color.RGBA{255, 235, 59, 255}
+	ColorStopLoss   = color.RGBA{255, 82, 82, 255}
+	ColorTakeProfit = color.RGBA{0, 230, 118, 255}
+)
+
+// Level a horizontal price line (position entry, stop loss, take profit)
+type Level struct {
+	Price float64
+	Label string
+	Color color.RGBA
+}
+
+// Options chart rendering options
+type Options struct {
+	Width      int
+	Height     int
+	MaxBars    int    // Only the latest N bars are drawn (0 = DefaultMaxBars)
+	Title      string // Drawn at the top-left, e.g. "BTCUSDT 15m"
+	ShowEMA    bool
+	ShowBOLL   bool
+	ShowVolume bool
+	Levels     []Level
+}
+
+// DefaultOptions returns options with all overlays enabled
+func DefaultOptions() Options {
+	return Options{
+		Width:      DefaultWidth,
+		Height:     DefaultHeight,
+		MaxBars:    DefaultMaxBars,
+		ShowEMA:    true,
+		ShowBOLL:   true,
+		ShowVolume: true,
+	}
+}
+
+// PositionLevels returns entry/SL/TP lines for a position, skipping unset prices
+func PositionLevels(entry, stopLoss, takeProfit float64) []Level {
+	var levels []Level
+	if entry > 0 {
+		levels = append(levels, Level{Price: entry, Label: "ENTRY", Color: ColorEntry})
+	}
+	if stopLoss > 0 {
+		levels = append(levels, Level{Price: stopLoss, Label: "SL", Color: ColorStopLoss})
+	}
+	if takeProfit > 0 {
+		levels = append(levels, Level{Price: takeProfit, Label: "TP", Color: ColorTakeProfit})
+	}
+	return levels
+}
+
+// RenderPNG renders a candlestick chart of the series and encodes it as PNG
+func RenderPNG(series *market.TimeframeSeriesData, opts Options) ([]byte, error) {
+	img, err := Render(series, opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws candles, overlays, volume and level lines into an image
+func Render(series *market.TimeframeSeriesData, opts Options) (*image.RGBA, error) {
+	if series == nil || len(series.Klines) == 0 {
+		return nil, fmt.Errorf("no kline data to render")
+	}
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	if opts.Height <= 0 {
+		opts.Height = DefaultHeight
+	}
+	if opts.MaxBars <= 0 {
+		opts.MaxBars = DefaultMaxBars
+	}
+
+	// Latest N bars; overlay series are right-aligned with the klines
+	klines := series.Klines
+	if len(klines) > opts.MaxBars {
+		klines = klines[len(klines)-opts.MaxBars:]
+	}
+	n := len(klines)
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	fillRect(img, 0, 0, opts.Width, opts.Height, ColorBackground)
+
+	plotLeft, plotRight := marginLeft, opts.Width-marginRight
+	plotTop, plotBottom := marginTop, opts.Height-marginBottom
+	if plotRight-plotLeft < n || plotBottom-plotTop < 40 {
+		return nil, fmt.Errorf("chart size %dx%d too small for %d bars", opts.Width, opts.Height, n)
+	}
+	priceBottom := plotBottom
+	volumeTop := plotBottom
+	if opts.ShowVolume {
+		volumeTop = plotBottom - int(float64(plotBottom-plotTop)*volumeRatio)
+		priceBottom = volumeTop - panelGap
+	}
+
+	// Overlay series aligned to the visible bars
+	var overlays []overlay
+	if opts.ShowBOLL {
+		overlays = append(overlays,
+			overlay{alignSeries(series.BOLLUpper, len(series.Klines), n), ColorBOLL},
+			overlay{alignSeries(series.BOLLMiddle, len(series.Klines), n), ColorBOLL},
+			overlay{alignSeries(series.BOLLLower, len(series.Klines), n), ColorBOLL},
+		)
+	}
+	if opts.ShowEMA {
+		overlays = append(overlays,
+			overlay{alignSeries(series.EMA20Values, len(series.Klines), n), ColorEMA20},
+			overlay{alignSeries(series.EMA50Values, len(series.Klines), n), ColorEMA50},
+		)
+	}
+
+	// Price range covers candles, overlays and levels
+	minPrice, maxPrice := math.Inf(1), math.Inf(-1)
+	for _, k := range klines {
+		minPrice = math.Min(minPrice, k.Low)
+		maxPrice = math.Max(maxPrice, k.High)
+	}
+	for _, o := range overlays {
+		for _, v := range o.values {
+			if !math.IsNaN(v) {
+				minPrice = math.Min(minPrice, v)
+				maxPrice = math.Max(maxPrice, v)
+			}
+		}
+	}
+	for _, l := range opts.Levels {
+		if l.Price > 0 {
+			minPrice = math.Min(minPrice, l.Price)
+			maxPrice = math.Max(maxPrice, l.Price)
+		}
+	}
+	if maxPrice <= minPrice {
+		maxPrice = minPrice*1.001 + 1e-9
+	}
+	pad := (maxPrice - minPrice) * 0.03
+	minPrice -= pad
+	maxPrice += pad
+
+	priceY := func(p float64) int {
+		return priceBottom - int(math.Round((p-minPrice)/(maxPrice-minPrice)*float64(priceBottom-plotTop)))
+	}
+	slot := float64(plotRight-plotLeft) / float64(n)
+	barX := func(i int) int {
+		return plotLeft + int(slot*float64(i)+slot/2)
+	}
+
+	// Grid and price axis labels
+	const ticks = 5
+	for i := 0; i <= ticks; i++ {
+		p := minPrice + (maxPrice-minPrice)*float64(i)/ticks
+		y := priceY(p)
+		drawHLine(img, plotLeft, plotRight, y, ColorGrid, 1)
+		drawText(img, plotRight+6, y-glyphHeight*textScale/2, FormatPrice(p), textScale, ColorText)
+	}
+
+	for _, o := range overlays {
+		drawSeries(img, o.values, barX, priceY, o.color)
+	}
+
+	// Candles
+	bodyWidth := int(slot * 0.7)
+	if bodyWidth < 1 {
+		bodyWidth = 1
+	}
+	for i, k := range klines {
+		c := ColorUp
+		if k.Close < k.Open {
+			c = ColorDown
+		}
+		x := barX(i)
+		drawVLine(img, x, priceY(k.High), priceY(k.Low), c)
+		top, bottom := priceY(math.Max(k.Open, k.Close)), priceY(math.Min(k.Open, k.Close))
+		if bottom == top {
+			bottom = top + 1
+		}
+		fillRect(img, x-bodyWidth/2, top, x-bodyWidth/2+bodyWidth, bottom, c)
+	}
+
+	// Volume panel
+	if opts.ShowVolume {
+		maxVolume := 0.0
+		for _, k := range klines {
+			maxVolume = math.Max(maxVolume, k.Volume)
+		}
+		drawHLine(img, plotLeft, plotRight, plotBottom, ColorGrid, 1)
+		if maxVolume > 0 {
+			for i, k := range klines {
+				c := ColorUp
+				if k.Close < k.Open {
+					c = ColorDown
+				}
+				h := int(k.Volume / maxVolume * float64(plotBottom-volumeTop))
+				x := barX(i)
+				fillRect(img, x-bodyWidth/2, plotBottom-h, x-bodyWidth/2+bodyWidth, plotBottom, dim(c))
+			}
+		}
+		drawText(img, plotRight+6, volumeTop, "VOL", textScale, ColorText)
+	}
+
+	// Position levels (dashed, labelled on the axis)
+	for _, l := range opts.Levels {
+		if l.Price <= 0 {
+			continue
+		}
+		y := priceY(l.Price)
+		drawDashedHLine(img, plotLeft, plotRight, y, l.Color)
+		fillRect(img, plotRight+2, y-glyphHeight*textScale/2-2, opts.Width, y+glyphHeight*textScale/2+2, ColorBackground)
+		drawText(img, plotRight+6, y-glyphHeight*textScale/2, l.Label, textScale, l.Color)
+		drawText(img, plotLeft+4, y-glyphHeight*textScale-3, l.Label+" "+FormatPrice(l.Price), textScale, l.Color)
+	}
+
+	// Title and legend
+	drawText(img, marginLeft, 8, opts.Title, textScale, ColorText)
+	x := marginLeft + textWidth(opts.Title, textScale) + 20
+	if opts.ShowEMA {
+		drawText(img, x, 8, "EMA20", textScale, ColorEMA20)
+		x += textWidth("EMA20", textScale) + 12
+		drawText(img, x, 8, "EMA50", textScale, ColorEMA50)
+		x += textWidth("EMA50", textScale) + 12
+	}
+	if opts.ShowBOLL {
+		drawText(img, x, 8, "BOLL", textScale, ColorBOLL)
+	}
+
+	return img, nil
+}
+
+// overlay a price series drawn as a line over the candles
+type overlay struct {
+	values []float64
+	color  color.RGBA
+}
+
+// alignSeries right-aligns an indicator series to the last `visible` of `total` klines
+// Bars without an indicator value are NaN
+func alignSeries(values []float64, total, visible int) []float64 {
+	out := make([]float64, visible)
+	offset := total - len(values) // kline index of values[0]
+	start := total - visible      // kline index of out[0]
+	for i := range out {
+		j := start + i - offset
+		if j >= 0 && j < len(values) && values[j] > 0 {
+			out[i] = values[j]
+		} else {
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// FormatPrice formats a price with precision adapted to its magnitude
+func FormatPrice(p float64) string {
+	abs := math.Abs(p)
+	switch {
+	case abs >= 1000:
+		return fmt.Sprintf("%.1f", p)
+	case abs >= 1:
+		return fmt.Sprintf("%.3f", p)
+	case abs >= 0.01:
+		return fmt.Sprintf("%.5f", p)
+	default:
+		return fmt.Sprintf("%.8f", p)
+	}
+}
+
+// ============================================================================
+// Drawing primitives
+// ============================================================================
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	r := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func drawHLine(img *image.RGBA, x0, x1, y int, c color.RGBA, width int) {
+	fillRect(img, x0, y, x1, y+width, c)
+}
+
+func drawDashedHLine(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x < x1; x += 10 {
+		end := x + 6
+		if end > x1 {
+			end = x1
+		}
+		fillRect(img, x, y, end, y+1, c)
+	}
+}
+
+func drawVLine(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	fillRect(img, x, y0, x+1, y1+1, c)
+}
+
+// drawLine draws a line using Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	errAcc := dx + dy
+	bounds := img.Bounds()
+	for {
+		if (image.Point{x0, y0}).In(bounds) {
+			img.SetRGBA(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * errAcc
+		if e2 >= dy {
+			errAcc += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			errAcc += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawSeries connects consecutive non-NaN values of a series
+func drawSeries(img *image.RGBA, values []float64, barX func(int) int, priceY func(float64) int, c color.RGBA) {
+	for i := 1; i < len(values); i++ {
+		if math.IsNaN(values[i-1]) || math.IsNaN(values[i]) {
+			continue
+		}
+		drawLine(img, barX(i-1), priceY(values[i-1]), barX(i), priceY(values[i]), c)
+	}
+}
+
+// dim returns a semi-transparent looking variant of a color on the dark background
+func dim(c color.RGBA) color.RGBA {
+	return color.RGBA{
+		R: uint8((int(c.R) + int(ColorBackground.R)) / 2),
+		G: uint8((int(c.G) + int(ColorBackground.G)) / 2),
+		B: uint8((int(c.B) + int(ColorBackground.B)) / 2),
+		A: 255,
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+
+	"nofx/market"
+)
+
+func testSeries(n int) *market.TimeframeSeriesData {
+	series := &market.TimeframeSeriesData{Timeframe: "15m"}
+	for i := 0; i < n; i++ {
+		open := 100 + float64(i)
+		close := open + 0.5
+		if i%3 == 0 {
+			close = open - 0.5
+		}
+		series.Klines = append(series.Klines, market.KlineBar{
+			Time: int64(i) * 900000, Open: open, High: open + 1, Low: open - 1, Close: close, Volume: float64(10 + i),
+		})
+		if i >= 19 {
+			series.EMA20Values = append(series.EMA20Values, open)
+			series.BOLLUpper = append(series.BOLLUpper, open+2)
+			series.BOLLMiddle = append(series.BOLLMiddle, open)
+			series.BOLLLower = append(series.BOLLLower, open-2)
+		}
+	}
+	return series
+}
+
+func TestRenderPNG(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Title = "BTCUSDT 15m"
+	opts.Levels = PositionLevels(120, 110, 135)
+
+	data, err := RenderPNG(testSeries(100), opts)
+	if err != nil {
+		t.Fatalf("RenderPNG() error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a valid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != DefaultWidth || b.Dy() != DefaultHeight {
+		t.Errorf("image size = %dx%d, want %dx%d", b.Dx(), b.Dy(), DefaultWidth, DefaultHeight)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render(nil, DefaultOptions()); err == nil {
+		t.Error("Render(nil) should fail")
+	}
+	if _, err := Render(&market.TimeframeSeriesData{}, DefaultOptions()); err == nil {
+		t.Error("Render() with no klines should fail")
+	}
+	opts := DefaultOptions()
+	opts.Width, opts.Height = 100, 60
+	if _, err := Render(testSeries(100), opts); err == nil {
+		t.Error("Render() should fail when the chart is too small for the bars")
+	}
+}
+
+func TestAlignSeries(t *testing.T) {
+	// 5 klines, indicator only available for the last 3, 4 visible bars
+	got := alignSeries([]float64{3, 4, 5}, 5, 4)
+	if !math.IsNaN(got[0]) || got[1] != 3 || got[3] != 5 {
+		t.Errorf("alignSeries() = %v, want [NaN 3 4 5]", got)
+	}
+}
+
+func TestPositionLevels(t *testing.T) {
+	levels := PositionLevels(100, 0, 110)
+	if len(levels) != 2 || levels[0].Label != "ENTRY" || levels[1].Label != "TP" {
+		t.Errorf("PositionLevels() = %+v, want ENTRY and TP only", levels)
+	}
+}
@@ -0,0 +1,100 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/chart"
+	"nofx/logger"
+	"nofx/mcp"
+	"strings"
+)
+
+// ============================================================================
+// Chart Attachments - candlestick images for multimodal models
+// ============================================================================
+
+// defaultChartCandidates number of candidates charted when not configured
+const defaultChartCandidates = 3
+
+// BuildChartImages renders PNG charts for every held position (with entry, stop loss and take profit
+// levels) and the top candidates, returning the image parts and the symbols charted (in the same order)
+func (e *StrategyEngine) BuildChartImages(ctx *Context) ([]mcp.ContentPart, []string) {
+	cfg := e.config.Charts
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	topN := cfg.TopCandidates
+	if topN <= 0 {
+		topN = defaultChartCandidates
+	}
+	timeframe := cfg.Timeframe
+	if timeframe == "" {
+		timeframe = e.config.Indicators.Klines.PrimaryTimeframe
+	}
+
+	// Held positions first, then candidates not already charted
+	positions := make(map[string]PositionInfo, len(ctx.Positions))
+	var order []string
+	for _, pos := range ctx.Positions {
+		if _, seen := positions[pos.Symbol]; !seen {
+			order = append(order, pos.Symbol)
+		}
+		positions[pos.Symbol] = pos
+	}
+	for _, coin := range ctx.CandidateCoins {
+		if _, held := positions[coin.Symbol]; !held {
+			order = append(order, coin.Symbol)
+		}
+	}
+
+	var images []mcp.ContentPart
+	var symbols []string
+	candidates := 0
+	for _, symbol := range order {
+		pos, held := positions[symbol]
+		if !held && candidates >= topN {
+			break
+		}
+		data, ok := ctx.MarketDataMap[symbol]
+		if !ok || data.TimeframeData == nil {
+			continue
+		}
+		series, ok := data.TimeframeData[timeframe]
+		if !ok {
+			continue
+		}
+
+		opts := chart.DefaultOptions()
+		opts.Title = fmt.Sprintf("%s %s", symbol, timeframe)
+		if cfg.MaxBars > 0 {
+			opts.MaxBars = cfg.MaxBars
+		}
+		if held {
+			opts.Title = fmt.Sprintf("%s %s (%s)", symbol, timeframe, strings.ToUpper(pos.Side))
+			opts.Levels = chart.PositionLevels(pos.EntryPrice, pos.StopLoss, pos.TakeProfit)
+		}
+
+		png, err := chart.RenderPNG(series, opts)
+		if err != nil {
+			logger.Warnf("⚠️ Failed to render chart for %s: %v", symbol, err)
+			continue
+		}
+		images = append(images, mcp.NewImagePart("image/png", png))
+		symbols = append(symbols, symbol)
+		if !held {
+			candidates++
+		}
+	}
+
+	return images, symbols
+}
+
+// chartPromptNote describes the attached charts at the end of the user prompt
+func (e *StrategyEngine) chartPromptNote(symbols []string) string {
+	if e.GetLanguage() == LangChinese {
+		return fmt.Sprintf("\n## 📈 K线图\n已附上以下币种的K线图(按顺序): %s。图中包含EMA20(橙)、EMA50(蓝)、布林带(灰)和成交量；持仓币种标注了入场价(黄)、止损(红)和止盈(绿)。\n",
+			strings.Join(symbols, ", "))
+	}
+	return fmt.Sprintf("\n## 📈 Charts\nCandlestick charts are attached for (in order): %s. They show EMA20 (orange), EMA50 (blue), Bollinger Bands (gray) and volume; held positions are marked with entry (yellow), stop loss (red) and take profit (green).\n",
+		strings.Join(symbols, ", "))
+}
@@ -0,0 +1,40 @@
+package kernel
+
+import (
+	"reflect"
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func TestBuildChartImagesIncludesHeldPositions(t *testing.T) {
+	series := &market.TimeframeSeriesData{Timeframe: "15m"}
+	for i := 0; i < 30; i++ {
+		price := 100 + float64(i)
+		series.Klines = append(series.Klines, market.KlineBar{Time: int64(i) * 900000, Open: price, High: price + 1, Low: price - 1, Close: price + 0.5, Volume: 10})
+	}
+	marketData := make(map[string]*market.Data)
+	for _, sym := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "XRPUSDT", "DOGEUSDT"} {
+		marketData[sym] = &market.Data{Symbol: sym, TimeframeData: map[string]*market.TimeframeSeriesData{"15m": series}}
+	}
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.Charts = &store.ChartConfig{Enabled: true, TopCandidates: 2, Timeframe: "15m"}
+	engine := NewStrategyEngine(&cfg)
+	ctx := &Context{
+		// DOGE is held but not a candidate; SOL is both
+		Positions: []PositionInfo{
+			{Symbol: "DOGEUSDT", Side: "long", EntryPrice: 110, StopLoss: 105, TakeProfit: 125},
+			{Symbol: "SOLUSDT", Side: "short", EntryPrice: 115},
+		},
+		CandidateCoins: []CandidateCoin{{Symbol: "BTCUSDT"}, {Symbol: "SOLUSDT"}, {Symbol: "ETHUSDT"}, {Symbol: "XRPUSDT"}},
+		MarketDataMap:  marketData,
+	}
+
+	images, symbols := engine.BuildChartImages(ctx)
+	want := []string{"DOGEUSDT", "SOLUSDT", "BTCUSDT", "ETHUSDT"}
+	if !reflect.DeepEqual(symbols, want) || len(images) != len(want) {
+		t.Fatalf("charted %v (%d images), want %v", symbols, len(images), want)
+	}
+}
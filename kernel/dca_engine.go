@@ -0,0 +1,378 @@
+package kernel
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// DCA Strategy - base order + safety orders, take profit from the average entry
+// ============================================================================
+
+// DCA deal statuses
+const (
+	DCADealActive = "active"
+	DCADealClosed = "closed"
+)
+
+// DCASafetyOrder one safety order level of a deal
+type DCASafetyOrder struct {
+	Index        int     `json:"index"`                  // 0 = first safety order
+	Price        float64 `json:"price"`                  // Limit / trigger price
+	DeviationPct float64 `json:"deviation_pct"`          // Cumulative deviation from the base order price (%)
+	OrderUSD     float64 `json:"order_usd"`              // Order size (notional)
+	Quantity     float64 `json:"quantity"`               // Order quantity at Price
+	State        string  `json:"state"`                  // "planned", "pending", "filled", "cancelled"
+	OrderID      string  `json:"order_id,omitempty"`     // Exchange order ID (if pending)
+	FilledPrice  float64 `json:"filled_price,omitempty"` // Average fill price (if filled)
+	FilledQty    float64 `json:"filled_qty,omitempty"`   // Filled quantity (if filled)
+}
+
+// DCADeal one DCA cycle: base order, safety orders and the take profit exit
+type DCADeal struct {
+	ID             string           `json:"id"`
+	Symbol         string           `json:"symbol"`
+	Side           string           `json:"side"` // "long" or "short"
+	Status         string           `json:"status"`
+	BasePrice      float64          `json:"base_price"`
+	Quantity       float64          `json:"quantity"` // Filled quantity
+	CostUSD        float64          `json:"cost_usd"` // Filled notional (sum of price × quantity)
+	SafetyOrders   []DCASafetyOrder `json:"safety_orders"`
+	TrailingActive bool             `json:"trailing_active"` // Take profit reached, trailing the best price
+	BestPrice      float64          `json:"best_price"`      // Best price since trailing started
+	OpenedAt       time.Time        `json:"opened_at"`
+	ClosedAt       time.Time        `json:"closed_at,omitempty"`
+	ClosePrice     float64          `json:"close_price,omitempty"`
+	RealizedPnL    float64          `json:"realized_pnl,omitempty"` // Net of Fees
+	Fees           float64          `json:"fees,omitempty"`         // Trading fees paid on the deal's fills
+}
+
+// DCAContext state handed to GetDCADecisions by the executor
+type DCAContext struct {
+	Config           *store.DCAStrategyConfig
+	Deal             *DCADeal // Active deal (nil = none)
+	CurrentPrice     float64
+	Now              time.Time
+	LastDealClosedAt time.Time // Start of the cooldown (zero = no previous deal)
+	LimitOrders      bool      // Safety orders are resting limit orders (live); otherwise market orders when price crosses the level
+}
+
+// ValidateDCAConfig validates a DCA configuration
+func ValidateDCAConfig(cfg *store.DCAStrategyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("dca_config is not set")
+	}
+	if strings.TrimSpace(cfg.Symbol) == "" {
+		return fmt.Errorf("dca_config.symbol is required")
+	}
+	if cfg.Side != "" && cfg.Side != "long" && cfg.Side != "short" {
+		return fmt.Errorf("dca_config.side must be long or short")
+	}
+	if cfg.Leverage < 1 || cfg.Leverage > 20 {
+		return fmt.Errorf("dca_config.leverage must be between 1 and 20")
+	}
+	if cfg.BaseOrderUSD <= 0 {
+		return fmt.Errorf("dca_config.base_order_usd must be positive")
+	}
+	if cfg.TakeProfitPct <= 0 {
+		return fmt.Errorf("dca_config.take_profit_pct must be positive")
+	}
+	if cfg.MaxSafetyOrders < 0 || cfg.MaxSafetyOrders > 25 {
+		return fmt.Errorf("dca_config.max_safety_orders must be between 0 and 25")
+	}
+	if cfg.TrailingDeviationPct < 0 || cfg.StopLossPct < 0 || cfg.CooldownMinutes < 0 {
+		return fmt.Errorf("dca_config.trailing_deviation_pct, stop_loss_pct and cooldown_minutes must not be negative")
+	}
+	if cfg.MaxSafetyOrders > 0 {
+		if cfg.SafetyOrderUSD <= 0 {
+			return fmt.Errorf("dca_config.safety_order_usd must be positive")
+		}
+		if cfg.PriceDeviationPct <= 0 {
+			return fmt.Errorf("dca_config.price_deviation_pct must be positive")
+		}
+		if cfg.StepScale < 0 || cfg.VolumeScale < 0 {
+			return fmt.Errorf("dca_config.step_scale and volume_scale must not be negative")
+		}
+		orders := PlanSafetyOrders(cfg, 1)
+		if last := orders[len(orders)-1]; cfg.Side != "short" && last.DeviationPct >= 100 {
+			return fmt.Errorf("last safety order deviation %.2f%% reaches zero price", last.DeviationPct)
+		}
+	}
+	return nil
+}
+
+// DCASide returns the deal direction of the config ("long" or "short")
+func DCASide(cfg *store.DCAStrategyConfig) string {
+	if cfg.Side == "short" {
+		return "short"
+	}
+	return "long"
+}
+
+// PlanSafetyOrders computes the safety order levels below (long) or above (short) the base order price.
+// Deviation steps grow with step_scale and order sizes with volume_scale.
+func PlanSafetyOrders(cfg *store.DCAStrategyConfig, basePrice float64) []DCASafetyOrder {
+	stepScale, volumeScale := cfg.StepScale, cfg.VolumeScale
+	if stepScale <= 0 {
+		stepScale = 1
+	}
+	if volumeScale <= 0 {
+		volumeScale = 1
+	}
+	sign := -1.0
+	if DCASide(cfg) == "short" {
+		sign = 1
+	}
+
+	orders := make([]DCASafetyOrder, 0, cfg.MaxSafetyOrders)
+	deviation, step, size := 0.0, cfg.PriceDeviationPct, cfg.SafetyOrderUSD
+	for i := 0; i < cfg.MaxSafetyOrders; i++ {
+		deviation += step
+		price := basePrice * (1 + sign*deviation/100)
+		so := DCASafetyOrder{
+			Index:        i,
+			Price:        price,
+			DeviationPct: deviation,
+			OrderUSD:     size,
+			State:        "planned",
+		}
+		if price > 0 {
+			so.Quantity = size / price
+		}
+		orders = append(orders, so)
+		step *= stepScale
+		size *= volumeScale
+	}
+	return orders
+}
+
+// NewDCADeal starts a deal from a filled base order
+func NewDCADeal(cfg *store.DCAStrategyConfig, basePrice, quantity float64, now time.Time) *DCADeal {
+	return &DCADeal{
+		ID:           fmt.Sprintf("dca_%s_%d", cfg.Symbol, now.UnixNano()),
+		Symbol:       cfg.Symbol,
+		Side:         DCASide(cfg),
+		Status:       DCADealActive,
+		BasePrice:    basePrice,
+		Quantity:     quantity,
+		CostUSD:      basePrice * quantity,
+		SafetyOrders: PlanSafetyOrders(cfg, basePrice),
+		OpenedAt:     now,
+	}
+}
+
+// AddFill adds a filled order to the deal
+func (d *DCADeal) AddFill(price, quantity float64) {
+	d.Quantity += quantity
+	d.CostUSD += price * quantity
+}
+
+// AverageEntry returns the average entry price of the filled orders
+func (d *DCADeal) AverageEntry() float64 {
+	if d.Quantity <= 0 {
+		return d.BasePrice
+	}
+	return d.CostUSD / d.Quantity
+}
+
+// FilledSafetyOrders returns the number of filled safety orders
+func (d *DCADeal) FilledSafetyOrders() int {
+	count := 0
+	for _, so := range d.SafetyOrders {
+		if so.State == "filled" {
+			count++
+		}
+	}
+	return count
+}
+
+// TakeProfitPrice returns the take profit price from the average entry
+func (d *DCADeal) TakeProfitPrice(cfg *store.DCAStrategyConfig) float64 {
+	if d.Side == "short" {
+		return d.AverageEntry() * (1 - cfg.TakeProfitPct/100)
+	}
+	return d.AverageEntry() * (1 + cfg.TakeProfitPct/100)
+}
+
+// StopLossPrice returns the stop loss price from the average entry (0 = disabled)
+func (d *DCADeal) StopLossPrice(cfg *store.DCAStrategyConfig) float64 {
+	if cfg.StopLossPct <= 0 {
+		return 0
+	}
+	if d.Side == "short" {
+		return d.AverageEntry() * (1 + cfg.StopLossPct/100)
+	}
+	return d.AverageEntry() * (1 - cfg.StopLossPct/100)
+}
+
+// checkExit checks take profit (with trailing) and stop loss, updating the trailing state
+func (d *DCADeal) checkExit(cfg *store.DCAStrategyConfig, price float64) (bool, string) {
+	// better reports whether a is a better exit price than b for the deal side
+	better := func(a, b float64) bool {
+		if d.Side == "short" {
+			return a <= b
+		}
+		return a >= b
+	}
+
+	if sl := d.StopLossPrice(cfg); sl > 0 && !better(price, sl) {
+		return true, fmt.Sprintf("stop loss %.4f hit (avg entry %.4f)", sl, d.AverageEntry())
+	}
+
+	tp := d.TakeProfitPrice(cfg)
+	if cfg.TrailingDeviationPct <= 0 {
+		if better(price, tp) {
+			return true, fmt.Sprintf("take profit %.4f reached (avg entry %.4f)", tp, d.AverageEntry())
+		}
+		return false, ""
+	}
+
+	if !d.TrailingActive {
+		if !better(price, tp) {
+			return false, ""
+		}
+		d.TrailingActive = true
+		d.BestPrice = price
+	}
+	if better(price, d.BestPrice) {
+		d.BestPrice = price
+	}
+	retrace := math.Abs(price-d.BestPrice) / d.BestPrice * 100
+	if retrace >= cfg.TrailingDeviationPct {
+		return true, fmt.Sprintf("trailing take profit: %.2f%% retrace from best %.4f", retrace, d.BestPrice)
+	}
+	return false, ""
+}
+
+// GetDCADecisions returns the decisions of one DCA cycle. No AI call is made.
+// The trailing take profit state of ctx.Deal is updated in place, so the executor should persist the deal.
+func GetDCADecisions(ctx *DCAContext) (*FullDecision, error) {
+	if ctx == nil || ctx.Config == nil {
+		return nil, fmt.Errorf("dca context is not available")
+	}
+	if ctx.CurrentPrice <= 0 {
+		return nil, fmt.Errorf("invalid price for %s: %.4f", ctx.Config.Symbol, ctx.CurrentPrice)
+	}
+	cfg := ctx.Config
+	side := DCASide(cfg)
+	var decisions []Decision
+	var trace strings.Builder
+
+	deal := ctx.Deal
+	if deal == nil || deal.Status != DCADealActive {
+		cooldown := time.Duration(cfg.CooldownMinutes) * time.Minute
+		if !ctx.LastDealClosedAt.IsZero() && ctx.Now.Sub(ctx.LastDealClosedAt) < cooldown {
+			fmt.Fprintf(&trace, "cooldown: %s left before the next deal\n",
+				(cooldown - ctx.Now.Sub(ctx.LastDealClosedAt)).Round(time.Second))
+		} else {
+			fmt.Fprintf(&trace, "no active deal: base order %.2f USDT at %.4f\n", cfg.BaseOrderUSD, ctx.CurrentPrice)
+			decisions = append(decisions, Decision{
+				Symbol:          cfg.Symbol,
+				Action:          "open_" + side,
+				Leverage:        cfg.Leverage,
+				PositionSizeUSD: cfg.BaseOrderUSD,
+				Price:           ctx.CurrentPrice,
+				Confidence:      100,
+				Reasoning:       "DCA base order",
+			})
+		}
+		return dcaFullDecision(cfg, decisions, &trace), nil
+	}
+
+	fmt.Fprintf(&trace, "deal %s: avg entry %.4f, qty %.6f, safety orders %d/%d, price %.4f\n",
+		deal.ID, deal.AverageEntry(), deal.Quantity, deal.FilledSafetyOrders(), len(deal.SafetyOrders), ctx.CurrentPrice)
+
+	if exit, reason := deal.checkExit(cfg, ctx.CurrentPrice); exit {
+		fmt.Fprintf(&trace, "exit: %s\n", reason)
+		decisions = append(decisions, Decision{
+			Symbol:     cfg.Symbol,
+			Action:     "close_" + side,
+			Price:      ctx.CurrentPrice,
+			EntryPrice: deal.AverageEntry(),
+			Confidence: 100,
+			Reasoning:  "DCA " + reason,
+		})
+		return dcaFullDecision(cfg, decisions, &trace), nil
+	}
+	if deal.TrailingActive {
+		fmt.Fprintf(&trace, "trailing take profit active, best price %.4f\n", deal.BestPrice)
+	}
+
+	for _, so := range deal.SafetyOrders {
+		if so.State != "planned" {
+			continue
+		}
+		if ctx.LimitOrders {
+			action := "place_buy_limit"
+			if side == "short" {
+				action = "place_sell_limit"
+			}
+			decisions = append(decisions, Decision{
+				Symbol:     cfg.Symbol,
+				Action:     action,
+				Leverage:   cfg.Leverage,
+				Price:      so.Price,
+				Quantity:   so.Quantity,
+				LevelIndex: so.Index,
+				Confidence: 100,
+				Reasoning:  fmt.Sprintf("DCA safety order #%d (deviation %.2f%%)", so.Index+1, so.DeviationPct),
+			})
+			continue
+		}
+		crossed := ctx.CurrentPrice <= so.Price
+		if side == "short" {
+			crossed = ctx.CurrentPrice >= so.Price
+		}
+		if crossed {
+			fmt.Fprintf(&trace, "safety order #%d triggered at %.4f\n", so.Index+1, so.Price)
+			decisions = append(decisions, Decision{
+				Symbol:          cfg.Symbol,
+				Action:          "open_" + side,
+				Leverage:        cfg.Leverage,
+				PositionSizeUSD: so.OrderUSD,
+				Price:           ctx.CurrentPrice,
+				LevelIndex:      so.Index,
+				Confidence:      100,
+				Reasoning:       fmt.Sprintf("DCA safety order #%d (deviation %.2f%%)", so.Index+1, so.DeviationPct),
+			})
+		}
+	}
+
+	return dcaFullDecision(cfg, decisions, &trace), nil
+}
+
+func dcaFullDecision(cfg *store.DCAStrategyConfig, decisions []Decision, trace *strings.Builder) *FullDecision {
+	logger.Infof("🪙 DCA decision: %d actions for %s", len(decisions), cfg.Symbol)
+	return &FullDecision{
+		SystemPrompt: describeDCAConfig(cfg),
+		CoTTrace:     trace.String(),
+		Decisions:    decisions,
+		Timestamp:    time.Now(),
+	}
+}
+
+// describeDCAConfig renders the configuration (stored as the "system prompt" of DCA decisions)
+func describeDCAConfig(cfg *store.DCAStrategyConfig) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# DCA strategy (%s %s, %dx)\n", cfg.Symbol, DCASide(cfg), cfg.Leverage)
+	fmt.Fprintf(&sb, "- base order: %.2f USDT\n", cfg.BaseOrderUSD)
+	if cfg.MaxSafetyOrders > 0 {
+		fmt.Fprintf(&sb, "- safety orders: %d × %.2f USDT, deviation %.2f%% (step scale %.2f, volume scale %.2f)\n",
+			cfg.MaxSafetyOrders, cfg.SafetyOrderUSD, cfg.PriceDeviationPct, cfg.StepScale, cfg.VolumeScale)
+	}
+	fmt.Fprintf(&sb, "- take profit: %.2f%% from average entry\n", cfg.TakeProfitPct)
+	if cfg.TrailingDeviationPct > 0 {
+		fmt.Fprintf(&sb, "- trailing deviation: %.2f%%\n", cfg.TrailingDeviationPct)
+	}
+	if cfg.StopLossPct > 0 {
+		fmt.Fprintf(&sb, "- stop loss: %.2f%% from average entry\n", cfg.StopLossPct)
+	}
+	if cfg.CooldownMinutes > 0 {
+		fmt.Fprintf(&sb, "- cooldown: %d minutes\n", cfg.CooldownMinutes)
+	}
+	return sb.String()
+}
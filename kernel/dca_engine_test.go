@@ -0,0 +1,168 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func dcaTestConfig() *store.DCAStrategyConfig {
+	return &store.DCAStrategyConfig{
+		Symbol:            "ETHUSDT",
+		Leverage:          3,
+		BaseOrderUSD:      100,
+		SafetyOrderUSD:    50,
+		MaxSafetyOrders:   3,
+		PriceDeviationPct: 2,
+		StepScale:         1.5,
+		VolumeScale:       2,
+		TakeProfitPct:     1.5,
+		CooldownMinutes:   30,
+	}
+}
+
+func TestPlanSafetyOrders(t *testing.T) {
+	orders := PlanSafetyOrders(dcaTestConfig(), 100)
+	want := []struct{ deviation, price, usd float64 }{
+		{2, 98, 50},
+		{5, 95, 100},
+		{9.5, 90.5, 200},
+	}
+	if len(orders) != len(want) {
+		t.Fatalf("orders = %d, want %d", len(orders), len(want))
+	}
+	for i, w := range want {
+		so := orders[i]
+		if math.Abs(so.DeviationPct-w.deviation) > 1e-9 || math.Abs(so.Price-w.price) > 1e-9 || so.OrderUSD != w.usd {
+			t.Errorf("order %d = %.2f%% @ %.2f (%.0f USDT), want %.2f%% @ %.2f (%.0f USDT)",
+				i, so.DeviationPct, so.Price, so.OrderUSD, w.deviation, w.price, w.usd)
+		}
+	}
+}
+
+func TestGetDCADecisions(t *testing.T) {
+	cfg := dcaTestConfig()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Cooldown blocks the next base order
+	fd, err := GetDCADecisions(&DCAContext{Config: cfg, CurrentPrice: 100, Now: now, LastDealClosedAt: now.Add(-10 * time.Minute)})
+	if err != nil || len(fd.Decisions) != 0 {
+		t.Fatalf("cooldown: decisions = %+v, err = %v, want none", fd, err)
+	}
+
+	// Base order after the cooldown
+	fd, _ = GetDCADecisions(&DCAContext{Config: cfg, CurrentPrice: 100, Now: now, LastDealClosedAt: now.Add(-time.Hour)})
+	if len(fd.Decisions) != 1 || fd.Decisions[0].Action != "open_long" || fd.Decisions[0].PositionSizeUSD != 100 {
+		t.Fatalf("base order: decisions = %+v", fd.Decisions)
+	}
+
+	// Resting safety orders for a new deal
+	deal := NewDCADeal(cfg, 100, 1, now)
+	fd, _ = GetDCADecisions(&DCAContext{Config: cfg, Deal: deal, CurrentPrice: 99, Now: now, LimitOrders: true})
+	if len(fd.Decisions) != 3 || fd.Decisions[2].Action != "place_buy_limit" || fd.Decisions[2].LevelIndex != 2 {
+		t.Fatalf("safety orders: decisions = %+v", fd.Decisions)
+	}
+
+	// Take profit from the average entry after a safety order fill: (100 + 98×2) / 3 = 98.67
+	deal.SafetyOrders[0].State = "filled"
+	deal.AddFill(98, 2)
+	for i := range deal.SafetyOrders[1:] {
+		deal.SafetyOrders[i+1].State = "pending"
+	}
+	fd, _ = GetDCADecisions(&DCAContext{Config: cfg, Deal: deal, CurrentPrice: 100.2, Now: now, LimitOrders: true})
+	if len(fd.Decisions) != 1 || fd.Decisions[0].Action != "close_long" {
+		t.Fatalf("take profit: decisions = %+v", fd.Decisions)
+	}
+}
+
+func TestDCATrailingTakeProfit(t *testing.T) {
+	cfg := dcaTestConfig()
+	cfg.MaxSafetyOrders = 0
+	cfg.TrailingDeviationPct = 1
+	deal := NewDCADeal(cfg, 100, 1, time.Now())
+
+	// TP price 101.5: trailing starts, follows the best price and exits on a 1% retrace
+	for _, step := range []struct {
+		price float64
+		exit  bool
+	}{
+		{101, false},
+		{102, false},
+		{104, false},
+		{103.5, false},
+		{102.9, true},
+	} {
+		fd, err := GetDCADecisions(&DCAContext{Config: cfg, Deal: deal, CurrentPrice: step.price, Now: time.Now()})
+		if err != nil {
+			t.Fatalf("GetDCADecisions() error: %v", err)
+		}
+		if exit := len(fd.Decisions) == 1; exit != step.exit {
+			t.Errorf("price %.1f: exit = %v, want %v", step.price, exit, step.exit)
+		}
+	}
+	if deal.BestPrice != 104 {
+		t.Errorf("best price = %.1f, want 104", deal.BestPrice)
+	}
+}
+
+func TestDCAStrategyTracksDeal(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "dca"
+	cfg.DCAConfig = dcaTestConfig()
+	s, err := NewStrategy(StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatalf("NewStrategy() error: %v", err)
+	}
+
+	cycle := func(price float64, positions []PositionInfo) []Decision {
+		ctx := &Context{
+			CurrentTime:   "2026-01-01 12:00:00 UTC",
+			Positions:     positions,
+			MarketDataMap: map[string]*market.Data{"ETHUSDT": {CurrentPrice: price}},
+		}
+		decisions, err := s.Decide(ctx)
+		if err != nil {
+			t.Fatalf("Decide() error: %v", err)
+		}
+		return decisions
+	}
+
+	if d := cycle(100, nil); len(d) != 1 || d[0].Action != "open_long" {
+		t.Fatalf("first cycle = %+v, want base order", d)
+	}
+	held := []PositionInfo{{Symbol: "ETHUSDT", Side: "long", EntryPrice: 100, Quantity: 1}}
+	if d := cycle(97, held); len(d) != 1 || d[0].LevelIndex != 0 || d[0].PositionSizeUSD != 50 {
+		t.Fatalf("price 97 = %+v, want safety order #1", d)
+	}
+	// Safety order #1 is filled and not repeated
+	held[0].EntryPrice, held[0].Quantity = 98.5, 1.5
+	if d := cycle(97, held); len(d) != 0 {
+		t.Fatalf("price 97 again = %+v, want none", d)
+	}
+	if d := cycle(100.5, held); len(d) != 1 || d[0].Action != "close_long" {
+		t.Fatalf("price 100.5 = %+v, want take profit", d)
+	}
+	// Cooldown after the deal
+	if d := cycle(100, nil); len(d) != 0 {
+		t.Fatalf("after close = %+v, want cooldown", d)
+	}
+}
+
+func TestValidateDCAConfig(t *testing.T) {
+	if err := ValidateDCAConfig(dcaTestConfig()); err != nil {
+		t.Fatalf("ValidateDCAConfig() error: %v", err)
+	}
+	cfg := dcaTestConfig()
+	cfg.PriceDeviationPct = 40 // 40 + 60 + 90: last safety order below zero
+	if err := ValidateDCAConfig(cfg); err == nil {
+		t.Error("deviation reaching zero price should fail")
+	}
+	cfg = dcaTestConfig()
+	cfg.TakeProfitPct = 0
+	if err := ValidateDCAConfig(cfg); err == nil {
+		t.Error("missing take profit should fail")
+	}
+}
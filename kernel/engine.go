@@ -132,6 +132,15 @@ type Context struct {
 	BTCETHLeverage     int                                `json:"-"`
 	AltcoinLeverage    int                                `json:"-"`
 	Timeframes         []string                           `json:"-"`
+
+	DecisionCalibration     *store.CalibrationReport `json:"-"` // Recent decision accuracy (optional)
+	CalibratedMinConfidence int                      `json:"-"` // Code-enforced dynamic min confidence (0 = not active)
+
+	Grid *GridContext `json:"-"` // Grid state (only set for grid strategies)
+	DCA  *DCAContext  `json:"-"` // DCA deal state (only set by executors that manage DCA deals)
+
+	FundingCarry *FundingCarryContext `json:"-"` // Cross-venue funding quotes and open pairs (only set for funding carry strategies)
+	Rebalance    *RebalanceContext    `json:"-"` // Target weights and mark prices (only set by executors that manage rebalancing)
 }
 
 // Decision AI trading decision
@@ -156,6 +165,9 @@ type Decision struct {
 	LevelIndex int     `json:"level_index,omitempty"` // Grid level index
 	OrderID    string  `json:"order_id,omitempty"`    // Order ID (for cancel)
 
+	// Multi-venue parameters
+	ExchangeID string `json:"exchange_id,omitempty"` // Exchange account to execute on (empty = the trader's own exchange)
+
 	// Common parameters
 	Confidence int     `json:"confidence,omitempty"` // Confidence level (0-100)
 	RiskUSD    float64 `json:"risk_usd,omitempty"`   // Maximum USD risk
@@ -211,6 +223,8 @@ type OIDeltaData struct {
 type StrategyEngine struct {
 	config       *store.StrategyConfig
 	nofxosClient *nofxos.Client
+	memoryStore  *store.DecisionMemoryStore // Optional: similarity-based decision memory
+	lessons      []string                   // Active lessons from trade self-reviews
 }
 
 // NewStrategyEngine creates strategy execution engine
@@ -306,9 +320,24 @@ func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *S
 	// 3. Build User Prompt using strategy engine
 	userPrompt := engine.BuildUserPrompt(ctx)
 
-	// 4. Call AI API
+	// 4. Call AI API (with chart images when enabled)
+	images, chartSymbols := engine.BuildChartImages(ctx)
+	var aiResponse string
+	var err error
 	aiCallStart := time.Now()
-	aiResponse, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	if len(images) > 0 {
+		userPrompt += engine.chartPromptNote(chartSymbols)
+		var request *mcp.Request
+		request, err = mcp.NewRequestBuilder().
+			WithSystemPrompt(systemPrompt).
+			AddUserMessageWithImages(userPrompt, images...).
+			Build()
+		if err == nil {
+			aiResponse, err = mcpClient.CallWithRequest(request)
+		}
+	} else {
+		aiResponse, err = mcpClient.CallWithMessages(systemPrompt, userPrompt)
+	}
 	aiCallDuration := time.Since(aiCallStart)
 	if err != nil {
 		return nil, fmt.Errorf("AI API call failed: %w", err)
@@ -343,14 +372,10 @@ func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *S
 // Market Data Fetching
 // ============================================================================
 
-// fetchMarketDataWithStrategy fetches market data using strategy config (multiple timeframes)
-func fetchMarketDataWithStrategy(ctx *Context, engine *StrategyEngine) error {
-	config := engine.GetConfig()
-	ctx.MarketDataMap = make(map[string]*market.Data)
-
-	timeframes := config.Indicators.Klines.SelectedTimeframes
-	primaryTimeframe := config.Indicators.Klines.PrimaryTimeframe
-	klineCount := config.Indicators.Klines.PrimaryCount
+// StrategyTimeframes returns the timeframes fetched for a strategy and its primary timeframe
+func StrategyTimeframes(klines store.KlineConfig) ([]string, string) {
+	timeframes := append([]string(nil), klines.SelectedTimeframes...)
+	primaryTimeframe := klines.PrimaryTimeframe
 
 	// Compatible with old configuration
 	if len(timeframes) == 0 {
@@ -359,13 +384,23 @@ func fetchMarketDataWithStrategy(ctx *Context, engine *StrategyEngine) error {
 		} else {
 			timeframes = append(timeframes, "3m")
 		}
-		if config.Indicators.Klines.LongerTimeframe != "" {
-			timeframes = append(timeframes, config.Indicators.Klines.LongerTimeframe)
+		if klines.LongerTimeframe != "" {
+			timeframes = append(timeframes, klines.LongerTimeframe)
 		}
 	}
 	if primaryTimeframe == "" {
 		primaryTimeframe = timeframes[0]
 	}
+	return timeframes, primaryTimeframe
+}
+
+// fetchMarketDataWithStrategy fetches market data using strategy config (multiple timeframes)
+func fetchMarketDataWithStrategy(ctx *Context, engine *StrategyEngine) error {
+	config := engine.GetConfig()
+	ctx.MarketDataMap = make(map[string]*market.Data)
+
+	timeframes, primaryTimeframe := StrategyTimeframes(config.Indicators.Klines)
+	klineCount := config.Indicators.Klines.PrimaryCount
 	if klineCount <= 0 {
 		klineCount = 30
 	}
@@ -1224,6 +1259,9 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 		sb.WriteString("Note: The above personalized strategy is a supplement to the basic rules and cannot violate the basic risk control principles.\n")
 	}
 
+	// 9. Lessons from trade self-reviews
+	e.writeLessons(&sb)
+
 	return sb.String()
 }
 
@@ -1323,24 +1361,9 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		ctx.Account.MarginUsedPct,
 		ctx.Account.PositionCount))
 
-	// Register records (历史决策记录)
-	registerConfig := RegisterConfig{
-		Enabled:           e.config.Register.Enabled,
-		MaxRecords:        e.config.Register.MaxRecords,
-		IncludeDecisions:  e.config.Register.IncludeDecisions,
-		IncludeMarketData: e.config.Register.IncludeMarketData,
-	}
-
-	if registerConfig.Enabled && registerConfig.MaxRecords > 0 {
-		// Create register instance using TraderID from context
-		traderID := ctx.TraderID
-		if traderID == "" {
-			traderID = "default"
-		}
-		register := NewRegister(traderID, registerConfig)
-		if prompt, err := register.BuildRegisterPrompt(); err == nil && prompt != "" {
-			sb.WriteString(prompt)
-		}
+	// Similar past setups (decision memory)
+	if prompt := e.buildMemoryPrompt(ctx); prompt != "" {
+		sb.WriteString(prompt)
 	}
 
 	// Recently completed orders (placed before positions to ensure visibility)
@@ -1421,6 +1444,11 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		sb.WriteString("\n")
 	}
 
+	// Recent decision accuracy (scored N bars after each decision)
+	if ctx.DecisionCalibration != nil && ctx.DecisionCalibration.Overall.Count > 0 {
+		sb.WriteString(e.formatDecisionCalibration(ctx.DecisionCalibration, ctx.CalibratedMinConfidence))
+	}
+
 	// Position information
 	if len(ctx.Positions) > 0 {
 		sb.WriteString("## Current Positions\n")
@@ -1494,6 +1522,50 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 	return sb.String()
 }
 
+// formatDecisionCalibration formats the "your recent accuracy" section
+func (e *StrategyEngine) formatDecisionCalibration(report *store.CalibrationReport, calibratedMinConfidence int) string {
+	var sb strings.Builder
+	overall := report.Overall
+
+	if e.GetLanguage() == LangChinese {
+		sb.WriteString("## 你的近期决策准确率\n")
+		sb.WriteString(fmt.Sprintf("已评估决策: %d | 命中率: %.1f%% | 平均前向收益: %+.2f%% | 止盈触发: %d | 止损触发: %d\n",
+			overall.Count, overall.HitRate, overall.AvgForwardReturnPct, overall.TakeProfitHits, overall.StopLossHits))
+		sb.WriteString("按信心度:")
+	} else {
+		sb.WriteString("## Your Recent Accuracy\n")
+		sb.WriteString(fmt.Sprintf("Scored decisions: %d | Hit rate: %.1f%% | Avg forward return: %+.2f%% | TP hits: %d | SL hits: %d\n",
+			overall.Count, overall.HitRate, overall.AvgForwardReturnPct, overall.TakeProfitHits, overall.StopLossHits))
+		sb.WriteString("By confidence:")
+	}
+	for _, bucket := range report.ByConfidence {
+		sb.WriteString(fmt.Sprintf(" [%s] %.0f%% (n=%d)", bucket.Key, bucket.HitRate, bucket.Count))
+	}
+	sb.WriteString("\n")
+
+	if len(report.ByAction) > 0 {
+		if e.GetLanguage() == LangChinese {
+			sb.WriteString("按动作:")
+		} else {
+			sb.WriteString("By action:")
+		}
+		for _, action := range report.ByAction {
+			sb.WriteString(fmt.Sprintf(" %s %.0f%% (n=%d)", action.Key, action.HitRate, action.Count))
+		}
+		sb.WriteString("\n")
+	}
+
+	if calibratedMinConfidence > 0 {
+		if e.GetLanguage() == LangChinese {
+			sb.WriteString(fmt.Sprintf("⚠️ 根据校准结果，开仓信心度需 ≥ %d (代码强制)\n", calibratedMinConfidence))
+		} else {
+			sb.WriteString(fmt.Sprintf("⚠️ Based on calibration, opening requires confidence ≥ %d (code enforced)\n", calibratedMinConfidence))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
 func (e *StrategyEngine) formatPositionInfo(index int, pos PositionInfo, ctx *Context) string {
 	var sb strings.Builder
 
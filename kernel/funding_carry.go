@@ -0,0 +1,286 @@
+package kernel
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/store"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Funding Carry Strategy - delta-neutral long/short across two venues
+//
+// Perp/perp: both legs are perpetuals on different exchange accounts. Perp vs. stable: the
+// long leg is held as spot on the trader's own account (no funding, never shorted) against a
+// short perpetual that receives positive funding.
+// ============================================================================
+
+// Funding carry defaults
+const (
+	defaultCarryMaxImbalancePct    = 10.0
+	defaultCarryMinLiquidationDist = 5.0
+)
+
+// CarryQuote funding rate of a symbol on one venue
+type CarryQuote struct {
+	ExchangeID    string  `json:"exchange_id"`    // Exchange account ID
+	Exchange      string  `json:"exchange"`       // Exchange type (binance, bybit, ...)
+	AnnualizedPct float64 `json:"annualized_pct"` // Annualized funding (%, paid by longs when positive)
+	MarkPrice     float64 `json:"mark_price"`
+	Spot          bool    `json:"spot,omitempty"` // Spot venue: pays no funding and only holds long legs
+}
+
+// CarryLeg one leg of a carry pair
+type CarryLeg struct {
+	ExchangeID       string  `json:"exchange_id"`
+	PositionID       int64   `json:"position_id,omitempty"` // trader_positions record
+	Quantity         float64 `json:"quantity"`
+	EntryPrice       float64 `json:"entry_price"`
+	MarkPrice        float64 `json:"mark_price"`
+	LiquidationPrice float64 `json:"liquidation_price"` // 0 = unknown
+	// Funding settled by the venue since the leg was opened
+	FundingPnL float64 `json:"funding_pnl"`
+	// Funding estimated from the quoted rates, for venues without a settlement history
+	EstimatedFundingPnL float64 `json:"estimated_funding_pnl"`
+}
+
+// Notional returns the current notional of the leg
+func (l *CarryLeg) Notional() float64 {
+	price := l.MarkPrice
+	if price <= 0 {
+		price = l.EntryPrice
+	}
+	return l.Quantity * price
+}
+
+// Funding returns the settled and estimated funding of the leg
+func (l *CarryLeg) Funding() float64 {
+	return l.FundingPnL + l.EstimatedFundingPnL
+}
+
+// CarryPair an open long/short pair of one symbol
+type CarryPair struct {
+	ID       string    `json:"id"`
+	Symbol   string    `json:"symbol"`
+	Long     CarryLeg  `json:"long"`
+	Short    CarryLeg  `json:"short"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// ImbalancePct returns the notional difference of the legs relative to the larger leg (%)
+func (p *CarryPair) ImbalancePct() float64 {
+	long, short := p.Long.Notional(), p.Short.Notional()
+	larger := math.Max(long, short)
+	if larger <= 0 {
+		return 0
+	}
+	return math.Abs(long-short) / larger * 100
+}
+
+// LiquidationDistancePct returns the distance of the closer leg to its liquidation price
+// (%, -1 when neither liquidation price is known)
+func (p *CarryPair) LiquidationDistancePct() float64 {
+	dist := -1.0
+	if p.Long.LiquidationPrice > 0 && p.Long.MarkPrice > 0 {
+		dist = (p.Long.MarkPrice - p.Long.LiquidationPrice) / p.Long.MarkPrice * 100
+	}
+	if p.Short.LiquidationPrice > 0 && p.Short.MarkPrice > 0 {
+		d := (p.Short.LiquidationPrice - p.Short.MarkPrice) / p.Short.MarkPrice * 100
+		if dist < 0 || d < dist {
+			dist = d
+		}
+	}
+	return dist
+}
+
+// FundingCarryContext state handed to GetFundingCarryDecisions by the executor
+type FundingCarryContext struct {
+	Config *store.FundingCarryStrategyConfig
+	Quotes map[string][]CarryQuote // symbol -> quotes of all venues
+	Pairs  []*CarryPair            // Open pairs (at most one per symbol)
+	Now    time.Time
+}
+
+// quote returns the quote of a symbol on a venue (nil if missing)
+func (c *FundingCarryContext) quote(symbol, exchangeID string) *CarryQuote {
+	for i := range c.Quotes[symbol] {
+		if c.Quotes[symbol][i].ExchangeID == exchangeID {
+			return &c.Quotes[symbol][i]
+		}
+	}
+	return nil
+}
+
+// ValidateFundingCarryConfig validates a funding carry configuration
+func ValidateFundingCarryConfig(cfg *store.FundingCarryStrategyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("funding_carry_config is not set")
+	}
+	if len(cfg.Symbols) == 0 {
+		return fmt.Errorf("funding_carry_config.symbols is required")
+	}
+	if len(cfg.HedgeExchangeIDs) == 0 && !cfg.SpotHedge {
+		return fmt.Errorf("funding_carry_config requires hedge_exchange_ids (perp vs. perp) or spot_hedge (perp vs. stable)")
+	}
+	if cfg.PositionSizeUSD <= 0 {
+		return fmt.Errorf("funding_carry_config.position_size_usd must be positive")
+	}
+	if cfg.Leverage < 1 || cfg.Leverage > 10 {
+		return fmt.Errorf("funding_carry_config.leverage must be between 1 and 10")
+	}
+	if cfg.MaxPairs < 1 {
+		return fmt.Errorf("funding_carry_config.max_pairs must be at least 1")
+	}
+	if cfg.MinEntryAPR <= 0 {
+		return fmt.Errorf("funding_carry_config.min_entry_apr must be positive")
+	}
+	if cfg.ExitAPR >= cfg.MinEntryAPR {
+		return fmt.Errorf("funding_carry_config.exit_apr must be below min_entry_apr")
+	}
+	if cfg.MaxImbalancePct < 0 || cfg.MinLiquidationDistancePct < 0 {
+		return fmt.Errorf("funding_carry_config thresholds cannot be negative")
+	}
+	return nil
+}
+
+// carrySpread best long/short venue combination of one symbol
+type carrySpread struct {
+	symbol    string
+	long      CarryQuote // Lowest funding: longs pay least / receive most
+	short     CarryQuote // Highest funding: shorts receive most
+	spreadAPR float64
+}
+
+// bestCarrySpread returns the widest spread of a symbol (false with fewer than two venues); spot
+// venues only take the long leg
+func bestCarrySpread(symbol string, quotes []CarryQuote) (carrySpread, bool) {
+	if len(quotes) < 2 {
+		return carrySpread{}, false
+	}
+	var lo, hi *CarryQuote
+	for i := range quotes {
+		q := &quotes[i]
+		if lo == nil || q.AnnualizedPct < lo.AnnualizedPct {
+			lo = q
+		}
+		if !q.Spot && (hi == nil || q.AnnualizedPct > hi.AnnualizedPct) {
+			hi = q
+		}
+	}
+	if hi == nil || lo.ExchangeID == hi.ExchangeID {
+		return carrySpread{}, false
+	}
+	return carrySpread{symbol: symbol, long: *lo, short: *hi, spreadAPR: hi.AnnualizedPct - lo.AnnualizedPct}, true
+}
+
+// GetFundingCarryDecisions unwinds pairs whose spread compressed or whose legs drifted apart,
+// then opens new pairs on the widest spreads
+func GetFundingCarryDecisions(ctx *FundingCarryContext) (*FullDecision, error) {
+	cfg := ctx.Config
+	if err := ValidateFundingCarryConfig(cfg); err != nil {
+		return nil, err
+	}
+	maxImbalance := cfg.MaxImbalancePct
+	if maxImbalance <= 0 {
+		maxImbalance = defaultCarryMaxImbalancePct
+	}
+	minLiqDist := cfg.MinLiquidationDistancePct
+	if minLiqDist <= 0 {
+		minLiqDist = defaultCarryMinLiquidationDist
+	}
+
+	var decisions []Decision
+	var trace strings.Builder
+	held := make(map[string]bool)
+	openPairs := 0
+
+	for _, pair := range ctx.Pairs {
+		held[pair.Symbol] = true
+		longQ, shortQ := ctx.quote(pair.Symbol, pair.Long.ExchangeID), ctx.quote(pair.Symbol, pair.Short.ExchangeID)
+
+		var reason string
+		switch {
+		case longQ == nil || shortQ == nil:
+			// Keep the hedge on when a venue is temporarily unavailable
+			fmt.Fprintf(&trace, "%s: funding quote missing, holding pair\n", pair.Symbol)
+		case shortQ.AnnualizedPct-longQ.AnnualizedPct < cfg.ExitAPR:
+			reason = fmt.Sprintf("spread compressed to %.2f%% APR (exit %.2f%%)", shortQ.AnnualizedPct-longQ.AnnualizedPct, cfg.ExitAPR)
+		}
+		if imb := pair.ImbalancePct(); reason == "" && imb > maxImbalance {
+			reason = fmt.Sprintf("leg imbalance %.2f%% exceeds %.2f%%", imb, maxImbalance)
+		}
+		if dist := pair.LiquidationDistancePct(); reason == "" && dist >= 0 && dist < minLiqDist {
+			reason = fmt.Sprintf("liquidation distance %.2f%% below %.2f%%", dist, minLiqDist)
+		}
+
+		if reason == "" {
+			openPairs++
+			if longQ != nil && shortQ != nil {
+				fmt.Fprintf(&trace, "%s: holding, spread %.2f%% APR, funding %.4f USDT\n",
+					pair.Symbol, shortQ.AnnualizedPct-longQ.AnnualizedPct, pair.Long.Funding()+pair.Short.Funding())
+			}
+			continue
+		}
+		fmt.Fprintf(&trace, "%s: unwinding, %s\n", pair.Symbol, reason)
+		decisions = append(decisions,
+			Decision{Symbol: pair.Symbol, Action: "close_long", ExchangeID: pair.Long.ExchangeID, Reasoning: "Funding carry unwind: " + reason},
+			Decision{Symbol: pair.Symbol, Action: "close_short", ExchangeID: pair.Short.ExchangeID, Reasoning: "Funding carry unwind: " + reason},
+		)
+	}
+
+	// Rank the spreads of symbols without a pair
+	var candidates []carrySpread
+	for _, symbol := range cfg.Symbols {
+		if held[symbol] {
+			continue
+		}
+		if sp, ok := bestCarrySpread(symbol, ctx.Quotes[symbol]); ok {
+			candidates = append(candidates, sp)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].spreadAPR > candidates[j].spreadAPR })
+
+	for _, sp := range candidates {
+		if openPairs >= cfg.MaxPairs {
+			break
+		}
+		if sp.spreadAPR < cfg.MinEntryAPR {
+			fmt.Fprintf(&trace, "%s: best spread %.2f%% APR below entry %.2f%%\n", sp.symbol, sp.spreadAPR, cfg.MinEntryAPR)
+			continue
+		}
+		reasoning := fmt.Sprintf("Funding carry: long %s (%.2f%% APR) / short %s (%.2f%% APR), spread %.2f%%",
+			sp.long.Exchange, sp.long.AnnualizedPct, sp.short.Exchange, sp.short.AnnualizedPct, sp.spreadAPR)
+		fmt.Fprintf(&trace, "%s: opening, %s\n", sp.symbol, reasoning)
+		decisions = append(decisions,
+			Decision{Symbol: sp.symbol, Action: "open_long", ExchangeID: sp.long.ExchangeID, Leverage: cfg.Leverage,
+				PositionSizeUSD: cfg.PositionSizeUSD, EntryPrice: sp.long.MarkPrice, Reasoning: reasoning},
+			Decision{Symbol: sp.symbol, Action: "open_short", ExchangeID: sp.short.ExchangeID, Leverage: cfg.Leverage,
+				PositionSizeUSD: cfg.PositionSizeUSD, EntryPrice: sp.short.MarkPrice, Reasoning: reasoning},
+		)
+		openPairs++
+	}
+
+	logger.Infof("💱 Funding carry decision: %d actions, %d pairs held", len(decisions), openPairs)
+	return &FullDecision{
+		SystemPrompt: describeFundingCarryConfig(cfg),
+		CoTTrace:     trace.String(),
+		Decisions:    decisions,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// describeFundingCarryConfig renders the configuration (stored as the "system prompt" of carry decisions)
+func describeFundingCarryConfig(cfg *store.FundingCarryStrategyConfig) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Funding carry strategy (%s, %dx)\n", strings.Join(cfg.Symbols, ", "), cfg.Leverage)
+	fmt.Fprintf(&sb, "- leg size: %.2f USDT, max pairs: %d\n", cfg.PositionSizeUSD, cfg.MaxPairs)
+	fmt.Fprintf(&sb, "- entry spread: %.2f%% APR, exit spread: %.2f%% APR\n", cfg.MinEntryAPR, cfg.ExitAPR)
+	fmt.Fprintf(&sb, "- hedge venues: %d\n", len(cfg.HedgeExchangeIDs))
+	if cfg.SpotHedge {
+		sb.WriteString("- spot hedge: long legs may be held as spot on the trader's own account\n")
+	}
+	return sb.String()
+}
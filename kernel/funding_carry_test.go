@@ -0,0 +1,137 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func carryTestConfig() *store.FundingCarryStrategyConfig {
+	return &store.FundingCarryStrategyConfig{
+		Symbols:          []string{"BTCUSDT", "ETHUSDT"},
+		HedgeExchangeIDs: []string{"ex-b"},
+		PositionSizeUSD:  1000,
+		Leverage:         2,
+		MaxPairs:         1,
+		MinEntryAPR:      20,
+		ExitAPR:          5,
+	}
+}
+
+func carryTestPair() *CarryPair {
+	return &CarryPair{
+		ID:     "carry_BTCUSDT_1",
+		Symbol: "BTCUSDT",
+		Long:   CarryLeg{ExchangeID: "ex-a", Quantity: 0.01, EntryPrice: 100000, MarkPrice: 100000},
+		Short:  CarryLeg{ExchangeID: "ex-b", Quantity: 0.01, EntryPrice: 100000, MarkPrice: 100000},
+	}
+}
+
+func TestGetFundingCarryDecisionsOpensWidestSpread(t *testing.T) {
+	ctx := &FundingCarryContext{
+		Config: carryTestConfig(),
+		Quotes: map[string][]CarryQuote{
+			"BTCUSDT": {
+				{ExchangeID: "ex-a", Exchange: "binance", AnnualizedPct: 5, MarkPrice: 100000},
+				{ExchangeID: "ex-b", Exchange: "bybit", AnnualizedPct: 30, MarkPrice: 100010},
+			},
+			"ETHUSDT": {
+				{ExchangeID: "ex-a", Exchange: "binance", AnnualizedPct: -10, MarkPrice: 3000},
+				{ExchangeID: "ex-b", Exchange: "bybit", AnnualizedPct: 40, MarkPrice: 3001},
+			},
+		},
+		Now: time.Now(),
+	}
+	fd, err := GetFundingCarryDecisions(ctx)
+	if err != nil {
+		t.Fatalf("GetFundingCarryDecisions() error: %v", err)
+	}
+	// MaxPairs 1: only ETH (50% spread) is opened, long on the low funding venue
+	if len(fd.Decisions) != 2 {
+		t.Fatalf("decisions = %+v, want one pair", fd.Decisions)
+	}
+	long, short := fd.Decisions[0], fd.Decisions[1]
+	if long.Symbol != "ETHUSDT" || long.Action != "open_long" || long.ExchangeID != "ex-a" {
+		t.Errorf("long leg = %+v", long)
+	}
+	if short.Action != "open_short" || short.ExchangeID != "ex-b" || short.PositionSizeUSD != 1000 {
+		t.Errorf("short leg = %+v", short)
+	}
+}
+
+func TestBestCarrySpreadSpotOnlyLong(t *testing.T) {
+	spot := CarryQuote{ExchangeID: "ex-a:spot", Exchange: "binance", Spot: true}
+
+	// Positive funding: long spot, short the perpetual
+	sp, ok := bestCarrySpread("BTCUSDT", []CarryQuote{spot, {ExchangeID: "ex-a", Exchange: "binance", AnnualizedPct: 25}})
+	if !ok || sp.long.ExchangeID != "ex-a:spot" || sp.short.ExchangeID != "ex-a" || sp.spreadAPR != 25 {
+		t.Errorf("spread = %+v (%v), want long spot / short perp at 25%%", sp, ok)
+	}
+
+	// Negative funding would need a spot short: no spread
+	if sp, ok := bestCarrySpread("BTCUSDT", []CarryQuote{spot, {ExchangeID: "ex-a", AnnualizedPct: -25}}); ok {
+		t.Errorf("spot cannot be shorted, got %+v", sp)
+	}
+}
+
+func TestGetFundingCarryDecisionsUnwind(t *testing.T) {
+	quotes := func(longAPR, shortAPR float64) map[string][]CarryQuote {
+		return map[string][]CarryQuote{"BTCUSDT": {
+			{ExchangeID: "ex-a", AnnualizedPct: longAPR},
+			{ExchangeID: "ex-b", AnnualizedPct: shortAPR},
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		quotes map[string][]CarryQuote
+		modify func(p *CarryPair)
+		unwind bool
+	}{
+		{"spread holds", quotes(0, 15), func(p *CarryPair) {}, false},
+		{"spread compressed", quotes(10, 12), func(p *CarryPair) {}, true},
+		{"quote missing", map[string][]CarryQuote{}, func(p *CarryPair) {}, false},
+		{"leg liquidated", quotes(0, 15), func(p *CarryPair) { p.Short.Quantity = 0 }, true},
+		{"near liquidation", quotes(0, 15), func(p *CarryPair) { p.Long.LiquidationPrice = 97000 }, true},
+		{"liquidation far", quotes(0, 15), func(p *CarryPair) { p.Long.LiquidationPrice = 60000 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := carryTestPair()
+			tt.modify(pair)
+			cfg := carryTestConfig()
+			cfg.Symbols = []string{"BTCUSDT"}
+			fd, err := GetFundingCarryDecisions(&FundingCarryContext{Config: cfg, Quotes: tt.quotes, Pairs: []*CarryPair{pair}})
+			if err != nil {
+				t.Fatalf("GetFundingCarryDecisions() error: %v", err)
+			}
+			if unwind := len(fd.Decisions) == 2; unwind != tt.unwind {
+				t.Fatalf("decisions = %+v, unwind want %v", fd.Decisions, tt.unwind)
+			}
+			if tt.unwind && (fd.Decisions[0].ExchangeID != "ex-a" || fd.Decisions[1].ExchangeID != "ex-b") {
+				t.Errorf("unwind legs on wrong exchanges: %+v", fd.Decisions)
+			}
+		})
+	}
+}
+
+func TestValidateFundingCarryConfig(t *testing.T) {
+	if err := ValidateFundingCarryConfig(carryTestConfig()); err != nil {
+		t.Fatalf("ValidateFundingCarryConfig() error: %v", err)
+	}
+	cfg := carryTestConfig()
+	cfg.HedgeExchangeIDs = nil
+	if err := ValidateFundingCarryConfig(cfg); err == nil {
+		t.Error("missing hedge exchange should fail")
+	}
+	cfg.SpotHedge = true
+	if err := ValidateFundingCarryConfig(cfg); err != nil {
+		t.Errorf("a spot hedge needs no hedge exchange: %v", err)
+	}
+	cfg = carryTestConfig()
+	cfg.ExitAPR = 25
+	if err := ValidateFundingCarryConfig(cfg); err == nil {
+		t.Error("exit APR above entry APR should fail")
+	}
+}
@@ -0,0 +1,136 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/store"
+	"strings"
+)
+
+// ============================================================================
+// Multi-Symbol Grid Allocation
+// ============================================================================
+
+// Capital allocation between the grids of a multi-symbol grid (GridStrategyConfig.Allocation)
+const (
+	GridAllocationFixed      = "fixed"
+	GridAllocationVolatility = "volatility"
+)
+
+// IsMultiSymbolGrid reports whether the configuration runs several symbol grids from one capital pool
+func IsMultiSymbolGrid(cfg *store.GridStrategyConfig) bool {
+	return cfg != nil && len(cfg.Symbols) > 0
+}
+
+// GridSymbolList returns the symbols traded by a grid configuration
+func GridSymbolList(cfg *store.GridStrategyConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+	if !IsMultiSymbolGrid(cfg) {
+		return []string{cfg.Symbol}
+	}
+	symbols := make([]string, len(cfg.Symbols))
+	for i, sc := range cfg.Symbols {
+		symbols[i] = sc.Symbol
+	}
+	return symbols
+}
+
+// validateGridSymbols validates the symbol list and allocation of a multi-symbol grid
+func validateGridSymbols(cfg *store.GridStrategyConfig) error {
+	switch cfg.Allocation {
+	case "", GridAllocationFixed, GridAllocationVolatility:
+	default:
+		return fmt.Errorf("grid_config.allocation must be '%s' or '%s'", GridAllocationFixed, GridAllocationVolatility)
+	}
+	seen := make(map[string]bool, len(cfg.Symbols))
+	for _, sc := range cfg.Symbols {
+		symbol := strings.ToUpper(strings.TrimSpace(sc.Symbol))
+		if symbol == "" {
+			return fmt.Errorf("grid_config.symbols entries need a symbol")
+		}
+		if seen[symbol] {
+			return fmt.Errorf("grid_config.symbols contains %s twice", symbol)
+		}
+		seen[symbol] = true
+		if sc.Weight < 0 {
+			return fmt.Errorf("grid_config.symbols weight of %s cannot be negative", symbol)
+		}
+		if sc.UpperPrice < 0 || sc.LowerPrice < 0 || (sc.UpperPrice > 0 && sc.LowerPrice >= sc.UpperPrice) {
+			return fmt.Errorf("grid_config.symbols bounds of %s must satisfy 0 < lower_price < upper_price", symbol)
+		}
+	}
+	return nil
+}
+
+// GridSymbolWeights returns the share of TotalInvestment of every symbol grid (summing to 1).
+// Volatility allocation divides each weight by the symbol volatility (e.g. ATR%); symbols
+// without a volatility reading get the average one, no readings at all falls back to fixed weights.
+func GridSymbolWeights(cfg *store.GridStrategyConfig, volatility map[string]float64) map[string]float64 {
+	weights := make(map[string]float64)
+	if !IsMultiSymbolGrid(cfg) {
+		if cfg != nil {
+			weights[cfg.Symbol] = 1
+		}
+		return weights
+	}
+
+	avgVol, known := 0.0, 0
+	if cfg.Allocation == GridAllocationVolatility {
+		for _, sc := range cfg.Symbols {
+			if v := volatility[sc.Symbol]; v > 0 {
+				avgVol += v
+				known++
+			}
+		}
+		if known > 0 {
+			avgVol /= float64(known)
+		}
+	}
+
+	total := 0.0
+	for _, sc := range cfg.Symbols {
+		w := sc.Weight
+		if w == 0 {
+			w = 1
+		}
+		if known > 0 {
+			v := volatility[sc.Symbol]
+			if v <= 0 {
+				v = avgVol
+			}
+			w /= v
+		}
+		weights[sc.Symbol] = w
+		total += w
+	}
+	for symbol := range weights {
+		weights[symbol] /= total
+	}
+	return weights
+}
+
+// GridSymbolConfigs derives the single-symbol configuration of every grid of a multi-symbol grid:
+// the shared settings with the symbol, its bounds and its share of TotalInvestment.
+// A symbol without bounds gets ATR bounds. A single-symbol configuration is returned as is.
+func GridSymbolConfigs(cfg *store.GridStrategyConfig, volatility map[string]float64) []*store.GridStrategyConfig {
+	if !IsMultiSymbolGrid(cfg) {
+		return []*store.GridStrategyConfig{cfg}
+	}
+	weights := GridSymbolWeights(cfg, volatility)
+	configs := make([]*store.GridStrategyConfig, 0, len(cfg.Symbols))
+	for _, sc := range cfg.Symbols {
+		symbolCfg := *cfg
+		symbolCfg.Symbols = nil
+		symbolCfg.Allocation = ""
+		symbolCfg.Symbol = sc.Symbol
+		symbolCfg.TotalInvestment = cfg.TotalInvestment * weights[sc.Symbol]
+		symbolCfg.UpperPrice = sc.UpperPrice
+		symbolCfg.LowerPrice = sc.LowerPrice
+		symbolCfg.UseATRBounds = cfg.UseATRBounds || sc.UpperPrice <= 0 || sc.LowerPrice <= 0
+		symbolCfg.TrailingUpperLimit = 0
+		symbolCfg.TrailingLowerLimit = 0
+		configs = append(configs, &symbolCfg)
+	}
+	return configs
+}
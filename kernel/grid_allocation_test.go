@@ -0,0 +1,76 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestGridSymbolWeights(t *testing.T) {
+	cfg := &store.GridStrategyConfig{
+		Symbols: []store.GridSymbolConfig{{Symbol: "BTCUSDT", Weight: 2}, {Symbol: "ETHUSDT"}, {Symbol: "SOLUSDT"}},
+	}
+	fixed := GridSymbolWeights(cfg, nil)
+	if math.Abs(fixed["BTCUSDT"]-0.5) > 1e-9 || math.Abs(fixed["ETHUSDT"]-0.25) > 1e-9 {
+		t.Errorf("fixed weights = %v, want BTC 0.5 and 0.25 for the others", fixed)
+	}
+
+	// ETH is twice as volatile as BTC, SOL has no reading and gets the 1.5% average
+	cfg.Allocation = GridAllocationVolatility
+	weights := GridSymbolWeights(cfg, map[string]float64{"BTCUSDT": 1, "ETHUSDT": 2})
+	raw := map[string]float64{"BTCUSDT": 2.0 / 1, "ETHUSDT": 1.0 / 2, "SOLUSDT": 1.0 / 1.5}
+	total := raw["BTCUSDT"] + raw["ETHUSDT"] + raw["SOLUSDT"]
+	for symbol, w := range raw {
+		if math.Abs(weights[symbol]-w/total) > 1e-9 {
+			t.Errorf("volatility weight of %s = %.4f, want %.4f", symbol, weights[symbol], w/total)
+		}
+	}
+
+	// No readings at all keeps the fixed split
+	if weights := GridSymbolWeights(cfg, nil); math.Abs(weights["BTCUSDT"]-0.5) > 1e-9 {
+		t.Errorf("volatility weights without readings = %v, want the fixed split", weights)
+	}
+}
+
+func TestGridSymbolConfigs(t *testing.T) {
+	cfg := &store.GridStrategyConfig{
+		GridCount: 10, TotalInvestment: 1000, Leverage: 3, UpperPrice: 110, LowerPrice: 90,
+		TrailingUpperLimit: 200,
+		Symbols: []store.GridSymbolConfig{
+			{Symbol: "BTCUSDT", Weight: 3, UpperPrice: 70000, LowerPrice: 60000},
+			{Symbol: "ETHUSDT", Weight: 1},
+		},
+	}
+	if err := ValidateGridConfig(cfg); err != nil {
+		t.Fatalf("valid multi-symbol config rejected: %v", err)
+	}
+
+	configs := GridSymbolConfigs(cfg, nil)
+	if len(configs) != 2 {
+		t.Fatalf("got %d configs, want 2", len(configs))
+	}
+	btc, eth := configs[0], configs[1]
+	if btc.Symbol != "BTCUSDT" || btc.TotalInvestment != 750 || btc.UpperPrice != 70000 || btc.UseATRBounds {
+		t.Errorf("unexpected BTC config: %+v", btc)
+	}
+	if eth.TotalInvestment != 250 || !eth.UseATRBounds || eth.UpperPrice != 0 {
+		t.Errorf("ETH without bounds should use ATR bounds: %+v", eth)
+	}
+	if btc.Leverage != 3 || btc.GridCount != 10 || len(btc.Symbols) != 0 || btc.TrailingUpperLimit != 0 {
+		t.Errorf("shared settings not carried over: %+v", btc)
+	}
+	if cfg.Symbol != "" || cfg.TotalInvestment != 1000 {
+		t.Error("the pool configuration must not be modified")
+	}
+
+	cfg.Symbols = append(cfg.Symbols, store.GridSymbolConfig{Symbol: "ethusdt"})
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected a duplicate symbol to be rejected")
+	}
+	cfg.Symbols = cfg.Symbols[:2]
+	cfg.Allocation = "random"
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected an unknown allocation to be rejected")
+	}
+}
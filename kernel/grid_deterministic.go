@@ -0,0 +1,304 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Deterministic Grid Mode
+// ============================================================================
+
+// Grid modes (GridStrategyConfig.Mode)
+const (
+	GridModeAI            = "ai"
+	GridModeDeterministic = "deterministic"
+)
+
+// IsDeterministicGrid reports whether the grid places its orders mechanically instead of asking the AI
+func IsDeterministicGrid(cfg *store.GridStrategyConfig) bool {
+	return cfg != nil && cfg.Mode == GridModeDeterministic
+}
+
+// GridAITuneInterval returns the AI re-tuning interval of a deterministic grid (0 = never)
+func GridAITuneInterval(cfg *store.GridStrategyConfig) time.Duration {
+	if !IsDeterministicGrid(cfg) || cfg.AITuneIntervalHours <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.AITuneIntervalHours * float64(time.Hour))
+}
+
+// GridRequiresAI reports whether a grid calls the AI: AI mode, or deterministic mode with re-tuning
+func GridRequiresAI(cfg *store.GridStrategyConfig) bool {
+	return !IsDeterministicGrid(cfg) || GridAITuneInterval(cfg) > 0
+}
+
+// ValidateGridConfig validates the grid configuration
+func ValidateGridConfig(cfg *store.GridStrategyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("grid_config is not set")
+	}
+	switch cfg.Mode {
+	case "", GridModeAI, GridModeDeterministic:
+	default:
+		return fmt.Errorf("grid_config.mode must be '%s' or '%s'", GridModeAI, GridModeDeterministic)
+	}
+	if cfg.GridCount < 2 {
+		return fmt.Errorf("grid_config.grid_count must be at least 2")
+	}
+	if cfg.AITuneIntervalHours < 0 {
+		return fmt.Errorf("grid_config.ai_tune_interval_hours cannot be negative")
+	}
+	if err := validateGridSpacing(cfg); err != nil {
+		return err
+	}
+	return validateGridSymbols(cfg)
+}
+
+// GridExitPrice returns the price of the paired order closing a filled level:
+// one step up for a buy, one step down for a sell
+func GridExitPrice(levels []GridLevelInfo, index int, spacing float64) float64 {
+	level := levels[index]
+	if level.Side == "sell" {
+		if index > 0 {
+			return levels[index-1].Price
+		}
+		return level.Price - spacing
+	}
+	if index < len(levels)-1 {
+		return levels[index+1].Price
+	}
+	return level.Price + spacing
+}
+
+// gridExitTarget returns the index of the level the paired exit of a filled level rests on (-1 = outside the grid)
+func gridExitTarget(levels []GridLevelInfo, index int) int {
+	target := index + 1
+	if levels[index].Side == "sell" {
+		target = index - 1
+	}
+	if target < 0 || target >= len(levels) {
+		return -1
+	}
+	return target
+}
+
+// gridEntrySide returns the side of the entry order of an empty level: neutral grids buy below the
+// price and sell above it, long/short grids only trade their side, bias grids keep the level side
+func gridEntrySide(level GridLevelInfo, direction market.GridDirection, price float64) string {
+	switch direction {
+	case market.GridDirectionLong:
+		return "buy"
+	case market.GridDirectionShort:
+		return "sell"
+	case market.GridDirectionLongBias, market.GridDirectionShortBias:
+		return level.Side
+	default:
+		if level.Price < price {
+			return "buy"
+		}
+		return "sell"
+	}
+}
+
+// GetDeterministicGridDecisions places the grid orders mechanically. Every filled level gets its
+// paired exit one step away; every empty level gets an entry order on its side of the price,
+// except the level closest to the price and the levels an exit order rests on.
+func GetDeterministicGridDecisions(ctx *GridContext, config *store.GridStrategyConfig) *FullDecision {
+	fd := &FullDecision{Timestamp: time.Now()}
+	levels := ctx.Levels
+	if ctx.IsPaused || len(levels) == 0 || ctx.CurrentPrice <= 0 {
+		fd.Decisions = []Decision{{Symbol: ctx.Symbol, Action: "hold", Reasoning: "Grid paused or not ready"}}
+		return fd
+	}
+
+	// Paired exits of filled levels
+	reserved := make(map[int]bool)
+	for i, level := range levels {
+		if level.State != "filled" {
+			continue
+		}
+		if target := gridExitTarget(levels, i); target >= 0 {
+			reserved[target] = true
+		}
+		if level.ExitOrderID != "" || level.PositionSize <= 0 {
+			continue
+		}
+		action, direction := "place_sell_limit", "up"
+		if level.Side == "sell" {
+			action, direction = "place_buy_limit", "down"
+		}
+		fd.Decisions = append(fd.Decisions, Decision{
+			Symbol:     ctx.Symbol,
+			Action:     action,
+			Price:      GridExitPrice(levels, i, ctx.GridSpacing),
+			Quantity:   level.PositionSize,
+			LevelIndex: i,
+			Confidence: 100,
+			Reasoning:  fmt.Sprintf("Level %d %s filled at %.4f, paired exit one step %s", i, level.Side, level.PositionEntry, direction),
+		})
+	}
+
+	// Entry orders on empty levels
+	nearest, nearestDist := -1, math.MaxFloat64
+	for i, level := range levels {
+		if dist := math.Abs(level.Price - ctx.CurrentPrice); dist < nearestDist {
+			nearest, nearestDist = i, dist
+		}
+	}
+	sizeFactor := 1 - math.Max(0, math.Min(ctx.PositionReductionPct, 100))/100
+	direction := market.GridDirection(ctx.CurrentDirection)
+	for i, level := range levels {
+		if level.State != "empty" || i == nearest || reserved[i] || level.Price <= 0 {
+			continue
+		}
+		side := gridEntrySide(level, direction, ctx.CurrentPrice)
+		action := ""
+		if side == "buy" && level.Price < ctx.CurrentPrice {
+			action = "place_buy_limit"
+		} else if side == "sell" && level.Price > ctx.CurrentPrice {
+			action = "place_sell_limit"
+		}
+		quantity := level.AllocatedUSD * float64(config.Leverage) / level.Price * sizeFactor
+		if action == "" || quantity <= 0 {
+			continue
+		}
+		fd.Decisions = append(fd.Decisions, Decision{
+			Symbol:     ctx.Symbol,
+			Action:     action,
+			Price:      level.Price,
+			Quantity:   quantity,
+			LevelIndex: i,
+			Confidence: 100,
+			Reasoning:  fmt.Sprintf("Level %d entry %s", i, side),
+		})
+	}
+
+	if len(fd.Decisions) == 0 {
+		fd.Decisions = []Decision{{Symbol: ctx.Symbol, Action: "hold", Reasoning: "All grid orders in place"}}
+	}
+	return fd
+}
+
+// ============================================================================
+// AI Re-tuning
+// ============================================================================
+
+// GridTuning grid parameters proposed by the AI for a deterministic grid.
+// The spacing follows from the bounds: (upper - lower) / (grid_count - 1).
+type GridTuning struct {
+	UpperPrice float64 `json:"upper_price"`
+	LowerPrice float64 `json:"lower_price"`
+	Direction  string  `json:"direction"` // Empty = keep the current direction
+	Reasoning  string  `json:"reasoning"`
+}
+
+// BuildGridTuningPrompt builds the system and user prompts asking the AI to re-tune the grid
+func BuildGridTuningPrompt(ctx *GridContext, config *store.GridStrategyConfig) (string, string) {
+	var sys strings.Builder
+	sys.WriteString("You are a grid trading expert re-tuning a mechanical futures grid.\n")
+	fmt.Fprintf(&sys, "The grid has %d levels evenly spaced between the lower and upper price; orders are placed automatically.\n", config.GridCount)
+	sys.WriteString("Choose bounds that contain the current price and suit the current volatility, and a direction:\n")
+	sys.WriteString("neutral (buy below, sell above), long / short (one side only), long_bias / short_bias.\n\n")
+	sys.WriteString("Output only the following JSON object:\n")
+	sys.WriteString("```json\n")
+	sys.WriteString("{\"upper_price\": 105000, \"lower_price\": 95000, \"direction\": \"neutral\", \"reasoning\": \"...\"}\n")
+	sys.WriteString("```\n")
+
+	var user strings.Builder
+	fmt.Fprintf(&user, "## %s grid\n\n", ctx.Symbol)
+	fmt.Fprintf(&user, "- Current price: %s\n", formatPriceSmart(ctx.CurrentPrice))
+	fmt.Fprintf(&user, "- Bounds: %s - %s, spacing %s, direction %s\n",
+		formatPriceSmart(ctx.LowerPrice), formatPriceSmart(ctx.UpperPrice), formatPriceSmart(ctx.GridSpacing), ctx.CurrentDirection)
+	fmt.Fprintf(&user, "- Levels: %d pending, %d filled | position %.4f\n", ctx.ActiveOrderCount, ctx.FilledLevelCount, ctx.CurrentPosition)
+	fmt.Fprintf(&user, "- ATR14 %.4f, Bollinger width %.2f%%, EMA20/50 distance %.2f%%, RSI14 %.1f\n",
+		ctx.ATR14, ctx.BollingerWidth, ctx.EMADistance, ctx.RSI14)
+	fmt.Fprintf(&user, "- Change 1h %+.2f%%, 4h %+.2f%%, funding %.4f%%\n", ctx.PriceChange1h, ctx.PriceChange4h, ctx.FundingRate*100)
+	if box := ctx.BoxData; box != nil {
+		fmt.Fprintf(&user, "- Boxes: short %s - %s, mid %s - %s, long %s - %s\n",
+			formatPriceSmart(box.ShortLower), formatPriceSmart(box.ShortUpper),
+			formatPriceSmart(box.MidLower), formatPriceSmart(box.MidUpper),
+			formatPriceSmart(box.LongLower), formatPriceSmart(box.LongUpper))
+	}
+	fmt.Fprintf(&user, "- Performance: profit %.2f USDT over %d trades, daily PnL %.2f\n", ctx.TotalProfit, ctx.TotalTrades, ctx.DailyPnL)
+	return sys.String(), user.String()
+}
+
+// ParseGridTuningResponse extracts the grid parameters from an AI response. The bounds must contain
+// the current price and the direction must be a known one.
+func ParseGridTuningResponse(response string, ctx *GridContext) (*GridTuning, error) {
+	s := removeInvisibleRunes(response)
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON object found in tuning response")
+	}
+
+	var tuning GridTuning
+	if err := json.Unmarshal([]byte(s[start:end+1]), &tuning); err != nil {
+		return nil, fmt.Errorf("failed to parse tuning response: %w", err)
+	}
+	if tuning.LowerPrice <= 0 || tuning.UpperPrice <= tuning.LowerPrice {
+		return nil, fmt.Errorf("invalid tuned bounds %.4f - %.4f", tuning.LowerPrice, tuning.UpperPrice)
+	}
+	if ctx.CurrentPrice < tuning.LowerPrice || ctx.CurrentPrice > tuning.UpperPrice {
+		return nil, fmt.Errorf("tuned bounds %.4f - %.4f do not contain the price %.4f",
+			tuning.LowerPrice, tuning.UpperPrice, ctx.CurrentPrice)
+	}
+	switch market.GridDirection(tuning.Direction) {
+	case "", market.GridDirectionNeutral, market.GridDirectionLong, market.GridDirectionShort,
+		market.GridDirectionLongBias, market.GridDirectionShortBias:
+	default:
+		return nil, fmt.Errorf("invalid tuned direction: %s", tuning.Direction)
+	}
+	tuning.Reasoning = strings.TrimSpace(tuning.Reasoning)
+	return &tuning, nil
+}
+
+// RefreshGridTuning asks the AI to re-tune a deterministic grid when a tuning is due and stores the
+// result in ctx.Tuning. Returns the prompts and response for the decision record (nil if not due).
+func RefreshGridTuning(ctx *GridContext, config *store.GridStrategyConfig, client mcp.AIClient) (*FullDecision, error) {
+	now := ctx.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	interval := GridAITuneInterval(config)
+	if interval <= 0 || client == nil || now.Sub(ctx.LastTunedAt) < interval {
+		return nil, nil
+	}
+
+	startTime := time.Now()
+	systemPrompt, userPrompt := BuildGridTuningPrompt(ctx, config)
+	response, err := client.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("AI tuning call failed: %w", err)
+	}
+	tuning, err := ParseGridTuningResponse(response, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Tuning = tuning
+	logger.Infof("🤖 [Grid] AI re-tuned grid: $%.2f - $%.2f, direction %q (%s)",
+		tuning.LowerPrice, tuning.UpperPrice, tuning.Direction, tuning.Reasoning)
+
+	return &FullDecision{
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+		CoTTrace:     extractCoTTrace(response),
+		Decisions: []Decision{{
+			Symbol:    ctx.Symbol,
+			Action:    "hold",
+			Reasoning: fmt.Sprintf("Grid re-tuned to %.4f - %.4f: %s", tuning.LowerPrice, tuning.UpperPrice, tuning.Reasoning),
+		}},
+		RawResponse:         response,
+		AIRequestDurationMs: time.Since(startTime).Milliseconds(),
+		Timestamp:           time.Now(),
+	}, nil
+}
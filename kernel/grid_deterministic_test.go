@@ -0,0 +1,113 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+
+	"nofx/store"
+)
+
+func deterministicGridTestContext() (*GridContext, *store.GridStrategyConfig) {
+	cfg := &store.GridStrategyConfig{Symbol: "BTCUSDT", GridCount: 5, Leverage: 2, Mode: GridModeDeterministic}
+	ctx := &GridContext{
+		Symbol:           "BTCUSDT",
+		CurrentPrice:     102.3,
+		GridSpacing:      1,
+		CurrentDirection: "neutral",
+		Levels: []GridLevelInfo{
+			{Index: 0, Price: 100, State: "filled", Side: "buy", PositionSize: 0.5, PositionEntry: 100, AllocatedUSD: 100},
+			{Index: 1, Price: 101, State: "empty", Side: "buy", AllocatedUSD: 100},
+			{Index: 2, Price: 102, State: "empty", Side: "buy", AllocatedUSD: 100},
+			{Index: 3, Price: 103, State: "empty", Side: "sell", AllocatedUSD: 100},
+			{Index: 4, Price: 104, State: "pending", Side: "sell", OrderID: "o4", AllocatedUSD: 100},
+		},
+	}
+	return ctx, cfg
+}
+
+func TestGetDeterministicGridDecisions(t *testing.T) {
+	ctx, cfg := deterministicGridTestContext()
+
+	fd := GetDeterministicGridDecisions(ctx, cfg)
+	if len(fd.Decisions) != 2 {
+		t.Fatalf("got %d decisions, want exit of level 0 and entry at level 3: %+v", len(fd.Decisions), fd.Decisions)
+	}
+	exit := fd.Decisions[0]
+	if exit.Action != "place_sell_limit" || exit.LevelIndex != 0 || exit.Price != 101 || exit.Quantity != 0.5 {
+		t.Errorf("unexpected paired exit: %+v", exit)
+	}
+	// Level 1 holds the exit and level 2 is closest to the price: no entries there
+	entry := fd.Decisions[1]
+	if entry.Action != "place_sell_limit" || entry.LevelIndex != 3 || math.Abs(entry.Quantity-200.0/103) > 1e-9 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	// Exit already placed, half size after a breakout
+	ctx.Levels[0].ExitOrderID = "x0"
+	ctx.PositionReductionPct = 50
+	fd = GetDeterministicGridDecisions(ctx, cfg)
+	if len(fd.Decisions) != 1 || math.Abs(fd.Decisions[0].Quantity-100.0/103) > 1e-9 {
+		t.Errorf("unexpected decisions: %+v", fd.Decisions)
+	}
+
+	ctx.IsPaused = true
+	fd = GetDeterministicGridDecisions(ctx, cfg)
+	if len(fd.Decisions) != 1 || fd.Decisions[0].Action != "hold" {
+		t.Errorf("paused grid placed orders: %+v", fd.Decisions)
+	}
+}
+
+func TestGridExitPrice(t *testing.T) {
+	ctx, _ := deterministicGridTestContext()
+	levels := ctx.Levels
+	levels[4].Side = "sell"
+	if p := GridExitPrice(levels, 4, 1); p != 103 {
+		t.Errorf("sell exit = %.2f, want one step down 103", p)
+	}
+	levels[4].Side = "buy"
+	if p := GridExitPrice(levels, 4, 1); p != 105 {
+		t.Errorf("buy exit at the top = %.2f, want 105", p)
+	}
+}
+
+func TestValidateGridConfig(t *testing.T) {
+	_, cfg := deterministicGridTestContext()
+	if err := ValidateGridConfig(cfg); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	if err := ValidateGridConfig(nil); err == nil {
+		t.Error("expected nil config to be rejected")
+	}
+	cfg.Mode = "manual"
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected unknown mode to be rejected")
+	}
+	cfg.Mode = ""
+	cfg.AITuneIntervalHours = -1
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected negative tune interval to be rejected")
+	}
+}
+
+func TestParseGridTuningResponse(t *testing.T) {
+	ctx, _ := deterministicGridTestContext()
+
+	tuning, err := ParseGridTuningResponse("thinking...\n```json\n{\"upper_price\": 110, \"lower_price\": 95, \"direction\": \"long_bias\", \"reasoning\": \" trend \"}\n```", ctx)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if tuning.UpperPrice != 110 || tuning.LowerPrice != 95 || tuning.Direction != "long_bias" || tuning.Reasoning != "trend" {
+		t.Errorf("unexpected tuning: %+v", tuning)
+	}
+
+	for _, response := range []string{
+		`{"upper_price": 101, "lower_price": 95}`,                    // Price outside the bounds
+		`{"upper_price": 95, "lower_price": 110}`,                    // Inverted bounds
+		`{"upper_price": 110, "lower_price": 95, "direction": "up"}`, // Unknown direction
+		"no json",
+	} {
+		if _, err := ParseGridTuningResponse(response, ctx); err == nil {
+			t.Errorf("expected %q to be rejected", response)
+		}
+	}
+}
@@ -27,6 +27,10 @@ type GridLevelInfo struct {
 	PositionEntry  float64 `json:"position_entry"`   // Entry price (if filled)
 	AllocatedUSD   float64 `json:"allocated_usd"`    // USD allocated to this level
 	UnrealizedPnL  float64 `json:"unrealized_pnl"`   // Unrealized P&L (if filled)
+	ExitOrderID    string  `json:"exit_order_id,omitempty"` // Paired exit order closing the filled position (deterministic mode)
+	ExitPrice      float64 `json:"exit_price,omitempty"`    // Paired exit order price
+	FilledAt       int64   `json:"filled_at,omitempty"`     // Entry fill time of the position (Unix ms)
+	EntryFee       float64 `json:"entry_fee,omitempty"`     // Fee paid by the entry fill
 }
 
 // GridContext contains all information needed for AI grid decision making
@@ -87,6 +91,20 @@ type GridContext struct {
 
 	// Grid direction (neutral, long, short, long_bias, short_bias)
 	CurrentDirection string `json:"current_direction,omitempty"`
+
+	// Position reduction after a box breakout (0 = normal, 50 = half size)
+	PositionReductionPct float64 `json:"position_reduction_pct,omitempty"`
+
+	// Deterministic mode AI re-tuning: last tuning (set by the executor) and the new
+	// parameters when a tuning happened this cycle (set by the strategy)
+	LastTunedAt time.Time   `json:"-"`
+	Tuning      *GridTuning `json:"-"`
+
+	// Time of the cycle (set by backtests replaying history; zero = wall clock)
+	Timestamp time.Time `json:"-"`
+
+	// Configuration of this symbol grid (multi-symbol grids; nil = the strategy grid config)
+	Config *store.GridStrategyConfig `json:"-"`
 }
 
 // ============================================================================
@@ -556,6 +574,7 @@ func isValidGridAction(action string) bool {
 // BuildGridContextFromMarketData builds grid context from market data
 func BuildGridContextFromMarketData(mktData *market.Data, config *store.GridStrategyConfig) *GridContext {
 	ctx := &GridContext{
+		Config:       config,
 		Symbol:       config.Symbol,
 		CurrentTime:  time.Now().Format("2006-01-02 15:04:05"),
 		CurrentPrice: mktData.CurrentPrice,
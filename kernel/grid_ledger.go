@@ -0,0 +1,200 @@
+package kernel
+
+import (
+	"sort"
+	"time"
+)
+
+// ============================================================================
+// Grid Round-Trip Ledger
+// ============================================================================
+
+// Round-trip exit reasons (GridRoundTrip.ExitReason)
+const (
+	GridExitPaired   = "paired_exit"
+	GridExitStopLoss = "stop_loss"
+)
+
+// GridRoundTrip is one completed entry → exit cycle of a grid level
+type GridRoundTrip struct {
+	Symbol     string    `json:"symbol"`
+	LevelIndex int       `json:"level_index"`
+	LevelPrice float64   `json:"level_price"`
+	Side       string    `json:"side"` // Entry side: "buy" (long round trip) or "sell" (short round trip)
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	EntryFee   float64   `json:"entry_fee"`
+	ExitFee    float64   `json:"exit_fee"`
+	GrossPnL   float64   `json:"gross_pnl"`
+	NetPnL     float64   `json:"net_pnl"` // Gross PnL minus the entry and exit fees
+	ExitReason string    `json:"exit_reason"`
+	OpenedAt   time.Time `json:"opened_at"`
+	ClosedAt   time.Time `json:"closed_at"`
+}
+
+// CloseGridRoundTrip builds the round trip of a filled level whose position closed at exitPrice
+func CloseGridRoundTrip(symbol string, level GridLevelInfo, exitPrice, exitFee float64, reason string, closedAt time.Time) GridRoundTrip {
+	gross := (exitPrice - level.PositionEntry) * level.PositionSize
+	if level.Side == "sell" {
+		gross = -gross
+	}
+	trip := GridRoundTrip{
+		Symbol:     symbol,
+		LevelIndex: level.Index,
+		LevelPrice: level.Price,
+		Side:       level.Side,
+		Quantity:   level.PositionSize,
+		EntryPrice: level.PositionEntry,
+		ExitPrice:  exitPrice,
+		EntryFee:   level.EntryFee,
+		ExitFee:    exitFee,
+		GrossPnL:   gross,
+		NetPnL:     gross - level.EntryFee - exitFee,
+		ExitReason: reason,
+		ClosedAt:   closedAt,
+	}
+	if level.FilledAt > 0 {
+		trip.OpenedAt = time.UnixMilli(level.FilledAt).UTC()
+	}
+	return trip
+}
+
+// GridInventoryPnL returns the unrealized PnL of the positions held by the filled levels at price
+func GridInventoryPnL(levels []GridLevelInfo, price float64) float64 {
+	pnl := 0.0
+	for _, level := range levels {
+		if level.State != "filled" || level.PositionEntry <= 0 {
+			continue
+		}
+		if level.Side == "sell" {
+			pnl += (level.PositionEntry - price) * level.PositionSize
+		} else {
+			pnl += (price - level.PositionEntry) * level.PositionSize
+		}
+	}
+	return pnl
+}
+
+// GridLevelProfit realized results of one grid level
+type GridLevelProfit struct {
+	Symbol     string  `json:"symbol"`
+	LevelIndex int     `json:"level_index"`
+	LevelPrice float64 `json:"level_price"` // Level price of the latest round trip
+	RoundTrips int     `json:"round_trips"`
+	Wins       int     `json:"wins"`
+	GrossPnL   float64 `json:"gross_pnl"`
+	Fees       float64 `json:"fees"`
+	NetPnL     float64 `json:"net_pnl"`
+}
+
+// GridDayProfit realized results of one UTC day
+type GridDayProfit struct {
+	Date       string  `json:"date"` // YYYY-MM-DD
+	RoundTrips int     `json:"round_trips"`
+	GrossPnL   float64 `json:"gross_pnl"`
+	Fees       float64 `json:"fees"`
+	NetPnL     float64 `json:"net_pnl"`
+}
+
+// GridLedger summarizes the round trips of a grid: per level, per day, and the grid APR
+// (realized round-trip profit) next to the unrealized PnL of the inventory the grid holds.
+type GridLedger struct {
+	RoundTrips    int               `json:"round_trips"`
+	Wins          int               `json:"wins"`
+	StopLosses    int               `json:"stop_losses"`
+	GrossPnL      float64           `json:"gross_pnl"`
+	Fees          float64           `json:"fees"`
+	NetPnL        float64           `json:"net_pnl"`
+	UnrealizedPnL float64           `json:"unrealized_pnl"` // Inventory of the filled levels at the current price
+	Days          float64           `json:"days"`           // Period the APRs are annualized over
+	GridAPR       float64           `json:"grid_apr"`       // Annualized net round-trip profit on the investment (%)
+	TotalAPR      float64           `json:"total_apr"`      // Same, including the unrealized inventory PnL (%)
+	Levels        []GridLevelProfit `json:"levels"`
+	Daily         []GridDayProfit   `json:"daily"`
+}
+
+// SummarizeGridLedger aggregates round trips by level and by day and annualizes the profit on
+// the investment over start → end
+func SummarizeGridLedger(trips []GridRoundTrip, investment, unrealized float64, start, end time.Time) *GridLedger {
+	ledger := &GridLedger{
+		Levels: []GridLevelProfit{},
+		Daily:  []GridDayProfit{},
+	}
+	type levelKey struct {
+		symbol string
+		index  int
+	}
+	levels := make(map[levelKey]*GridLevelProfit)
+	days := make(map[string]*GridDayProfit)
+
+	for _, trip := range trips {
+		fees := trip.EntryFee + trip.ExitFee
+		ledger.RoundTrips++
+		ledger.GrossPnL += trip.GrossPnL
+		ledger.Fees += fees
+		ledger.NetPnL += trip.NetPnL
+		if trip.NetPnL > 0 {
+			ledger.Wins++
+		}
+		if trip.ExitReason == GridExitStopLoss {
+			ledger.StopLosses++
+		}
+
+		key := levelKey{trip.Symbol, trip.LevelIndex}
+		lp, ok := levels[key]
+		if !ok {
+			lp = &GridLevelProfit{Symbol: trip.Symbol, LevelIndex: trip.LevelIndex}
+			levels[key] = lp
+		}
+		lp.LevelPrice = trip.LevelPrice
+		lp.RoundTrips++
+		lp.GrossPnL += trip.GrossPnL
+		lp.Fees += fees
+		lp.NetPnL += trip.NetPnL
+		if trip.NetPnL > 0 {
+			lp.Wins++
+		}
+
+		date := trip.ClosedAt.UTC().Format("2006-01-02")
+		dp, ok := days[date]
+		if !ok {
+			dp = &GridDayProfit{Date: date}
+			days[date] = dp
+		}
+		dp.RoundTrips++
+		dp.GrossPnL += trip.GrossPnL
+		dp.Fees += fees
+		dp.NetPnL += trip.NetPnL
+	}
+
+	for _, lp := range levels {
+		ledger.Levels = append(ledger.Levels, *lp)
+	}
+	sort.Slice(ledger.Levels, func(i, j int) bool {
+		if ledger.Levels[i].Symbol != ledger.Levels[j].Symbol {
+			return ledger.Levels[i].Symbol < ledger.Levels[j].Symbol
+		}
+		return ledger.Levels[i].LevelIndex < ledger.Levels[j].LevelIndex
+	})
+	for _, dp := range days {
+		ledger.Daily = append(ledger.Daily, *dp)
+	}
+	sort.Slice(ledger.Daily, func(i, j int) bool { return ledger.Daily[i].Date < ledger.Daily[j].Date })
+
+	ledger.Annualize(investment, unrealized, start, end)
+	return ledger
+}
+
+// Annualize sets the unrealized inventory PnL and the APRs of the investment over start → end
+func (l *GridLedger) Annualize(investment, unrealized float64, start, end time.Time) {
+	l.UnrealizedPnL = unrealized
+	l.Days, l.GridAPR, l.TotalAPR = 0, 0, 0
+	if !start.IsZero() && end.After(start) {
+		l.Days = end.Sub(start).Hours() / 24
+	}
+	if investment > 0 && l.Days > 0 {
+		l.GridAPR = l.NetPnL / investment / l.Days * 365 * 100
+		l.TotalAPR = (l.NetPnL + unrealized) / investment / l.Days * 365 * 100
+	}
+}
@@ -0,0 +1,54 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSummarizeGridLedger(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	long := GridLevelInfo{Index: 1, Price: 100, Side: "buy", PositionEntry: 100, PositionSize: 2, EntryFee: 0.04,
+		FilledAt: day.Add(time.Hour).UnixMilli()}
+	short := GridLevelInfo{Index: 3, Price: 110, Side: "sell", PositionEntry: 110, PositionSize: 1, EntryFee: 0.02}
+
+	trips := []GridRoundTrip{
+		CloseGridRoundTrip("BTCUSDT", long, 105, 0.04, GridExitPaired, day.Add(2*time.Hour)),
+		CloseGridRoundTrip("BTCUSDT", short, 105, 0.02, GridExitPaired, day.Add(26*time.Hour)),
+		CloseGridRoundTrip("BTCUSDT", long, 95, 0.1, GridExitStopLoss, day.Add(27*time.Hour)),
+	}
+	if trips[0].GrossPnL != 10 || math.Abs(trips[0].NetPnL-9.92) > 1e-9 || !trips[0].OpenedAt.Equal(day.Add(time.Hour)) {
+		t.Errorf("unexpected long round trip: %+v", trips[0])
+	}
+	if trips[1].GrossPnL != 5 {
+		t.Errorf("short round trip gross = %.2f, want 5", trips[1].GrossPnL)
+	}
+
+	ledger := SummarizeGridLedger(trips, 1000, -4, day, day.Add(10*24*time.Hour))
+	wantNet := 9.92 + 4.96 - 10.14
+	if ledger.RoundTrips != 3 || ledger.Wins != 2 || ledger.StopLosses != 1 || math.Abs(ledger.NetPnL-wantNet) > 1e-9 {
+		t.Errorf("unexpected totals: %+v", ledger)
+	}
+	if len(ledger.Levels) != 2 || ledger.Levels[0].LevelIndex != 1 || ledger.Levels[0].RoundTrips != 2 ||
+		math.Abs(ledger.Levels[0].NetPnL-(9.92-10.14)) > 1e-9 {
+		t.Errorf("unexpected per-level results: %+v", ledger.Levels)
+	}
+	if len(ledger.Daily) != 2 || ledger.Daily[0].Date != "2026-03-01" || ledger.Daily[1].RoundTrips != 2 {
+		t.Errorf("unexpected daily results: %+v", ledger.Daily)
+	}
+	if ledger.Days != 10 || math.Abs(ledger.GridAPR-wantNet/1000/10*365*100) > 1e-9 ||
+		math.Abs(ledger.TotalAPR-(wantNet-4)/1000/10*365*100) > 1e-9 {
+		t.Errorf("unexpected APRs: %.4f / %.4f over %.1f days", ledger.GridAPR, ledger.TotalAPR, ledger.Days)
+	}
+}
+
+func TestGridInventoryPnL(t *testing.T) {
+	levels := []GridLevelInfo{
+		{State: "filled", Side: "buy", PositionEntry: 100, PositionSize: 2},
+		{State: "filled", Side: "sell", PositionEntry: 110, PositionSize: 1},
+		{State: "pending", Side: "buy", PositionEntry: 90, PositionSize: 5},
+	}
+	if pnl := GridInventoryPnL(levels, 104); pnl != 8+6 {
+		t.Errorf("inventory PnL = %.2f, want 14", pnl)
+	}
+}
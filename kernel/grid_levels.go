@@ -0,0 +1,182 @@
+package kernel
+
+import (
+	"math"
+	"nofx/market"
+	"nofx/store"
+)
+
+// ============================================================================
+// Grid Layout (shared by the live grid trader and the backtest grid engine)
+// ============================================================================
+
+// GridBounds returns the grid bounds around the price: ±ATR × multiplier (default 2.0), or
+// ±3% scaled by the grid count when no ATR is available
+func GridBounds(price, atr float64, config *store.GridStrategyConfig) (upper, lower float64) {
+	if atr <= 0 {
+		multiplier := 0.03 * float64(config.GridCount) / 10
+		return price * (1 + multiplier), price * (1 - multiplier)
+	}
+
+	multiplier := config.ATRMultiplier
+	if multiplier <= 0 {
+		multiplier = 2.0
+	}
+	halfRange := atr * multiplier
+	return price + halfRange, price - halfRange
+}
+
+// GridLevelWeights returns the capital weight of each level for a distribution:
+// "gaussian" (more in the middle), "pyramid" (more at the bottom) or uniform
+func GridLevelWeights(count int, distribution string) []float64 {
+	weights := make([]float64, count)
+	for i := range weights {
+		switch distribution {
+		case "gaussian":
+			center := float64(count-1) / 2
+			sigma := float64(count) / 4
+			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
+		case "pyramid":
+			weights[i] = float64(count - i)
+		default: // uniform
+			weights[i] = 1.0
+		}
+	}
+	return weights
+}
+
+// NewGridLevels creates empty levels between the bounds with the investment split by the
+// distribution weights. Levels below the current price buy, levels above sell.
+func NewGridLevels(lower, upper, currentPrice float64, config *store.GridStrategyConfig) []GridLevelInfo {
+	weights := GridLevelWeights(config.GridCount, config.Distribution)
+	totalWeight := 0.0
+	for _, w := range weights {
+		totalWeight += w
+	}
+
+	prices := GridLevelPrices(lower, upper, config.GridCount, IsGeometricGrid(config))
+	levels := make([]GridLevelInfo, config.GridCount)
+	for i, price := range prices {
+		side := "buy"
+		if price > currentPrice {
+			side = "sell"
+		}
+		levels[i] = GridLevelInfo{
+			Index:        i,
+			Price:        price,
+			State:        "empty",
+			Side:         side,
+			AllocatedUSD: config.TotalInvestment * weights[i] / totalWeight,
+		}
+	}
+	return levels
+}
+
+// AssignGridSides redistributes the buy/sell sides of the levels for a grid direction:
+// neutral buys below the price and sells above, long/short use one side only and the bias
+// directions split the levels by the bias ratio (default 0.7). Levels holding an order or a
+// position keep their side.
+func AssignGridSides(levels []GridLevelInfo, direction market.GridDirection, biasRatio, currentPrice float64) {
+	kept := make(map[int]string)
+	for i, level := range levels {
+		if level.State != "empty" {
+			kept[i] = level.Side
+		}
+	}
+	defer func() {
+		for i, side := range kept {
+			levels[i].Side = side
+		}
+	}()
+
+	if biasRatio <= 0 || biasRatio > 1 {
+		biasRatio = 0.7
+	}
+	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
+
+	switch direction {
+	case market.GridDirectionNeutral:
+		for i := range levels {
+			if levels[i].Price <= currentPrice {
+				levels[i].Side = "buy"
+			} else {
+				levels[i].Side = "sell"
+			}
+		}
+
+	case market.GridDirectionLong:
+		for i := range levels {
+			levels[i].Side = "buy"
+		}
+
+	case market.GridDirectionShort:
+		for i := range levels {
+			levels[i].Side = "sell"
+		}
+
+	case market.GridDirectionLongBias, market.GridDirectionShortBias:
+		// long_bias keeps every level below the price as buy and converts some above to buy,
+		// short_bias keeps every level above as sell and converts some below to sell
+		totalLevels := len(levels)
+		targetBuyLevels := int(float64(totalLevels) * buyRatio)
+		buyCount, sellCount := 0, 0
+		for i := range levels {
+			needMoreBuys := buyCount < targetBuyLevels
+			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
+
+			side := "sell"
+			if levels[i].Price <= currentPrice {
+				if needMoreBuys {
+					side = "buy"
+				}
+			} else if needMoreSells && direction == market.GridDirectionShortBias {
+				side = "sell"
+			} else if needMoreBuys && direction == market.GridDirectionLongBias {
+				side = "buy"
+			} else if !needMoreSells {
+				side = "buy"
+			}
+
+			levels[i].Side = side
+			if side == "buy" {
+				buyCount++
+			} else {
+				sellCount++
+			}
+		}
+	}
+}
+
+// RestoreFilledLevels moves the positions of the filled levels of a previous ladder onto the level
+// of the new ladder closest to their entry price. Returns the indexes of the restored levels.
+func RestoreFilledLevels(levels, previous []GridLevelInfo) []int {
+	var restored []int
+	for _, filled := range previous {
+		if filled.State != "filled" {
+			continue
+		}
+		closestIdx := -1
+		closestDist := math.MaxFloat64
+		for i, level := range levels {
+			if dist := math.Abs(level.Price - filled.PositionEntry); dist < closestDist {
+				closestDist = dist
+				closestIdx = i
+			}
+		}
+		if closestIdx < 0 {
+			continue
+		}
+		level := &levels[closestIdx]
+		level.State = "filled"
+		level.Side = filled.Side
+		level.PositionEntry = filled.PositionEntry
+		level.PositionSize = filled.PositionSize
+		level.FilledAt = filled.FilledAt
+		level.EntryFee = filled.EntryFee
+		level.UnrealizedPnL = filled.UnrealizedPnL
+		level.OrderID = filled.OrderID
+		level.OrderQuantity = filled.OrderQuantity
+		restored = append(restored, closestIdx)
+	}
+	return restored
+}
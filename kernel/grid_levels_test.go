@@ -0,0 +1,59 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestGridBounds(t *testing.T) {
+	cfg := &store.GridStrategyConfig{GridCount: 10}
+	if upper, lower := GridBounds(100, 0, cfg); math.Abs(upper-103) > 1e-9 || math.Abs(lower-97) > 1e-9 {
+		t.Errorf("default bounds = %.2f - %.2f, want 97 - 103", lower, upper)
+	}
+	if upper, lower := GridBounds(100, 1.5, cfg); upper != 103 || lower != 97 {
+		t.Errorf("ATR bounds = %.2f - %.2f, want 97 - 103 (2 × ATR)", lower, upper)
+	}
+	cfg.ATRMultiplier = 4
+	if upper, lower := GridBounds(100, 1.5, cfg); upper != 106 || lower != 94 {
+		t.Errorf("ATR bounds = %.2f - %.2f, want 94 - 106", lower, upper)
+	}
+}
+
+func TestNewGridLevels(t *testing.T) {
+	cfg := &store.GridStrategyConfig{GridCount: 5, TotalInvestment: 1000, Distribution: "pyramid"}
+	levels := NewGridLevels(90, 110, 101, cfg)
+
+	total := 0.0
+	for i, level := range levels {
+		total += level.AllocatedUSD
+		wantSide := "buy"
+		if level.Price > 101 {
+			wantSide = "sell"
+		}
+		if level.Side != wantSide || level.State != "empty" {
+			t.Errorf("level %d at %.2f: side %s state %s", i, level.Price, level.Side, level.State)
+		}
+	}
+	if math.Abs(total-1000) > 1e-9 || levels[0].AllocatedUSD <= levels[4].AllocatedUSD {
+		t.Errorf("pyramid allocation should sum to 1000 and weigh the bottom: %+v", levels)
+	}
+}
+
+func TestRestoreFilledLevels(t *testing.T) {
+	cfg := &store.GridStrategyConfig{GridCount: 5, TotalInvestment: 500}
+	previous := NewGridLevels(90, 110, 100, cfg)
+	previous[1].State = "filled"
+	previous[1].PositionEntry = 95
+	previous[1].PositionSize = 2
+
+	levels := NewGridLevels(94, 102, 100, cfg)
+	restored := RestoreFilledLevels(levels, previous)
+	if len(restored) != 1 || restored[0] != 0 {
+		t.Fatalf("restored levels = %v, want [0] (94 is closest to the 95 entry)", restored)
+	}
+	if levels[0].State != "filled" || levels[0].PositionSize != 2 || levels[0].PositionEntry != 95 {
+		t.Errorf("filled position not carried over: %+v", levels[0])
+	}
+}
@@ -0,0 +1,256 @@
+package kernel
+
+import (
+	"nofx/market"
+	"time"
+)
+
+// ============================================================================
+// Grid Regime and Box Breakout Rules (shared by the live grid trader and the
+// backtest grid engine)
+// ============================================================================
+
+// ClassifyRegimeLevel determines the regime level based on market indicators
+// bollingerWidth: Bollinger band width as percentage
+// atr14Pct: ATR14 as percentage of current price
+func ClassifyRegimeLevel(bollingerWidth, atr14Pct float64) market.RegimeLevel {
+	// Narrow: Bollinger < 2%, ATR < 1%
+	if bollingerWidth < 2.0 && atr14Pct < 1.0 {
+		return market.RegimeLevelNarrow
+	}
+
+	// Standard: Bollinger 2-3%, ATR 1-2%
+	if bollingerWidth <= 3.0 && atr14Pct <= 2.0 {
+		return market.RegimeLevelStandard
+	}
+
+	// Wide: Bollinger 3-4%, ATR 2-3%
+	if bollingerWidth <= 4.0 && atr14Pct <= 3.0 {
+		return market.RegimeLevelWide
+	}
+
+	// Volatile: Bollinger > 4%, ATR > 3%
+	return market.RegimeLevelVolatile
+}
+
+// ============================================================================
+// Breakout Detection
+// ============================================================================
+
+// DetectBoxBreakout checks if price has broken out of any box level
+// Returns the highest breakout level and direction
+func DetectBoxBreakout(box *market.BoxData) (market.BreakoutLevel, string) {
+	if box == nil {
+		return market.BreakoutNone, ""
+	}
+
+	price := box.CurrentPrice
+
+	// Check long box first (highest priority)
+	if price > box.LongUpper {
+		return market.BreakoutLong, "up"
+	}
+	if price < box.LongLower {
+		return market.BreakoutLong, "down"
+	}
+
+	// Check mid box
+	if price > box.MidUpper {
+		return market.BreakoutMid, "up"
+	}
+	if price < box.MidLower {
+		return market.BreakoutMid, "down"
+	}
+
+	// Check short box
+	if price > box.ShortUpper {
+		return market.BreakoutShort, "up"
+	}
+	if price < box.ShortLower {
+		return market.BreakoutShort, "down"
+	}
+
+	return market.BreakoutNone, ""
+}
+
+// ============================================================================
+// Breakout Confirmation Logic
+// ============================================================================
+
+const BoxBreakoutConfirmRequired = 3 // 3 candles to confirm breakout
+
+// BoxBreakoutState tracks the current breakout state
+type BoxBreakoutState struct {
+	Level        market.BreakoutLevel
+	Direction    string
+	ConfirmCount int
+	StartTime    time.Time
+}
+
+// ConfirmBoxBreakout updates breakout state and returns true if breakout is confirmed
+func ConfirmBoxBreakout(state *BoxBreakoutState, currentLevel market.BreakoutLevel, direction string) bool {
+	// If price returned to box, reset state
+	if currentLevel == market.BreakoutNone {
+		state.ConfirmCount = 0
+		state.Level = market.BreakoutNone
+		state.Direction = ""
+		return false
+	}
+
+	// If same breakout continues, increment count
+	if state.Level == currentLevel && state.Direction == direction {
+		state.ConfirmCount++
+	} else {
+		// New breakout, reset count
+		state.Level = currentLevel
+		state.Direction = direction
+		state.ConfirmCount = 1
+		state.StartTime = time.Now()
+	}
+
+	return state.ConfirmCount >= BoxBreakoutConfirmRequired
+}
+
+// ============================================================================
+// Breakout Handler
+// ============================================================================
+
+// BoxBreakoutAction represents the action to take on breakout
+type BoxBreakoutAction int
+
+const (
+	BoxBreakoutActionNone           BoxBreakoutAction = iota
+	BoxBreakoutActionReducePosition                   // Short box breakout: reduce to 50%
+	BoxBreakoutActionPauseGrid                        // Mid box breakout: pause grid + cancel orders
+	BoxBreakoutActionCloseAll                         // Long box breakout: pause + cancel + close all
+)
+
+// GetBoxBreakoutAction returns the appropriate action for a breakout level
+func GetBoxBreakoutAction(level market.BreakoutLevel) BoxBreakoutAction {
+	switch level {
+	case market.BreakoutShort:
+		return BoxBreakoutActionReducePosition
+	case market.BreakoutMid:
+		return BoxBreakoutActionPauseGrid
+	case market.BreakoutLong:
+		return BoxBreakoutActionCloseAll
+	default:
+		return BoxBreakoutActionNone
+	}
+}
+
+// ============================================================================
+// Grid Direction Adjustment
+// ============================================================================
+
+const (
+	// BoxBreakoutActionAdjustDirection adjusts grid direction based on breakout
+	BoxBreakoutActionAdjustDirection BoxBreakoutAction = 4
+)
+
+// DetermineGridDirection determines the new grid direction based on box breakout
+// currentDirection: the current grid direction
+// breakoutLevel: which box level has been broken (short/mid/long)
+// direction: breakout direction ("up" or "down")
+// Returns: the new grid direction
+func DetermineGridDirection(box *market.BoxData, currentDirection market.GridDirection, breakoutLevel market.BreakoutLevel, direction string) market.GridDirection {
+	if box == nil {
+		return currentDirection
+	}
+
+	price := box.CurrentPrice
+
+	switch breakoutLevel {
+	case market.BreakoutShort:
+		// Short box breakout: bias direction
+		// Still within mid box, so not a full trend yet
+		if direction == "up" {
+			return market.GridDirectionLongBias
+		}
+		return market.GridDirectionShortBias
+
+	case market.BreakoutMid:
+		// Mid box breakout: full direction
+		// More significant move, commit fully
+		if direction == "up" {
+			return market.GridDirectionLong
+		}
+		return market.GridDirectionShort
+
+	case market.BreakoutLong:
+		// Long box breakout: handled by existing emergency logic
+		// Return current direction, let existing handlers take over
+		return currentDirection
+
+	case market.BreakoutNone:
+		// No breakout - check if we should recover toward neutral
+		return DetermineRecoveryDirection(price, box, currentDirection)
+
+	default:
+		return currentDirection
+	}
+}
+
+// DetermineRecoveryDirection determines if grid direction should recover toward neutral
+// This implements the gradual recovery logic: long → long_bias → neutral ← short_bias ← short
+func DetermineRecoveryDirection(price float64, box *market.BoxData, currentDirection market.GridDirection) market.GridDirection {
+	// Check if price is back inside the short box
+	insideShortBox := price >= box.ShortLower && price <= box.ShortUpper
+
+	if !insideShortBox {
+		// Still outside short box, maintain current direction
+		return currentDirection
+	}
+
+	// Price is inside short box, start recovery toward neutral
+	switch currentDirection {
+	case market.GridDirectionLong:
+		// Full long → bias long
+		return market.GridDirectionLongBias
+	case market.GridDirectionLongBias:
+		// Bias long → neutral
+		return market.GridDirectionNeutral
+	case market.GridDirectionShort:
+		// Full short → bias short
+		return market.GridDirectionShortBias
+	case market.GridDirectionShortBias:
+		// Bias short → neutral
+		return market.GridDirectionNeutral
+	default:
+		return currentDirection
+	}
+}
+
+// GetBoxBreakoutActionWithDirection returns the appropriate action for a breakout level
+// when direction adjustment is enabled
+func GetBoxBreakoutActionWithDirection(level market.BreakoutLevel, enableDirectionAdjust bool) BoxBreakoutAction {
+	if !enableDirectionAdjust {
+		// Fall back to original behavior
+		return GetBoxBreakoutAction(level)
+	}
+
+	switch level {
+	case market.BreakoutShort:
+		// Short box breakout with direction adjustment: adjust direction instead of reducing position
+		return BoxBreakoutActionAdjustDirection
+	case market.BreakoutMid:
+		// Mid box breakout with direction adjustment: adjust to full direction
+		return BoxBreakoutActionAdjustDirection
+	case market.BreakoutLong:
+		// Long box breakout: always trigger emergency handling
+		return BoxBreakoutActionCloseAll
+	default:
+		return BoxBreakoutActionNone
+	}
+}
+
+// ShouldRecoverDirection checks if the current grid direction should start recovering toward neutral
+func ShouldRecoverDirection(box *market.BoxData, currentDirection market.GridDirection) bool {
+	if box == nil || currentDirection == market.GridDirectionNeutral {
+		return false
+	}
+
+	price := box.CurrentPrice
+	// Check if price is back inside the short box
+	return price >= box.ShortLower && price <= box.ShortUpper
+}
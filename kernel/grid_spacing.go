@@ -0,0 +1,98 @@
+package kernel
+
+import (
+	"fmt"
+	"math"
+	"nofx/store"
+)
+
+// ============================================================================
+// Grid Spacing and Trailing
+// ============================================================================
+
+// Grid level spacing (GridStrategyConfig.Spacing)
+const (
+	GridSpacingArithmetic = "arithmetic"
+	GridSpacingGeometric  = "geometric"
+)
+
+// IsGeometricGrid reports whether the grid levels are spaced by equal percentages
+func IsGeometricGrid(cfg *store.GridStrategyConfig) bool {
+	return cfg != nil && cfg.Spacing == GridSpacingGeometric
+}
+
+// validateGridSpacing validates the spacing and trailing settings of a grid configuration
+func validateGridSpacing(cfg *store.GridStrategyConfig) error {
+	switch cfg.Spacing {
+	case "", GridSpacingArithmetic, GridSpacingGeometric:
+	default:
+		return fmt.Errorf("grid_config.spacing must be '%s' or '%s'", GridSpacingArithmetic, GridSpacingGeometric)
+	}
+	if cfg.TrailingUpperLimit < 0 || cfg.TrailingLowerLimit < 0 {
+		return fmt.Errorf("grid_config trailing limits cannot be negative")
+	}
+	if cfg.TrailingUpperLimit > 0 && cfg.TrailingLowerLimit >= cfg.TrailingUpperLimit {
+		return fmt.Errorf("grid_config.trailing_lower_limit must be below trailing_upper_limit")
+	}
+	return nil
+}
+
+// GridLevelPrices returns the prices of count levels from lower to upper: equal price steps for an
+// arithmetic grid, equal percentage steps for a geometric grid
+func GridLevelPrices(lower, upper float64, count int, geometric bool) []float64 {
+	if count <= 0 {
+		return nil
+	}
+	prices := make([]float64, count)
+	if count == 1 {
+		prices[0] = lower
+		return prices
+	}
+	for i := range prices {
+		if geometric && lower > 0 {
+			prices[i] = lower * math.Pow(upper/lower, float64(i)/float64(count-1))
+		} else {
+			prices[i] = lower + float64(i)*(upper-lower)/float64(count-1)
+		}
+	}
+	return prices
+}
+
+// GridSpacingPct returns the step between adjacent levels in percent: constant for a geometric
+// grid, measured at the lower bound for an arithmetic grid
+func GridSpacingPct(lower, upper float64, count int, geometric bool) float64 {
+	if count < 2 || lower <= 0 || upper <= lower {
+		return 0
+	}
+	if geometric {
+		return (math.Pow(upper/lower, 1/float64(count-1)) - 1) * 100
+	}
+	return (upper - lower) / float64(count-1) / lower * 100
+}
+
+// GridTrailBounds shifts the bounds by the fewest whole grid steps that bring the price back into
+// the range, keeping the shape of the ladder. Returns the new bounds and the number of steps
+// (positive = up, 0 = price already inside the range).
+func GridTrailBounds(lower, upper float64, count int, geometric bool, price float64) (float64, float64, int) {
+	if count < 2 || lower <= 0 || upper <= lower || price <= 0 || (price >= lower && price <= upper) {
+		return lower, upper, 0
+	}
+
+	if geometric {
+		logRatio := math.Log(upper/lower) / float64(count-1)
+		steps := int(math.Ceil(math.Log(price/upper) / logRatio))
+		if price < lower {
+			steps = -int(math.Ceil(math.Log(lower/price) / logRatio))
+		}
+		factor := math.Exp(float64(steps) * logRatio)
+		return lower * factor, upper * factor, steps
+	}
+
+	step := (upper - lower) / float64(count-1)
+	steps := int(math.Ceil((price - upper) / step))
+	if price < lower {
+		steps = -int(math.Ceil((lower - price) / step))
+	}
+	shift := float64(steps) * step
+	return lower + shift, upper + shift, steps
+}
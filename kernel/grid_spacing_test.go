@@ -0,0 +1,72 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestGridLevelPrices(t *testing.T) {
+	arithmetic := GridLevelPrices(100, 200, 5, false)
+	for i, want := range []float64{100, 125, 150, 175, 200} {
+		if math.Abs(arithmetic[i]-want) > 1e-9 {
+			t.Errorf("arithmetic level %d = %.4f, want %.4f", i, arithmetic[i], want)
+		}
+	}
+
+	geometric := GridLevelPrices(100, 1600, 5, true)
+	for i, want := range []float64{100, 200, 400, 800, 1600} {
+		if math.Abs(geometric[i]-want) > 1e-9 {
+			t.Errorf("geometric level %d = %.4f, want %.4f", i, geometric[i], want)
+		}
+	}
+	if pct := GridSpacingPct(100, 1600, 5, true); math.Abs(pct-100) > 1e-9 {
+		t.Errorf("geometric spacing = %.4f%%, want 100%%", pct)
+	}
+	if pct := GridSpacingPct(100, 200, 5, false); math.Abs(pct-25) > 1e-9 {
+		t.Errorf("arithmetic spacing = %.4f%%, want 25%%", pct)
+	}
+}
+
+func TestGridTrailBounds(t *testing.T) {
+	// Arithmetic step 25: price 230 is 1.2 steps above, the ladder moves 2 steps up
+	lower, upper, steps := GridTrailBounds(100, 200, 5, false, 230)
+	if steps != 2 || lower != 150 || upper != 250 {
+		t.Errorf("arithmetic trail up = %.2f - %.2f (%d steps), want 150 - 250 (2)", lower, upper, steps)
+	}
+	lower, upper, steps = GridTrailBounds(100, 200, 5, false, 90)
+	if steps != -1 || lower != 75 || upper != 175 {
+		t.Errorf("arithmetic trail down = %.2f - %.2f (%d steps), want 75 - 175 (-1)", lower, upper, steps)
+	}
+
+	// Geometric ratio 2: price 3000 needs one doubling
+	lower, upper, steps = GridTrailBounds(100, 1600, 5, true, 3000)
+	if steps != 1 || math.Abs(lower-200) > 1e-6 || math.Abs(upper-3200) > 1e-6 {
+		t.Errorf("geometric trail up = %.2f - %.2f (%d steps), want 200 - 3200 (1)", lower, upper, steps)
+	}
+	lower, upper, steps = GridTrailBounds(100, 1600, 5, true, 30)
+	if steps != -2 || math.Abs(lower-25) > 1e-6 || math.Abs(upper-400) > 1e-6 {
+		t.Errorf("geometric trail down = %.2f - %.2f (%d steps), want 25 - 400 (-2)", lower, upper, steps)
+	}
+
+	if _, _, steps = GridTrailBounds(100, 200, 5, false, 150); steps != 0 {
+		t.Errorf("price inside the range trailed %d steps", steps)
+	}
+}
+
+func TestValidateGridSpacing(t *testing.T) {
+	cfg := &store.GridStrategyConfig{GridCount: 10, Spacing: GridSpacingGeometric, Trailing: true, TrailingUpperLimit: 120000}
+	if err := ValidateGridConfig(cfg); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	cfg.Spacing = "log"
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected unknown spacing to be rejected")
+	}
+	cfg.Spacing = ""
+	cfg.TrailingLowerLimit = 130000
+	if err := ValidateGridConfig(cfg); err == nil {
+		t.Error("expected trailing lower limit above the upper limit to be rejected")
+	}
+}
@@ -0,0 +1,198 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Decision Memory - similarity retrieval of past setups
+// ============================================================================
+
+// memorySetupsPerSymbol maximum number of similar setups shown for one symbol
+const memorySetupsPerSymbol = 3
+
+// Market regime labels derived from BTC (shared by memory recording and retrieval)
+const (
+	RegimeNormal          = "normal"
+	RegimeStrongUptrend   = "strong_uptrend"
+	RegimeStrongDowntrend = "strong_downtrend"
+	RegimeOverbought      = "overbought"
+	RegimeOversold        = "oversold"
+)
+
+// SetMemoryStore enables database-backed decision memory for the engine
+func (e *StrategyEngine) SetMemoryStore(ms *store.DecisionMemoryStore) {
+	e.memoryStore = ms
+}
+
+// DetectMarketRegime classifies the overall market from BTC data
+func DetectMarketRegime(btcData *market.Data) string {
+	if btcData == nil {
+		return RegimeNormal
+	}
+	switch {
+	case btcData.PriceChange4h > 5:
+		return RegimeStrongUptrend
+	case btcData.PriceChange4h < -5:
+		return RegimeStrongDowntrend
+	case btcData.CurrentRSI7 > 70:
+		return RegimeOverbought
+	case btcData.CurrentRSI7 < 30:
+		return RegimeOversold
+	default:
+		return RegimeNormal
+	}
+}
+
+// ExtractMemoryFeatures builds the feature vector used for similarity search
+func ExtractMemoryFeatures(data *market.Data) store.MemoryFeatures {
+	if data == nil {
+		return store.MemoryFeatures{}
+	}
+	f := store.MemoryFeatures{
+		Price:         data.CurrentPrice,
+		PriceChange1h: data.PriceChange1h,
+		PriceChange4h: data.PriceChange4h,
+		RSI7:          data.CurrentRSI7,
+		ADX:           data.CurrentADX,
+		FundingRate:   data.FundingRate,
+	}
+	if data.CurrentPrice > 0 {
+		f.MACDPct = data.CurrentMACD / data.CurrentPrice * 100
+	}
+	if data.CurrentEMA20 > 0 {
+		f.EMA20DistPct = (data.CurrentPrice - data.CurrentEMA20) / data.CurrentEMA20 * 100
+	}
+	return f
+}
+
+// NewDecisionMemory creates a memory entry for an opening decision, nil for other actions
+func NewDecisionMemory(ctx *Context, d *Decision) *store.DecisionMemory {
+	var side string
+	switch d.Action {
+	case "open_long":
+		side = "LONG"
+	case "open_short":
+		side = "SHORT"
+	default:
+		return nil
+	}
+
+	return &store.DecisionMemory{
+		TraderID:    ctx.TraderID,
+		Symbol:      d.Symbol,
+		CycleNumber: ctx.CallCount,
+		Action:      d.Action,
+		Side:        side,
+		Confidence:  d.Confidence,
+		Regime:      DetectMarketRegime(ctx.MarketDataMap["BTCUSDT"]),
+		Features:    ExtractMemoryFeatures(ctx.MarketDataMap[d.Symbol]),
+	}
+}
+
+// buildMemoryPrompt retrieves similar past setups for positions and candidates
+func (e *StrategyEngine) buildMemoryPrompt(ctx *Context) string {
+	if e.memoryStore == nil || !e.config.Register.Enabled || e.config.Register.MaxRecords <= 0 {
+		return ""
+	}
+
+	// Positions first, then candidates (deduplicated)
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, pos := range ctx.Positions {
+		if !seen[pos.Symbol] {
+			seen[pos.Symbol] = true
+			symbols = append(symbols, pos.Symbol)
+		}
+	}
+	for _, coin := range ctx.CandidateCoins {
+		if !seen[coin.Symbol] {
+			seen[coin.Symbol] = true
+			symbols = append(symbols, coin.Symbol)
+		}
+	}
+
+	regime := DetectMarketRegime(ctx.MarketDataMap["BTCUSDT"])
+	lang := e.GetLanguage()
+	remaining := e.config.Register.MaxRecords
+
+	var body strings.Builder
+	for _, symbol := range symbols {
+		if remaining <= 0 {
+			break
+		}
+		data, ok := ctx.MarketDataMap[symbol]
+		if !ok {
+			continue
+		}
+
+		limit := memorySetupsPerSymbol
+		if limit > remaining {
+			limit = remaining
+		}
+		similar, err := e.memoryStore.FindSimilar(ctx.TraderID, symbol, regime, ExtractMemoryFeatures(data), limit)
+		if err != nil {
+			logger.Warnf("⚠️ Failed to query decision memory for %s: %v", symbol, err)
+			continue
+		}
+		if len(similar) == 0 {
+			continue
+		}
+
+		body.WriteString(fmt.Sprintf("### %s\n", symbol))
+		for _, m := range similar {
+			body.WriteString(e.formatSimilarMemory(m, lang))
+		}
+		remaining -= len(similar)
+	}
+
+	if body.Len() == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	if lang == LangChinese {
+		sb.WriteString(fmt.Sprintf("## 🧠 相似历史场景 (当前市场: %s)\n", regime))
+		sb.WriteString("以下是与当前指标最接近的历史开仓及其结果，距离越小越相似:\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("## 🧠 Similar Past Setups (current regime: %s)\n", regime))
+		sb.WriteString("Past entries whose indicators were closest to now, with their outcomes (lower distance = more similar):\n")
+	}
+	sb.WriteString(body.String())
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatSimilarMemory formats a single similar setup line
+func (e *StrategyEngine) formatSimilarMemory(m store.SimilarMemory, lang Language) string {
+	var sb strings.Builder
+	when := time.UnixMilli(m.CreatedAt).UTC().Format("01-02 15:04")
+	hold := ""
+	if m.HoldMs > 0 {
+		hold = (time.Duration(m.HoldMs) * time.Millisecond).Round(time.Minute).String()
+	}
+
+	if lang == LangChinese {
+		sb.WriteString(fmt.Sprintf("- [%s] %s %s | 市场: %s | 结果: %s %+.2f USDT (%+.2f%%) 持仓 %s | 距离 %.2f",
+			when, m.Symbol, m.Action, m.Regime, m.Outcome, m.RealizedPnL, m.PnLPct, hold, m.Distance))
+	} else {
+		sb.WriteString(fmt.Sprintf("- [%s] %s %s | Regime: %s | Result: %s %+.2f USDT (%+.2f%%) held %s | distance %.2f",
+			when, m.Symbol, m.Action, m.Regime, m.Outcome, m.RealizedPnL, m.PnLPct, hold, m.Distance))
+	}
+	if e.config.Register.IncludeDecisions && m.Confidence > 0 {
+		sb.WriteString(fmt.Sprintf(" | conf %d", m.Confidence))
+	}
+	sb.WriteString("\n")
+
+	if e.config.Register.IncludeMarketData {
+		f := m.Features
+		sb.WriteString(fmt.Sprintf("  Price=%s 1h=%+.2f%% 4h=%+.2f%% RSI7=%.1f MACD=%.3f%% ADX=%.1f EMA20Dist=%+.2f%% Funding=%.4f%%\n",
+			formatPriceSmart(f.Price), f.PriceChange1h, f.PriceChange4h, f.RSI7, f.MACDPct, f.ADX, f.EMA20DistPct, f.FundingRate*100))
+	}
+	return sb.String()
+}
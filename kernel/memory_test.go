@@ -0,0 +1,181 @@
+package kernel
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func TestDetectMarketRegime(t *testing.T) {
+	tests := []struct {
+		name string
+		data *market.Data
+		want string
+	}{
+		{"nil data", nil, RegimeNormal},
+		{"strong uptrend", &market.Data{PriceChange4h: 6, CurrentRSI7: 80}, RegimeStrongUptrend},
+		{"strong downtrend", &market.Data{PriceChange4h: -6}, RegimeStrongDowntrend},
+		{"overbought", &market.Data{PriceChange4h: 1, CurrentRSI7: 75}, RegimeOverbought},
+		{"oversold", &market.Data{PriceChange4h: -1, CurrentRSI7: 20}, RegimeOversold},
+		{"normal", &market.Data{PriceChange4h: 1, CurrentRSI7: 50}, RegimeNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMarketRegime(tt.data); got != tt.want {
+				t.Errorf("DetectMarketRegime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMemoryFeatures(t *testing.T) {
+	f := ExtractMemoryFeatures(&market.Data{
+		CurrentPrice: 110,
+		CurrentEMA20: 100,
+		CurrentMACD:  1.1,
+		CurrentRSI7:  60,
+	})
+	if f.EMA20DistPct < 9.99 || f.EMA20DistPct > 10.01 {
+		t.Errorf("EMA20DistPct = %.4f, want 10", f.EMA20DistPct)
+	}
+	if f.MACDPct < 0.999 || f.MACDPct > 1.001 {
+		t.Errorf("MACDPct = %.4f, want 1", f.MACDPct)
+	}
+	if f.RSI7 != 60 {
+		t.Errorf("RSI7 = %.2f, want 60", f.RSI7)
+	}
+}
+
+func TestDecisionMemoryRoundTrip(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	ctx := &Context{
+		TraderID:  "trader-1",
+		CallCount: 7,
+		MarketDataMap: map[string]*market.Data{
+			"BTCUSDT": {CurrentPrice: 60000, PriceChange4h: 1, CurrentRSI7: 50},
+			"ETHUSDT": {CurrentPrice: 3000, CurrentEMA20: 2950, CurrentRSI7: 62, PriceChange1h: 0.8},
+		},
+		CandidateCoins: []CandidateCoin{{Symbol: "ETHUSDT"}},
+	}
+
+	if m := NewDecisionMemory(ctx, &Decision{Symbol: "ETHUSDT", Action: "hold"}); m != nil {
+		t.Fatal("non-opening decisions should not be memorized")
+	}
+	memory := NewDecisionMemory(ctx, &Decision{Symbol: "ETHUSDT", Action: "open_long", Confidence: 80})
+	if memory == nil || memory.Side != "LONG" || memory.Regime != RegimeNormal {
+		t.Fatalf("unexpected memory: %+v", memory)
+	}
+	if err := st.DecisionMemory().Record(memory); err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+
+	// Open position is not resolved yet
+	now := time.Now().UTC().UnixMilli()
+	pos := &store.TraderPosition{
+		TraderID:   "trader-1",
+		Symbol:     "ETHUSDT",
+		Side:       "LONG",
+		Quantity:   1,
+		EntryPrice: 3000,
+		EntryTime:  now,
+		Leverage:   2,
+		Status:     "OPEN",
+	}
+	if err := st.Position().Create(pos); err != nil {
+		t.Fatalf("failed to create position: %v", err)
+	}
+	if n, err := st.DecisionMemory().ResolveOutcomes("trader-1"); err != nil || n != 0 {
+		t.Fatalf("ResolveOutcomes() = %d, %v; want 0, nil", n, err)
+	}
+
+	if err := st.Position().ClosePosition(pos.ID, 3150, "exit-1", 150, 1, "take_profit"); err != nil {
+		t.Fatalf("failed to close position: %v", err)
+	}
+	if n, err := st.DecisionMemory().ResolveOutcomes("trader-1"); err != nil || n != 1 {
+		t.Fatalf("ResolveOutcomes() = %d, %v; want 1, nil", n, err)
+	}
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.Register.Enabled = true
+	cfg.Register.MaxRecords = 5
+	engine := NewStrategyEngine(&cfg)
+	engine.SetMemoryStore(st.DecisionMemory())
+
+	prompt := engine.buildMemoryPrompt(ctx)
+	for _, want := range []string{"Similar Past Setups", "ETHUSDT open_long", "win", "+150.00 USDT", "+10.00%"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("memory prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	cfg.Register.Enabled = false
+	if prompt := engine.buildMemoryPrompt(ctx); prompt != "" {
+		t.Errorf("memory prompt should be empty when disabled, got:\n%s", prompt)
+	}
+}
+
+func TestDecisionMemoryMatching(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+	memories := st.DecisionMemory()
+	now := time.Now().UTC().UnixMilli()
+	hour := int64(time.Hour / time.Millisecond)
+
+	// A rarely traded symbol keeps its precedent behind a full pool of newer setups
+	old := &store.DecisionMemory{TraderID: "t1", Symbol: "DOGEUSDT", Action: "open_long", Side: "LONG",
+		Outcome: store.MemoryOutcomeWin, CreatedAt: now - 48*hour}
+	if err := memories.Record(old); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		m := &store.DecisionMemory{TraderID: "t1", Symbol: "BTCUSDT", Action: "open_long", Side: "LONG",
+			Outcome: store.MemoryOutcomeLoss, CreatedAt: now - int64(i)}
+		if err := memories.Record(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	similar, err := memories.FindSimilar("t1", "DOGEUSDT", "", store.MemoryFeatures{}, 3)
+	if err != nil || len(similar) != 3 || similar[0].ID != old.ID {
+		t.Fatalf("the DOGE setup should rank first: %+v (%v)", similar, err)
+	}
+
+	// Pending setups take the first position within their window; one without a position expires
+	linked := &store.DecisionMemory{TraderID: "t1", Symbol: "ETHUSDT", Action: "open_short", Side: "SHORT", CreatedAt: now - 3*hour}
+	orphan := &store.DecisionMemory{TraderID: "t1", Symbol: "ETHUSDT", Action: "open_short", Side: "SHORT", CreatedAt: now - 2*hour}
+	for _, m := range []*store.DecisionMemory{linked, orphan} {
+		if err := memories.Record(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pos := &store.TraderPosition{TraderID: "t1", Symbol: "ETHUSDT", Side: "SHORT", Quantity: 1, EntryPrice: 3000,
+		EntryTime: now - 3*hour + 1000, Status: "OPEN"}
+	if err := st.Position().Create(pos); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Position().ClosePosition(pos.ID, 2900, "exit-1", 100, 1, "take_profit"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := memories.ResolveOutcomes("t1"); err != nil || n != 2 {
+		t.Fatalf("ResolveOutcomes() = %d, %v; want 2, nil", n, err)
+	}
+	outcomes := map[int64]string{}
+	recent, _ := memories.GetRecent("t1", 600)
+	for _, m := range recent {
+		outcomes[m.ID] = m.Outcome
+	}
+	if outcomes[linked.ID] != store.MemoryOutcomeWin || outcomes[orphan.ID] != store.MemoryOutcomeExpired {
+		t.Errorf("outcomes = %s and %s, want win and expired", outcomes[linked.ID], outcomes[orphan.ID])
+	}
+}
@@ -0,0 +1,309 @@
+package kernel
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Pairs Strategy - mean reversion of the spread between two correlated perps
+// ============================================================================
+
+// Pairs defaults
+const (
+	defaultPairsTimeframe    = "1h"
+	defaultPairsLookbackBars = 100
+	minPairsLookbackBars     = 20
+	maxPairsLookbackBars     = 250
+)
+
+// PairsSignal hedge ratio and z-score of the spread ln(A) - β·ln(B) over the rolling window
+type PairsSignal struct {
+	HedgeRatio float64 `json:"hedge_ratio"` // β: OLS slope of ln(A) on ln(B)
+	Spread     float64 `json:"spread"`      // Latest spread
+	Mean       float64 `json:"mean"`
+	StdDev     float64 `json:"std_dev"`
+	ZScore     float64 `json:"z_score"`
+	Bars       int     `json:"bars"`
+}
+
+// PairsTimeframe returns the kline timeframe of a pairs configuration
+func PairsTimeframe(cfg *store.PairsStrategyConfig) string {
+	if cfg.Timeframe == "" {
+		return defaultPairsTimeframe
+	}
+	return cfg.Timeframe
+}
+
+// PairsLookbackBars returns the rolling window of a pairs configuration
+func PairsLookbackBars(cfg *store.PairsStrategyConfig) int {
+	if cfg.LookbackBars <= 0 {
+		return defaultPairsLookbackBars
+	}
+	return cfg.LookbackBars
+}
+
+// ValidatePairsConfig validates a pairs configuration
+func ValidatePairsConfig(cfg *store.PairsStrategyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("pairs_config is not set")
+	}
+	if strings.TrimSpace(cfg.SymbolA) == "" || strings.TrimSpace(cfg.SymbolB) == "" {
+		return fmt.Errorf("pairs_config.symbol_a and symbol_b are required")
+	}
+	if cfg.SymbolA == cfg.SymbolB {
+		return fmt.Errorf("pairs_config.symbol_a and symbol_b must differ")
+	}
+	if _, err := market.TFDuration(PairsTimeframe(cfg)); err != nil {
+		return fmt.Errorf("pairs_config.timeframe is invalid: %w", err)
+	}
+	if n := PairsLookbackBars(cfg); n < minPairsLookbackBars || n > maxPairsLookbackBars {
+		return fmt.Errorf("pairs_config.lookback_bars must be between %d and %d", minPairsLookbackBars, maxPairsLookbackBars)
+	}
+	if cfg.EntryZ <= 0 {
+		return fmt.Errorf("pairs_config.entry_z must be positive")
+	}
+	if cfg.ExitZ < 0 || cfg.ExitZ >= cfg.EntryZ {
+		return fmt.Errorf("pairs_config.exit_z must be between 0 and entry_z")
+	}
+	if cfg.StopZ != 0 && cfg.StopZ <= cfg.EntryZ {
+		return fmt.Errorf("pairs_config.stop_z must be above entry_z")
+	}
+	if cfg.PositionSizeUSD <= 0 {
+		return fmt.Errorf("pairs_config.position_size_usd must be positive")
+	}
+	if cfg.Leverage < 1 || cfg.Leverage > 20 {
+		return fmt.Errorf("pairs_config.leverage must be between 1 and 20")
+	}
+	return nil
+}
+
+// ComputePairsSignal computes the hedge ratio and z-score from aligned close prices (oldest first)
+func ComputePairsSignal(closesA, closesB []float64) (*PairsSignal, error) {
+	n := len(closesA)
+	if n != len(closesB) {
+		return nil, fmt.Errorf("series length mismatch: %d vs %d", n, len(closesB))
+	}
+	if n < 3 {
+		return nil, fmt.Errorf("not enough bars: %d", n)
+	}
+
+	y, x := make([]float64, n), make([]float64, n)
+	var meanX, meanY float64
+	for i := 0; i < n; i++ {
+		if closesA[i] <= 0 || closesB[i] <= 0 {
+			return nil, fmt.Errorf("non-positive price at bar %d", i)
+		}
+		y[i], x[i] = math.Log(closesA[i]), math.Log(closesB[i])
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX float64
+	for i := 0; i < n; i++ {
+		cov += (x[i] - meanX) * (y[i] - meanY)
+		varX += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if varX == 0 {
+		return nil, fmt.Errorf("flat price series")
+	}
+	beta := cov / varX
+
+	spreads := make([]float64, n)
+	var mean float64
+	for i := 0; i < n; i++ {
+		spreads[i] = y[i] - beta*x[i]
+		mean += spreads[i]
+	}
+	mean /= float64(n)
+	var variance float64
+	for _, s := range spreads {
+		variance += (s - mean) * (s - mean)
+	}
+	std := math.Sqrt(variance / float64(n))
+	if std == 0 {
+		return nil, fmt.Errorf("constant spread")
+	}
+
+	last := spreads[n-1]
+	return &PairsSignal{
+		HedgeRatio: beta,
+		Spread:     last,
+		Mean:       mean,
+		StdDev:     std,
+		ZScore:     (last - mean) / std,
+		Bars:       n,
+	}, nil
+}
+
+// pairsKlines returns the klines of a symbol on the pairs timeframe from the context
+// (fetched when the context carries no market data, as in live trading)
+func pairsKlines(ctx *Context, cfg *store.PairsStrategyConfig, symbol string, fetch bool) ([]market.KlineBar, error) {
+	tf := PairsTimeframe(cfg)
+	if fetch {
+		data, err := market.GetWithTimeframes(symbol, []string{tf}, tf, PairsLookbackBars(cfg), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s klines: %w", symbol, err)
+		}
+		ctx.MarketDataMap[symbol] = data
+	}
+	data, ok := ctx.MarketDataMap[symbol]
+	if !ok || data == nil || data.TimeframeData[tf] == nil {
+		return nil, fmt.Errorf("no %s klines for %s", tf, symbol)
+	}
+	return data.TimeframeData[tf].Klines, nil
+}
+
+// alignedCloses returns the closes of the bars present in both series (last n, oldest first)
+func alignedCloses(a, b []market.KlineBar, n int) ([]float64, []float64) {
+	closeB := make(map[int64]float64, len(b))
+	for _, k := range b {
+		closeB[k.Time] = k.Close
+	}
+	var closesA, closesB []float64
+	for _, k := range a {
+		if cb, ok := closeB[k.Time]; ok {
+			closesA = append(closesA, k.Close)
+			closesB = append(closesB, cb)
+		}
+	}
+	if len(closesA) > n {
+		closesA, closesB = closesA[len(closesA)-n:], closesB[len(closesB)-n:]
+	}
+	return closesA, closesB
+}
+
+// GetPairsDecisions enters both legs when |z| reaches the entry threshold and exits both on
+// mean reversion or at the stop; a lone leg (the other closed outside the strategy) is closed
+func GetPairsDecisions(ctx *Context, cfg *store.PairsStrategyConfig) (*FullDecision, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	if err := ValidatePairsConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	fetch := len(ctx.MarketDataMap) == 0
+	if fetch {
+		ctx.MarketDataMap = make(map[string]*market.Data)
+	}
+	klinesA, err := pairsKlines(ctx, cfg, cfg.SymbolA, fetch)
+	if err != nil {
+		return nil, err
+	}
+	klinesB, err := pairsKlines(ctx, cfg, cfg.SymbolB, fetch)
+	if err != nil {
+		return nil, err
+	}
+	lookback := PairsLookbackBars(cfg)
+	closesA, closesB := alignedCloses(klinesA, klinesB, lookback)
+	if len(closesA) < lookback {
+		return nil, fmt.Errorf("not enough aligned %s bars: %d/%d", PairsTimeframe(cfg), len(closesA), lookback)
+	}
+	sig, err := ComputePairsSignal(closesA, closesB)
+	if err != nil {
+		return nil, err
+	}
+
+	var trace strings.Builder
+	fmt.Fprintf(&trace, "%s/%s: β=%.4f, z=%.2f (spread %.5f, mean %.5f, σ %.5f, %d bars)\n",
+		cfg.SymbolA, cfg.SymbolB, sig.HedgeRatio, sig.ZScore, sig.Spread, sig.Mean, sig.StdDev, sig.Bars)
+
+	var posA, posB *PositionInfo
+	for i := range ctx.Positions {
+		switch ctx.Positions[i].Symbol {
+		case cfg.SymbolA:
+			posA = &ctx.Positions[i]
+		case cfg.SymbolB:
+			posB = &ctx.Positions[i]
+		}
+	}
+
+	var decisions []Decision
+	closeLeg := func(pos *PositionInfo, reason string) {
+		decisions = append(decisions, Decision{Symbol: pos.Symbol, Action: "close_" + pos.Side, Reasoning: reason})
+	}
+
+	switch {
+	case posA != nil && posB != nil:
+		var reason string
+		longSpread := posA.Side == "long"
+		switch {
+		case cfg.StopZ > 0 && math.Abs(sig.ZScore) >= cfg.StopZ:
+			reason = fmt.Sprintf("Pairs stop: |z| %.2f reached %.2f", math.Abs(sig.ZScore), cfg.StopZ)
+		case longSpread && sig.ZScore >= -cfg.ExitZ, !longSpread && sig.ZScore <= cfg.ExitZ:
+			reason = fmt.Sprintf("Pairs exit: z reverted to %.2f", sig.ZScore)
+		}
+		if reason == "" {
+			fmt.Fprintf(&trace, "holding %s %s / %s %s\n", posA.Side, cfg.SymbolA, posB.Side, cfg.SymbolB)
+			break
+		}
+		fmt.Fprintf(&trace, "%s\n", reason)
+		closeLeg(posA, reason)
+		closeLeg(posB, reason)
+
+	case posA != nil || posB != nil:
+		pos := posA
+		if pos == nil {
+			pos = posB
+		}
+		reason := fmt.Sprintf("Pairs leg %s held without its hedge", pos.Symbol)
+		fmt.Fprintf(&trace, "%s, closing\n", reason)
+		closeLeg(pos, reason)
+
+	default:
+		if math.Abs(sig.ZScore) < cfg.EntryZ {
+			fmt.Fprintf(&trace, "|z| below entry %.2f\n", cfg.EntryZ)
+			break
+		}
+		if cfg.StopZ > 0 && math.Abs(sig.ZScore) >= cfg.StopZ {
+			fmt.Fprintf(&trace, "|z| beyond stop %.2f, not entering\n", cfg.StopZ)
+			break
+		}
+		if sig.HedgeRatio <= 0 {
+			fmt.Fprintf(&trace, "hedge ratio %.4f not positive, pair is not correlated\n", sig.HedgeRatio)
+			break
+		}
+		// z > 0: A rich relative to B -> short A / long B
+		actionA, actionB := "open_long", "open_short"
+		if sig.ZScore > 0 {
+			actionA, actionB = "open_short", "open_long"
+		}
+		reason := fmt.Sprintf("Pairs entry: z=%.2f, β=%.4f", sig.ZScore, sig.HedgeRatio)
+		fmt.Fprintf(&trace, "%s: %s %s, %s %s\n", reason, actionA, cfg.SymbolA, actionB, cfg.SymbolB)
+		decisions = append(decisions,
+			Decision{Symbol: cfg.SymbolA, Action: actionA, Leverage: cfg.Leverage, PositionSizeUSD: cfg.PositionSizeUSD,
+				EntryPrice: closesA[len(closesA)-1], Reasoning: reason},
+			Decision{Symbol: cfg.SymbolB, Action: actionB, Leverage: cfg.Leverage, PositionSizeUSD: cfg.PositionSizeUSD * sig.HedgeRatio,
+				EntryPrice: closesB[len(closesB)-1], Reasoning: reason},
+		)
+	}
+
+	logger.Infof("🔗 Pairs decision: %d actions for %s/%s (z=%.2f)", len(decisions), cfg.SymbolA, cfg.SymbolB, sig.ZScore)
+	return &FullDecision{
+		SystemPrompt: describePairsConfig(cfg),
+		CoTTrace:     trace.String(),
+		Decisions:    decisions,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// describePairsConfig renders the configuration (stored as the "system prompt" of pairs decisions)
+func describePairsConfig(cfg *store.PairsStrategyConfig) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Pairs strategy (%s / %s, %dx)\n", cfg.SymbolA, cfg.SymbolB, cfg.Leverage)
+	fmt.Fprintf(&sb, "- window: %d × %s bars\n", PairsLookbackBars(cfg), PairsTimeframe(cfg))
+	fmt.Fprintf(&sb, "- entry |z| ≥ %.2f, exit |z| ≤ %.2f", cfg.EntryZ, cfg.ExitZ)
+	if cfg.StopZ > 0 {
+		fmt.Fprintf(&sb, ", stop |z| ≥ %.2f", cfg.StopZ)
+	}
+	fmt.Fprintf(&sb, "\n- leg A size: %.2f USDT (leg B sized by the hedge ratio)\n", cfg.PositionSizeUSD)
+	return sb.String()
+}
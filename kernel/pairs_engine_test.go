@@ -0,0 +1,139 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func pairsTestConfig() *store.PairsStrategyConfig {
+	return &store.PairsStrategyConfig{
+		SymbolA:         "ETHUSDT",
+		SymbolB:         "BTCUSDT",
+		Timeframe:       "1h",
+		LookbackBars:    50,
+		EntryZ:          2,
+		ExitZ:           0.5,
+		StopZ:           4,
+		PositionSizeUSD: 1000,
+		Leverage:        3,
+	}
+}
+
+// pairsTestContext builds B as a slow oscillation and A = B^1.5 × e^(±0.002), with the last bar
+// of A (no noise) shifted by lastShift (log terms) to move the z-score
+func pairsTestContext(lastShift float64, positions []PositionInfo) *Context {
+	const n = 50
+	var barsA, barsB []market.KlineBar
+	for i := 0; i < n; i++ {
+		b := 50000 * math.Exp(0.05*math.Sin(float64(i)/5))
+		a := math.Pow(b, 1.5) / 3000
+		switch {
+		case i == n-1:
+			a *= math.Exp(lastShift)
+		case i%2 == 0:
+			a *= math.Exp(0.002)
+		default:
+			a *= math.Exp(-0.002)
+		}
+		ts := int64(i) * 3600000
+		barsA = append(barsA, market.KlineBar{Time: ts, Close: a})
+		barsB = append(barsB, market.KlineBar{Time: ts, Close: b})
+	}
+	series := func(bars []market.KlineBar) *market.Data {
+		return &market.Data{TimeframeData: map[string]*market.TimeframeSeriesData{"1h": {Timeframe: "1h", Klines: bars}}}
+	}
+	return &Context{
+		Positions:     positions,
+		MarketDataMap: map[string]*market.Data{"ETHUSDT": series(barsA), "BTCUSDT": series(barsB)},
+	}
+}
+
+func TestComputePairsSignal(t *testing.T) {
+	var a, b []float64
+	for i := 0; i < 40; i++ {
+		pb := 100 * math.Exp(0.1*math.Sin(float64(i)/4))
+		b = append(b, pb)
+		a = append(a, 2*math.Pow(pb, 0.8)*math.Exp(0.01*math.Cos(float64(i))))
+	}
+	sig, err := ComputePairsSignal(a, b)
+	if err != nil {
+		t.Fatalf("ComputePairsSignal() error: %v", err)
+	}
+	if math.Abs(sig.HedgeRatio-0.8) > 0.05 {
+		t.Errorf("hedge ratio = %.4f, want ≈0.8", sig.HedgeRatio)
+	}
+	if _, err := ComputePairsSignal(a, b[1:]); err == nil {
+		t.Error("length mismatch should fail")
+	}
+}
+
+func TestGetPairsDecisions(t *testing.T) {
+	cfg := pairsTestConfig()
+
+	// No signal
+	fd, err := GetPairsDecisions(pairsTestContext(0, nil), cfg)
+	if err != nil {
+		t.Fatalf("GetPairsDecisions() error: %v", err)
+	}
+	if len(fd.Decisions) != 0 {
+		t.Fatalf("flat spread: decisions = %+v, want none", fd.Decisions)
+	}
+
+	// A rich vs B: short A, long B sized by the hedge ratio
+	fd, _ = GetPairsDecisions(pairsTestContext(0.006, nil), cfg)
+	if len(fd.Decisions) != 2 {
+		t.Fatalf("entry: decisions = %+v, %s", fd.Decisions, fd.CoTTrace)
+	}
+	if fd.Decisions[0].Symbol != "ETHUSDT" || fd.Decisions[0].Action != "open_short" || fd.Decisions[1].Action != "open_long" {
+		t.Errorf("entry legs = %+v", fd.Decisions)
+	}
+	if ratio := fd.Decisions[1].PositionSizeUSD / fd.Decisions[0].PositionSizeUSD; math.Abs(ratio-1.5) > 0.1 {
+		t.Errorf("leg B / leg A size = %.3f, want ≈1.5 (hedge ratio)", ratio)
+	}
+
+	held := []PositionInfo{{Symbol: "ETHUSDT", Side: "short"}, {Symbol: "BTCUSDT", Side: "long"}}
+
+	// Still stretched: hold
+	if fd, _ = GetPairsDecisions(pairsTestContext(0.006, held), cfg); len(fd.Decisions) != 0 {
+		t.Errorf("holding: decisions = %+v, want none", fd.Decisions)
+	}
+	// Reverted: close both legs
+	fd, _ = GetPairsDecisions(pairsTestContext(0, held), cfg)
+	if len(fd.Decisions) != 2 || fd.Decisions[0].Action != "close_short" || fd.Decisions[1].Action != "close_long" {
+		t.Errorf("exit: decisions = %+v", fd.Decisions)
+	}
+	// Stop
+	fd, _ = GetPairsDecisions(pairsTestContext(0.03, held), cfg)
+	if len(fd.Decisions) != 2 {
+		t.Errorf("stop: decisions = %+v, %s", fd.Decisions, fd.CoTTrace)
+	}
+	// Lone leg is closed
+	fd, _ = GetPairsDecisions(pairsTestContext(0.006, held[1:]), cfg)
+	if len(fd.Decisions) != 1 || fd.Decisions[0].Symbol != "BTCUSDT" || fd.Decisions[0].Action != "close_long" {
+		t.Errorf("lone leg: decisions = %+v", fd.Decisions)
+	}
+}
+
+func TestValidatePairsConfig(t *testing.T) {
+	if err := ValidatePairsConfig(pairsTestConfig()); err != nil {
+		t.Fatalf("ValidatePairsConfig() error: %v", err)
+	}
+	cfg := pairsTestConfig()
+	cfg.SymbolB = cfg.SymbolA
+	if err := ValidatePairsConfig(cfg); err == nil {
+		t.Error("same symbols should fail")
+	}
+	cfg = pairsTestConfig()
+	cfg.ExitZ = 2.5
+	if err := ValidatePairsConfig(cfg); err == nil {
+		t.Error("exit_z above entry_z should fail")
+	}
+	cfg = pairsTestConfig()
+	cfg.StopZ = 1.5
+	if err := ValidatePairsConfig(cfg); err == nil {
+		t.Error("stop_z below entry_z should fail")
+	}
+}
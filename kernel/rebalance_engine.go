@@ -0,0 +1,444 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Rebalance Strategy - target-weight portfolio across a basket of perps
+// ============================================================================
+
+// Rebalance defaults
+const (
+	defaultRebalanceDriftBand     = 2.0
+	defaultRebalanceMinNotional   = 10.0
+	defaultRebalanceGrossExposure = 100.0
+	defaultRebalanceAIRefresh     = 24.0
+	defaultRebalanceBatchSize     = 5
+)
+
+// RebalanceContext target state handed to the strategy by executors that manage rebalancing.
+// The strategy updates Targets/TargetsUpdatedAt when the AI sets new weights and fills Plan.
+type RebalanceContext struct {
+	Targets          map[string]float64 // Current target weights (nil = the configured targets)
+	TargetsUpdatedAt time.Time          // Last AI target update (zero = never)
+	TargetsReasoning string             // AI reasoning of the current targets
+	LastRebalanceAt  time.Time          // Last cycle that sent orders (zero = never)
+	Prices           map[string]float64 // Mark prices of the basket symbols
+	Now              time.Time
+	Plan             *RebalancePlan // Set by the strategy
+}
+
+// RebalancePlan weights and orders of one rebalance check
+type RebalancePlan struct {
+	Equity      float64            `json:"equity"`
+	Targets     map[string]float64 `json:"targets"`   // Target weights (% of equity, negative = short)
+	Weights     map[string]float64 `json:"weights"`   // Weights before the rebalance
+	Drift       map[string]float64 `json:"drift"`     // Weight - target (percentage points)
+	MaxDrift    float64            `json:"max_drift"` // Largest |drift|
+	Orders      []Decision         `json:"orders"`    // Reductions first, then increases
+	TurnoverUSD float64            `json:"turnover_usd"`
+	TurnoverPct float64            `json:"turnover_pct"` // Turnover in % of equity
+}
+
+// RebalanceDriftBand returns the drift band in percentage points
+func RebalanceDriftBand(cfg *store.RebalanceStrategyConfig) float64 {
+	if cfg.DriftBand <= 0 {
+		return defaultRebalanceDriftBand
+	}
+	return cfg.DriftBand
+}
+
+// RebalanceMinNotional returns the minimum order notional in USDT
+func RebalanceMinNotional(cfg *store.RebalanceStrategyConfig) float64 {
+	if cfg.MinNotional <= 0 {
+		return defaultRebalanceMinNotional
+	}
+	return cfg.MinNotional
+}
+
+// RebalanceMaxGross returns the maximum sum of |weights| in % of equity
+func RebalanceMaxGross(cfg *store.RebalanceStrategyConfig) float64 {
+	if cfg.MaxGrossExposure <= 0 {
+		return defaultRebalanceGrossExposure
+	}
+	return cfg.MaxGrossExposure
+}
+
+// RebalanceBatchSize returns the number of orders sent per batch
+func RebalanceBatchSize(cfg *store.RebalanceStrategyConfig) int {
+	if cfg.BatchSize <= 0 {
+		return defaultRebalanceBatchSize
+	}
+	return cfg.BatchSize
+}
+
+// RebalanceAIRefresh returns the interval between two AI target updates
+func RebalanceAIRefresh(cfg *store.RebalanceStrategyConfig) time.Duration {
+	hours := cfg.AIRefreshHours
+	if hours <= 0 {
+		hours = defaultRebalanceAIRefresh
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// RebalanceSymbols returns the basket symbols (sorted)
+func RebalanceSymbols(cfg *store.RebalanceStrategyConfig) []string {
+	symbols := make([]string, 0, len(cfg.Targets))
+	for symbol := range cfg.Targets {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// grossExposure returns the sum of |weights|
+func grossExposure(weights map[string]float64) float64 {
+	var gross float64
+	for _, w := range weights {
+		gross += math.Abs(w)
+	}
+	return gross
+}
+
+// ValidateRebalanceConfig validates a rebalance configuration
+func ValidateRebalanceConfig(cfg *store.RebalanceStrategyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("rebalance_config is not set")
+	}
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("rebalance_config.targets is empty")
+	}
+	for symbol := range cfg.Targets {
+		if strings.TrimSpace(symbol) == "" {
+			return fmt.Errorf("rebalance_config.targets contains an empty symbol")
+		}
+	}
+	if cfg.Leverage < 1 || cfg.Leverage > 20 {
+		return fmt.Errorf("rebalance_config.leverage must be between 1 and 20")
+	}
+	if cfg.DriftBand < 0 || cfg.MinNotional < 0 || cfg.MaxGrossExposure < 0 ||
+		cfg.RebalanceIntervalHours < 0 || cfg.AIRefreshHours < 0 || cfg.BatchSize < 0 {
+		return fmt.Errorf("rebalance_config values must not be negative")
+	}
+	maxGross := RebalanceMaxGross(cfg)
+	if maxGross > 100*float64(cfg.Leverage) {
+		return fmt.Errorf("rebalance_config.max_gross_exposure %.0f%% exceeds 100%% × leverage", maxGross)
+	}
+	if gross := grossExposure(cfg.Targets); gross > maxGross {
+		return fmt.Errorf("rebalance_config.targets add up to %.1f%% gross exposure (max %.1f%%)", gross, maxGross)
+	}
+	return nil
+}
+
+// ComputeRebalancePlan compares the current weights with the targets and returns the orders that
+// bring every symbol whose drift exceeds the band back to its target. Symbols held outside the
+// targets have a target of 0. Orders below the minimum notional are skipped.
+func ComputeRebalancePlan(cfg *store.RebalanceStrategyConfig, targets map[string]float64, positions []PositionInfo,
+	equity float64, prices map[string]float64) (*RebalancePlan, error) {
+	if equity <= 0 {
+		return nil, fmt.Errorf("equity is not positive: %.2f", equity)
+	}
+
+	// Held notional per symbol and side
+	held := make(map[string]map[string]float64)
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		price := prices[pos.Symbol]
+		if price <= 0 {
+			price = pos.MarkPrice
+		}
+		if held[pos.Symbol] == nil {
+			held[pos.Symbol] = make(map[string]float64)
+		}
+		held[pos.Symbol][pos.Side] += math.Abs(pos.Quantity) * price
+	}
+
+	symbolSet := make(map[string]bool)
+	for symbol := range targets {
+		symbolSet[symbol] = true
+	}
+	for symbol := range held {
+		symbolSet[symbol] = true
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	plan := &RebalancePlan{
+		Equity:  equity,
+		Targets: make(map[string]float64, len(symbols)),
+		Weights: make(map[string]float64, len(symbols)),
+		Drift:   make(map[string]float64, len(symbols)),
+	}
+	band := RebalanceDriftBand(cfg)
+	minNotional := RebalanceMinNotional(cfg)
+	var reductions, increases []Decision
+
+	for _, symbol := range symbols {
+		long, short := held[symbol]["long"], held[symbol]["short"]
+		weight := (long - short) / equity * 100
+		target := targets[symbol]
+		drift := weight - target
+		plan.Targets[symbol] = target
+		plan.Weights[symbol] = weight
+		plan.Drift[symbol] = drift
+		plan.MaxDrift = math.Max(plan.MaxDrift, math.Abs(drift))
+		if math.Abs(drift) < band {
+			continue
+		}
+
+		price := prices[symbol]
+		if price <= 0 && target != 0 {
+			return nil, fmt.Errorf("no price for %s", symbol)
+		}
+		reason := fmt.Sprintf("Rebalance %s: weight %.2f%% → target %.2f%% (drift %+.2f pts)", symbol, weight, target, drift)
+		targetUSD := math.Abs(target) / 100 * equity
+		side, other := "long", "short"
+		if target < 0 {
+			side, other = "short", "long"
+		}
+
+		// The opposite side (or any side when the target is 0) is closed entirely
+		if held[symbol][other] > 0 {
+			reductions = append(reductions, Decision{Symbol: symbol, Action: "close_" + other, Reasoning: reason})
+			plan.TurnoverUSD += held[symbol][other]
+		}
+		if target == 0 {
+			if held[symbol][side] > 0 {
+				reductions = append(reductions, Decision{Symbol: symbol, Action: "close_" + side, Reasoning: reason})
+				plan.TurnoverUSD += held[symbol][side]
+			}
+			continue
+		}
+
+		current := held[symbol][side]
+		switch {
+		case current > targetUSD:
+			if targetUSD < minNotional {
+				reductions = append(reductions, Decision{Symbol: symbol, Action: "close_" + side, Reasoning: reason})
+				plan.TurnoverUSD += current
+				break
+			}
+			reduce := current - targetUSD
+			if reduce < minNotional {
+				break
+			}
+			reductions = append(reductions, Decision{Symbol: symbol, Action: "close_" + side,
+				ClosePercentage: reduce / current, Reasoning: reason})
+			plan.TurnoverUSD += reduce
+		case targetUSD-current >= minNotional:
+			add := targetUSD - current
+			increases = append(increases, Decision{Symbol: symbol, Action: "open_" + side, Leverage: cfg.Leverage,
+				PositionSizeUSD: add, EntryPrice: price, Reasoning: reason})
+			plan.TurnoverUSD += add
+		}
+	}
+
+	plan.Orders = append(reductions, increases...)
+	plan.TurnoverPct = plan.TurnoverUSD / equity * 100
+	return plan, nil
+}
+
+// GetRebalanceDecisions returns the rebalance orders of one cycle (nothing while the minimum
+// interval since the last rebalance has not elapsed)
+func GetRebalanceDecisions(rc *RebalanceContext, cfg *store.RebalanceStrategyConfig, ctx *Context) (*FullDecision, error) {
+	if rc == nil || ctx == nil {
+		return nil, fmt.Errorf("rebalance context is not available")
+	}
+	targets := rc.Targets
+	if targets == nil {
+		targets = cfg.Targets
+	}
+
+	var trace strings.Builder
+	if rc.TargetsReasoning != "" {
+		fmt.Fprintf(&trace, "Targets (AI, %s): %s\n", rc.TargetsUpdatedAt.UTC().Format("2006-01-02 15:04"), rc.TargetsReasoning)
+	}
+
+	interval := time.Duration(cfg.RebalanceIntervalHours * float64(time.Hour))
+	if interval > 0 && !rc.LastRebalanceAt.IsZero() && rc.Now.Sub(rc.LastRebalanceAt) < interval {
+		fmt.Fprintf(&trace, "Next rebalance check after %s\n", rc.LastRebalanceAt.Add(interval).UTC().Format("2006-01-02 15:04"))
+		return &FullDecision{
+			SystemPrompt: describeRebalanceConfig(cfg, targets),
+			CoTTrace:     trace.String(),
+			Timestamp:    time.Now(),
+		}, nil
+	}
+
+	plan, err := ComputeRebalancePlan(cfg, targets, ctx.Positions, ctx.Account.TotalEquity, rc.Prices)
+	if err != nil {
+		return nil, err
+	}
+	rc.Plan = plan
+
+	for _, symbol := range sortedKeys(plan.Weights) {
+		fmt.Fprintf(&trace, "%s: weight %.2f%%, target %.2f%%, drift %+.2f pts\n",
+			symbol, plan.Weights[symbol], plan.Targets[symbol], plan.Drift[symbol])
+	}
+	if len(plan.Orders) == 0 {
+		fmt.Fprintf(&trace, "All weights within the %.2f pts band\n", RebalanceDriftBand(cfg))
+	} else {
+		fmt.Fprintf(&trace, "%d orders, turnover %.2f USDT (%.2f%% of equity)\n", len(plan.Orders), plan.TurnoverUSD, plan.TurnoverPct)
+	}
+
+	logger.Infof("⚖️ Rebalance decision: %d orders, max drift %.2f pts, turnover %.2f USDT",
+		len(plan.Orders), plan.MaxDrift, plan.TurnoverUSD)
+	return &FullDecision{
+		SystemPrompt: describeRebalanceConfig(cfg, targets),
+		CoTTrace:     trace.String(),
+		Decisions:    plan.Orders,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// sortedKeys returns the keys of a weight map (sorted)
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeRebalanceConfig renders the configuration (stored as the "system prompt" of rebalance decisions)
+func describeRebalanceConfig(cfg *store.RebalanceStrategyConfig, targets map[string]float64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Rebalance strategy (%d symbols, %dx)\n", len(targets), cfg.Leverage)
+	for _, symbol := range sortedKeys(targets) {
+		fmt.Fprintf(&sb, "- %s: %+.2f%%\n", symbol, targets[symbol])
+	}
+	fmt.Fprintf(&sb, "- drift band: %.2f pts, min notional: %.2f USDT, max gross: %.0f%%\n",
+		RebalanceDriftBand(cfg), RebalanceMinNotional(cfg), RebalanceMaxGross(cfg))
+	if cfg.RebalanceIntervalHours > 0 {
+		fmt.Fprintf(&sb, "- at most one rebalance every %.1fh\n", cfg.RebalanceIntervalHours)
+	}
+	if cfg.AITargets {
+		fmt.Fprintf(&sb, "- weights set by the AI every %s\n", RebalanceAIRefresh(cfg))
+	}
+	return sb.String()
+}
+
+// ============================================================================
+// AI Target Weights
+// ============================================================================
+
+// RebalanceTargetsResult target weights proposed by the AI
+type RebalanceTargetsResult struct {
+	Weights   map[string]float64 `json:"weights"`
+	Reasoning string             `json:"reasoning"`
+}
+
+// BuildRebalanceTargetsPrompt builds the system and user prompts asking the AI for basket weights
+func BuildRebalanceTargetsPrompt(cfg *store.RebalanceStrategyConfig, marketData map[string]*market.Data, weights map[string]float64) (string, string) {
+	var sys strings.Builder
+	sys.WriteString("You are a portfolio manager allocating a basket of crypto perpetual futures.\n")
+	fmt.Fprintf(&sys, "Set a target weight for each symbol in %% of equity (notional). Positive = long, negative = short, 0 = flat.\n")
+	fmt.Fprintf(&sys, "The sum of |weights| must not exceed %.0f%%. Only use the listed symbols.\n\n", RebalanceMaxGross(cfg))
+	sys.WriteString("Output only the following JSON object:\n")
+	sys.WriteString("```json\n")
+	sys.WriteString("{\"weights\": {\"BTCUSDT\": 40, \"ETHUSDT\": -10}, \"reasoning\": \"...\"}\n")
+	sys.WriteString("```\n")
+
+	var user strings.Builder
+	user.WriteString("## Basket\n\n")
+	for _, symbol := range RebalanceSymbols(cfg) {
+		fmt.Fprintf(&user, "- %s: default %+.2f%%, current %+.2f%%", symbol, cfg.Targets[symbol], weights[symbol])
+		if data := marketData[symbol]; data != nil {
+			fmt.Fprintf(&user, " | price %s, 1h %+.2f%%, 4h %+.2f%%, RSI7 %.1f, ADX %.1f, funding %.4f%%",
+				formatPriceSmart(data.CurrentPrice), data.PriceChange1h, data.PriceChange4h,
+				data.CurrentRSI7, data.CurrentADX, data.FundingRate*100)
+		}
+		user.WriteString("\n")
+	}
+	return sys.String(), user.String()
+}
+
+// ParseRebalanceTargetsResponse extracts the weights from an AI response, dropping symbols outside
+// the basket and scaling the weights down to the maximum gross exposure
+func ParseRebalanceTargetsResponse(response string, cfg *store.RebalanceStrategyConfig) (*RebalanceTargetsResult, error) {
+	s := removeInvisibleRunes(response)
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON object found in targets response")
+	}
+
+	var result RebalanceTargetsResult
+	if err := json.Unmarshal([]byte(s[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse targets response: %w", err)
+	}
+
+	weights := make(map[string]float64, len(cfg.Targets))
+	for symbol := range cfg.Targets {
+		if w, ok := result.Weights[symbol]; ok && !math.IsNaN(w) && !math.IsInf(w, 0) {
+			weights[symbol] = w
+		} else {
+			weights[symbol] = 0
+		}
+	}
+	if gross, maxGross := grossExposure(weights), RebalanceMaxGross(cfg); gross > maxGross {
+		for symbol := range weights {
+			weights[symbol] *= maxGross / gross
+		}
+	}
+	result.Weights = weights
+	result.Reasoning = strings.TrimSpace(result.Reasoning)
+	return &result, nil
+}
+
+// RefreshRebalanceTargets asks the AI for new weights when they are due. On failure the previous
+// targets are kept.
+func RefreshRebalanceTargets(rc *RebalanceContext, cfg *store.RebalanceStrategyConfig, client mcp.AIClient, ctx *Context) error {
+	if !cfg.AITargets || client == nil {
+		return nil
+	}
+	if rc.Targets != nil && rc.Now.Sub(rc.TargetsUpdatedAt) < RebalanceAIRefresh(cfg) {
+		return nil
+	}
+
+	marketData := ctx.MarketDataMap
+	if len(marketData) == 0 {
+		marketData = make(map[string]*market.Data)
+		for _, symbol := range RebalanceSymbols(cfg) {
+			if data, err := market.Get(symbol); err == nil {
+				marketData[symbol] = data
+			}
+		}
+	}
+	var weights map[string]float64
+	if ctx.Account.TotalEquity > 0 {
+		if plan, err := ComputeRebalancePlan(cfg, nil, ctx.Positions, ctx.Account.TotalEquity, rc.Prices); err == nil {
+			weights = plan.Weights
+		}
+	}
+
+	systemPrompt, userPrompt := BuildRebalanceTargetsPrompt(cfg, marketData, weights)
+	response, err := client.CallWithMessages(systemPrompt, userPrompt)
+	if err != nil {
+		return fmt.Errorf("failed to get AI targets: %w", err)
+	}
+	result, err := ParseRebalanceTargetsResponse(response, cfg)
+	if err != nil {
+		return err
+	}
+	rc.Targets = result.Weights
+	rc.TargetsUpdatedAt = rc.Now
+	rc.TargetsReasoning = result.Reasoning
+	logger.Infof("⚖️ AI rebalance targets updated: %v (%s)", result.Weights, result.Reasoning)
+	return nil
+}
@@ -0,0 +1,175 @@
+package kernel
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func rebalanceTestConfig() *store.RebalanceStrategyConfig {
+	return &store.RebalanceStrategyConfig{
+		Targets:     map[string]float64{"BTCUSDT": 50, "ETHUSDT": 30, "SOLUSDT": -20},
+		Leverage:    2,
+		DriftBand:   2,
+		MinNotional: 10,
+	}
+}
+
+func findOrder(orders []Decision, symbol, action string) *Decision {
+	for i := range orders {
+		if orders[i].Symbol == symbol && orders[i].Action == action {
+			return &orders[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateRebalanceConfig(t *testing.T) {
+	if err := ValidateRebalanceConfig(rebalanceTestConfig()); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+
+	cfg := rebalanceTestConfig()
+	cfg.Targets["XRPUSDT"] = 10 // Gross 110% > default 100%
+	if err := ValidateRebalanceConfig(cfg); err == nil {
+		t.Error("expected gross exposure above the maximum to be rejected")
+	}
+	cfg.MaxGrossExposure = 150
+	if err := ValidateRebalanceConfig(cfg); err != nil {
+		t.Errorf("gross exposure within max_gross_exposure rejected: %v", err)
+	}
+	cfg.MaxGrossExposure = 250 // Above 100% × 2x
+	if err := ValidateRebalanceConfig(cfg); err == nil {
+		t.Error("expected max_gross_exposure above 100% × leverage to be rejected")
+	}
+
+	cfg = rebalanceTestConfig()
+	cfg.Leverage = 0
+	if err := ValidateRebalanceConfig(cfg); err == nil {
+		t.Error("expected leverage 0 to be rejected")
+	}
+}
+
+func TestComputeRebalancePlan(t *testing.T) {
+	cfg := rebalanceTestConfig()
+	prices := map[string]float64{"BTCUSDT": 50000, "ETHUSDT": 2500, "SOLUSDT": 100, "DOGEUSDT": 0.1}
+	positions := []PositionInfo{
+		{Symbol: "BTCUSDT", Side: "long", Quantity: 0.12, MarkPrice: 50000}, // 6000 = 60% (target 50%)
+		{Symbol: "ETHUSDT", Side: "long", Quantity: 1.16, MarkPrice: 2500},  // 2900 = 29% (within band)
+		{Symbol: "SOLUSDT", Side: "long", Quantity: 5, MarkPrice: 100},      // 500 = 5% long (target 20% short)
+		{Symbol: "DOGEUSDT", Side: "long", Quantity: 3000, MarkPrice: 0.1},  // 300 = 3% outside targets
+	}
+
+	plan, err := ComputeRebalancePlan(cfg, cfg.Targets, positions, 10000, prices)
+	if err != nil {
+		t.Fatalf("ComputeRebalancePlan: %v", err)
+	}
+
+	if math.Abs(plan.Weights["BTCUSDT"]-60) > 1e-9 || math.Abs(plan.Drift["BTCUSDT"]-10) > 1e-9 {
+		t.Errorf("BTC weight/drift = %.2f/%.2f, want 60/10", plan.Weights["BTCUSDT"], plan.Drift["BTCUSDT"])
+	}
+	if math.Abs(plan.MaxDrift-25) > 1e-9 {
+		t.Errorf("MaxDrift = %.2f, want 25 (SOL +5%% vs -20%%)", plan.MaxDrift)
+	}
+
+	btc := findOrder(plan.Orders, "BTCUSDT", "close_long")
+	if btc == nil || math.Abs(btc.ClosePercentage-1000.0/6000.0) > 1e-9 {
+		t.Errorf("expected BTC partial close of 1/6, got %+v", btc)
+	}
+	if o := findOrder(plan.Orders, "ETHUSDT", "open_long"); o != nil {
+		t.Errorf("ETH within band should not trade, got %+v", o)
+	}
+	if findOrder(plan.Orders, "SOLUSDT", "close_long") == nil {
+		t.Error("expected SOL long to be closed before flipping short")
+	}
+	sol := findOrder(plan.Orders, "SOLUSDT", "open_short")
+	if sol == nil || math.Abs(sol.PositionSizeUSD-2000) > 1e-9 || sol.Leverage != 2 {
+		t.Errorf("expected SOL short of 2000 USDT at 2x, got %+v", sol)
+	}
+	if o := findOrder(plan.Orders, "DOGEUSDT", "close_long"); o == nil || o.ClosePercentage != 0 {
+		t.Errorf("expected DOGE outside targets to be closed entirely, got %+v", o)
+	}
+
+	// Reductions come before increases
+	seenOpen := false
+	for _, o := range plan.Orders {
+		if o.Action == "open_long" || o.Action == "open_short" {
+			seenOpen = true
+		} else if seenOpen {
+			t.Fatalf("reduction %s %s after an increase", o.Action, o.Symbol)
+		}
+	}
+
+	// 1000 (BTC) + 500 (SOL long) + 2000 (SOL short) + 300 (DOGE)
+	if math.Abs(plan.TurnoverUSD-3800) > 1e-6 || math.Abs(plan.TurnoverPct-38) > 1e-6 {
+		t.Errorf("turnover = %.2f USDT (%.2f%%), want 3800 (38%%)", plan.TurnoverUSD, plan.TurnoverPct)
+	}
+}
+
+func TestComputeRebalancePlanMinNotional(t *testing.T) {
+	cfg := rebalanceTestConfig()
+	cfg.DriftBand = 0.01
+	cfg.MinNotional = 50
+	targets := map[string]float64{"BTCUSDT": 50}
+	prices := map[string]float64{"BTCUSDT": 50000}
+	positions := []PositionInfo{{Symbol: "BTCUSDT", Side: "long", Quantity: 0.0995, MarkPrice: 50000}} // 4975 vs 5000
+
+	plan, err := ComputeRebalancePlan(cfg, targets, positions, 10000, prices)
+	if err != nil {
+		t.Fatalf("ComputeRebalancePlan: %v", err)
+	}
+	if len(plan.Orders) != 0 {
+		t.Errorf("25 USDT top-up below min notional should be skipped, got %+v", plan.Orders)
+	}
+}
+
+func TestRebalanceStrategyInterval(t *testing.T) {
+	cfg := rebalanceTestConfig()
+	cfg.RebalanceIntervalHours = 24
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	rc := &RebalanceContext{
+		LastRebalanceAt: now.Add(-time.Hour),
+		Prices:          map[string]float64{"BTCUSDT": 50000, "ETHUSDT": 2500, "SOLUSDT": 100},
+		Now:             now,
+	}
+	ctx := &Context{Account: AccountInfo{TotalEquity: 10000}}
+
+	fd, err := GetRebalanceDecisions(rc, cfg, ctx)
+	if err != nil {
+		t.Fatalf("GetRebalanceDecisions: %v", err)
+	}
+	if len(fd.Decisions) != 0 || rc.Plan != nil {
+		t.Errorf("expected no rebalance within the interval, got %d orders", len(fd.Decisions))
+	}
+
+	rc.LastRebalanceAt = now.Add(-25 * time.Hour)
+	fd, err = GetRebalanceDecisions(rc, cfg, ctx)
+	if err != nil {
+		t.Fatalf("GetRebalanceDecisions: %v", err)
+	}
+	if len(fd.Decisions) != 3 {
+		t.Errorf("expected 3 opens from a flat account, got %d", len(fd.Decisions))
+	}
+}
+
+func TestParseRebalanceTargetsResponse(t *testing.T) {
+	cfg := rebalanceTestConfig()
+	response := "```json\n{\"weights\": {\"BTCUSDT\": 80, \"ETHUSDT\": -40, \"DOGEUSDT\": 30}, \"reasoning\": \" risk-on \"}\n```"
+
+	result, err := ParseRebalanceTargetsResponse(response, cfg)
+	if err != nil {
+		t.Fatalf("ParseRebalanceTargetsResponse: %v", err)
+	}
+	if _, ok := result.Weights["DOGEUSDT"]; ok {
+		t.Error("symbols outside the basket should be dropped")
+	}
+	// 80 + 40 = 120% gross scaled down to 100%
+	if math.Abs(result.Weights["BTCUSDT"]-80.0/1.2) > 1e-9 || math.Abs(result.Weights["ETHUSDT"]+40.0/1.2) > 1e-9 {
+		t.Errorf("weights not scaled to max gross: %v", result.Weights)
+	}
+	if result.Weights["SOLUSDT"] != 0 || result.Reasoning != "risk-on" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,110 @@
+package kernel
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"nofx/store"
+)
+
+// ============================================================================
+// Regime Router
+// ============================================================================
+//
+// A regime router has no decisions of its own: the trader classifies the market regime of
+// the router symbol and runs the strategy routed to it. This file holds the configuration
+// rules shared by the trader and the API.
+
+const (
+	// RegimeRouteRanging route of every non-trending regime without its own route
+	RegimeRouteRanging = "ranging"
+	// RegimeRouteDefault route used when no other route matches
+	RegimeRouteDefault = "default"
+
+	defaultRegimeConfirmCycles = 3
+	defaultRegimeTrendADX      = 25.0
+)
+
+// regimeRouteKeys valid keys of RegimeRouterConfig.Routes (the classified regimes first)
+var regimeRouteKeys = []string{"trending", "narrow", "standard", "wide", "volatile", RegimeRouteRanging, RegimeRouteDefault}
+
+// RegimeConfirmCycles returns the hysteresis of a router configuration
+func RegimeConfirmCycles(cfg *store.RegimeRouterConfig) int {
+	if cfg.ConfirmCycles <= 0 {
+		return defaultRegimeConfirmCycles
+	}
+	return cfg.ConfirmCycles
+}
+
+// RegimeTrendADX returns the ADX at or above which the market counts as trending
+func RegimeTrendADX(cfg *store.RegimeRouterConfig) float64 {
+	if cfg.TrendADX <= 0 {
+		return defaultRegimeTrendADX
+	}
+	return cfg.TrendADX
+}
+
+// RegimeHandover returns the position handover mode ("close" or "keep")
+func RegimeHandover(cfg *store.RegimeRouterConfig) string {
+	if cfg.Handover == "" {
+		return "close"
+	}
+	return cfg.Handover
+}
+
+// ResolveRegimeRoute returns the strategy ID routed to a regime ("" = none).
+// Lookup order: the regime itself, "ranging" for non-trending regimes, then "default".
+func ResolveRegimeRoute(cfg *store.RegimeRouterConfig, regime string) string {
+	if id := cfg.Routes[regime]; id != "" {
+		return id
+	}
+	if regime != "trending" {
+		if id := cfg.Routes[RegimeRouteRanging]; id != "" {
+			return id
+		}
+	}
+	return cfg.Routes[RegimeRouteDefault]
+}
+
+// ValidateRegimeRouterConfig validates a regime router configuration
+func ValidateRegimeRouterConfig(cfg *store.RegimeRouterConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("regime_router_config is not set")
+	}
+	if strings.TrimSpace(cfg.Symbol) == "" {
+		return fmt.Errorf("regime_router_config.symbol is required")
+	}
+	if len(cfg.Routes) == 0 {
+		return fmt.Errorf("regime_router_config.routes is required")
+	}
+	keys := make([]string, 0, len(cfg.Routes))
+	for key := range cfg.Routes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(regimeRouteKeys, key) {
+			return fmt.Errorf("regime_router_config.routes: unknown regime '%s' (%s)", key, strings.Join(regimeRouteKeys, ", "))
+		}
+		if strings.TrimSpace(cfg.Routes[key]) == "" {
+			return fmt.Errorf("regime_router_config.routes.%s: strategy ID is empty", key)
+		}
+	}
+	for _, regime := range regimeRouteKeys[:5] {
+		if ResolveRegimeRoute(cfg, regime) == "" {
+			return fmt.Errorf("regime_router_config.routes: no strategy for regime '%s' (add it, 'ranging' or 'default')", regime)
+		}
+	}
+	if cfg.ConfirmCycles < 0 || cfg.ConfirmCycles > 50 {
+		return fmt.Errorf("regime_router_config.confirm_cycles must be between 1 and 50")
+	}
+	if cfg.TrendADX < 0 || cfg.TrendADX > 100 {
+		return fmt.Errorf("regime_router_config.trend_adx must be between 0 and 100")
+	}
+	if h := RegimeHandover(cfg); h != "close" && h != "keep" {
+		return fmt.Errorf("regime_router_config.handover must be 'close' or 'keep'")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package kernel
+
+import (
+	"testing"
+
+	"nofx/store"
+)
+
+func routerTestConfig() *store.RegimeRouterConfig {
+	return &store.RegimeRouterConfig{
+		Symbol: "BTCUSDT",
+		Routes: map[string]string{"trending": "ai-trend", "ranging": "grid-range", "volatile": "ai-defensive"},
+	}
+}
+
+func TestResolveRegimeRoute(t *testing.T) {
+	cfg := routerTestConfig()
+	tests := map[string]string{
+		"trending": "ai-trend",
+		"narrow":   "grid-range",
+		"wide":     "grid-range",
+		"volatile": "ai-defensive",
+	}
+	for regime, want := range tests {
+		if got := ResolveRegimeRoute(cfg, regime); got != want {
+			t.Errorf("ResolveRegimeRoute(%s) = %q, want %q", regime, got, want)
+		}
+	}
+
+	// Trending never falls back to the ranging route
+	cfg.Routes = map[string]string{"ranging": "grid-range", "default": "ai-any"}
+	if got := ResolveRegimeRoute(cfg, "trending"); got != "ai-any" {
+		t.Errorf("trending fallback = %q, want default route", got)
+	}
+}
+
+func TestValidateRegimeRouterConfig(t *testing.T) {
+	if err := ValidateRegimeRouterConfig(routerTestConfig()); err != nil {
+		t.Fatalf("ValidateRegimeRouterConfig() error: %v", err)
+	}
+	cfg := routerTestConfig()
+	delete(cfg.Routes, "trending")
+	if err := ValidateRegimeRouterConfig(cfg); err == nil {
+		t.Error("uncovered trending regime should fail")
+	}
+	cfg = routerTestConfig()
+	cfg.Routes["sideways"] = "grid-range"
+	if err := ValidateRegimeRouterConfig(cfg); err == nil {
+		t.Error("unknown regime should fail")
+	}
+	cfg = routerTestConfig()
+	cfg.Handover = "flip"
+	if err := ValidateRegimeRouterConfig(cfg); err == nil {
+		t.Error("unknown handover should fail")
+	}
+}
@@ -0,0 +1,153 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Trade Self-Review - post-mortem of closed trades producing lessons
+// ============================================================================
+
+// maxReviewReasoningLen truncates entry reasoning in the review prompt
+const maxReviewReasoningLen = 600
+
+// ReviewTrade a closed position with the reasoning that opened it
+type ReviewTrade struct {
+	Position        *store.TraderPosition
+	EntryReasoning  string
+	EntryConfidence int
+}
+
+// TradeReviewResult structured post-mortem returned by the AI
+type TradeReviewResult struct {
+	Summary  string   `json:"summary"`
+	Mistakes []string `json:"mistakes"`
+	Rules    []string `json:"rules"`
+}
+
+// SetActiveLessons sets the approved lessons injected into the system prompt
+func (e *StrategyEngine) SetActiveLessons(lessons []string) {
+	e.lessons = lessons
+}
+
+// writeLessons writes the lessons section of the system prompt
+func (e *StrategyEngine) writeLessons(sb *strings.Builder) {
+	if len(e.lessons) == 0 {
+		return
+	}
+	if e.GetLanguage() == LangChinese {
+		sb.WriteString("# 📚 复盘经验教训\n\n")
+		sb.WriteString("以下规则来自你对过往已平仓交易的复盘，决策时必须遵守:\n")
+	} else {
+		sb.WriteString("# 📚 Lessons From Past Reviews\n\n")
+		sb.WriteString("The following rules come from your own post-mortems of closed trades. Follow them when deciding:\n")
+	}
+	for i, lesson := range e.lessons {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, lesson))
+	}
+	sb.WriteString("\n")
+}
+
+// BuildTradeReviewPrompt builds the system and user prompts for a trade review
+func BuildTradeReviewPrompt(trades []ReviewTrade, existing []string, lang Language) (string, string) {
+	var sys strings.Builder
+	if lang == LangChinese {
+		sys.WriteString("你是一名严格的交易复盘教练。你将看到一个自动交易系统最近已平仓的交易，以及开仓时AI给出的理由。\n")
+		sys.WriteString("找出反复出现的错误，并总结成简短、可执行、可验证的规则，供未来决策使用。\n")
+		sys.WriteString("不要重复已有规则；如果没有足够证据，返回空数组。\n\n")
+		sys.WriteString("只输出如下JSON对象:\n")
+	} else {
+		sys.WriteString("You are a strict trading review coach. You will see the recently closed trades of an automated trading system together with the AI reasoning that opened them.\n")
+		sys.WriteString("Identify recurring mistakes and distill them into short, actionable, verifiable rules for future decisions.\n")
+		sys.WriteString("Do not repeat existing rules; return empty arrays when the evidence is insufficient.\n\n")
+		sys.WriteString("Output only the following JSON object:\n")
+	}
+	sys.WriteString("```json\n")
+	sys.WriteString("{\"summary\": \"...\", \"mistakes\": [\"...\"], \"rules\": [\"...\"]}\n")
+	sys.WriteString("```\n")
+
+	var user strings.Builder
+	var totalPnL float64
+	wins := 0
+	for _, t := range trades {
+		totalPnL += t.Position.RealizedPnL
+		if t.Position.RealizedPnL > 0 {
+			wins++
+		}
+	}
+	if lang == LangChinese {
+		user.WriteString(fmt.Sprintf("## 已平仓交易 (%d 笔, 盈利 %d 笔, 合计 %+.2f USDT)\n\n", len(trades), wins, totalPnL))
+	} else {
+		user.WriteString(fmt.Sprintf("## Closed Trades (%d trades, %d winners, total %+.2f USDT)\n\n", len(trades), wins, totalPnL))
+	}
+
+	for i, t := range trades {
+		pos := t.Position
+		entry := time.UnixMilli(pos.EntryTime).UTC().Format("01-02 15:04")
+		exit := time.UnixMilli(pos.ExitTime).UTC().Format("01-02 15:04")
+		hold := time.Duration(pos.ExitTime-pos.EntryTime) * time.Millisecond
+		user.WriteString(fmt.Sprintf("%d. %s %s %dx | entry %s @ %s → exit %s @ %s (held %s) | PnL %+.2f USDT | close: %s\n",
+			i+1, pos.Symbol, pos.Side, pos.Leverage,
+			formatPriceSmart(pos.EntryPrice), entry, formatPriceSmart(pos.ExitPrice), exit,
+			hold.Round(time.Minute), pos.RealizedPnL, pos.CloseReason))
+		if t.EntryReasoning != "" {
+			reasoning := t.EntryReasoning
+			if len(reasoning) > maxReviewReasoningLen {
+				reasoning = reasoning[:maxReviewReasoningLen] + "..."
+			}
+			user.WriteString(fmt.Sprintf("   Entry reasoning (confidence %d): %s\n", t.EntryConfidence, reasoning))
+		}
+	}
+
+	if len(existing) > 0 {
+		if lang == LangChinese {
+			user.WriteString("\n## 已有规则\n")
+		} else {
+			user.WriteString("\n## Existing Rules\n")
+		}
+		for _, rule := range existing {
+			user.WriteString(fmt.Sprintf("- %s\n", rule))
+		}
+	}
+
+	return sys.String(), user.String()
+}
+
+// ParseTradeReviewResponse extracts the review JSON object from an AI response
+func ParseTradeReviewResponse(response string) (*TradeReviewResult, error) {
+	s := removeInvisibleRunes(response)
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("no JSON object found in review response")
+	}
+
+	var result TradeReviewResult
+	if err := json.Unmarshal([]byte(s[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse review response: %w", err)
+	}
+
+	result.Summary = strings.TrimSpace(result.Summary)
+	result.Mistakes = cleanLessonList(result.Mistakes)
+	result.Rules = cleanLessonList(result.Rules)
+	return &result, nil
+}
+
+// cleanLessonList trims entries and drops empty ones and duplicates
+func cleanLessonList(items []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,298 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/rules"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Rule-based Strategy - deterministic decisions without AI
+// ============================================================================
+
+// RuleSet compiled rules of a rule_based strategy
+type RuleSet struct {
+	LongEntry    *rules.Expr
+	ShortEntry   *rules.Expr
+	LongExit     *rules.Expr
+	ShortExit    *rules.Expr
+	PositionSize *rules.Expr
+	StopLoss     *rules.Expr
+	TakeProfit   *rules.Expr
+	Leverage     int
+}
+
+// CompileRuleSet compiles every rule of the config, returning the first error with its field name.
+// Timeframe-suffixed variables (rsi14_4h) must use one of the strategy's K-line timeframes.
+func CompileRuleSet(cfg *store.RuleStrategyConfig, klines store.KlineConfig) (*RuleSet, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("rule_config is not set")
+	}
+	if strings.TrimSpace(cfg.LongEntry) == "" && strings.TrimSpace(cfg.ShortEntry) == "" {
+		return nil, fmt.Errorf("rule_config needs long_entry or short_entry")
+	}
+	if strings.TrimSpace(cfg.PositionSize) == "" {
+		return nil, fmt.Errorf("rule_config.position_size is required")
+	}
+	if cfg.Leverage < 0 {
+		return nil, fmt.Errorf("rule_config.leverage must not be negative")
+	}
+
+	timeframes, primaryTimeframe := StrategyTimeframes(klines)
+	available := map[string]bool{primaryTimeframe: true}
+	for _, tf := range timeframes {
+		available[tf] = true
+	}
+
+	rs := &RuleSet{Leverage: cfg.Leverage}
+	fields := []struct {
+		name   string
+		source string
+		target **rules.Expr
+	}{
+		{"long_entry", cfg.LongEntry, &rs.LongEntry},
+		{"short_entry", cfg.ShortEntry, &rs.ShortEntry},
+		{"long_exit", cfg.LongExit, &rs.LongExit},
+		{"short_exit", cfg.ShortExit, &rs.ShortExit},
+		{"position_size", cfg.PositionSize, &rs.PositionSize},
+		{"stop_loss", cfg.StopLoss, &rs.StopLoss},
+		{"take_profit", cfg.TakeProfit, &rs.TakeProfit},
+	}
+	for _, f := range fields {
+		if strings.TrimSpace(f.source) == "" {
+			continue
+		}
+		expr, err := rules.Compile(f.source)
+		if err != nil {
+			return nil, fmt.Errorf("rule_config.%s: %w", f.name, err)
+		}
+		for _, name := range expr.Variables() {
+			if tf := rules.VariableTimeframe(name); tf != "" && !available[tf] {
+				return nil, fmt.Errorf("rule_config.%s: variable %s needs the %s timeframe, which is not in the K-line timeframes %v", f.name, name, tf, timeframes)
+			}
+		}
+		*f.target = expr
+	}
+	return rs, nil
+}
+
+// GetRuleBasedDecision evaluates the rule_based strategy for held positions and candidates.
+// Same output as GetFullDecisionWithStrategy, but no AI call is made.
+func GetRuleBasedDecision(ctx *Context, engine *StrategyEngine) (*FullDecision, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	if engine == nil {
+		return nil, fmt.Errorf("strategy engine is nil")
+	}
+	ctx.StrategyConfig = engine.config
+
+	rs, err := CompileRuleSet(engine.config.RuleConfig, engine.config.Indicators.Klines)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ctx.MarketDataMap) == 0 {
+		if err := fetchMarketDataWithStrategy(ctx, engine); err != nil {
+			return nil, fmt.Errorf("failed to fetch market data: %w", err)
+		}
+	}
+
+	primaryTF := engine.config.Indicators.Klines.PrimaryTimeframe
+	risk := engine.GetRiskControlConfig()
+	accountVars := rules.Vars{
+		"equity":          ctx.Account.TotalEquity,
+		"available":       ctx.Account.AvailableBalance,
+		"margin_used_pct": ctx.Account.MarginUsedPct,
+		"positions":       float64(len(ctx.Positions)),
+	}
+
+	var decisions []Decision
+	var trace strings.Builder
+
+	// 1. Exit rules for held positions
+	held := make(map[string]bool, len(ctx.Positions))
+	for _, pos := range ctx.Positions {
+		held[pos.Symbol] = true
+
+		exitRule, action := rs.LongExit, "close_long"
+		if pos.Side == "short" {
+			exitRule, action = rs.ShortExit, "close_short"
+		}
+		if exitRule == nil {
+			continue
+		}
+
+		vars := rules.MarketVars(ctx.MarketDataMap[pos.Symbol], primaryTF).Merge(accountVars)
+		vars["entry_price"] = pos.EntryPrice
+		vars["pnl_pct"] = pos.UnrealizedPnLPct
+		vars["peak_pnl_pct"] = pos.PeakPnLPct
+		if pos.MarkPrice > 0 {
+			vars["price"] = pos.MarkPrice
+		}
+
+		hit, err := exitRule.EvalBool(vars)
+		if err != nil {
+			fmt.Fprintf(&trace, "%s %s exit: skipped (%v)\n", pos.Symbol, pos.Side, err)
+			continue
+		}
+		fmt.Fprintf(&trace, "%s %s exit [%s] = %v\n", pos.Symbol, pos.Side, exitRule, hit)
+		if hit {
+			decisions = append(decisions, Decision{
+				Symbol:     pos.Symbol,
+				Action:     action,
+				Confidence: 100,
+				Reasoning:  fmt.Sprintf("Rule %s_exit matched: %s", pos.Side, exitRule),
+			})
+		}
+	}
+
+	// 2. Entry rules for candidates (no pyramiding, bounded by max positions)
+	slots := risk.MaxPositions - len(ctx.Positions)
+	for _, coin := range ctx.CandidateCoins {
+		if slots <= 0 {
+			break
+		}
+		if held[coin.Symbol] {
+			continue
+		}
+		data, ok := ctx.MarketDataMap[coin.Symbol]
+		if !ok || data.CurrentPrice <= 0 {
+			continue
+		}
+		vars := rules.MarketVars(data, primaryTF).Merge(accountVars)
+
+		d, err := evaluateEntry(rs, coin.Symbol, data.CurrentPrice, vars, risk, &trace)
+		if err != nil {
+			fmt.Fprintf(&trace, "%s entry: skipped (%v)\n", coin.Symbol, err)
+			continue
+		}
+		if d != nil {
+			decisions = append(decisions, *d)
+			slots--
+		}
+	}
+
+	logger.Infof("📐 Rule-based decision: %d actions from %d positions / %d candidates",
+		len(decisions), len(ctx.Positions), len(ctx.CandidateCoins))
+
+	return &FullDecision{
+		SystemPrompt: describeRuleConfig(engine.config.RuleConfig),
+		CoTTrace:     trace.String(),
+		Decisions:    decisions,
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// evaluateEntry evaluates the entry rules of one symbol (long first); nil when no rule matched
+func evaluateEntry(rs *RuleSet, symbol string, price float64, vars rules.Vars, risk store.RiskControlConfig, trace *strings.Builder) (*Decision, error) {
+	sides := []struct {
+		side   string
+		action string
+		rule   *rules.Expr
+	}{
+		{"long", "open_long", rs.LongEntry},
+		{"short", "open_short", rs.ShortEntry},
+	}
+
+	for _, s := range sides {
+		if s.rule == nil {
+			continue
+		}
+		hit, err := s.rule.EvalBool(vars)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(trace, "%s %s entry [%s] = %v\n", symbol, s.side, s.rule, hit)
+		if !hit {
+			continue
+		}
+
+		size, err := rs.PositionSize.Eval(vars)
+		if err != nil {
+			return nil, fmt.Errorf("position_size: %w", err)
+		}
+
+		// Code-enforced limits (same as AI decisions)
+		maxLeverage, maxRatio := risk.AltcoinMaxLeverage, risk.AltcoinMaxPositionValueRatio
+		if symbol == "BTCUSDT" || symbol == "ETHUSDT" {
+			maxLeverage, maxRatio = risk.BTCETHMaxLeverage, risk.BTCETHMaxPositionValueRatio
+		}
+		if maxRatio > 0 && size > vars["equity"]*maxRatio {
+			size = vars["equity"] * maxRatio
+		}
+		if size <= 0 || size < risk.MinPositionSize {
+			return nil, fmt.Errorf("position size %.2f USDT is below the minimum %.2f", size, risk.MinPositionSize)
+		}
+		leverage := rs.Leverage
+		if leverage <= 0 || leverage > maxLeverage {
+			leverage = maxLeverage
+		}
+		if leverage <= 0 {
+			leverage = 1
+		}
+
+		d := &Decision{
+			Symbol:          symbol,
+			Action:          s.action,
+			Leverage:        leverage,
+			PositionSizeUSD: size,
+			Confidence:      100,
+			Reasoning:       fmt.Sprintf("Rule %s_entry matched: %s", s.side, s.rule),
+		}
+		sign := 1.0
+		if s.side == "short" {
+			sign = -1
+		}
+		if rs.StopLoss != nil {
+			distance, err := rs.StopLoss.Eval(vars)
+			if err != nil {
+				return nil, fmt.Errorf("stop_loss: %w", err)
+			}
+			if distance > 0 {
+				d.StopLoss = price - sign*distance
+			}
+		}
+		if rs.TakeProfit != nil {
+			distance, err := rs.TakeProfit.Eval(vars)
+			if err != nil {
+				return nil, fmt.Errorf("take_profit: %w", err)
+			}
+			if distance > 0 {
+				d.TakeProfit = price + sign*distance
+			}
+		}
+		if d.StopLoss < 0 || d.TakeProfit < 0 {
+			return nil, fmt.Errorf("stop loss / take profit distance exceeds price %.4f", price)
+		}
+		return d, nil
+	}
+	return nil, nil
+}
+
+// describeRuleConfig renders the rules (stored as the "system prompt" of rule-based decisions)
+func describeRuleConfig(cfg *store.RuleStrategyConfig) string {
+	var sb strings.Builder
+	sb.WriteString("# Rule-based strategy\n")
+	rows := [][2]string{
+		{"long_entry", cfg.LongEntry},
+		{"short_entry", cfg.ShortEntry},
+		{"long_exit", cfg.LongExit},
+		{"short_exit", cfg.ShortExit},
+		{"position_size", cfg.PositionSize},
+		{"stop_loss", cfg.StopLoss},
+		{"take_profit", cfg.TakeProfit},
+	}
+	for _, row := range rows {
+		if row[1] != "" {
+			fmt.Fprintf(&sb, "- %s: %s\n", row[0], row[1])
+		}
+	}
+	if cfg.Leverage > 0 {
+		fmt.Fprintf(&sb, "- leverage: %dx\n", cfg.Leverage)
+	}
+	return sb.String()
+}
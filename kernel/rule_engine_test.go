@@ -0,0 +1,105 @@
+package kernel
+
+import (
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func ruleTestEngine(rc *store.RuleStrategyConfig) *StrategyEngine {
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "rule_based"
+	cfg.RuleConfig = rc
+	cfg.Indicators.Klines.PrimaryTimeframe = "15m"
+	cfg.RiskControl.MaxPositions = 2
+	cfg.RiskControl.AltcoinMaxLeverage = 5
+	cfg.RiskControl.AltcoinMaxPositionValueRatio = 1
+	cfg.RiskControl.MinPositionSize = 12
+	return NewStrategyEngine(&cfg)
+}
+
+func ruleTestData(price, ema20, ema50 float64) *market.Data {
+	return &market.Data{
+		CurrentPrice: price,
+		TimeframeData: map[string]*market.TimeframeSeriesData{
+			"15m": {
+				Klines:      []market.KlineBar{{Close: price}},
+				EMA20Values: []float64{ema20},
+				EMA50Values: []float64{ema50},
+				ATR14:       2,
+			},
+		},
+	}
+}
+
+func TestGetRuleBasedDecision(t *testing.T) {
+	engine := ruleTestEngine(&store.RuleStrategyConfig{
+		LongEntry:    "ema20 > ema50",
+		ShortEntry:   "ema20 < ema50",
+		LongExit:     "ema20 < ema50 || pnl_pct < -5",
+		PositionSize: "equity * 2",
+		StopLoss:     "2 * atr14",
+		TakeProfit:   "6 * atr14",
+	})
+
+	ctx := &Context{
+		Account: AccountInfo{TotalEquity: 1000, AvailableBalance: 800},
+		Positions: []PositionInfo{
+			{Symbol: "SOLUSDT", Side: "long", EntryPrice: 100, MarkPrice: 99, UnrealizedPnLPct: -1},
+		},
+		CandidateCoins: []CandidateCoin{{Symbol: "SOLUSDT"}, {Symbol: "XRPUSDT"}, {Symbol: "DOGEUSDT"}},
+		MarketDataMap: map[string]*market.Data{
+			"SOLUSDT":  ruleTestData(99, 95, 100), // Trend flipped: exit long
+			"XRPUSDT":  ruleTestData(50, 49, 48),  // Long entry
+			"DOGEUSDT": ruleTestData(20, 19, 21),  // Short entry, but no slot left
+		},
+	}
+
+	fd, err := GetRuleBasedDecision(ctx, engine)
+	if err != nil {
+		t.Fatalf("GetRuleBasedDecision() error: %v", err)
+	}
+	if len(fd.Decisions) != 2 {
+		t.Fatalf("decisions = %+v, want close SOL and open XRP", fd.Decisions)
+	}
+
+	if d := fd.Decisions[0]; d.Symbol != "SOLUSDT" || d.Action != "close_long" {
+		t.Errorf("first decision = %s %s, want SOLUSDT close_long", d.Symbol, d.Action)
+	}
+
+	open := fd.Decisions[1]
+	if open.Symbol != "XRPUSDT" || open.Action != "open_long" {
+		t.Fatalf("second decision = %s %s, want XRPUSDT open_long", open.Symbol, open.Action)
+	}
+	// Size capped by altcoin position value ratio, leverage defaults to the risk limit
+	if open.PositionSizeUSD != 1000 || open.Leverage != 5 {
+		t.Errorf("size/leverage = %.0f/%d, want 1000/5", open.PositionSizeUSD, open.Leverage)
+	}
+	if open.StopLoss != 46 || open.TakeProfit != 62 {
+		t.Errorf("SL/TP = %.2f/%.2f, want 46/62", open.StopLoss, open.TakeProfit)
+	}
+}
+
+func TestCompileRuleSetErrors(t *testing.T) {
+	tests := []*store.RuleStrategyConfig{
+		nil,
+		{PositionSize: "100"},
+		{LongEntry: "ema20 > ema50"},
+		{LongEntry: "ema20 >", PositionSize: "100"},
+		{LongEntry: "ema20 > ema50", PositionSize: "100", Leverage: -1},
+		// 4h is not among the K-line timeframes
+		{LongEntry: "ema20 > ema50 && rsi14_4h < 70", PositionSize: "100"},
+	}
+	klines := store.KlineConfig{PrimaryTimeframe: "15m", SelectedTimeframes: []string{"15m", "1h"}}
+	for i, cfg := range tests {
+		if _, err := CompileRuleSet(cfg, klines); err == nil {
+			t.Errorf("case %d: CompileRuleSet() should fail", i)
+		}
+	}
+
+	cfg := &store.RuleStrategyConfig{LongEntry: "rsi14_1h < 30 && prev_close_15m < close", PositionSize: "100"}
+	if _, err := CompileRuleSet(cfg, klines); err != nil {
+		t.Errorf("CompileRuleSet() with configured timeframes: %v", err)
+	}
+}
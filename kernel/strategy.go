@@ -0,0 +1,586 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Pluggable Strategies
+// ============================================================================
+//
+// A Strategy turns a Context into decisions. Implementations are registered by
+// StrategyType and created by AutoTrader and backtest.Runner, so custom compiled
+// strategies plug in without touching the trading loop:
+//
+//	func init() {
+//		kernel.RegisterStrategy(kernel.StrategyRegistration{
+//			Type: "my_strategy",
+//			New:  func(env kernel.StrategyEnv) (kernel.Strategy, error) { return &myStrategy{env: env}, nil },
+//		})
+//	}
+//
+// Strategies whose orders need more than the generic decision executor (grids, deals, multi-leg
+// positions) also register their cycle with the trader, see trader.RegisterStrategyCycle.
+
+// Strategy decision maker of one strategy type
+type Strategy interface {
+	// Init is called once before the first decision cycle
+	Init() error
+	// Decide returns the decisions of one cycle
+	Decide(ctx *Context) ([]Decision, error)
+	// Close is called when the trader or backtest stops
+	Close() error
+}
+
+// DetailedStrategy optional: strategies that also report prompts and reasoning for decision records
+type DetailedStrategy interface {
+	Strategy
+	DecideDetailed(ctx *Context) (*FullDecision, error)
+}
+
+// DecisionReviewer optional: strategies that veto or adjust decisions coming from outside the
+// decision cycle (e.g. debate consensus executed on the trader)
+type DecisionReviewer interface {
+	ReviewDecision(d *Decision) error
+}
+
+// SeriesStrategy optional: strategies that read kline series from Context.MarketDataMap report
+// how many bars they need (backtests attach at least this many)
+type SeriesStrategy interface {
+	SeriesBars() int
+}
+
+// StrategyEnv dependencies handed to a strategy factory
+type StrategyEnv struct {
+	Config   *store.StrategyConfig
+	Engine   *StrategyEngine
+	AIClient mcp.AIClient // May be nil for strategies that do not require AI
+	Variant  string       // Prompt variant ("balanced", "baseline", ...)
+}
+
+// StrategyFactory creates a strategy instance
+type StrategyFactory func(env StrategyEnv) (Strategy, error)
+
+// StrategyRegistration describes a strategy type
+type StrategyRegistration struct {
+	Type       string
+	RequiresAI bool // Decide calls the AI client (backtests need an AI model and may cache decisions)
+	New        StrategyFactory
+	// Validate optional: checks the strategy's own config section (run before New and when a
+	// strategy is saved)
+	Validate func(cfg *store.StrategyConfig) error
+	// CheckBacktest optional: returns why a config cannot be backtested (nil = supported)
+	CheckBacktest func(cfg *store.StrategyConfig) error
+	// Markets optional: symbols the strategy trades instead of its coin source, and K-line
+	// timeframes it reads besides the configured ones
+	Markets func(cfg *store.StrategyConfig) (symbols []string, timeframes []string)
+}
+
+var (
+	strategyRegistryMu sync.RWMutex
+	strategyRegistry   = map[string]StrategyRegistration{}
+)
+
+// DefaultStrategyType strategy type used when StrategyConfig.StrategyType is empty
+const DefaultStrategyType = "ai_trading"
+
+// RegisterStrategy registers a strategy type (a later registration replaces an earlier one)
+func RegisterStrategy(reg StrategyRegistration) {
+	if reg.Type == "" || reg.New == nil {
+		panic("kernel: RegisterStrategy requires Type and New")
+	}
+	strategyRegistryMu.Lock()
+	defer strategyRegistryMu.Unlock()
+	strategyRegistry[reg.Type] = reg
+}
+
+// LookupStrategy returns the registration of a strategy type ("" = default type)
+func LookupStrategy(strategyType string) (StrategyRegistration, bool) {
+	if strategyType == "" {
+		strategyType = DefaultStrategyType
+	}
+	strategyRegistryMu.RLock()
+	defer strategyRegistryMu.RUnlock()
+	reg, ok := strategyRegistry[strategyType]
+	return reg, ok
+}
+
+// RegisteredStrategyTypes returns all registered strategy types (sorted)
+func RegisteredStrategyTypes() []string {
+	strategyRegistryMu.RLock()
+	defer strategyRegistryMu.RUnlock()
+	types := make([]string, 0, len(strategyRegistry))
+	for t := range strategyRegistry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ValidateStrategy checks a config with the validation registered for its strategy type
+func ValidateStrategy(cfg *store.StrategyConfig) error {
+	reg, ok := LookupStrategy(cfg.StrategyType)
+	if !ok {
+		return fmt.Errorf("strategy_type '%s' is invalid (%s)", cfg.StrategyType, strings.Join(RegisteredStrategyTypes(), ", "))
+	}
+	if reg.Validate != nil {
+		return reg.Validate(cfg)
+	}
+	return nil
+}
+
+// CheckBacktest returns why a config cannot be backtested (nil = supported)
+func CheckBacktest(cfg *store.StrategyConfig) error {
+	reg, ok := LookupStrategy(cfg.StrategyType)
+	if !ok {
+		return fmt.Errorf("unknown strategy type: %s", cfg.StrategyType)
+	}
+	if reg.CheckBacktest != nil {
+		return reg.CheckBacktest(cfg)
+	}
+	return nil
+}
+
+// StrategyMarkets returns the symbols a config trades on its own (nil = use the coin source) and
+// the extra K-line timeframes it needs
+func StrategyMarkets(cfg *store.StrategyConfig) ([]string, []string) {
+	reg, ok := LookupStrategy(cfg.StrategyType)
+	if !ok || reg.Markets == nil {
+		return nil, nil
+	}
+	return reg.Markets(cfg)
+}
+
+// NewStrategy creates the strategy registered for env.Config.StrategyType
+func NewStrategy(env StrategyEnv) (Strategy, error) {
+	if env.Config == nil {
+		return nil, fmt.Errorf("strategy config is nil")
+	}
+	reg, ok := LookupStrategy(env.Config.StrategyType)
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy type: %s", env.Config.StrategyType)
+	}
+	if reg.RequiresAI && env.AIClient == nil {
+		return nil, fmt.Errorf("strategy type %s requires an AI client", reg.Type)
+	}
+	if reg.Validate != nil {
+		if err := reg.Validate(env.Config); err != nil {
+			return nil, err
+		}
+	}
+	if env.Engine == nil {
+		env.Engine = NewStrategyEngine(env.Config)
+	}
+	return reg.New(env)
+}
+
+// RunStrategy runs one decision cycle, returning the full decision for records
+func RunStrategy(s Strategy, ctx *Context) (*FullDecision, error) {
+	if detailed, ok := s.(DetailedStrategy); ok {
+		return detailed.DecideDetailed(ctx)
+	}
+	decisions, err := s.Decide(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &FullDecision{Decisions: decisions, Timestamp: time.Now()}, nil
+}
+
+// ============================================================================
+// Built-in Strategies
+// ============================================================================
+
+func init() {
+	RegisterStrategy(StrategyRegistration{
+		Type:       "ai_trading",
+		RequiresAI: true,
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&aiStrategy{env: env}), nil
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "grid_trading",
+		New: func(env StrategyEnv) (Strategy, error) {
+			// AI mode asks the AI every cycle; deterministic mode only for optional re-tuning
+			if env.AIClient == nil && GridRequiresAI(env.Config.GridConfig) {
+				return nil, fmt.Errorf("grid_trading requires an AI client unless mode is '%s' without AI re-tuning", GridModeDeterministic)
+			}
+			return withDetailedBase(&gridStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidateGridConfig(cfg.GridConfig)
+		},
+		CheckBacktest: func(cfg *store.StrategyConfig) error {
+			if IsMultiSymbolGrid(cfg.GridConfig) {
+				return fmt.Errorf("multi-symbol grid backtests are not supported, backtest each symbol grid separately")
+			}
+			return nil
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "rule_based",
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&ruleStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			_, err := CompileRuleSet(cfg.RuleConfig, cfg.Indicators.Klines)
+			return err
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "dca",
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&dcaStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidateDCAConfig(cfg.DCAConfig)
+		},
+		Markets: func(cfg *store.StrategyConfig) ([]string, []string) {
+			if cfg.DCAConfig == nil {
+				return nil, nil
+			}
+			return []string{cfg.DCAConfig.Symbol}, nil
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "funding_carry",
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&fundingCarryStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidateFundingCarryConfig(cfg.FundingCarryConfig)
+		},
+		CheckBacktest: func(cfg *store.StrategyConfig) error {
+			return fmt.Errorf("funding_carry trades across several exchange accounts and cannot be backtested")
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "pairs_trading",
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&pairsStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidatePairsConfig(cfg.PairsConfig)
+		},
+		Markets: func(cfg *store.StrategyConfig) ([]string, []string) {
+			pc := cfg.PairsConfig
+			if pc == nil {
+				return nil, nil
+			}
+			// The spread is computed on the pair's own timeframe
+			return []string{pc.SymbolA, pc.SymbolB}, []string{PairsTimeframe(pc)}
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "rebalance",
+		New: func(env StrategyEnv) (Strategy, error) {
+			if env.Config.RebalanceConfig.AITargets && env.AIClient == nil {
+				return nil, fmt.Errorf("rebalance_config.ai_targets requires an AI client")
+			}
+			return withDetailedBase(&rebalanceStrategy{env: env}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidateRebalanceConfig(cfg.RebalanceConfig)
+		},
+		Markets: func(cfg *store.StrategyConfig) ([]string, []string) {
+			if cfg.RebalanceConfig == nil {
+				return nil, nil
+			}
+			return RebalanceSymbols(cfg.RebalanceConfig), nil
+		},
+		CheckBacktest: func(cfg *store.StrategyConfig) error {
+			if cfg.RebalanceConfig != nil && cfg.RebalanceConfig.AITargets {
+				return fmt.Errorf("rebalance strategies with ai_targets are not supported in backtests")
+			}
+			return nil
+		},
+	})
+	RegisterStrategy(StrategyRegistration{
+		Type: "regime_router",
+		New: func(env StrategyEnv) (Strategy, error) {
+			return withDetailedBase(&regimeRouterStrategy{}), nil
+		},
+		Validate: func(cfg *store.StrategyConfig) error {
+			return ValidateRegimeRouterConfig(cfg.RegimeRouterConfig)
+		},
+		CheckBacktest: func(cfg *store.StrategyConfig) error {
+			return fmt.Errorf("regime_router switches between saved strategies and cannot be backtested")
+		},
+	})
+}
+
+// detailedDecider the decision logic of a built-in strategy
+type detailedDecider interface {
+	DecideDetailed(ctx *Context) (*FullDecision, error)
+}
+
+// detailedBase is embedded by the built-in strategies so that they only implement DecideDetailed:
+// Init and Close do nothing and Decide returns the decisions of DecideDetailed
+type detailedBase struct {
+	decider detailedDecider
+}
+
+func (b *detailedBase) Init() error  { return nil }
+func (b *detailedBase) Close() error { return nil }
+
+func (b *detailedBase) Decide(ctx *Context) ([]Decision, error) {
+	fd, err := b.decider.DecideDetailed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fd.Decisions, nil
+}
+
+func (b *detailedBase) bind(decider detailedDecider) { b.decider = decider }
+
+// withDetailedBase binds the embedded detailedBase of a built-in strategy to its DecideDetailed
+func withDetailedBase[S interface {
+	detailedDecider
+	bind(decider detailedDecider)
+}](s S) S {
+	s.bind(s)
+	return s
+}
+
+// aiStrategy full AI decision with the strategy engine prompts
+type aiStrategy struct {
+	detailedBase
+	env StrategyEnv
+}
+
+func (s *aiStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	return GetFullDecisionWithStrategy(ctx, s.env.AIClient, s.env.Engine, s.env.Variant)
+}
+
+// gridStrategy grid decisions (AI or deterministic); the grid state is provided in Context.Grid by the executor
+type gridStrategy struct {
+	detailedBase
+	env StrategyEnv
+}
+
+func (s *gridStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	if ctx == nil || ctx.Grid == nil {
+		return nil, fmt.Errorf("grid context is not available")
+	}
+	cfg := s.env.Config.GridConfig
+	if ctx.Grid.Config != nil {
+		cfg = ctx.Grid.Config
+	}
+	if IsDeterministicGrid(cfg) {
+		// A re-tuning replaces the levels, orders are placed on the new grid next cycle
+		tuned, err := RefreshGridTuning(ctx.Grid, cfg, s.env.AIClient)
+		if err != nil {
+			logger.Warnf("[Grid] AI re-tuning failed, keeping current parameters: %v", err)
+		}
+		if tuned != nil {
+			return tuned, nil
+		}
+		return GetDeterministicGridDecisions(ctx.Grid, cfg), nil
+	}
+	lang := s.env.Config.Language
+	if lang == "" {
+		lang = "en"
+	}
+	return GetGridDecisions(ctx.Grid, s.env.AIClient, cfg, lang)
+}
+
+// ruleStrategy deterministic rules (no AI)
+type ruleStrategy struct {
+	detailedBase
+	env StrategyEnv
+}
+
+func (s *ruleStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	return GetRuleBasedDecision(ctx, s.env.Engine)
+}
+
+// dcaStrategy DCA bot decisions (no AI). Executors that manage deals (AutoTrader) pass them in
+// Context.DCA; otherwise (backtests) the deal is tracked here from the positions of each cycle.
+// Orders only change the deal once the next cycle's position shows them filled.
+type dcaStrategy struct {
+	detailedBase
+	env          StrategyEnv
+	deal         *DCADeal
+	lastClosedAt time.Time
+
+	pendingLevels []int     // Safety orders sent in the previous cycle
+	pendingAt     time.Time // Time of the previous cycle's orders
+}
+
+func (s *dcaStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	if ctx.DCA != nil {
+		return GetDCADecisions(ctx.DCA)
+	}
+
+	cfg := s.env.Config.DCAConfig
+	data, ok := ctx.MarketDataMap[cfg.Symbol]
+	if !ok || data == nil || data.CurrentPrice <= 0 {
+		return nil, fmt.Errorf("no market data for DCA symbol %s", cfg.Symbol)
+	}
+	now, err := time.Parse("2006-01-02 15:04:05 UTC", ctx.CurrentTime)
+	if err != nil {
+		now = time.Now().UTC()
+	}
+
+	var pos *PositionInfo
+	for i := range ctx.Positions {
+		if ctx.Positions[i].Symbol == cfg.Symbol && ctx.Positions[i].Side == DCASide(cfg) {
+			pos = &ctx.Positions[i]
+			break
+		}
+	}
+
+	// Reconcile the tracked deal with the position (closed by the deal, stop loss or liquidation,
+	// opened by the base order or adopted, grown by safety orders)
+	filledAt := now
+	if !s.pendingAt.IsZero() {
+		filledAt = s.pendingAt
+	}
+	switch {
+	case pos == nil && s.deal != nil:
+		s.deal, s.lastClosedAt = nil, filledAt
+	case pos != nil && s.deal == nil:
+		s.deal = NewDCADeal(cfg, pos.EntryPrice, pos.Quantity, filledAt)
+	case pos != nil && pos.Quantity > s.deal.Quantity && len(s.pendingLevels) > 0:
+		fillPrice := (pos.EntryPrice*pos.Quantity - s.deal.CostUSD) / (pos.Quantity - s.deal.Quantity)
+		for _, level := range s.pendingLevels {
+			if level >= 0 && level < len(s.deal.SafetyOrders) {
+				s.deal.SafetyOrders[level].State = "filled"
+				s.deal.SafetyOrders[level].FilledPrice = fillPrice
+			}
+		}
+	}
+	if pos != nil {
+		s.deal.Quantity, s.deal.CostUSD = pos.Quantity, pos.EntryPrice*pos.Quantity
+	}
+	s.pendingLevels, s.pendingAt = nil, time.Time{}
+
+	fd, err := GetDCADecisions(&DCAContext{
+		Config:           cfg,
+		Deal:             s.deal,
+		CurrentPrice:     data.CurrentPrice,
+		Now:              now,
+		LastDealClosedAt: s.lastClosedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Decisions are executed at market after the cycle; the next cycle confirms them from the position
+	for _, d := range fd.Decisions {
+		if strings.HasPrefix(d.Action, "open_") && s.deal != nil {
+			s.pendingLevels = append(s.pendingLevels, d.LevelIndex)
+		}
+	}
+	if len(fd.Decisions) > 0 {
+		s.pendingAt = now
+	}
+	return fd, nil
+}
+
+// ReviewDecision only lets outside decisions close the deal: orders the deal did not plan would
+// leave its safety orders and take profit out of line with the position
+func (s *dcaStrategy) ReviewDecision(d *Decision) error {
+	cfg := s.env.Config.DCAConfig
+	switch {
+	case d.Action == "hold" || d.Action == "wait":
+		return nil
+	case market.Normalize(d.Symbol) != market.Normalize(cfg.Symbol):
+		return fmt.Errorf("the DCA bot only trades %s", cfg.Symbol)
+	case d.Action != "close_"+DCASide(cfg):
+		return fmt.Errorf("%s would change the deal outside its safety orders; only close_%s is accepted", d.Action, DCASide(cfg))
+	}
+	return nil
+}
+
+// fundingCarryStrategy cross-venue funding carry (no AI); quotes and pairs are provided in
+// Context.FundingCarry by the executor, which holds the connections to all venues
+type fundingCarryStrategy struct {
+	detailedBase
+	env StrategyEnv
+}
+
+func (s *fundingCarryStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	if ctx == nil || ctx.FundingCarry == nil {
+		return nil, fmt.Errorf("funding carry context is not available")
+	}
+	return GetFundingCarryDecisions(ctx.FundingCarry)
+}
+
+// pairsStrategy two-leg statistical arbitrage (no AI)
+type pairsStrategy struct {
+	detailedBase
+	env StrategyEnv
+}
+
+func (s *pairsStrategy) SeriesBars() int {
+	return PairsLookbackBars(s.env.Config.PairsConfig)
+}
+
+func (s *pairsStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	return GetPairsDecisions(ctx, s.env.Config.PairsConfig)
+}
+
+// rebalanceStrategy target-weight portfolio (AI sets the weights when ai_targets is enabled).
+// Executors that manage rebalancing (AutoTrader) pass the targets in Context.Rebalance; otherwise
+// (backtests) they are tracked here.
+type rebalanceStrategy struct {
+	detailedBase
+	env   StrategyEnv
+	state RebalanceContext
+}
+
+func (s *rebalanceStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	cfg := s.env.Config.RebalanceConfig
+	rc := ctx.Rebalance
+	if rc == nil {
+		rc = &s.state
+		now, err := time.Parse("2006-01-02 15:04:05 UTC", ctx.CurrentTime)
+		if err != nil {
+			now = time.Now().UTC()
+		}
+		rc.Now = now
+		rc.Prices = make(map[string]float64, len(ctx.MarketDataMap))
+		for symbol, data := range ctx.MarketDataMap {
+			if data != nil {
+				rc.Prices[symbol] = data.CurrentPrice
+			}
+		}
+	}
+
+	if err := RefreshRebalanceTargets(rc, cfg, s.env.AIClient, ctx); err != nil {
+		logger.Warnf("⚠️ Rebalance: %v (keeping the current targets)", err)
+	}
+	fd, err := GetRebalanceDecisions(rc, cfg, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Rebalance == nil && len(fd.Decisions) > 0 {
+		s.state.LastRebalanceAt = rc.Now
+	}
+	return fd, nil
+}
+
+// regimeRouterStrategy placeholder of the regime router: the trader classifies the regime and
+// runs the routed strategy, so the router itself never decides
+type regimeRouterStrategy struct {
+	detailedBase
+}
+
+func (s *regimeRouterStrategy) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	return nil, fmt.Errorf("regime_router has no decisions of its own; it runs the strategy routed to the current regime")
+}
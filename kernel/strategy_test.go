@@ -0,0 +1,194 @@
+package kernel
+
+import (
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+// staticStrategy returns fixed decisions and records lifecycle calls
+type staticStrategy struct {
+	decisions []Decision
+	inited    bool
+	closed    bool
+}
+
+func (s *staticStrategy) Init() error                             { s.inited = true; return nil }
+func (s *staticStrategy) Close() error                            { s.closed = true; return nil }
+func (s *staticStrategy) Decide(ctx *Context) ([]Decision, error) { return s.decisions, nil }
+
+func TestStrategyRegistry(t *testing.T) {
+	for _, typ := range []string{"ai_trading", "grid_trading", "rule_based"} {
+		if _, ok := LookupStrategy(typ); !ok {
+			t.Errorf("built-in strategy %s is not registered", typ)
+		}
+	}
+	if reg, ok := LookupStrategy(""); !ok || reg.Type != DefaultStrategyType {
+		t.Errorf("LookupStrategy(\"\") = %s, %v, want %s", reg.Type, ok, DefaultStrategyType)
+	}
+
+	custom := &staticStrategy{decisions: []Decision{{Symbol: "BTCUSDT", Action: "hold"}}}
+	RegisterStrategy(StrategyRegistration{
+		Type: "test_static",
+		New:  func(env StrategyEnv) (Strategy, error) { return custom, nil },
+	})
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "test_static"
+	s, err := NewStrategy(StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatalf("NewStrategy() error: %v", err)
+	}
+	if err := s.Init(); err != nil || !custom.inited {
+		t.Fatalf("Init() = %v, inited = %v", err, custom.inited)
+	}
+
+	fd, err := RunStrategy(s, &Context{})
+	if err != nil {
+		t.Fatalf("RunStrategy() error: %v", err)
+	}
+	if len(fd.Decisions) != 1 || fd.Decisions[0].Symbol != "BTCUSDT" || fd.Timestamp.IsZero() {
+		t.Errorf("RunStrategy() = %+v, want the static decision", fd)
+	}
+	if err := s.Close(); err != nil || !custom.closed {
+		t.Errorf("Close() = %v, closed = %v", err, custom.closed)
+	}
+}
+
+// detailedStatic a built-in style strategy that only implements DecideDetailed
+type detailedStatic struct {
+	detailedBase
+}
+
+func (s *detailedStatic) DecideDetailed(ctx *Context) (*FullDecision, error) {
+	return &FullDecision{Decisions: []Decision{{Symbol: "ETHUSDT", Action: "wait"}}}, nil
+}
+
+func TestDetailedBase(t *testing.T) {
+	var s Strategy = withDetailedBase(&detailedStatic{})
+	if s.Init() != nil || s.Close() != nil {
+		t.Error("Init and Close of a built-in strategy should do nothing")
+	}
+	decisions, err := s.Decide(&Context{})
+	if err != nil || len(decisions) != 1 || decisions[0].Symbol != "ETHUSDT" {
+		t.Errorf("Decide() = %+v, %v; want the decisions of DecideDetailed", decisions, err)
+	}
+}
+
+func TestNewStrategyErrors(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+
+	cfg.StrategyType = "unknown"
+	if _, err := NewStrategy(StrategyEnv{Config: &cfg}); err == nil {
+		t.Error("unknown strategy type should fail")
+	}
+
+	cfg.StrategyType = "ai_trading"
+	if _, err := NewStrategy(StrategyEnv{Config: &cfg}); err == nil {
+		t.Error("AI strategy without an AI client should fail")
+	}
+
+	cfg.StrategyType = "rule_based"
+	if _, err := NewStrategy(StrategyEnv{Config: &cfg}); err == nil {
+		t.Error("rule_based strategy without rule_config should fail")
+	}
+}
+
+func TestDCAReviewDecision(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "dca"
+	cfg.DCAConfig = &store.DCAStrategyConfig{Symbol: "BTCUSDT", Leverage: 2, BaseOrderUSD: 100, TakeProfitPct: 1}
+	s, err := NewStrategy(StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatalf("NewStrategy() error: %v", err)
+	}
+	reviewer, ok := s.(DecisionReviewer)
+	if !ok {
+		t.Fatal("dca strategy should review outside decisions")
+	}
+	for _, d := range []Decision{{Symbol: "BTCUSDT", Action: "close_long"}, {Symbol: "ETHUSDT", Action: "hold"}} {
+		if err := reviewer.ReviewDecision(&d); err != nil {
+			t.Errorf("ReviewDecision(%s %s) = %v, want accepted", d.Action, d.Symbol, err)
+		}
+	}
+	for _, d := range []Decision{{Symbol: "BTCUSDT", Action: "open_long"}, {Symbol: "BTCUSDT", Action: "close_short"}, {Symbol: "ETHUSDT", Action: "close_long"}} {
+		if err := reviewer.ReviewDecision(&d); err == nil {
+			t.Errorf("ReviewDecision(%s %s) should be rejected", d.Action, d.Symbol)
+		}
+	}
+}
+
+func TestStrategyValidation(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	if err := ValidateStrategy(&cfg); err != nil {
+		t.Errorf("default config: %v", err)
+	}
+	cfg.StrategyType = "pairs_trading"
+	if err := ValidateStrategy(&cfg); err == nil {
+		t.Error("pairs_trading without pairs_config should fail")
+	}
+	cfg.PairsConfig = &store.PairsStrategyConfig{SymbolA: "ETHUSDT", SymbolB: "BTCUSDT"}
+	if symbols, timeframes := StrategyMarkets(&cfg); len(symbols) != 2 || len(timeframes) != 1 || timeframes[0] != "1h" {
+		t.Errorf("StrategyMarkets() = %v, %v, want both legs on the default 1h timeframe", symbols, timeframes)
+	}
+	cfg.StrategyType = "unknown"
+	if err := ValidateStrategy(&cfg); err == nil {
+		t.Error("unknown strategy type should fail")
+	}
+
+	cfg.StrategyType = "funding_carry"
+	if err := CheckBacktest(&cfg); err == nil {
+		t.Error("funding_carry should not be backtestable")
+	}
+	cfg.StrategyType = "rebalance"
+	cfg.RebalanceConfig = &store.RebalanceStrategyConfig{AITargets: true}
+	if err := CheckBacktest(&cfg); err == nil {
+		t.Error("rebalance with ai_targets should not be backtestable")
+	}
+	cfg.RebalanceConfig.AITargets = false
+	if err := CheckBacktest(&cfg); err != nil {
+		t.Errorf("rebalance with fixed targets: %v", err)
+	}
+}
+
+func TestDCAStrategyWaitsForFills(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "dca"
+	cfg.DCAConfig = &store.DCAStrategyConfig{Symbol: "BTCUSDT", Leverage: 2, BaseOrderUSD: 100, SafetyOrderUSD: 100,
+		MaxSafetyOrders: 2, PriceDeviationPct: 2, TakeProfitPct: 5}
+	s, err := NewStrategy(StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatalf("NewStrategy() error: %v", err)
+	}
+	dca := s.(*dcaStrategy)
+	cycle := func(price float64, positions ...PositionInfo) []Decision {
+		t.Helper()
+		fd, err := dca.DecideDetailed(&Context{
+			CurrentTime:   "2026-01-01 00:00:00 UTC",
+			Positions:     positions,
+			MarketDataMap: map[string]*market.Data{"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: price}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fd.Decisions
+	}
+
+	cycle(100)
+	// The base order did not fill: no deal, the base order is sent again
+	if d := cycle(100); dca.deal != nil || len(d) != 1 || d[0].Action != "open_long" {
+		t.Fatalf("the unfilled base order should be retried, deal %+v, decisions %+v", dca.deal, d)
+	}
+	if d := cycle(97, PositionInfo{Symbol: "BTCUSDT", Side: "long", Quantity: 1, EntryPrice: 100}); len(d) != 1 || d[0].LevelIndex != 0 {
+		t.Fatalf("expected safety order #1, got %+v", d)
+	}
+	// The safety order did not fill: it stays planned and is sent again
+	if cycle(97, PositionInfo{Symbol: "BTCUSDT", Side: "long", Quantity: 1, EntryPrice: 100}); dca.deal.SafetyOrders[0].State != "planned" {
+		t.Fatalf("an unfilled safety order should stay planned: %+v", dca.deal.SafetyOrders[0])
+	}
+	cycle(97, PositionInfo{Symbol: "BTCUSDT", Side: "long", Quantity: 2, EntryPrice: 98.5})
+	if so := dca.deal.SafetyOrders[0]; so.State != "filled" || so.FilledPrice != 97 {
+		t.Errorf("the safety order should be filled at 97 once the position grew: %+v", so)
+	}
+}
@@ -648,6 +648,12 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 		return fmt.Errorf("trader %s has no strategy configured", traderCfg.Name)
 	}
 
+	// Load copy trading configuration (followers mirror the leader instead of running the strategy)
+	followConfig, err := traderCfg.ParseFollowConfig()
+	if err != nil {
+		return fmt.Errorf("trader %s: %w", traderCfg.Name, err)
+	}
+
 	// Build AutoTraderConfig (ai500APIURL/oiTopAPIURL obtained from strategy config, used in StrategyEngine)
 	traderConfig := trader.AutoTraderConfig{
 		ID:                    traderCfg.ID,
@@ -669,48 +675,15 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 		IsCrossMargin:        traderCfg.IsCrossMargin,
 		ShowInCompetition:    traderCfg.ShowInCompetition,
 		StrategyConfig:       strategyConfig,
+		LeaderTraderID:       traderCfg.LeaderTraderID,
+		FollowConfig:         followConfig,
 	}
 
 	logger.Infof("📊 Loading trader %s: ScanIntervalMinutes=%d (from DB), ScanInterval=%v",
 		traderCfg.Name, traderCfg.ScanIntervalMinutes, traderConfig.ScanInterval)
 
-	// Set API keys based on exchange type (convert EncryptedString to string)
-	switch exchangeCfg.ExchangeType {
-	case "binance":
-		traderConfig.BinanceAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BinanceSecretKey = string(exchangeCfg.SecretKey)
-	case "bybit":
-		traderConfig.BybitAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BybitSecretKey = string(exchangeCfg.SecretKey)
-	case "okx":
-		traderConfig.OKXAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.OKXSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.OKXPassphrase = string(exchangeCfg.Passphrase)
-	case "bitget":
-		traderConfig.BitgetAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.BitgetSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.BitgetPassphrase = string(exchangeCfg.Passphrase)
-	case "gate":
-		traderConfig.GateAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.GateSecretKey = string(exchangeCfg.SecretKey)
-	case "kucoin":
-		traderConfig.KuCoinAPIKey = string(exchangeCfg.APIKey)
-		traderConfig.KuCoinSecretKey = string(exchangeCfg.SecretKey)
-		traderConfig.KuCoinPassphrase = string(exchangeCfg.Passphrase)
-	case "hyperliquid":
-		traderConfig.HyperliquidPrivateKey = string(exchangeCfg.APIKey)
-		traderConfig.HyperliquidWalletAddr = exchangeCfg.HyperliquidWalletAddr
-	case "aster":
-		traderConfig.AsterUser = exchangeCfg.AsterUser
-		traderConfig.AsterSigner = exchangeCfg.AsterSigner
-		traderConfig.AsterPrivateKey = string(exchangeCfg.AsterPrivateKey)
-	case "lighter":
-		traderConfig.LighterPrivateKey = string(exchangeCfg.LighterPrivateKey)
-		traderConfig.LighterWalletAddr = exchangeCfg.LighterWalletAddr
-		traderConfig.LighterAPIKeyPrivateKey = string(exchangeCfg.LighterAPIKeyPrivateKey)
-		traderConfig.LighterAPIKeyIndex = exchangeCfg.LighterAPIKeyIndex
-		traderConfig.LighterTestnet = exchangeCfg.Testnet
-	}
+	// Set API keys based on exchange type
+	trader.SetExchangeCredentials(&traderConfig, exchangeCfg)
 
 	// Set API keys based on AI model (convert EncryptedString to string)
 	switch aiModelCfg.Provider {
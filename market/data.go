@@ -1289,6 +1289,11 @@ func BuildDataFromKlines(symbol string, primary []Kline, longer []Kline) (*Data,
 	return data, nil
 }
 
+// BuildTimeframeSeries constructs the indicator series of one timeframe from preloaded K-lines (for backtesting/simulation).
+func BuildTimeframeSeries(klines []Kline, timeframe string, count int) *TimeframeSeriesData {
+	return calculateTimeframeSeries(klines, timeframe, count)
+}
+
 func priceChangeFromSeries(series []Kline, duration time.Duration) float64 {
 	if len(series) == 0 || duration <= 0 {
 		return 0
@@ -0,0 +1,282 @@
+package market
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VenueFundingRate current funding rate of a perpetual contract on one exchange
+type VenueFundingRate struct {
+	Exchange        string    `json:"exchange"`
+	Symbol          string    `json:"symbol"`
+	Rate            float64   `json:"rate"`           // Funding rate per interval (0.0001 = 0.01%)
+	IntervalHours   float64   `json:"interval_hours"` // Funding interval
+	NextFundingTime time.Time `json:"next_funding_time,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AnnualizedPct returns the funding rate annualized in percent (paid by longs when positive)
+func (f *VenueFundingRate) AnnualizedPct() float64 {
+	if f.IntervalHours <= 0 {
+		return 0
+	}
+	return f.Rate * (24 / f.IntervalHours) * 365 * 100
+}
+
+var (
+	venueFundingMap sync.Map // map["exchange:symbol"]*VenueFundingRate
+	venueFundingTTL = 5 * time.Minute
+)
+
+// FundingRateExchanges exchanges supported by GetVenueFundingRate
+func FundingRateExchanges() []string {
+	return []string{"binance", "bybit", "okx", "bitget", "gate", "kucoin", "hyperliquid", "aster"}
+}
+
+// GetVenueFundingRate retrieves the current funding rate of symbol (e.g. "BTCUSDT") on an exchange
+// from its public API (cached for 5 minutes)
+func GetVenueFundingRate(exchange, symbol string) (*VenueFundingRate, error) {
+	symbol = Normalize(symbol)
+	key := exchange + ":" + symbol
+	if cached, ok := venueFundingMap.Load(key); ok {
+		fr := cached.(*VenueFundingRate)
+		if time.Since(fr.UpdatedAt) < venueFundingTTL {
+			return fr, nil
+		}
+	}
+
+	var fr *VenueFundingRate
+	var err error
+	switch exchange {
+	case "binance":
+		fr, err = fetchBinanceStyleFunding("https://fapi.binance.com", symbol)
+	case "aster":
+		fr, err = fetchBinanceStyleFunding("https://fapi.asterdex.com", symbol)
+	case "bybit":
+		fr, err = fetchBybitFunding(symbol)
+	case "okx":
+		fr, err = fetchOKXFunding(symbol)
+	case "bitget":
+		fr, err = fetchBitgetFunding(symbol)
+	case "gate":
+		fr, err = fetchGateFunding(symbol)
+	case "kucoin":
+		fr, err = fetchKuCoinFunding(symbol)
+	case "hyperliquid":
+		fr, err = fetchHyperliquidFunding(symbol)
+	default:
+		return nil, fmt.Errorf("funding rate not supported for exchange %s", exchange)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s funding rate for %s: %w", exchange, symbol, err)
+	}
+
+	fr.Exchange = exchange
+	fr.Symbol = symbol
+	fr.UpdatedAt = time.Now()
+	if fr.IntervalHours <= 0 {
+		fr.IntervalHours = 8
+	}
+	venueFundingMap.Store(key, fr)
+	return fr, nil
+}
+
+// baseAsset returns the base asset of a USDT symbol ("BTCUSDT" -> "BTC")
+func baseAsset(symbol string) string {
+	return strings.TrimSuffix(symbol, "USDT")
+}
+
+// getJSON performs a GET request and decodes the JSON response
+func getJSON(url string, out interface{}) error {
+	resp, err := NewAPIClient().client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// parseRate parses a numeric string field (0 when empty or invalid)
+func parseRate(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func fetchBinanceStyleFunding(baseURL, symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := getJSON(fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", baseURL, symbol), &result); err != nil {
+		return nil, err
+	}
+	return &VenueFundingRate{
+		Rate:            parseRate(result.LastFundingRate),
+		IntervalHours:   8,
+		NextFundingTime: time.UnixMilli(result.NextFundingTime),
+	}, nil
+}
+
+func fetchBybitFunding(symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Result struct {
+			List []struct {
+				FundingRate         string `json:"fundingRate"`
+				NextFundingTime     string `json:"nextFundingTime"`
+				FundingIntervalHour string `json:"fundingIntervalHour"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := getJSON("https://api.bybit.com/v5/market/tickers?category=linear&symbol="+symbol, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Result.List) == 0 {
+		return nil, fmt.Errorf("symbol not found")
+	}
+	t := result.Result.List[0]
+	next, _ := strconv.ParseInt(t.NextFundingTime, 10, 64)
+	return &VenueFundingRate{
+		Rate:            parseRate(t.FundingRate),
+		IntervalHours:   parseRate(t.FundingIntervalHour),
+		NextFundingTime: time.UnixMilli(next),
+	}, nil
+}
+
+func fetchOKXFunding(symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Data []struct {
+			FundingRate     string `json:"fundingRate"`
+			FundingTime     string `json:"fundingTime"`
+			NextFundingTime string `json:"nextFundingTime"`
+		} `json:"data"`
+	}
+	instID := baseAsset(symbol) + "-USDT-SWAP"
+	if err := getJSON("https://www.okx.com/api/v5/public/funding-rate?instId="+instID, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("symbol not found")
+	}
+	d := result.Data[0]
+	current, _ := strconv.ParseInt(d.FundingTime, 10, 64)
+	next, _ := strconv.ParseInt(d.NextFundingTime, 10, 64)
+	fr := &VenueFundingRate{Rate: parseRate(d.FundingRate), NextFundingTime: time.UnixMilli(current)}
+	if next > current {
+		fr.IntervalHours = float64(next-current) / float64(time.Hour/time.Millisecond)
+	}
+	return fr, nil
+}
+
+func fetchBitgetFunding(symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		Data []struct {
+			FundingRate         string `json:"fundingRate"`
+			FundingRateInterval string `json:"fundingRateInterval"`
+			NextUpdate          string `json:"nextUpdate"`
+		} `json:"data"`
+	}
+	url := "https://api.bitget.com/api/v2/mix/market/current-fund-rate?productType=usdt-futures&symbol=" + symbol
+	if err := getJSON(url, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("symbol not found")
+	}
+	d := result.Data[0]
+	next, _ := strconv.ParseInt(d.NextUpdate, 10, 64)
+	return &VenueFundingRate{
+		Rate:            parseRate(d.FundingRate),
+		IntervalHours:   parseRate(d.FundingRateInterval),
+		NextFundingTime: time.UnixMilli(next),
+	}, nil
+}
+
+func fetchGateFunding(symbol string) (*VenueFundingRate, error) {
+	var result struct {
+		FundingRate     string `json:"funding_rate"`
+		FundingInterval int64  `json:"funding_interval"` // seconds
+		FundingNextTime int64  `json:"funding_next_apply"`
+	}
+	if err := getJSON("https://api.gateio.ws/api/v4/futures/usdt/contracts/"+baseAsset(symbol)+"_USDT", &result); err != nil {
+		return nil, err
+	}
+	return &VenueFundingRate{
+		Rate:            parseRate(result.FundingRate),
+		IntervalHours:   float64(result.FundingInterval) / 3600,
+		NextFundingTime: time.Unix(result.FundingNextTime, 0),
+	}, nil
+}
+
+func fetchKuCoinFunding(symbol string) (*VenueFundingRate, error) {
+	base := baseAsset(symbol)
+	if base == "BTC" {
+		base = "XBT"
+	}
+	var result struct {
+		Data struct {
+			Value       float64 `json:"value"`
+			Granularity int64   `json:"granularity"` // milliseconds
+			TimePoint   int64   `json:"timePoint"`
+		} `json:"data"`
+	}
+	if err := getJSON("https://api-futures.kucoin.com/api/v1/funding-rate/"+base+"USDTM/current", &result); err != nil {
+		return nil, err
+	}
+	return &VenueFundingRate{
+		Rate:          result.Data.Value,
+		IntervalHours: float64(result.Data.Granularity) / float64(time.Hour/time.Millisecond),
+	}, nil
+}
+
+func fetchHyperliquidFunding(symbol string) (*VenueFundingRate, error) {
+	payload, _ := json.Marshal(map[string]string{"type": "metaAndAssetCtxs"})
+	resp, err := NewAPIClient().client.Post("https://api.hyperliquid.xyz/info", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if len(result) < 2 {
+		return nil, fmt.Errorf("unexpected response")
+	}
+	var meta struct {
+		Universe []struct {
+			Name string `json:"name"`
+		} `json:"universe"`
+	}
+	var ctxs []struct {
+		Funding string `json:"funding"`
+	}
+	if err := json.Unmarshal(result[0], &meta); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(result[1], &ctxs); err != nil {
+		return nil, err
+	}
+
+	coin := baseAsset(symbol)
+	for i, asset := range meta.Universe {
+		if asset.Name == coin && i < len(ctxs) {
+			// Hyperliquid pays funding every hour
+			return &VenueFundingRate{Rate: parseRate(ctxs[i].Funding), IntervalHours: 1}, nil
+		}
+	}
+	return nil, fmt.Errorf("symbol not found")
+}
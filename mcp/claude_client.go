@@ -92,6 +92,49 @@ func (c *ClaudeClient) buildMCPRequestBody(systemPrompt, userPrompt string) map[
 	return requestBody
 }
 
+// buildRequestBodyFromRequest Claude takes the system prompt as a top-level field
+// and encodes images as base64 source blocks
+func (c *ClaudeClient) buildRequestBodyFromRequest(req *Request) map[string]any {
+	var systemPrompt string
+	messages := make([]map[string]any, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			if systemPrompt != "" {
+				systemPrompt += "\n\n"
+			}
+			systemPrompt += msg.Content
+			continue
+		}
+		messages = append(messages, map[string]any{
+			"role":    msg.Role,
+			"content": claudeMessageContent(msg),
+		})
+	}
+
+	requestBody := map[string]any{
+		"model":      req.Model,
+		"max_tokens": c.MaxTokens,
+		"messages":   messages,
+	}
+	if systemPrompt != "" {
+		requestBody["system"] = systemPrompt
+	}
+	if req.MaxTokens != nil {
+		requestBody["max_tokens"] = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		requestBody["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		requestBody["top_p"] = *req.TopP
+	}
+	if len(req.Stop) > 0 {
+		requestBody["stop_sequences"] = req.Stop
+	}
+
+	return requestBody
+}
+
 // parseMCPResponse Claude has different response format
 func (c *ClaudeClient) parseMCPResponse(body []byte) (string, error) {
 	var response struct {
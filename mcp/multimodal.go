@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// ============================================================
+// Multimodal content encoding (per provider)
+// ============================================================
+
+// HasImages reports whether the message carries image parts
+func (m Message) HasImages() bool {
+	for _, part := range m.Parts {
+		if part.Type == ContentTypeImage {
+			return true
+		}
+	}
+	return false
+}
+
+// dataURL encodes an image part as a base64 data URL
+func (p ContentPart) dataURL() string {
+	return fmt.Sprintf("data:%s;base64,%s", p.MediaType, base64.StdEncoding.EncodeToString(p.Data))
+}
+
+// openAIMessageContent encodes message content in OpenAI-compatible format
+// (OpenAI, Gemini, Qwen-VL and other compatible endpoints)
+// Text-only messages stay a plain string for compatibility with text-only models
+func openAIMessageContent(msg Message) any {
+	if len(msg.Parts) == 0 {
+		return msg.Content
+	}
+
+	parts := make([]map[string]any, 0, len(msg.Parts)+1)
+	if msg.Content != "" {
+		parts = append(parts, map[string]any{"type": "text", "text": msg.Content})
+	}
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case ContentTypeImage:
+			parts = append(parts, map[string]any{
+				"type":      "image_url",
+				"image_url": map[string]any{"url": part.dataURL()},
+			})
+		default:
+			parts = append(parts, map[string]any{"type": "text", "text": part.Text})
+		}
+	}
+	return parts
+}
+
+// claudeMessageContent encodes message content in Anthropic Messages API format
+func claudeMessageContent(msg Message) any {
+	if len(msg.Parts) == 0 {
+		return msg.Content
+	}
+
+	parts := make([]map[string]any, 0, len(msg.Parts)+1)
+	if msg.Content != "" {
+		parts = append(parts, map[string]any{"type": "text", "text": msg.Content})
+	}
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case ContentTypeImage:
+			parts = append(parts, map[string]any{
+				"type": "image",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": part.MediaType,
+					"data":       base64.StdEncoding.EncodeToString(part.Data),
+				},
+			})
+		default:
+			parts = append(parts, map[string]any{"type": "text", "text": part.Text})
+		}
+	}
+	return parts
+}
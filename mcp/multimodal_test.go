@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestClient_CallWithRequest_Images(t *testing.T) {
+	mockHTTP := NewMockHTTPClient()
+	mockHTTP.SetSuccessResponse("Image response")
+
+	client := NewClient(
+		WithHTTPClient(mockHTTP.ToHTTPClient()),
+		WithLogger(NewMockLogger()),
+		WithAPIKey("sk-test-key"),
+	)
+
+	request := NewRequestBuilder().
+		WithSystemPrompt("You are a chart analyst").
+		AddUserMessageWithImages("Analyze this chart", NewImagePart("image/png", []byte{0x89, 'P', 'N', 'G'})).
+		MustBuild()
+
+	if _, err := client.CallWithRequest(request); err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+
+	var body struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(mockHTTP.GetRequests()[0].Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if len(body.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(body.Messages))
+	}
+
+	// System message stays a plain string
+	var system string
+	if err := json.Unmarshal(body.Messages[0].Content, &system); err != nil || system != "You are a chart analyst" {
+		t.Errorf("system content = %s, want plain string", body.Messages[0].Content)
+	}
+
+	var parts []map[string]any
+	if err := json.Unmarshal(body.Messages[1].Content, &parts); err != nil {
+		t.Fatalf("user content should be a part array: %v", err)
+	}
+	if len(parts) != 2 || parts[0]["type"] != "text" || parts[1]["type"] != "image_url" {
+		t.Fatalf("unexpected parts: %v", parts)
+	}
+	url, _ := parts[1]["image_url"].(map[string]any)["url"].(string)
+	if !strings.HasPrefix(url, "data:image/png;base64,") {
+		t.Errorf("image url = %q, want data URL", url)
+	}
+}
+
+func TestClaudeClient_BuildRequestBodyFromRequest_Images(t *testing.T) {
+	client := NewClaudeClientWithOptions(WithLogger(NewMockLogger())).(*ClaudeClient)
+
+	request := NewRequestBuilder().
+		WithSystemPrompt("You are a chart analyst").
+		AddUserMessageWithImages("Analyze this chart", NewImagePart("image/png", []byte("png"))).
+		MustBuild()
+
+	body := client.buildRequestBodyFromRequest(request)
+	if body["system"] != "You are a chart analyst" {
+		t.Errorf("system = %v, want top-level system prompt", body["system"])
+	}
+
+	messages := body["messages"].([]map[string]any)
+	if len(messages) != 1 || messages[0]["role"] != "user" {
+		t.Fatalf("unexpected messages: %v", messages)
+	}
+	parts := messages[0]["content"].([]map[string]any)
+	if len(parts) != 2 || parts[1]["type"] != "image" {
+		t.Fatalf("unexpected content parts: %v", parts)
+	}
+	source := parts[1]["source"].(map[string]any)
+	if source["type"] != "base64" || source["media_type"] != "image/png" || source["data"] != "cG5n" {
+		t.Errorf("unexpected image source: %v", source)
+	}
+}
@@ -1,9 +1,25 @@
 package mcp
 
+// Content part types
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+)
+
 // Message represents a conversation message
 type Message struct {
-	Role    string `json:"role"`    // "system", "user", "assistant"
-	Content string `json:"content"` // Message content
+	Role    string        `json:"role"`            // "system", "user", "assistant"
+	Content string        `json:"content"`         // Message content
+	Parts   []ContentPart `json:"parts,omitempty"` // Extra content parts (e.g. images), sent after Content
+}
+
+// ContentPart a multimodal content part (text or image)
+// Images are kept as raw bytes and base64-encoded per provider when the request is built
+type ContentPart struct {
+	Type      string `json:"type"`                 // "text" or "image"
+	Text      string `json:"text,omitempty"`       // Text content
+	MediaType string `json:"media_type,omitempty"` // Image MIME type, e.g. "image/png"
+	Data      []byte `json:"data,omitempty"`       // Raw image bytes
 }
 
 // Tool represents a tool/function that AI can call
@@ -70,3 +86,29 @@ func NewAssistantMessage(content string) Message {
 		Content: content,
 	}
 }
+
+// NewUserMessageWithImages creates a user message with image parts
+func NewUserMessageWithImages(content string, images ...ContentPart) Message {
+	return Message{
+		Role:    "user",
+		Content: content,
+		Parts:   images,
+	}
+}
+
+// NewTextPart creates a text content part
+func NewTextPart(text string) ContentPart {
+	return ContentPart{
+		Type: ContentTypeText,
+		Text: text,
+	}
+}
+
+// NewImagePart creates an image content part
+func NewImagePart(mediaType string, data []byte) ContentPart {
+	return ContentPart{
+		Type:      ContentTypeImage,
+		MediaType: mediaType,
+		Data:      data,
+	}
+}
@@ -80,6 +80,14 @@ func (b *RequestBuilder) AddUserMessage(content string) *RequestBuilder {
 	return b.WithUserPrompt(content)
 }
 
+// AddUserMessageWithImages adds user message with attached images (for multimodal models)
+func (b *RequestBuilder) AddUserMessageWithImages(content string, images ...ContentPart) *RequestBuilder {
+	if content != "" || len(images) > 0 {
+		b.messages = append(b.messages, NewUserMessageWithImages(content, images...))
+	}
+	return b
+}
+
 // AddAssistantMessage adds assistant message (for multi-turn conversation context)
 func (b *RequestBuilder) AddAssistantMessage(content string) *RequestBuilder {
 	if content != "" {
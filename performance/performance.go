@@ -0,0 +1,413 @@
+// Package performance is the metric engine shared by backtests and live traders, so both are
+// measured from the same equity curve, trade list and benchmark with the same formulas.
+package performance
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Annualization and sample size conventions shared by every ratio
+const (
+	PeriodsPerYear = 252.0 // Each equity point is treated as one trading period
+	MinDataPoints  = 10    // Fewer equity points leave the ratios at 0
+	CalmarMonths   = 36    // Months of history used by the Calmar ratio
+)
+
+// BenchmarkSymbol is the buy-and-hold benchmark alpha and beta are measured against
+const BenchmarkSymbol = "BTCUSDT"
+
+// EquityPoint one account equity reading
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// Trade one position; ClosedAt is zero while it is still open
+type Trade struct {
+	OpenedAt time.Time
+	ClosedAt time.Time
+}
+
+// PricePoint one benchmark price
+type PricePoint struct {
+	Time  time.Time
+	Price float64
+}
+
+// Input everything the metrics are computed from
+type Input struct {
+	InitialEquity float64       // Equity before the first point (0 = equity of the first point)
+	Equity        []EquityPoint // Ascending by time
+	Trades        []Trade
+	Benchmark     []PricePoint // Buy-and-hold benchmark over the same window (optional)
+}
+
+// MonthlyReturn return of one UTC calendar month
+type MonthlyReturn struct {
+	Month       string  `json:"month"` // YYYY-MM
+	StartEquity float64 `json:"start_equity"`
+	EndEquity   float64 `json:"end_equity"`
+	ReturnPct   float64 `json:"return_pct"`
+}
+
+// Report performance metrics of one equity curve
+type Report struct {
+	Start              time.Time       `json:"start"`
+	End                time.Time       `json:"end"`
+	Days               float64         `json:"days"`
+	TotalReturnPct     float64         `json:"total_return_pct"`
+	AnnualReturnPct    float64         `json:"annual_return_pct"` // Compound annual growth rate
+	MaxDrawdownPct     float64         `json:"max_drawdown_pct"`
+	SharpeRatio        float64         `json:"sharpe_ratio"`
+	SortinoRatio       float64         `json:"sortino_ratio"`
+	CalmarRatio        float64         `json:"calmar_ratio"` // Annual return / max drawdown over the last 36 months
+	MARRatio           float64         `json:"mar_ratio"`    // Annual return / max drawdown over the whole history
+	UlcerIndex         float64         `json:"ulcer_index"`
+	ExposurePct        float64         `json:"exposure_pct"` // Share of the window with at least one open position
+	AvgHoldingHours    float64         `json:"avg_holding_hours"`
+	Benchmark          string          `json:"benchmark,omitempty"`
+	BenchmarkReturnPct float64         `json:"benchmark_return_pct"`
+	Alpha              float64         `json:"alpha"` // Return above beta × benchmark return over the window (%)
+	Beta               float64         `json:"beta"`
+	MonthlyReturns     []MonthlyReturn `json:"monthly_returns"`
+}
+
+// Compute calculates the performance report of an equity curve, its trades and the benchmark
+func Compute(in Input) *Report {
+	report := &Report{MonthlyReturns: []MonthlyReturn{}}
+	points := in.Equity
+	if len(points) == 0 {
+		return report
+	}
+
+	initial := in.InitialEquity
+	if initial <= 0 {
+		initial = points[0].Equity
+	}
+	report.Start = points[0].Time
+	report.End = points[len(points)-1].Time
+	report.Days = report.End.Sub(report.Start).Hours() / 24
+
+	last := points[len(points)-1].Equity
+	if initial > 0 {
+		report.TotalReturnPct = (last - initial) / initial * 100
+	}
+	report.AnnualReturnPct = annualReturnPct(initial, last, report.Days)
+	report.MaxDrawdownPct = MaxDrawdownPct(points, initial)
+	report.UlcerIndex = UlcerIndex(points, initial)
+
+	returns := Returns(points)
+	report.SharpeRatio = SharpeRatio(returns)
+	report.SortinoRatio = SortinoRatio(returns)
+	if report.MaxDrawdownPct > 0 {
+		report.MARRatio = report.AnnualReturnPct / report.MaxDrawdownPct
+	}
+	report.CalmarRatio = calmarRatio(points, initial)
+
+	report.ExposurePct = ExposurePct(in.Trades, report.Start, report.End)
+	report.AvgHoldingHours = AvgHoldingHours(in.Trades)
+	report.MonthlyReturns = MonthlyReturns(points, initial)
+
+	if len(in.Benchmark) > 0 {
+		report.Benchmark = BenchmarkSymbol
+		benchmark := alignBenchmark(points, in.Benchmark)
+		if first, end := firstPositive(benchmark), benchmark[len(benchmark)-1]; first > 0 && end > 0 {
+			report.BenchmarkReturnPct = (end - first) / first * 100
+		}
+		report.Beta = beta(points, benchmark)
+		if report.Beta != 0 {
+			report.Alpha = report.TotalReturnPct - report.Beta*report.BenchmarkReturnPct
+		}
+	}
+	return report
+}
+
+// Returns the period returns between consecutive equity points (points without equity are skipped)
+func Returns(points []EquityPoint) []float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	returns := make([]float64, 0, len(points)-1)
+	prev := points[0].Equity
+	for i := 1; i < len(points); i++ {
+		curr := points[i].Equity
+		if prev <= 0 {
+			prev = curr
+			continue
+		}
+		returns = append(returns, (curr-prev)/prev)
+		prev = curr
+	}
+	return returns
+}
+
+// SharpeRatio annualized mean / sample standard deviation of the period returns (risk-free rate 0)
+func SharpeRatio(returns []float64) float64 {
+	if len(returns) < MinDataPoints-1 {
+		return 0
+	}
+	return meanOverStd(returns) * math.Sqrt(PeriodsPerYear)
+}
+
+// TradeSharpeRatio mean / sample standard deviation of per-trade returns; trades are not evenly
+// spaced in time, so the ratio is not annualized (0 below two trades)
+func TradeSharpeRatio(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	return meanOverStd(returns)
+}
+
+// meanOverStd mean / sample standard deviation of at least two returns
+func meanOverStd(returns []float64) float64 {
+	m := mean(returns)
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - m) * (r - m)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std < 1e-10 {
+		// Zero or near-zero volatility - return 0 instead of infinity/NaN
+		return 0
+	}
+	return m / std
+}
+
+// SortinoRatio annualized mean / downside deviation of the period returns (target 0)
+func SortinoRatio(returns []float64) float64 {
+	if len(returns) < MinDataPoints-1 {
+		return 0
+	}
+	downside := 0.0
+	for _, r := range returns {
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	dd := math.Sqrt(downside / float64(len(returns)))
+	if dd < 1e-10 {
+		return 0
+	}
+	return mean(returns) / dd * math.Sqrt(PeriodsPerYear)
+}
+
+// MaxDrawdownPct largest peak-to-trough decline of the equity curve (%), starting from initial
+func MaxDrawdownPct(points []EquityPoint, initial float64) float64 {
+	maxDD := 0.0
+	for _, dd := range drawdowns(points, initial) {
+		if dd > maxDD {
+			maxDD = dd
+		}
+	}
+	return maxDD
+}
+
+// UlcerIndex root mean square of the drawdowns (%) of the equity curve
+func UlcerIndex(points []EquityPoint, initial float64) float64 {
+	dds := drawdowns(points, initial)
+	if len(dds) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, dd := range dds {
+		sum += dd * dd
+	}
+	return math.Sqrt(sum / float64(len(dds)))
+}
+
+// ExposurePct share of start → end with at least one open trade (%); open trades count until end
+func ExposurePct(trades []Trade, start, end time.Time) float64 {
+	window := end.Sub(start)
+	if window <= 0 {
+		return 0
+	}
+	type span struct{ from, to time.Time }
+	spans := make([]span, 0, len(trades))
+	for _, t := range trades {
+		if t.OpenedAt.IsZero() {
+			continue
+		}
+		from, to := t.OpenedAt, t.ClosedAt
+		if to.IsZero() || to.After(end) {
+			to = end
+		}
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(from) {
+			spans = append(spans, span{from, to})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].from.Before(spans[j].from) })
+
+	var exposed time.Duration
+	var cursor time.Time
+	for _, s := range spans {
+		if s.from.Before(cursor) {
+			s.from = cursor
+		}
+		if s.to.After(s.from) {
+			exposed += s.to.Sub(s.from)
+			cursor = s.to
+		}
+	}
+	return float64(exposed) / float64(window) * 100
+}
+
+// AvgHoldingHours average time between opening and closing of the closed trades
+func AvgHoldingHours(trades []Trade) float64 {
+	total, count := 0.0, 0
+	for _, t := range trades {
+		if t.OpenedAt.IsZero() || t.ClosedAt.IsZero() || t.ClosedAt.Before(t.OpenedAt) {
+			continue
+		}
+		total += t.ClosedAt.Sub(t.OpenedAt).Hours()
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// MonthlyReturns return of every UTC month from its opening equity (previous month close) to its last point
+func MonthlyReturns(points []EquityPoint, initial float64) []MonthlyReturn {
+	months := []MonthlyReturn{}
+	if len(points) == 0 {
+		return months
+	}
+	open := initial
+	if open <= 0 {
+		open = points[0].Equity
+	}
+	for _, pt := range points {
+		month := pt.Time.UTC().Format("2006-01")
+		if len(months) == 0 || months[len(months)-1].Month != month {
+			if len(months) > 0 {
+				open = months[len(months)-1].EndEquity
+			}
+			months = append(months, MonthlyReturn{Month: month, StartEquity: open})
+		}
+		months[len(months)-1].EndEquity = pt.Equity
+	}
+	for i := range months {
+		if m := &months[i]; m.StartEquity > 0 {
+			m.ReturnPct = (m.EndEquity - m.StartEquity) / m.StartEquity * 100
+		}
+	}
+	return months
+}
+
+// drawdowns the drawdown (%) below the running peak at every equity point
+func drawdowns(points []EquityPoint, initial float64) []float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	peak := initial
+	if peak <= 0 {
+		peak = points[0].Equity
+	}
+	dds := make([]float64, 0, len(points))
+	for _, pt := range points {
+		if pt.Equity > peak {
+			peak = pt.Equity
+		}
+		if peak <= 0 {
+			continue
+		}
+		dds = append(dds, (peak-pt.Equity)/peak*100)
+	}
+	return dds
+}
+
+// annualReturnPct compound annual growth rate (%) from initial to last over days
+func annualReturnPct(initial, last, days float64) float64 {
+	if initial <= 0 || days <= 0 {
+		return 0
+	}
+	if last <= 0 {
+		return -100
+	}
+	return (math.Pow(last/initial, 365/days) - 1) * 100
+}
+
+// calmarRatio annual return / max drawdown of the last 36 months of the equity curve
+func calmarRatio(points []EquityPoint, initial float64) float64 {
+	last := points[len(points)-1]
+	from := last.Time.AddDate(0, -CalmarMonths, 0)
+	start, startEquity := points[0].Time, initial
+	if startEquity <= 0 {
+		startEquity = points[0].Equity
+	}
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(from) })
+	if i > 0 {
+		start, startEquity = points[i-1].Time, points[i-1].Equity
+	}
+	maxDD := MaxDrawdownPct(points[i:], startEquity)
+	if maxDD <= 0 {
+		return 0
+	}
+	return annualReturnPct(startEquity, last.Equity, last.Time.Sub(start).Hours()/24) / maxDD
+}
+
+// alignBenchmark the latest benchmark price at or before every equity point (0 before the first price)
+func alignBenchmark(points []EquityPoint, benchmark []PricePoint) []float64 {
+	prices := make([]float64, len(points))
+	j := 0
+	last := 0.0
+	for i, pt := range points {
+		for j < len(benchmark) && !benchmark[j].Time.After(pt.Time) {
+			last = benchmark[j].Price
+			j++
+		}
+		prices[i] = last
+	}
+	return prices
+}
+
+// beta covariance of the equity and benchmark period returns over the benchmark variance
+func beta(points []EquityPoint, benchmark []float64) float64 {
+	var strat, bench []float64
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Equity <= 0 || benchmark[i-1] <= 0 || benchmark[i] <= 0 {
+			continue
+		}
+		strat = append(strat, (points[i].Equity-points[i-1].Equity)/points[i-1].Equity)
+		bench = append(bench, (benchmark[i]-benchmark[i-1])/benchmark[i-1])
+	}
+	if len(strat) < MinDataPoints-1 {
+		return 0
+	}
+	ms, mb := mean(strat), mean(bench)
+	cov, variance := 0.0, 0.0
+	for i := range strat {
+		cov += (strat[i] - ms) * (bench[i] - mb)
+		variance += (bench[i] - mb) * (bench[i] - mb)
+	}
+	if variance < 1e-18 {
+		return 0
+	}
+	return cov / variance
+}
+
+func firstPositive(values []float64) float64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,145 @@
+package performance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+
+// curve builds one equity point per day from t0
+func curve(equity ...float64) []EquityPoint {
+	points := make([]EquityPoint, len(equity))
+	for i, e := range equity {
+		points[i] = EquityPoint{Time: t0.AddDate(0, 0, i), Equity: e}
+	}
+	return points
+}
+
+func TestDrawdownMetrics(t *testing.T) {
+	points := curve(100, 120, 90, 110, 130)
+
+	// Peak 120 → trough 90
+	if dd := MaxDrawdownPct(points, 100); math.Abs(dd-25) > 1e-9 {
+		t.Errorf("max drawdown = %.4f, want 25", dd)
+	}
+	// Drawdowns 0, 0, 25, 8.33, 0
+	dd4 := 10.0 / 120 * 100
+	want := math.Sqrt((25*25 + dd4*dd4) / 5)
+	if ui := UlcerIndex(points, 100); math.Abs(ui-want) > 1e-9 {
+		t.Errorf("ulcer index = %.4f, want %.4f", ui, want)
+	}
+
+	report := Compute(Input{InitialEquity: 100, Equity: points})
+	if math.Abs(report.TotalReturnPct-30) > 1e-9 || report.Days != 4 {
+		t.Errorf("total return = %.4f over %.1f days, want 30 over 4", report.TotalReturnPct, report.Days)
+	}
+	annual := (math.Pow(1.3, 365.0/4) - 1) * 100
+	if math.Abs(report.AnnualReturnPct-annual)/annual > 1e-9 {
+		t.Errorf("annual return = %.4g, want %.4g", report.AnnualReturnPct, annual)
+	}
+	if math.Abs(report.MARRatio-annual/25)/(annual/25) > 1e-9 || report.CalmarRatio != report.MARRatio {
+		t.Errorf("MAR = %.4g, Calmar = %.4g, want both %.4g under 36 months", report.MARRatio, report.CalmarRatio, annual/25)
+	}
+}
+
+func TestReturnRatios(t *testing.T) {
+	returns := []float64{0.01, -0.02, 0.03, 0.01, -0.01, 0.02, 0.00, 0.01, -0.01, 0.02}
+	m := mean(returns)
+	variance, downside := 0.0, 0.0
+	for _, r := range returns {
+		variance += (r - m) * (r - m)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	sharpe := m / math.Sqrt(variance/9) * math.Sqrt(252)
+	sortino := m / math.Sqrt(downside/10) * math.Sqrt(252)
+	if got := SharpeRatio(returns); math.Abs(got-sharpe) > 1e-9 {
+		t.Errorf("sharpe = %.6f, want %.6f", got, sharpe)
+	}
+	if got := SortinoRatio(returns); math.Abs(got-sortino) > 1e-9 {
+		t.Errorf("sortino = %.6f, want %.6f", got, sortino)
+	}
+	if got := TradeSharpeRatio(returns); math.Abs(got-sharpe/math.Sqrt(252)) > 1e-9 {
+		t.Errorf("trade sharpe = %.6f, want %.6f unannualized", got, sharpe/math.Sqrt(252))
+	}
+	if TradeSharpeRatio(returns[:2]) == 0 || TradeSharpeRatio(returns[:1]) != 0 {
+		t.Error("trade sharpe needs two trades only")
+	}
+	if SharpeRatio(returns[:5]) != 0 || SortinoRatio(returns[:5]) != 0 {
+		t.Error("ratios of fewer than 10 equity points should be 0")
+	}
+	if SortinoRatio([]float64{0.01, 0.02, 0.01, 0.01, 0.02, 0.01, 0.01, 0.02, 0.01}) != 0 {
+		t.Error("sortino without losing periods should be 0")
+	}
+}
+
+func TestExposureAndHolding(t *testing.T) {
+	start, end := t0, t0.Add(10*time.Hour)
+	trades := []Trade{
+		{OpenedAt: t0.Add(-time.Hour), ClosedAt: t0.Add(2 * time.Hour)},    // Clipped to 2h
+		{OpenedAt: t0.Add(time.Hour), ClosedAt: t0.Add(3 * time.Hour)},     // Overlaps: +1h
+		{OpenedAt: t0.Add(5 * time.Hour), ClosedAt: t0.Add(6 * time.Hour)}, // +1h
+		{OpenedAt: t0.Add(8 * time.Hour)},                                  // Still open: +2h
+	}
+	if exp := ExposurePct(trades, start, end); math.Abs(exp-60) > 1e-9 {
+		t.Errorf("exposure = %.2f%%, want 60%%", exp)
+	}
+	// Closed trades hold 3h, 2h and 1h
+	if h := AvgHoldingHours(trades); math.Abs(h-2) > 1e-9 {
+		t.Errorf("average holding = %.2fh, want 2h", h)
+	}
+}
+
+func TestMonthlyReturns(t *testing.T) {
+	// Jan 30, Jan 31 | Feb 1 ... Feb 3
+	months := MonthlyReturns(curve(100, 110, 99, 120, 121), 100)
+	if len(months) != 2 {
+		t.Fatalf("got %d months, want 2", len(months))
+	}
+	if months[0].Month != "2026-01" || math.Abs(months[0].ReturnPct-10) > 1e-9 {
+		t.Errorf("unexpected January: %+v", months[0])
+	}
+	if months[1].Month != "2026-02" || months[1].StartEquity != 110 || math.Abs(months[1].ReturnPct-10) > 1e-9 {
+		t.Errorf("February should run from the January close: %+v", months[1])
+	}
+}
+
+func TestBenchmarkAlphaBeta(t *testing.T) {
+	// The strategy moves 2x the benchmark every day
+	bench := []float64{100}
+	moves := []float64{0.01, -0.02, 0.015, 0.01, -0.005, 0.02, -0.01, 0.005, 0.01, -0.01}
+	for _, m := range moves {
+		bench = append(bench, bench[len(bench)-1]*(1+m))
+	}
+	equity := []float64{1000}
+	for _, m := range moves {
+		equity = append(equity, equity[len(equity)-1]*(1+2*m))
+	}
+	points := curve(equity...)
+	prices := make([]PricePoint, len(bench))
+	for i, p := range bench {
+		prices[i] = PricePoint{Time: points[i].Time, Price: p}
+	}
+
+	report := Compute(Input{InitialEquity: 1000, Equity: points, Benchmark: prices})
+	if report.Benchmark != BenchmarkSymbol {
+		t.Errorf("benchmark = %q, want %s", report.Benchmark, BenchmarkSymbol)
+	}
+	benchReturn := (bench[len(bench)-1] - 100) / 100 * 100
+	if math.Abs(report.BenchmarkReturnPct-benchReturn) > 1e-9 {
+		t.Errorf("benchmark return = %.4f, want %.4f", report.BenchmarkReturnPct, benchReturn)
+	}
+	if math.Abs(report.Beta-2) > 1e-9 {
+		t.Errorf("beta = %.4f, want 2", report.Beta)
+	}
+	if alpha := report.TotalReturnPct - 2*benchReturn; math.Abs(report.Alpha-alpha) > 1e-9 {
+		t.Errorf("alpha = %.4f, want %.4f", report.Alpha, alpha)
+	}
+
+	if plain := Compute(Input{Equity: points}); plain.Benchmark != "" || plain.Beta != 0 || plain.Alpha != 0 {
+		t.Errorf("no benchmark should leave alpha and beta empty: %+v", plain)
+	}
+}
@@ -0,0 +1,475 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Expression language for rule-based strategies
+//
+//   ema20 > ema50 && rsi14_4h < 70
+//   close < boll_lower || pnl_pct <= -3
+//   equity * 0.1
+//   max(2 * atr14, price * 0.01)
+//
+// Operators (low → high precedence): ||, &&, comparisons (> >= < <= == !=),
+// + -, * /, unary ! and -. Booleans are numbers: true = 1, false = 0.
+// Functions: abs(x), min(a, b, ...), max(a, b, ...).
+// ============================================================================
+
+// Expr compiled expression
+type Expr struct {
+	source    string
+	root      node
+	variables []string // Variable names in order of first use
+}
+
+// Compile parses an expression and checks that every variable name is known
+func Compile(source string) (*Expr, error) {
+	p := &parser{src: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	return &Expr{source: source, root: root, variables: p.variables}, nil
+}
+
+// MustCompile compiles an expression, panics if failed
+func MustCompile(source string) *Expr {
+	expr, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return expr
+}
+
+// String returns the expression source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Variables returns the variable names used by the expression
+func (e *Expr) Variables() []string {
+	return append([]string(nil), e.variables...)
+}
+
+// Eval evaluates the expression to a number
+func (e *Expr) Eval(vars Vars) (float64, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("expression %q is not a finite number", e.source)
+	}
+	return v, nil
+}
+
+// EvalBool evaluates the expression as a condition (non-zero = true)
+func (e *Expr) EvalBool(vars Vars) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return v != 0, nil
+}
+
+// ============================================================================
+// AST
+// ============================================================================
+
+type node interface {
+	eval(vars Vars) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(Vars) (float64, error) { return float64(n), nil }
+
+type varNode string
+
+func (n varNode) eval(vars Vars) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("variable %s is not available", string(n))
+	}
+	return v, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars Vars) (float64, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		return boolValue(v == 0), nil
+	}
+	return -v, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars Vars) (float64, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	// Short-circuit logical operators
+	switch n.op {
+	case "&&":
+		if l == 0 {
+			return 0, nil
+		}
+	case "||":
+		if l != 0 {
+			return 1, nil
+		}
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return boolValue(r != 0), nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case ">":
+		return boolValue(l > r), nil
+	case ">=":
+		return boolValue(l >= r), nil
+	case "<":
+		return boolValue(l < r), nil
+	case "<=":
+		return boolValue(l <= r), nil
+	case "==":
+		return boolValue(l == r), nil
+	case "!=":
+		return boolValue(l != r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(vars Vars) (float64, error) {
+	values := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = v
+	}
+
+	switch n.name {
+	case "abs":
+		return math.Abs(values[0]), nil
+	case "min":
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Min(result, v)
+		}
+		return result, nil
+	case "max":
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+		return result, nil
+	}
+	return 0, fmt.Errorf("unknown function %s", n.name)
+}
+
+// functionArity minimum and maximum argument count (-1 = unlimited)
+var functionArity = map[string][2]int{
+	"abs": {1, 1},
+	"min": {2, -1},
+	"max": {2, -1},
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ============================================================================
+// Lexer / Parser (recursive descent)
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenIdent
+	tokenOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+type parser struct {
+	src       string
+	tokens    []token
+	pos       int
+	variables []string
+}
+
+// twoCharOps operators made of two characters (checked before single characters)
+var twoCharOps = []string{"&&", "||", ">=", "<=", "==", "!="}
+
+func (p *parser) tokenize() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: src[start:i], offset: start})
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && (isIdentStart(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenIdent, text: strings.ToLower(src[start:i]), offset: start})
+		default:
+			matched := false
+			for _, op := range twoCharOps {
+				if strings.HasPrefix(src[i:], op) {
+					p.tokens = append(p.tokens, token{kind: tokenOp, text: op, offset: i})
+					i += 2
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			if !strings.ContainsRune("+-*/<>!(),", rune(c)) {
+				return fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenOp, text: string(c), offset: i})
+			i++
+		}
+	}
+	return nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// acceptOp consumes the next token if it is one of the given operators
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	tok, ok := p.peek()
+	if !ok || tok.kind != tokenOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.acceptOp(">", ">=", "<", "<=", "==", "!="); ok {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+// parseBinary parses a left-associative chain of the given operators
+func (p *parser) parseBinary(next func() (node, error), ops ...string) (node, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.acceptOp("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.offset)
+		}
+		return numberNode(v), nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return numberNode(1), nil
+		case "false":
+			return numberNode(0), nil
+		}
+		if _, ok := p.acceptOp("("); ok {
+			return p.parseCall(tok)
+		}
+		if !IsVariable(tok.text) {
+			return nil, fmt.Errorf("unknown variable %q at position %d", tok.text, tok.offset)
+		}
+		p.addVariable(tok.text)
+		return varNode(tok.text), nil
+
+	default:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.acceptOp(")"); !ok {
+				return nil, fmt.Errorf("missing ')' for '(' at position %d", tok.offset)
+			}
+			return inner, nil
+		}
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.offset)
+	}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	arity, ok := functionArity[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.offset)
+	}
+
+	var args []node
+	if _, ok := p.acceptOp(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.acceptOp(","); ok {
+				continue
+			}
+			if _, ok := p.acceptOp(")"); !ok {
+				return nil, fmt.Errorf("missing ')' for %s() at position %d", name.text, name.offset)
+			}
+			break
+		}
+	}
+
+	if len(args) < arity[0] || (arity[1] >= 0 && len(args) > arity[1]) {
+		return nil, fmt.Errorf("%s() called with %d arguments", name.text, len(args))
+	}
+	return &callNode{name: name.text, args: args}, nil
+}
+
+func (p *parser) addVariable(name string) {
+	for _, v := range p.variables {
+		if v == name {
+			return
+		}
+	}
+	p.variables = append(p.variables, name)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"testing"
+
+	"nofx/market"
+)
+
+func TestEval(t *testing.T) {
+	vars := Vars{"ema20": 105, "ema50": 100, "rsi14_4h": 65, "equity": 1000, "atr14": 2, "price": 100}
+
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"ema20 > ema50 && rsi14_4h < 70", 1},
+		{"ema20 > ema50 && rsi14_4h > 70", 0},
+		{"ema20 < ema50 || rsi14_4h >= 65", 1},
+		{"!(ema20 > ema50)", 0},
+		{"equity * 0.1", 100},
+		{"1 + 2 * 3 - 4 / 2", 5},
+		{"-atr14 * 2", -4},
+		{"max(2 * atr14, price * 0.01)", 4},
+		{"min(1, 2, 0.5)", 0.5},
+		{"abs(ema50 - ema20)", 5},
+		{"true && !false", 1},
+		{"EMA20 == 105", 1},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) error: %v", tt.expr, err)
+		}
+		got, err := expr.Eval(vars)
+		if err != nil {
+			t.Fatalf("Eval(%q) error: %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"ema20 >",
+		"(ema20 > ema50",
+		"foo > 1",
+		"rsi14_7m < 30",
+		"ema20 $ ema50",
+		"sqrt(4)",
+		"abs(1, 2)",
+		"ema20 ema50",
+	} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) should fail", src)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	if _, err := MustCompile("ema50 > 0").Eval(Vars{}); err == nil {
+		t.Error("missing variable should fail")
+	}
+	if _, err := MustCompile("price / 0").Eval(Vars{"price": 1}); err == nil {
+		t.Error("division by zero should fail")
+	}
+	// Short-circuit skips the unavailable right side
+	if ok, err := MustCompile("price < 0 && ema50 > 0").EvalBool(Vars{"price": 1}); err != nil || ok {
+		t.Errorf("EvalBool() = %v, %v, want false without error", ok, err)
+	}
+}
+
+func TestIsVariable(t *testing.T) {
+	for _, name := range []string{"price", "ema20", "prev_ema20", "rsi14_4h", "prev_close_15m", "change_1h", "pnl_pct"} {
+		if !IsVariable(name) {
+			t.Errorf("IsVariable(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"ema", "prev_price", "price_4h", "rsi14_4x", "_4h"} {
+		if IsVariable(name) {
+			t.Errorf("IsVariable(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestVariableTimeframes(t *testing.T) {
+	expr := MustCompile("rsi14_4h < 70 && prev_close_15m > ema20 && change_1h > 0 && rsi14_4h > 30")
+	got := expr.Variables()
+	if len(got) != 4 || got[0] != "rsi14_4h" || got[3] != "change_1h" {
+		t.Fatalf("Variables() = %v, want each name once in order of use", got)
+	}
+	want := map[string]string{"rsi14_4h": "4h", "prev_close_15m": "15m", "ema20": "", "change_1h": ""}
+	for name, tf := range want {
+		if got := VariableTimeframe(name); got != tf {
+			t.Errorf("VariableTimeframe(%q) = %q, want %q", name, got, tf)
+		}
+	}
+}
+
+func TestMarketVars(t *testing.T) {
+	series := &market.TimeframeSeriesData{
+		Timeframe:   "4h",
+		Klines:      []market.KlineBar{{Close: 99}, {Close: 101}},
+		EMA20Values: []float64{98, 100},
+		RSI14Values: []float64{55},
+		ATR14:       3,
+	}
+	data := &market.Data{
+		CurrentPrice:  101,
+		CurrentEMA20:  90,
+		TimeframeData: map[string]*market.TimeframeSeriesData{"4h": series},
+	}
+
+	vars := MarketVars(data, "4h")
+	want := map[string]float64{
+		"price": 101, "ema20": 100, "prev_ema20": 98, "ema20_4h": 100, "rsi14_4h": 55,
+		"close": 101, "prev_close_4h": 99, "atr14": 3,
+	}
+	for name, value := range want {
+		if vars[name] != value {
+			t.Errorf("vars[%s] = %v, want %v", name, vars[name], value)
+		}
+	}
+	if _, ok := vars["prev_rsi14_4h"]; ok {
+		t.Error("prev_rsi14_4h should be unavailable with a single value")
+	}
+}
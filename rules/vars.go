@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"nofx/market"
+	"strings"
+)
+
+// Vars variable values available to an expression
+type Vars map[string]float64
+
+// seriesVariables indicator values read from a timeframe series. They can be suffixed with a
+// timeframe (rsi14_4h) and prefixed with prev_ for the previous bar (prev_ema20)
+var seriesVariables = []string{
+	"open", "high", "low", "close", "volume",
+	"ema20", "ema50", "macd", "rsi7", "rsi14",
+	"adx", "di_plus", "di_minus",
+	"boll_upper", "boll_middle", "boll_lower",
+	"atr14",
+}
+
+// contextVariables values that do not depend on a timeframe
+var contextVariables = []string{
+	// Market
+	"price", "change_15m", "change_1h", "change_4h", "funding_rate", "oi", "oi_avg",
+	// Account
+	"equity", "available", "margin_used_pct", "positions",
+	// Current position (only set when evaluating exit rules)
+	"entry_price", "pnl_pct", "peak_pnl_pct",
+}
+
+var (
+	seriesVariableSet  = toSet(seriesVariables)
+	contextVariableSet = toSet(contextVariables)
+	timeframeSet       = toSet(market.SupportedTimeframes())
+)
+
+// IsVariable reports whether name is a known variable (it may still be unavailable at runtime)
+func IsVariable(name string) bool {
+	if contextVariableSet[name] {
+		return true
+	}
+	base := strings.TrimPrefix(name, "prev_")
+	if seriesVariableSet[base] {
+		return true
+	}
+	if i := strings.LastIndex(base, "_"); i > 0 && timeframeSet[base[i+1:]] {
+		return seriesVariableSet[base[:i]]
+	}
+	return false
+}
+
+// VariableTimeframe returns the timeframe suffix of a series variable ("" when it has none)
+func VariableTimeframe(name string) string {
+	base := strings.TrimPrefix(name, "prev_")
+	if i := strings.LastIndex(base, "_"); i > 0 && timeframeSet[base[i+1:]] && seriesVariableSet[base[:i]] {
+		return base[i+1:]
+	}
+	return ""
+}
+
+// Variables returns the documented variable names (for UI / prompts)
+func Variables() (series []string, context []string) {
+	return append([]string(nil), seriesVariables...), append([]string(nil), contextVariables...)
+}
+
+// MarketVars builds the market variables of a symbol. Unsuffixed indicator names refer to the
+// primary timeframe; every other timeframe in data.TimeframeData is available with a suffix.
+func MarketVars(data *market.Data, primaryTimeframe string) Vars {
+	vars := Vars{}
+	if data == nil {
+		return vars
+	}
+
+	vars["price"] = data.CurrentPrice
+	vars["change_15m"] = data.PriceChange15m
+	vars["change_1h"] = data.PriceChange1h
+	vars["change_4h"] = data.PriceChange4h
+	vars["funding_rate"] = data.FundingRate
+	if data.OpenInterest != nil {
+		vars["oi"] = data.OpenInterest.Latest
+		vars["oi_avg"] = data.OpenInterest.Average
+	}
+
+	// Fallback for the primary timeframe when no series is attached
+	if data.CurrentEMA20 > 0 {
+		vars["ema20"] = data.CurrentEMA20
+	}
+	if data.CurrentRSI7 > 0 {
+		vars["rsi7"] = data.CurrentRSI7
+	}
+	if data.CurrentADX > 0 {
+		vars["adx"] = data.CurrentADX
+	}
+	vars["macd"] = data.CurrentMACD
+	vars["close"] = data.CurrentPrice
+
+	for tf, series := range data.TimeframeData {
+		addSeriesVars(vars, series, "_"+tf)
+		if tf == primaryTimeframe {
+			addSeriesVars(vars, series, "")
+		}
+	}
+	return vars
+}
+
+// Merge copies other into v (other wins)
+func (v Vars) Merge(other Vars) Vars {
+	for name, value := range other {
+		v[name] = value
+	}
+	return v
+}
+
+// addSeriesVars adds the latest and previous value of every series indicator
+func addSeriesVars(vars Vars, s *market.TimeframeSeriesData, suffix string) {
+	if s == nil {
+		return
+	}
+
+	set := func(name string, values []float64) {
+		if n := len(values); n > 0 {
+			vars[name+suffix] = values[n-1]
+			if n > 1 {
+				vars["prev_"+name+suffix] = values[n-2]
+			}
+		}
+	}
+
+	if n := len(s.Klines); n > 0 {
+		opens, highs, lows, closes, volumes := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for i, k := range s.Klines {
+			opens[i], highs[i], lows[i], closes[i], volumes[i] = k.Open, k.High, k.Low, k.Close, k.Volume
+		}
+		set("open", opens)
+		set("high", highs)
+		set("low", lows)
+		set("close", closes)
+		set("volume", volumes)
+	}
+	set("ema20", s.EMA20Values)
+	set("ema50", s.EMA50Values)
+	set("macd", s.MACDValues)
+	set("rsi7", s.RSI7Values)
+	set("rsi14", s.RSI14Values)
+	set("adx", s.ADXValues)
+	set("di_plus", s.DIPlusValues)
+	set("di_minus", s.DIMinusValues)
+	set("boll_upper", s.BOLLUpper)
+	set("boll_middle", s.BOLLMiddle)
+	set("boll_lower", s.BOLLLower)
+	if s.ATR14 > 0 {
+		vars["atr14"+suffix] = s.ATR14
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Copy trade link statuses
+const (
+	CopyTradeFilled  = "filled"  // Mirrored on the follower
+	CopyTradeSkipped = "skipped" // Not mirrored (stale, below minimum, no follower position, ...)
+	CopyTradeFailed  = "failed"  // Mirroring failed on the follower exchange
+)
+
+// CopyTradeLink links one executed leader action to the follower order mirroring it
+type CopyTradeLink struct {
+	ID               int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	FollowerID       string    `gorm:"column:follower_id;not null;index:idx_copy_links_follower" json:"follower_id"`
+	LeaderID         string    `gorm:"column:leader_id;not null;index" json:"leader_id"`
+	LeaderRecordID   int64     `gorm:"column:leader_record_id;not null" json:"leader_record_id"` // Leader decision record
+	Action           string    `gorm:"column:action;not null" json:"action"`
+	LeaderSymbol     string    `gorm:"column:leader_symbol" json:"leader_symbol"`
+	LeaderQuantity   float64   `gorm:"column:leader_quantity;default:0" json:"leader_quantity"`
+	LeaderPrice      float64   `gorm:"column:leader_price;default:0" json:"leader_price"`
+	LeaderOrderID    int64     `gorm:"column:leader_order_id;default:0" json:"leader_order_id"`
+	LeaderTime       time.Time `gorm:"column:leader_time" json:"leader_time"`
+	FollowerSymbol   string    `gorm:"column:follower_symbol" json:"follower_symbol"`
+	FollowerQuantity float64   `gorm:"column:follower_quantity;default:0" json:"follower_quantity"`
+	FollowerPrice    float64   `gorm:"column:follower_price;default:0" json:"follower_price"`
+	FollowerOrderID  int64     `gorm:"column:follower_order_id;default:0" json:"follower_order_id"`
+	Ratio            float64   `gorm:"column:ratio;default:0" json:"ratio"` // Size ratio follower/leader
+	Status           string    `gorm:"column:status;not null" json:"status"`
+	Error            string    `gorm:"column:error;default:''" json:"error,omitempty"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime;index:idx_copy_links_follower,sort:desc" json:"created_at"`
+}
+
+func (CopyTradeLink) TableName() string { return "copy_trade_links" }
+
+// CopyTradeCursor position of a follower in its leader's decision log
+type CopyTradeCursor struct {
+	FollowerID   string    `gorm:"primaryKey" json:"follower_id"`
+	LeaderID     string    `gorm:"column:leader_id;not null" json:"leader_id"`
+	LastRecordID int64     `gorm:"column:last_record_id;default:0" json:"last_record_id"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (CopyTradeCursor) TableName() string { return "copy_trade_cursors" }
+
+// CopyTradeStore copy trading storage
+type CopyTradeStore struct {
+	db *gorm.DB
+}
+
+// NewCopyTradeStore creates a new copy trade store
+func NewCopyTradeStore(db *gorm.DB) *CopyTradeStore {
+	return &CopyTradeStore{db: db}
+}
+
+// InitTables initializes copy trading tables
+func (s *CopyTradeStore) InitTables() error {
+	if err := s.db.AutoMigrate(&CopyTradeLink{}, &CopyTradeCursor{}); err != nil {
+		return fmt.Errorf("failed to migrate copy trade tables: %w", err)
+	}
+	return nil
+}
+
+// SaveLink saves a leader/follower order link
+func (s *CopyTradeStore) SaveLink(link *CopyTradeLink) error {
+	if err := s.db.Create(link).Error; err != nil {
+		return fmt.Errorf("failed to save copy trade link: %w", err)
+	}
+	return nil
+}
+
+// GetLinks gets the latest links of a follower (newest first)
+func (s *CopyTradeStore) GetLinks(followerID string, limit int) ([]*CopyTradeLink, error) {
+	var links []*CopyTradeLink
+	err := s.db.Where("follower_id = ?", followerID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query copy trade links: %w", err)
+	}
+	return links, nil
+}
+
+// GetCursor gets the cursor of a follower (nil if it never followed)
+func (s *CopyTradeStore) GetCursor(followerID string) (*CopyTradeCursor, error) {
+	var cursor CopyTradeCursor
+	err := s.db.Where("follower_id = ?", followerID).First(&cursor).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// SaveCursor saves the cursor of a follower
+func (s *CopyTradeStore) SaveCursor(cursor *CopyTradeCursor) error {
+	return s.db.Save(cursor).Error
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== DCA Store Models ====================
+// These models mirror the kernel DCA types but are defined here
+// to avoid import cycles between store and kernel packages.
+
+// DCADealModel GORM model for dca_deals table
+type DCADealModel struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	TraderID  string     `json:"trader_id" gorm:"index;not null"`
+	Symbol    string     `json:"symbol" gorm:"not null"`
+	Side      string     `json:"side" gorm:"not null"`
+	Status    string     `json:"status" gorm:"index;not null"` // active/closed
+	OpenedAt  time.Time  `json:"opened_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	BasePrice      float64 `json:"base_price"`
+	Quantity       float64 `json:"quantity"`
+	CostUSD        float64 `json:"cost_usd"`
+	AvgEntryPrice  float64 `json:"avg_entry_price"`
+	SafetyFilled   int     `json:"safety_filled" gorm:"default:0"`
+	TrailingActive bool    `json:"trailing_active" gorm:"default:false"`
+	BestPrice      float64 `json:"best_price"`
+	ClosePrice     float64 `json:"close_price"`
+	RealizedPnL    float64 `json:"realized_pnl" gorm:"default:0"` // Net of fees
+	Fees           float64 `json:"fees" gorm:"default:0"`
+	CloseReason    string  `json:"close_reason,omitempty"`
+}
+
+func (DCADealModel) TableName() string {
+	return "dca_deals"
+}
+
+// DCASafetyOrderModel GORM model for dca_safety_orders table
+type DCASafetyOrderModel struct {
+	ID           string     `json:"id" gorm:"primaryKey"`
+	DealID       string     `json:"deal_id" gorm:"index;not null"`
+	OrderIndex   int        `json:"order_index" gorm:"not null"`
+	Price        float64    `json:"price" gorm:"not null"`
+	DeviationPct float64    `json:"deviation_pct"`
+	OrderUSD     float64    `json:"order_usd"`
+	Quantity     float64    `json:"quantity"`
+	State        string     `json:"state" gorm:"not null"` // planned/pending/filled/cancelled
+	OrderID      string     `json:"order_id,omitempty"`
+	FilledPrice  float64    `json:"filled_price,omitempty"`
+	FilledQty    float64    `json:"filled_qty,omitempty"`
+	FilledAt     *time.Time `json:"filled_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (DCASafetyOrderModel) TableName() string {
+	return "dca_safety_orders"
+}
+
+// ==================== DCA Store ====================
+
+// DCAStore provides database operations for DCA deals
+type DCAStore struct {
+	db *gorm.DB
+}
+
+// NewDCAStore creates a new DCA store
+func NewDCAStore(db *gorm.DB) *DCAStore {
+	return &DCAStore{db: db}
+}
+
+// InitTables initializes DCA tables
+func (s *DCAStore) InitTables() error {
+	if err := s.db.AutoMigrate(&DCADealModel{}, &DCASafetyOrderModel{}); err != nil {
+		return fmt.Errorf("failed to migrate dca tables: %w", err)
+	}
+	return nil
+}
+
+// SaveDeal saves a deal together with its safety orders
+func (s *DCAStore) SaveDeal(deal *DCADealModel, orders []DCASafetyOrderModel) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		deal.UpdatedAt = time.Now()
+		if err := tx.Save(deal).Error; err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range orders {
+			orders[i].DealID = deal.ID
+			orders[i].UpdatedAt = now
+		}
+		return tx.Save(&orders).Error
+	})
+}
+
+// LoadActiveDeal loads the active deal of a trader (nil if none)
+func (s *DCAStore) LoadActiveDeal(traderID string) (*DCADealModel, []DCASafetyOrderModel, error) {
+	var deal DCADealModel
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, "active").
+		Order("opened_at DESC").
+		First(&deal).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	orders, err := s.LoadSafetyOrders(deal.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &deal, orders, nil
+}
+
+// LoadLastClosedDeal loads the most recently closed deal of a trader (nil if none)
+func (s *DCAStore) LoadLastClosedDeal(traderID string) (*DCADealModel, error) {
+	var deal DCADealModel
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, "closed").
+		Order("closed_at DESC").
+		First(&deal).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deal, nil
+}
+
+// LoadSafetyOrders loads the safety orders of a deal
+func (s *DCAStore) LoadSafetyOrders(dealID string) ([]DCASafetyOrderModel, error) {
+	var orders []DCASafetyOrderModel
+	err := s.db.Where("deal_id = ?", dealID).
+		Order("order_index ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ListDeals lists the deals of a trader, newest first
+func (s *DCAStore) ListDeals(traderID string, limit int) ([]DCADealModel, error) {
+	var deals []DCADealModel
+	query := s.db.Where("trader_id = ?", traderID).
+		Order("opened_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&deals).Error; err != nil {
+		return nil, err
+	}
+	return deals, nil
+}
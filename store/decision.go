@@ -80,19 +80,20 @@ type PositionSnapshot struct {
 
 // DecisionAction decision action
 type DecisionAction struct {
-	Action     string    `json:"action"`
-	Symbol     string    `json:"symbol"`
-	Quantity   float64   `json:"quantity"`
-	Leverage   int       `json:"leverage"`
-	Price      float64   `json:"price"`
-	StopLoss   float64   `json:"stop_loss,omitempty"`   // Stop loss price
-	TakeProfit float64   `json:"take_profit,omitempty"` // Take profit price
-	Confidence int       `json:"confidence,omitempty"`  // AI confidence (0-100)
-	Reasoning  string    `json:"reasoning,omitempty"`   // Brief reasoning
-	OrderID    int64     `json:"order_id"`
-	Timestamp  time.Time `json:"timestamp"`
-	Success    bool      `json:"success"`
-	Error      string    `json:"error"`
+	Action          string    `json:"action"`
+	Symbol          string    `json:"symbol"`
+	Quantity        float64   `json:"quantity"`
+	Leverage        int       `json:"leverage"`
+	Price           float64   `json:"price"`
+	StopLoss        float64   `json:"stop_loss,omitempty"`        // Stop loss price
+	TakeProfit      float64   `json:"take_profit,omitempty"`      // Take profit price
+	ClosePercentage float64   `json:"close_percentage,omitempty"` // Share of the position closed by a partial close
+	Confidence      int       `json:"confidence,omitempty"`       // AI confidence (0-100)
+	Reasoning       string    `json:"reasoning,omitempty"`        // Brief reasoning
+	OrderID         int64     `json:"order_id"`
+	Timestamp       time.Time `json:"timestamp"`
+	Success         bool      `json:"success"`
+	Error           string    `json:"error"`
 }
 
 // Statistics statistics information
@@ -246,6 +247,24 @@ func (s *DecisionStore) GetRecordsByDate(traderID string, date time.Time) ([]*De
 	return records, nil
 }
 
+// GetRecordsInRange gets records of a trader within [start, end] (oldest first)
+func (s *DecisionStore) GetRecordsInRange(traderID string, start, end time.Time) ([]*DecisionRecord, error) {
+	var dbRecords []*DecisionRecordDB
+	err := s.db.Where("trader_id = ? AND timestamp >= ? AND timestamp <= ?", traderID, start, end).
+		Order("timestamp ASC").
+		Find(&dbRecords).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision records: %w", err)
+	}
+
+	records := make([]*DecisionRecord, len(dbRecords))
+	for i, db := range dbRecords {
+		records[i] = db.toRecord()
+	}
+
+	return records, nil
+}
+
 // CleanOldRecords cleans old records from N days ago
 func (s *DecisionStore) CleanOldRecords(traderID string, days int) (int64, error) {
 	cutoffTime := time.Now().AddDate(0, 0, -days)
@@ -315,6 +334,22 @@ func (s *DecisionStore) GetRecordsAfterID(traderID string, afterID int64, limit
 	return records, nil
 }
 
+// GetLastRecordID gets the highest record ID of specified trader (0 if none)
+func (s *DecisionStore) GetLastRecordID(traderID string) (int64, error) {
+	var lastID *int64
+	err := s.db.Model(&DecisionRecordDB{}).
+		Where("trader_id = ?", traderID).
+		Select("MAX(id)").
+		Scan(&lastID).Error
+	if err != nil {
+		return 0, err
+	}
+	if lastID == nil {
+		return 0, nil
+	}
+	return *lastID, nil
+}
+
 // GetLastCycleNumber gets the last cycle number for specified trader
 func (s *DecisionStore) GetLastCycleNumber(traderID string) (int, error) {
 	var cycleNumber *int
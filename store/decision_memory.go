@@ -0,0 +1,308 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Decision memory outcome states
+const (
+	MemoryOutcomePending   = "pending"
+	MemoryOutcomeWin       = "win"
+	MemoryOutcomeLoss      = "loss"
+	MemoryOutcomeBreakeven = "breakeven"
+	MemoryOutcomeExpired   = "expired"
+)
+
+const (
+	// memoryEntryToleranceMs allows the position entry time to slightly precede the decision timestamp
+	memoryEntryToleranceMs = int64(5 * time.Minute / time.Millisecond)
+	// memoryPositionWaitMs is how long after the decision its position may be recorded; a pending
+	// memory without a position by then is expired
+	memoryPositionWaitMs = int64(time.Hour / time.Millisecond)
+	// memorySearchPoolSize limits how many resolved memories are scored per similarity query
+	memorySearchPoolSize = 500
+	// memorySymbolPenalty is added to the distance of setups recorded on a different symbol
+	memorySymbolPenalty = 1.5
+	// memoryRegimePenalty is added to the distance of setups recorded in a different market regime
+	memoryRegimePenalty = 1.0
+)
+
+// MemoryFeatures indicator snapshot describing a trade setup
+type MemoryFeatures struct {
+	Price         float64 `gorm:"column:price;default:0" json:"price"`
+	PriceChange1h float64 `gorm:"column:price_change_1h;default:0" json:"price_change_1h"` // %
+	PriceChange4h float64 `gorm:"column:price_change_4h;default:0" json:"price_change_4h"` // %
+	RSI7          float64 `gorm:"column:rsi7;default:0" json:"rsi7"`
+	MACDPct       float64 `gorm:"column:macd_pct;default:0" json:"macd_pct"` // MACD as % of price
+	ADX           float64 `gorm:"column:adx;default:0" json:"adx"`
+	EMA20DistPct  float64 `gorm:"column:ema20_dist_pct;default:0" json:"ema20_dist_pct"` // Price distance from EMA20 (%)
+	FundingRate   float64 `gorm:"column:funding_rate;default:0" json:"funding_rate"`
+}
+
+// DecisionMemory a recorded trade setup and its realized outcome
+// All time fields use int64 millisecond timestamps (UTC)
+type DecisionMemory struct {
+	ID          int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID    string         `gorm:"column:trader_id;not null;index:idx_memory_trader_symbol" json:"trader_id"`
+	Symbol      string         `gorm:"column:symbol;not null;index:idx_memory_trader_symbol" json:"symbol"`
+	CycleNumber int            `gorm:"column:cycle_number;default:0" json:"cycle_number"`
+	Action      string         `gorm:"column:action;not null" json:"action"`
+	Side        string         `gorm:"column:side;default:''" json:"side"` // LONG/SHORT
+	Confidence  int            `gorm:"column:confidence;default:0" json:"confidence"`
+	Regime      string         `gorm:"column:regime;default:''" json:"regime"`
+	Features    MemoryFeatures `gorm:"embedded" json:"features"`
+	Outcome     string         `gorm:"column:outcome;default:pending;index:idx_memory_outcome" json:"outcome"`
+	PositionID  int64          `gorm:"column:position_id;default:0" json:"position_id"`
+	RealizedPnL float64        `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
+	PnLPct      float64        `gorm:"column:pnl_pct;default:0" json:"pnl_pct"`
+	HoldMs      int64          `gorm:"column:hold_ms;default:0" json:"hold_ms"`
+	CreatedAt   int64          `gorm:"column:created_at" json:"created_at"`   // Unix milliseconds UTC
+	ResolvedAt  int64          `gorm:"column:resolved_at" json:"resolved_at"` // Unix milliseconds UTC, 0 means pending
+}
+
+// TableName returns the table name
+func (DecisionMemory) TableName() string {
+	return "decision_memories"
+}
+
+// SimilarMemory a past setup with its distance to the queried setup
+type SimilarMemory struct {
+	DecisionMemory
+	Distance float64 `json:"distance"`
+}
+
+// DecisionMemoryStore decision memory storage
+type DecisionMemoryStore struct {
+	db *gorm.DB
+}
+
+// NewDecisionMemoryStore creates decision memory storage instance
+func NewDecisionMemoryStore(db *gorm.DB) *DecisionMemoryStore {
+	return &DecisionMemoryStore{db: db}
+}
+
+// initTables initializes decision memory tables
+func (s *DecisionMemoryStore) initTables() error {
+	// For PostgreSQL with existing table, skip AutoMigrate
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'decision_memories'`).Scan(&tableExists)
+		if tableExists > 0 {
+			return nil
+		}
+	}
+	return s.db.AutoMigrate(&DecisionMemory{})
+}
+
+// Record saves a new trade setup with pending outcome
+func (s *DecisionMemoryStore) Record(m *DecisionMemory) error {
+	if m.CreatedAt == 0 {
+		m.CreatedAt = time.Now().UTC().UnixMilli()
+	}
+	if m.Outcome == "" {
+		m.Outcome = MemoryOutcomePending
+	}
+	if err := s.db.Omit("ID").Create(m).Error; err != nil {
+		return fmt.Errorf("failed to save decision memory: %w", err)
+	}
+	return nil
+}
+
+// ResolveOutcomes links pending memories to closed positions and stores their outcome
+// Returns the number of memories resolved (including expired ones)
+func (s *DecisionMemoryStore) ResolveOutcomes(traderID string) (int, error) {
+	var pending []DecisionMemory
+	err := s.db.Where("trader_id = ? AND outcome = ?", traderID, MemoryOutcomePending).
+		Order("created_at ASC").
+		Find(&pending).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to query pending memories: %w", err)
+	}
+
+	// One position query per symbol and side
+	type memoryGroup struct{ symbol, side string }
+	var order []memoryGroup
+	groups := make(map[memoryGroup][]DecisionMemory)
+	for _, m := range pending {
+		g := memoryGroup{m.Symbol, m.Side}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], m)
+	}
+
+	nowMs := time.Now().UTC().UnixMilli()
+	resolved := 0
+	for _, g := range order {
+		memories := groups[g]
+		var positions []TraderPosition
+		err := s.db.Where("trader_id = ? AND symbol = ? AND side = ? AND status != ? AND entry_time BETWEEN ? AND ?",
+			traderID, g.symbol, g.side, "PENDING",
+			memories[0].CreatedAt-memoryEntryToleranceMs, memories[len(memories)-1].CreatedAt+memoryPositionWaitMs).
+			Where("id NOT IN (?)", s.db.Model(&DecisionMemory{}).
+				Select("position_id").
+				Where("trader_id = ? AND position_id > 0", traderID)).
+			Order("entry_time ASC").
+			Find(&positions).Error
+		if err != nil {
+			return resolved, fmt.Errorf("failed to query positions for %s %s memories: %w", g.symbol, g.side, err)
+		}
+
+		// Each memory takes the first unclaimed position recorded within its window
+		next := 0
+		for _, m := range memories {
+			for next < len(positions) && positions[next].EntryTime < m.CreatedAt-memoryEntryToleranceMs {
+				next++
+			}
+			updates := map[string]interface{}{}
+			if next >= len(positions) || positions[next].EntryTime > m.CreatedAt+memoryPositionWaitMs {
+				if nowMs-m.CreatedAt < memoryPositionWaitMs {
+					continue
+				}
+				updates["outcome"] = MemoryOutcomeExpired
+				updates["resolved_at"] = nowMs
+			} else {
+				pos := positions[next]
+				next++
+				if pos.Status != "CLOSED" {
+					continue
+				}
+				updates["outcome"] = classifyMemoryOutcome(pos.RealizedPnL)
+				updates["position_id"] = pos.ID
+				updates["realized_pnl"] = pos.RealizedPnL
+				updates["pnl_pct"] = positionPnLPct(&pos)
+				updates["resolved_at"] = nowMs
+				if pos.ExitTime > pos.EntryTime {
+					updates["hold_ms"] = pos.ExitTime - pos.EntryTime
+				}
+			}
+
+			if err := s.db.Model(&DecisionMemory{}).Where("id = ?", m.ID).Updates(updates).Error; err != nil {
+				return resolved, fmt.Errorf("failed to update memory %d: %w", m.ID, err)
+			}
+			resolved++
+		}
+	}
+
+	return resolved, nil
+}
+
+// FindSimilar returns the resolved setups closest to the given features
+// Setups on the same symbol and regime are preferred; others are penalized rather than excluded
+func (s *DecisionMemoryStore) FindSimilar(traderID, symbol, regime string, features MemoryFeatures, limit int) ([]SimilarMemory, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	// The symbol's own setups first, the pool is filled up with the latest setups of other symbols
+	resolvedOutcomes := []string{MemoryOutcomeWin, MemoryOutcomeLoss, MemoryOutcomeBreakeven}
+	var pool []DecisionMemory
+	err := s.db.Where("trader_id = ? AND symbol = ? AND outcome IN ?", traderID, symbol, resolvedOutcomes).
+		Order("created_at DESC").
+		Limit(memorySearchPoolSize).
+		Find(&pool).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision memories: %w", err)
+	}
+	if len(pool) < memorySearchPoolSize {
+		var others []DecisionMemory
+		err := s.db.Where("trader_id = ? AND symbol != ? AND outcome IN ?", traderID, symbol, resolvedOutcomes).
+			Order("created_at DESC").
+			Limit(memorySearchPoolSize - len(pool)).
+			Find(&others).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to query decision memories: %w", err)
+		}
+		pool = append(pool, others...)
+	}
+
+	similar := make([]SimilarMemory, 0, len(pool))
+	for _, m := range pool {
+		d := FeatureDistance(features, m.Features)
+		if m.Symbol != symbol {
+			d += memorySymbolPenalty
+		}
+		if regime != "" && m.Regime != regime {
+			d += memoryRegimePenalty
+		}
+		similar = append(similar, SimilarMemory{DecisionMemory: m, Distance: d})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Distance < similar[j].Distance
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// GetRecent gets the latest memories for a trader (newest first)
+func (s *DecisionMemoryStore) GetRecent(traderID string, limit int) ([]*DecisionMemory, error) {
+	var memories []*DecisionMemory
+	err := s.db.Where("trader_id = ?", traderID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&memories).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision memories: %w", err)
+	}
+	return memories, nil
+}
+
+// DeleteByTrader removes all memories of a trader
+func (s *DecisionMemoryStore) DeleteByTrader(traderID string) error {
+	return s.db.Where("trader_id = ?", traderID).Delete(&DecisionMemory{}).Error
+}
+
+// FeatureDistance computes a scaled Euclidean distance between two setups
+// Each feature is divided by a typical magnitude so that no single indicator dominates
+func FeatureDistance(a, b MemoryFeatures) float64 {
+	terms := []float64{
+		(a.PriceChange1h - b.PriceChange1h) / 2,
+		(a.PriceChange4h - b.PriceChange4h) / 5,
+		(a.RSI7 - b.RSI7) / 20,
+		(a.MACDPct - b.MACDPct) / 0.5,
+		(a.ADX - b.ADX) / 15,
+		(a.EMA20DistPct - b.EMA20DistPct) / 2,
+		(a.FundingRate - b.FundingRate) * 10000 / 5, // basis points
+	}
+	sum := 0.0
+	for _, t := range terms {
+		sum += t * t
+	}
+	return math.Sqrt(sum)
+}
+
+// classifyMemoryOutcome maps realized PnL to an outcome label
+func classifyMemoryOutcome(pnl float64) string {
+	switch {
+	case pnl > 0:
+		return MemoryOutcomeWin
+	case pnl < 0:
+		return MemoryOutcomeLoss
+	default:
+		return MemoryOutcomeBreakeven
+	}
+}
+
+// positionPnLPct returns the leveraged price return of a closed position (%)
+func positionPnLPct(pos *TraderPosition) float64 {
+	if pos.EntryPrice <= 0 {
+		return 0
+	}
+	leverage := pos.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	move := (pos.ExitPrice - pos.EntryPrice) / pos.EntryPrice
+	if pos.Side == "SHORT" {
+		move = -move
+	}
+	return move * 100 * float64(leverage)
+}
@@ -0,0 +1,320 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DecisionScore forward-looking evaluation of a single AI decision action
+// All time fields use int64 millisecond timestamps (UTC)
+type DecisionScore struct {
+	ID               int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID         string  `gorm:"column:trader_id;not null;index:idx_scores_trader_time" json:"trader_id"`
+	DecisionRecordID int64   `gorm:"column:decision_record_id;not null;uniqueIndex:idx_scores_record_action" json:"decision_record_id"`
+	CycleNumber      int     `gorm:"column:cycle_number;default:0" json:"cycle_number"`
+	Symbol           string  `gorm:"column:symbol;not null;uniqueIndex:idx_scores_record_action" json:"symbol"`
+	Action           string  `gorm:"column:action;not null;uniqueIndex:idx_scores_record_action" json:"action"`
+	Confidence       int     `gorm:"column:confidence;default:0" json:"confidence"`
+	AIModel          string  `gorm:"column:ai_model;default:''" json:"ai_model"`
+	Executed         bool    `gorm:"column:executed;default:false" json:"executed"`
+	DecisionTime     int64   `gorm:"column:decision_time;not null;index:idx_scores_trader_time" json:"decision_time"` // Unix milliseconds UTC
+	EntryPrice       float64 `gorm:"column:entry_price;default:0" json:"entry_price"`
+	StopLoss         float64 `gorm:"column:stop_loss;default:0" json:"stop_loss"`
+	TakeProfit       float64 `gorm:"column:take_profit;default:0" json:"take_profit"`
+	Timeframe        string  `gorm:"column:timeframe;default:''" json:"timeframe"`
+	HorizonBars      int     `gorm:"column:horizon_bars;default:0" json:"horizon_bars"`
+	ForwardReturnPct float64 `gorm:"column:forward_return_pct;default:0" json:"forward_return_pct"` // Directional return after horizon (%)
+	MaxFavorablePct  float64 `gorm:"column:max_favorable_pct;default:0" json:"max_favorable_pct"`   // Max favorable excursion (%)
+	MaxAdversePct    float64 `gorm:"column:max_adverse_pct;default:0" json:"max_adverse_pct"`       // Max adverse excursion (%)
+	HitStopLoss      bool    `gorm:"column:hit_stop_loss;default:false" json:"hit_stop_loss"`
+	HitTakeProfit    bool    `gorm:"column:hit_take_profit;default:false" json:"hit_take_profit"`
+	FirstHit         string  `gorm:"column:first_hit;default:''" json:"first_hit"` // "stop_loss", "take_profit" or ""
+	Correct          bool    `gorm:"column:correct;default:false" json:"correct"`
+	PositionID       int64   `gorm:"column:position_id;default:0" json:"position_id"`
+	RealizedPnL      float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
+	PnLSettled       bool    `gorm:"column:pnl_settled;default:false" json:"pnl_settled"` // Linked position closed, RealizedPnL final
+	ScoredAt         int64   `gorm:"column:scored_at" json:"scored_at"`                   // Unix milliseconds UTC
+}
+
+// TableName returns the table name
+func (DecisionScore) TableName() string {
+	return "decision_scores"
+}
+
+// DecisionScoreCursor last decision record fully processed by a trader's scorer
+type DecisionScoreCursor struct {
+	TraderID     string `gorm:"column:trader_id;primaryKey" json:"trader_id"`
+	LastRecordID int64  `gorm:"column:last_record_id;not null;default:0" json:"last_record_id"`
+	UpdatedAt    int64  `gorm:"column:updated_at" json:"updated_at"` // Unix milliseconds UTC
+}
+
+// TableName returns the table name
+func (DecisionScoreCursor) TableName() string {
+	return "decision_score_cursors"
+}
+
+// CalibrationStats aggregated hit rate for a group of scored decisions
+type CalibrationStats struct {
+	Key                 string  `json:"key"`
+	Count               int     `json:"count"`
+	Correct             int     `json:"correct"`
+	HitRate             float64 `json:"hit_rate"` // %
+	AvgConfidence       float64 `json:"avg_confidence"`
+	AvgForwardReturnPct float64 `json:"avg_forward_return_pct"`
+	TakeProfitHits      int     `json:"take_profit_hits"`
+	StopLossHits        int     `json:"stop_loss_hits"`
+	RealizedPnL         float64 `json:"realized_pnl"`
+}
+
+// CalibrationReport hit rates grouped by confidence bucket, symbol, action and model
+type CalibrationReport struct {
+	TraderID     string             `json:"trader_id"`
+	Since        int64              `json:"since"` // Unix milliseconds UTC
+	Overall      CalibrationStats   `json:"overall"`
+	ByConfidence []CalibrationStats `json:"by_confidence"`
+	BySymbol     []CalibrationStats `json:"by_symbol"`
+	ByAction     []CalibrationStats `json:"by_action"`
+	ByModel      []CalibrationStats `json:"by_model"`
+}
+
+// confidenceBuckets lower bounds of confidence buckets (ascending)
+var confidenceBuckets = []int{0, 60, 70, 80, 90}
+
+// ConfidenceBucket returns the bucket label for a confidence value, e.g. "70-79"
+func ConfidenceBucket(confidence int) string {
+	for i := len(confidenceBuckets) - 1; i >= 0; i-- {
+		lower := confidenceBuckets[i]
+		if confidence < lower {
+			continue
+		}
+		if i == len(confidenceBuckets)-1 {
+			return fmt.Sprintf("%d+", lower)
+		}
+		return fmt.Sprintf("%d-%d", lower, confidenceBuckets[i+1]-1)
+	}
+	return fmt.Sprintf("%d-%d", confidenceBuckets[0], confidenceBuckets[1]-1)
+}
+
+// DecisionScoreStore decision score storage
+type DecisionScoreStore struct {
+	db *gorm.DB
+}
+
+// NewDecisionScoreStore creates decision score storage instance
+func NewDecisionScoreStore(db *gorm.DB) *DecisionScoreStore {
+	return &DecisionScoreStore{db: db}
+}
+
+// initTables initializes decision score tables
+func (s *DecisionScoreStore) initTables() error {
+	// For PostgreSQL with existing table, skip AutoMigrate
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'decision_scores'`).Scan(&tableExists)
+		if tableExists > 0 {
+			s.db.Exec(`ALTER TABLE decision_scores ADD COLUMN IF NOT EXISTS pnl_settled BOOLEAN DEFAULT FALSE`)
+			return s.db.AutoMigrate(&DecisionScoreCursor{})
+		}
+	}
+	return s.db.AutoMigrate(&DecisionScore{}, &DecisionScoreCursor{})
+}
+
+// Save saves a decision score; an action that is already scored is left unchanged
+func (s *DecisionScoreStore) Save(score *DecisionScore) error {
+	if score.ScoredAt == 0 {
+		score.ScoredAt = time.Now().UTC().UnixMilli()
+	}
+	err := s.db.Omit("ID").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "decision_record_id"}, {Name: "symbol"}, {Name: "action"}},
+		DoNothing: true,
+	}).Create(score).Error
+	if err != nil {
+		return fmt.Errorf("failed to save decision score: %w", err)
+	}
+	return nil
+}
+
+// GetCursor returns the last decision record processed by a trader's scorer, false when none was saved
+func (s *DecisionScoreStore) GetCursor(traderID string) (int64, bool, error) {
+	var cursor DecisionScoreCursor
+	result := s.db.Where("trader_id = ?", traderID).Limit(1).Find(&cursor)
+	if result.Error != nil {
+		return 0, false, fmt.Errorf("failed to load scoring cursor: %w", result.Error)
+	}
+	return cursor.LastRecordID, result.RowsAffected > 0, nil
+}
+
+// SaveCursor saves the last decision record processed by a trader's scorer
+func (s *DecisionScoreStore) SaveCursor(traderID string, lastRecordID int64) error {
+	cursor := DecisionScoreCursor{TraderID: traderID, LastRecordID: lastRecordID, UpdatedAt: time.Now().UTC().UnixMilli()}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "trader_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_record_id", "updated_at"}),
+	}).Create(&cursor).Error
+	if err != nil {
+		return fmt.Errorf("failed to save scoring cursor: %w", err)
+	}
+	return nil
+}
+
+// SettleRealizedPnL copies the realized PnL of linked positions that have closed since their decision
+// was scored. Returns the number of scores settled.
+func (s *DecisionScoreStore) SettleRealizedPnL(traderID string) (int64, error) {
+	result := s.db.Exec(`UPDATE decision_scores
+		SET realized_pnl = (SELECT p.realized_pnl FROM trader_positions p WHERE p.id = decision_scores.position_id),
+			pnl_settled = ?
+		WHERE trader_id = ? AND pnl_settled = ? AND position_id > 0
+			AND position_id IN (SELECT id FROM trader_positions WHERE status = 'CLOSED')`,
+		true, traderID, false)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to settle decision score PnL: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetLastScoredRecordID returns the highest decision record ID scored for a trader
+func (s *DecisionScoreStore) GetLastScoredRecordID(traderID string) (int64, error) {
+	var lastID *int64
+	err := s.db.Model(&DecisionScore{}).
+		Where("trader_id = ?", traderID).
+		Select("MAX(decision_record_id)").
+		Scan(&lastID).Error
+	if err != nil {
+		return 0, err
+	}
+	if lastID == nil {
+		return 0, nil
+	}
+	return *lastID, nil
+}
+
+// GetScores gets scores for a trader since the given time (oldest first)
+func (s *DecisionScoreStore) GetScores(traderID string, sinceMs int64) ([]*DecisionScore, error) {
+	var scores []*DecisionScore
+	err := s.db.Where("trader_id = ? AND decision_time >= ?", traderID, sinceMs).
+		Order("decision_time ASC").
+		Find(&scores).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision scores: %w", err)
+	}
+	return scores, nil
+}
+
+// GetCalibrationReport builds a calibration report from scores since the given time
+func (s *DecisionScoreStore) GetCalibrationReport(traderID string, sinceMs int64) (*CalibrationReport, error) {
+	scores, err := s.GetScores(traderID, sinceMs)
+	if err != nil {
+		return nil, err
+	}
+	report := BuildCalibrationReport(scores)
+	report.TraderID = traderID
+	report.Since = sinceMs
+	return report, nil
+}
+
+// BuildCalibrationReport aggregates scores into a calibration report
+func BuildCalibrationReport(scores []*DecisionScore) *CalibrationReport {
+	overall := &CalibrationStats{Key: "all"}
+	byConfidence := make(map[string]*CalibrationStats)
+	bySymbol := make(map[string]*CalibrationStats)
+	byAction := make(map[string]*CalibrationStats)
+	byModel := make(map[string]*CalibrationStats)
+
+	add := func(groups map[string]*CalibrationStats, key string, score *DecisionScore) {
+		stats, ok := groups[key]
+		if !ok {
+			stats = &CalibrationStats{Key: key}
+			groups[key] = stats
+		}
+		stats.add(score)
+	}
+
+	for _, score := range scores {
+		overall.add(score)
+		add(byConfidence, ConfidenceBucket(score.Confidence), score)
+		add(bySymbol, score.Symbol, score)
+		add(byAction, score.Action, score)
+		model := score.AIModel
+		if model == "" {
+			model = "unknown"
+		}
+		add(byModel, model, score)
+	}
+
+	overall.finalize()
+	report := &CalibrationReport{
+		Overall:      *overall,
+		ByConfidence: sortedCalibrationStats(byConfidence, func(a, b CalibrationStats) bool { return bucketLower(a.Key) < bucketLower(b.Key) }),
+		BySymbol:     sortedCalibrationStats(bySymbol, func(a, b CalibrationStats) bool { return a.Count > b.Count }),
+		ByAction:     sortedCalibrationStats(byAction, func(a, b CalibrationStats) bool { return a.Key < b.Key }),
+		ByModel:      sortedCalibrationStats(byModel, func(a, b CalibrationStats) bool { return a.Count > b.Count }),
+	}
+	return report
+}
+
+// SuggestMinConfidence returns the lowest confidence bucket floor from which every higher bucket
+// with at least minSamples scores reaches targetHitRate (%). Never returns less than base.
+func (r *CalibrationReport) SuggestMinConfidence(base int, targetHitRate float64, minSamples int) int {
+	suggested := -1
+	for i := len(r.ByConfidence) - 1; i >= 0; i-- {
+		stats := r.ByConfidence[i]
+		if stats.Count < minSamples {
+			continue
+		}
+		if stats.HitRate < targetHitRate {
+			break
+		}
+		suggested = bucketLower(stats.Key)
+	}
+	if suggested < base {
+		// No qualifying bucket, or calibration is looser than the configured floor
+		return base
+	}
+	return suggested
+}
+
+func (c *CalibrationStats) add(score *DecisionScore) {
+	c.Count++
+	if score.Correct {
+		c.Correct++
+	}
+	if score.HitTakeProfit {
+		c.TakeProfitHits++
+	}
+	if score.HitStopLoss {
+		c.StopLossHits++
+	}
+	c.AvgConfidence += float64(score.Confidence)
+	c.AvgForwardReturnPct += score.ForwardReturnPct
+	c.RealizedPnL += score.RealizedPnL
+}
+
+func (c *CalibrationStats) finalize() {
+	if c.Count == 0 {
+		return
+	}
+	c.HitRate = float64(c.Correct) / float64(c.Count) * 100
+	c.AvgConfidence /= float64(c.Count)
+	c.AvgForwardReturnPct /= float64(c.Count)
+}
+
+func sortedCalibrationStats(groups map[string]*CalibrationStats, less func(a, b CalibrationStats) bool) []CalibrationStats {
+	result := make([]CalibrationStats, 0, len(groups))
+	for _, stats := range groups {
+		stats.finalize()
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	return result
+}
+
+// bucketLower parses the lower bound of a confidence bucket label
+func bucketLower(label string) int {
+	var lower int
+	fmt.Sscanf(label, "%d", &lower)
+	return lower
+}
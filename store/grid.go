@@ -117,6 +117,13 @@ type GridInstanceModel struct {
 	DirectionChangedAt     time.Time `json:"direction_changed_at"`
 	DirectionChangeCount   int       `json:"direction_change_count" gorm:"default:0"`
 
+	// Last AI re-tuning of a deterministic grid
+	LastTunedAt time.Time `json:"last_tuned_at"`
+
+	// Trailing grid shifts
+	TrailCount  int       `json:"trail_count" gorm:"default:0"`
+	LastTrailAt time.Time `json:"last_trail_at"`
+
 	TotalProfit     float64   `json:"total_profit" gorm:"default:0"`
 	TotalFees       float64   `json:"total_fees" gorm:"default:0"`
 	TotalTrades     int       `json:"total_trades" gorm:"default:0"`
@@ -150,6 +157,9 @@ type GridLevelModel struct {
 	PositionOpenAt   *time.Time `json:"position_open_at,omitempty"`
 	AllocationWeight float64    `json:"allocation_weight"`
 	AllocatedUSD     float64    `json:"allocated_usd"`
+	ExitOrderID      string     `json:"exit_order_id,omitempty"` // Paired exit order of a filled level
+	ExitPrice        float64    `json:"exit_price,omitempty"`
+	EntryFee         float64    `json:"entry_fee,omitempty"` // Fee of the entry fill of the held position
 	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
@@ -180,6 +190,48 @@ func (GridEventModel) TableName() string {
 	return "grid_events"
 }
 
+// Grid event types
+const (
+	GridEventStarted         = "started"
+	GridEventRestored        = "restored"
+	GridEventOrderPlaced     = "order_placed"
+	GridEventOrderFilled     = "order_filled"
+	GridEventOrderCancelled  = "order_cancelled"
+	GridEventStopLoss        = "stop_loss"
+	GridEventBreakout        = "breakout"
+	GridEventDirectionChange = "direction_change"
+	GridEventRegimeChange    = "regime_change"
+	GridEventPaused          = "paused"
+	GridEventResumed         = "resumed"
+	GridEventAdjusted        = "grid_adjusted"
+	GridEventTrailed         = "grid_trailed"
+	GridEventEmergencyExit   = "emergency_exit"
+)
+
+// GridRoundTripModel GORM model for grid_round_trips table: one completed entry → exit of a level
+type GridRoundTripModel struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	InstanceID string    `json:"instance_id" gorm:"index;not null"`
+	Symbol     string    `json:"symbol" gorm:"not null"`
+	LevelIndex int       `json:"level_index" gorm:"not null"`
+	LevelPrice float64   `json:"level_price"`
+	Side       string    `json:"side" gorm:"not null"` // Entry side
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	EntryFee   float64   `json:"entry_fee"`
+	ExitFee    float64   `json:"exit_fee"`
+	GrossPnL   float64   `json:"gross_pnl"`
+	NetPnL     float64   `json:"net_pnl"`
+	ExitReason string    `json:"exit_reason"`
+	OpenedAt   time.Time `json:"opened_at"`
+	ClosedAt   time.Time `json:"closed_at" gorm:"index"`
+}
+
+func (GridRoundTripModel) TableName() string {
+	return "grid_round_trips"
+}
+
 // GridRegimeAssessmentModel GORM model for grid_regime_assessments table
 type GridRegimeAssessmentModel struct {
 	ID              string    `json:"id" gorm:"primaryKey"`
@@ -234,6 +286,17 @@ func (s *GridStore) InitTables() error {
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_events_instance_id ON grid_events(instance_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_events_level_id ON grid_events(level_id)`)
 			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_grid_regime_assessments_instance_id ON grid_regime_assessments(instance_id)`)
+			// Columns added after the tables were created
+			s.db.Exec(`ALTER TABLE grid_levels ADD COLUMN IF NOT EXISTS exit_order_id TEXT`)
+			s.db.Exec(`ALTER TABLE grid_levels ADD COLUMN IF NOT EXISTS exit_price DOUBLE PRECISION DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS last_tuned_at TIMESTAMPTZ`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS trail_count INTEGER DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE grid_instances ADD COLUMN IF NOT EXISTS last_trail_at TIMESTAMPTZ`)
+			s.db.Exec(`ALTER TABLE grid_levels ADD COLUMN IF NOT EXISTS entry_fee DOUBLE PRECISION DEFAULT 0`)
+			// Tables added after the first release
+			if err := s.db.AutoMigrate(&GridRoundTripModel{}); err != nil {
+				return fmt.Errorf("failed to migrate grid round trips: %w", err)
+			}
 			return nil
 		}
 	}
@@ -245,6 +308,7 @@ func (s *GridStore) InitTables() error {
 		&GridLevelModel{},
 		&GridEventModel{},
 		&GridRegimeAssessmentModel{},
+		&GridRoundTripModel{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate grid tables: %w", err)
 	}
@@ -313,6 +377,9 @@ func (s *GridStore) DeleteGridConfig(id string) error {
 			if err := tx.Where("instance_id = ?", instance.ID).Delete(&GridRegimeAssessmentModel{}).Error; err != nil {
 				return err
 			}
+			if err := tx.Where("instance_id = ?", instance.ID).Delete(&GridRoundTripModel{}).Error; err != nil {
+				return err
+			}
 		}
 
 		// Delete instances
@@ -345,6 +412,18 @@ func (s *GridStore) LoadGridInstance(configID string) (*GridInstanceModel, error
 	return &instance, nil
 }
 
+// LoadGridInstanceBySymbol loads the latest grid instance of one symbol of a config (multi-symbol grids)
+func (s *GridStore) LoadGridInstanceBySymbol(configID, symbol string) (*GridInstanceModel, error) {
+	var instance GridInstanceModel
+	err := s.db.Where("config_id = ? AND symbol = ?", configID, symbol).
+		Order("started_at DESC").
+		First(&instance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
 // LoadGridInstanceByID loads a grid instance by ID
 func (s *GridStore) LoadGridInstanceByID(id string) (*GridInstanceModel, error) {
 	var instance GridInstanceModel
@@ -453,6 +532,28 @@ func (s *GridStore) CountGridEvents(instanceID string) (int64, error) {
 	return count, err
 }
 
+// ==================== Round Trip Operations ====================
+
+// SaveGridRoundTrip saves a completed round trip
+func (s *GridStore) SaveGridRoundTrip(trip *GridRoundTripModel) error {
+	if trip.ClosedAt.IsZero() {
+		trip.ClosedAt = time.Now()
+	}
+	return s.db.Create(trip).Error
+}
+
+// LoadGridRoundTrips loads the round trips of an instance, oldest first
+func (s *GridStore) LoadGridRoundTrips(instanceID string) ([]GridRoundTripModel, error) {
+	var trips []GridRoundTripModel
+	err := s.db.Where("instance_id = ?", instanceID).
+		Order("closed_at ASC").
+		Find(&trips).Error
+	if err != nil {
+		return nil, err
+	}
+	return trips, nil
+}
+
 // ==================== Regime Assessment Operations ====================
 
 // SaveGridRegimeAssessment saves a regime assessment
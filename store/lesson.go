@@ -0,0 +1,269 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Lesson status
+const (
+	LessonStatusPending    = "pending"    // Proposed by review, waiting for user approval
+	LessonStatusActive     = "active"     // Injected into system prompt
+	LessonStatusSuperseded = "superseded" // Replaced by a newer version
+)
+
+// Lesson categories
+const (
+	LessonCategoryMistake = "mistake"
+	LessonCategoryRule    = "rule"
+)
+
+// TradeReview a periodic AI post-mortem over closed trades
+type TradeReview struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID      string    `gorm:"column:trader_id;not null;index:idx_trade_reviews_trader" json:"trader_id"`
+	PeriodStart   time.Time `gorm:"column:period_start" json:"period_start"`
+	PeriodEnd     time.Time `gorm:"column:period_end" json:"period_end"`
+	PositionCount int       `gorm:"column:position_count;default:0" json:"position_count"`
+	TotalPnL      float64   `gorm:"column:total_pnl;default:0" json:"total_pnl"`
+	Summary       string    `gorm:"column:summary;default:''" json:"summary"`
+	RawResponse   string    `gorm:"column:raw_response;default:''" json:"raw_response"`
+	Success       bool      `gorm:"default:false" json:"success"`
+	ErrorMessage  string    `gorm:"column:error_message;default:''" json:"error_message"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (TradeReview) TableName() string { return "trade_reviews" }
+
+// TradingLesson a versioned lesson learned from trade reviews
+// Editing a lesson creates a new version sharing the same LessonKey
+type TradingLesson struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID  string    `gorm:"column:trader_id;not null;index:idx_lessons_trader_status" json:"trader_id"`
+	LessonKey string    `gorm:"column:lesson_key;not null;index" json:"lesson_key"`
+	Version   int       `gorm:"column:version;not null;default:1" json:"version"`
+	ReviewID  int64     `gorm:"column:review_id;default:0" json:"review_id"`
+	Category  string    `gorm:"column:category;default:'rule'" json:"category"`
+	Content   string    `gorm:"column:content;not null" json:"content"`
+	Status    string    `gorm:"column:status;default:'pending';index:idx_lessons_trader_status" json:"status"`
+	EditedBy  string    `gorm:"column:edited_by;default:''" json:"edited_by"` // "" for AI generated, user ID for manual edits
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (TradingLesson) TableName() string { return "trading_lessons" }
+
+// LessonStore trade review and lesson storage
+type LessonStore struct {
+	db *gorm.DB
+}
+
+// NewLessonStore creates a new LessonStore
+func NewLessonStore(db *gorm.DB) *LessonStore {
+	return &LessonStore{db: db}
+}
+
+// initTables initializes trade review and lesson tables
+func (s *LessonStore) initTables() error {
+	// For PostgreSQL with existing tables, skip AutoMigrate
+	if s.db.Dialector.Name() == "postgres" {
+		var tableExists int64
+		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trading_lessons'`).Scan(&tableExists)
+		if tableExists > 0 {
+			return nil
+		}
+	}
+	return s.db.AutoMigrate(&TradeReview{}, &TradingLesson{})
+}
+
+// SaveReview saves a trade review
+func (s *LessonStore) SaveReview(review *TradeReview) error {
+	if err := s.db.Omit("ID").Create(review).Error; err != nil {
+		return fmt.Errorf("failed to save trade review: %w", err)
+	}
+	return nil
+}
+
+// GetLatestReview gets the most recent review of a trader (nil if none)
+func (s *LessonStore) GetLatestReview(traderID string) (*TradeReview, error) {
+	var reviews []TradeReview
+	err := s.db.Where("trader_id = ?", traderID).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade reviews: %w", err)
+	}
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	return &reviews[0], nil
+}
+
+// GetLatestSuccessfulReview gets the most recent successful review of a trader (nil if none)
+func (s *LessonStore) GetLatestSuccessfulReview(traderID string) (*TradeReview, error) {
+	var reviews []TradeReview
+	err := s.db.Where("trader_id = ? AND success = ?", traderID, true).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade reviews: %w", err)
+	}
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	return &reviews[0], nil
+}
+
+// ListReviews gets the latest reviews of a trader (newest first)
+func (s *LessonStore) ListReviews(traderID string, limit int) ([]*TradeReview, error) {
+	var reviews []*TradeReview
+	err := s.db.Where("trader_id = ?", traderID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// CreateLesson creates the first version of a lesson
+func (s *LessonStore) CreateLesson(lesson *TradingLesson) error {
+	if lesson.LessonKey == "" {
+		lesson.LessonKey = fmt.Sprintf("%s_%d", lesson.TraderID, time.Now().UnixNano())
+	}
+	if lesson.Version == 0 {
+		lesson.Version = 1
+	}
+	if lesson.Status == "" {
+		lesson.Status = LessonStatusPending
+	}
+	if lesson.Category == "" {
+		lesson.Category = LessonCategoryRule
+	}
+	if err := s.db.Omit("ID").Create(lesson).Error; err != nil {
+		return fmt.Errorf("failed to create lesson: %w", err)
+	}
+	return nil
+}
+
+// GetLesson gets a lesson version by ID
+func (s *LessonStore) GetLesson(traderID string, id int64) (*TradingLesson, error) {
+	var lesson TradingLesson
+	err := s.db.Where("id = ? AND trader_id = ?", id, traderID).First(&lesson).Error
+	if err != nil {
+		return nil, err
+	}
+	return &lesson, nil
+}
+
+// ListLessons lists current (non-superseded) lessons of a trader, optionally filtered by status
+func (s *LessonStore) ListLessons(traderID, status string) ([]*TradingLesson, error) {
+	query := s.db.Where("trader_id = ?", traderID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status <> ?", LessonStatusSuperseded)
+	}
+
+	var lessons []*TradingLesson
+	if err := query.Order("created_at DESC").Find(&lessons).Error; err != nil {
+		return nil, fmt.Errorf("failed to query lessons: %w", err)
+	}
+	return lessons, nil
+}
+
+// GetLessonHistory gets all versions of a lesson (oldest first)
+func (s *LessonStore) GetLessonHistory(traderID, lessonKey string) ([]*TradingLesson, error) {
+	var lessons []*TradingLesson
+	err := s.db.Where("trader_id = ? AND lesson_key = ?", traderID, lessonKey).
+		Order("version ASC").
+		Find(&lessons).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lesson history: %w", err)
+	}
+	return lessons, nil
+}
+
+// GetActiveLessons gets active lessons for prompt injection (newest first)
+func (s *LessonStore) GetActiveLessons(traderID string, limit int) ([]*TradingLesson, error) {
+	var lessons []*TradingLesson
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, LessonStatusActive).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&lessons).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active lessons: %w", err)
+	}
+	return lessons, nil
+}
+
+// ApproveLesson activates a pending lesson
+func (s *LessonStore) ApproveLesson(traderID string, id int64) error {
+	result := s.db.Model(&TradingLesson{}).
+		Where("id = ? AND trader_id = ? AND status = ?", id, traderID, LessonStatusPending).
+		Updates(map[string]interface{}{
+			"status":     LessonStatusActive,
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pending lesson not found")
+	}
+	return nil
+}
+
+// UpdateLesson creates a new version of a lesson with the edited content
+// The new version keeps the status of the edited one, which becomes superseded
+func (s *LessonStore) UpdateLesson(traderID string, id int64, content, category, editedBy string) (*TradingLesson, error) {
+	var next *TradingLesson
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var current TradingLesson
+		if err := tx.Where("id = ? AND trader_id = ?", id, traderID).First(&current).Error; err != nil {
+			return err
+		}
+		if current.Status == LessonStatusSuperseded {
+			return fmt.Errorf("lesson version %d is superseded, edit the latest version", id)
+		}
+		if category == "" {
+			category = current.Category
+		}
+
+		next = &TradingLesson{
+			TraderID:  traderID,
+			LessonKey: current.LessonKey,
+			Version:   current.Version + 1,
+			ReviewID:  current.ReviewID,
+			Category:  category,
+			Content:   content,
+			Status:    current.Status,
+			EditedBy:  editedBy,
+		}
+		if err := tx.Model(&TradingLesson{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+			"status":     LessonStatusSuperseded,
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("ID").Create(next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// DeleteLesson deletes a lesson with all its versions
+func (s *LessonStore) DeleteLesson(traderID string, id int64) error {
+	lesson, err := s.GetLesson(traderID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.Where("trader_id = ? AND lesson_key = ?", traderID, lesson.LessonKey).Delete(&TradingLesson{}).Error
+}
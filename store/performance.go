@@ -0,0 +1,41 @@
+package store
+
+import (
+	"nofx/performance"
+	"time"
+)
+
+// tradeCurveStartEquity notional starting equity of the realized-PnL curve of GetFullStats
+const tradeCurveStartEquity = 10000.0
+
+// PerformanceInput loads the metric engine input of a live trader over start → end: its equity snapshots
+// (EquityStore) and the holding periods of its positions (PositionStore). The benchmark is left to the caller.
+func (s *Store) PerformanceInput(traderID string, start, end time.Time) (performance.Input, error) {
+	var in performance.Input
+
+	snapshots, err := s.Equity().GetByTimeRange(traderID, start, end)
+	if err != nil {
+		return in, err
+	}
+	in.Equity = make([]performance.EquityPoint, 0, len(snapshots))
+	for _, snap := range snapshots {
+		in.Equity = append(in.Equity, performance.EquityPoint{Time: snap.Timestamp.UTC(), Equity: snap.TotalEquity})
+	}
+
+	closed, err := s.Position().GetClosedPositionsInRange(traderID, start, end)
+	if err != nil {
+		return in, err
+	}
+	open, err := s.Position().GetOpenPositions(traderID)
+	if err != nil {
+		return in, err
+	}
+	for _, pos := range append(closed, open...) {
+		trade := performance.Trade{OpenedAt: time.UnixMilli(pos.EntryTime).UTC()}
+		if pos.Status == "CLOSED" && pos.ExitTime > 0 {
+			trade.ClosedAt = time.UnixMilli(pos.ExitTime).UTC()
+		}
+		in.Trades = append(in.Trades, trade)
+	}
+	return in, nil
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"fmt"
 	"math"
+	"nofx/performance"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -67,7 +69,7 @@ type TraderStats struct {
 	LossTrades     int     `json:"loss_trades"`
 	WinRate        float64 `json:"win_rate"`
 	ProfitFactor   float64 `json:"profit_factor"`
-	SharpeRatio    float64 `json:"sharpe_ratio"`
+	SharpeRatio    float64 `json:"sharpe_ratio"` // Per-trade, not annualized
 	TotalPnL       float64 `json:"total_pnl"`
 	TotalFee       float64 `json:"total_fee"`
 	AvgWin         float64 `json:"avg_win"`
@@ -78,29 +80,33 @@ type TraderStats struct {
 // TraderPosition position record
 // All time fields use int64 millisecond timestamps (UTC) to avoid timezone issues
 type TraderPosition struct {
-	ID                 int64   `gorm:"primaryKey;autoIncrement" json:"id"`
-	TraderID           string  `gorm:"column:trader_id;not null;index:idx_positions_trader" json:"trader_id"`
-	ExchangeID         string  `gorm:"column:exchange_id;not null;default:'';index:idx_positions_exchange" json:"exchange_id"`
-	ExchangeType       string  `gorm:"column:exchange_type;not null;default:''" json:"exchange_type"`
-	ExchangePositionID string  `gorm:"column:exchange_position_id;not null;default:''" json:"exchange_position_id"`
-	Symbol             string  `gorm:"column:symbol;not null" json:"symbol"`
-	Side               string  `gorm:"column:side;not null" json:"side"`
-	EntryQuantity      float64 `gorm:"column:entry_quantity;default:0" json:"entry_quantity"`
-	Quantity           float64 `gorm:"column:quantity;not null" json:"quantity"`
-	EntryPrice         float64 `gorm:"column:entry_price;not null" json:"entry_price"`
-	EntryOrderID       string  `gorm:"column:entry_order_id;default:''" json:"entry_order_id"`
-	EntryTime          int64   `gorm:"column:entry_time;not null;index:idx_positions_entry" json:"entry_time"` // Unix milliseconds UTC
-	ExitPrice          float64 `gorm:"column:exit_price;default:0" json:"exit_price"`
-	ExitOrderID        string  `gorm:"column:exit_order_id;default:''" json:"exit_order_id"`
-	ExitTime           int64   `gorm:"column:exit_time;index:idx_positions_exit" json:"exit_time"` // Unix milliseconds UTC, 0 means not set
-	RealizedPnL        float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
-	Fee                float64 `gorm:"column:fee;default:0" json:"fee"`
-	Leverage           int     `gorm:"column:leverage;default:1" json:"leverage"`
-	Status             string  `gorm:"column:status;default:OPEN;index:idx_positions_status" json:"status"`
-	CloseReason        string  `gorm:"column:close_reason;default:''" json:"close_reason"`
-	Source             string  `gorm:"column:source;default:system" json:"source"`
-	CreatedAt          int64   `gorm:"column:created_at" json:"created_at"`   // Unix milliseconds UTC
-	UpdatedAt          int64   `gorm:"column:updated_at" json:"updated_at"`   // Unix milliseconds UTC
+	ID                  int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID            string  `gorm:"column:trader_id;not null;index:idx_positions_trader" json:"trader_id"`
+	ExchangeID          string  `gorm:"column:exchange_id;not null;default:'';index:idx_positions_exchange" json:"exchange_id"`
+	ExchangeType        string  `gorm:"column:exchange_type;not null;default:''" json:"exchange_type"`
+	ExchangePositionID  string  `gorm:"column:exchange_position_id;not null;default:''" json:"exchange_position_id"`
+	Symbol              string  `gorm:"column:symbol;not null" json:"symbol"`
+	Side                string  `gorm:"column:side;not null" json:"side"`
+	EntryQuantity       float64 `gorm:"column:entry_quantity;default:0" json:"entry_quantity"`
+	Quantity            float64 `gorm:"column:quantity;not null" json:"quantity"`
+	EntryPrice          float64 `gorm:"column:entry_price;not null" json:"entry_price"`
+	EntryOrderID        string  `gorm:"column:entry_order_id;default:''" json:"entry_order_id"`
+	EntryTime           int64   `gorm:"column:entry_time;not null;index:idx_positions_entry" json:"entry_time"` // Unix milliseconds UTC
+	ExitPrice           float64 `gorm:"column:exit_price;default:0" json:"exit_price"`
+	ExitOrderID         string  `gorm:"column:exit_order_id;default:''" json:"exit_order_id"`
+	ExitTime            int64   `gorm:"column:exit_time;index:idx_positions_exit" json:"exit_time"` // Unix milliseconds UTC, 0 means not set
+	RealizedPnL         float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"`
+	Fee                 float64 `gorm:"column:fee;default:0" json:"fee"`
+	Leverage            int     `gorm:"column:leverage;default:1" json:"leverage"`
+	Status              string  `gorm:"column:status;default:OPEN;index:idx_positions_status" json:"status"`
+	CloseReason         string  `gorm:"column:close_reason;default:''" json:"close_reason"`
+	Source              string  `gorm:"column:source;default:system" json:"source"`
+	GroupID             string  `gorm:"column:group_id;default:''" json:"group_id,omitempty"`                              // Links the legs of a multi-leg position (e.g. funding carry pair)
+	EstimatedFundingPnL float64 `gorm:"column:estimated_funding_pnl;default:0" json:"estimated_funding_pnl"`               // Funding received (+) or paid (-) estimated from quoted rates, not included in RealizedPnL
+	FundingPnL          float64 `gorm:"column:funding_pnl;default:0" json:"funding_pnl"`                                   // Funding received (+) or paid (-) as settled by the exchange, not included in RealizedPnL
+	SleeveID            string  `gorm:"column:sleeve_id;default:'';index:idx_positions_sleeve" json:"sleeve_id,omitempty"` // Strategy sleeve that owns the position ("" = trader)
+	CreatedAt           int64   `gorm:"column:created_at" json:"created_at"`                                               // Unix milliseconds UTC
+	UpdatedAt           int64   `gorm:"column:updated_at" json:"updated_at"`                                               // Unix milliseconds UTC
 }
 
 // TableName returns the table name
@@ -142,6 +148,13 @@ func (s *PositionStore) InitTables() error {
 				}
 			}
 
+			// Add columns introduced after the initial schema
+			s.db.Exec(`ALTER TABLE trader_positions ADD COLUMN IF NOT EXISTS group_id TEXT DEFAULT ''`)
+			s.db.Exec(`ALTER TABLE trader_positions ADD COLUMN IF NOT EXISTS estimated_funding_pnl DOUBLE PRECISION DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE trader_positions ADD COLUMN IF NOT EXISTS funding_pnl DOUBLE PRECISION DEFAULT 0`)
+			s.db.Exec(`ALTER TABLE trader_positions ADD COLUMN IF NOT EXISTS sleeve_id TEXT DEFAULT ''`)
+			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_positions_sleeve ON trader_positions(sleeve_id)`)
+
 			// Just ensure index exists
 			s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_exchange_pos_unique ON trader_positions(exchange_id, exchange_position_id) WHERE exchange_position_id != ''`)
 			return nil
@@ -306,6 +319,221 @@ func (s *PositionStore) ClosePositionFully(id int64, exitPrice float64, exitOrde
 	}).Error
 }
 
+// AddEstimatedFundingPnL accumulates estimated funding on an open position
+func (s *PositionStore) AddEstimatedFundingPnL(id int64, amount float64) error {
+	return s.db.Model(&TraderPosition{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"estimated_funding_pnl": gorm.Expr("estimated_funding_pnl + ?", amount),
+		"updated_at":            time.Now().UTC().UnixMilli(),
+	}).Error
+}
+
+// SetFundingPnL sets the funding settled by the exchange on a position (the total since it was opened)
+func (s *PositionStore) SetFundingPnL(id int64, amount float64) error {
+	return s.db.Model(&TraderPosition{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"funding_pnl": amount,
+		"updated_at":  time.Now().UTC().UnixMilli(),
+	}).Error
+}
+
+// LinkOpenPosition tags the open position of a trader on an exchange account with a source and group
+// (used for legs recorded by OrderSync) and drops its pending link; returns the position ID, 0 if the
+// position is not recorded yet
+func (s *PositionStore) LinkOpenPosition(traderID, exchangeID, symbol, side, source, groupID string) (int64, error) {
+	var pos TraderPosition
+	err := s.db.Where("trader_id = ? AND exchange_id = ? AND symbol = ? AND side = ? AND status = ? AND (group_id = '' OR group_id IS NULL)",
+		traderID, exchangeID, symbol, strings.ToUpper(side), "OPEN").
+		Order("entry_time DESC").
+		First(&pos).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query open position: %w", err)
+	}
+	err = s.db.Model(&TraderPosition{}).Where("id = ?", pos.ID).Updates(map[string]interface{}{
+		"source":     source,
+		"group_id":   groupID,
+		"updated_at": time.Now().UTC().UnixMilli(),
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	err = s.db.Where("trader_id = ? AND exchange_id = ? AND symbol = ? AND side = ? AND group_id = ? AND status = ?",
+		traderID, exchangeID, symbol, strings.ToUpper(side), groupID, "PENDING").
+		Delete(&TraderPosition{}).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete pending link: %w", err)
+	}
+	return pos.ID, nil
+}
+
+// CreatePendingLink records a leg ordered on the trader's own exchange under its group until OrderSync
+// records the position (LinkOpenPosition then replaces it), so the group survives a restart
+func (s *PositionStore) CreatePendingLink(pos *TraderPosition) error {
+	pos.Status = "PENDING"
+	pos.EntryQuantity = pos.Quantity
+	return s.db.Create(pos).Error
+}
+
+// GetPendingLinks gets the legs of a trader still waiting for OrderSync
+func (s *PositionStore) GetPendingLinks(traderID string) ([]*TraderPosition, error) {
+	var positions []*TraderPosition
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, "PENDING").
+		Order("entry_time DESC").
+		Find(&positions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending links: %w", err)
+	}
+	return positions, nil
+}
+
+// DeletePendingLinks deletes the pending legs of a group (e.g. a pair that was closed before OrderSync
+// recorded it)
+func (s *PositionStore) DeletePendingLinks(traderID, groupID string) error {
+	return s.db.Where("trader_id = ? AND group_id = ? AND status = ?", traderID, groupID, "PENDING").
+		Delete(&TraderPosition{}).Error
+}
+
+// AssignSleeve attributes the open position of a symbol/side that has no sleeve yet to a sleeve.
+// Returns the position ID (0 if no such open position exists yet).
+func (s *PositionStore) AssignSleeve(traderID, symbol, side, sleeveID string) (int64, error) {
+	var pos TraderPosition
+	err := s.db.Where("trader_id = ? AND symbol = ? AND side = ? AND status = ? AND (sleeve_id = '' OR sleeve_id IS NULL)",
+		traderID, symbol, strings.ToUpper(side), "OPEN").
+		Order("entry_time DESC").
+		First(&pos).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query open position: %w", err)
+	}
+	err = s.db.Model(&TraderPosition{}).Where("id = ?", pos.ID).Updates(map[string]interface{}{
+		"sleeve_id":  sleeveID,
+		"updated_at": time.Now().UTC().UnixMilli(),
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	return pos.ID, nil
+}
+
+// GetSleeveRealizedPnL gets the realized PnL net of fees of a sleeve's closed positions
+func (s *PositionStore) GetSleeveRealizedPnL(traderID, sleeveID string) (float64, error) {
+	var result struct {
+		PnL float64
+		Fee float64
+	}
+	err := s.db.Model(&TraderPosition{}).
+		Select("COALESCE(SUM(realized_pnl), 0) as pnl, COALESCE(SUM(fee), 0) as fee").
+		Where("trader_id = ? AND sleeve_id = ? AND status = ?", traderID, sleeveID, "CLOSED").
+		Scan(&result).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum sleeve PnL: %w", err)
+	}
+	return result.PnL - result.Fee, nil
+}
+
+// PnLBreakdown funding/price PnL split of a trader's positions from one source
+type PnLBreakdown struct {
+	Source              string  `json:"source"`
+	FundingPnL          float64 `json:"funding_pnl"`           // Settled by the exchange
+	EstimatedFundingPnL float64 `json:"estimated_funding_pnl"` // Estimated from quoted funding rates (legs without settlement history)
+	PricePnL            float64 `json:"price_pnl"`             // Realized PnL of the legs (excludes funding)
+	Fee                 float64 `json:"fee"`
+	NetPnL              float64 `json:"net_pnl"`
+	OpenLegs            int     `json:"open_legs"`
+	ClosedLegs          int     `json:"closed_legs"`
+}
+
+// GetPnLBreakdown gets the funding/price PnL split of positions opened by a source (e.g. "funding_carry")
+func (s *PositionStore) GetPnLBreakdown(traderID, source string) (*PnLBreakdown, error) {
+	var positions []TraderPosition
+	if err := s.db.Where("trader_id = ? AND source = ? AND status != ?", traderID, source, "PENDING").Find(&positions).Error; err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	b := &PnLBreakdown{Source: source}
+	for _, pos := range positions {
+		b.FundingPnL += pos.FundingPnL
+		b.EstimatedFundingPnL += pos.EstimatedFundingPnL
+		b.PricePnL += pos.RealizedPnL
+		b.Fee += pos.Fee
+		if pos.Status == "OPEN" {
+			b.OpenLegs++
+		} else {
+			b.ClosedLegs++
+		}
+	}
+	b.NetPnL = b.FundingPnL + b.EstimatedFundingPnL + b.PricePnL - b.Fee
+	return b, nil
+}
+
+// PositionGroupPnL PnL of a multi-leg position (legs linked by group_id), reported as one position
+type PositionGroupPnL struct {
+	GroupID             string   `json:"group_id"`
+	Source              string   `json:"source"`
+	Symbols             []string `json:"symbols"`
+	Legs                int      `json:"legs"`
+	Status              string   `json:"status"` // OPEN while any leg is open
+	EntryTime           int64    `json:"entry_time"`
+	ExitTime            int64    `json:"exit_time,omitempty"`
+	RealizedPnL         float64  `json:"realized_pnl"`
+	FundingPnL          float64  `json:"funding_pnl"`
+	EstimatedFundingPnL float64  `json:"estimated_funding_pnl"`
+	Fee                 float64  `json:"fee"`
+	NetPnL              float64  `json:"net_pnl"`
+}
+
+// GetGroupedPnL gets the PnL of a trader's multi-leg positions, newest first
+func (s *PositionStore) GetGroupedPnL(traderID string, limit int) ([]PositionGroupPnL, error) {
+	var positions []TraderPosition
+	err := s.db.Where("trader_id = ? AND group_id != '' AND status != ?", traderID, "PENDING").
+		Order("entry_time DESC").
+		Find(&positions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grouped positions: %w", err)
+	}
+
+	var groups []PositionGroupPnL
+	index := make(map[string]int)
+	for _, pos := range positions {
+		i, ok := index[pos.GroupID]
+		if !ok {
+			if limit > 0 && len(groups) >= limit {
+				continue
+			}
+			i = len(groups)
+			index[pos.GroupID] = i
+			groups = append(groups, PositionGroupPnL{GroupID: pos.GroupID, Source: pos.Source, Status: "CLOSED", EntryTime: pos.EntryTime})
+		}
+		g := &groups[i]
+		g.Legs++
+		if !slices.Contains(g.Symbols, pos.Symbol) {
+			g.Symbols = append(g.Symbols, pos.Symbol)
+		}
+		if pos.Status == "OPEN" {
+			g.Status = "OPEN"
+		}
+		if pos.EntryTime < g.EntryTime {
+			g.EntryTime = pos.EntryTime
+		}
+		if pos.ExitTime > g.ExitTime {
+			g.ExitTime = pos.ExitTime
+		}
+		g.RealizedPnL += pos.RealizedPnL
+		g.FundingPnL += pos.FundingPnL
+		g.EstimatedFundingPnL += pos.EstimatedFundingPnL
+		g.Fee += pos.Fee
+	}
+	for i := range groups {
+		if groups[i].Status == "OPEN" {
+			groups[i].ExitTime = 0
+		}
+		groups[i].NetPnL = groups[i].RealizedPnL + groups[i].FundingPnL + groups[i].EstimatedFundingPnL - groups[i].Fee
+	}
+	return groups, nil
+}
+
 // DeleteAllOpenPositions deletes all OPEN positions for a trader
 func (s *PositionStore) DeleteAllOpenPositions(traderID string) error {
 	return s.db.Where("trader_id = ? AND status = ?", traderID, "OPEN").Delete(&TraderPosition{}).Error
@@ -385,6 +613,47 @@ func (s *PositionStore) GetClosedPositions(traderID string, limit int) ([]*Trade
 	return positions, nil
 }
 
+// GetClosedPositionsInRange gets positions closed within a time range (oldest first)
+func (s *PositionStore) GetClosedPositionsInRange(traderID string, start, end time.Time) ([]*TraderPosition, error) {
+	var positions []*TraderPosition
+	err := s.db.Where("trader_id = ? AND status = ? AND exit_time >= ? AND exit_time <= ?",
+		traderID, "CLOSED", start.UnixMilli(), end.UnixMilli()).
+		Order("exit_time ASC").
+		Find(&positions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed positions: %w", err)
+	}
+	return positions, nil
+}
+
+// FindPositionForDecision finds the position opened by a decision
+// Matches by entry order ID first, then falls back to the first entry of the same symbol/side within the window
+func (s *PositionStore) FindPositionForDecision(traderID, symbol, side, entryOrderID string, fromMs, toMs int64) (*TraderPosition, error) {
+	var pos TraderPosition
+	if entryOrderID != "" && entryOrderID != "0" {
+		result := s.db.Where("trader_id = ? AND entry_order_id = ? AND status != ?", traderID, entryOrderID, "PENDING").Limit(1).Find(&pos)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return &pos, nil
+		}
+	}
+
+	result := s.db.Where("trader_id = ? AND symbol = ? AND side = ? AND entry_time >= ? AND entry_time <= ? AND status != ?",
+		traderID, symbol, side, fromMs, toMs, "PENDING").
+		Order("entry_time ASC").
+		Limit(1).
+		Find(&pos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &pos, nil
+}
+
 // GetAllOpenPositions gets all traders' open positions
 func (s *PositionStore) GetAllOpenPositions() ([]*TraderPosition, error) {
 	var positions []*TraderPosition
@@ -456,14 +725,18 @@ func (s *PositionStore) GetFullStats(traderID string) (*TraderStats, error) {
 		return nil, fmt.Errorf("failed to query position statistics: %w", err)
 	}
 
-	var pnls []float64
+	// Realized-PnL equity curve, measured with the same engine as equity curves and backtests
+	curve := []performance.EquityPoint{{Equity: tradeCurveStartEquity}}
 	var totalWin, totalLoss float64
 
 	for _, pos := range positions {
 		stats.TotalTrades++
 		stats.TotalPnL += pos.RealizedPnL
 		stats.TotalFee += pos.Fee
-		pnls = append(pnls, pos.RealizedPnL)
+		curve = append(curve, performance.EquityPoint{
+			Time:   time.UnixMilli(pos.ExitTime).UTC(),
+			Equity: tradeCurveStartEquity + stats.TotalPnL,
+		})
 
 		if pos.RealizedPnL > 0 {
 			stats.WinTrades++
@@ -486,12 +759,8 @@ func (s *PositionStore) GetFullStats(traderID string) (*TraderStats, error) {
 	if stats.LossTrades > 0 {
 		stats.AvgLoss = totalLoss / float64(stats.LossTrades)
 	}
-	if len(pnls) > 1 {
-		stats.SharpeRatio = calculateSharpeRatioFromPnls(pnls)
-	}
-	if len(pnls) > 0 {
-		stats.MaxDrawdownPct = calculateMaxDrawdownFromPnls(pnls)
-	}
+	stats.SharpeRatio = performance.TradeSharpeRatio(performance.Returns(curve))
+	stats.MaxDrawdownPct = performance.MaxDrawdownPct(curve, tradeCurveStartEquity)
 
 	return stats, nil
 }
@@ -583,58 +852,6 @@ func formatDurationMs(ms int64) string {
 	return fmt.Sprintf("%dd%dh", days, remainingHours)
 }
 
-// calculateSharpeRatioFromPnls calculates Sharpe ratio
-func calculateSharpeRatioFromPnls(pnls []float64) float64 {
-	if len(pnls) < 2 {
-		return 0
-	}
-
-	var sum float64
-	for _, pnl := range pnls {
-		sum += pnl
-	}
-	mean := sum / float64(len(pnls))
-
-	var variance float64
-	for _, pnl := range pnls {
-		variance += (pnl - mean) * (pnl - mean)
-	}
-	stdDev := math.Sqrt(variance / float64(len(pnls)-1))
-
-	if stdDev == 0 {
-		return 0
-	}
-
-	return mean / stdDev
-}
-
-// calculateMaxDrawdownFromPnls calculates maximum drawdown
-func calculateMaxDrawdownFromPnls(pnls []float64) float64 {
-	if len(pnls) == 0 {
-		return 0
-	}
-
-	const startingEquity = 10000.0
-	equity := startingEquity
-	peak := startingEquity
-	var maxDD float64
-
-	for _, pnl := range pnls {
-		equity += pnl
-		if equity > peak {
-			peak = equity
-		}
-		if peak > 0 {
-			dd := (peak - equity) / peak * 100
-			if dd > maxDD {
-				maxDD = dd
-			}
-		}
-	}
-
-	return maxDD
-}
-
 // SymbolStats per-symbol trading statistics
 type SymbolStats struct {
 	Symbol      string  `json:"symbol"`
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RebalanceRun one executed rebalance of a target-weight portfolio trader
+type RebalanceRun struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID    string    `gorm:"column:trader_id;not null;index:idx_rebalance_runs_trader" json:"trader_id"`
+	Timestamp   time.Time `gorm:"not null;index:idx_rebalance_runs_trader,sort:desc" json:"timestamp"`
+	Equity      float64   `gorm:"column:equity;default:0" json:"equity"`
+	TargetsJSON string    `gorm:"column:targets_json;default:''" json:"targets_json"` // Target weights (% of equity)
+	WeightsJSON string    `gorm:"column:weights_json;default:''" json:"weights_json"` // Weights before the rebalance
+	OrdersJSON  string    `gorm:"column:orders_json;default:''" json:"orders_json"`   // Orders with their execution result
+	MaxDrift    float64   `gorm:"column:max_drift;default:0" json:"max_drift"`        // Percentage points
+	TurnoverUSD float64   `gorm:"column:turnover_usd;default:0" json:"turnover_usd"`  // Notional of the filled orders
+	TurnoverPct float64   `gorm:"column:turnover_pct;default:0" json:"turnover_pct"`  // Turnover in % of equity
+	OrderCount  int       `gorm:"column:order_count;default:0" json:"order_count"`
+	FailedCount int       `gorm:"column:failed_count;default:0" json:"failed_count"`
+	AITargets   bool      `gorm:"column:ai_targets;default:false" json:"ai_targets"` // Targets set by the AI
+}
+
+func (RebalanceRun) TableName() string { return "rebalance_runs" }
+
+// RebalanceTurnover turnover summary of a trader over a time range
+type RebalanceTurnover struct {
+	Runs        int     `json:"runs"`
+	TurnoverUSD float64 `json:"turnover_usd"`
+	AvgEquity   float64 `json:"avg_equity"`
+	TurnoverPct float64 `json:"turnover_pct"` // Total turnover in % of the average equity
+}
+
+// RebalanceStore rebalance history storage
+type RebalanceStore struct {
+	db *gorm.DB
+}
+
+// NewRebalanceStore creates a new rebalance store
+func NewRebalanceStore(db *gorm.DB) *RebalanceStore {
+	return &RebalanceStore{db: db}
+}
+
+// InitTables initializes rebalance tables
+func (s *RebalanceStore) InitTables() error {
+	if err := s.db.AutoMigrate(&RebalanceRun{}); err != nil {
+		return fmt.Errorf("failed to migrate rebalance tables: %w", err)
+	}
+	return nil
+}
+
+// SaveRun saves an executed rebalance
+func (s *RebalanceStore) SaveRun(run *RebalanceRun) error {
+	if run.Timestamp.IsZero() {
+		run.Timestamp = time.Now().UTC()
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to save rebalance run: %w", err)
+	}
+	return nil
+}
+
+// GetLatest gets the latest rebalance of a trader (nil if none)
+func (s *RebalanceStore) GetLatest(traderID string) (*RebalanceRun, error) {
+	var run RebalanceRun
+	err := s.db.Where("trader_id = ?", traderID).Order("timestamp DESC").First(&run).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rebalance runs: %w", err)
+	}
+	return &run, nil
+}
+
+// List lists the latest rebalances of a trader (newest first)
+func (s *RebalanceStore) List(traderID string, limit int) ([]*RebalanceRun, error) {
+	var runs []*RebalanceRun
+	err := s.db.Where("trader_id = ?", traderID).Order("timestamp DESC").Limit(limit).Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rebalance runs: %w", err)
+	}
+	return runs, nil
+}
+
+// GetTurnover sums the turnover of a trader's rebalances within a time range
+func (s *RebalanceStore) GetTurnover(traderID string, start, end time.Time) (*RebalanceTurnover, error) {
+	var result RebalanceTurnover
+	err := s.db.Model(&RebalanceRun{}).
+		Where("trader_id = ? AND timestamp >= ? AND timestamp <= ?", traderID, start, end).
+		Select("COUNT(*) AS runs, COALESCE(SUM(turnover_usd), 0) AS turnover_usd, COALESCE(AVG(equity), 0) AS avg_equity").
+		Scan(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rebalance turnover: %w", err)
+	}
+	if result.AvgEquity > 0 {
+		result.TurnoverPct = result.TurnoverUSD / result.AvgEquity * 100
+	}
+	return &result, nil
+}
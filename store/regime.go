@@ -0,0 +1,86 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RegimeSwitchModel GORM model for regime_switches table (one row per confirmed regime change
+// of a regime router trader)
+type RegimeSwitchModel struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	TraderID       string    `json:"trader_id" gorm:"index;not null"`
+	Symbol         string    `json:"symbol"`
+	FromRegime     string    `json:"from_regime"` // "" on the first activation
+	ToRegime       string    `json:"to_regime" gorm:"not null"`
+	FromStrategyID string    `json:"from_strategy_id"`
+	ToStrategyID   string    `json:"to_strategy_id"`
+	Handover       string    `json:"handover"`         // close/keep/none (same strategy)
+	ClosedCount    int       `json:"closed_positions"` // Positions closed by the handover
+	BollingerWidth float64   `json:"bollinger_width"`
+	ATRPct         float64   `json:"atr_pct"`
+	ADX            float64   `json:"adx"`
+	Breakout       string    `json:"breakout"`
+	Reason         string    `json:"reason"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (RegimeSwitchModel) TableName() string {
+	return "regime_switches"
+}
+
+// RegimeStore provides database operations for regime router events
+type RegimeStore struct {
+	db *gorm.DB
+}
+
+// NewRegimeStore creates a new regime store
+func NewRegimeStore(db *gorm.DB) *RegimeStore {
+	return &RegimeStore{db: db}
+}
+
+// InitTables initializes regime tables
+func (s *RegimeStore) InitTables() error {
+	if err := s.db.AutoMigrate(&RegimeSwitchModel{}); err != nil {
+		return fmt.Errorf("failed to migrate regime tables: %w", err)
+	}
+	return nil
+}
+
+// SaveSwitch saves a regime switch event
+func (s *RegimeStore) SaveSwitch(event *RegimeSwitchModel) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return s.db.Create(event).Error
+}
+
+// LoadLastSwitch loads the latest regime switch of a trader (nil if none)
+func (s *RegimeStore) LoadLastSwitch(traderID string) (*RegimeSwitchModel, error) {
+	var event RegimeSwitchModel
+	err := s.db.Where("trader_id = ?", traderID).
+		Order("created_at DESC, id DESC").
+		First(&event).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// LoadRecentSwitches loads the latest regime switches of a trader (newest first)
+func (s *RegimeStore) LoadRecentSwitches(traderID string, limit int) ([]RegimeSwitchModel, error) {
+	var events []RegimeSwitchModel
+	query := s.db.Where("trader_id = ?", traderID).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
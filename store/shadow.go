@@ -0,0 +1,248 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultShadowFeeRate taker fee charged on simulated shadow fills (0.05%)
+const DefaultShadowFeeRate = 0.0005
+
+// ShadowStrategy a candidate strategy running in shadow next to a trader's live strategy.
+// It receives the live trading context every cycle but its decisions are only simulated.
+type ShadowStrategy struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	TraderID       string    `gorm:"column:trader_id;not null;index" json:"trader_id"`
+	StrategyID     string    `gorm:"column:strategy_id;not null" json:"strategy_id"`
+	Name           string    `gorm:"column:name;not null;default:''" json:"name"`
+	InitialBalance float64   `gorm:"column:initial_balance;not null;default:0" json:"initial_balance"` // Simulated starting equity
+	FeeRate        float64   `gorm:"column:fee_rate;default:0" json:"fee_rate"`                        // 0 = DefaultShadowFeeRate
+	Enabled        bool      `gorm:"column:enabled;default:true" json:"enabled"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (ShadowStrategy) TableName() string { return "trader_shadows" }
+
+// Fee returns the simulated fee rate
+func (s *ShadowStrategy) Fee() float64 {
+	if s.FeeRate <= 0 {
+		return DefaultShadowFeeRate
+	}
+	return s.FeeRate
+}
+
+// ShadowTrade a simulated position of a shadow strategy (never sent to the exchange)
+type ShadowTrade struct {
+	ID          int64   `gorm:"primaryKey;autoIncrement" json:"id"`
+	ShadowID    string  `gorm:"column:shadow_id;not null;index:idx_shadow_trades_shadow" json:"shadow_id"`
+	TraderID    string  `gorm:"column:trader_id;not null" json:"trader_id"`
+	Shadow      bool    `gorm:"column:shadow;default:true" json:"shadow"` // Always true (simulated)
+	Symbol      string  `gorm:"column:symbol;not null" json:"symbol"`
+	Side        string  `gorm:"column:side;not null" json:"side"` // LONG/SHORT
+	Quantity    float64 `gorm:"column:quantity;not null" json:"quantity"`
+	Leverage    int     `gorm:"column:leverage;default:1" json:"leverage"`
+	EntryPrice  float64 `gorm:"column:entry_price;not null" json:"entry_price"`
+	EntryTime   int64   `gorm:"column:entry_time;not null;index:idx_shadow_trades_shadow" json:"entry_time"` // Unix milliseconds UTC
+	StopLoss    float64 `gorm:"column:stop_loss;default:0" json:"stop_loss,omitempty"`
+	TakeProfit  float64 `gorm:"column:take_profit;default:0" json:"take_profit,omitempty"`
+	ExitPrice   float64 `gorm:"column:exit_price;default:0" json:"exit_price"`
+	ExitTime    int64   `gorm:"column:exit_time;default:0" json:"exit_time"`
+	Fee         float64 `gorm:"column:fee;default:0" json:"fee"`
+	RealizedPnL float64 `gorm:"column:realized_pnl;default:0" json:"realized_pnl"` // Before fees
+	Status      string  `gorm:"column:status;default:OPEN" json:"status"`          // OPEN/CLOSED
+	CloseReason string  `gorm:"column:close_reason;default:''" json:"close_reason,omitempty"`
+}
+
+func (ShadowTrade) TableName() string { return "shadow_trades" }
+
+// ShadowDecision one decision cycle of a shadow strategy
+type ShadowDecision struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ShadowID     string    `gorm:"column:shadow_id;not null;index:idx_shadow_decisions_shadow" json:"shadow_id"`
+	TraderID     string    `gorm:"column:trader_id;not null" json:"trader_id"`
+	Shadow       bool      `gorm:"column:shadow;default:true" json:"shadow"` // Always true (simulated)
+	Timestamp    time.Time `gorm:"not null;index:idx_shadow_decisions_shadow,sort:desc" json:"timestamp"`
+	CoTTrace     string    `gorm:"column:cot_trace;default:''" json:"cot_trace"`
+	DecisionJSON string    `gorm:"column:decision_json;default:''" json:"decision_json"`
+	ExecutionLog string    `gorm:"column:execution_log;default:''" json:"execution_log"`
+	Success      bool      `gorm:"column:success;default:false" json:"success"`
+	ErrorMessage string    `gorm:"column:error_message;default:''" json:"error_message,omitempty"`
+}
+
+func (ShadowDecision) TableName() string { return "shadow_decisions" }
+
+// ShadowEquitySnapshot simulated equity of a shadow strategy at a point in time
+type ShadowEquitySnapshot struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ShadowID      string    `gorm:"column:shadow_id;not null;index:idx_shadow_equity_time" json:"shadow_id"`
+	Timestamp     time.Time `gorm:"not null;index:idx_shadow_equity_time,sort:desc" json:"timestamp"`
+	TotalEquity   float64   `gorm:"column:total_equity;default:0" json:"total_equity"`
+	UnrealizedPnL float64   `gorm:"column:unrealized_pnl;default:0" json:"unrealized_pnl"`
+	PositionCount int       `gorm:"column:position_count;default:0" json:"position_count"`
+}
+
+func (ShadowEquitySnapshot) TableName() string { return "shadow_equity_snapshots" }
+
+// ShadowStore shadow strategy storage
+type ShadowStore struct {
+	db *gorm.DB
+}
+
+// NewShadowStore creates a new shadow store
+func NewShadowStore(db *gorm.DB) *ShadowStore {
+	return &ShadowStore{db: db}
+}
+
+// InitTables initializes shadow tables
+func (s *ShadowStore) InitTables() error {
+	if err := s.db.AutoMigrate(&ShadowStrategy{}, &ShadowTrade{}, &ShadowDecision{}, &ShadowEquitySnapshot{}); err != nil {
+		return fmt.Errorf("failed to migrate shadow tables: %w", err)
+	}
+	return nil
+}
+
+// Create creates a shadow strategy
+func (s *ShadowStore) Create(shadow *ShadowStrategy) error {
+	return s.db.Create(shadow).Error
+}
+
+// SetEnabled pauses or resumes a shadow strategy
+func (s *ShadowStore) SetEnabled(traderID, id string, enabled bool) error {
+	return s.db.Model(&ShadowStrategy{}).
+		Where("id = ? AND trader_id = ?", id, traderID).
+		Update("enabled", enabled).Error
+}
+
+// Delete deletes a shadow strategy and its simulated history
+func (s *ShadowStore) Delete(traderID, id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&ShadowTrade{}, &ShadowDecision{}} {
+			if err := tx.Where("shadow_id = ? AND trader_id = ?", id, traderID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("shadow_id = ?", id).Delete(&ShadowEquitySnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND trader_id = ?", id, traderID).Delete(&ShadowStrategy{}).Error
+	})
+}
+
+// Get gets a shadow strategy of a trader
+func (s *ShadowStore) Get(traderID, id string) (*ShadowStrategy, error) {
+	var shadow ShadowStrategy
+	if err := s.db.Where("id = ? AND trader_id = ?", id, traderID).First(&shadow).Error; err != nil {
+		return nil, err
+	}
+	return &shadow, nil
+}
+
+// List lists the shadow strategies of a trader (oldest first)
+func (s *ShadowStore) List(traderID string) ([]*ShadowStrategy, error) {
+	var shadows []*ShadowStrategy
+	err := s.db.Where("trader_id = ?", traderID).Order("created_at ASC").Find(&shadows).Error
+	if err != nil {
+		return nil, err
+	}
+	return shadows, nil
+}
+
+// ListEnabled lists the enabled shadow strategies of a trader (oldest first)
+func (s *ShadowStore) ListEnabled(traderID string) ([]*ShadowStrategy, error) {
+	var shadows []*ShadowStrategy
+	err := s.db.Where("trader_id = ? AND enabled = ?", traderID, true).Order("created_at ASC").Find(&shadows).Error
+	if err != nil {
+		return nil, err
+	}
+	return shadows, nil
+}
+
+// SaveTrade creates or updates a simulated trade
+func (s *ShadowStore) SaveTrade(trade *ShadowTrade) error {
+	trade.Shadow = true
+	if err := s.db.Save(trade).Error; err != nil {
+		return fmt.Errorf("failed to save shadow trade: %w", err)
+	}
+	return nil
+}
+
+// GetOpenTrades gets the open simulated trades of a shadow strategy
+func (s *ShadowStore) GetOpenTrades(shadowID string) ([]*ShadowTrade, error) {
+	var trades []*ShadowTrade
+	err := s.db.Where("shadow_id = ? AND status = ?", shadowID, "OPEN").Order("entry_time ASC").Find(&trades).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow trades: %w", err)
+	}
+	return trades, nil
+}
+
+// GetClosedTrades gets the simulated trades of a shadow strategy closed within a time range
+func (s *ShadowStore) GetClosedTrades(shadowID string, start, end time.Time) ([]*ShadowTrade, error) {
+	var trades []*ShadowTrade
+	err := s.db.Where("shadow_id = ? AND status = ? AND exit_time >= ? AND exit_time <= ?",
+		shadowID, "CLOSED", start.UnixMilli(), end.UnixMilli()).
+		Order("exit_time ASC").
+		Find(&trades).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow trades: %w", err)
+	}
+	return trades, nil
+}
+
+// GetRealizedPnL gets the realized PnL net of fees of a shadow strategy
+func (s *ShadowStore) GetRealizedPnL(shadowID string) (float64, error) {
+	var total float64
+	err := s.db.Model(&ShadowTrade{}).
+		Where("shadow_id = ? AND status = ?", shadowID, "CLOSED").
+		Select("COALESCE(SUM(realized_pnl - fee), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// SaveDecision saves a shadow decision cycle
+func (s *ShadowStore) SaveDecision(decision *ShadowDecision) error {
+	decision.Shadow = true
+	if decision.Timestamp.IsZero() {
+		decision.Timestamp = time.Now().UTC()
+	}
+	if err := s.db.Create(decision).Error; err != nil {
+		return fmt.Errorf("failed to save shadow decision: %w", err)
+	}
+	return nil
+}
+
+// GetDecisions gets the latest decision cycles of a shadow strategy (newest first)
+func (s *ShadowStore) GetDecisions(shadowID string, limit int) ([]*ShadowDecision, error) {
+	var decisions []*ShadowDecision
+	err := s.db.Where("shadow_id = ?", shadowID).Order("timestamp DESC").Limit(limit).Find(&decisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow decisions: %w", err)
+	}
+	return decisions, nil
+}
+
+// SaveEquity saves a shadow equity snapshot
+func (s *ShadowStore) SaveEquity(snapshot *ShadowEquitySnapshot) error {
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now().UTC()
+	}
+	if err := s.db.Omit("ID").Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to save shadow equity snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetEquityInRange gets the shadow equity snapshots within a time range (ascending)
+func (s *ShadowStore) GetEquityInRange(shadowID string, start, end time.Time) ([]*ShadowEquitySnapshot, error) {
+	var snapshots []*ShadowEquitySnapshot
+	err := s.db.Where("shadow_id = ? AND timestamp >= ? AND timestamp <= ?", shadowID, start, end).
+		Order("timestamp ASC").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow equity: %w", err)
+	}
+	return snapshots, nil
+}
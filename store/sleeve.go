@@ -0,0 +1,165 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sleeve allocation types
+const (
+	SleeveAllocationUSD = "usd" // Fixed USDT capital
+	SleeveAllocationPct = "pct" // Percentage of account equity
+)
+
+// TraderSleeve one strategy running inside a trader with its own share of the account capital.
+// A trader with sleeves runs them side by side on its exchange account; the trader's own
+// strategy then only supplies the account-wide risk control.
+type TraderSleeve struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	TraderID       string    `gorm:"column:trader_id;not null;index" json:"trader_id"`
+	StrategyID     string    `gorm:"column:strategy_id;not null" json:"strategy_id"`
+	Name           string    `gorm:"column:name;not null;default:''" json:"name"`
+	AllocationType string    `gorm:"column:allocation_type;not null;default:pct" json:"allocation_type"` // usd/pct
+	Allocation     float64   `gorm:"column:allocation;not null;default:0" json:"allocation"`             // USDT or % of equity
+	Enabled        bool      `gorm:"column:enabled;default:true" json:"enabled"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (TraderSleeve) TableName() string { return "trader_sleeves" }
+
+// Capital returns the capital allocated to the sleeve for an account equity
+func (s *TraderSleeve) Capital(accountEquity float64) float64 {
+	if s.AllocationType == SleeveAllocationUSD {
+		return s.Allocation
+	}
+	return accountEquity * s.Allocation / 100
+}
+
+// SleeveEquitySnapshot equity of one sleeve at a point in time (for per-sleeve return curves)
+type SleeveEquitySnapshot struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraderID      string    `gorm:"column:trader_id;not null;index:idx_sleeve_equity_time" json:"trader_id"`
+	SleeveID      string    `gorm:"column:sleeve_id;not null;index:idx_sleeve_equity_time" json:"sleeve_id"`
+	Timestamp     time.Time `gorm:"not null;index:idx_sleeve_equity_time,sort:desc" json:"timestamp"`
+	Capital       float64   `gorm:"column:capital;default:0" json:"capital"`           // Allocated capital
+	RealizedPnL   float64   `gorm:"column:realized_pnl;default:0" json:"realized_pnl"` // Net of fees
+	UnrealizedPnL float64   `gorm:"column:unrealized_pnl;default:0" json:"unrealized_pnl"`
+	TotalEquity   float64   `gorm:"column:total_equity;default:0" json:"total_equity"` // Capital + realized + unrealized
+	MarginUsed    float64   `gorm:"column:margin_used;default:0" json:"margin_used"`
+	PositionCount int       `gorm:"column:position_count;default:0" json:"position_count"`
+}
+
+func (SleeveEquitySnapshot) TableName() string { return "trader_sleeve_equity_snapshots" }
+
+// SleeveStore trader sleeve storage
+type SleeveStore struct {
+	db *gorm.DB
+}
+
+// NewSleeveStore creates a new sleeve store
+func NewSleeveStore(db *gorm.DB) *SleeveStore {
+	return &SleeveStore{db: db}
+}
+
+// InitTables initializes sleeve tables
+func (s *SleeveStore) InitTables() error {
+	if err := s.db.AutoMigrate(&TraderSleeve{}, &SleeveEquitySnapshot{}); err != nil {
+		return fmt.Errorf("failed to migrate sleeve tables: %w", err)
+	}
+	return nil
+}
+
+// Create creates a sleeve
+func (s *SleeveStore) Create(sleeve *TraderSleeve) error {
+	return s.db.Create(sleeve).Error
+}
+
+// Update updates the strategy, allocation and state of a sleeve
+func (s *SleeveStore) Update(sleeve *TraderSleeve) error {
+	return s.db.Model(&TraderSleeve{}).
+		Where("id = ? AND trader_id = ?", sleeve.ID, sleeve.TraderID).
+		Updates(map[string]interface{}{
+			"strategy_id":     sleeve.StrategyID,
+			"name":            sleeve.Name,
+			"allocation_type": sleeve.AllocationType,
+			"allocation":      sleeve.Allocation,
+			"enabled":         sleeve.Enabled,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// Delete deletes a sleeve and its equity history
+func (s *SleeveStore) Delete(traderID, id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("trader_id = ? AND sleeve_id = ?", traderID, id).Delete(&SleeveEquitySnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND trader_id = ?", id, traderID).Delete(&TraderSleeve{}).Error
+	})
+}
+
+// Get gets a sleeve of a trader
+func (s *SleeveStore) Get(traderID, id string) (*TraderSleeve, error) {
+	var sleeve TraderSleeve
+	if err := s.db.Where("id = ? AND trader_id = ?", id, traderID).First(&sleeve).Error; err != nil {
+		return nil, err
+	}
+	return &sleeve, nil
+}
+
+// List lists the sleeves of a trader (oldest first)
+func (s *SleeveStore) List(traderID string) ([]*TraderSleeve, error) {
+	var sleeves []*TraderSleeve
+	err := s.db.Where("trader_id = ?", traderID).
+		Order("created_at ASC").
+		Find(&sleeves).Error
+	if err != nil {
+		return nil, err
+	}
+	return sleeves, nil
+}
+
+// ListEnabled lists the enabled sleeves of a trader (oldest first)
+func (s *SleeveStore) ListEnabled(traderID string) ([]*TraderSleeve, error) {
+	var sleeves []*TraderSleeve
+	err := s.db.Where("trader_id = ? AND enabled = ?", traderID, true).
+		Order("created_at ASC").
+		Find(&sleeves).Error
+	if err != nil {
+		return nil, err
+	}
+	return sleeves, nil
+}
+
+// SaveEquity saves a sleeve equity snapshot
+func (s *SleeveStore) SaveEquity(snapshot *SleeveEquitySnapshot) error {
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now().UTC()
+	} else {
+		snapshot.Timestamp = snapshot.Timestamp.UTC()
+	}
+	if err := s.db.Omit("ID").Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to save sleeve equity snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetEquityCurve gets the latest equity snapshots of a sleeve in chronological order
+func (s *SleeveStore) GetEquityCurve(traderID, sleeveID string, limit int) ([]*SleeveEquitySnapshot, error) {
+	var snapshots []*SleeveEquitySnapshot
+	err := s.db.Where("trader_id = ? AND sleeve_id = ?", traderID, sleeveID).
+		Order("timestamp DESC").
+		Limit(limit).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sleeve equity: %w", err)
+	}
+	// Reverse to chronological order
+	for i, j := 0, len(snapshots)-1; i < j; i, j = i+1, j-1 {
+		snapshots[i], snapshots[j] = snapshots[j], snapshots[i]
+	}
+	return snapshots, nil
+}
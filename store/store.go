@@ -29,6 +29,15 @@ type Store struct {
 	equity   *EquityStore
 	order    *OrderStore
 	grid     *GridStore
+	dca      *DCAStore
+	regime   *RegimeStore
+	sleeve   *SleeveStore
+	copy     *CopyTradeStore
+	shadow   *ShadowStore
+	rebal    *RebalanceStore
+	memory   *DecisionMemoryStore
+	score    *DecisionScoreStore
+	lesson   *LessonStore
 
 	mu sync.RWMutex
 }
@@ -160,6 +169,33 @@ func (s *Store) initTables() error {
 	if err := s.Grid().InitTables(); err != nil {
 		return fmt.Errorf("failed to initialize grid tables: %w", err)
 	}
+	if err := s.DCA().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize dca tables: %w", err)
+	}
+	if err := s.Regime().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize regime tables: %w", err)
+	}
+	if err := s.Sleeve().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize sleeve tables: %w", err)
+	}
+	if err := s.CopyTrade().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize copy trade tables: %w", err)
+	}
+	if err := s.Shadow().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize shadow tables: %w", err)
+	}
+	if err := s.Rebalance().InitTables(); err != nil {
+		return fmt.Errorf("failed to initialize rebalance tables: %w", err)
+	}
+	if err := s.DecisionMemory().initTables(); err != nil {
+		return fmt.Errorf("failed to initialize decision memory tables: %w", err)
+	}
+	if err := s.DecisionScore().initTables(); err != nil {
+		return fmt.Errorf("failed to initialize decision score tables: %w", err)
+	}
+	if err := s.Lesson().initTables(); err != nil {
+		return fmt.Errorf("failed to initialize lesson tables: %w", err)
+	}
 	return nil
 }
 
@@ -293,6 +329,96 @@ func (s *Store) Grid() *GridStore {
 	return s.grid
 }
 
+// DCA gets DCA deal storage
+func (s *Store) DCA() *DCAStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dca == nil {
+		s.dca = NewDCAStore(s.gdb)
+	}
+	return s.dca
+}
+
+// Regime gets regime router event storage
+func (s *Store) Regime() *RegimeStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.regime == nil {
+		s.regime = NewRegimeStore(s.gdb)
+	}
+	return s.regime
+}
+
+// Sleeve gets trader sleeve storage
+func (s *Store) Sleeve() *SleeveStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sleeve == nil {
+		s.sleeve = NewSleeveStore(s.gdb)
+	}
+	return s.sleeve
+}
+
+// CopyTrade gets copy trading storage
+func (s *Store) CopyTrade() *CopyTradeStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.copy == nil {
+		s.copy = NewCopyTradeStore(s.gdb)
+	}
+	return s.copy
+}
+
+// Shadow gets shadow strategy storage
+func (s *Store) Shadow() *ShadowStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shadow == nil {
+		s.shadow = NewShadowStore(s.gdb)
+	}
+	return s.shadow
+}
+
+// Rebalance gets rebalance history storage
+func (s *Store) Rebalance() *RebalanceStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rebal == nil {
+		s.rebal = NewRebalanceStore(s.gdb)
+	}
+	return s.rebal
+}
+
+// DecisionMemory gets decision memory storage
+func (s *Store) DecisionMemory() *DecisionMemoryStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.memory == nil {
+		s.memory = NewDecisionMemoryStore(s.gdb)
+	}
+	return s.memory
+}
+
+// DecisionScore gets decision outcome score storage
+func (s *Store) DecisionScore() *DecisionScoreStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.score == nil {
+		s.score = NewDecisionScoreStore(s.gdb)
+	}
+	return s.score
+}
+
+// Lesson gets trade review and lesson storage
+func (s *Store) Lesson() *LessonStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lesson == nil {
+		s.lesson = NewLessonStore(s.gdb)
+	}
+	return s.lesson
+}
+
 // Close closes database connection
 func (s *Store) Close() error {
 	if s.driver != nil {
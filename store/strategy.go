@@ -32,7 +32,8 @@ func (Strategy) TableName() string { return "strategies" }
 
 // StrategyConfig strategy configuration details (JSON structure)
 type StrategyConfig struct {
-	// Strategy type: "ai_trading" (default) or "grid_trading"
+	// Strategy type: "ai_trading" (default), "grid_trading", "rule_based", "dca", "funding_carry", "pairs_trading",
+	// "regime_router" or "rebalance"
 	StrategyType string `json:"strategy_type,omitempty"`
 
 	// language setting: "zh" for Chinese, "en" for English
@@ -59,6 +60,33 @@ type StrategyConfig struct {
 
 	// Grid trading configuration (only used when StrategyType == "grid_trading")
 	GridConfig *GridStrategyConfig `json:"grid_config,omitempty"`
+
+	// Rule-based trading configuration (only used when StrategyType == "rule_based")
+	RuleConfig *RuleStrategyConfig `json:"rule_config,omitempty"`
+
+	// DCA bot configuration (only used when StrategyType == "dca")
+	DCAConfig *DCAStrategyConfig `json:"dca_config,omitempty"`
+
+	// Funding rate carry configuration (only used when StrategyType == "funding_carry")
+	FundingCarryConfig *FundingCarryStrategyConfig `json:"funding_carry_config,omitempty"`
+
+	// Pairs trading configuration (only used when StrategyType == "pairs_trading")
+	PairsConfig *PairsStrategyConfig `json:"pairs_config,omitempty"`
+
+	// Regime router configuration (only used when StrategyType == "regime_router")
+	RegimeRouterConfig *RegimeRouterConfig `json:"regime_router_config,omitempty"`
+
+	// Target-weight portfolio configuration (only used when StrategyType == "rebalance")
+	RebalanceConfig *RebalanceStrategyConfig `json:"rebalance_config,omitempty"`
+
+	// Decision outcome scoring and confidence calibration (nil = disabled)
+	Scoring *ScoringConfig `json:"scoring,omitempty"`
+
+	// Periodic AI self-review of closed trades (nil = disabled)
+	Review *ReviewConfig `json:"review,omitempty"`
+
+	// Candlestick chart images for multimodal models (nil = disabled)
+	Charts *ChartConfig `json:"charts,omitempty"`
 }
 
 // GridStrategyConfig grid trading specific configuration
@@ -93,6 +121,176 @@ type GridStrategyConfig struct {
 	EnableDirectionAdjust bool `json:"enable_direction_adjust"`
 	// Direction bias ratio for long_bias/short_bias modes (default 0.7 = 70%/30%)
 	DirectionBiasRatio float64 `json:"direction_bias_ratio"`
+	// Order placement: "ai" (default, AI decides every cycle) or "deterministic" (paired orders
+	// placed mechanically: a filled buy gets a sell one step up and vice versa)
+	Mode string `json:"mode,omitempty"`
+	// Deterministic mode: hours between AI re-tunings of bounds and direction (0 = never, no AI calls)
+	AITuneIntervalHours float64 `json:"ai_tune_interval_hours,omitempty"`
+	// Level spacing: "arithmetic" (default, equal price steps) or "geometric" (equal percentage
+	// steps, better suited to wide ranges)
+	Spacing string `json:"spacing,omitempty"`
+	// Trailing (infinity) grid: shift the whole ladder by whole steps when price leaves the range
+	// instead of pausing the grid
+	Trailing bool `json:"trailing,omitempty"`
+	// Trailing limits: the ladder never moves above / below these prices (0 = unlimited)
+	TrailingUpperLimit float64 `json:"trailing_upper_limit,omitempty"`
+	TrailingLowerLimit float64 `json:"trailing_lower_limit,omitempty"`
+	// Multi-symbol grid: one grid per entry sharing TotalInvestment (replaces Symbol when set).
+	// The other settings apply to every grid; trailing limits are ignored, bounds are per symbol.
+	Symbols []GridSymbolConfig `json:"symbols,omitempty"`
+	// Capital split between the symbol grids: "fixed" (default, by weight) or "volatility"
+	// (weight divided by the 4h ATR%, calmer symbols get more)
+	Allocation string `json:"allocation,omitempty"`
+}
+
+// GridSymbolConfig one symbol grid of a multi-symbol grid strategy
+type GridSymbolConfig struct {
+	// Trading pair (e.g., "ETHUSDT")
+	Symbol string `json:"symbol"`
+	// Relative share of TotalInvestment (0 = 1, equal split)
+	Weight float64 `json:"weight,omitempty"`
+	// Price boundaries of this grid (0 = auto-calculate from ATR)
+	UpperPrice float64 `json:"upper_price,omitempty"`
+	LowerPrice float64 `json:"lower_price,omitempty"`
+}
+
+// RuleStrategyConfig rule-based (no AI) strategy configuration
+// Every rule is an expression of the rules package, e.g. "ema20 > ema50 && rsi14_4h < 70"
+type RuleStrategyConfig struct {
+	// Entry conditions (empty = never open that side)
+	LongEntry  string `json:"long_entry,omitempty"`
+	ShortEntry string `json:"short_entry,omitempty"`
+	// Exit conditions evaluated for held positions (empty = only SL/TP close the position)
+	LongExit  string `json:"long_exit,omitempty"`
+	ShortExit string `json:"short_exit,omitempty"`
+	// Position size in USDT (notional), e.g. "equity * 0.2"
+	PositionSize string `json:"position_size"`
+	// Leverage (0 = max allowed by risk control for the symbol)
+	Leverage int `json:"leverage,omitempty"`
+	// Stop loss / take profit distance from entry price, e.g. "2 * atr14" (empty = not set)
+	StopLoss   string `json:"stop_loss,omitempty"`
+	TakeProfit string `json:"take_profit,omitempty"`
+}
+
+// DCAStrategyConfig DCA bot (base order + safety orders) configuration
+type DCAStrategyConfig struct {
+	// Trading pair (e.g., "BTCUSDT")
+	Symbol string `json:"symbol"`
+	// Deal direction: "long" (default) or "short"
+	Side string `json:"side,omitempty"`
+	// Leverage (1-20)
+	Leverage int `json:"leverage"`
+	// Base order size in USDT (notional)
+	BaseOrderUSD float64 `json:"base_order_usd"`
+	// First safety order size in USDT (notional)
+	SafetyOrderUSD float64 `json:"safety_order_usd"`
+	// Maximum number of safety orders per deal (0-25)
+	MaxSafetyOrders int `json:"max_safety_orders"`
+	// Price deviation of the first safety order from the base order price (%)
+	PriceDeviationPct float64 `json:"price_deviation_pct"`
+	// Each safety order deviation step is multiplied by this (default 1 = equal steps)
+	StepScale float64 `json:"step_scale,omitempty"`
+	// Each safety order size is multiplied by this (default 1 = equal sizes)
+	VolumeScale float64 `json:"volume_scale,omitempty"`
+	// Take profit from the average entry price (%)
+	TakeProfitPct float64 `json:"take_profit_pct"`
+	// Trailing take profit: after the take profit price is reached, close when price retraces
+	// this much from the best price (%, 0 = close at the take profit price)
+	TrailingDeviationPct float64 `json:"trailing_deviation_pct,omitempty"`
+	// Stop loss from the average entry price (%, 0 = disabled)
+	StopLossPct float64 `json:"stop_loss_pct,omitempty"`
+	// Minutes to wait after a deal closes before opening the next one
+	CooldownMinutes int `json:"cooldown_minutes,omitempty"`
+	// Use maker-only orders for safety orders
+	UseMakerOnly bool `json:"use_maker_only"`
+}
+
+// FundingCarryStrategyConfig delta-neutral funding rate carry configuration:
+// long the perpetual on the venue with the lowest funding rate, short it on the venue with the highest
+type FundingCarryStrategyConfig struct {
+	// Symbols to scan (e.g., ["BTCUSDT", "ETHUSDT"])
+	Symbols []string `json:"symbols"`
+	// Exchange account IDs used as additional venues (the trader's own exchange is always included)
+	HedgeExchangeIDs []string `json:"hedge_exchange_ids"`
+	// Also hold long legs as spot on the trader's own account (perp vs. stable hedge)
+	SpotHedge bool `json:"spot_hedge,omitempty"`
+	// Notional of each leg in USDT
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	// Leverage of each leg (1-10)
+	Leverage int `json:"leverage"`
+	// Maximum number of open pairs
+	MaxPairs int `json:"max_pairs"`
+	// Open a pair when the annualized funding spread is at least this (%)
+	MinEntryAPR float64 `json:"min_entry_apr"`
+	// Unwind a pair when the annualized funding spread falls below this (%)
+	ExitAPR float64 `json:"exit_apr"`
+	// Unwind when the leg notionals differ by more than this (%, default 10)
+	MaxImbalancePct float64 `json:"max_imbalance_pct,omitempty"`
+	// Unwind when a leg is closer than this to its liquidation price (%, default 5)
+	MinLiquidationDistancePct float64 `json:"min_liquidation_distance_pct,omitempty"`
+}
+
+// PairsStrategyConfig statistical arbitrage on the spread ln(A) - β·ln(B) of two correlated perpetuals
+type PairsStrategyConfig struct {
+	// First leg (e.g., "ETHUSDT"); sized with PositionSizeUSD
+	SymbolA string `json:"symbol_a"`
+	// Second leg (e.g., "BTCUSDT"); sized with β × PositionSizeUSD
+	SymbolB string `json:"symbol_b"`
+	// Kline timeframe of the hedge ratio and z-score (default "1h")
+	Timeframe string `json:"timeframe,omitempty"`
+	// Bars of the rolling window (20-250, default 100)
+	LookbackBars int `json:"lookback_bars,omitempty"`
+	// Enter when |z| reaches this (e.g., 2)
+	EntryZ float64 `json:"entry_z"`
+	// Exit when the z-score reverts to within this of zero (e.g., 0.5)
+	ExitZ float64 `json:"exit_z"`
+	// Stop out when |z| reaches this (0 = disabled)
+	StopZ float64 `json:"stop_z,omitempty"`
+	// Notional of leg A in USDT
+	PositionSizeUSD float64 `json:"position_size_usd"`
+	// Leverage of both legs (1-20)
+	Leverage int `json:"leverage"`
+}
+
+// RegimeRouterConfig regime router configuration: classifies the market regime of one symbol
+// and runs the strategy routed to it
+type RegimeRouterConfig struct {
+	// Symbol whose regime is classified (e.g., "BTCUSDT")
+	Symbol string `json:"symbol"`
+	// Strategy ID per regime. Keys: "trending", "narrow", "standard", "wide", "volatile",
+	// "ranging" (any non-trending regime without its own route) and "default" (fallback)
+	Routes map[string]string `json:"routes"`
+	// Consecutive cycles a new regime must be observed before switching (default 3)
+	ConfirmCycles int `json:"confirm_cycles,omitempty"`
+	// ADX (1h) at or above which the market counts as trending (default 25)
+	TrendADX float64 `json:"trend_adx,omitempty"`
+	// Positions on a strategy switch: "close" (default) closes them, "keep" hands them over
+	// to the next strategy. Open orders are cancelled either way
+	Handover string `json:"handover,omitempty"`
+}
+
+// RebalanceStrategyConfig target-weight portfolio: each symbol is traded back to its target share
+// of equity when its weight drifts out of the band
+type RebalanceStrategyConfig struct {
+	// Target weight per symbol in % of equity (notional, negative = short),
+	// e.g. {"BTCUSDT": 50, "ETHUSDT": 30, "SOLUSDT": -20}. With AITargets these are the basket and fallback
+	Targets map[string]float64 `json:"targets"`
+	// Leverage of all positions (1-20)
+	Leverage int `json:"leverage"`
+	// Rebalance a symbol when its weight is more than this many percentage points off target (default 2)
+	DriftBand float64 `json:"drift_band,omitempty"`
+	// Orders below this notional in USDT are skipped (default 10)
+	MinNotional float64 `json:"min_notional,omitempty"`
+	// Maximum sum of |weights| in % of equity (default 100, at most 100 × leverage)
+	MaxGrossExposure float64 `json:"max_gross_exposure,omitempty"`
+	// Minimum hours between two rebalances (0 = whenever the band is breached)
+	RebalanceIntervalHours float64 `json:"rebalance_interval_hours,omitempty"`
+	// Let the AI set the weights of the basket symbols
+	AITargets bool `json:"ai_targets,omitempty"`
+	// Hours between two AI target updates (default 24)
+	AIRefreshHours float64 `json:"ai_refresh_hours,omitempty"`
+	// Orders sent per batch (default 5); reductions are sent before increases
+	BatchSize int `json:"batch_size,omitempty"`
 }
 
 // PromptSectionsConfig editable sections of System Prompt
@@ -256,6 +454,52 @@ type RiskControlConfig struct {
 	MinConfidence int `json:"min_confidence"`
 }
 
+// ScoringConfig decision outcome scoring configuration
+type ScoringConfig struct {
+	// Score decisions N bars after they were made
+	Enabled bool `json:"enabled"`
+	// Kline timeframe used for forward evaluation (default "15m")
+	Timeframe string `json:"timeframe,omitempty"`
+	// Number of bars after the decision to evaluate (default 16)
+	HorizonBars int `json:"horizon_bars,omitempty"`
+	// Only scores within this window feed the prompt and dynamic confidence (default 14)
+	LookbackDays int `json:"lookback_days,omitempty"`
+	// Add "your recent accuracy" section to the user prompt
+	IncludeInPrompt bool `json:"include_in_prompt"`
+	// Raise MinConfidence to the lowest calibrated bucket reaching TargetHitRate (CODE ENFORCED)
+	DynamicMinConfidence bool `json:"dynamic_min_confidence"`
+	// Hit rate (%) a confidence bucket must reach to be trusted (default 55)
+	TargetHitRate float64 `json:"target_hit_rate,omitempty"`
+	// Min scored decisions in a bucket before it is considered (default 10)
+	MinSamples int `json:"min_samples,omitempty"`
+}
+
+// ReviewConfig periodic trade self-review configuration
+type ReviewConfig struct {
+	// Run a post-mortem over closed trades every IntervalHours
+	Enabled bool `json:"enabled"`
+	// Hours between reviews (default 24)
+	IntervalHours int `json:"interval_hours,omitempty"`
+	// Closed trades from the last N hours are reviewed (default 24)
+	LookbackHours int `json:"lookback_hours,omitempty"`
+	// Activate proposed lessons immediately instead of waiting for approval
+	AutoApprove bool `json:"auto_approve"`
+	// Max active lessons injected into the system prompt (default 10)
+	MaxActiveLessons int `json:"max_active_lessons,omitempty"`
+}
+
+// ChartConfig candlestick chart attachment configuration
+type ChartConfig struct {
+	// Attach chart images to the user prompt (the AI model must accept images)
+	Enabled bool `json:"enabled"`
+	// Number of top candidate coins to chart each cycle (default 3); held positions are always charted
+	TopCandidates int `json:"top_candidates,omitempty"`
+	// Timeframe to chart (default: primary timeframe)
+	Timeframe string `json:"timeframe,omitempty"`
+	// Max bars per chart (default 80)
+	MaxBars int `json:"max_bars,omitempty"`
+}
+
 // NewStrategyStore creates a new StrategyStore
 func NewStrategyStore(db *gorm.DB) *StrategyStore {
 	return &StrategyStore{db: db}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -30,6 +31,8 @@ type Trader struct {
 	IsRunning           bool      `gorm:"column:is_running;default:false" json:"is_running"`
 	IsCrossMargin       bool      `gorm:"column:is_cross_margin;default:true" json:"is_cross_margin"`
 	ShowInCompetition   bool      `gorm:"column:show_in_competition;default:true" json:"show_in_competition"`
+	LeaderTraderID      string    `gorm:"column:leader_trader_id;default:''" json:"leader_trader_id,omitempty"`     // Copy trading: trader being followed
+	FollowConfig        string    `gorm:"column:follow_config;type:text;default:''" json:"follow_config,omitempty"` // Copy trading: FollowConfig JSON
 	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 
@@ -49,6 +52,52 @@ func (Trader) TableName() string {
 	return "traders"
 }
 
+// Follow sizing modes
+const (
+	FollowSizingEquityRatio = "equity_ratio" // Scale by follower equity / leader equity
+	FollowSizingMultiplier  = "multiplier"   // Scale by a fixed multiplier
+)
+
+// FollowConfig copy trading configuration of a follower trader
+type FollowConfig struct {
+	SizingMode string  `json:"sizing_mode"`          // equity_ratio/multiplier (default equity_ratio)
+	Multiplier float64 `json:"multiplier,omitempty"` // Fixed multiplier, or extra factor on the equity ratio (default 1)
+
+	// Leader symbol → follower symbol overrides (other symbols are normalized for the follower's exchange)
+	SymbolMap map[string]string `json:"symbol_map,omitempty"`
+
+	// Follower risk caps (0 = no cap beyond the follower's strategy risk control)
+	MaxPositionUSD float64 `json:"max_position_usd,omitempty"` // Max notional of one mirrored open
+	MaxLeverage    int     `json:"max_leverage,omitempty"`     // Max leverage of mirrored opens
+	MaxMarginUsage float64 `json:"max_margin_usage,omitempty"` // Max account margin usage after an open (0-1)
+
+	// Leader decisions older than this are not mirrored (default 300s)
+	MaxDelaySeconds int `json:"max_delay_seconds,omitempty"`
+}
+
+// ParseFollowConfig parses the copy trading configuration (nil if the trader follows nobody)
+func (t *Trader) ParseFollowConfig() (*FollowConfig, error) {
+	if t.LeaderTraderID == "" {
+		return nil, nil
+	}
+	cfg := &FollowConfig{}
+	if t.FollowConfig != "" {
+		if err := json.Unmarshal([]byte(t.FollowConfig), cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse follow config: %w", err)
+		}
+	}
+	if cfg.SizingMode == "" {
+		cfg.SizingMode = FollowSizingEquityRatio
+	}
+	if cfg.Multiplier <= 0 {
+		cfg.Multiplier = 1
+	}
+	if cfg.MaxDelaySeconds <= 0 {
+		cfg.MaxDelaySeconds = 300
+	}
+	return cfg, nil
+}
+
 // TraderFullConfig trader full configuration (includes AI model, exchange and strategy)
 type TraderFullConfig struct {
 	Trader   *Trader
@@ -63,6 +112,8 @@ func (s *TraderStore) initTables() error {
 		var tableExists int64
 		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'traders'`).Scan(&tableExists)
 		if tableExists > 0 {
+			s.db.Exec(`ALTER TABLE traders ADD COLUMN IF NOT EXISTS leader_trader_id TEXT DEFAULT ''`)
+			s.db.Exec(`ALTER TABLE traders ADD COLUMN IF NOT EXISTS follow_config TEXT DEFAULT ''`)
 			return nil
 		}
 	}
@@ -151,6 +202,26 @@ func (s *TraderStore) UpdateCustomPrompt(userID, id string, customPrompt string,
 		}).Error
 }
 
+// UpdateFollow sets the leader and copy trading configuration of a trader (empty leader = stop following)
+func (s *TraderStore) UpdateFollow(userID, id, leaderTraderID, followConfig string) error {
+	return s.db.Model(&Trader{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"leader_trader_id": leaderTraderID,
+			"follow_config":    followConfig,
+		}).Error
+}
+
+// ListFollowers gets the traders following a leader
+func (s *TraderStore) ListFollowers(leaderTraderID string) ([]*Trader, error) {
+	var traders []*Trader
+	err := s.db.Where("leader_trader_id = ?", leaderTraderID).Find(&traders).Error
+	if err != nil {
+		return nil, err
+	}
+	return traders, nil
+}
+
 // Delete deletes trader and associated data
 func (s *TraderStore) Delete(userID, id string) error {
 	// Delete associated equity snapshots first
@@ -21,6 +21,7 @@ import (
 	"nofx/trader/okx"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -109,6 +110,10 @@ type AutoTraderConfig struct {
 
 	// Strategy configuration (use complete strategy config)
 	StrategyConfig *store.StrategyConfig // Strategy configuration (includes coin sources, indicators, risk control, prompts, etc.)
+
+	// Copy trading (empty LeaderTraderID = not following)
+	LeaderTraderID string              // Trader whose executed decisions are mirrored
+	FollowConfig   *store.FollowConfig // Sizing, symbol mapping and follower risk caps
 }
 
 // AutoTrader automatic trader
@@ -124,6 +129,7 @@ type AutoTrader struct {
 	mcpClient             mcp.AIClient
 	store                 *store.Store           // Data storage (decision records, etc.)
 	strategyEngine        *kernel.StrategyEngine // Strategy engine (uses strategy configuration)
+	strategy              kernel.Strategy        // Decision maker registered for the strategy type
 	cycleNumber           int                    // Current cycle number
 	initialBalance        float64
 	dailyPnL              float64
@@ -134,7 +140,7 @@ type AutoTrader struct {
 	isRunning             bool
 	isRunningMutex        sync.RWMutex       // Mutex to protect isRunning flag
 	startTime             time.Time          // System start time
-	callCount             int                // AI call count
+	callCount             atomic.Int64       // AI call count (read by GetStatus while a cycle runs)
 	positionFirstSeenTime map[string]int64   // Position first seen time (symbol_side -> timestamp in milliseconds)
 	stopMonitorCh         chan struct{}      // Used to stop monitoring goroutine
 	monitorWg             sync.WaitGroup     // Used to wait for monitoring goroutine to finish
@@ -143,7 +149,21 @@ type AutoTrader struct {
 	lastBalanceSyncTime   time.Time          // Last balance sync time
 	lastOpenTime          time.Time          // Last time a position was opened
 	userID                string             // User ID
-	gridState             *GridState         // Grid trading state (only used when StrategyType == "grid_trading")
+	gridState             *GridState         // Grid trading state (only used when StrategyType == "grid_trading"); the grid the cycle is running
+	gridStates            []*GridState       // All symbol grids sharing the grid capital (one entry unless grid_config.symbols is set)
+	dcaState              *DCAState          // DCA trading state (only used when StrategyType == "dca")
+	carryState            *FundingCarryState // Funding carry state (only used when StrategyType == "funding_carry")
+	pairsState            *PairsState        // Pairs trading state (only used when StrategyType == "pairs_trading")
+	rebalanceState        *RebalanceState    // Rebalance state (only used when StrategyType == "rebalance")
+	routerState           *RegimeRouterState // Regime router state (only used when StrategyType == "regime_router")
+	sleeveSet             *SleeveSet         // Strategy sleeves (only used when the trader has enabled sleeves)
+	stateMu               sync.RWMutex       // Guards routerState, sleeveSet and gridStates, which the cycle replaces while the API reads them
+	followState           *FollowState       // Copy trading state (only used when following a leader)
+	shadowSet             *ShadowSet         // Shadow strategies simulated next to the live strategy
+	shadowRunning         atomic.Bool        // A shadow cycle is running in the background
+	decisionScorer        *DecisionScorer    // Decision outcome scorer (nil when scoring disabled)
+	calibratedMinConf     int                // Dynamic min confidence from calibration (0 = use strategy config)
+	tradeReviewer         *TradeReviewer     // Periodic trade self-review (nil when review disabled)
 }
 
 // NewAutoTrader creates an automatic trader
@@ -246,57 +266,9 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	}
 	logger.Infof("📊 [%s] Position mode: %s", config.Name, marginModeStr)
 
-	switch config.Exchange {
-	case "binance":
-		logger.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
-		trader = binance.NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, userID)
-	case "bybit":
-		logger.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
-		trader = bybit.NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey)
-	case "okx":
-		logger.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
-		trader = okx.NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
-	case "bitget":
-		logger.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
-		trader = bitget.NewBitgetTrader(config.BitgetAPIKey, config.BitgetSecretKey, config.BitgetPassphrase)
-	case "gate":
-		logger.Infof("🏦 [%s] Using Gate.io Futures trading", config.Name)
-		trader = gate.NewGateTrader(config.GateAPIKey, config.GateSecretKey)
-	case "kucoin":
-		logger.Infof("🏦 [%s] Using KuCoin Futures trading", config.Name)
-		trader = kucoin.NewKuCoinTrader(config.KuCoinAPIKey, config.KuCoinSecretKey, config.KuCoinPassphrase)
-	case "hyperliquid":
-		logger.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
-		trader, err = hyperliquid.NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
-		}
-	case "aster":
-		logger.Infof("🏦 [%s] Using Aster trading", config.Name)
-		trader, err = aster.NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
-		}
-	case "lighter":
-		logger.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
-
-		if config.LighterWalletAddr == "" || config.LighterAPIKeyPrivateKey == "" {
-			return nil, fmt.Errorf("Lighter requires wallet address and API Key private key")
-		}
-
-		// Lighter only supports mainnet (testnet disabled)
-		trader, err = lighter.NewLighterTraderV2(
-			config.LighterWalletAddr,
-			config.LighterAPIKeyPrivateKey,
-			config.LighterAPIKeyIndex,
-			false, // Always use mainnet for Lighter
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize LIGHTER trader: %w", err)
-		}
-		logger.Infof("✓ LIGHTER trader initialized successfully")
-	default:
-		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
+	trader, err = newExchangeTrader(config, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate initial balance configuration, auto-fetch from exchange if 0
@@ -343,8 +315,36 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		return nil, fmt.Errorf("[%s] strategy not configured", config.Name)
 	}
 	strategyEngine := kernel.NewStrategyEngine(config.StrategyConfig)
+	if st != nil {
+		strategyEngine.SetMemoryStore(st.DecisionMemory())
+	}
 	logger.Infof("✓ [%s] Using strategy engine (strategy configuration loaded)", config.Name)
 
+	strategy, err := kernel.NewStrategy(kernel.StrategyEnv{
+		Config:   config.StrategyConfig,
+		Engine:   strategyEngine,
+		AIClient: mcpClient,
+		Variant:  "balanced",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to create strategy: %w", config.Name, err)
+	}
+
+	// Create decision scorer (optional)
+	var decisionScorer *DecisionScorer
+	if st != nil && config.StrategyConfig.Scoring != nil && config.StrategyConfig.Scoring.Enabled {
+		decisionScorer, err = NewDecisionScorer(st, config.ID, config.AIModel, *config.StrategyConfig.Scoring)
+		if err != nil {
+			logger.Warnf("⚠️ [%s] Decision scoring disabled: %v", config.Name, err)
+		}
+	}
+
+	// Create trade reviewer (optional)
+	var tradeReviewer *TradeReviewer
+	if st != nil && config.StrategyConfig.Review != nil && config.StrategyConfig.Review.Enabled {
+		tradeReviewer = NewTradeReviewer(st, config.ID, mcpClient, strategyEngine.GetLanguage(), *config.StrategyConfig.Review)
+	}
+
 	return &AutoTrader{
 		id:                    config.ID,
 		name:                  config.Name,
@@ -357,11 +357,11 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		mcpClient:             mcpClient,
 		store:                 st,
 		strategyEngine:        strategyEngine,
+		strategy:              strategy,
 		cycleNumber:           cycleNumber,
 		initialBalance:        config.InitialBalance,
 		lastResetTime:         time.Now(),
 		startTime:             time.Now(),
-		callCount:             0,
 		isRunning:             false,
 		positionFirstSeenTime: make(map[string]int64),
 		stopMonitorCh:         make(chan struct{}),
@@ -371,9 +371,112 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		lastBalanceSyncTime:   time.Now(),
 		lastOpenTime:          time.Now().Add(-config.MinOpenInterval), // Allow immediate opening
 		userID:                userID,
+		decisionScorer:        decisionScorer,
+		tradeReviewer:         tradeReviewer,
 	}, nil
 }
 
+// SetExchangeCredentials copies the credentials of an exchange account into the config
+// (EncryptedString values are converted to plain strings)
+func SetExchangeCredentials(cfg *AutoTraderConfig, ex *store.Exchange) {
+	switch ex.ExchangeType {
+	case "binance":
+		cfg.BinanceAPIKey = string(ex.APIKey)
+		cfg.BinanceSecretKey = string(ex.SecretKey)
+	case "bybit":
+		cfg.BybitAPIKey = string(ex.APIKey)
+		cfg.BybitSecretKey = string(ex.SecretKey)
+	case "okx":
+		cfg.OKXAPIKey = string(ex.APIKey)
+		cfg.OKXSecretKey = string(ex.SecretKey)
+		cfg.OKXPassphrase = string(ex.Passphrase)
+	case "bitget":
+		cfg.BitgetAPIKey = string(ex.APIKey)
+		cfg.BitgetSecretKey = string(ex.SecretKey)
+		cfg.BitgetPassphrase = string(ex.Passphrase)
+	case "gate":
+		cfg.GateAPIKey = string(ex.APIKey)
+		cfg.GateSecretKey = string(ex.SecretKey)
+	case "kucoin":
+		cfg.KuCoinAPIKey = string(ex.APIKey)
+		cfg.KuCoinSecretKey = string(ex.SecretKey)
+		cfg.KuCoinPassphrase = string(ex.Passphrase)
+	case "hyperliquid":
+		cfg.HyperliquidPrivateKey = string(ex.APIKey)
+		cfg.HyperliquidWalletAddr = ex.HyperliquidWalletAddr
+	case "aster":
+		cfg.AsterUser = ex.AsterUser
+		cfg.AsterSigner = ex.AsterSigner
+		cfg.AsterPrivateKey = string(ex.AsterPrivateKey)
+	case "lighter":
+		cfg.LighterPrivateKey = string(ex.LighterPrivateKey)
+		cfg.LighterWalletAddr = ex.LighterWalletAddr
+		cfg.LighterAPIKeyPrivateKey = string(ex.LighterAPIKeyPrivateKey)
+		cfg.LighterAPIKeyIndex = ex.LighterAPIKeyIndex
+		cfg.LighterTestnet = ex.Testnet
+	}
+}
+
+// newExchangeTrader creates the exchange trader selected by config.Exchange
+func newExchangeTrader(config AutoTraderConfig, userID string) (Trader, error) {
+	var trader Trader
+	var err error
+
+	switch config.Exchange {
+	case "binance":
+		logger.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
+		trader = binance.NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, userID)
+	case "bybit":
+		logger.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
+		trader = bybit.NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey)
+	case "okx":
+		logger.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
+		trader = okx.NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
+	case "bitget":
+		logger.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
+		trader = bitget.NewBitgetTrader(config.BitgetAPIKey, config.BitgetSecretKey, config.BitgetPassphrase)
+	case "gate":
+		logger.Infof("🏦 [%s] Using Gate.io Futures trading", config.Name)
+		trader = gate.NewGateTrader(config.GateAPIKey, config.GateSecretKey)
+	case "kucoin":
+		logger.Infof("🏦 [%s] Using KuCoin Futures trading", config.Name)
+		trader = kucoin.NewKuCoinTrader(config.KuCoinAPIKey, config.KuCoinSecretKey, config.KuCoinPassphrase)
+	case "hyperliquid":
+		logger.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
+		trader, err = hyperliquid.NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
+		}
+	case "aster":
+		logger.Infof("🏦 [%s] Using Aster trading", config.Name)
+		trader, err = aster.NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
+		}
+	case "lighter":
+		logger.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
+
+		if config.LighterWalletAddr == "" || config.LighterAPIKeyPrivateKey == "" {
+			return nil, fmt.Errorf("Lighter requires wallet address and API Key private key")
+		}
+
+		// Lighter only supports mainnet (testnet disabled)
+		trader, err = lighter.NewLighterTraderV2(
+			config.LighterWalletAddr,
+			config.LighterAPIKeyPrivateKey,
+			config.LighterAPIKeyIndex,
+			false, // Always use mainnet for Lighter
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize LIGHTER trader: %w", err)
+		}
+		logger.Infof("✓ LIGHTER trader initialized successfully")
+	default:
+		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
+	}
+	return trader, nil
+}
+
 // Run runs the automatic trading main loop
 func (at *AutoTrader) Run() error {
 	at.isRunningMutex.Lock()
@@ -390,6 +493,12 @@ func (at *AutoTrader) Run() error {
 	at.monitorWg.Add(1)
 	defer at.monitorWg.Done()
 
+	if at.strategy != nil {
+		if err := at.strategy.Init(); err != nil {
+			return fmt.Errorf("strategy initialization failed: %w", err)
+		}
+	}
+
 	// Start drawdown monitoring
 	at.startDrawdownMonitor()
 
@@ -468,25 +577,15 @@ func (at *AutoTrader) Run() error {
 	ticker := time.NewTicker(at.config.ScanInterval)
 	defer ticker.Stop()
 
-	// Check if this is a grid trading strategy
-	isGridStrategy := at.IsGridStrategy()
-	if isGridStrategy {
-		logger.Infof("🔲 [%s] Grid trading strategy detected, initializing grid...", at.name)
-		if err := at.InitializeGrid(); err != nil {
-			logger.Errorf("❌ [%s] Failed to initialize grid: %v", at.name, err)
-			return fmt.Errorf("grid initialization failed: %w", err)
-		}
+	// Select the cycle of the strategy type
+	runCycle, failMsg, err := at.selectCycle()
+	if err != nil {
+		return err
 	}
 
 	// Execute immediately on first run
-	if isGridStrategy {
-		if err := at.RunGridCycle(); err != nil {
-			logger.Infof("❌ Grid execution failed: %v", err)
-		}
-	} else {
-		if err := at.runCycle(); err != nil {
-			logger.Infof("❌ Execution failed: %v", err)
-		}
+	if err := runCycle(); err != nil {
+		logger.Infof("❌ %s: %v", failMsg, err)
 	}
 
 	for {
@@ -500,14 +599,8 @@ func (at *AutoTrader) Run() error {
 
 		select {
 		case <-ticker.C:
-			if isGridStrategy {
-				if err := at.RunGridCycle(); err != nil {
-					logger.Infof("❌ Grid execution failed: %v", err)
-				}
-			} else {
-				if err := at.runCycle(); err != nil {
-					logger.Infof("❌ Execution failed: %v", err)
-				}
+			if err := runCycle(); err != nil {
+				logger.Infof("❌ %s: %v", failMsg, err)
 			}
 		case <-at.stopMonitorCh:
 			logger.Infof("[%s] ⏹ Stop signal received, exiting automatic trading main loop", at.name)
@@ -518,6 +611,69 @@ func (at *AutoTrader) Run() error {
 	return nil
 }
 
+// selectCycle initializes the configured strategy type and returns its cycle
+func (at *AutoTrader) selectCycle() (func() error, string, error) {
+	switch {
+	case at.IsFollower():
+		logger.Infof("👥 [%s] Follower of trader %s, restoring copy trading cursor...", at.name, at.config.LeaderTraderID)
+		if err := at.InitializeFollower(); err != nil {
+			logger.Errorf("❌ [%s] Failed to initialize follower: %v", at.name, err)
+			return nil, "", fmt.Errorf("follower initialization failed: %w", err)
+		}
+		return at.RunFollowerCycle, "Follower execution failed", nil
+	case at.HasSleeves():
+		logger.Infof("🧩 [%s] Strategy sleeves detected, loading sleeve strategies...", at.name)
+		if err := at.InitializeSleeves(); err != nil {
+			logger.Errorf("❌ [%s] Failed to initialize sleeves: %v", at.name, err)
+			return nil, "", fmt.Errorf("sleeves initialization failed: %w", err)
+		}
+		return at.RunSleevesCycle, "Sleeves execution failed", nil
+	}
+	return at.selectStrategyCycle()
+}
+
+// StrategyCycle executor of a strategy type that manages its own orders (grids, deals, multi-leg
+// positions) instead of having its decisions executed by the generic cycle
+type StrategyCycle struct {
+	Name string                     // Shown in logs, e.g. "DCA"
+	Init func(at *AutoTrader) error // Restores the executor state before the first cycle
+	Run  func(at *AutoTrader) error // Runs one cycle
+}
+
+var (
+	strategyCyclesMu sync.RWMutex
+	strategyCycles   = map[string]StrategyCycle{}
+)
+
+// RegisterStrategyCycle registers the cycle of a strategy type registered with
+// kernel.RegisterStrategy (a later registration replaces an earlier one)
+func RegisterStrategyCycle(strategyType string, cycle StrategyCycle) {
+	if strategyType == "" || cycle.Init == nil || cycle.Run == nil {
+		panic("trader: RegisterStrategyCycle requires a strategy type, Init and Run")
+	}
+	strategyCyclesMu.Lock()
+	defer strategyCyclesMu.Unlock()
+	strategyCycles[strategyType] = cycle
+}
+
+// selectStrategyCycle initializes a single strategy type (also the one routed by the regime router);
+// types without a registered cycle run the generic decision cycle
+func (at *AutoTrader) selectStrategyCycle() (func() error, string, error) {
+	strategyCyclesMu.RLock()
+	cycle, ok := strategyCycles[at.strategyType()]
+	strategyCyclesMu.RUnlock()
+	if !ok {
+		return at.runCycle, "Execution failed", nil
+	}
+
+	logger.Infof("🧩 [%s] %s strategy detected, initializing...", at.name, cycle.Name)
+	if err := cycle.Init(at); err != nil {
+		logger.Errorf("❌ [%s] Failed to initialize %s: %v", at.name, cycle.Name, err)
+		return nil, "", fmt.Errorf("%s initialization failed: %w", cycle.Name, err)
+	}
+	return func() error { return cycle.Run(at) }, cycle.Name + " execution failed", nil
+}
+
 // Stop stops the automatic trading
 func (at *AutoTrader) Stop() {
 	at.isRunningMutex.Lock()
@@ -530,15 +686,25 @@ func (at *AutoTrader) Stop() {
 
 	close(at.stopMonitorCh) // Notify monitoring goroutine to stop
 	at.monitorWg.Wait()     // Wait for monitoring goroutine to finish
+	if at.strategy != nil {
+		if err := at.strategy.Close(); err != nil {
+			logger.Warnf("⚠️ [%s] Failed to close strategy: %v", at.name, err)
+		}
+	}
+	if routed := at.routedStrategy(); routed != nil {
+		if err := routed.Strategy.Close(); err != nil {
+			logger.Warnf("⚠️ [%s] Failed to close routed strategy: %v", at.name, err)
+		}
+	}
 	logger.Info("⏹ Automatic trading system stopped")
 }
 
 // runCycle runs one trading cycle (using AI full decision-making)
 func (at *AutoTrader) runCycle() error {
-	at.callCount++
+	cycle := at.callCount.Add(1)
 
 	logger.Info("\n" + strings.Repeat("=", 70) + "\n")
-	logger.Infof("⏰ %s - AI decision cycle #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
+	logger.Infof("⏰ %s - AI decision cycle #%d", time.Now().Format("2006-01-02 15:04:05"), cycle)
 	logger.Info(strings.Repeat("=", 70))
 
 	// 0. Check if trader is stopped (early exit to prevent trades after Stop() is called)
@@ -546,7 +712,7 @@ func (at *AutoTrader) runCycle() error {
 	running := at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
-		logger.Infof("⏹ Trader is stopped, aborting cycle #%d", at.callCount)
+		logger.Infof("⏹ Trader is stopped, aborting cycle #%d", cycle)
 		return nil
 	}
 
@@ -602,6 +768,9 @@ func (at *AutoTrader) runCycle() error {
 		return nil
 	}
 
+	// Shadow strategies decide on the same context once the live cycle is done
+	defer at.startShadowCycle(ctx)
+
 	logger.Info(strings.Repeat("=", 70))
 	for _, coin := range ctx.CandidateCoins {
 		record.CandidateCoins = append(record.CandidateCoins, coin.Symbol)
@@ -610,9 +779,9 @@ func (at *AutoTrader) runCycle() error {
 	logger.Infof("📊 Account equity: %.2f USDT | Available: %.2f USDT | Positions: %d",
 		ctx.Account.TotalEquity, ctx.Account.AvailableBalance, ctx.Account.PositionCount)
 
-	// 5. Use strategy engine to call AI for decision
-	logger.Infof("🤖 Requesting AI analysis and decision... [Strategy Engine]")
-	aiDecision, err := kernel.GetFullDecisionWithStrategy(ctx, at.mcpClient, at.strategyEngine, "balanced")
+	// 5. Ask the registered strategy for decisions (AI, rules or custom)
+	logger.Infof("🤖 Requesting decision... [%s strategy]", at.strategyType())
+	aiDecision, err := kernel.RunStrategy(at.runningStrategy().Strategy, ctx)
 
 	if aiDecision != nil && aiDecision.AIRequestDurationMs > 0 {
 		record.AIRequestDurationMs = aiDecision.AIRequestDurationMs
@@ -703,7 +872,7 @@ func (at *AutoTrader) runCycle() error {
 	running = at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
-		logger.Infof("⏹ Trader stopped before decision execution, aborting cycle #%d", at.callCount)
+		logger.Infof("⏹ Trader stopped before decision execution, aborting cycle #%d", cycle)
 		return nil
 	}
 
@@ -719,17 +888,18 @@ func (at *AutoTrader) runCycle() error {
 		}
 
 		actionRecord := store.DecisionAction{
-			Action:     d.Action,
-			Symbol:     d.Symbol,
-			Quantity:   0,
-			Leverage:   d.Leverage,
-			Price:      0,
-			StopLoss:   d.StopLoss,
-			TakeProfit: d.TakeProfit,
-			Confidence: d.Confidence,
-			Reasoning:  d.Reasoning,
-			Timestamp:  time.Now().UTC(),
-			Success:    false,
+			Action:          d.Action,
+			Symbol:          d.Symbol,
+			Quantity:        0,
+			Leverage:        d.Leverage,
+			Price:           0,
+			StopLoss:        d.StopLoss,
+			TakeProfit:      d.TakeProfit,
+			ClosePercentage: d.ClosePercentage,
+			Confidence:      d.Confidence,
+			Reasoning:       d.Reasoning,
+			Timestamp:       time.Now().UTC(),
+			Success:         false,
 		}
 
 		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
@@ -746,48 +916,18 @@ func (at *AutoTrader) runCycle() error {
 		record.Decisions = append(record.Decisions, actionRecord)
 	}
 
-	// 9. Save register record (寄存器记录)
-	if at.strategyEngine != nil {
-		config := at.strategyEngine.GetConfig()
-		if config.Register.Enabled && config.Register.MaxRecords > 0 {
-			// 创建寄存器配置
-			registerConfig := kernel.RegisterConfig{
-				Enabled:           config.Register.Enabled,
-				MaxRecords:        config.Register.MaxRecords,
-				IncludeDecisions:  config.Register.IncludeDecisions,
-				IncludeMarketData: config.Register.IncludeMarketData,
-			}
-
-			// 创建寄存器实例
-			register := kernel.NewRegister(at.id, registerConfig)
-
-			// 创建寄存器记录
-			executionStatus := "success"
-			if !record.Success {
-				executionStatus = "failed"
+	// 9. Save opening setups to decision memory (outcomes are linked once positions close)
+	if at.store != nil && at.runningStrategy().Engine.GetConfig().Register.Enabled {
+		for i, d := range sortedDecisions {
+			if i >= len(record.Decisions) || !record.Decisions[i].Success {
+				continue
 			}
-
-			// 构建简化的决策记录
-			var registerDecisions []kernel.Decision
-			for _, d := range aiDecision.Decisions {
-				// Populate EntryPrice from current position context if available
-				for _, posInfo := range ctx.Positions {
-					if posInfo.Symbol == d.Symbol {
-						d.EntryPrice = posInfo.EntryPrice
-						break
-					}
-				}
-				registerDecisions = append(registerDecisions, d)
+			memory := kernel.NewDecisionMemory(ctx, &d)
+			if memory == nil {
+				continue
 			}
-
-			// 创建寄存器记录
-			registerRecord := kernel.CreateRecordFromContext(ctx, registerDecisions, executionStatus)
-
-			// 保存到寄存器
-			if err := register.SaveRecord(registerRecord); err != nil {
-				logger.Infof("⚠ Failed to save register record: %v", err)
-			} else {
-				logger.Infof("✓ Register record saved successfully")
+			if err := at.store.DecisionMemory().Record(memory); err != nil {
+				logger.Infof("⚠ Failed to save decision memory (%s %s): %v", d.Symbol, d.Action, err)
 			}
 		}
 	}
@@ -800,6 +940,79 @@ func (at *AutoTrader) runCycle() error {
 	return nil
 }
 
+// strategyType returns the configured strategy type (default: ai_trading)
+func (at *AutoTrader) strategyType() string {
+	cfg := at.strategyConfig()
+	if cfg == nil || cfg.StrategyType == "" {
+		return kernel.DefaultStrategyType
+	}
+	return cfg.StrategyType
+}
+
+// loadedStrategy a strategy loaded from the store, ready to take over (part of) the trader
+type loadedStrategy struct {
+	ID       string
+	Name     string
+	Config   *store.StrategyConfig
+	Engine   *kernel.StrategyEngine
+	Strategy kernel.Strategy
+}
+
+// traderStrategy returns the trader's strategy: the one routed by the regime router, else its own
+func (at *AutoTrader) traderStrategy() *loadedStrategy {
+	if routed := at.routedStrategy(); routed != nil {
+		return routed
+	}
+	return &loadedStrategy{Config: at.config.StrategyConfig, Engine: at.strategyEngine, Strategy: at.strategy}
+}
+
+// runningStrategy returns the strategy of the running cycle: the active sleeve's, else the
+// trader's. Neither the router nor sleeves replace the trader's own strategy fields, so this is
+// safe to call from the API and monitor goroutines.
+func (at *AutoTrader) runningStrategy() *loadedStrategy {
+	if r := at.activeSleeve(); r != nil {
+		return r.Strategy
+	}
+	return at.traderStrategy()
+}
+
+// strategyConfig returns the configuration of the running strategy
+func (at *AutoTrader) strategyConfig() *store.StrategyConfig {
+	return at.runningStrategy().Config
+}
+
+// loadUserStrategy loads a strategy of the trader's user and creates its decision maker (used by
+// the regime router and strategy sleeves)
+func (at *AutoTrader) loadUserStrategy(strategyID string) (*loadedStrategy, error) {
+	st, err := at.store.Strategy().Get(at.userID, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found: %w", err)
+	}
+	cfg, err := st.ParseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy config: %w", err)
+	}
+	if cfg.StrategyType == "regime_router" {
+		return nil, fmt.Errorf("a regime router cannot run inside another strategy")
+	}
+
+	engine := kernel.NewStrategyEngine(cfg)
+	engine.SetMemoryStore(at.store.DecisionMemory())
+	strategy, err := kernel.NewStrategy(kernel.StrategyEnv{
+		Config:   cfg,
+		Engine:   engine,
+		AIClient: at.mcpClient,
+		Variant:  "balanced",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := strategy.Init(); err != nil {
+		return nil, fmt.Errorf("strategy initialization failed: %w", err)
+	}
+	return &loadedStrategy{ID: st.ID, Name: st.Name, Config: cfg, Engine: engine, Strategy: strategy}, nil
+}
+
 // buildTradingContext builds trading context
 func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	// 1. Get account information
@@ -946,10 +1159,11 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 
 	// 3. Use strategy engine to get candidate coins (must have strategy engine)
 	var candidateCoins []kernel.CandidateCoin
-	if at.strategyEngine == nil {
+	engine := at.runningStrategy().Engine
+	if engine == nil {
 		logger.Infof("⚠️ [%s] No strategy engine configured, skipping candidate coins", at.name)
 	} else {
-		coins, err := at.strategyEngine.GetCandidateCoins()
+		coins, err := engine.GetCandidateCoins()
 		if err != nil {
 			// Log warning but don't fail - equity snapshot should still be saved
 			logger.Infof("⚠️ [%s] Failed to get candidate coins: %v (will use empty list)", at.name, err)
@@ -972,7 +1186,7 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	}
 
 	// 5. Get leverage from strategy config
-	strategyConfig := at.strategyEngine.GetConfig()
+	strategyConfig := engine.GetConfig()
 	btcEthLeverage := strategyConfig.RiskControl.BTCETHMaxLeverage
 	altcoinLeverage := strategyConfig.RiskControl.AltcoinMaxLeverage
 	logger.Infof("📋 [%s] Strategy leverage config: BTC/ETH=%dx, Altcoin=%dx", at.name, btcEthLeverage, altcoinLeverage)
@@ -982,7 +1196,7 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 		TraderID:        at.id,
 		CurrentTime:     time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
 		RuntimeMinutes:  int(time.Since(at.startTime).Minutes()),
-		CallCount:       at.callCount,
+		CallCount:       int(at.callCount.Load()),
 		BTCETHLeverage:  btcEthLeverage,
 		AltcoinLeverage: altcoinLeverage,
 		Account: kernel.AccountInfo{
@@ -1031,6 +1245,13 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 				})
 			}
 		}
+		// Link closed positions back to the setups that opened them (decision memory)
+		if resolved, err := at.store.DecisionMemory().ResolveOutcomes(at.id); err != nil {
+			logger.Infof("⚠️ [%s] Failed to resolve decision memory outcomes: %v", at.name, err)
+		} else if resolved > 0 {
+			logger.Infof("🧠 [%s] Resolved %d decision memory outcomes", at.name, resolved)
+		}
+
 		// Get trading statistics for AI context
 		stats, err := at.store.Position().GetFullStats(at.id)
 		if err != nil {
@@ -1057,6 +1278,12 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 		logger.Infof("⚠️ [%s] Store is nil, cannot get recent trades", at.name)
 	}
 
+	// 7.5 Score past decisions and attach calibration (if enabled in strategy config)
+	at.updateDecisionCalibration(ctx)
+
+	// 7.6 Load lessons from trade self-reviews (if enabled in strategy config)
+	at.updateTradeLessons()
+
 	// 8. Get quantitative data (if enabled in strategy config)
 	if strategyConfig.Indicators.EnableQuantData {
 		// Collect symbols to query (candidate coins + position coins)
@@ -1074,14 +1301,14 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 		}
 
 		logger.Infof("📊 [%s] Fetching quantitative data for %d symbols...", at.name, len(symbols))
-		ctx.QuantDataMap = at.strategyEngine.FetchQuantDataBatch(symbols)
+		ctx.QuantDataMap = engine.FetchQuantDataBatch(symbols)
 		logger.Infof("📊 [%s] Successfully fetched quantitative data for %d symbols", at.name, len(ctx.QuantDataMap))
 	}
 
 	// 9. Get OI ranking data (market-wide position changes)
 	if strategyConfig.Indicators.EnableOIRanking {
 		logger.Infof("📊 [%s] Fetching OI ranking data...", at.name)
-		ctx.OIRankingData = at.strategyEngine.FetchOIRankingData()
+		ctx.OIRankingData = engine.FetchOIRankingData()
 		if ctx.OIRankingData != nil {
 			logger.Infof("📊 [%s] OI ranking data ready: %d top, %d low positions",
 				at.name, len(ctx.OIRankingData.TopPositions), len(ctx.OIRankingData.LowPositions))
@@ -1091,7 +1318,7 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	// 10. Get NetFlow ranking data (market-wide fund flow)
 	if strategyConfig.Indicators.EnableNetFlowRanking {
 		logger.Infof("💰 [%s] Fetching NetFlow ranking data...", at.name)
-		ctx.NetFlowRankingData = at.strategyEngine.FetchNetFlowRankingData()
+		ctx.NetFlowRankingData = engine.FetchNetFlowRankingData()
 		if ctx.NetFlowRankingData != nil {
 			logger.Infof("💰 [%s] NetFlow ranking data ready: inst_in=%d, inst_out=%d",
 				at.name, len(ctx.NetFlowRankingData.InstitutionFutureTop), len(ctx.NetFlowRankingData.InstitutionFutureLow))
@@ -1101,23 +1328,41 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	// 11. Get Price ranking data (market-wide gainers/losers)
 	if strategyConfig.Indicators.EnablePriceRanking {
 		logger.Infof("📈 [%s] Fetching Price ranking data...", at.name)
-		ctx.PriceRankingData = at.strategyEngine.FetchPriceRankingData()
+		ctx.PriceRankingData = engine.FetchPriceRankingData()
 		if ctx.PriceRankingData != nil {
 			logger.Infof("📈 [%s] Price ranking data ready for %d durations",
 				at.name, len(ctx.PriceRankingData.Durations))
 		}
 	}
 
+	// 12. Limit the context to the running sleeve (positions and capital it owns)
+	at.applySleeveScope(ctx)
+
 	return ctx, nil
 }
 
 // executeDecisionWithRecord executes AI decision and records detailed information
 func (at *AutoTrader) executeDecisionWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
+	return at.executeDecision(decision, actionRecord, false)
+}
+
+// executeDecision executes a decision; mirrored decisions copy a leader's trade and follow its
+// timing instead of the minimum open interval
+func (at *AutoTrader) executeDecision(decision *kernel.Decision, actionRecord *store.DecisionAction, mirrored bool) (err error) {
+	if err := at.checkSleeveDecision(decision); err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			at.claimSleevePosition(decision)
+		}
+	}()
+
 	switch decision.Action {
 	case "open_long":
-		return at.executeOpenLongWithRecord(decision, actionRecord)
+		return at.executeOpenLongWithRecord(decision, actionRecord, mirrored)
 	case "open_short":
-		return at.executeOpenShortWithRecord(decision, actionRecord)
+		return at.executeOpenShortWithRecord(decision, actionRecord, mirrored)
 	case "close_long":
 		return at.executeCloseLongWithRecord(decision, actionRecord)
 	case "close_short":
@@ -1262,6 +1507,13 @@ func (at *AutoTrader) updatePositionStops(symbol string, side string, quantity f
 func (at *AutoTrader) ExecuteDecision(d *kernel.Decision) error {
 	logger.Infof("[%s] Executing external decision: %s %s", at.name, d.Action, d.Symbol)
 
+	// Let the strategy veto or adjust decisions made outside its own cycle
+	if reviewer, ok := at.runningStrategy().Strategy.(kernel.DecisionReviewer); ok {
+		if err := reviewer.ReviewDecision(d); err != nil {
+			return fmt.Errorf("rejected by %s strategy: %w", at.strategyType(), err)
+		}
+	}
+
 	// Create a minimal action record for tracking
 	actionRecord := &store.DecisionAction{
 		Symbol:     d.Symbol,
@@ -1285,11 +1537,17 @@ func (at *AutoTrader) ExecuteDecision(d *kernel.Decision) error {
 }
 
 // executeOpenLongWithRecord executes open long position and records detailed information
-func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
+func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction, mirrored bool) error {
 	logger.Infof("  📈 Open long: %s", decision.Symbol)
 
+	// [CODE ENFORCED] Calibrated min confidence (a mirrored open carries the leader's confidence,
+	// which passed the leader's own calibration)
+	if err := at.enforceMinConfidence(decision.Confidence, mirrored); err != nil {
+		return err
+	}
+
 	// [RATE LIMIT] Check minimum time interval between opening positions
-	if time.Since(at.lastOpenTime) < at.config.MinOpenInterval {
+	if !mirrored && time.Since(at.lastOpenTime) < at.config.MinOpenInterval {
 		msg := fmt.Sprintf("⚠️ Rate limit: skipping open long for %s (last open was %v ago, min interval %v)",
 			decision.Symbol, time.Since(at.lastOpenTime).Round(time.Second), at.config.MinOpenInterval)
 		logger.Warn(msg)
@@ -1444,9 +1702,15 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 }
 
 // executeOpenShortWithRecord executes open short position and records detailed information
-func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
+func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction, mirrored bool) error {
 	logger.Infof("  📉 Open short: %s", decision.Symbol)
 
+	// [CODE ENFORCED] Calibrated min confidence (a mirrored open carries the leader's confidence,
+	// which passed the leader's own calibration)
+	if err := at.enforceMinConfidence(decision.Confidence, mirrored); err != nil {
+		return err
+	}
+
 	// ⚠️ Get current positions for multiple checks
 	positions, err := at.trader.GetPositions()
 	if err != nil {
@@ -1851,8 +2115,8 @@ func (at *AutoTrader) SetOverrideBasePrompt(override bool) {
 
 // GetSystemPromptTemplate gets current system prompt template name (from strategy config)
 func (at *AutoTrader) GetSystemPromptTemplate() string {
-	if at.strategyEngine != nil {
-		config := at.strategyEngine.GetConfig()
+	if engine := at.runningStrategy().Engine; engine != nil {
+		config := engine.GetConfig()
 		if config.CustomPrompt != "" {
 			return "custom"
 		}
@@ -1865,6 +2129,10 @@ func (at *AutoTrader) saveEquitySnapshot(ctx *kernel.Context) {
 	if at.store == nil || ctx == nil {
 		return
 	}
+	if at.activeSleeve() != nil {
+		at.saveSleeveEquity(ctx)
+		return
+	}
 
 	snapshot := &store.EquitySnapshot{
 		TraderID:      at.id,
@@ -1890,6 +2158,9 @@ func (at *AutoTrader) saveDecision(record *store.DecisionRecord) error {
 	at.cycleNumber++
 	record.CycleNumber = at.cycleNumber
 	record.TraderID = at.id
+	if r := at.activeSleeve(); r != nil {
+		record.ExecutionLog = append([]string{fmt.Sprintf("Sleeve: %s (%s)", r.Sleeve.Name, r.Strategy.Name)}, record.ExecutionLog...)
+	}
 
 	if record.Timestamp.IsZero() {
 		record.Timestamp = time.Now().UTC()
@@ -1928,7 +2199,7 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		"is_running":      isRunning,
 		"start_time":      at.startTime.Format(time.RFC3339),
 		"runtime_minutes": int(time.Since(at.startTime).Minutes()),
-		"call_count":      at.callCount,
+		"call_count":      at.callCount.Load(),
 		"initial_balance": at.initialBalance,
 		"scan_interval":   at.config.ScanInterval.String(),
 		"stop_until":      at.stopUntil.Format(time.RFC3339),
@@ -1937,10 +2208,14 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 	}
 
 	// Add strategy info
-	if at.config.StrategyConfig != nil {
-		result["strategy_type"] = at.config.StrategyConfig.StrategyType
-		if at.config.StrategyConfig.GridConfig != nil {
-			result["grid_symbol"] = at.config.StrategyConfig.GridConfig.Symbol
+	// The trader's strategy, not the sleeve of a running cycle
+	if cfg := at.traderStrategy().Config; cfg != nil {
+		result["strategy_type"] = cfg.StrategyType
+		if cfg.GridConfig != nil {
+			result["grid_symbol"] = cfg.GridConfig.Symbol
+			if kernel.IsMultiSymbolGrid(cfg.GridConfig) {
+				result["grid_symbols"] = kernel.GridSymbolList(cfg.GridConfig)
+			}
 		}
 	}
 
@@ -2469,6 +2744,78 @@ func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string,
 	}
 }
 
+// recordLinkedPosition records one leg of a multi-leg position (e.g. a funding carry pair) under groupID and
+// returns its position ID. Legs on the trader's own exchange are recorded by OrderSync, so the synced
+// record is linked instead; 0 means it has not been synced yet and linking is retried later. Until then a
+// leg just ordered (orderID set) is kept as a pending link so the group is not lost on a restart.
+func (at *AutoTrader) recordLinkedPosition(exchangeID, exchangeType, symbol, side string, quantity, price float64, leverage int, orderID, source, groupID string) int64 {
+	if at.store == nil {
+		return 0
+	}
+	nowMs := time.Now().UTC().UnixMilli()
+	if exchangeID == at.exchangeID {
+		id, err := at.store.Position().LinkOpenPosition(at.id, exchangeID, symbol, side, source, groupID)
+		if err != nil {
+			logger.Warnf("  ⚠️ Failed to link %s %s position to %s: %v", symbol, side, groupID, err)
+		}
+		if id == 0 && orderID != "" {
+			pending := &store.TraderPosition{
+				TraderID:     at.id,
+				ExchangeID:   exchangeID,
+				ExchangeType: exchangeType,
+				Symbol:       symbol,
+				Side:         strings.ToUpper(side),
+				Quantity:     quantity,
+				EntryPrice:   price,
+				EntryOrderID: orderID,
+				EntryTime:    nowMs,
+				Leverage:     leverage,
+				Source:       source,
+				GroupID:      groupID,
+				CreatedAt:    nowMs,
+				UpdatedAt:    nowMs,
+			}
+			if err := at.store.Position().CreatePendingLink(pending); err != nil {
+				logger.Warnf("  ⚠️ Failed to record pending %s %s leg of %s: %v", symbol, side, groupID, err)
+			}
+		}
+		return id
+	}
+
+	pos := &store.TraderPosition{
+		TraderID:     at.id,
+		ExchangeID:   exchangeID,
+		ExchangeType: exchangeType,
+		Symbol:       symbol,
+		Side:         strings.ToUpper(side),
+		Quantity:     quantity,
+		EntryPrice:   price,
+		EntryOrderID: orderID,
+		EntryTime:    nowMs,
+		Leverage:     leverage,
+		Source:       source,
+		GroupID:      groupID,
+		CreatedAt:    nowMs,
+		UpdatedAt:    nowMs,
+	}
+	if err := at.store.Position().Create(pos); err != nil {
+		logger.Warnf("  ⚠️ Failed to record %s %s position of %s: %v", symbol, side, groupID, err)
+		return 0
+	}
+	return pos.ID
+}
+
+// closeLinkedPosition closes the record of a leg recorded by recordLinkedPosition
+// (records on the trader's own exchange are closed by OrderSync)
+func (at *AutoTrader) closeLinkedPosition(positionID int64, exchangeID string, exitPrice float64, orderID string, realizedPnL float64, reason string) {
+	if at.store == nil || positionID == 0 || exchangeID == at.exchangeID {
+		return
+	}
+	if err := at.store.Position().ClosePosition(positionID, exitPrice, orderID, realizedPnL, 0, reason); err != nil {
+		logger.Warnf("  ⚠️ Failed to close position record %d: %v", positionID, err)
+	}
+}
+
 // createOrderRecord creates an order record struct from order details
 func (at *AutoTrader) createOrderRecord(orderID, symbol, action, positionSide string, quantity, price float64, leverage int) *store.TraderOrder {
 	// Determine order type (market for auto trader)
@@ -2600,11 +2947,12 @@ func isBTCETH(symbol string) bool {
 // equity: the account equity
 // symbol: the trading symbol
 func (at *AutoTrader) enforcePositionValueRatio(positionSizeUSD float64, equity float64, symbol string) (float64, bool) {
-	if at.config.StrategyConfig == nil {
+	cfg := at.strategyConfig()
+	if cfg == nil {
 		return positionSizeUSD, false
 	}
 
-	riskControl := at.config.StrategyConfig.RiskControl
+	riskControl := cfg.RiskControl
 
 	// Get the appropriate position value ratio limit
 	var maxPositionValueRatio float64
@@ -2635,11 +2983,12 @@ func (at *AutoTrader) enforcePositionValueRatio(positionSizeUSD float64, equity
 
 // enforceMinPositionSize checks minimum position size (CODE ENFORCED)
 func (at *AutoTrader) enforceMinPositionSize(positionSizeUSD float64) error {
-	if at.config.StrategyConfig == nil {
+	cfg := at.strategyConfig()
+	if cfg == nil {
 		return nil
 	}
 
-	minSize := at.config.StrategyConfig.RiskControl.MinPositionSize
+	minSize := cfg.RiskControl.MinPositionSize
 	if minSize <= 0 {
 		minSize = 12 // Default: 12 USDT
 	}
@@ -2652,11 +3001,12 @@ func (at *AutoTrader) enforceMinPositionSize(positionSizeUSD float64) error {
 
 // enforceMaxPositions checks maximum positions count (CODE ENFORCED)
 func (at *AutoTrader) enforceMaxPositions(currentPositionCount int) error {
-	if at.config.StrategyConfig == nil {
+	cfg := at.strategyConfig()
+	if cfg == nil {
 		return nil
 	}
 
-	maxPositions := at.config.StrategyConfig.RiskControl.MaxPositions
+	maxPositions := cfg.RiskControl.MaxPositions
 	if maxPositions <= 0 {
 		maxPositions = 3 // Default: 3 positions
 	}
@@ -2667,6 +3017,19 @@ func (at *AutoTrader) enforceMaxPositions(currentPositionCount int) error {
 	return nil
 }
 
+// enforceMinConfidence rejects openings below the calibrated min confidence (CODE ENFORCED)
+// Only active when dynamic min confidence is enabled in the scoring config; mirrored leader opens
+// are exempt
+func (at *AutoTrader) enforceMinConfidence(confidence int, mirrored bool) error {
+	if mirrored || at.calibratedMinConf <= 0 {
+		return nil
+	}
+	if confidence < at.calibratedMinConf {
+		return fmt.Errorf("❌ [RISK CONTROL] Confidence %d below calibrated minimum (%d)", confidence, at.calibratedMinConf)
+	}
+	return nil
+}
+
 // getSideFromAction converts order action to side (BUY/SELL)
 func getSideFromAction(action string) string {
 	switch action {
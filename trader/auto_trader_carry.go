@@ -0,0 +1,682 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Funding Carry State Management
+// ============================================================================
+
+// fundingCarrySource position source of funding carry legs
+const fundingCarrySource = "funding_carry"
+
+// carrySpotSuffix suffix of the venue ID of the spot account of the trader's own exchange
+const carrySpotSuffix = ":spot"
+
+// carryVenue one exchange account used by the funding carry strategy
+type carryVenue struct {
+	exchangeID string
+	exchange   string // Exchange type (binance, bybit, ...)
+	trader     Trader
+	spot       SpotTrader // Set on the spot venue (spot hedge), nil for perpetual venues
+}
+
+// open opens a leg on the venue (the spot venue buys the asset and cannot hold a short leg)
+func (v *carryVenue) open(symbol, side string, quantity float64, leverage int) (map[string]interface{}, error) {
+	switch {
+	case v.spot != nil && side == "long":
+		return v.spot.SpotBuy(symbol, quantity)
+	case v.spot != nil:
+		return nil, fmt.Errorf("spot venue cannot hold a short leg")
+	case side == "long":
+		return v.trader.OpenLong(symbol, quantity, leverage)
+	default:
+		return v.trader.OpenShort(symbol, quantity, leverage)
+	}
+}
+
+// close closes a leg on the venue; the spot venue sells quantity, capped at the free balance
+// (fees paid in the asset leave less than was bought)
+func (v *carryVenue) close(symbol, side string, quantity float64) (map[string]interface{}, error) {
+	switch {
+	case v.spot != nil:
+		balance, err := v.spot.GetSpotBalance(symbol)
+		if err != nil {
+			return nil, err
+		}
+		return v.spot.SpotSell(symbol, math.Min(quantity, balance))
+	case side == "long":
+		return v.trader.CloseLong(symbol, 0)
+	default:
+		return v.trader.CloseShort(symbol, 0)
+	}
+}
+
+// fill returns the fill of an order placed on the venue (spot market orders report it in the response)
+func (v *carryVenue) fill(symbol string, order map[string]interface{}, quantity, price float64) orderFill {
+	if v.spot == nil {
+		return confirmOrderFill(v.trader, symbol, order, quantity, price)
+	}
+	fill := orderFill{OrderID: orderIDOf(order), Price: price, Quantity: quantity}
+	if avgPrice, err := SafeFloat64(order, "avgPrice"); err == nil && avgPrice > 0 {
+		fill.Price = avgPrice
+	}
+	if executedQty, err := SafeFloat64(order, "executedQty"); err == nil && executedQty > 0 {
+		fill.Quantity = executedQty
+	}
+	if commission, err := SafeFloat64(order, "commission"); err == nil {
+		fill.Fee = commission
+	}
+	return fill
+}
+
+// FundingCarryState holds the runtime state for funding carry trading
+type FundingCarryState struct {
+	mu sync.Mutex
+
+	// Configuration
+	Config *store.FundingCarryStrategyConfig
+
+	// Venues by exchange account ID (the trader's own exchange, the hedge exchanges and, with a
+	// spot hedge, the own exchange's spot account)
+	venues map[string]*carryVenue
+
+	// Open pairs
+	Pairs []*kernel.CarryPair
+
+	// Last time funding was accrued on the open legs
+	LastAccrualAt time.Time
+
+	IsInitialized bool
+}
+
+// carryFundingRate fetches the funding rate of a symbol on a venue (replaced in tests)
+var carryFundingRate = market.GetVenueFundingRate
+
+func init() {
+	RegisterStrategyCycle("funding_carry", StrategyCycle{Name: "Funding carry", Init: (*AutoTrader).InitializeFundingCarry, Run: (*AutoTrader).RunFundingCarryCycle})
+}
+
+// InitializeFundingCarry connects the hedge exchanges and restores open pairs from the database
+func (at *AutoTrader) InitializeFundingCarry() error {
+	strategyConfig := at.strategyConfig()
+	if strategyConfig == nil || strategyConfig.FundingCarryConfig == nil {
+		return fmt.Errorf("funding carry configuration not found")
+	}
+	if at.store == nil {
+		return fmt.Errorf("funding carry requires the store to load hedge exchanges")
+	}
+	carryConfig := strategyConfig.FundingCarryConfig
+	state := &FundingCarryState{
+		Config: carryConfig,
+		venues: map[string]*carryVenue{
+			at.exchangeID: {exchangeID: at.exchangeID, exchange: at.exchange, trader: at.trader},
+		},
+	}
+
+	for _, id := range carryConfig.HedgeExchangeIDs {
+		if _, ok := state.venues[id]; ok {
+			continue
+		}
+		ex, err := at.store.Exchange().GetByID(at.userID, id)
+		if err != nil {
+			return fmt.Errorf("failed to load hedge exchange %s: %w", id, err)
+		}
+		if !ex.Enabled {
+			return fmt.Errorf("hedge exchange %s is disabled", id)
+		}
+		cfg := at.config
+		cfg.Exchange = ex.ExchangeType
+		cfg.ExchangeID = ex.ID
+		cfg.HyperliquidTestnet = ex.Testnet
+		SetExchangeCredentials(&cfg, ex)
+		t, err := newExchangeTrader(cfg, at.userID)
+		if err != nil {
+			return fmt.Errorf("failed to connect hedge exchange %s: %w", id, err)
+		}
+		state.venues[id] = &carryVenue{exchangeID: id, exchange: ex.ExchangeType, trader: t}
+	}
+	if carryConfig.SpotHedge {
+		spot, ok := at.trader.(SpotTrader)
+		if !ok {
+			return fmt.Errorf("spot hedge is not supported on %s", at.exchange)
+		}
+		id := at.exchangeID + carrySpotSuffix
+		state.venues[id] = &carryVenue{exchangeID: id, exchange: at.exchange, trader: at.trader, spot: spot}
+	}
+
+	for _, venue := range state.venues {
+		if venue.spot != nil {
+			continue
+		}
+		for _, symbol := range carryConfig.Symbols {
+			if err := venue.trader.SetLeverage(symbol, carryConfig.Leverage); err != nil {
+				logger.Warnf("[Carry] Failed to set leverage %dx for %s on %s: %v", carryConfig.Leverage, symbol, venue.exchange, err)
+			}
+		}
+	}
+
+	// Restore pairs from the open legs (a pair missing a leg is unwound by the imbalance check);
+	// legs still waiting for OrderSync are linked again by refreshCarryLegs
+	positions, err := at.store.Position().GetOpenPositions(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open positions: %w", err)
+	}
+	pending, err := at.store.Position().GetPendingLinks(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load pending legs: %w", err)
+	}
+	pairs := make(map[string]*kernel.CarryPair)
+	for _, pos := range append(positions, pending...) {
+		if pos.Source != fundingCarrySource || pos.GroupID == "" {
+			continue
+		}
+		pair, ok := pairs[pos.GroupID]
+		if !ok {
+			pair = &kernel.CarryPair{ID: pos.GroupID, Symbol: pos.Symbol, OpenedAt: time.UnixMilli(pos.EntryTime)}
+			pairs[pos.GroupID] = pair
+			state.Pairs = append(state.Pairs, pair)
+		}
+		leg := kernel.CarryLeg{
+			ExchangeID:          pos.ExchangeID,
+			PositionID:          pos.ID,
+			Quantity:            pos.Quantity,
+			EntryPrice:          pos.EntryPrice,
+			FundingPnL:          pos.FundingPnL,
+			EstimatedFundingPnL: pos.EstimatedFundingPnL,
+		}
+		if pos.Status != "OPEN" {
+			leg.PositionID = 0
+		}
+		if pos.Side == "LONG" {
+			pair.Long = leg
+		} else {
+			pair.Short = leg
+		}
+	}
+
+	at.carryState = state
+	at.carryState.IsInitialized = true
+	logger.Infof("💱 [Carry] Initialized: %d venues, %d symbols, %d pairs restored, entry %.2f%% / exit %.2f%% APR",
+		len(state.venues), len(carryConfig.Symbols), len(state.Pairs), carryConfig.MinEntryAPR, carryConfig.ExitAPR)
+	return nil
+}
+
+// RunFundingCarryCycle executes one funding carry cycle
+func (at *AutoTrader) RunFundingCarryCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Carry] Trader is stopped, aborting funding carry cycle")
+		return nil
+	}
+
+	if at.carryState == nil || !at.carryState.IsInitialized {
+		if err := at.InitializeFundingCarry(); err != nil {
+			return fmt.Errorf("failed to initialize funding carry: %w", err)
+		}
+	}
+
+	at.refreshCarryLegs()
+	quotes := at.fetchCarryQuotes()
+	now := time.Now()
+	at.accrueCarryFunding(quotes, now)
+
+	at.carryState.mu.Lock()
+	carryCtx := &kernel.FundingCarryContext{
+		Config: at.carryState.Config,
+		Quotes: quotes,
+		Pairs:  at.carryState.Pairs,
+		Now:    now,
+	}
+	decision, err := kernel.RunStrategy(at.runningStrategy().Strategy, &kernel.Context{TraderID: at.id, FundingCarry: carryCtx})
+	at.carryState.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to get funding carry decisions: %w", err)
+	}
+
+	// Legs are executed in pairs: both closes of a symbol together, open_long followed by its open_short
+	decisions := decision.Decisions
+	closed := make(map[string]bool)
+	var actions []store.DecisionAction
+	for i := 0; i < len(decisions); i++ {
+		at.isRunningMutex.RLock()
+		running := at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			logger.Infof("[Carry] Trader stopped, skipping remaining decisions")
+			break
+		}
+
+		d := &decisions[i]
+		switch d.Action {
+		case "close_long", "close_short":
+			if closed[d.Symbol] {
+				continue
+			}
+			closed[d.Symbol] = true
+			// Both legs of the symbol are closed together, each with its own record
+			legActions := make(map[string]*store.DecisionAction)
+			for j := i; j < len(decisions); j++ {
+				if decisions[j].Symbol == d.Symbol && strings.HasPrefix(decisions[j].Action, "close_") {
+					action := newStrategyAction(&decisions[j])
+					legActions[strings.TrimPrefix(decisions[j].Action, "close_")] = &action
+				}
+			}
+			if err := at.closeCarryPair(d.Symbol, d.Reasoning, legActions); err != nil {
+				logger.Warnf("[Carry] Failed to unwind %s: %v", d.Symbol, err)
+			}
+			for _, side := range []string{"long", "short"} {
+				if action := legActions[side]; action != nil {
+					actions = append(actions, *action)
+				}
+			}
+		case "open_long":
+			if i+1 >= len(decisions) || decisions[i+1].Action != "open_short" || decisions[i+1].Symbol != d.Symbol {
+				logger.Warnf("[Carry] open_long %s without a matching open_short, skipped", d.Symbol)
+				continue
+			}
+			i++
+			longAction, shortAction := newStrategyAction(d), newStrategyAction(&decisions[i])
+			if err := at.openCarryPair(d, &decisions[i], &longAction, &shortAction); err != nil {
+				logger.Warnf("[Carry] Failed to open %s: %v", d.Symbol, err)
+			}
+			actions = append(actions, longAction, shortAction)
+		default:
+			logger.Warnf("[Carry] Unknown action: %s", d.Action)
+		}
+	}
+
+	at.saveStrategyDecisionRecord("Carry", decision, actions)
+	return nil
+}
+
+// fetchCarryQuotes fetches the funding rate and price of every symbol on every venue (the spot
+// venue pays no funding and is quoted at the perpetual's price)
+func (at *AutoTrader) fetchCarryQuotes() map[string][]kernel.CarryQuote {
+	quotes := make(map[string][]kernel.CarryQuote)
+	for _, symbol := range at.carryState.Config.Symbols {
+		for _, venue := range at.carryState.venues {
+			var apr float64
+			if venue.spot == nil {
+				fr, err := carryFundingRate(venue.exchange, symbol)
+				if err != nil {
+					logger.Warnf("[Carry] %v", err)
+					continue
+				}
+				apr = fr.AnnualizedPct()
+			}
+			price, err := venue.trader.GetMarketPrice(symbol)
+			if err != nil {
+				logger.Warnf("[Carry] Failed to get %s price on %s: %v", symbol, venue.exchange, err)
+				continue
+			}
+			quotes[symbol] = append(quotes[symbol], kernel.CarryQuote{
+				ExchangeID:    venue.exchangeID,
+				Exchange:      venue.exchange,
+				AnnualizedPct: apr,
+				MarkPrice:     price,
+				Spot:          venue.spot != nil,
+			})
+		}
+	}
+	return quotes
+}
+
+// refreshCarryLegs updates quantity, mark price and liquidation price of the open legs from the venues
+func (at *AutoTrader) refreshCarryLegs() {
+	state := at.carryState
+	positionsByVenue := make(map[string][]map[string]interface{})
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	for _, pair := range state.Pairs {
+		for _, leg := range []struct {
+			leg  *kernel.CarryLeg
+			side string
+		}{{&pair.Long, "long"}, {&pair.Short, "short"}} {
+			venue, ok := state.venues[leg.leg.ExchangeID]
+			if !ok {
+				leg.leg.Quantity = 0
+				continue
+			}
+			if venue.spot != nil {
+				// The spot balance may hold more than the leg, never count it; fees paid in the
+				// asset leave less than was bought
+				balance, err := venue.spot.GetSpotBalance(pair.Symbol)
+				if err != nil {
+					logger.Warnf("[Carry] Failed to get %s spot balance on %s: %v", pair.Symbol, venue.exchange, err)
+					continue
+				}
+				leg.leg.Quantity = math.Min(leg.leg.Quantity, balance)
+				if price, err := venue.trader.GetMarketPrice(pair.Symbol); err == nil {
+					leg.leg.MarkPrice = price
+				}
+				continue
+			}
+			positions, ok := positionsByVenue[venue.exchangeID]
+			if !ok {
+				var err error
+				positions, err = venue.trader.GetPositions()
+				if err != nil {
+					// Keep the last known state when a venue is temporarily unavailable
+					logger.Warnf("[Carry] Failed to get positions on %s: %v", venue.exchange, err)
+					continue
+				}
+				positionsByVenue[venue.exchangeID] = positions
+			}
+
+			// Legs on the trader's own exchange are linked once OrderSync has recorded them
+			if leg.leg.PositionID == 0 && leg.leg.Quantity > 0 && at.store != nil {
+				leg.leg.PositionID = at.recordCarryLeg(pair, leg.leg, venue, leg.side, "")
+				if leg.leg.PositionID > 0 && leg.leg.EstimatedFundingPnL != 0 {
+					if err := at.store.Position().AddEstimatedFundingPnL(leg.leg.PositionID, leg.leg.EstimatedFundingPnL); err != nil {
+						logger.Warnf("[Carry] Failed to record estimated funding on position %d: %v", leg.leg.PositionID, err)
+					}
+				}
+			}
+
+			// A missing position (liquidated, closed manually) leaves the leg empty
+			leg.leg.Quantity = 0
+			for _, pos := range positions {
+				if pos["symbol"] != pair.Symbol || pos["side"] != leg.side {
+					continue
+				}
+				size, _ := pos["positionAmt"].(float64)
+				leg.leg.Quantity = math.Abs(size)
+				leg.leg.MarkPrice, _ = pos["markPrice"].(float64)
+				leg.leg.LiquidationPrice, _ = pos["liquidationPrice"].(float64)
+				break
+			}
+		}
+	}
+}
+
+// accrueCarryFunding books the funding of each open leg: the venue's settlements where it reports
+// them, otherwise an estimate from the quoted rate since the last cycle (longs pay positive
+// funding, shorts receive it). Spot legs pay no funding.
+func (at *AutoTrader) accrueCarryFunding(quotes map[string][]kernel.CarryQuote, now time.Time) {
+	state := at.carryState
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	last := state.LastAccrualAt
+	state.LastAccrualAt = now
+	var hours float64
+	if !last.IsZero() {
+		hours = now.Sub(last).Hours()
+	}
+
+	for _, pair := range state.Pairs {
+		for _, leg := range []struct {
+			leg  *kernel.CarryLeg
+			sign float64
+		}{{&pair.Long, -1}, {&pair.Short, 1}} {
+			if leg.leg.Quantity <= 0 {
+				continue
+			}
+			venue, ok := state.venues[leg.leg.ExchangeID]
+			if !ok || venue.spot != nil {
+				continue
+			}
+			if history, ok := venue.trader.(FundingFeeTrader); ok {
+				at.syncCarryFunding(pair, leg.leg, venue, history)
+				continue
+			}
+			if hours == 0 {
+				continue
+			}
+			var apr float64
+			found := false
+			for _, q := range quotes[pair.Symbol] {
+				if q.ExchangeID == leg.leg.ExchangeID {
+					apr, found = q.AnnualizedPct, true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+			amount := leg.sign * apr / 100 / (365 * 24) * hours * leg.leg.Notional()
+			leg.leg.EstimatedFundingPnL += amount
+			if leg.leg.PositionID > 0 {
+				if err := at.store.Position().AddEstimatedFundingPnL(leg.leg.PositionID, amount); err != nil {
+					logger.Warnf("[Carry] Failed to record estimated funding on position %d: %v", leg.leg.PositionID, err)
+				}
+			}
+		}
+	}
+}
+
+// syncCarryFunding sets the funding settled on a leg since its pair was opened (the account's
+// settlements of the symbol; the carry trader is assumed to be the only holder of it)
+func (at *AutoTrader) syncCarryFunding(pair *kernel.CarryPair, leg *kernel.CarryLeg, venue *carryVenue, history FundingFeeTrader) {
+	fees, err := history.GetFundingFees(pair.Symbol, pair.OpenedAt)
+	if err != nil {
+		// Keep the last settled total; it is complete again on the next successful sync
+		logger.Warnf("[Carry] Failed to get %s funding history on %s: %v", pair.Symbol, venue.exchange, err)
+		return
+	}
+	var total float64
+	for _, fee := range fees {
+		if fee.Symbol == pair.Symbol && !fee.Time.Before(pair.OpenedAt) {
+			total += fee.Amount
+		}
+	}
+	if total == leg.FundingPnL {
+		return
+	}
+	leg.FundingPnL = total
+	if leg.PositionID > 0 && at.store != nil {
+		if err := at.store.Position().SetFundingPnL(leg.PositionID, total); err != nil {
+			logger.Warnf("[Carry] Failed to record funding on position %d: %v", leg.PositionID, err)
+		}
+	}
+}
+
+// openCarryPair opens both legs of a pair; the long leg is unwound if the short leg fails. The
+// outcome of each leg is recorded in its action.
+func (at *AutoTrader) openCarryPair(long, short *kernel.Decision, longAction, shortAction *store.DecisionAction) error {
+	fail := func(longErr, shortErr error, err error) error {
+		finishStrategyAction(longAction, longErr)
+		finishStrategyAction(shortAction, shortErr)
+		return err
+	}
+
+	state := at.carryState
+	longVenue, ok := state.venues[long.ExchangeID]
+	if !ok {
+		err := fmt.Errorf("unknown exchange %s", long.ExchangeID)
+		return fail(err, err, err)
+	}
+	shortVenue, ok := state.venues[short.ExchangeID]
+	if !ok {
+		err := fmt.Errorf("unknown exchange %s", short.ExchangeID)
+		return fail(err, err, err)
+	}
+
+	price := long.EntryPrice
+	if price <= 0 {
+		var err error
+		if price, err = longVenue.trader.GetMarketPrice(long.Symbol); err != nil {
+			err = fmt.Errorf("failed to get market price: %w", err)
+			return fail(err, err, err)
+		}
+	}
+	// Equal quantities keep the pair delta-neutral
+	quantity := long.PositionSizeUSD / price
+	if longVenue.spot != nil {
+		// The spot leg buys exactly the perpetual's lot size
+		if formatted, err := shortVenue.trader.FormatQuantity(short.Symbol, quantity); err == nil {
+			if q, err := strconv.ParseFloat(formatted, 64); err == nil && q > 0 {
+				quantity = q
+			}
+		}
+	}
+
+	longOrder, err := longVenue.open(long.Symbol, "long", quantity, long.Leverage)
+	if err != nil {
+		err = fmt.Errorf("failed to open long leg on %s: %w", longVenue.exchange, err)
+		return fail(err, fmt.Errorf("not sent: %w", err), err)
+	}
+	shortOrder, err := shortVenue.open(short.Symbol, "short", quantity, short.Leverage)
+	if err != nil {
+		err = fmt.Errorf("failed to open short leg on %s: %w", shortVenue.exchange, err)
+		rollbackErr := fmt.Errorf("rolled back: %w", err)
+		if _, closeErr := longVenue.close(long.Symbol, "long", quantity); closeErr != nil {
+			logger.Errorf("❌ [Carry] Failed to unwind long leg of %s on %s after short leg failure: %v", long.Symbol, longVenue.exchange, closeErr)
+			rollbackErr = fmt.Errorf("unwind failed (%v) after: %w", closeErr, err)
+		}
+		setActionFill(longAction, orderFill{OrderID: orderIDOf(longOrder), Price: price, Quantity: quantity})
+		return fail(rollbackErr, err, err)
+	}
+
+	shortPrice := short.EntryPrice
+	if shortPrice <= 0 {
+		shortPrice = price
+	}
+	longFill := longVenue.fill(long.Symbol, longOrder, quantity, price)
+	shortFill := shortVenue.fill(short.Symbol, shortOrder, quantity, shortPrice)
+	setActionFill(longAction, longFill)
+	setActionFill(shortAction, shortFill)
+	finishStrategyAction(longAction, nil)
+	finishStrategyAction(shortAction, nil)
+
+	now := time.Now()
+	pair := &kernel.CarryPair{
+		ID:       fmt.Sprintf("carry_%s_%d", long.Symbol, now.UnixNano()),
+		Symbol:   long.Symbol,
+		OpenedAt: now,
+		Long:     kernel.CarryLeg{ExchangeID: longVenue.exchangeID, Quantity: longFill.Quantity, EntryPrice: longFill.Price, MarkPrice: longFill.Price},
+		Short:    kernel.CarryLeg{ExchangeID: shortVenue.exchangeID, Quantity: shortFill.Quantity, EntryPrice: shortFill.Price, MarkPrice: shortFill.Price},
+	}
+	pair.Long.PositionID = at.recordCarryLeg(pair, &pair.Long, longVenue, "long", longFill.OrderID)
+	pair.Short.PositionID = at.recordCarryLeg(pair, &pair.Short, shortVenue, "short", shortFill.OrderID)
+
+	state.mu.Lock()
+	state.Pairs = append(state.Pairs, pair)
+	state.mu.Unlock()
+
+	logger.Infof("💱 [Carry] Pair %s opened: long %.6f %s on %s, short on %s", pair.ID, quantity, pair.Symbol, longVenue.exchange, shortVenue.exchange)
+	return nil
+}
+
+// recordCarryLeg records a leg in trader_positions and returns its position ID (0 if not recorded yet);
+// spot legs are recorded directly at 1x since OrderSync only tracks the perpetual account
+func (at *AutoTrader) recordCarryLeg(pair *kernel.CarryPair, leg *kernel.CarryLeg, venue *carryVenue, side, orderID string) int64 {
+	leverage := at.carryState.Config.Leverage
+	if venue.spot != nil {
+		leverage = 1
+	}
+	return at.recordLinkedPosition(venue.exchangeID, venue.exchange, pair.Symbol, side, leg.Quantity, leg.EntryPrice,
+		leverage, orderID, fundingCarrySource, pair.ID)
+}
+
+// closeCarryPair closes both legs of the pair of a symbol and records the funding/price PnL split;
+// the outcome of each leg is recorded in actions (by side, entries may be nil)
+func (at *AutoTrader) closeCarryPair(symbol, reason string, actions map[string]*store.DecisionAction) error {
+	state := at.carryState
+	state.mu.Lock()
+	var pair *kernel.CarryPair
+	for _, p := range state.Pairs {
+		if p.Symbol == symbol {
+			pair = p
+			break
+		}
+	}
+	state.mu.Unlock()
+	if pair == nil {
+		err := fmt.Errorf("no open pair for %s", symbol)
+		for _, action := range actions {
+			finishStrategyAction(action, err)
+		}
+		return err
+	}
+
+	var errs []string
+	for _, leg := range []struct {
+		leg  *kernel.CarryLeg
+		side string
+	}{{&pair.Long, "long"}, {&pair.Short, "short"}} {
+		err := at.closeCarryLeg(pair, leg.leg, leg.side, reason, actions[leg.side])
+		if action := actions[leg.side]; action != nil {
+			finishStrategyAction(action, err)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		// The remaining leg is retried on the next cycle
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	state.mu.Lock()
+	for i, p := range state.Pairs {
+		if p == pair {
+			state.Pairs = append(state.Pairs[:i], state.Pairs[i+1:]...)
+			break
+		}
+	}
+	state.mu.Unlock()
+	if at.store != nil {
+		if err := at.store.Position().DeletePendingLinks(at.id, pair.ID); err != nil {
+			logger.Warnf("[Carry] Failed to delete pending legs of %s: %v", pair.ID, err)
+		}
+	}
+
+	logger.Infof("💱 [Carry] Pair %s closed (%s)", pair.ID, reason)
+	return nil
+}
+
+// closeCarryLeg closes one leg on its venue (if still open) and closes its position record; the
+// fill is recorded in action if not nil
+func (at *AutoTrader) closeCarryLeg(pair *kernel.CarryPair, leg *kernel.CarryLeg, side, reason string, action *store.DecisionAction) error {
+	if leg.Quantity <= 0 && leg.PositionID == 0 {
+		return nil
+	}
+	venue, ok := at.carryState.venues[leg.ExchangeID]
+	if !ok {
+		return fmt.Errorf("unknown exchange %s", leg.ExchangeID)
+	}
+
+	exitPrice, err := venue.trader.GetMarketPrice(pair.Symbol)
+	if err != nil {
+		exitPrice = leg.MarkPrice
+	}
+	var orderID string
+	if leg.Quantity > 0 {
+		order, err := venue.close(pair.Symbol, side, leg.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to close %s leg on %s: %w", side, venue.exchange, err)
+		}
+		fill := venue.fill(pair.Symbol, order, leg.Quantity, exitPrice)
+		if action != nil {
+			setActionFill(action, fill)
+		}
+		orderID, exitPrice = fill.OrderID, fill.Price
+	}
+
+	pricePnL := (exitPrice - leg.EntryPrice) * leg.Quantity
+	if side == "short" {
+		pricePnL = -pricePnL
+	}
+	at.closeLinkedPosition(leg.PositionID, leg.ExchangeID, exitPrice, orderID, pricePnL, reason)
+	logger.Infof("[Carry] %s %s leg closed on %s: price PnL $%.2f, funding $%.2f (settled $%.2f)",
+		pair.Symbol, side, venue.exchange, pricePnL, leg.Funding(), leg.FundingPnL)
+
+	leg.Quantity = 0
+	leg.PositionID = 0
+	return nil
+}
@@ -0,0 +1,262 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+	"nofx/trader/types"
+)
+
+// fakeFundingTrader a venue reporting its funding settlements
+type fakeFundingTrader struct {
+	*fakeTrader
+	fees []types.FundingFee
+}
+
+func (f *fakeFundingTrader) GetFundingFees(symbol string, startTime time.Time) ([]types.FundingFee, error) {
+	return f.fees, nil
+}
+
+// fakeSpotTrader a venue with a spot account; buys pay their fee in the asset
+type fakeSpotTrader struct {
+	*fakeTrader
+	spot map[string]float64 // symbol -> base asset balance
+}
+
+func (f *fakeSpotTrader) spotOrder(action, symbol string, quantity float64) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if action == "spot_buy" {
+		f.spot[symbol] += quantity * 0.999
+	} else {
+		f.spot[symbol] -= quantity
+	}
+	f.orders = append(f.orders, action+" "+symbol)
+	price := f.prices[symbol]
+	return map[string]interface{}{"orderId": strconv.Itoa(len(f.orders)), "avgPrice": price, "executedQty": quantity}, nil
+}
+
+func (f *fakeSpotTrader) SpotBuy(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.spotOrder("spot_buy", symbol, quantity)
+}
+
+func (f *fakeSpotTrader) SpotSell(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.spotOrder("spot_sell", symbol, quantity)
+}
+
+func (f *fakeSpotTrader) GetSpotBalance(symbol string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.spot[symbol], nil
+}
+
+func (f *fakeSpotTrader) FormatQuantity(symbol string, quantity float64) (string, error) {
+	return strconv.FormatFloat(quantity, 'f', 3, 64), nil
+}
+
+// newCarryTrader builds a funding carry trader on two fake venues: "ex-a" (binance, the trader's
+// own exchange) and "ex-b" (bybit); rates holds the funding rate per 8h of each exchange
+func newCarryTrader(t *testing.T, rates map[string]float64) (*AutoTrader, *fakeTrader, *fakeTrader) {
+	t.Helper()
+	withFastFills(t)
+	fetch := carryFundingRate
+	carryFundingRate = func(exchange, symbol string) (*market.VenueFundingRate, error) {
+		rate, ok := rates[exchange]
+		if !ok {
+			return nil, fmt.Errorf("no funding on %s", exchange)
+		}
+		return &market.VenueFundingRate{Exchange: exchange, Symbol: symbol, Rate: rate, IntervalHours: 8}, nil
+	}
+	t.Cleanup(func() { carryFundingRate = fetch })
+
+	st, err := store.New(filepath.Join(t.TempDir(), "carry.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "funding_carry"
+	cfg.FundingCarryConfig = &store.FundingCarryStrategyConfig{
+		Symbols: []string{"BTCUSDT"}, HedgeExchangeIDs: []string{"ex-b"},
+		PositionSizeUSD: 1000, Leverage: 2, MaxPairs: 1, MinEntryAPR: 20, ExitAPR: 5,
+	}
+	strategy, err := kernel.NewStrategy(kernel.StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	venueA := newFakeTrader(map[string]float64{"BTCUSDT": 100000})
+	venueB := newFakeTrader(map[string]float64{"BTCUSDT": 100050})
+	at := &AutoTrader{
+		id: "carry", exchangeID: "ex-a", exchange: "binance", trader: venueA,
+		store: st, strategy: strategy, isRunning: true,
+		carryState: &FundingCarryState{
+			Config: cfg.FundingCarryConfig,
+			venues: map[string]*carryVenue{
+				"ex-a": {exchangeID: "ex-a", exchange: "binance", trader: venueA},
+				"ex-b": {exchangeID: "ex-b", exchange: "bybit", trader: venueB},
+			},
+			IsInitialized: true,
+		},
+	}
+	return at, venueA, venueB
+}
+
+func TestRunFundingCarryCycle(t *testing.T) {
+	rates := map[string]float64{"binance": -0.0001, "bybit": 0.0002}
+	at, venueA, venueB := newCarryTrader(t, rates)
+
+	// 32.85% APR spread: long on binance (lowest funding), short on bybit
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(at.carryState.Pairs) != 1 {
+		t.Fatalf("expected one open pair, got %d", len(at.carryState.Pairs))
+	}
+	pair := at.carryState.Pairs[0]
+	if venueA.positions["BTCUSDT long"] != 0.01 || venueB.positions["BTCUSDT short"] != 0.01 {
+		t.Errorf("legs should hold equal quantities: %v / %v", venueA.positions, venueB.positions)
+	}
+	// The short leg records the bybit fill, not the binance reference price
+	if pair.Short.EntryPrice != 100050 || pair.Short.PositionID == 0 {
+		t.Errorf("short leg should carry its own fill and record: %+v", pair.Short)
+	}
+
+	// The spread compresses below the exit: both legs are unwound
+	rates["bybit"] = -0.0001
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(at.carryState.Pairs) != 0 || venueA.positions["BTCUSDT long"] != 0 || venueB.positions["BTCUSDT short"] != 0 {
+		t.Errorf("the pair should be unwound on both venues: %v / %v", venueA.positions, venueB.positions)
+	}
+
+	records, err := at.store.Decision().GetLatestRecords("carry", 1)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected the unwind record, got %d (%v)", len(records), err)
+	}
+	for _, a := range records[0].Decisions {
+		if !strings.HasPrefix(a.Action, "close_") || !a.Success || a.Quantity != 0.01 {
+			t.Errorf("unexpected unwind action: %+v", a)
+		}
+	}
+}
+
+func TestOpenCarryPairUnwindsLongWhenShortFails(t *testing.T) {
+	at, venueA, venueB := newCarryTrader(t, nil)
+	venueB.fail["open_short BTCUSDT"] = fmt.Errorf("margin insufficient")
+
+	long := &kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", ExchangeID: "ex-a", PositionSizeUSD: 1000, Leverage: 2}
+	short := &kernel.Decision{Symbol: "BTCUSDT", Action: "open_short", ExchangeID: "ex-b", PositionSizeUSD: 1000, Leverage: 2}
+	var longAction, shortAction store.DecisionAction
+	if err := at.openCarryPair(long, short, &longAction, &shortAction); err == nil {
+		t.Fatal("the pair should fail with its short leg")
+	}
+
+	if got := strings.Join(venueA.orders, ", "); got != "open_long BTCUSDT, close_long BTCUSDT" {
+		t.Errorf("the long leg should be unwound, orders: %s", got)
+	}
+	if venueA.positions["BTCUSDT long"] != 0 || len(at.carryState.Pairs) != 0 {
+		t.Errorf("nothing should stay open: %v, %d pairs", venueA.positions, len(at.carryState.Pairs))
+	}
+	if longAction.Success || !strings.Contains(longAction.Error, "rolled back") || longAction.OrderID == 0 {
+		t.Errorf("the long leg should be recorded as rolled back: %+v", longAction)
+	}
+	if shortAction.Success || !strings.Contains(shortAction.Error, "margin insufficient") {
+		t.Errorf("the short leg should be recorded as failed: %+v", shortAction)
+	}
+}
+
+func TestCarryFundingSettledByVenue(t *testing.T) {
+	at, _, venueB := newCarryTrader(t, map[string]float64{"binance": -0.0001, "bybit": 0.0002})
+	bybit := &fakeFundingTrader{fakeTrader: venueB}
+	at.carryState.venues["ex-b"].trader = bybit
+
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+	pair := at.carryState.Pairs[0]
+	// Only the settlements since the pair was opened count
+	bybit.fees = []types.FundingFee{
+		{Symbol: "BTCUSDT", Amount: -5, Time: pair.OpenedAt.Add(-time.Hour)},
+		{Symbol: "BTCUSDT", Amount: 1.5, Time: pair.OpenedAt.Add(time.Minute)},
+		{Symbol: "BTCUSDT", Amount: 2, Time: pair.OpenedAt.Add(2 * time.Minute)},
+	}
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bybit leg books the settlements, the binance leg (no history) keeps the estimate
+	if pair.Short.FundingPnL != 3.5 || pair.Short.EstimatedFundingPnL != 0 {
+		t.Errorf("short leg funding = %.4f settled / %.4f estimated, want 3.5 / 0", pair.Short.FundingPnL, pair.Short.EstimatedFundingPnL)
+	}
+	if pair.Long.FundingPnL != 0 || pair.Long.EstimatedFundingPnL <= 0 {
+		t.Errorf("long leg should only carry an estimate: %+v", pair.Long)
+	}
+	breakdown, err := at.store.Position().GetPnLBreakdown("carry", fundingCarrySource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breakdown.FundingPnL != 3.5 {
+		t.Errorf("recorded settled funding = %.4f, want 3.5", breakdown.FundingPnL)
+	}
+}
+
+func TestFundingCarrySpotHedge(t *testing.T) {
+	rates := map[string]float64{"binance": 0.0003}
+	at, venueA, _ := newCarryTrader(t, rates)
+	cfg := *at.carryState.Config
+	cfg.HedgeExchangeIDs, cfg.SpotHedge = nil, true
+	at.config.StrategyConfig = &store.StrategyConfig{FundingCarryConfig: &cfg}
+
+	// The spot hedge needs a spot account on the trader's own exchange
+	if err := at.InitializeFundingCarry(); err == nil || !strings.Contains(err.Error(), "spot hedge is not supported") {
+		t.Fatalf("expected the missing spot support to fail, got %v", err)
+	}
+	spot := &fakeSpotTrader{fakeTrader: venueA, spot: map[string]float64{"BTCUSDT": 0.5}}
+	at.trader = spot
+	if err := at.InitializeFundingCarry(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 32.85% APR on the perpetual: long spot, short the perpetual on the same account
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(at.carryState.Pairs) != 1 {
+		t.Fatalf("expected one open pair, got %d", len(at.carryState.Pairs))
+	}
+	pair := at.carryState.Pairs[0]
+	if pair.Long.ExchangeID != "ex-a"+carrySpotSuffix || pair.Short.ExchangeID != "ex-a" {
+		t.Errorf("legs on wrong venues: long %s / short %s", pair.Long.ExchangeID, pair.Short.ExchangeID)
+	}
+	if got := strings.Join(spot.orders, ", "); got != "spot_buy BTCUSDT, open_short BTCUSDT" {
+		t.Errorf("orders = %s", got)
+	}
+	if pair.Long.PositionID == 0 {
+		t.Error("the spot leg should be recorded directly")
+	}
+
+	// Funding turns negative: the spot asset bought (net of fees) is sold, the short is closed
+	rates["binance"] = -0.0001
+	if err := at.RunFundingCarryCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(at.carryState.Pairs) != 0 || venueA.positions["BTCUSDT short"] != 0 {
+		t.Errorf("the pair should be unwound: %d pairs, %v", len(at.carryState.Pairs), venueA.positions)
+	}
+	if balance := spot.spot["BTCUSDT"]; balance < 0.4999 || balance > 0.5 {
+		t.Errorf("spot balance = %.6f, want the initial 0.5 back less the buy fee", balance)
+	}
+	if pair.Long.EstimatedFundingPnL != 0 || pair.Long.FundingPnL != 0 {
+		t.Errorf("the spot leg pays no funding: %+v", pair.Long)
+	}
+}
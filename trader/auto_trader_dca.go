@@ -0,0 +1,506 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// DCA Trading State Management
+// ============================================================================
+
+// DCAState holds the runtime state for DCA trading
+type DCAState struct {
+	mu sync.Mutex
+
+	// Configuration
+	Config *store.DCAStrategyConfig
+
+	// Active deal (nil = waiting for the next base order)
+	Deal *kernel.DCADeal
+
+	// Close time of the last deal (cooldown start)
+	LastClosedAt time.Time
+
+	IsInitialized bool
+}
+
+func init() {
+	RegisterStrategyCycle("dca", StrategyCycle{Name: "DCA", Init: (*AutoTrader).InitializeDCA, Run: (*AutoTrader).RunDCACycle})
+}
+
+// InitializeDCA restores the active deal from the database and sets leverage
+func (at *AutoTrader) InitializeDCA() error {
+	strategyConfig := at.strategyConfig()
+	if strategyConfig == nil || strategyConfig.DCAConfig == nil {
+		return fmt.Errorf("dca configuration not found")
+	}
+	dcaConfig := strategyConfig.DCAConfig
+	at.dcaState = &DCAState{Config: dcaConfig}
+
+	if at.store != nil {
+		dealModel, orderModels, err := at.store.DCA().LoadActiveDeal(at.id)
+		if err != nil {
+			return fmt.Errorf("failed to load active deal: %w", err)
+		}
+		if dealModel != nil {
+			at.dcaState.Deal = dcaDealFromModel(dealModel, orderModels)
+			logger.Infof("🪙 [DCA] Restored deal %s: avg entry $%.4f, %d/%d safety orders filled",
+				dealModel.ID, at.dcaState.Deal.AverageEntry(), at.dcaState.Deal.FilledSafetyOrders(), len(orderModels))
+		}
+		lastDeal, err := at.store.DCA().LoadLastClosedDeal(at.id)
+		if err != nil {
+			logger.Warnf("[DCA] Failed to load last closed deal: %v", err)
+		} else if lastDeal != nil && lastDeal.ClosedAt != nil {
+			at.dcaState.LastClosedAt = *lastDeal.ClosedAt
+		}
+	}
+
+	if err := at.trader.SetLeverage(dcaConfig.Symbol, dcaConfig.Leverage); err != nil {
+		logger.Warnf("[DCA] Failed to set leverage %dx on exchange: %v", dcaConfig.Leverage, err)
+	}
+
+	at.dcaState.IsInitialized = true
+	logger.Infof("🪙 [DCA] Initialized: %s %s, base $%.2f, %d safety orders, TP %.2f%%",
+		dcaConfig.Symbol, strings.ToUpper(kernel.DCASide(dcaConfig)), dcaConfig.BaseOrderUSD,
+		dcaConfig.MaxSafetyOrders, dcaConfig.TakeProfitPct)
+	return nil
+}
+
+// RunDCACycle executes one DCA trading cycle
+func (at *AutoTrader) RunDCACycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[DCA] Trader is stopped, aborting DCA cycle")
+		return nil
+	}
+
+	if at.dcaState == nil || !at.dcaState.IsInitialized {
+		if err := at.InitializeDCA(); err != nil {
+			return fmt.Errorf("failed to initialize dca: %w", err)
+		}
+	}
+	dcaConfig := at.dcaState.Config
+
+	// Pick up safety order fills and positions closed outside the bot
+	at.syncDCAState()
+
+	price, err := at.trader.GetMarketPrice(dcaConfig.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get market price: %w", err)
+	}
+
+	at.dcaState.mu.Lock()
+	dcaCtx := &kernel.DCAContext{
+		Config:           dcaConfig,
+		Deal:             at.dcaState.Deal,
+		CurrentPrice:     price,
+		Now:              time.Now(),
+		LastDealClosedAt: at.dcaState.LastClosedAt,
+		LimitOrders:      true,
+	}
+	decision, err := kernel.RunStrategy(at.runningStrategy().Strategy, &kernel.Context{TraderID: at.id, DCA: dcaCtx})
+	at.dcaState.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to get dca decisions: %w", err)
+	}
+
+	var actions []store.DecisionAction
+	for _, d := range decision.Decisions {
+		at.isRunningMutex.RLock()
+		running := at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			logger.Infof("[DCA] Trader stopped, skipping remaining %d decisions", len(decision.Decisions))
+			break
+		}
+
+		action := newStrategyAction(&d)
+		err := at.executeDCADecision(&d, price, &action)
+		if err != nil {
+			logger.Warnf("[DCA] Failed to execute decision %s: %v", d.Action, err)
+		}
+		finishStrategyAction(&action, err)
+		actions = append(actions, action)
+	}
+
+	// Trailing state may have changed even without decisions
+	at.saveDCADeal("")
+	at.saveStrategyDecisionRecord("DCA", decision, actions)
+	return nil
+}
+
+// executeDCADecision executes a single DCA decision and records the execution in action
+func (at *AutoTrader) executeDCADecision(d *kernel.Decision, price float64, action *store.DecisionAction) error {
+	switch d.Action {
+	case "open_long", "open_short":
+		return at.openDCABaseOrder(d, price, action)
+	case "place_buy_limit", "place_sell_limit":
+		return at.placeDCASafetyOrder(d, action)
+	case "close_long", "close_short":
+		return at.closeDCADeal(d, price, action)
+	default:
+		logger.Warnf("[DCA] Unknown action: %s", d.Action)
+		return nil
+	}
+}
+
+// openDCABaseOrder opens the base order at market and starts a new deal
+func (at *AutoTrader) openDCABaseOrder(d *kernel.Decision, price float64, action *store.DecisionAction) error {
+	dcaConfig := at.dcaState.Config
+	quantity := d.PositionSizeUSD / price
+
+	var order map[string]interface{}
+	var err error
+	if d.Action == "open_long" {
+		order, err = at.trader.OpenLong(d.Symbol, quantity, d.Leverage)
+	} else {
+		order, err = at.trader.OpenShort(d.Symbol, quantity, d.Leverage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open base order: %w", err)
+	}
+	fill := confirmOrderFill(at.trader, d.Symbol, order, quantity, price)
+
+	// Use the exchange entry price when available
+	entryPrice, filledQty := fill.Price, fill.Quantity
+	if size, entry := at.dcaExchangePosition(); size > 0 && entry > 0 {
+		entryPrice, filledQty = entry, size
+	}
+	fill.Price, fill.Quantity = entryPrice, filledQty
+	setActionFill(action, fill)
+
+	deal := kernel.NewDCADeal(dcaConfig, entryPrice, filledQty, time.Now())
+	deal.Fees = dcaFee(fill, gridTakerFeeRate)
+	at.dcaState.mu.Lock()
+	at.dcaState.Deal = deal
+	at.dcaState.mu.Unlock()
+	at.saveDCADeal("")
+
+	logger.Infof("🪙 [DCA] Deal %s opened: %s %.6f %s at $%.4f", deal.ID, d.Action, filledQty, d.Symbol, entryPrice)
+	return nil
+}
+
+// placeDCASafetyOrder places a safety order limit order
+func (at *AutoTrader) placeDCASafetyOrder(d *kernel.Decision, action *store.DecisionAction) error {
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+
+	side, positionSide := "BUY", "LONG"
+	if d.Action == "place_sell_limit" {
+		side, positionSide = "SELL", "SHORT"
+	}
+	req := &LimitOrderRequest{
+		Symbol:       d.Symbol,
+		Side:         side,
+		PositionSide: positionSide,
+		Price:        d.Price,
+		Quantity:     d.Quantity,
+		Leverage:     d.Leverage,
+		PostOnly:     at.dcaState.Config.UseMakerOnly,
+		ClientID:     fmt.Sprintf("dca-%d-%d", d.LevelIndex, time.Now().UnixNano()%1000000),
+	}
+	result, err := gridTrader.PlaceLimitOrder(req)
+	if err != nil {
+		return fmt.Errorf("failed to place safety order: %w", err)
+	}
+	// The order rests on the book; its fill is picked up by syncDCAState
+	setActionFill(action, orderFill{OrderID: result.OrderID, Price: d.Price, Quantity: d.Quantity})
+
+	at.dcaState.mu.Lock()
+	if deal := at.dcaState.Deal; deal != nil && d.LevelIndex >= 0 && d.LevelIndex < len(deal.SafetyOrders) {
+		deal.SafetyOrders[d.LevelIndex].State = "pending"
+		deal.SafetyOrders[d.LevelIndex].OrderID = result.OrderID
+	}
+	at.dcaState.mu.Unlock()
+
+	logger.Infof("[DCA] Placed safety order #%d: %s %.6f at $%.4f, orderID=%s",
+		d.LevelIndex+1, side, d.Quantity, d.Price, result.OrderID)
+	return nil
+}
+
+// closeDCADeal cancels open safety orders and closes the position
+func (at *AutoTrader) closeDCADeal(d *kernel.Decision, price float64, action *store.DecisionAction) error {
+	at.cancelDCASafetyOrders()
+
+	var quantity float64
+	at.dcaState.mu.Lock()
+	if at.dcaState.Deal != nil {
+		quantity = at.dcaState.Deal.Quantity
+	}
+	at.dcaState.mu.Unlock()
+
+	var order map[string]interface{}
+	var err error
+	if d.Action == "close_long" {
+		order, err = at.trader.CloseLong(d.Symbol, 0)
+	} else {
+		order, err = at.trader.CloseShort(d.Symbol, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close deal: %w", err)
+	}
+	fill := confirmOrderFill(at.trader, d.Symbol, order, quantity, price)
+	setActionFill(action, fill)
+
+	at.finishDCADeal(fill.Price, dcaFee(fill, gridTakerFeeRate), d.Reasoning)
+	return nil
+}
+
+// dcaFee returns the commission reported for a fill, or an estimate at feeRate when the
+// exchange did not report one
+func dcaFee(fill orderFill, feeRate float64) float64 {
+	if fill.Fee > 0 {
+		return fill.Fee
+	}
+	return fill.Price * fill.Quantity * feeRate
+}
+
+// finishDCADeal marks the active deal as closed and starts the cooldown; closeFee is the fee of
+// the closing order, the PnL is net of all the deal's fees
+func (at *AutoTrader) finishDCADeal(price, closeFee float64, reason string) {
+	at.dcaState.mu.Lock()
+	deal := at.dcaState.Deal
+	if deal == nil {
+		at.dcaState.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	deal.Status = kernel.DCADealClosed
+	deal.ClosedAt = now
+	deal.ClosePrice = price
+	deal.Fees += closeFee
+	deal.RealizedPnL = (price - deal.AverageEntry()) * deal.Quantity
+	if deal.Side == "short" {
+		deal.RealizedPnL = -deal.RealizedPnL
+	}
+	deal.RealizedPnL -= deal.Fees
+	at.dcaState.LastClosedAt = now
+	at.dcaState.mu.Unlock()
+
+	at.saveDCADeal(reason)
+
+	at.dcaState.mu.Lock()
+	at.dcaState.Deal = nil
+	at.dcaState.mu.Unlock()
+
+	logger.Infof("🪙 [DCA] Deal %s closed at $%.4f, PnL $%.2f after $%.2f fees (%s)", deal.ID, price, deal.RealizedPnL, deal.Fees, reason)
+}
+
+// cancelDCASafetyOrders cancels pending safety orders of the active deal
+func (at *AutoTrader) cancelDCASafetyOrders() {
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+
+	at.dcaState.mu.Lock()
+	defer at.dcaState.mu.Unlock()
+	if at.dcaState.Deal == nil {
+		return
+	}
+	for i := range at.dcaState.Deal.SafetyOrders {
+		so := &at.dcaState.Deal.SafetyOrders[i]
+		if so.State != "pending" {
+			continue
+		}
+		if err := gridTrader.CancelOrder(at.dcaState.Config.Symbol, so.OrderID); err != nil {
+			logger.Warnf("[DCA] Failed to cancel safety order %s: %v", so.OrderID, err)
+		}
+		so.State = "cancelled"
+	}
+}
+
+// syncDCAState syncs safety order fills and the position with the exchange
+func (at *AutoTrader) syncDCAState() {
+	at.dcaState.mu.Lock()
+	deal := at.dcaState.Deal
+	at.dcaState.mu.Unlock()
+	if deal == nil {
+		return
+	}
+	symbol := at.dcaState.Config.Symbol
+
+	openOrders, err := at.trader.GetOpenOrders(symbol)
+	if err != nil {
+		logger.Warnf("[DCA] Failed to get open orders: %v", err)
+		return
+	}
+	activeOrderIDs := make(map[string]bool, len(openOrders))
+	for _, order := range openOrders {
+		activeOrderIDs[order.OrderID] = true
+	}
+
+	at.dcaState.mu.Lock()
+	for i := range deal.SafetyOrders {
+		so := &deal.SafetyOrders[i]
+		if so.State != "pending" || activeOrderIDs[so.OrderID] {
+			continue
+		}
+		status, err := at.trader.GetOrderStatus(symbol, so.OrderID)
+		if err != nil {
+			logger.Warnf("[DCA] Failed to get status of safety order %s: %v", so.OrderID, err)
+			continue
+		}
+		state, _ := status["status"].(string)
+		switch state {
+		case "FILLED":
+			fillPrice, _ := status["avgPrice"].(float64)
+			fillQty, _ := status["executedQty"].(float64)
+			if fillPrice <= 0 {
+				fillPrice = so.Price
+			}
+			if fillQty <= 0 {
+				fillQty = so.Quantity
+			}
+			so.State = "filled"
+			so.FilledPrice = fillPrice
+			so.FilledQty = fillQty
+			deal.AddFill(fillPrice, fillQty)
+			commission, _ := SafeFloat64(status, "commission")
+			deal.Fees += dcaFee(orderFill{Price: fillPrice, Quantity: fillQty, Fee: commission}, gridMakerFeeRate)
+			logger.Infof("🪙 [DCA] Safety order #%d filled at $%.4f, avg entry now $%.4f", so.Index+1, fillPrice, deal.AverageEntry())
+		case "CANCELED", "CANCELLED", "EXPIRED", "REJECTED":
+			so.State = "cancelled"
+			logger.Infof("[DCA] Safety order #%d %s", so.Index+1, strings.ToLower(state))
+		}
+	}
+	at.dcaState.mu.Unlock()
+
+	// Position closed outside the bot (stop loss, liquidation, manual close)
+	if size, _ := at.dcaExchangePosition(); size == 0 {
+		at.cancelDCASafetyOrders()
+		price, err := at.trader.GetMarketPrice(symbol)
+		if err != nil {
+			price = deal.AverageEntry()
+		}
+		// The closing fill is not ours to query; estimate its fee
+		at.finishDCADeal(price, price*deal.Quantity*gridTakerFeeRate, "position closed outside the DCA bot")
+	}
+}
+
+// dcaExchangePosition returns the size and entry price of the deal side position on the exchange (size -1 = unknown)
+func (at *AutoTrader) dcaExchangePosition() (float64, float64) {
+	dcaConfig := at.dcaState.Config
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		logger.Warnf("[DCA] Failed to get positions: %v", err)
+		return -1, 0
+	}
+	for _, pos := range positions {
+		if pos["symbol"] != dcaConfig.Symbol || pos["side"] != kernel.DCASide(dcaConfig) {
+			continue
+		}
+		size, _ := pos["positionAmt"].(float64)
+		entry, _ := pos["entryPrice"].(float64)
+		return math.Abs(size), entry
+	}
+	return 0, 0
+}
+
+// saveDCADeal persists the active deal and its safety orders
+func (at *AutoTrader) saveDCADeal(closeReason string) {
+	if at.store == nil {
+		return
+	}
+	at.dcaState.mu.Lock()
+	deal := at.dcaState.Deal
+	if deal == nil {
+		at.dcaState.mu.Unlock()
+		return
+	}
+	dealModel, orderModels := dcaDealToModel(at.id, deal)
+	at.dcaState.mu.Unlock()
+
+	dealModel.CloseReason = closeReason
+	if err := at.store.DCA().SaveDeal(dealModel, orderModels); err != nil {
+		logger.Warnf("[DCA] Failed to save deal %s: %v", deal.ID, err)
+	}
+}
+
+// dcaDealToModel converts a deal to its store models
+func dcaDealToModel(traderID string, deal *kernel.DCADeal) (*store.DCADealModel, []store.DCASafetyOrderModel) {
+	model := &store.DCADealModel{
+		ID:             deal.ID,
+		TraderID:       traderID,
+		Symbol:         deal.Symbol,
+		Side:           deal.Side,
+		Status:         deal.Status,
+		OpenedAt:       deal.OpenedAt,
+		BasePrice:      deal.BasePrice,
+		Quantity:       deal.Quantity,
+		CostUSD:        deal.CostUSD,
+		AvgEntryPrice:  deal.AverageEntry(),
+		SafetyFilled:   deal.FilledSafetyOrders(),
+		TrailingActive: deal.TrailingActive,
+		BestPrice:      deal.BestPrice,
+		ClosePrice:     deal.ClosePrice,
+		RealizedPnL:    deal.RealizedPnL,
+		Fees:           deal.Fees,
+	}
+	if !deal.ClosedAt.IsZero() {
+		closedAt := deal.ClosedAt
+		model.ClosedAt = &closedAt
+	}
+
+	orders := make([]store.DCASafetyOrderModel, 0, len(deal.SafetyOrders))
+	for _, so := range deal.SafetyOrders {
+		orders = append(orders, store.DCASafetyOrderModel{
+			ID:           fmt.Sprintf("%s_so%d", deal.ID, so.Index),
+			DealID:       deal.ID,
+			OrderIndex:   so.Index,
+			Price:        so.Price,
+			DeviationPct: so.DeviationPct,
+			OrderUSD:     so.OrderUSD,
+			Quantity:     so.Quantity,
+			State:        so.State,
+			OrderID:      so.OrderID,
+			FilledPrice:  so.FilledPrice,
+			FilledQty:    so.FilledQty,
+		})
+	}
+	return model, orders
+}
+
+// dcaDealFromModel restores a deal from its store models
+func dcaDealFromModel(model *store.DCADealModel, orders []store.DCASafetyOrderModel) *kernel.DCADeal {
+	deal := &kernel.DCADeal{
+		ID:             model.ID,
+		Symbol:         model.Symbol,
+		Side:           model.Side,
+		Status:         model.Status,
+		BasePrice:      model.BasePrice,
+		Quantity:       model.Quantity,
+		CostUSD:        model.CostUSD,
+		TrailingActive: model.TrailingActive,
+		BestPrice:      model.BestPrice,
+		OpenedAt:       model.OpenedAt,
+		ClosePrice:     model.ClosePrice,
+		RealizedPnL:    model.RealizedPnL,
+		Fees:           model.Fees,
+	}
+	for _, o := range orders {
+		deal.SafetyOrders = append(deal.SafetyOrders, kernel.DCASafetyOrder{
+			Index:        o.OrderIndex,
+			Price:        o.Price,
+			DeviationPct: o.DeviationPct,
+			OrderUSD:     o.OrderUSD,
+			Quantity:     o.Quantity,
+			State:        o.State,
+			OrderID:      o.OrderID,
+			FilledPrice:  o.FilledPrice,
+			FilledQty:    o.FilledQty,
+		})
+	}
+	return deal
+}
@@ -0,0 +1,479 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Copy Trading (follower traders)
+// ============================================================================
+//
+// A follower mirrors the executed decisions of a leader trader: it reads the leader's decision
+// log after its cursor, scales opens by the equity ratio (or a fixed multiplier) and executes them
+// on its own exchange through the regular execution path, so the follower's strategy risk control
+// still applies. The leader is already rate limited, so mirrored opens skip MinOpenInterval.
+
+// followRecordBatch max leader decision records read per cycle
+const followRecordBatch = 50
+
+// FollowState holds the runtime state of a follower trader
+type FollowState struct {
+	mu sync.Mutex
+
+	Config   *store.FollowConfig
+	LeaderID string
+
+	// Last leader decision record processed (persisted in copy_trade_cursors)
+	LastRecordID int64
+
+	IsInitialized bool
+}
+
+// IsFollower returns true if the trader follows a leader trader
+func (at *AutoTrader) IsFollower() bool {
+	return at.config.LeaderTraderID != "" && at.config.FollowConfig != nil
+}
+
+// InitializeFollower restores the follower cursor (a new or changed leader starts from its latest decision)
+func (at *AutoTrader) InitializeFollower() error {
+	if at.store == nil {
+		return fmt.Errorf("copy trading requires the store to read the leader's decisions")
+	}
+	leaderID := at.config.LeaderTraderID
+	if leaderID == at.id {
+		return fmt.Errorf("a trader cannot follow itself")
+	}
+	if _, err := at.store.Trader().GetByID(leaderID); err != nil {
+		return fmt.Errorf("leader trader %s not found: %w", leaderID, err)
+	}
+
+	state := &FollowState{Config: at.config.FollowConfig, LeaderID: leaderID}
+	cursor, err := at.store.CopyTrade().GetCursor(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load copy trade cursor: %w", err)
+	}
+	if cursor != nil && cursor.LeaderID == leaderID {
+		state.LastRecordID = cursor.LastRecordID
+	} else {
+		// Do not replay the leader's history
+		latest, err := at.store.Decision().GetLatestRecords(leaderID, 1)
+		if err != nil {
+			return fmt.Errorf("failed to load leader decisions: %w", err)
+		}
+		if len(latest) > 0 {
+			state.LastRecordID = latest[len(latest)-1].ID
+		}
+		if err := at.saveFollowCursor(state); err != nil {
+			return err
+		}
+	}
+
+	at.followState = state
+	at.followState.IsInitialized = true
+	logger.Infof("👥 [Follow] Following trader %s from decision #%d (sizing %s ×%.2f, max delay %ds)",
+		leaderID, state.LastRecordID, state.Config.SizingMode, state.Config.Multiplier, state.Config.MaxDelaySeconds)
+	return nil
+}
+
+// saveFollowCursor persists the follower's position in the leader's decision log
+func (at *AutoTrader) saveFollowCursor(state *FollowState) error {
+	err := at.store.CopyTrade().SaveCursor(&store.CopyTradeCursor{
+		FollowerID:   at.id,
+		LeaderID:     state.LeaderID,
+		LastRecordID: state.LastRecordID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save copy trade cursor: %w", err)
+	}
+	return nil
+}
+
+// RunFollowerCycle mirrors the leader decisions executed since the last cycle
+func (at *AutoTrader) RunFollowerCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Follow] Trader is stopped, aborting follower cycle")
+		return nil
+	}
+
+	if at.followState == nil || !at.followState.IsInitialized {
+		if err := at.InitializeFollower(); err != nil {
+			return fmt.Errorf("failed to initialize follower: %w", err)
+		}
+	}
+	state := at.followState
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	records, err := at.store.Decision().GetRecordsAfterID(state.LeaderID, state.LastRecordID, followRecordBatch)
+	if err != nil {
+		return fmt.Errorf("failed to read leader decisions: %w", err)
+	}
+
+	record := &store.DecisionRecord{ExecutionLog: []string{}, Success: true}
+	for _, leaderRecord := range records {
+		for i := range leaderRecord.Decisions {
+			action := &leaderRecord.Decisions[i]
+			if !action.Success {
+				continue
+			}
+			link, actionRecord := at.mirrorLeaderAction(state, leaderRecord, action)
+			if link == nil {
+				continue
+			}
+			if err := at.store.CopyTrade().SaveLink(link); err != nil {
+				logger.Warnf("[Follow] %v", err)
+			}
+			switch link.Status {
+			case store.CopyTradeFilled:
+				record.Decisions = append(record.Decisions, *actionRecord)
+				record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ Mirrored leader %s %s (ratio %.4f)", link.Action, link.FollowerSymbol, link.Ratio))
+			case store.CopyTradeFailed:
+				actionRecord.Success = false
+				actionRecord.Error = link.Error
+				record.Decisions = append(record.Decisions, *actionRecord)
+				record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ Leader %s %s failed: %s", link.Action, link.FollowerSymbol, link.Error))
+				record.Success = false
+			default:
+				record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("⏭ Leader %s %s skipped: %s", link.Action, link.LeaderSymbol, link.Error))
+			}
+		}
+		state.LastRecordID = leaderRecord.ID
+		if err := at.saveFollowCursor(state); err != nil {
+			logger.Warnf("[Follow] %v", err)
+		}
+	}
+
+	if len(record.ExecutionLog) > 0 {
+		record.ExecutionLog = append([]string{fmt.Sprintf("Following trader %s", state.LeaderID)}, record.ExecutionLog...)
+		at.saveDecision(record)
+	}
+	at.saveAccountEquitySnapshot()
+	return nil
+}
+
+// mirrorLeaderAction mirrors one executed leader action (nil link = nothing to mirror)
+func (at *AutoTrader) mirrorLeaderAction(state *FollowState, leaderRecord *store.DecisionRecord, action *store.DecisionAction) (*store.CopyTradeLink, *store.DecisionAction) {
+	cfg := state.Config
+	symbol := followSymbol(cfg, action.Symbol)
+	leaderTime := action.Timestamp
+	if leaderTime.IsZero() {
+		leaderTime = leaderRecord.Timestamp
+	}
+	link := &store.CopyTradeLink{
+		FollowerID:     at.id,
+		LeaderID:       state.LeaderID,
+		LeaderRecordID: leaderRecord.ID,
+		Action:         action.Action,
+		LeaderSymbol:   action.Symbol,
+		LeaderQuantity: action.Quantity,
+		LeaderPrice:    action.Price,
+		LeaderOrderID:  action.OrderID,
+		LeaderTime:     leaderTime,
+		FollowerSymbol: symbol,
+	}
+	skip := func(reason string) (*store.CopyTradeLink, *store.DecisionAction) {
+		link.Status = store.CopyTradeSkipped
+		link.Error = reason
+		logger.Infof("⏭ [Follow] Leader %s %s not mirrored: %s", action.Action, action.Symbol, reason)
+		return link, nil
+	}
+
+	// Only position opens, closes and SL/TP updates carry over; limit orders,
+	// cancels and grid/DCA actions belong to the leader's own order book
+	isOpen := action.Action == "open_long" || action.Action == "open_short"
+	isClose := action.Action == "close_long" || action.Action == "close_short" ||
+		action.Action == "partial_close" || action.Action == "PARTIAL_CLOSE"
+	isHold := action.Action == "hold" || action.Action == "wait"
+	if !isOpen && !isClose && !isHold || isHold && action.StopLoss <= 0 && action.TakeProfit <= 0 {
+		return nil, nil
+	}
+	if delay := time.Since(leaderTime); delay > time.Duration(cfg.MaxDelaySeconds)*time.Second {
+		return skip(fmt.Sprintf("leader decision is %s old", delay.Round(time.Second)))
+	}
+
+	decision := &kernel.Decision{
+		Symbol:     symbol,
+		Action:     action.Action,
+		Leverage:   action.Leverage,
+		StopLoss:   action.StopLoss,
+		TakeProfit: action.TakeProfit,
+		Confidence: action.Confidence,
+		Reasoning:  fmt.Sprintf("Copy of leader %s decision #%d", state.LeaderID, leaderRecord.ID),
+	}
+
+	if isOpen {
+		equity, _, margin, _, err := at.accountState()
+		if err != nil {
+			return skip(err.Error())
+		}
+		ratio, err := followRatio(cfg, equity, leaderRecord.AccountState.TotalBalance)
+		if err != nil {
+			return skip(err.Error())
+		}
+		link.Ratio = ratio
+		size, leverage, err := capFollowOpen(cfg, action.Quantity*action.Price*ratio, action.Leverage, equity, margin)
+		if err != nil {
+			return skip(err.Error())
+		}
+		decision.PositionSizeUSD = size
+		decision.Leverage = leverage
+	} else {
+		side := at.followerPositionSide(symbol)
+		if side == "" {
+			return skip("follower has no position")
+		}
+		if action.Action == "close_long" && side != "long" || action.Action == "close_short" && side != "short" {
+			return skip(fmt.Sprintf("follower position is %s", side))
+		}
+		if action.Action == "partial_close" || action.Action == "PARTIAL_CLOSE" {
+			pct := leaderClosePercentage(leaderRecord, action)
+			if pct <= 0 {
+				return skip("leader close percentage unknown")
+			}
+			decision.ClosePercentage = pct
+		}
+	}
+
+	actionRecord := &store.DecisionAction{
+		Action:          decision.Action,
+		Symbol:          symbol,
+		Leverage:        decision.Leverage,
+		StopLoss:        decision.StopLoss,
+		TakeProfit:      decision.TakeProfit,
+		ClosePercentage: decision.ClosePercentage,
+		Confidence:      decision.Confidence,
+		Reasoning:       decision.Reasoning,
+		Timestamp:       time.Now().UTC(),
+	}
+	if err := at.executeDecision(decision, actionRecord, true); err != nil {
+		link.Status = store.CopyTradeFailed
+		link.Error = err.Error()
+		logger.Warnf("[Follow] Failed to mirror leader %s %s: %v", action.Action, symbol, err)
+		return link, actionRecord
+	}
+	actionRecord.Success = true
+	link.Status = store.CopyTradeFilled
+	link.FollowerQuantity = actionRecord.Quantity
+	link.FollowerPrice = actionRecord.Price
+	link.FollowerOrderID = actionRecord.OrderID
+	logger.Infof("👥 [Follow] Mirrored leader %s %s → %s (order %d)", action.Action, action.Symbol, symbol, actionRecord.OrderID)
+	return link, actionRecord
+}
+
+// leaderClosePercentage returns the share of its position the leader closed in a partial close:
+// the recorded percentage, else the closed quantity against the position held before the cycle
+// (0 = unknown)
+func leaderClosePercentage(leaderRecord *store.DecisionRecord, action *store.DecisionAction) float64 {
+	if action.ClosePercentage > 0 {
+		return math.Min(action.ClosePercentage, 1)
+	}
+	if action.Quantity <= 0 {
+		return 0
+	}
+	for _, pos := range leaderRecord.Positions {
+		if pos.Symbol == action.Symbol && pos.PositionAmt != 0 {
+			return math.Min(action.Quantity/math.Abs(pos.PositionAmt), 1)
+		}
+	}
+	return 0
+}
+
+// followerPositionSide returns the side of the follower's position on a symbol ("" = none)
+func (at *AutoTrader) followerPositionSide(symbol string) string {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return ""
+	}
+	for _, pos := range positions {
+		if pos["symbol"] != symbol {
+			continue
+		}
+		if size, _ := pos["positionAmt"].(float64); size != 0 {
+			side, _ := pos["side"].(string)
+			return side
+		}
+	}
+	return ""
+}
+
+// followSymbol maps a leader symbol to the follower's symbol (explicit override, else the
+// normalized symbol that every exchange adapter accepts)
+func followSymbol(cfg *store.FollowConfig, symbol string) string {
+	if mapped, ok := cfg.SymbolMap[symbol]; ok && mapped != "" {
+		return mapped
+	}
+	return market.Normalize(symbol)
+}
+
+// followRatio returns the follower/leader size ratio
+func followRatio(cfg *store.FollowConfig, followerEquity, leaderEquity float64) (float64, error) {
+	if cfg.SizingMode == store.FollowSizingMultiplier {
+		return cfg.Multiplier, nil
+	}
+	if leaderEquity <= 0 {
+		return 0, fmt.Errorf("leader equity unknown")
+	}
+	if followerEquity <= 0 {
+		return 0, fmt.Errorf("follower equity unknown")
+	}
+	return followerEquity / leaderEquity * cfg.Multiplier, nil
+}
+
+// capFollowOpen applies the follower risk caps to a mirrored open and returns its size and leverage
+func capFollowOpen(cfg *store.FollowConfig, sizeUSD float64, leverage int, equity, marginUsed float64) (float64, int, error) {
+	if leverage < 1 {
+		leverage = 1
+	}
+	if cfg.MaxLeverage > 0 && leverage > cfg.MaxLeverage {
+		leverage = cfg.MaxLeverage
+	}
+	if cfg.MaxPositionUSD > 0 && sizeUSD > cfg.MaxPositionUSD {
+		sizeUSD = cfg.MaxPositionUSD
+	}
+	if cfg.MaxMarginUsage > 0 {
+		room := equity*cfg.MaxMarginUsage - marginUsed
+		if room <= 0 {
+			return 0, 0, fmt.Errorf("margin usage at cap (%.0f%%)", cfg.MaxMarginUsage*100)
+		}
+		if sizeUSD/float64(leverage) > room {
+			sizeUSD = room * float64(leverage)
+		}
+	}
+	if sizeUSD <= 0 {
+		return 0, 0, fmt.Errorf("scaled size is zero")
+	}
+	return sizeUSD, leverage, nil
+}
+
+// ============================================================================
+// Tracking Error
+// ============================================================================
+
+// CopySymbolDrift exposure of one position on the leader and the follower
+type CopySymbolDrift struct {
+	Symbol           string  `json:"symbol"` // Follower symbol
+	Side             string  `json:"side"`
+	LeaderNotional   float64 `json:"leader_notional"`
+	TargetNotional   float64 `json:"target_notional"` // Leader notional × ratio
+	FollowerNotional float64 `json:"follower_notional"`
+}
+
+// CopyTrackingReport how closely a follower tracks its leader
+type CopyTrackingReport struct {
+	Samples           int               `json:"samples"`            // Aligned equity return intervals
+	TrackingErrorPct  float64           `json:"tracking_error_pct"` // Std dev of the per-interval return difference
+	LeaderReturnPct   float64           `json:"leader_return_pct"`
+	FollowerReturnPct float64           `json:"follower_return_pct"`
+	Ratio             float64           `json:"ratio"`              // Current size ratio
+	ExposureDriftPct  float64           `json:"exposure_drift_pct"` // Σ|follower − target notional| / follower equity
+	Positions         []CopySymbolDrift `json:"positions"`
+}
+
+// CopyTracking compares a follower with its leader: return tracking error over the aligned equity
+// curves (both ascending) and the current position exposure drift
+func CopyTracking(cfg *store.FollowConfig, leaderCurve, followerCurve []*store.EquitySnapshot,
+	leaderPositions, followerPositions []*store.TraderPosition) *CopyTrackingReport {
+	report := &CopyTrackingReport{Positions: []CopySymbolDrift{}}
+
+	// Align every follower snapshot with the latest leader snapshot at or before it
+	type pair struct{ leader, follower float64 }
+	var pairs []pair
+	var lastLeader *store.EquitySnapshot
+	j := 0
+	for _, f := range followerCurve {
+		for j < len(leaderCurve) && !leaderCurve[j].Timestamp.After(f.Timestamp) {
+			j++
+		}
+		if j == 0 {
+			continue
+		}
+		l := leaderCurve[j-1]
+		if l == lastLeader || l.TotalEquity <= 0 || f.TotalEquity <= 0 {
+			continue
+		}
+		lastLeader = l
+		pairs = append(pairs, pair{l.TotalEquity, f.TotalEquity})
+	}
+	if len(pairs) >= 2 {
+		diffs := make([]float64, 0, len(pairs)-1)
+		for i := 1; i < len(pairs); i++ {
+			leaderRet := pairs[i].leader/pairs[i-1].leader - 1
+			followerRet := pairs[i].follower/pairs[i-1].follower - 1
+			diffs = append(diffs, followerRet-leaderRet)
+		}
+		var mean float64
+		for _, d := range diffs {
+			mean += d
+		}
+		mean /= float64(len(diffs))
+		var variance float64
+		for _, d := range diffs {
+			variance += (d - mean) * (d - mean)
+		}
+		report.Samples = len(diffs)
+		report.TrackingErrorPct = math.Sqrt(variance/float64(len(diffs))) * 100
+		first, last := pairs[0], pairs[len(pairs)-1]
+		report.LeaderReturnPct = (last.leader/first.leader - 1) * 100
+		report.FollowerReturnPct = (last.follower/first.follower - 1) * 100
+	}
+
+	// Exposure drift against the latest equities
+	var leaderEquity, followerEquity float64
+	if n := len(leaderCurve); n > 0 {
+		leaderEquity = leaderCurve[n-1].TotalEquity
+	}
+	if n := len(followerCurve); n > 0 {
+		followerEquity = followerCurve[n-1].TotalEquity
+	}
+	ratio, err := followRatio(cfg, followerEquity, leaderEquity)
+	if err != nil {
+		return report
+	}
+	report.Ratio = ratio
+
+	drifts := make(map[string]*CopySymbolDrift)
+	entry := func(symbol, side string) *CopySymbolDrift {
+		key := symbol + "_" + strings.ToLower(side)
+		if d, ok := drifts[key]; ok {
+			return d
+		}
+		d := &CopySymbolDrift{Symbol: symbol, Side: strings.ToLower(side)}
+		drifts[key] = d
+		return d
+	}
+	for _, pos := range leaderPositions {
+		d := entry(followSymbol(cfg, pos.Symbol), pos.Side)
+		d.LeaderNotional += pos.Quantity * pos.EntryPrice
+		d.TargetNotional += pos.Quantity * pos.EntryPrice * ratio
+	}
+	for _, pos := range followerPositions {
+		entry(pos.Symbol, pos.Side).FollowerNotional += pos.Quantity * pos.EntryPrice
+	}
+	var drift float64
+	for _, d := range drifts {
+		drift += math.Abs(d.FollowerNotional - d.TargetNotional)
+		report.Positions = append(report.Positions, *d)
+	}
+	sort.Slice(report.Positions, func(i, j int) bool {
+		if report.Positions[i].Symbol != report.Positions[j].Symbol {
+			return report.Positions[i].Symbol < report.Positions[j].Symbol
+		}
+		return report.Positions[i].Side < report.Positions[j].Side
+	})
+	if followerEquity > 0 {
+		report.ExposureDriftPct = drift / followerEquity * 100
+	}
+	return report
+}
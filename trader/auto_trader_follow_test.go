@@ -0,0 +1,135 @@
+package trader
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func TestFollowSizing(t *testing.T) {
+	ratioCfg := &store.FollowConfig{SizingMode: store.FollowSizingEquityRatio, Multiplier: 1}
+	if r, err := followRatio(ratioCfg, 2000, 10000); err != nil || math.Abs(r-0.2) > 1e-9 {
+		t.Errorf("equity ratio = %v, %v; want 0.2", r, err)
+	}
+	if _, err := followRatio(ratioCfg, 2000, 0); err == nil {
+		t.Error("unknown leader equity should fail")
+	}
+	multCfg := &store.FollowConfig{SizingMode: store.FollowSizingMultiplier, Multiplier: 0.5}
+	if r, _ := followRatio(multCfg, 2000, 0); r != 0.5 {
+		t.Errorf("multiplier ratio = %v, want 0.5", r)
+	}
+
+	caps := &store.FollowConfig{MaxPositionUSD: 1000, MaxLeverage: 5, MaxMarginUsage: 0.5}
+	size, lev, err := capFollowOpen(caps, 3000, 10, 1000, 0)
+	if err != nil || size != 1000 || lev != 5 {
+		t.Errorf("capped open = %.2f %dx %v, want 1000 5x", size, lev, err)
+	}
+	// 450 margin used of a 500 cap leaves 50 margin = 250 notional at 5x
+	size, _, _ = capFollowOpen(caps, 1000, 5, 1000, 450)
+	if math.Abs(size-250) > 1e-9 {
+		t.Errorf("margin capped size = %.2f, want 250", size)
+	}
+	if _, _, err := capFollowOpen(caps, 1000, 5, 1000, 500); err == nil {
+		t.Error("open at the margin cap should be skipped")
+	}
+}
+
+func TestCopyTracking(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	curve := func(traderID string, offset time.Duration, equities ...float64) []*store.EquitySnapshot {
+		var snapshots []*store.EquitySnapshot
+		for i, eq := range equities {
+			snapshots = append(snapshots, &store.EquitySnapshot{TraderID: traderID, Timestamp: base.Add(time.Duration(i)*time.Hour + offset), TotalEquity: eq})
+		}
+		return snapshots
+	}
+	cfg := &store.FollowConfig{SizingMode: store.FollowSizingEquityRatio, Multiplier: 1, SymbolMap: map[string]string{"XAUUSDT": "PAXGUSDT"}}
+
+	// A follower at 1/10 of the leader with identical returns has no tracking error
+	leader := curve("leader", 0, 10000, 10500, 10290)
+	follower := curve("follower", time.Minute, 1000, 1050, 1029)
+	positions := []*store.TraderPosition{
+		{Symbol: "BTCUSDT", Side: "LONG", Quantity: 0.1, EntryPrice: 50000},
+		{Symbol: "XAUUSDT", Side: "SHORT", Quantity: 1, EntryPrice: 2000},
+	}
+	followerPositions := []*store.TraderPosition{
+		{Symbol: "BTCUSDT", Side: "LONG", Quantity: 0.01, EntryPrice: 50000},
+	}
+	report := CopyTracking(cfg, leader, follower, positions, followerPositions)
+	if report.Samples != 2 || report.TrackingErrorPct > 1e-9 {
+		t.Errorf("tracking error = %.6f%% over %d samples, want 0 over 2", report.TrackingErrorPct, report.Samples)
+	}
+	if math.Abs(report.Ratio-0.1) > 1e-9 {
+		t.Errorf("ratio = %v, want 0.1", report.Ratio)
+	}
+	// Missing PAXG short: 200 target notional of 1029 equity
+	if len(report.Positions) != 2 || report.Positions[1].Symbol != "PAXGUSDT" {
+		t.Fatalf("positions = %+v", report.Positions)
+	}
+	if want := 200 / 1029.0 * 100; math.Abs(report.ExposureDriftPct-want) > 1e-6 {
+		t.Errorf("exposure drift = %.4f%%, want %.4f%%", report.ExposureDriftPct, want)
+	}
+
+	// Diverging returns produce tracking error
+	follower = curve("follower", time.Minute, 1000, 1020, 1040)
+	if report := CopyTracking(cfg, leader, follower, nil, nil); report.TrackingErrorPct <= 0 {
+		t.Error("diverging follower should have tracking error")
+	}
+}
+
+func TestLeaderClosePercentage(t *testing.T) {
+	record := &store.DecisionRecord{Positions: []store.PositionSnapshot{
+		{Symbol: "BTCUSDT", Side: "short", PositionAmt: -0.4},
+	}}
+	cases := []struct {
+		name   string
+		action store.DecisionAction
+		want   float64
+	}{
+		{"recorded percentage", store.DecisionAction{Symbol: "BTCUSDT", ClosePercentage: 0.3, Quantity: 0.1}, 0.3},
+		{"closed quantity against the leader position", store.DecisionAction{Symbol: "BTCUSDT", Quantity: 0.1}, 0.25},
+		{"capped at the whole position", store.DecisionAction{Symbol: "BTCUSDT", Quantity: 0.5}, 1},
+		{"no leader position", store.DecisionAction{Symbol: "ETHUSDT", Quantity: 1}, 0},
+	}
+	for _, c := range cases {
+		if got := leaderClosePercentage(record, &c.action); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("%s: close percentage = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMirroredOpensSkipCalibratedConfidence(t *testing.T) {
+	at := &AutoTrader{calibratedMinConf: 70}
+	if err := at.enforceMinConfidence(50, false); err == nil {
+		t.Error("an own open below the calibrated minimum should be rejected")
+	}
+	if err := at.enforceMinConfidence(50, true); err != nil {
+		t.Errorf("a mirrored leader open should not be vetoed by the follower's calibration: %v", err)
+	}
+}
+
+func TestMirrorLeaderActionIgnoresOrderBookActions(t *testing.T) {
+	at := &AutoTrader{id: "follower"}
+	state := &FollowState{LeaderID: "leader", Config: &store.FollowConfig{MaxDelaySeconds: 60}}
+	record := &store.DecisionRecord{ID: 1, Timestamp: time.Now().Add(-time.Hour)}
+
+	// Stale on purpose: anything that gets past the action filter is skipped for its delay
+	for _, action := range []string{"place_long_limit", "place_short_limit", "cancel_order", "grid_place", "dca_buy", "hold"} {
+		link, decision := at.mirrorLeaderAction(state, record, &store.DecisionAction{Action: action, Symbol: "BTCUSDT"})
+		if link != nil || decision != nil {
+			t.Errorf("%s should not be mirrored, got link %+v", action, link)
+		}
+	}
+	for _, action := range []string{"open_long", "close_short", "partial_close"} {
+		link, _ := at.mirrorLeaderAction(state, record, &store.DecisionAction{Action: action, Symbol: "BTCUSDT"})
+		if link == nil || link.Status != store.CopyTradeSkipped {
+			t.Errorf("%s should be considered for mirroring, got link %+v", action, link)
+		}
+	}
+	link, _ := at.mirrorLeaderAction(state, record, &store.DecisionAction{Action: "hold", Symbol: "BTCUSDT", StopLoss: 90000})
+	if link == nil {
+		t.Error("a hold that moves the stop loss should be considered for mirroring")
+	}
+}
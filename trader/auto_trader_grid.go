@@ -2,12 +2,14 @@ package trader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"nofx/kernel"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/store"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,6 +25,10 @@ type GridState struct {
 	// Configuration
 	Config *store.GridStrategyConfig
 
+	// Persisted instance (grid_instances), set once during initialization
+	InstanceID string
+	StartedAt  time.Time
+
 	// Grid levels
 	Levels []kernel.GridLevelInfo
 
@@ -65,11 +71,22 @@ type GridState struct {
 
 	// Current regime level
 	CurrentRegimeLevel string
+	LastRegimeCheck    time.Time
 
 	// Grid direction adjustment
 	CurrentDirection       market.GridDirection
 	DirectionChangedAt     time.Time
 	DirectionChangeCount   int
+
+	// Last AI re-tuning (deterministic mode)
+	LastTunedAt time.Time
+
+	// Trailing grid shifts
+	TrailCount  int
+	LastTrailAt time.Time
+
+	// Round trips closed since the last save (grid_round_trips)
+	closedTrips []kernel.GridRoundTrip
 }
 
 // NewGridState creates a new grid state
@@ -98,7 +115,7 @@ const (
 // checkBreakout detects if price has broken out of grid range
 // Returns breakout type and percentage beyond boundary
 func (at *AutoTrader) checkBreakout() (BreakoutType, float64) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	currentPrice, err := at.trader.GetMarketPrice(gridConfig.Symbol)
 	if err != nil {
@@ -129,10 +146,66 @@ func (at *AutoTrader) checkBreakout() (BreakoutType, float64) {
 	return BreakoutNone, 0
 }
 
+// trailGrid shifts a trailing grid by whole steps so the price is inside the range again.
+// Returns false when the grid does not trail or a trailing limit stops it, so the breakout is handled as usual.
+func (at *AutoTrader) trailGrid() bool {
+	gridConfig := at.gridState.Config
+	if !gridConfig.Trailing {
+		return false
+	}
+
+	currentPrice, err := at.trader.GetMarketPrice(gridConfig.Symbol)
+	if err != nil {
+		logger.Warnf("[Grid] Failed to get price for trailing: %v", err)
+		return false
+	}
+
+	at.gridState.mu.RLock()
+	lower := at.gridState.LowerPrice
+	upper := at.gridState.UpperPrice
+	at.gridState.mu.RUnlock()
+
+	newLower, newUpper, steps := kernel.GridTrailBounds(lower, upper, gridConfig.GridCount, kernel.IsGeometricGrid(gridConfig), currentPrice)
+	if steps == 0 {
+		return true // Price moved back into the range
+	}
+	if newLower <= 0 ||
+		(gridConfig.TrailingUpperLimit > 0 && newUpper > gridConfig.TrailingUpperLimit) ||
+		(gridConfig.TrailingLowerLimit > 0 && newLower < gridConfig.TrailingLowerLimit) {
+		logger.Warnf("[Grid] Trailing limit reached ($%.2f - $%.2f), not shifting to $%.2f - $%.2f",
+			gridConfig.TrailingLowerLimit, gridConfig.TrailingUpperLimit, newLower, newUpper)
+		return false
+	}
+
+	// Cancel existing orders first, the ladder is rebuilt on the new bounds
+	if err := at.cancelAllGridOrders(); err != nil {
+		logger.Errorf("[Grid] Failed to cancel orders during trailing: %v", err)
+	}
+
+	at.gridState.mu.Lock()
+	at.gridState.LowerPrice = newLower
+	at.gridState.UpperPrice = newUpper
+	at.regridLocked(currentPrice, gridConfig)
+	at.gridState.TrailCount++
+	at.gridState.LastTrailAt = time.Now()
+	trailCount := at.gridState.TrailCount
+	at.gridState.mu.Unlock()
+
+	logger.Infof("🔁 [Grid] Trailed %+d steps with price $%.2f: $%.2f - $%.2f (trail #%d)",
+		steps, currentPrice, newLower, newUpper, trailCount)
+	at.recordGridEvent(store.GridEventModel{
+		EventType:   store.GridEventTrailed,
+		TriggerType: "trailing",
+		Price:       currentPrice,
+		Message:     fmt.Sprintf("trailed %+d steps: $%.2f - $%.2f → $%.2f - $%.2f", steps, lower, upper, newLower, newUpper),
+	})
+	return true
+}
+
 // checkMaxDrawdown checks if current drawdown exceeds maximum allowed
 // Returns: (exceeded bool, currentDrawdown float64)
 func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.strategyConfig().GridConfig
 	if gridConfig.MaxDrawdownPct <= 0 {
 		return false, 0
 	}
@@ -156,13 +229,13 @@ func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 		return false, 0
 	}
 
-	// Update peak equity
-	at.gridState.mu.Lock()
-	if currentEquity > at.gridState.PeakEquity {
-		at.gridState.PeakEquity = currentEquity
+	// Update peak equity (every symbol grid tracks the same account equity)
+	peakEquity := currentEquity
+	for _, gs := range at.gridStates {
+		gs.mu.RLock()
+		peakEquity = math.Max(peakEquity, gs.PeakEquity)
+		gs.mu.RUnlock()
 	}
-	peakEquity := at.gridState.PeakEquity
-	at.gridState.mu.Unlock()
 
 	if peakEquity <= 0 {
 		return false, 0
@@ -171,12 +244,15 @@ func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 	// Calculate current drawdown
 	drawdown := (peakEquity - currentEquity) / peakEquity * 100
 
-	// Update max drawdown tracking
-	at.gridState.mu.Lock()
-	if drawdown > at.gridState.MaxDrawdown {
-		at.gridState.MaxDrawdown = drawdown
+	// Update peak and max drawdown tracking
+	for _, gs := range at.gridStates {
+		gs.mu.Lock()
+		gs.PeakEquity = peakEquity
+		if drawdown > gs.MaxDrawdown {
+			gs.MaxDrawdown = drawdown
+		}
+		gs.mu.Unlock()
 	}
-	at.gridState.mu.Unlock()
 
 	return drawdown >= gridConfig.MaxDrawdownPct, drawdown
 }
@@ -184,21 +260,24 @@ func (at *AutoTrader) checkMaxDrawdown() (bool, float64) {
 // checkDailyLossLimit checks if daily loss exceeds limit
 // Returns: (exceeded bool, dailyLossPct float64)
 func (at *AutoTrader) checkDailyLossLimit() (bool, float64) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.strategyConfig().GridConfig
 	if gridConfig.DailyLossLimitPct <= 0 {
 		return false, 0
 	}
 
-	at.gridState.mu.Lock()
-	// Reset daily PnL if new day
+	// Combined PnL of every symbol grid, reset if new day
 	now := time.Now()
-	if now.YearDay() != at.gridState.LastDailyReset.YearDay() ||
-		now.Year() != at.gridState.LastDailyReset.Year() {
-		at.gridState.DailyPnL = 0
-		at.gridState.LastDailyReset = now
+	dailyPnL := 0.0
+	for _, gs := range at.gridStates {
+		gs.mu.Lock()
+		if now.YearDay() != gs.LastDailyReset.YearDay() ||
+			now.Year() != gs.LastDailyReset.Year() {
+			gs.DailyPnL = 0
+			gs.LastDailyReset = now
+		}
+		dailyPnL += gs.DailyPnL
+		gs.mu.Unlock()
 	}
-	dailyPnL := at.gridState.DailyPnL
-	at.gridState.mu.Unlock()
 
 	// Calculate daily loss as percentage of total investment
 	dailyLossPct := 0.0
@@ -219,7 +298,7 @@ func (at *AutoTrader) updateDailyPnL(realizedPnL float64) {
 
 // emergencyExit closes all positions and cancels all orders
 func (at *AutoTrader) emergencyExit(reason string) error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	logger.Errorf("[Grid] EMERGENCY EXIT: %s", reason)
 
@@ -249,6 +328,7 @@ func (at *AutoTrader) emergencyExit(reason string) error {
 	at.gridState.IsPaused = true
 	at.gridState.mu.Unlock()
 
+	at.recordGridEvent(store.GridEventModel{EventType: store.GridEventEmergencyExit, Message: reason})
 	return nil
 }
 
@@ -270,6 +350,11 @@ func (at *AutoTrader) handleBreakout(breakoutType BreakoutType, breakoutPct floa
 		at.gridState.IsPaused = true
 		at.gridState.mu.Unlock()
 
+		at.recordGridEvent(store.GridEventModel{
+			EventType:   store.GridEventBreakout,
+			TriggerType: "range",
+			Message:     fmt.Sprintf("%s breakout %.2f%% beyond grid range, grid paused", breakoutType, breakoutPct),
+		})
 		return fmt.Errorf("grid paused due to %s breakout (%.2f%%)", breakoutType, breakoutPct)
 	}
 
@@ -284,7 +369,7 @@ func (at *AutoTrader) handleBreakout(breakoutType BreakoutType, breakoutPct floa
 
 // checkBoxBreakout checks for multi-period box breakouts and takes appropriate action
 func (at *AutoTrader) checkBoxBreakout() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 	if gridConfig == nil {
 		return nil
 	}
@@ -335,6 +420,13 @@ func (at *AutoTrader) checkBoxBreakout() error {
 	enableDirectionAdjust := gridConfig.EnableDirectionAdjust
 	action := getBreakoutActionWithDirection(breakoutLevel, enableDirectionAdjust)
 
+	at.recordGridEvent(store.GridEventModel{
+		EventType:   store.GridEventBreakout,
+		Price:       box.CurrentPrice,
+		TriggerType: string(breakoutLevel),
+		Message:     fmt.Sprintf("%s box breakout %s confirmed", breakoutLevel, direction),
+	})
+
 	// If direction adjustment action, determine the new direction
 	if action == BreakoutActionAdjustDirection {
 		box, _ := market.GetBoxData(gridConfig.Symbol)
@@ -408,7 +500,7 @@ func (at *AutoTrader) executeDirectionAdjustment(newDirection market.GridDirecti
 
 // closeAllPositions closes all open positions for the grid symbol
 func (at *AutoTrader) closeAllPositions() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 	if gridConfig == nil {
 		return nil
 	}
@@ -444,7 +536,7 @@ func (at *AutoTrader) closeAllPositions() error {
 
 // checkFalseBreakoutRecovery checks if price has returned to box after breakout
 func (at *AutoTrader) checkFalseBreakoutRecovery() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 	if gridConfig == nil {
 		return nil
 	}
@@ -483,6 +575,12 @@ func (at *AutoTrader) checkFalseBreakoutRecovery() error {
 		at.gridState.PositionReductionPct = 50 // Recover at 50%
 		at.gridState.IsPaused = false
 		at.gridState.mu.Unlock()
+
+		at.recordGridEvent(store.GridEventModel{
+			EventType: store.GridEventResumed,
+			Price:     box.CurrentPrice,
+			Message:   "false breakout recovery, position reduced to 50%",
+		})
 	}
 
 	// Check for direction recovery toward neutral (if direction adjustment is enabled)
@@ -504,15 +602,78 @@ func (at *AutoTrader) checkFalseBreakoutRecovery() error {
 // AutoTrader Grid Methods
 // ============================================================================
 
-// InitializeGrid initializes the grid state and calculates levels
+// InitializeGrid initializes the grid state and calculates levels.
+// A multi-symbol grid gets one state per symbol, each with its share of the total investment.
 func (at *AutoTrader) InitializeGrid() error {
-	if at.config.StrategyConfig == nil || at.config.StrategyConfig.GridConfig == nil {
+	cfg := at.strategyConfig()
+	if cfg == nil || cfg.GridConfig == nil {
 		return fmt.Errorf("grid configuration not found")
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	poolConfig := cfg.GridConfig
+	var volatility map[string]float64
+	if kernel.IsMultiSymbolGrid(poolConfig) && poolConfig.Allocation == kernel.GridAllocationVolatility {
+		volatility = at.gridSymbolVolatility(kernel.GridSymbolList(poolConfig))
+	}
+
+	states := make([]*GridState, 0, len(poolConfig.Symbols)+1)
+	for _, gridConfig := range kernel.GridSymbolConfigs(poolConfig, volatility) {
+		if err := at.initializeGridState(gridConfig); err != nil {
+			return fmt.Errorf("%s: %w", gridConfig.Symbol, err)
+		}
+		states = append(states, at.gridState)
+	}
+	at.setGridStates(states)
+	at.gridState = states[0]
+
+	if len(states) > 1 {
+		parts := make([]string, len(states))
+		for i, gs := range states {
+			parts[i] = fmt.Sprintf("%s $%.2f", gs.Config.Symbol, gs.Config.TotalInvestment)
+		}
+		logger.Infof("📊 [Grid] Multi-symbol grid initialized (%s allocation): %s",
+			gridAllocationName(poolConfig), strings.Join(parts, ", "))
+	}
+	return nil
+}
+
+// gridAllocationName returns the capital allocation mode of a multi-symbol grid
+func gridAllocationName(config *store.GridStrategyConfig) string {
+	if config.Allocation == "" {
+		return kernel.GridAllocationFixed
+	}
+	return config.Allocation
+}
+
+// gridSymbolVolatility returns the 4h ATR% of each symbol (symbols without data are left out)
+func (at *AutoTrader) gridSymbolVolatility(symbols []string) map[string]float64 {
+	volatility := make(map[string]float64, len(symbols))
+	for _, symbol := range symbols {
+		mktData, err := market.GetWithTimeframes(symbol, []string{"4h"}, "4h", 20, nil, nil)
+		if err != nil || mktData.CurrentPrice <= 0 || mktData.LongerTermContext == nil {
+			logger.Warnf("[Grid] No volatility for %s, using the average: %v", symbol, err)
+			continue
+		}
+		volatility[symbol] = mktData.LongerTermContext.ATR14 / mktData.CurrentPrice * 100
+	}
+	return volatility
+}
+
+// initializeGridState creates the state of one symbol grid, restoring its last instance when there is one
+func (at *AutoTrader) initializeGridState(gridConfig *store.GridStrategyConfig) error {
 	at.gridState = NewGridState(gridConfig)
 
+	// Resume the last grid instance after a restart
+	restored, err := at.restoreGrid()
+	if err != nil {
+		return err
+	}
+	if restored {
+		at.gridState.IsInitialized = true
+		at.setGridLeverage()
+		return nil
+	}
+
 	// Get current market price
 	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
 	if err != nil {
@@ -542,6 +703,18 @@ func (at *AutoTrader) InitializeGrid() error {
 	at.initializeGridLevels(price, gridConfig)
 
 	at.gridState.IsInitialized = true
+	at.setGridLeverage()
+
+	logger.Infof("📊 [Grid] Initialized: %d levels, $%.2f - $%.2f, spacing $%.2f",
+		gridConfig.GridCount, at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing)
+
+	at.startGridInstance()
+	return nil
+}
+
+// setGridLeverage sets the grid leverage on the exchange
+func (at *AutoTrader) setGridLeverage() {
+	gridConfig := at.gridState.Config
 
 	// CRITICAL: Set leverage on exchange before trading
 	if err := at.trader.SetLeverage(gridConfig.Symbol, gridConfig.Leverage); err != nil {
@@ -550,19 +723,12 @@ func (at *AutoTrader) InitializeGrid() error {
 	} else {
 		logger.Infof("[Grid] Leverage set to %dx for %s", gridConfig.Leverage, gridConfig.Symbol)
 	}
-
-	logger.Infof("📊 [Grid] Initialized: %d levels, $%.2f - $%.2f, spacing $%.2f",
-		gridConfig.GridCount, at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing)
-
-	return nil
 }
 
 // calculateDefaultBounds calculates default bounds based on price
 func (at *AutoTrader) calculateDefaultBounds(price float64, config *store.GridStrategyConfig) {
-	// Default: ±3% from current price
-	multiplier := 0.03 * float64(config.GridCount) / 10
-	at.gridState.UpperPrice = price * (1 + multiplier)
-	at.gridState.LowerPrice = price * (1 - multiplier)
+	// Default: ±3% from current price, scaled by grid count
+	at.gridState.UpperPrice, at.gridState.LowerPrice = kernel.GridBounds(price, 0, config)
 }
 
 // calculateATRBounds calculates bounds using ATR
@@ -571,66 +737,12 @@ func (at *AutoTrader) calculateATRBounds(price float64, mktData *market.Data, co
 	if mktData.LongerTermContext != nil {
 		atr = mktData.LongerTermContext.ATR14
 	}
-
-	if atr <= 0 {
-		at.calculateDefaultBounds(price, config)
-		return
-	}
-
-	multiplier := config.ATRMultiplier
-	if multiplier <= 0 {
-		multiplier = 2.0
-	}
-
-	halfRange := atr * multiplier
-	at.gridState.UpperPrice = price + halfRange
-	at.gridState.LowerPrice = price - halfRange
+	at.gridState.UpperPrice, at.gridState.LowerPrice = kernel.GridBounds(price, atr, config)
 }
 
 // initializeGridLevels creates the grid level structure
 func (at *AutoTrader) initializeGridLevels(currentPrice float64, config *store.GridStrategyConfig) {
-	levels := make([]kernel.GridLevelInfo, config.GridCount)
-	totalWeight := 0.0
-	weights := make([]float64, config.GridCount)
-
-	// Calculate weights based on distribution
-	for i := 0; i < config.GridCount; i++ {
-		switch config.Distribution {
-		case "gaussian":
-			// Gaussian distribution - more weight in the middle
-			center := float64(config.GridCount-1) / 2
-			sigma := float64(config.GridCount) / 4
-			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
-		case "pyramid":
-			// Pyramid - more weight at bottom
-			weights[i] = float64(config.GridCount - i)
-		default: // uniform
-			weights[i] = 1.0
-		}
-		totalWeight += weights[i]
-	}
-
-	// Create levels
-	for i := 0; i < config.GridCount; i++ {
-		price := at.gridState.LowerPrice + float64(i)*at.gridState.GridSpacing
-		allocatedUSD := config.TotalInvestment * weights[i] / totalWeight
-
-		// Determine initial side (below current price = buy, above = sell)
-		side := "buy"
-		if price > currentPrice {
-			side = "sell"
-		}
-
-		levels[i] = kernel.GridLevelInfo{
-			Index:        i,
-			Price:        price,
-			State:        "empty",
-			Side:         side,
-			AllocatedUSD: allocatedUSD,
-		}
-	}
-
-	at.gridState.Levels = levels
+	at.gridState.Levels = kernel.NewGridLevels(at.gridState.LowerPrice, at.gridState.UpperPrice, currentPrice, config)
 
 	// Apply direction-based side assignment if enabled
 	if config.EnableDirectionAdjust {
@@ -643,87 +755,13 @@ func (at *AutoTrader) initializeGridLevels(currentPrice float64, config *store.G
 func (at *AutoTrader) applyGridDirection(currentPrice float64) {
 	config := at.gridState.Config
 	direction := at.gridState.CurrentDirection
+	kernel.AssignGridSides(at.gridState.Levels, direction, config.DirectionBiasRatio, currentPrice)
 
-	// Get bias ratio from config, default to 0.7 (70%/30%)
-	biasRatio := config.DirectionBiasRatio
-	if biasRatio <= 0 || biasRatio > 1 {
-		biasRatio = 0.7
-	}
-
-	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
-
-	// Calculate how many levels should be buy vs sell based on direction
-	totalLevels := len(at.gridState.Levels)
-	targetBuyLevels := int(float64(totalLevels) * buyRatio)
-
-	// For neutral: use price-based assignment (buy below, sell above)
-	if direction == market.GridDirectionNeutral {
-		for i := range at.gridState.Levels {
-			if at.gridState.Levels[i].Price <= currentPrice {
-				at.gridState.Levels[i].Side = "buy"
-			} else {
-				at.gridState.Levels[i].Side = "sell"
-			}
-		}
-		return
-	}
-
-	// For long/long_bias: more buy levels
-	// For short/short_bias: more sell levels
-	switch direction {
-	case market.GridDirectionLong:
-		// 100% buy - all levels are buy
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "buy"
-		}
-
-	case market.GridDirectionShort:
-		// 100% sell - all levels are sell
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "sell"
-		}
-
-	case market.GridDirectionLongBias, market.GridDirectionShortBias:
-		// Assign sides based on position relative to current price
-		// For long_bias: keep all below as buy, convert some above to buy
-		// For short_bias: keep all above as sell, convert some below to sell
-		buyCount := 0
-		sellCount := 0
-
-		for i := range at.gridState.Levels {
-			needMoreBuys := buyCount < targetBuyLevels
-			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
-
-			if at.gridState.Levels[i].Price <= currentPrice {
-				// Level below or at current price
-				if needMoreBuys {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				}
-			} else {
-				// Level above current price
-				if needMoreSells && direction == market.GridDirectionShortBias {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else if needMoreBuys && direction == market.GridDirectionLongBias {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else if needMoreSells {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				}
-			}
-		}
+	if direction != market.GridDirectionNeutral {
+		buyRatio, _ := direction.GetBuySellRatio(config.DirectionBiasRatio)
+		logger.Infof("[Grid] Applied direction %s: buy_ratio=%.0f%%, levels reconfigured",
+			direction, buyRatio*100)
 	}
-
-	logger.Infof("[Grid] Applied direction %s: buy_ratio=%.0f%%, levels reconfigured",
-		direction, buyRatio*100)
 }
 
 // adjustGridDirection handles runtime direction adjustment when breakout is detected
@@ -742,6 +780,10 @@ func (at *AutoTrader) adjustGridDirection(newDirection market.GridDirection) err
 
 	logger.Infof("[Grid] Direction changed: %s → %s (change count: %d)",
 		oldDirection, newDirection, at.gridState.DirectionChangeCount)
+	at.recordGridEvent(store.GridEventModel{
+		EventType: store.GridEventDirectionChange,
+		Message:   fmt.Sprintf("%s → %s", oldDirection, newDirection),
+	})
 
 	// Get current price for recalculation
 	currentPrice, err := at.trader.GetMarketPrice(at.gridState.Config.Symbol)
@@ -771,31 +813,75 @@ func (at *AutoTrader) RunGridCycle() error {
 			return fmt.Errorf("failed to initialize grid: %w", err)
 		}
 	}
+	// Persist levels, counters and risk state however the cycle ends
+	defer at.forEachGrid(at.saveGridState)
 
-	// CRITICAL: Check for breakout before executing any trades
-	breakoutType, breakoutPct := at.checkBreakout()
-	if breakoutType != BreakoutNone {
-		if err := at.handleBreakout(breakoutType, breakoutPct); err != nil {
-			return err // Grid paused due to breakout
-		}
-	}
-
-	// CRITICAL: Check max drawdown
+	// CRITICAL: Check max drawdown (account equity, covers every symbol grid)
 	exceeded, drawdown := at.checkMaxDrawdown()
 	if exceeded {
-		return at.emergencyExit(fmt.Sprintf("max drawdown exceeded: %.2f%%", drawdown))
+		at.forEachGrid(func() {
+			at.emergencyExit(fmt.Sprintf("max drawdown exceeded: %.2f%%", drawdown))
+		})
+		return nil
 	}
 
-	// CRITICAL: Check daily loss limit
+	// CRITICAL: Check daily loss limit (combined PnL of every symbol grid)
 	dailyExceeded, dailyLossPct := at.checkDailyLossLimit()
 	if dailyExceeded {
 		logger.Errorf("[Grid] Daily loss limit exceeded: %.2f%%", dailyLossPct)
-		at.gridState.mu.Lock()
-		at.gridState.IsPaused = true
-		at.gridState.mu.Unlock()
+		at.forEachGrid(func() {
+			at.gridState.mu.Lock()
+			at.gridState.IsPaused = true
+			at.gridState.mu.Unlock()
+			at.recordGridEvent(store.GridEventModel{
+				EventType: store.GridEventPaused,
+				Message:   fmt.Sprintf("daily loss limit exceeded: %.2f%%", dailyLossPct),
+			})
+		})
 		return fmt.Errorf("daily loss limit exceeded: %.2f%%", dailyLossPct)
 	}
 
+	if len(at.gridStates) == 1 {
+		return at.runGridSymbolCycle()
+	}
+	var errs []error
+	for _, gs := range at.gridStates {
+		at.isRunningMutex.RLock()
+		running = at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			break
+		}
+		at.gridState = gs
+		if err := at.runGridSymbolCycle(); err != nil {
+			logger.Warnf("[Grid] %s cycle failed: %v", gs.Config.Symbol, err)
+			errs = append(errs, fmt.Errorf("%s: %w", gs.Config.Symbol, err))
+		}
+	}
+	at.gridState = at.gridStates[0]
+	return errors.Join(errs...)
+}
+
+// forEachGrid runs fn with every symbol grid as the current grid
+func (at *AutoTrader) forEachGrid(fn func()) {
+	current := at.gridState
+	for _, gs := range at.gridStates {
+		at.gridState = gs
+		fn()
+	}
+	at.gridState = current
+}
+
+// runGridSymbolCycle runs the breakout checks and decisions of the current symbol grid
+func (at *AutoTrader) runGridSymbolCycle() error {
+	// CRITICAL: Check for breakout before executing any trades
+	breakoutType, breakoutPct := at.checkBreakout()
+	if breakoutType != BreakoutNone && !at.trailGrid() {
+		if err := at.handleBreakout(breakoutType, breakoutPct); err != nil {
+			return err // Grid paused due to breakout
+		}
+	}
+
 	// Check multi-period box breakout
 	if err := at.checkBoxBreakout(); err != nil {
 		logger.Infof("Box breakout check error: %v", err)
@@ -815,27 +901,35 @@ func (at *AutoTrader) RunGridCycle() error {
 		return nil
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
-	lang := at.config.StrategyConfig.Language
-	if lang == "" {
-		lang = "en"
-	}
-
 	// Build grid context
 	gridCtx, err := at.buildGridContext()
 	if err != nil {
 		return fmt.Errorf("failed to build grid context: %w", err)
 	}
+	at.assessGridRegime(gridCtx)
 
 	// Get AI decisions
-	decision, err := kernel.GetGridDecisions(gridCtx, at.mcpClient, gridConfig, lang)
+	decision, err := kernel.RunStrategy(at.runningStrategy().Strategy, &kernel.Context{TraderID: at.id, Grid: gridCtx})
 	if err != nil {
 		return fmt.Errorf("failed to get grid decisions: %w", err)
 	}
 
+	// A due tuning counts as done even when the AI failed, so it is not retried every cycle
+	if interval := kernel.GridAITuneInterval(at.gridState.Config); interval > 0 &&
+		time.Since(gridCtx.LastTunedAt) >= interval {
+		at.gridState.mu.Lock()
+		at.gridState.LastTunedAt = time.Now()
+		at.gridState.mu.Unlock()
+	}
+	if gridCtx.Tuning != nil {
+		at.applyGridTuning(gridCtx.Tuning, gridCtx.CurrentPrice)
+		at.saveGridDecisionRecord(decision)
+		return nil
+	}
+
 	// Check if trader is stopped before executing any decisions (prevent trades after Stop())
 	at.isRunningMutex.RLock()
-	running = at.isRunning
+	running := at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
 		logger.Infof("[Grid] Trader stopped before decision execution, aborting grid cycle")
@@ -869,7 +963,7 @@ func (at *AutoTrader) RunGridCycle() error {
 
 // buildGridContext builds the context for AI grid decisions
 func (at *AutoTrader) buildGridContext() (*kernel.GridContext, error) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	// Get market data
 	mktData, err := market.GetWithTimeframes(gridConfig.Symbol, []string{"5m", "4h"}, "5m", 50, nil, nil)
@@ -892,6 +986,9 @@ func (at *AutoTrader) buildGridContext() (*kernel.GridContext, error) {
 	ctx.WinningTrades = at.gridState.WinningTrades
 	ctx.MaxDrawdown = at.gridState.MaxDrawdown
 	ctx.DailyPnL = at.gridState.DailyPnL
+	ctx.CurrentDirection = string(at.gridState.CurrentDirection)
+	ctx.PositionReductionPct = at.gridState.PositionReductionPct
+	ctx.LastTunedAt = at.gridState.LastTunedAt
 
 	// Count active orders and filled levels
 	for _, level := range at.gridState.Levels {
@@ -965,46 +1062,65 @@ func (at *AutoTrader) executeGridDecision(d *kernel.Decision) error {
 	}
 }
 
-// checkTotalPositionLimit checks if adding a new position would exceed total limits
+// checkTotalPositionLimit checks if adding a new position would exceed total limits.
+// A symbol grid of a multi-symbol grid is limited by its allocation, and all grids together by the shared investment.
 // Returns: (allowed bool, currentPositionValue float64, maxAllowed float64)
 func (at *AutoTrader) checkTotalPositionLimit(symbol string, additionalValue float64) (bool, float64, float64) {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	// Calculate max allowed total position value
 	// Total position should not exceed: TotalInvestment × Leverage
 	maxTotalPositionValue := gridConfig.TotalInvestment * float64(gridConfig.Leverage)
 
-	// Get current position value from exchange
+	// Get current positions from exchange
+	positions, _ := at.trader.GetPositions()
+	currentValue := gridExposure(positions, symbol, at.gridState)
+	if currentValue+additionalValue > maxTotalPositionValue {
+		return false, currentValue, maxTotalPositionValue
+	}
+	if len(at.gridStates) <= 1 {
+		return true, currentValue, maxTotalPositionValue
+	}
+
+	// Combined limit of the capital pool
+	poolConfig := at.strategyConfig().GridConfig
+	maxPoolValue := poolConfig.TotalInvestment * float64(poolConfig.Leverage)
+	poolValue := 0.0
+	for _, gs := range at.gridStates {
+		poolValue += gridExposure(positions, gs.Config.Symbol, gs)
+	}
+	if poolValue+additionalValue > maxPoolValue {
+		return false, poolValue, maxPoolValue
+	}
+	return true, currentValue, maxTotalPositionValue
+}
+
+// gridExposure returns the position value of a symbol plus the value of the grid pending orders
+func gridExposure(positions []map[string]interface{}, symbol string, gs *GridState) float64 {
 	currentPositionValue := 0.0
-	positions, err := at.trader.GetPositions()
-	if err == nil {
-		for _, pos := range positions {
-			if sym, ok := pos["symbol"].(string); ok && sym == symbol {
-				if size, ok := pos["positionAmt"].(float64); ok {
-					if price, ok := pos["markPrice"].(float64); ok {
-						currentPositionValue = math.Abs(size) * price
-					} else if entryPrice, ok := pos["entryPrice"].(float64); ok {
-						currentPositionValue = math.Abs(size) * entryPrice
-					}
+	for _, pos := range positions {
+		if sym, ok := pos["symbol"].(string); ok && sym == symbol {
+			if size, ok := pos["positionAmt"].(float64); ok {
+				if price, ok := pos["markPrice"].(float64); ok {
+					currentPositionValue = math.Abs(size) * price
+				} else if entryPrice, ok := pos["entryPrice"].(float64); ok {
+					currentPositionValue = math.Abs(size) * entryPrice
 				}
 			}
 		}
 	}
 
 	// Also count pending orders as potential position
-	at.gridState.mu.RLock()
+	gs.mu.RLock()
 	pendingValue := 0.0
-	for _, level := range at.gridState.Levels {
+	for _, level := range gs.Levels {
 		if level.State == "pending" {
 			pendingValue += level.OrderQuantity * level.Price
 		}
 	}
-	at.gridState.mu.RUnlock()
-
-	totalAfterOrder := currentPositionValue + pendingValue + additionalValue
-	allowed := totalAfterOrder <= maxTotalPositionValue
+	gs.mu.RUnlock()
 
-	return allowed, currentPositionValue + pendingValue, maxTotalPositionValue
+	return currentPositionValue + pendingValue
 }
 
 // placeGridLimitOrder places a limit order for grid trading
@@ -1016,7 +1132,20 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 		gridTrader = NewGridTraderAdapter(at.trader)
 	}
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
+
+	// An order against the side of a filled level is its paired exit: it closes the level
+	// position, so the sizing caps below do not apply
+	at.gridState.mu.RLock()
+	isExit := false
+	if d.LevelIndex >= 0 && d.LevelIndex < len(at.gridState.Levels) {
+		level := at.gridState.Levels[d.LevelIndex]
+		isExit = level.State == "filled" && level.Side != strings.ToLower(side)
+	}
+	at.gridState.mu.RUnlock()
+	if isExit {
+		return at.placeGridExitOrder(gridTrader, d, side)
+	}
 
 	// CRITICAL: Validate and cap quantity to prevent excessive position sizes
 	// This protects against AI miscalculations or leverage misconfigurations
@@ -1089,17 +1218,64 @@ func (at *AutoTrader) placeGridLimitOrder(d *kernel.Decision, side string) error
 
 	// Update grid level state
 	at.gridState.mu.Lock()
-	if d.LevelIndex >= 0 && d.LevelIndex < len(at.gridState.Levels) {
+	tracked := d.LevelIndex >= 0 && d.LevelIndex < len(at.gridState.Levels)
+	var event store.GridEventModel
+	if tracked {
 		at.gridState.Levels[d.LevelIndex].State = "pending"
+		at.gridState.Levels[d.LevelIndex].Side = strings.ToLower(side)
 		at.gridState.Levels[d.LevelIndex].OrderID = result.OrderID
-		at.gridState.Levels[d.LevelIndex].OrderQuantity = d.Quantity
+		at.gridState.Levels[d.LevelIndex].OrderQuantity = quantity
 		at.gridState.OrderBook[result.OrderID] = d.LevelIndex
+		event = at.gridState.levelEventLocked(store.GridEventOrderPlaced, d.LevelIndex)
 	}
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Placed %s limit order at $%.2f, qty=%.4f, level=%d, orderID=%s",
+		side, d.Price, quantity, d.LevelIndex, result.OrderID)
+
+	// Persist the order-to-level mapping immediately so a restart can reconcile it
+	if tracked {
+		at.saveGridLevel(d.LevelIndex)
+		event.Message = "order " + result.OrderID
+		at.recordGridEvent(event)
+	}
+	return nil
+}
+
+// placeGridExitOrder places the paired exit order closing the position of a filled level
+func (at *AutoTrader) placeGridExitOrder(gridTrader GridTrader, d *kernel.Decision, side string) error {
+	gridConfig := at.gridState.Config
+
+	req := &LimitOrderRequest{
+		Symbol:   d.Symbol,
+		Side:     side,
+		Price:    d.Price,
+		Quantity: d.Quantity,
+		Leverage: gridConfig.Leverage,
+		PostOnly: gridConfig.UseMakerOnly,
+		ClientID: fmt.Sprintf("grid-x%d-%d", d.LevelIndex, time.Now().UnixNano()%1000000),
+	}
+	result, err := gridTrader.PlaceLimitOrder(req)
+	if err != nil {
+		return fmt.Errorf("failed to place exit order: %w", err)
+	}
+
+	at.gridState.mu.Lock()
+	level := &at.gridState.Levels[d.LevelIndex]
+	level.ExitOrderID = result.OrderID
+	level.ExitPrice = d.Price
+	at.gridState.OrderBook[result.OrderID] = d.LevelIndex
+	event := at.gridState.levelEventLocked(store.GridEventOrderPlaced, d.LevelIndex)
+	at.gridState.mu.Unlock()
+
+	logger.Infof("[Grid] Placed %s exit order at $%.2f, qty=%.4f, level=%d, orderID=%s",
 		side, d.Price, d.Quantity, d.LevelIndex, result.OrderID)
 
+	at.saveGridLevel(d.LevelIndex)
+	event.Price = d.Price
+	event.Side = strings.ToLower(side)
+	event.Message = "paired exit order " + result.OrderID
+	at.recordGridEvent(event)
 	return nil
 }
 
@@ -1116,23 +1292,38 @@ func (at *AutoTrader) cancelGridOrder(d *kernel.Decision) error {
 
 	// Update state
 	at.gridState.mu.Lock()
+	cancelledLevel := -1
+	var event store.GridEventModel
 	if levelIdx, ok := at.gridState.OrderBook[d.OrderID]; ok {
 		if levelIdx >= 0 && levelIdx < len(at.gridState.Levels) {
-			at.gridState.Levels[levelIdx].State = "empty"
-			at.gridState.Levels[levelIdx].OrderID = ""
-			at.gridState.Levels[levelIdx].OrderQuantity = 0
+			event = at.gridState.levelEventLocked(store.GridEventOrderCancelled, levelIdx)
+			if level := &at.gridState.Levels[levelIdx]; level.ExitOrderID == d.OrderID {
+				// Paired exit: the level keeps its position, the exit is placed again
+				level.ExitOrderID = ""
+				level.ExitPrice = 0
+			} else {
+				level.State = "empty"
+				level.OrderID = ""
+				level.OrderQuantity = 0
+			}
+			cancelledLevel = levelIdx
 		}
 		delete(at.gridState.OrderBook, d.OrderID)
 	}
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Cancelled order: %s", d.OrderID)
+	if cancelledLevel >= 0 {
+		at.saveGridLevel(cancelledLevel)
+		event.Message = "order " + d.OrderID
+		at.recordGridEvent(event)
+	}
 	return nil
 }
 
 // cancelAllGridOrders cancels all grid orders
 func (at *AutoTrader) cancelAllGridOrders() error {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	if err := at.trader.CancelAllOrders(gridConfig.Symbol); err != nil {
 		return fmt.Errorf("failed to cancel all orders: %w", err)
@@ -1140,17 +1331,32 @@ func (at *AutoTrader) cancelAllGridOrders() error {
 
 	// Reset all pending levels
 	at.gridState.mu.Lock()
+	var events []store.GridEventModel
 	for i := range at.gridState.Levels {
 		if at.gridState.Levels[i].State == "pending" {
+			events = append(events, at.gridState.levelEventLocked(store.GridEventOrderCancelled, i))
 			at.gridState.Levels[i].State = "empty"
 			at.gridState.Levels[i].OrderID = ""
 			at.gridState.Levels[i].OrderQuantity = 0
 		}
+		if at.gridState.Levels[i].ExitOrderID != "" {
+			event := at.gridState.levelEventLocked(store.GridEventOrderCancelled, i)
+			event.Message = "paired exit order " + at.gridState.Levels[i].ExitOrderID
+			events = append(events, event)
+			at.gridState.Levels[i].ExitOrderID = ""
+			at.gridState.Levels[i].ExitPrice = 0
+		}
 	}
 	at.gridState.OrderBook = make(map[string]int)
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Cancelled all orders")
+	for _, event := range events {
+		at.recordGridEvent(event)
+	}
+	if len(events) > 0 {
+		at.saveGridState()
+	}
 	return nil
 }
 
@@ -1163,6 +1369,7 @@ func (at *AutoTrader) pauseGrid(reason string) error {
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Paused: %s", reason)
+	at.recordGridEvent(store.GridEventModel{EventType: store.GridEventPaused, Message: reason})
 	return nil
 }
 
@@ -1173,6 +1380,7 @@ func (at *AutoTrader) resumeGrid() error {
 	at.gridState.mu.Unlock()
 
 	logger.Infof("[Grid] Resumed")
+	at.recordGridEvent(store.GridEventModel{EventType: store.GridEventResumed})
 	return nil
 }
 
@@ -1181,7 +1389,7 @@ func (at *AutoTrader) adjustGrid(d *kernel.Decision) error {
 	// Cancel existing orders first
 	at.cancelAllGridOrders()
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	// Get current price
 	price, err := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -1193,12 +1401,17 @@ func (at *AutoTrader) adjustGrid(d *kernel.Decision) error {
 	at.initializeGridLevels(price, gridConfig)
 
 	logger.Infof("[Grid] Adjusted grid bounds around price $%.2f", price)
+	at.recordGridEvent(store.GridEventModel{
+		EventType: store.GridEventAdjusted,
+		Price:     price,
+		Message:   d.Reasoning,
+	})
 	return nil
 }
 
 // syncGridState syncs grid state with exchange
 func (at *AutoTrader) syncGridState() {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	// Get open orders from exchange
 	openOrders, err := at.trader.GetOpenOrders(gridConfig.Symbol)
@@ -1207,72 +1420,52 @@ func (at *AutoTrader) syncGridState() {
 		return
 	}
 
-	// Build set of active order IDs
-	activeOrderIDs := make(map[string]bool)
-	for _, order := range openOrders {
-		activeOrderIDs[order.OrderID] = true
-	}
-
 	// Get current positions to verify fills
-	positions, err := at.trader.GetPositions()
-	currentPositionSize := 0.0
+	currentPositionSize, err := at.gridPositionSize()
 	if err != nil {
 		logger.Warnf("[Grid] Failed to get positions for state sync: %v", err)
-	} else {
-		for _, pos := range positions {
-			if sym, ok := pos["symbol"].(string); ok && sym == gridConfig.Symbol {
-				if size, ok := pos["positionAmt"].(float64); ok {
-					currentPositionSize = size
-				}
-			}
-		}
 	}
 
+	// Final status of the orders that left the book (fill vs cancel)
+	statuses := at.gridOrderStatuses(openOrders)
+
 	// Update levels based on order status
 	at.gridState.mu.Lock()
-	expectedPositionSize := 0.0
-	for _, level := range at.gridState.Levels {
-		if level.State == "filled" {
-			expectedPositionSize += level.PositionSize
-		}
-	}
+	events := at.gridState.reconcileOrdersLocked(openOrders, statuses, currentPositionSize)
+	at.gridState.mu.Unlock()
 
-	for i := range at.gridState.Levels {
-		level := &at.gridState.Levels[i]
-		if level.State == "pending" && level.OrderID != "" {
-			if !activeOrderIDs[level.OrderID] {
-				// Order no longer exists - check if position changed to determine fill vs cancel
-				// This is a heuristic - ideally we'd query order history
-				// If current position is larger than expected filled positions, this order was likely filled
-				if math.Abs(currentPositionSize) > math.Abs(expectedPositionSize) {
-					// Position increased, likely filled
-					level.State = "filled"
-					level.PositionEntry = level.Price
-					level.PositionSize = level.OrderQuantity
-					at.gridState.TotalTrades++
-					logger.Infof("[Grid] Level %d order filled at $%.2f", i, level.Price)
-				} else {
-					// Position didn't increase as expected, likely cancelled
-					level.State = "empty"
-					level.OrderID = ""
-					level.OrderQuantity = 0
-					logger.Infof("[Grid] Level %d order cancelled/expired", i)
-				}
-				delete(at.gridState.OrderBook, level.OrderID)
-			}
-		}
+	for _, event := range events {
+		at.recordGridEvent(event)
 	}
-	at.gridState.mu.Unlock()
 
 	logger.Debugf("[Grid] Synced state: position=%.4f, orders=%d", currentPositionSize, len(openOrders))
 
 	// Check stop loss
 	at.checkAndExecuteStopLoss()
+	at.saveGridRoundTrips()
 
 	// Check grid skew
 	at.autoAdjustGrid()
 }
 
+// gridPositionSize returns the signed position size of the grid symbol
+func (at *AutoTrader) gridPositionSize() (float64, error) {
+	gridConfig := at.gridState.Config
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return 0, err
+	}
+	for _, pos := range positions {
+		if sym, ok := pos["symbol"].(string); ok && sym == gridConfig.Symbol {
+			if size, ok := pos["positionAmt"].(float64); ok {
+				return size, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
 // saveGridDecisionRecord saves the grid decision to database
 func (at *AutoTrader) saveGridDecisionRecord(decision *kernel.FullDecision) {
 	if at.store == nil {
@@ -1323,12 +1516,31 @@ func (at *AutoTrader) saveGridDecisionRecord(decision *kernel.FullDecision) {
 	}
 }
 
+func init() {
+	RegisterStrategyCycle("grid_trading", StrategyCycle{Name: "Grid", Init: (*AutoTrader).InitializeGrid, Run: (*AutoTrader).RunGridCycle})
+}
+
 // IsGridStrategy returns true if current strategy is grid trading
 func (at *AutoTrader) IsGridStrategy() bool {
-	if at.config.StrategyConfig == nil {
+	cfg := at.strategyConfig()
+	if cfg == nil {
 		return false
 	}
-	return at.config.StrategyConfig.StrategyType == "grid_trading" && at.config.StrategyConfig.GridConfig != nil
+	return cfg.StrategyType == "grid_trading" && cfg.GridConfig != nil
+}
+
+// setGridStates replaces the symbol grids (read by the API through gridStateList)
+func (at *AutoTrader) setGridStates(states []*GridState) {
+	at.stateMu.Lock()
+	at.gridStates = states
+	at.stateMu.Unlock()
+}
+
+// gridStateList returns the symbol grids of the running grid (safe to call from the API)
+func (at *AutoTrader) gridStateList() []*GridState {
+	at.stateMu.RLock()
+	defer at.stateMu.RUnlock()
+	return at.gridStates
 }
 
 // checkGridSkew checks if grid is heavily skewed (too many fills on one side)
@@ -1384,7 +1596,7 @@ func (at *AutoTrader) autoAdjustGrid() {
 	logger.Warnf("[Grid] Grid heavily skewed: buy_filled=%d, sell_filled=%d. Auto-adjusting...",
 		buyFilled, sellFilled)
 
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 
 	// Get current price
 	currentPrice, err := at.trader.GetMarketPrice(gridConfig.Symbol)
@@ -1420,14 +1632,6 @@ func (at *AutoTrader) autoAdjustGrid() {
 	at.gridState.mu.Lock()
 	defer at.gridState.mu.Unlock()
 
-	// Preserve filled positions before reinitializing
-	filledPositions := make(map[int]kernel.GridLevelInfo)
-	for i, level := range at.gridState.Levels {
-		if level.State == "filled" {
-			filledPositions[i] = level
-		}
-	}
-
 	// CRITICAL FIX: Recalculate grid bounds centered on current price
 	// Use the same logic as InitializeGrid() - either ATR-based or default percentage
 	if gridConfig.UseATRBounds {
@@ -1444,47 +1648,68 @@ func (at *AutoTrader) autoAdjustGrid() {
 		at.calculateDefaultBoundsLocked(currentPrice, gridConfig)
 	}
 
+	at.regridLocked(currentPrice, gridConfig)
+
+	at.recordGridEvent(store.GridEventModel{
+		EventType: store.GridEventAdjusted,
+		Price:     currentPrice,
+		Message: fmt.Sprintf("auto-adjust on skew (buy_filled=%d, sell_filled=%d): $%.2f - $%.2f",
+			buyFilled, sellFilled, at.gridState.LowerPrice, at.gridState.UpperPrice),
+	})
+}
+
+// applyGridTuning moves a deterministic grid to the bounds and direction proposed by the AI
+func (at *AutoTrader) applyGridTuning(tuning *kernel.GridTuning, currentPrice float64) {
+	if err := at.cancelAllGridOrders(); err != nil {
+		logger.Errorf("[Grid] Failed to cancel orders during AI re-tuning: %v", err)
+	}
+
+	at.gridState.mu.Lock()
+	at.gridState.UpperPrice = tuning.UpperPrice
+	at.gridState.LowerPrice = tuning.LowerPrice
+	at.regridLocked(currentPrice, at.gridState.Config)
+	at.gridState.mu.Unlock()
+
+	if tuning.Direction != "" {
+		if err := at.adjustGridDirection(market.GridDirection(tuning.Direction)); err != nil {
+			logger.Warnf("[Grid] Failed to apply tuned direction: %v", err)
+		}
+	}
+
+	at.recordGridEvent(store.GridEventModel{
+		EventType:   store.GridEventAdjusted,
+		TriggerType: "ai_tune",
+		Price:       currentPrice,
+		Message: fmt.Sprintf("AI re-tuning: $%.2f - $%.2f, direction=%s: %s",
+			tuning.LowerPrice, tuning.UpperPrice, tuning.Direction, tuning.Reasoning),
+	})
+}
+
+// regridLocked rebuilds the levels over the current bounds and moves filled positions onto the
+// closest new level (caller must hold lock)
+func (at *AutoTrader) regridLocked(currentPrice float64, config *store.GridStrategyConfig) {
+	// Preserve filled positions before reinitializing
+	previous := at.gridState.Levels
+
 	// Recalculate grid spacing based on new bounds
-	at.gridState.GridSpacing = (at.gridState.UpperPrice - at.gridState.LowerPrice) / float64(gridConfig.GridCount-1)
+	at.gridState.GridSpacing = (at.gridState.UpperPrice - at.gridState.LowerPrice) / float64(config.GridCount-1)
 
 	logger.Infof("[Grid] New bounds: $%.2f - $%.2f, spacing: $%.2f",
 		at.gridState.LowerPrice, at.gridState.UpperPrice, at.gridState.GridSpacing)
 
 	// Initialize new grid levels (without lock since we already hold it)
-	at.initializeGridLevelsLocked(currentPrice, gridConfig)
+	at.initializeGridLevelsLocked(currentPrice, config)
 
 	// CRITICAL FIX: Restore filled positions - find closest new level for each filled position
-	for _, filledLevel := range filledPositions {
-		closestIdx := -1
-		closestDist := math.MaxFloat64
-
-		for i, newLevel := range at.gridState.Levels {
-			dist := math.Abs(newLevel.Price - filledLevel.PositionEntry)
-			if dist < closestDist {
-				closestDist = dist
-				closestIdx = i
-			}
-		}
-
-		if closestIdx >= 0 {
-			// Restore the filled state to the closest level
-			at.gridState.Levels[closestIdx].State = "filled"
-			at.gridState.Levels[closestIdx].PositionEntry = filledLevel.PositionEntry
-			at.gridState.Levels[closestIdx].PositionSize = filledLevel.PositionSize
-			at.gridState.Levels[closestIdx].UnrealizedPnL = filledLevel.UnrealizedPnL
-			at.gridState.Levels[closestIdx].OrderID = filledLevel.OrderID
-			at.gridState.Levels[closestIdx].OrderQuantity = filledLevel.OrderQuantity
-			logger.Infof("[Grid] Restored filled position at level %d (entry $%.2f)", closestIdx, filledLevel.PositionEntry)
-		}
+	for _, idx := range kernel.RestoreFilledLevels(at.gridState.Levels, previous) {
+		logger.Infof("[Grid] Restored filled position at level %d (entry $%.2f)", idx, at.gridState.Levels[idx].PositionEntry)
 	}
 }
 
 // calculateDefaultBoundsLocked calculates default bounds (caller must hold lock)
 func (at *AutoTrader) calculateDefaultBoundsLocked(price float64, config *store.GridStrategyConfig) {
 	// Default: ±3% from current price, scaled by grid count
-	multiplier := 0.03 * float64(config.GridCount) / 10
-	at.gridState.UpperPrice = price * (1 + multiplier)
-	at.gridState.LowerPrice = price * (1 - multiplier)
+	at.gridState.UpperPrice, at.gridState.LowerPrice = kernel.GridBounds(price, 0, config)
 }
 
 // calculateATRBoundsLocked calculates bounds using ATR (caller must hold lock)
@@ -1493,66 +1718,12 @@ func (at *AutoTrader) calculateATRBoundsLocked(price float64, mktData *market.Da
 	if mktData.LongerTermContext != nil {
 		atr = mktData.LongerTermContext.ATR14
 	}
-
-	if atr <= 0 {
-		at.calculateDefaultBoundsLocked(price, config)
-		return
-	}
-
-	multiplier := config.ATRMultiplier
-	if multiplier <= 0 {
-		multiplier = 2.0
-	}
-
-	halfRange := atr * multiplier
-	at.gridState.UpperPrice = price + halfRange
-	at.gridState.LowerPrice = price - halfRange
+	at.gridState.UpperPrice, at.gridState.LowerPrice = kernel.GridBounds(price, atr, config)
 }
 
 // initializeGridLevelsLocked creates the grid level structure (caller must hold lock)
 func (at *AutoTrader) initializeGridLevelsLocked(currentPrice float64, config *store.GridStrategyConfig) {
-	levels := make([]kernel.GridLevelInfo, config.GridCount)
-	totalWeight := 0.0
-	weights := make([]float64, config.GridCount)
-
-	// Calculate weights based on distribution
-	for i := 0; i < config.GridCount; i++ {
-		switch config.Distribution {
-		case "gaussian":
-			// Gaussian distribution - more weight in the middle
-			center := float64(config.GridCount-1) / 2
-			sigma := float64(config.GridCount) / 4
-			weights[i] = math.Exp(-math.Pow(float64(i)-center, 2) / (2 * sigma * sigma))
-		case "pyramid":
-			// Pyramid - more weight at bottom
-			weights[i] = float64(config.GridCount - i)
-		default: // uniform
-			weights[i] = 1.0
-		}
-		totalWeight += weights[i]
-	}
-
-	// Create levels
-	for i := 0; i < config.GridCount; i++ {
-		price := at.gridState.LowerPrice + float64(i)*at.gridState.GridSpacing
-		allocatedUSD := config.TotalInvestment * weights[i] / totalWeight
-
-		// Determine initial side (below current price = buy, above = sell)
-		side := "buy"
-		if price > currentPrice {
-			side = "sell"
-		}
-
-		levels[i] = kernel.GridLevelInfo{
-			Index:        i,
-			Price:        price,
-			State:        "empty",
-			Side:         side,
-			AllocatedUSD: allocatedUSD,
-		}
-	}
-
-	at.gridState.Levels = levels
+	at.gridState.Levels = kernel.NewGridLevels(at.gridState.LowerPrice, at.gridState.UpperPrice, currentPrice, config)
 
 	// Apply direction-based side assignment if enabled (note: caller holds lock)
 	if config.EnableDirectionAdjust {
@@ -1564,74 +1735,7 @@ func (at *AutoTrader) initializeGridLevelsLocked(currentPrice float64, config *s
 func (at *AutoTrader) applyGridDirectionLocked(currentPrice float64) {
 	config := at.gridState.Config
 	direction := at.gridState.CurrentDirection
-
-	// Get bias ratio from config, default to 0.7 (70%/30%)
-	biasRatio := config.DirectionBiasRatio
-	if biasRatio <= 0 || biasRatio > 1 {
-		biasRatio = 0.7
-	}
-
-	buyRatio, _ := direction.GetBuySellRatio(biasRatio)
-
-	// For neutral: use price-based assignment (buy below, sell above)
-	if direction == market.GridDirectionNeutral {
-		for i := range at.gridState.Levels {
-			if at.gridState.Levels[i].Price <= currentPrice {
-				at.gridState.Levels[i].Side = "buy"
-			} else {
-				at.gridState.Levels[i].Side = "sell"
-			}
-		}
-		return
-	}
-
-	totalLevels := len(at.gridState.Levels)
-	targetBuyLevels := int(float64(totalLevels) * buyRatio)
-
-	switch direction {
-	case market.GridDirectionLong:
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "buy"
-		}
-
-	case market.GridDirectionShort:
-		for i := range at.gridState.Levels {
-			at.gridState.Levels[i].Side = "sell"
-		}
-
-	case market.GridDirectionLongBias, market.GridDirectionShortBias:
-		buyCount := 0
-		sellCount := 0
-
-		for i := range at.gridState.Levels {
-			needMoreBuys := buyCount < targetBuyLevels
-			needMoreSells := sellCount < (totalLevels - targetBuyLevels)
-
-			if at.gridState.Levels[i].Price <= currentPrice {
-				if needMoreBuys {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				}
-			} else {
-				if needMoreSells && direction == market.GridDirectionShortBias {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else if needMoreBuys && direction == market.GridDirectionLongBias {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				} else if needMoreSells {
-					at.gridState.Levels[i].Side = "sell"
-					sellCount++
-				} else {
-					at.gridState.Levels[i].Side = "buy"
-					buyCount++
-				}
-			}
-		}
-	}
+	kernel.AssignGridSides(at.gridState.Levels, direction, config.DirectionBiasRatio, currentPrice)
 }
 
 // GridRiskInfo contains risk information for frontend display
@@ -1664,46 +1768,87 @@ type GridRiskInfo struct {
 	CurrentGridDirection    string `json:"current_grid_direction"`
 	DirectionChangeCount    int    `json:"direction_change_count"`
 	EnableDirectionAdjust   bool   `json:"enable_direction_adjust"`
+
+	// Grid layout
+	UpperPrice     float64 `json:"upper_price"`
+	LowerPrice     float64 `json:"lower_price"`
+	Spacing        string  `json:"spacing"`
+	GridSpacingPct float64 `json:"grid_spacing_pct"`
+
+	// Trailing grid
+	Trailing    bool      `json:"trailing"`
+	TrailCount  int       `json:"trail_count"`
+	LastTrailAt time.Time `json:"last_trail_at"`
+
+	// Multi-symbol grid: capital allocation mode and per-symbol stats
+	Allocation string               `json:"allocation,omitempty"`
+	Symbols    []GridSymbolRiskInfo `json:"symbols,omitempty"`
+}
+
+// GridSymbolRiskInfo contains the stats of one symbol grid of a multi-symbol grid
+type GridSymbolRiskInfo struct {
+	Symbol               string  `json:"symbol"`
+	Allocation           float64 `json:"allocation"` // Share of the total investment in USDT
+	CurrentPrice         float64 `json:"current_price"`
+	UpperPrice           float64 `json:"upper_price"`
+	LowerPrice           float64 `json:"lower_price"`
+	CurrentPosition      float64 `json:"current_position"` // Position value in USDT
+	PositionSize         float64 `json:"position_size"`    // Signed position size
+	RegimeLevel          string  `json:"regime_level"`
+	CurrentGridDirection string  `json:"current_grid_direction"`
+	IsPaused             bool    `json:"is_paused"`
+	ActiveOrders         int     `json:"active_orders"`
+	FilledLevels         int     `json:"filled_levels"`
+	TotalProfit          float64 `json:"total_profit"`
+	TotalTrades          int     `json:"total_trades"`
+	WinningTrades        int     `json:"winning_trades"`
 }
 
 // GetGridRiskInfo returns current risk information for frontend display
+// A multi-symbol grid reports the first grid layout, the combined position of all grids and per-symbol stats.
 func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
-	gridConfig := at.config.StrategyConfig.GridConfig
-	if gridConfig == nil {
+	poolConfig := at.strategyConfig().GridConfig
+	states := at.gridStateList()
+	if poolConfig == nil || len(states) == 0 {
 		return &GridRiskInfo{}
 	}
-
-	at.gridState.mu.RLock()
-	defer at.gridState.mu.RUnlock()
+	gs := states[0]
+	gridConfig := gs.Config
 
 	// Get current price
 	currentPrice, _ := at.trader.GetMarketPrice(gridConfig.Symbol)
 
 	// Calculate effective leverage
-	totalInvestment := gridConfig.TotalInvestment
+	totalInvestment := poolConfig.TotalInvestment
 	leverage := gridConfig.Leverage
 
-	// Get current position value
+	// Get current position value (all symbol grids)
 	positions, _ := at.trader.GetPositions()
 	var currentPositionValue float64
 	var currentPositionSize float64
-	for _, pos := range positions {
-		if sym, _ := pos["symbol"].(string); sym == gridConfig.Symbol {
-			size, _ := pos["positionAmt"].(float64)
-			entry, _ := pos["entryPrice"].(float64)
-			currentPositionValue = math.Abs(size * entry)
-			currentPositionSize = size
-			break
+	symbolStats := make([]GridSymbolRiskInfo, 0, len(states))
+	for _, state := range states {
+		stats := gridSymbolRiskInfo(state, positions)
+		if state == gs {
+			stats.CurrentPrice = currentPrice
+			currentPositionSize = stats.PositionSize
+		} else {
+			stats.CurrentPrice, _ = at.trader.GetMarketPrice(stats.Symbol)
 		}
+		currentPositionValue += stats.CurrentPosition
+		symbolStats = append(symbolStats, stats)
 	}
 
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
 	effectiveLeverage := 0.0
 	if totalInvestment > 0 {
 		effectiveLeverage = currentPositionValue / totalInvestment
 	}
 
 	// Calculate max position based on regime
-	regimeLevel := market.RegimeLevel(at.gridState.CurrentRegimeLevel)
+	regimeLevel := market.RegimeLevel(gs.CurrentRegimeLevel)
 	if regimeLevel == "" {
 		regimeLevel = market.RegimeLevelStandard
 	}
@@ -1756,7 +1901,13 @@ func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
 		positionPercent = currentPositionValue / maxPosition * 100
 	}
 
-	return &GridRiskInfo{
+	geometric := kernel.IsGeometricGrid(gridConfig)
+	spacing := kernel.GridSpacingArithmetic
+	if geometric {
+		spacing = kernel.GridSpacingGeometric
+	}
+
+	info := &GridRiskInfo{
 		CurrentLeverage:     leverage,
 		EffectiveLeverage:   effectiveLeverage,
 		RecommendedLeverage: recommendedLeverage,
@@ -1770,26 +1921,77 @@ func (at *AutoTrader) GetGridRiskInfo() *GridRiskInfo {
 
 		RegimeLevel: string(regimeLevel),
 
-		ShortBoxUpper: at.gridState.ShortBoxUpper,
-		ShortBoxLower: at.gridState.ShortBoxLower,
-		MidBoxUpper:   at.gridState.MidBoxUpper,
-		MidBoxLower:   at.gridState.MidBoxLower,
-		LongBoxUpper:  at.gridState.LongBoxUpper,
-		LongBoxLower:  at.gridState.LongBoxLower,
+		ShortBoxUpper: gs.ShortBoxUpper,
+		ShortBoxLower: gs.ShortBoxLower,
+		MidBoxUpper:   gs.MidBoxUpper,
+		MidBoxLower:   gs.MidBoxLower,
+		LongBoxUpper:  gs.LongBoxUpper,
+		LongBoxLower:  gs.LongBoxLower,
 		CurrentPrice:  currentPrice,
 
-		BreakoutLevel:     at.gridState.BreakoutLevel,
-		BreakoutDirection: at.gridState.BreakoutDirection,
+		BreakoutLevel:     gs.BreakoutLevel,
+		BreakoutDirection: gs.BreakoutDirection,
 
-		CurrentGridDirection:  string(at.gridState.CurrentDirection),
-		DirectionChangeCount:  at.gridState.DirectionChangeCount,
+		CurrentGridDirection:  string(gs.CurrentDirection),
+		DirectionChangeCount:  gs.DirectionChangeCount,
 		EnableDirectionAdjust: gridConfig.EnableDirectionAdjust,
+
+		UpperPrice:     gs.UpperPrice,
+		LowerPrice:     gs.LowerPrice,
+		Spacing:        spacing,
+		GridSpacingPct: kernel.GridSpacingPct(gs.LowerPrice, gs.UpperPrice, gridConfig.GridCount, geometric),
+
+		Trailing:    gridConfig.Trailing,
+		TrailCount:  gs.TrailCount,
+		LastTrailAt: gs.LastTrailAt,
+	}
+	if len(states) > 1 {
+		info.Allocation = gridAllocationName(poolConfig)
+		info.Symbols = symbolStats
+	}
+	return info
+}
+
+// gridSymbolRiskInfo returns the stats of one symbol grid
+func gridSymbolRiskInfo(gs *GridState, positions []map[string]interface{}) GridSymbolRiskInfo {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	stats := GridSymbolRiskInfo{
+		Symbol:               gs.Config.Symbol,
+		Allocation:           gs.Config.TotalInvestment,
+		UpperPrice:           gs.UpperPrice,
+		LowerPrice:           gs.LowerPrice,
+		RegimeLevel:          gs.CurrentRegimeLevel,
+		CurrentGridDirection: string(gs.CurrentDirection),
+		IsPaused:             gs.IsPaused,
+		TotalProfit:          gs.TotalProfit,
+		TotalTrades:          gs.TotalTrades,
+		WinningTrades:        gs.WinningTrades,
+	}
+	for _, level := range gs.Levels {
+		switch level.State {
+		case "pending":
+			stats.ActiveOrders++
+		case "filled":
+			stats.FilledLevels++
+		}
+	}
+	for _, pos := range positions {
+		if sym, _ := pos["symbol"].(string); sym == stats.Symbol {
+			size, _ := pos["positionAmt"].(float64)
+			entry, _ := pos["entryPrice"].(float64)
+			stats.CurrentPosition = math.Abs(size * entry)
+			stats.PositionSize = size
+			break
+		}
 	}
+	return stats
 }
 
 // checkAndExecuteStopLoss checks if any filled level has exceeded stop loss and closes it
 func (at *AutoTrader) checkAndExecuteStopLoss() {
-	gridConfig := at.config.StrategyConfig.GridConfig
+	gridConfig := at.gridState.Config
 	if gridConfig.StopLossPct <= 0 {
 		return // Stop loss not configured
 	}
@@ -1800,6 +2002,11 @@ func (at *AutoTrader) checkAndExecuteStopLoss() {
 		return
 	}
 
+	gridTrader, ok := at.trader.(GridTrader)
+	if !ok {
+		gridTrader = NewGridTraderAdapter(at.trader)
+	}
+
 	at.gridState.mu.Lock()
 	defer at.gridState.mu.Unlock()
 
@@ -1824,6 +2031,16 @@ func (at *AutoTrader) checkAndExecuteStopLoss() {
 			logger.Warnf("[Grid] STOP LOSS TRIGGERED: Level %d, entry=$%.2f, current=$%.2f, loss=%.2f%%",
 				i, level.PositionEntry, currentPrice, lossPct)
 
+			// Cancel the paired exit first so it cannot fill against the closed position
+			if level.ExitOrderID != "" {
+				if err := gridTrader.CancelOrder(gridConfig.Symbol, level.ExitOrderID); err != nil {
+					logger.Warnf("[Grid] Failed to cancel exit order of level %d: %v", i, err)
+				}
+				delete(at.gridState.OrderBook, level.ExitOrderID)
+				level.ExitOrderID = ""
+				level.ExitPrice = 0
+			}
+
 			// Close the position
 			var closeErr error
 			if level.Side == "buy" {
@@ -1835,6 +2052,9 @@ func (at *AutoTrader) checkAndExecuteStopLoss() {
 			if closeErr != nil {
 				logger.Errorf("[Grid] Failed to execute stop loss for level %d: %v", i, closeErr)
 			} else {
+				trip := kernel.CloseGridRoundTrip(gridConfig.Symbol, *level, currentPrice,
+					currentPrice*level.PositionSize*gridTakerFeeRate, kernel.GridExitStopLoss, time.Now().UTC())
+				at.gridState.closedTrips = append(at.gridState.closedTrips, trip)
 				level.State = "stopped"
 				realizedLoss := -lossPct * level.AllocatedUSD / 100
 				level.UnrealizedPnL = realizedLoss
@@ -1842,6 +2062,11 @@ func (at *AutoTrader) checkAndExecuteStopLoss() {
 				// Update daily PnL tracking (lock already held, update directly)
 				at.gridState.DailyPnL += realizedLoss
 				at.gridState.TotalProfit += realizedLoss
+				event := at.gridState.levelEventLocked(store.GridEventStopLoss, i)
+				event.Price = currentPrice
+				event.PnL = realizedLoss
+				event.Message = fmt.Sprintf("entry $%.2f, loss %.2f%%", level.PositionEntry, lossPct)
+				at.recordGridEvent(event)
 				logger.Infof("[Grid] Stop loss executed: Level %d closed at $%.2f (loss %.2f%%)",
 					i, currentPrice, lossPct)
 			}
@@ -0,0 +1,737 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ============================================================================
+// Grid State Persistence
+// ============================================================================
+
+// Grid instance states (grid_instances.state)
+const (
+	gridInstanceRunning = "running"
+	gridInstancePaused  = "paused"
+	gridInstanceStopped = "stopped"
+)
+
+// Fee rates assumed by the round-trip ledger: grid orders rest as maker limit orders, stop losses
+// close at market. Exchanges do not report the fee of a grid order fill.
+const (
+	gridMakerFeeRate = 0.0002
+	gridTakerFeeRate = 0.0005
+)
+
+// gridLevelID returns the stable row ID of a level, so saving a level updates it in place
+func gridLevelID(instanceID string, index int) string {
+	return fmt.Sprintf("%s-%d", instanceID, index)
+}
+
+// gridInstanceModelLocked snapshots the grid state into an instance row (caller must hold lock).
+// Grid strategies keep their configuration in the strategy, so the trader ID is the config ID.
+func gridInstanceModelLocked(s *GridState, traderID string) *store.GridInstanceModel {
+	state := gridInstanceRunning
+	if s.IsPaused {
+		state = gridInstancePaused
+	}
+
+	activeLevels := 0
+	for _, level := range s.Levels {
+		if level.State == "pending" || level.State == "filled" {
+			activeLevels++
+		}
+	}
+
+	dailyProfit, dailyLoss := 0.0, 0.0
+	if s.DailyPnL >= 0 {
+		dailyProfit = s.DailyPnL
+	} else {
+		dailyLoss = -s.DailyPnL
+	}
+
+	return &store.GridInstanceModel{
+		ID:                 s.InstanceID,
+		ConfigID:           traderID,
+		Symbol:             s.Config.Symbol,
+		State:              state,
+		StartedAt:          s.StartedAt,
+		CurrentUpperPrice:  s.UpperPrice,
+		CurrentLowerPrice:  s.LowerPrice,
+		CurrentGridSpacing: s.GridSpacing,
+		ActiveLevelCount:   activeLevels,
+		CurrentRegime:      s.CurrentRegimeLevel,
+		LastRegimeCheck:    s.LastRegimeCheck,
+		CurrentRegimeLevel: s.CurrentRegimeLevel,
+
+		ShortBoxUpper: s.ShortBoxUpper,
+		ShortBoxLower: s.ShortBoxLower,
+		MidBoxUpper:   s.MidBoxUpper,
+		MidBoxLower:   s.MidBoxLower,
+		LongBoxUpper:  s.LongBoxUpper,
+		LongBoxLower:  s.LongBoxLower,
+
+		BreakoutLevel:        s.BreakoutLevel,
+		BreakoutDirection:    s.BreakoutDirection,
+		BreakoutConfirmCount: s.BreakoutConfirmCount,
+		PositionReductionPct: s.PositionReductionPct,
+
+		CurrentDirection:     string(s.CurrentDirection),
+		DirectionChangedAt:   s.DirectionChangedAt,
+		DirectionChangeCount: s.DirectionChangeCount,
+		LastTunedAt:          s.LastTunedAt,
+		TrailCount:           s.TrailCount,
+		LastTrailAt:          s.LastTrailAt,
+
+		TotalProfit:    s.TotalProfit,
+		TotalTrades:    s.TotalTrades,
+		WinningTrades:  s.WinningTrades,
+		MaxDrawdown:    s.MaxDrawdown,
+		PeakEquity:     s.PeakEquity,
+		DailyProfit:    dailyProfit,
+		DailyLoss:      dailyLoss,
+		LastDailyReset: s.LastDailyReset,
+	}
+}
+
+// gridLevelModelLocked snapshots one level into a level row (caller must hold lock)
+func gridLevelModelLocked(s *GridState, index int) store.GridLevelModel {
+	level := s.Levels[index]
+	model := store.GridLevelModel{
+		ID:            gridLevelID(s.InstanceID, level.Index),
+		InstanceID:    s.InstanceID,
+		LevelIndex:    level.Index,
+		Price:         level.Price,
+		State:         level.State,
+		Side:          level.Side,
+		OrderID:       level.OrderID,
+		OrderQuantity: level.OrderQuantity,
+		PositionSize:  level.PositionSize,
+		PositionEntry: level.PositionEntry,
+		AllocatedUSD:  level.AllocatedUSD,
+		ExitOrderID:   level.ExitOrderID,
+		ExitPrice:     level.ExitPrice,
+		EntryFee:      level.EntryFee,
+	}
+	if level.State == "pending" {
+		model.OrderPrice = level.Price
+	}
+	if level.FilledAt > 0 {
+		openedAt := time.UnixMilli(level.FilledAt).UTC()
+		model.PositionOpenAt = &openedAt
+	}
+	if s.Config != nil && s.Config.TotalInvestment > 0 {
+		model.AllocationWeight = level.AllocatedUSD / s.Config.TotalInvestment
+	}
+	return model
+}
+
+// restoreGridStateLocked loads a persisted instance and its levels into the grid state (caller must hold lock)
+func restoreGridStateLocked(s *GridState, inst *store.GridInstanceModel, levels []store.GridLevelModel) {
+	s.InstanceID = inst.ID
+	s.StartedAt = inst.StartedAt
+	s.IsPaused = inst.State == gridInstancePaused
+
+	s.UpperPrice = inst.CurrentUpperPrice
+	s.LowerPrice = inst.CurrentLowerPrice
+	s.GridSpacing = inst.CurrentGridSpacing
+	s.CurrentRegimeLevel = inst.CurrentRegimeLevel
+	s.LastRegimeCheck = inst.LastRegimeCheck
+
+	s.ShortBoxUpper, s.ShortBoxLower = inst.ShortBoxUpper, inst.ShortBoxLower
+	s.MidBoxUpper, s.MidBoxLower = inst.MidBoxUpper, inst.MidBoxLower
+	s.LongBoxUpper, s.LongBoxLower = inst.LongBoxUpper, inst.LongBoxLower
+
+	s.BreakoutLevel = inst.BreakoutLevel
+	s.BreakoutDirection = inst.BreakoutDirection
+	s.BreakoutConfirmCount = inst.BreakoutConfirmCount
+	s.PositionReductionPct = inst.PositionReductionPct
+
+	s.CurrentDirection = market.GridDirection(inst.CurrentDirection)
+	if s.CurrentDirection == "" {
+		s.CurrentDirection = market.GridDirectionNeutral
+	}
+	s.DirectionChangedAt = inst.DirectionChangedAt
+	s.DirectionChangeCount = inst.DirectionChangeCount
+	s.LastTunedAt = inst.LastTunedAt
+	s.TrailCount = inst.TrailCount
+	s.LastTrailAt = inst.LastTrailAt
+
+	s.TotalProfit = inst.TotalProfit
+	s.TotalTrades = inst.TotalTrades
+	s.WinningTrades = inst.WinningTrades
+	s.MaxDrawdown = inst.MaxDrawdown
+	s.PeakEquity = inst.PeakEquity
+	s.DailyPnL = inst.DailyProfit - inst.DailyLoss
+	s.LastDailyReset = inst.LastDailyReset
+
+	s.Levels = make([]kernel.GridLevelInfo, len(levels))
+	s.OrderBook = make(map[string]int)
+	for i, l := range levels {
+		s.Levels[i] = kernel.GridLevelInfo{
+			Index:         l.LevelIndex,
+			Price:         l.Price,
+			State:         l.State,
+			Side:          l.Side,
+			OrderID:       l.OrderID,
+			OrderQuantity: l.OrderQuantity,
+			PositionSize:  l.PositionSize,
+			PositionEntry: l.PositionEntry,
+			AllocatedUSD:  l.AllocatedUSD,
+			ExitOrderID:   l.ExitOrderID,
+			ExitPrice:     l.ExitPrice,
+			EntryFee:      l.EntryFee,
+		}
+		if l.PositionOpenAt != nil {
+			s.Levels[i].FilledAt = l.PositionOpenAt.UnixMilli()
+		}
+		if l.State == "pending" && l.OrderID != "" {
+			s.OrderBook[l.OrderID] = i
+		}
+		if l.State == "filled" && l.ExitOrderID != "" {
+			s.OrderBook[l.ExitOrderID] = i
+		}
+	}
+}
+
+// levelEventLocked builds an event for a level transition (caller must hold lock)
+func (s *GridState) levelEventLocked(eventType string, index int) store.GridEventModel {
+	level := s.Levels[index]
+	quantity := level.OrderQuantity
+	if level.State == "filled" || level.State == "stopped" {
+		quantity = level.PositionSize
+	}
+	return store.GridEventModel{
+		EventType: eventType,
+		LevelID:   gridLevelID(s.InstanceID, level.Index),
+		Price:     level.Price,
+		Quantity:  quantity,
+		Side:      level.Side,
+	}
+}
+
+// reconcileOrdersLocked resolves the grid orders that are no longer open on the exchange (caller
+// must hold lock). statuses holds the final exchange status of those orders when it is known;
+// otherwise a vanished entry counts as filled while the position is larger than what the filled
+// levels account for, and a vanished exit as filled while it is smaller. Returns the level events.
+func (s *GridState) reconcileOrdersLocked(openOrders []OpenOrder, statuses map[string]string, positionSize float64) []store.GridEventModel {
+	active := make(map[string]bool, len(openOrders))
+	for _, order := range openOrders {
+		active[order.OrderID] = true
+	}
+
+	expectedPositionSize := 0.0
+	for _, level := range s.Levels {
+		if level.State == "filled" {
+			expectedPositionSize += level.PositionSize
+		}
+	}
+
+	var events []store.GridEventModel
+	for i := range s.Levels {
+		level := &s.Levels[i]
+		switch {
+		case level.State == "pending" && level.OrderID != "" && !active[level.OrderID]:
+			filled, known := gridOrderFilled(statuses[level.OrderID])
+			if !known {
+				// No order status - infer the fill from the position
+				filled = math.Abs(positionSize) > expectedPositionSize
+			} else if isGridOrderLive(statuses[level.OrderID]) {
+				continue
+			}
+			delete(s.OrderBook, level.OrderID)
+
+			if filled {
+				level.State = "filled"
+				level.PositionEntry = level.Price
+				level.PositionSize = level.OrderQuantity
+				level.FilledAt = time.Now().UnixMilli()
+				level.EntryFee = level.Price * level.PositionSize * gridMakerFeeRate
+				expectedPositionSize += level.PositionSize
+				s.TotalTrades++
+				events = append(events, s.levelEventLocked(store.GridEventOrderFilled, i))
+				logger.Infof("[Grid] Level %d order filled at $%.2f", i, level.Price)
+			} else {
+				events = append(events, s.levelEventLocked(store.GridEventOrderCancelled, i))
+				level.State = "empty"
+				level.OrderID = ""
+				level.OrderQuantity = 0
+				logger.Infof("[Grid] Level %d order cancelled/expired", i)
+			}
+
+		case level.State == "filled" && level.ExitOrderID != "" && !active[level.ExitOrderID]:
+			filled, known := gridOrderFilled(statuses[level.ExitOrderID])
+			if !known {
+				filled = math.Abs(positionSize) <= expectedPositionSize-level.PositionSize/2
+			} else if isGridOrderLive(statuses[level.ExitOrderID]) {
+				continue
+			}
+			delete(s.OrderBook, level.ExitOrderID)
+
+			exitSide := "sell"
+			if level.Side == "sell" {
+				exitSide = "buy"
+			}
+			event := store.GridEventModel{
+				LevelID:  gridLevelID(s.InstanceID, level.Index),
+				Price:    level.ExitPrice,
+				Quantity: level.PositionSize,
+				Side:     exitSide,
+				Message:  fmt.Sprintf("paired exit of level %d", i),
+			}
+			if filled {
+				pnl := (level.ExitPrice - level.PositionEntry) * level.PositionSize
+				if level.Side == "sell" {
+					pnl = -pnl
+				}
+				s.TotalProfit += pnl
+				s.DailyPnL += pnl
+				if pnl > 0 {
+					s.WinningTrades++
+				}
+				expectedPositionSize -= level.PositionSize
+				event.EventType = store.GridEventOrderFilled
+				event.PnL = pnl
+				trip := kernel.CloseGridRoundTrip(s.Config.Symbol, *level, level.ExitPrice,
+					level.ExitPrice*level.PositionSize*gridMakerFeeRate, kernel.GridExitPaired, time.Now().UTC())
+				event.Fee = trip.EntryFee + trip.ExitFee
+				s.closedTrips = append(s.closedTrips, trip)
+				logger.Infof("[Grid] Level %d paired exit filled at $%.2f, PnL %.4f", i, level.ExitPrice, pnl)
+
+				level.State = "empty"
+				level.OrderID = ""
+				level.OrderQuantity = 0
+				level.PositionSize = 0
+				level.PositionEntry = 0
+				level.FilledAt = 0
+				level.EntryFee = 0
+				level.UnrealizedPnL = 0
+			} else {
+				event.EventType = store.GridEventOrderCancelled
+				logger.Infof("[Grid] Level %d paired exit cancelled/expired", i)
+			}
+			level.ExitOrderID = ""
+			level.ExitPrice = 0
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// gridOrderFilled interprets an exchange order status: (filled, known)
+func gridOrderFilled(status string) (bool, bool) {
+	if status == "" {
+		return false, false
+	}
+	return status == "FILLED", true
+}
+
+// isGridOrderLive reports whether an exchange order status means the order can still fill
+func isGridOrderLive(status string) bool {
+	switch status {
+	case "NEW", "OPEN", "PARTIALLY_FILLED":
+		return true
+	}
+	return false
+}
+
+// adoptOrdersLocked maps open orders unknown to the grid (placed after the last save) back to
+// the empty level they were placed at (caller must hold lock). Returns the number of adopted orders.
+func (s *GridState) adoptOrdersLocked(openOrders []OpenOrder) int {
+	adopted := 0
+	for _, order := range openOrders {
+		if _, known := s.OrderBook[order.OrderID]; known || order.Price <= 0 {
+			continue
+		}
+
+		if s.adoptExitOrderLocked(order) {
+			adopted++
+			continue
+		}
+
+		closest, closestDist := -1, math.MaxFloat64
+		for i, level := range s.Levels {
+			if dist := math.Abs(level.Price - order.Price); dist < closestDist {
+				closest, closestDist = i, dist
+			}
+		}
+		if closest < 0 || closestDist > s.GridSpacing/2 || s.Levels[closest].State != "empty" {
+			logger.Warnf("[Grid] Open order %s at $%.2f does not match any empty level, leaving it untracked",
+				order.OrderID, order.Price)
+			continue
+		}
+
+		level := &s.Levels[closest]
+		level.State = "pending"
+		level.OrderID = order.OrderID
+		level.OrderQuantity = order.Quantity
+		s.OrderBook[order.OrderID] = closest
+		adopted++
+	}
+	return adopted
+}
+
+// adoptExitOrderLocked maps an unknown open order to the filled level it is the paired exit of (caller must hold lock)
+func (s *GridState) adoptExitOrderLocked(order OpenOrder) bool {
+	side := strings.ToLower(order.Side)
+	for i := range s.Levels {
+		level := &s.Levels[i]
+		if level.State != "filled" || level.ExitOrderID != "" || side == level.Side {
+			continue
+		}
+		exitPrice := kernel.GridExitPrice(s.Levels, i, s.GridSpacing)
+		if math.Abs(exitPrice-order.Price) > s.GridSpacing/2 {
+			continue
+		}
+		level.ExitOrderID = order.OrderID
+		level.ExitPrice = order.Price
+		s.OrderBook[order.OrderID] = i
+		return true
+	}
+	return false
+}
+
+// restoreGrid rehydrates the grid from the last persisted instance and reconciles it with the
+// exchange open orders. Returns false when there is no instance to resume
+func (at *AutoTrader) restoreGrid() (bool, error) {
+	if at.store == nil {
+		return false, nil
+	}
+	gridConfig := at.gridState.Config
+
+	// Every grid of a multi-symbol grid has its own instance
+	var inst *store.GridInstanceModel
+	var err error
+	if kernel.IsMultiSymbolGrid(at.strategyConfig().GridConfig) {
+		inst, err = at.store.Grid().LoadGridInstanceBySymbol(at.id, gridConfig.Symbol)
+	} else {
+		inst, err = at.store.Grid().LoadGridInstance(at.id)
+	}
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warnf("[Grid] Failed to load grid instance: %v", err)
+		}
+		return false, nil
+	}
+	if inst.State == gridInstanceStopped {
+		return false, nil
+	}
+
+	levels, err := at.store.Grid().LoadGridLevels(inst.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load grid levels: %w", err)
+	}
+	if inst.Symbol != gridConfig.Symbol || len(levels) != gridConfig.GridCount {
+		logger.Infof("📊 [Grid] Configuration changed since last run (%s, %d levels), starting a new grid",
+			inst.Symbol, len(levels))
+		at.stopGridInstance(inst)
+		return false, nil
+	}
+
+	openOrders, err := at.trader.GetOpenOrders(gridConfig.Symbol)
+	if err != nil {
+		return false, fmt.Errorf("failed to get open orders for reconciliation: %w", err)
+	}
+	positionSize, err := at.gridPositionSize()
+	if err != nil {
+		return false, fmt.Errorf("failed to get position for reconciliation: %w", err)
+	}
+
+	at.gridState.mu.Lock()
+	restoreGridStateLocked(at.gridState, inst, levels)
+	at.gridState.mu.Unlock()
+	statuses := at.gridOrderStatuses(openOrders)
+
+	at.gridState.mu.Lock()
+	events := at.gridState.reconcileOrdersLocked(openOrders, statuses, positionSize)
+	adopted := at.gridState.adoptOrdersLocked(openOrders)
+	pending := len(at.gridState.OrderBook)
+	at.gridState.mu.Unlock()
+
+	for _, event := range events {
+		at.recordGridEvent(event)
+	}
+	at.saveGridRoundTrips()
+	at.recordGridEvent(store.GridEventModel{
+		EventType: store.GridEventRestored,
+		Message: fmt.Sprintf("restored %d levels: %d pending orders, %d resolved while offline, %d adopted",
+			len(levels), pending, len(events), adopted),
+	})
+	at.saveGridState()
+
+	logger.Infof("📊 [Grid] Restored instance %s: %d pending orders, %d resolved while offline, %d adopted",
+		inst.ID, pending, len(events), adopted)
+	return true, nil
+}
+
+// gridOrderStatuses looks up the exchange status of the grid orders that left the order book
+func (at *AutoTrader) gridOrderStatuses(openOrders []OpenOrder) map[string]string {
+	active := make(map[string]bool, len(openOrders))
+	for _, order := range openOrders {
+		active[order.OrderID] = true
+	}
+
+	var vanished []string
+	at.gridState.mu.RLock()
+	for _, level := range at.gridState.Levels {
+		if level.State == "pending" && level.OrderID != "" && !active[level.OrderID] {
+			vanished = append(vanished, level.OrderID)
+		}
+		if level.State == "filled" && level.ExitOrderID != "" && !active[level.ExitOrderID] {
+			vanished = append(vanished, level.ExitOrderID)
+		}
+	}
+	symbol := at.gridState.Config.Symbol
+	at.gridState.mu.RUnlock()
+
+	statuses := make(map[string]string, len(vanished))
+	for _, orderID := range vanished {
+		result, err := at.trader.GetOrderStatus(symbol, orderID)
+		if err != nil {
+			logger.Debugf("[Grid] Order status of %s unavailable: %v", orderID, err)
+			continue
+		}
+		if status, ok := result["status"].(string); ok {
+			statuses[orderID] = strings.ToUpper(status)
+		}
+	}
+	return statuses
+}
+
+// startGridInstance creates the instance row of a freshly initialized grid
+func (at *AutoTrader) startGridInstance() {
+	at.gridState.mu.Lock()
+	at.gridState.InstanceID = uuid.New().String()
+	at.gridState.StartedAt = time.Now().UTC()
+	at.gridState.LastTunedAt = at.gridState.StartedAt
+	at.gridState.mu.Unlock()
+
+	at.saveGridState()
+	at.recordGridEvent(store.GridEventModel{
+		EventType: store.GridEventStarted,
+		Message:   fmt.Sprintf("%d levels, $%.2f - $%.2f", len(at.gridState.Levels), at.gridState.LowerPrice, at.gridState.UpperPrice),
+	})
+}
+
+// stopGridInstance marks a superseded instance as stopped so it is never resumed
+func (at *AutoTrader) stopGridInstance(inst *store.GridInstanceModel) {
+	now := time.Now().UTC()
+	inst.State = gridInstanceStopped
+	inst.StoppedAt = &now
+	if err := at.store.Grid().SaveGridInstance(inst); err != nil {
+		logger.Warnf("[Grid] Failed to stop grid instance %s: %v", inst.ID, err)
+	}
+}
+
+// saveGridState persists the grid instance and all of its levels
+func (at *AutoTrader) saveGridState() {
+	if at.store == nil || at.gridState == nil || at.gridState.InstanceID == "" {
+		return
+	}
+
+	at.gridState.mu.RLock()
+	inst := gridInstanceModelLocked(at.gridState, at.id)
+	levels := make([]store.GridLevelModel, len(at.gridState.Levels))
+	for i := range at.gridState.Levels {
+		levels[i] = gridLevelModelLocked(at.gridState, i)
+	}
+	at.gridState.mu.RUnlock()
+
+	if err := at.store.Grid().SaveGridInstance(inst); err != nil {
+		logger.Warnf("[Grid] Failed to save grid instance: %v", err)
+	}
+	if err := at.store.Grid().SaveGridLevels(levels); err != nil {
+		logger.Warnf("[Grid] Failed to save grid levels: %v", err)
+	}
+}
+
+// saveGridLevel persists a single level right after its order changed
+func (at *AutoTrader) saveGridLevel(index int) {
+	if at.store == nil || at.gridState == nil || at.gridState.InstanceID == "" {
+		return
+	}
+
+	at.gridState.mu.RLock()
+	if index < 0 || index >= len(at.gridState.Levels) {
+		at.gridState.mu.RUnlock()
+		return
+	}
+	level := gridLevelModelLocked(at.gridState, index)
+	at.gridState.mu.RUnlock()
+
+	if err := at.store.Grid().SaveGridLevel(&level); err != nil {
+		logger.Warnf("[Grid] Failed to save grid level %d: %v", index, err)
+	}
+}
+
+// saveGridRoundTrips persists the round trips closed since the last call
+func (at *AutoTrader) saveGridRoundTrips() {
+	if at.gridState == nil {
+		return
+	}
+	at.gridState.mu.Lock()
+	trips := at.gridState.closedTrips
+	at.gridState.closedTrips = nil
+	instanceID := at.gridState.InstanceID
+	at.gridState.mu.Unlock()
+
+	if at.store == nil || instanceID == "" {
+		return
+	}
+	for _, trip := range trips {
+		model := gridRoundTripModel(instanceID, trip)
+		if err := at.store.Grid().SaveGridRoundTrip(&model); err != nil {
+			logger.Warnf("[Grid] Failed to save round trip of level %d: %v", trip.LevelIndex, err)
+		}
+	}
+}
+
+// gridRoundTripModel converts a round trip into its row
+func gridRoundTripModel(instanceID string, trip kernel.GridRoundTrip) store.GridRoundTripModel {
+	return store.GridRoundTripModel{
+		ID:         uuid.New().String(),
+		InstanceID: instanceID,
+		Symbol:     trip.Symbol,
+		LevelIndex: trip.LevelIndex,
+		LevelPrice: trip.LevelPrice,
+		Side:       trip.Side,
+		Quantity:   trip.Quantity,
+		EntryPrice: trip.EntryPrice,
+		ExitPrice:  trip.ExitPrice,
+		EntryFee:   trip.EntryFee,
+		ExitFee:    trip.ExitFee,
+		GrossPnL:   trip.GrossPnL,
+		NetPnL:     trip.NetPnL,
+		ExitReason: trip.ExitReason,
+		OpenedAt:   trip.OpenedAt,
+		ClosedAt:   trip.ClosedAt,
+	}
+}
+
+// gridRoundTripFromModel converts a stored round trip back
+func gridRoundTripFromModel(m store.GridRoundTripModel) kernel.GridRoundTrip {
+	return kernel.GridRoundTrip{
+		Symbol:     m.Symbol,
+		LevelIndex: m.LevelIndex,
+		LevelPrice: m.LevelPrice,
+		Side:       m.Side,
+		Quantity:   m.Quantity,
+		EntryPrice: m.EntryPrice,
+		ExitPrice:  m.ExitPrice,
+		EntryFee:   m.EntryFee,
+		ExitFee:    m.ExitFee,
+		GrossPnL:   m.GrossPnL,
+		NetPnL:     m.NetPnL,
+		ExitReason: m.ExitReason,
+		OpenedAt:   m.OpenedAt,
+		ClosedAt:   m.ClosedAt,
+	}
+}
+
+// GetGridLedger returns the round-trip ledger of the running grid instances: profit per level and
+// per day, and the grid APR next to the unrealized PnL of the inventory the grid holds
+func (at *AutoTrader) GetGridLedger() (*kernel.GridLedger, error) {
+	poolConfig := at.strategyConfig().GridConfig
+	states := at.gridStateList()
+	if poolConfig == nil || len(states) == 0 {
+		return nil, fmt.Errorf("grid is not running")
+	}
+	if at.store == nil {
+		return nil, fmt.Errorf("grid ledger requires a store")
+	}
+
+	var (
+		trips      []kernel.GridRoundTrip
+		unrealized float64
+		start      time.Time
+	)
+	for _, gs := range states {
+		gs.mu.RLock()
+		instanceID, startedAt, symbol := gs.InstanceID, gs.StartedAt, gs.Config.Symbol
+		levels := append([]kernel.GridLevelInfo(nil), gs.Levels...)
+		gs.mu.RUnlock()
+		if instanceID == "" {
+			continue
+		}
+		if start.IsZero() || startedAt.Before(start) {
+			start = startedAt
+		}
+
+		models, err := at.store.Grid().LoadGridRoundTrips(instanceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load round trips of %s: %w", symbol, err)
+		}
+		for _, m := range models {
+			trips = append(trips, gridRoundTripFromModel(m))
+		}
+		if price, err := at.trader.GetMarketPrice(symbol); err == nil {
+			unrealized += kernel.GridInventoryPnL(levels, price)
+		}
+	}
+	return kernel.SummarizeGridLedger(trips, poolConfig.TotalInvestment, unrealized, start, time.Now()), nil
+}
+
+// recordGridEvent stores a grid event for the current instance.
+// Does not take the grid lock (InstanceID is only set during initialization).
+func (at *AutoTrader) recordGridEvent(event store.GridEventModel) {
+	if at.store == nil || at.gridState == nil || at.gridState.InstanceID == "" {
+		return
+	}
+	event.ID = uuid.New().String()
+	event.InstanceID = at.gridState.InstanceID
+	if err := at.store.Grid().SaveGridEvent(&event); err != nil {
+		logger.Warnf("[Grid] Failed to save grid event %s: %v", event.EventType, err)
+	}
+}
+
+// assessGridRegime classifies the regime level from the cycle's market data, stores the
+// assessment and records a regime change
+func (at *AutoTrader) assessGridRegime(ctx *kernel.GridContext) {
+	if ctx.CurrentPrice <= 0 {
+		return
+	}
+	regime := string(classifyRegimeLevel(ctx.BollingerWidth, ctx.ATR14/ctx.CurrentPrice*100))
+
+	at.gridState.mu.Lock()
+	oldRegime := at.gridState.CurrentRegimeLevel
+	at.gridState.CurrentRegimeLevel = regime
+	at.gridState.LastRegimeCheck = time.Now().UTC()
+	instanceID := at.gridState.InstanceID
+	at.gridState.mu.Unlock()
+
+	if oldRegime != "" && oldRegime != regime {
+		logger.Infof("📊 [Grid] Regime changed: %s → %s", oldRegime, regime)
+		at.recordGridEvent(store.GridEventModel{
+			EventType: store.GridEventRegimeChange,
+			Price:     ctx.CurrentPrice,
+			OldRegime: oldRegime,
+			NewRegime: regime,
+		})
+	}
+
+	if at.store == nil || instanceID == "" {
+		return
+	}
+	assessment := &store.GridRegimeAssessmentModel{
+		ID:             uuid.New().String(),
+		InstanceID:     instanceID,
+		Regime:         regime,
+		ATR14:          ctx.ATR14,
+		BollingerWidth: ctx.BollingerWidth,
+		EMADistance:    ctx.EMADistance,
+		CurrentPrice:   ctx.CurrentPrice,
+	}
+	if err := at.store.Grid().SaveGridRegimeAssessment(assessment); err != nil {
+		logger.Warnf("[Grid] Failed to save regime assessment: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package trader
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+func gridStoreTestState() *GridState {
+	state := NewGridState(&store.GridStrategyConfig{Symbol: "BTCUSDT", GridCount: 4, TotalInvestment: 1000})
+	state.InstanceID = "inst-1"
+	state.StartedAt = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	state.UpperPrice, state.LowerPrice, state.GridSpacing = 103, 100, 1
+	state.Levels = []kernel.GridLevelInfo{
+		{Index: 0, Price: 100, State: "filled", Side: "buy", OrderID: "o0", OrderQuantity: 0.5, PositionSize: 0.5, PositionEntry: 100, AllocatedUSD: 250,
+			FilledAt: time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC).UnixMilli(), EntryFee: 0.01},
+		{Index: 1, Price: 101, State: "pending", Side: "buy", OrderID: "o1", OrderQuantity: 0.5, AllocatedUSD: 250},
+		{Index: 2, Price: 102, State: "pending", Side: "sell", OrderID: "o2", OrderQuantity: 0.5, AllocatedUSD: 250},
+		{Index: 3, Price: 103, State: "empty", Side: "sell", AllocatedUSD: 250},
+	}
+	state.OrderBook = map[string]int{"o1": 1, "o2": 2}
+	state.TotalProfit, state.TotalTrades, state.DailyPnL = 12.5, 7, -3
+	state.CurrentDirection = market.GridDirectionLongBias
+	state.DirectionChangeCount = 2
+	state.BreakoutLevel = string(market.BreakoutShort)
+	state.IsPaused = true
+	return state
+}
+
+func TestGridStateRoundTrip(t *testing.T) {
+	state := gridStoreTestState()
+
+	inst := gridInstanceModelLocked(state, "trader-1")
+	levels := make([]store.GridLevelModel, len(state.Levels))
+	for i := range state.Levels {
+		levels[i] = gridLevelModelLocked(state, i)
+	}
+	if inst.ConfigID != "trader-1" || inst.State != gridInstancePaused || inst.ActiveLevelCount != 3 {
+		t.Errorf("unexpected instance row: %+v", inst)
+	}
+	if inst.DailyProfit != 0 || inst.DailyLoss != 3 {
+		t.Errorf("daily PnL stored as %.2f/%.2f, want 0/3", inst.DailyProfit, inst.DailyLoss)
+	}
+	if levels[1].ID != "inst-1-1" || levels[1].OrderPrice != 101 || levels[1].AllocationWeight != 0.25 {
+		t.Errorf("unexpected level row: %+v", levels[1])
+	}
+
+	restored := NewGridState(state.Config)
+	restoreGridStateLocked(restored, inst, levels)
+	if restored.InstanceID != "inst-1" || !restored.IsPaused || restored.DailyPnL != -3 ||
+		restored.TotalProfit != 12.5 || restored.TotalTrades != 7 {
+		t.Errorf("counters not restored: %+v", restored)
+	}
+	if restored.CurrentDirection != market.GridDirectionLongBias || restored.DirectionChangeCount != 2 ||
+		restored.BreakoutLevel != string(market.BreakoutShort) {
+		t.Errorf("direction/breakout not restored: %+v", restored)
+	}
+	if len(restored.Levels) != 4 || restored.Levels[0].PositionSize != 0.5 || restored.Levels[2].OrderID != "o2" ||
+		restored.Levels[0].FilledAt != state.Levels[0].FilledAt || restored.Levels[0].EntryFee != 0.01 {
+		t.Errorf("levels not restored: %+v", restored.Levels)
+	}
+	if len(restored.OrderBook) != 2 || restored.OrderBook["o1"] != 1 || restored.OrderBook["o2"] != 2 {
+		t.Errorf("order book = %v, want pending orders only", restored.OrderBook)
+	}
+}
+
+func TestGridReconcileOrders(t *testing.T) {
+	state := gridStoreTestState()
+
+	// o1 is gone and the position grew by one level: filled. o2 is still open.
+	events := state.reconcileOrdersLocked([]OpenOrder{{OrderID: "o2", Price: 102}}, nil, 1.0)
+	if len(events) != 1 || events[0].EventType != store.GridEventOrderFilled || events[0].LevelID != "inst-1-1" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if state.Levels[1].State != "filled" || state.Levels[1].PositionSize != 0.5 || state.TotalTrades != 8 {
+		t.Errorf("level 1 not filled: %+v", state.Levels[1])
+	}
+	if _, ok := state.OrderBook["o1"]; ok {
+		t.Error("filled order still in order book")
+	}
+
+	// o2 vanished but the position did not grow: cancelled
+	events = state.reconcileOrdersLocked(nil, nil, 1.0)
+	if len(events) != 1 || events[0].EventType != store.GridEventOrderCancelled || events[0].Quantity != 0.5 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if state.Levels[2].State != "empty" || state.Levels[2].OrderID != "" || len(state.OrderBook) != 0 {
+		t.Errorf("level 2 not cleared: %+v, order book %v", state.Levels[2], state.OrderBook)
+	}
+}
+
+func TestGridReconcileExitOrders(t *testing.T) {
+	state := gridStoreTestState()
+	state.Levels[0].ExitOrderID, state.Levels[0].ExitPrice = "x0", 101
+	state.OrderBook["x0"] = 0
+
+	// The exchange still reports the exit as open: nothing changes
+	events := state.reconcileOrdersLocked([]OpenOrder{{OrderID: "o1"}, {OrderID: "o2"}}, map[string]string{"x0": "NEW"}, 0.5)
+	if len(events) != 0 || state.Levels[0].ExitOrderID != "x0" {
+		t.Fatalf("live exit reconciled: %+v", events)
+	}
+
+	events = state.reconcileOrdersLocked([]OpenOrder{{OrderID: "o1"}, {OrderID: "o2"}}, map[string]string{"x0": "FILLED"}, 0)
+	if len(events) != 1 || events[0].EventType != store.GridEventOrderFilled || events[0].PnL != 0.5 || events[0].Side != "sell" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if state.TotalProfit != 13 || state.DailyPnL != -2.5 || state.WinningTrades != 1 {
+		t.Errorf("exit PnL not realized: profit %.2f, daily %.2f, wins %d", state.TotalProfit, state.DailyPnL, state.WinningTrades)
+	}
+	// The round trip is queued for the ledger with maker fees on both fills
+	if len(state.closedTrips) != 1 {
+		t.Fatalf("got %d round trips, want 1", len(state.closedTrips))
+	}
+	trip := state.closedTrips[0]
+	if trip.LevelIndex != 0 || trip.EntryPrice != 100 || trip.ExitPrice != 101 || trip.GrossPnL != 0.5 ||
+		trip.ExitReason != kernel.GridExitPaired || math.Abs(trip.NetPnL-(0.5-0.01-101*0.5*gridMakerFeeRate)) > 1e-9 {
+		t.Errorf("unexpected round trip: %+v", trip)
+	}
+	if level := state.Levels[0]; level.State != "empty" || level.PositionSize != 0 || level.ExitOrderID != "" {
+		t.Errorf("level 0 not reset: %+v", level)
+	}
+	if _, ok := state.OrderBook["x0"]; ok {
+		t.Error("filled exit still in order book")
+	}
+
+	// A cancelled exit keeps the position so a new exit can be placed
+	state = gridStoreTestState()
+	state.Levels[0].ExitOrderID, state.Levels[0].ExitPrice = "x0", 101
+	events = state.reconcileOrdersLocked([]OpenOrder{{OrderID: "o1"}, {OrderID: "o2"}}, map[string]string{"x0": "CANCELED"}, 0.5)
+	if len(events) != 1 || events[0].EventType != store.GridEventOrderCancelled {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if level := state.Levels[0]; level.State != "filled" || level.PositionSize != 0.5 || level.ExitOrderID != "" || level.ExitPrice != 0 {
+		t.Errorf("cancelled exit not cleared: %+v", level)
+	}
+}
+
+func TestGridAdoptOrders(t *testing.T) {
+	state := gridStoreTestState()
+
+	adopted := state.adoptOrdersLocked([]OpenOrder{
+		{OrderID: "o2", Price: 102},                  // Already tracked
+		{OrderID: "o3", Price: 103.2, Quantity: 0.4}, // Empty level 3
+		{OrderID: "o4", Price: 100.1, Quantity: 0.4}, // Level 0 is filled
+		{OrderID: "o5", Price: 110, Quantity: 0.4},   // Outside the grid
+	})
+	if adopted != 1 {
+		t.Fatalf("adopted %d orders, want 1", adopted)
+	}
+	if state.Levels[3].State != "pending" || state.Levels[3].OrderID != "o3" || state.OrderBook["o3"] != 3 {
+		t.Errorf("o3 not adopted at level 3: %+v", state.Levels[3])
+	}
+}
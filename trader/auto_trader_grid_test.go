@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// newGridPoolTrader builds a multi-symbol grid trader with its symbol grid states (no exchange)
+func newGridPoolTrader(pool *store.GridStrategyConfig) *AutoTrader {
+	at := &AutoTrader{config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{
+		StrategyType: "grid_trading",
+		GridConfig:   pool,
+	}}}
+	for _, cfg := range kernel.GridSymbolConfigs(pool, nil) {
+		at.gridStates = append(at.gridStates, NewGridState(cfg))
+	}
+	at.gridState = at.gridStates[0]
+	return at
+}
+
+func TestGridPoolDailyLossLimit(t *testing.T) {
+	at := newGridPoolTrader(&store.GridStrategyConfig{
+		TotalInvestment: 1000, Leverage: 2, GridCount: 5, DailyLossLimitPct: 5,
+		Symbols: []store.GridSymbolConfig{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}},
+	})
+	now := time.Now()
+	for _, gs := range at.gridStates {
+		gs.LastDailyReset = now
+	}
+
+	// 30 USDT lost on each grid is 3% of its allocation but 6% of the pool together
+	at.gridStates[0].DailyPnL = -30
+	at.gridStates[1].DailyPnL = -30
+	exceeded, pct := at.checkDailyLossLimit()
+	if !exceeded || math.Abs(pct-6) > 1e-9 {
+		t.Errorf("combined daily loss = %.2f%% (exceeded %v), want 6%% over the limit", pct, exceeded)
+	}
+
+	// A new day resets every grid
+	for _, gs := range at.gridStates {
+		gs.LastDailyReset = now.AddDate(0, 0, -1)
+	}
+	if exceeded, pct := at.checkDailyLossLimit(); exceeded || pct != 0 {
+		t.Errorf("daily loss after reset = %.2f%%, want 0", pct)
+	}
+}
+
+func TestGridSymbolRiskInfo(t *testing.T) {
+	at := newGridPoolTrader(&store.GridStrategyConfig{
+		TotalInvestment: 1000, Leverage: 2, GridCount: 4,
+		Symbols: []store.GridSymbolConfig{{Symbol: "BTCUSDT", Weight: 3}, {Symbol: "ETHUSDT"}},
+	})
+	eth := at.gridStates[1]
+	eth.Levels = []kernel.GridLevelInfo{
+		{State: "pending", Price: 100, OrderQuantity: 2},
+		{State: "filled", Price: 110},
+		{State: "pending", Price: 120, OrderQuantity: 1},
+	}
+	eth.TotalProfit = 12.5
+	positions := []map[string]interface{}{
+		{"symbol": "BTCUSDT", "positionAmt": 0.01, "entryPrice": 60000.0, "markPrice": 61000.0},
+		{"symbol": "ETHUSDT", "positionAmt": -1.5, "entryPrice": 110.0},
+	}
+
+	// Position at mark price (or entry price) plus the pending orders
+	if v := gridExposure(positions, "BTCUSDT", at.gridStates[0]); math.Abs(v-610) > 1e-9 {
+		t.Errorf("BTC exposure = %.2f, want 610", v)
+	}
+	if v := gridExposure(positions, "ETHUSDT", eth); math.Abs(v-(165+200+120)) > 1e-9 {
+		t.Errorf("ETH exposure = %.2f, want 485", v)
+	}
+
+	stats := gridSymbolRiskInfo(eth, positions)
+	if stats.Symbol != "ETHUSDT" || stats.Allocation != 250 || stats.ActiveOrders != 2 || stats.FilledLevels != 1 {
+		t.Errorf("unexpected ETH stats: %+v", stats)
+	}
+	if stats.PositionSize != -1.5 || math.Abs(stats.CurrentPosition-165) > 1e-9 || stats.TotalProfit != 12.5 {
+		t.Errorf("unexpected ETH position stats: %+v", stats)
+	}
+}
@@ -0,0 +1,352 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Pairs Trading State Management
+// ============================================================================
+
+// pairsSource position source of pairs legs
+const pairsSource = "pairs_trading"
+
+// PairsState holds the runtime state for pairs trading
+type PairsState struct {
+	mu sync.Mutex
+
+	// Configuration
+	Config *store.PairsStrategyConfig
+
+	// Group of the open pair ("" = flat); both legs are linked to it in trader_positions
+	GroupID string
+
+	// Position records of the legs by symbol (0 = not linked yet)
+	PositionIDs map[string]int64
+
+	IsInitialized bool
+}
+
+func init() {
+	RegisterStrategyCycle("pairs_trading", StrategyCycle{Name: "Pairs", Init: (*AutoTrader).InitializePairs, Run: (*AutoTrader).RunPairsCycle})
+}
+
+// InitializePairs restores the open pair from the database and sets leverage
+func (at *AutoTrader) InitializePairs() error {
+	strategyConfig := at.strategyConfig()
+	if strategyConfig == nil || strategyConfig.PairsConfig == nil {
+		return fmt.Errorf("pairs configuration not found")
+	}
+	pairsConfig := strategyConfig.PairsConfig
+	at.pairsState = &PairsState{Config: pairsConfig, PositionIDs: make(map[string]int64)}
+
+	if at.store != nil {
+		positions, err := at.store.Position().GetOpenPositions(at.id)
+		if err != nil {
+			return fmt.Errorf("failed to load open positions: %w", err)
+		}
+		// Legs ordered before OrderSync recorded them are linked again by linkPairsLegs
+		pending, err := at.store.Position().GetPendingLinks(at.id)
+		if err != nil {
+			return fmt.Errorf("failed to load pending legs: %w", err)
+		}
+		for _, pos := range append(positions, pending...) {
+			if pos.Source != pairsSource || pos.GroupID == "" {
+				continue
+			}
+			at.pairsState.GroupID = pos.GroupID
+			if pos.Status == "OPEN" {
+				at.pairsState.PositionIDs[pos.Symbol] = pos.ID
+			}
+		}
+		if at.pairsState.GroupID != "" {
+			logger.Infof("🔗 [Pairs] Restored pair %s (%d legs linked)", at.pairsState.GroupID, len(at.pairsState.PositionIDs))
+		}
+	}
+
+	for _, symbol := range []string{pairsConfig.SymbolA, pairsConfig.SymbolB} {
+		if err := at.trader.SetLeverage(symbol, pairsConfig.Leverage); err != nil {
+			logger.Warnf("[Pairs] Failed to set leverage %dx for %s on exchange: %v", pairsConfig.Leverage, symbol, err)
+		}
+	}
+
+	at.pairsState.IsInitialized = true
+	logger.Infof("🔗 [Pairs] Initialized: %s / %s, %d × %s bars, entry |z| %.2f, exit |z| %.2f",
+		pairsConfig.SymbolA, pairsConfig.SymbolB, kernel.PairsLookbackBars(pairsConfig), kernel.PairsTimeframe(pairsConfig),
+		pairsConfig.EntryZ, pairsConfig.ExitZ)
+	return nil
+}
+
+// RunPairsCycle executes one pairs trading cycle
+func (at *AutoTrader) RunPairsCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Pairs] Trader is stopped, aborting pairs cycle")
+		return nil
+	}
+
+	if at.pairsState == nil || !at.pairsState.IsInitialized {
+		if err := at.InitializePairs(); err != nil {
+			return fmt.Errorf("failed to initialize pairs: %w", err)
+		}
+	}
+
+	positions, err := at.pairsPositions()
+	if err != nil {
+		return err
+	}
+	at.linkPairsLegs(positions)
+
+	ctx := &kernel.Context{
+		TraderID:    at.id,
+		CurrentTime: time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
+		Positions:   positions,
+	}
+	decision, err := kernel.RunStrategy(at.runningStrategy().Strategy, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pairs decisions: %w", err)
+	}
+
+	// Entries come as two opens (leg A, then leg B) and are executed together
+	decisions := decision.Decisions
+	var actions []store.DecisionAction
+	for i := 0; i < len(decisions); i++ {
+		at.isRunningMutex.RLock()
+		running := at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			logger.Infof("[Pairs] Trader stopped, skipping remaining decisions")
+			break
+		}
+
+		d := &decisions[i]
+		switch d.Action {
+		case "open_long", "open_short":
+			if i+1 >= len(decisions) || !strings.HasPrefix(decisions[i+1].Action, "open_") {
+				logger.Warnf("[Pairs] %s %s without its second leg, skipped", d.Action, d.Symbol)
+				continue
+			}
+			i++
+			firstAction, secondAction := newStrategyAction(d), newStrategyAction(&decisions[i])
+			if err := at.openPairsLegs(d, &decisions[i], &firstAction, &secondAction); err != nil {
+				logger.Warnf("[Pairs] Failed to enter pair: %v", err)
+			}
+			actions = append(actions, firstAction, secondAction)
+		case "close_long", "close_short":
+			action := newStrategyAction(d)
+			var held float64
+			for _, pos := range positions {
+				if pos.Symbol == d.Symbol {
+					held = pos.Quantity
+				}
+			}
+			err := at.closePairsLeg(d, held, &action)
+			if err != nil {
+				logger.Warnf("[Pairs] Failed to close %s: %v", d.Symbol, err)
+			}
+			finishStrategyAction(&action, err)
+			actions = append(actions, action)
+		default:
+			logger.Warnf("[Pairs] Unknown action: %s", d.Action)
+		}
+	}
+
+	at.saveStrategyDecisionRecord("Pairs", decision, actions)
+	return nil
+}
+
+// pairsPositions returns the exchange positions of the two legs
+func (at *AutoTrader) pairsPositions() ([]kernel.PositionInfo, error) {
+	cfg := at.pairsState.Config
+	raw, err := at.trader.GetPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	var positions []kernel.PositionInfo
+	for _, pos := range raw {
+		symbol, _ := pos["symbol"].(string)
+		if symbol != cfg.SymbolA && symbol != cfg.SymbolB {
+			continue
+		}
+		size, _ := pos["positionAmt"].(float64)
+		if size == 0 {
+			continue
+		}
+		side, _ := pos["side"].(string)
+		entry, _ := pos["entryPrice"].(float64)
+		mark, _ := pos["markPrice"].(float64)
+		positions = append(positions, kernel.PositionInfo{
+			Symbol:     symbol,
+			Side:       side,
+			EntryPrice: entry,
+			MarkPrice:  mark,
+			Quantity:   math.Abs(size),
+			Leverage:   cfg.Leverage,
+		})
+	}
+	return positions, nil
+}
+
+// linkPairsLegs links the legs of the open pair once OrderSync has recorded them, and forgets
+// the pair when both legs are flat
+func (at *AutoTrader) linkPairsLegs(positions []kernel.PositionInfo) {
+	state := at.pairsState
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.GroupID == "" {
+		return
+	}
+	if len(positions) == 0 {
+		if at.store != nil {
+			if err := at.store.Position().DeletePendingLinks(at.id, state.GroupID); err != nil {
+				logger.Warnf("[Pairs] Failed to delete pending legs of %s: %v", state.GroupID, err)
+			}
+		}
+		state.GroupID = ""
+		state.PositionIDs = make(map[string]int64)
+		return
+	}
+	for _, pos := range positions {
+		if state.PositionIDs[pos.Symbol] > 0 {
+			continue
+		}
+		if id := at.recordPairsLeg(pos.Symbol, pos.Side, pos.Quantity, pos.EntryPrice, ""); id > 0 {
+			state.PositionIDs[pos.Symbol] = id
+		}
+	}
+}
+
+// recordPairsLeg links a leg to the open pair in trader_positions
+func (at *AutoTrader) recordPairsLeg(symbol, side string, quantity, price float64, orderID string) int64 {
+	return at.recordLinkedPosition(at.exchangeID, at.exchange, symbol, side, quantity, price,
+		at.pairsState.Config.Leverage, orderID, pairsSource, at.pairsState.GroupID)
+}
+
+// openPairsLegs opens both legs; the first leg is rolled back if the second fails. The outcome
+// of each leg is recorded in its action.
+func (at *AutoTrader) openPairsLegs(first, second *kernel.Decision, firstAction, secondAction *store.DecisionAction) error {
+	state := at.pairsState
+	state.mu.Lock()
+	if state.GroupID != "" {
+		state.mu.Unlock()
+		err := fmt.Errorf("pair %s is still open", state.GroupID)
+		finishStrategyAction(firstAction, err)
+		finishStrategyAction(secondAction, err)
+		return err
+	}
+	state.GroupID = fmt.Sprintf("pairs_%s_%s_%d", first.Symbol, second.Symbol, time.Now().UnixNano())
+	state.mu.Unlock()
+
+	firstFill, err := at.openPairsLeg(first)
+	if err != nil {
+		at.resetPairsState()
+		err = fmt.Errorf("first leg %s: %w", first.Symbol, err)
+		finishStrategyAction(firstAction, err)
+		finishStrategyAction(secondAction, fmt.Errorf("not sent: %w", err))
+		return err
+	}
+	setActionFill(firstAction, firstFill)
+	secondFill, err := at.openPairsLeg(second)
+	if err != nil {
+		err = fmt.Errorf("second leg %s: %w", second.Symbol, err)
+		closeAction := "close_long"
+		if first.Action == "open_short" {
+			closeAction = "close_short"
+		}
+		rollbackErr := fmt.Errorf("rolled back: %w", err)
+		if rbErr := at.closePairsLeg(&kernel.Decision{Symbol: first.Symbol, Action: closeAction}, firstFill.Quantity, nil); rbErr != nil {
+			logger.Errorf("❌ [Pairs] Failed to roll back first leg %s after second leg failure: %v", first.Symbol, rbErr)
+			rollbackErr = fmt.Errorf("roll back failed (%v) after: %w", rbErr, err)
+		} else {
+			logger.Infof("[Pairs] Rolled back first leg %s", first.Symbol)
+		}
+		at.resetPairsState()
+		finishStrategyAction(firstAction, rollbackErr)
+		finishStrategyAction(secondAction, err)
+		return err
+	}
+	setActionFill(secondAction, secondFill)
+	finishStrategyAction(firstAction, nil)
+	finishStrategyAction(secondAction, nil)
+
+	state.mu.Lock()
+	for _, leg := range []struct {
+		d    *kernel.Decision
+		fill orderFill
+	}{{first, firstFill}, {second, secondFill}} {
+		side := strings.TrimPrefix(leg.d.Action, "open_")
+		if id := at.recordPairsLeg(leg.d.Symbol, side, leg.fill.Quantity, leg.fill.Price, leg.fill.OrderID); id > 0 {
+			state.PositionIDs[leg.d.Symbol] = id
+		}
+	}
+	groupID := state.GroupID
+	state.mu.Unlock()
+
+	logger.Infof("🔗 [Pairs] Pair %s opened: %s %.6f %s, %s %.6f %s (%s)", groupID,
+		first.Action, firstFill.Quantity, first.Symbol, second.Action, secondFill.Quantity, second.Symbol, first.Reasoning)
+	return nil
+}
+
+// openPairsLeg opens one leg at market and returns its fill
+func (at *AutoTrader) openPairsLeg(d *kernel.Decision) (orderFill, error) {
+	price := d.EntryPrice
+	if current, err := at.trader.GetMarketPrice(d.Symbol); err == nil && current > 0 {
+		price = current
+	}
+	if price <= 0 {
+		return orderFill{}, fmt.Errorf("no price for %s", d.Symbol)
+	}
+	quantity := d.PositionSizeUSD / price
+
+	var order map[string]interface{}
+	var err error
+	if d.Action == "open_long" {
+		order, err = at.trader.OpenLong(d.Symbol, quantity, d.Leverage)
+	} else {
+		order, err = at.trader.OpenShort(d.Symbol, quantity, d.Leverage)
+	}
+	if err != nil {
+		return orderFill{}, err
+	}
+	fill := confirmOrderFill(at.trader, d.Symbol, order, quantity, price)
+	d.EntryPrice = fill.Price
+	return fill, nil
+}
+
+// closePairsLeg closes one leg of the held quantity at market (records are closed by OrderSync);
+// the fill is recorded in action if not nil
+func (at *AutoTrader) closePairsLeg(d *kernel.Decision, quantity float64, action *store.DecisionAction) error {
+	var order map[string]interface{}
+	var err error
+	if d.Action == "close_long" {
+		order, err = at.trader.CloseLong(d.Symbol, 0)
+	} else {
+		order, err = at.trader.CloseShort(d.Symbol, 0)
+	}
+	if err != nil {
+		return err
+	}
+	if action != nil {
+		price, _ := at.trader.GetMarketPrice(d.Symbol)
+		setActionFill(action, confirmOrderFill(at.trader, d.Symbol, order, quantity, price))
+	}
+	logger.Infof("🔗 [Pairs] Closed %s %s: %s", d.Symbol, strings.TrimPrefix(d.Action, "close_"), d.Reasoning)
+	return nil
+}
+
+// resetPairsState forgets the open pair
+func (at *AutoTrader) resetPairsState() {
+	at.pairsState.mu.Lock()
+	at.pairsState.GroupID = ""
+	at.pairsState.PositionIDs = make(map[string]int64)
+	at.pairsState.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// scriptedStrategy returns the next batch of decisions on each cycle (none once exhausted)
+type scriptedStrategy struct {
+	cycles [][]kernel.Decision
+}
+
+func (s *scriptedStrategy) Init() error  { return nil }
+func (s *scriptedStrategy) Close() error { return nil }
+func (s *scriptedStrategy) Decide(ctx *kernel.Context) ([]kernel.Decision, error) {
+	if len(s.cycles) == 0 {
+		return nil, nil
+	}
+	next := s.cycles[0]
+	s.cycles = s.cycles[1:]
+	return next, nil
+}
+
+func newPairsTrader(t *testing.T, st *store.Store, fake *fakeTrader, cycles ...[]kernel.Decision) *AutoTrader {
+	t.Helper()
+	at := &AutoTrader{
+		id: "pairs", exchangeID: "ex-a", exchange: "binance", trader: fake, store: st, isRunning: true,
+		strategy: &scriptedStrategy{cycles: cycles},
+		config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{
+			StrategyType: "pairs_trading",
+			PairsConfig:  &store.PairsStrategyConfig{SymbolA: "BTCUSDT", SymbolB: "ETHUSDT", Leverage: 2},
+		}},
+	}
+	if err := at.InitializePairs(); err != nil {
+		t.Fatal(err)
+	}
+	return at
+}
+
+func TestRunPairsCycleKeepsGroupAcrossRestart(t *testing.T) {
+	withFastFills(t)
+	st, err := store.New(filepath.Join(t.TempDir(), "pairs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 100000, "ETHUSDT": 2500})
+
+	at := newPairsTrader(t, st, fake, []kernel.Decision{
+		{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 1000, Leverage: 2},
+		{Symbol: "ETHUSDT", Action: "open_short", PositionSizeUSD: 1000, Leverage: 2},
+	})
+	if err := at.RunPairsCycle(); err != nil {
+		t.Fatal(err)
+	}
+	groupID := at.pairsState.GroupID
+	if groupID == "" || fake.positions["BTCUSDT long"] != 0.01 || fake.positions["ETHUSDT short"] != 0.4 {
+		t.Fatalf("both legs should be open under a group: %q, %v", groupID, fake.positions)
+	}
+	pending, err := st.Position().GetPendingLinks("pairs")
+	if err != nil || len(pending) != 2 || pending[0].GroupID != groupID {
+		t.Fatalf("the legs should be persisted as pending until OrderSync records them: %+v (%v)", pending, err)
+	}
+
+	// Restart before OrderSync ran: the group is restored from the pending legs
+	at = newPairsTrader(t, st, fake, []kernel.Decision{
+		{Symbol: "BTCUSDT", Action: "close_long"},
+		{Symbol: "ETHUSDT", Action: "close_short"},
+	})
+	if at.pairsState.GroupID != groupID {
+		t.Fatalf("restored group = %q, want %q", at.pairsState.GroupID, groupID)
+	}
+	// OrderSync records the BTC leg, which the next cycle links to the group
+	if err := st.Position().Create(&store.TraderPosition{TraderID: "pairs", ExchangeID: "ex-a", Symbol: "BTCUSDT",
+		Side: "LONG", Quantity: 0.01, EntryPrice: 100000}); err != nil {
+		t.Fatal(err)
+	}
+	if err := at.RunPairsCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if at.pairsState.PositionIDs["BTCUSDT"] == 0 {
+		t.Error("the synced BTC leg should be linked")
+	}
+	if pending, _ := st.Position().GetPendingLinks("pairs"); len(pending) != 1 || pending[0].Symbol != "ETHUSDT" {
+		t.Errorf("only the unsynced ETH leg should stay pending: %+v", pending)
+	}
+	records, _ := st.Decision().GetLatestRecords("pairs", 1)
+	if len(records) != 1 || len(records[0].Decisions) != 2 || records[0].Decisions[1].Quantity != 0.4 {
+		t.Fatalf("the close record should carry the closed quantities: %+v", records)
+	}
+
+	// Flat: the group and its pending leg are dropped
+	if err := at.RunPairsCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := st.Position().GetPendingLinks("pairs"); at.pairsState.GroupID != "" || len(pending) != 0 {
+		t.Errorf("a flat pair should forget its group: %q, %d pending", at.pairsState.GroupID, len(pending))
+	}
+}
+
+func TestOpenPairsLegsRollsBackFirstLeg(t *testing.T) {
+	withFastFills(t)
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 100000, "ETHUSDT": 2500})
+	fake.fail["open_short ETHUSDT"] = fmt.Errorf("symbol in reduce-only mode")
+	at := &AutoTrader{id: "pairs", trader: fake, pairsState: &PairsState{
+		Config:      &store.PairsStrategyConfig{SymbolA: "BTCUSDT", SymbolB: "ETHUSDT", Leverage: 2},
+		PositionIDs: make(map[string]int64),
+	}}
+
+	var first, second store.DecisionAction
+	err := at.openPairsLegs(
+		&kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 1000},
+		&kernel.Decision{Symbol: "ETHUSDT", Action: "open_short", PositionSizeUSD: 1000},
+		&first, &second)
+	if err == nil {
+		t.Fatal("the entry should fail with its second leg")
+	}
+
+	if got := strings.Join(fake.orders, ", "); got != "open_long BTCUSDT, close_long BTCUSDT" {
+		t.Errorf("the first leg should be closed again, orders: %s", got)
+	}
+	if fake.positions["BTCUSDT long"] != 0 || at.pairsState.GroupID != "" {
+		t.Errorf("no leg or group should remain: %v, %q", fake.positions, at.pairsState.GroupID)
+	}
+	if first.Success || !strings.Contains(first.Error, "rolled back") || first.Quantity != 0.01 {
+		t.Errorf("the first leg should be recorded as rolled back: %+v", first)
+	}
+	if second.Success || !strings.Contains(second.Error, "reduce-only") {
+		t.Errorf("the second leg should be recorded as failed: %+v", second)
+	}
+}
@@ -0,0 +1,332 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Rebalance State Management
+// ============================================================================
+
+// rebalanceBatchPause pause between two order batches (lets reductions free margin first)
+const rebalanceBatchPause = 2 * time.Second
+
+// RebalanceState holds the runtime state of a target-weight portfolio
+type RebalanceState struct {
+	mu sync.Mutex
+
+	// Configuration
+	Config *store.RebalanceStrategyConfig
+
+	// Targets and last rebalance, handed to the strategy every cycle
+	Context kernel.RebalanceContext
+
+	IsInitialized bool
+}
+
+// rebalanceOrderResult execution result of one rebalance order (stored in the rebalance history)
+type rebalanceOrderResult struct {
+	Symbol          string  `json:"symbol"`
+	Action          string  `json:"action"`
+	ClosePercentage float64 `json:"close_percentage,omitempty"`
+	Quantity        float64 `json:"quantity"`
+	Price           float64 `json:"price"`
+	NotionalUSD     float64 `json:"notional_usd"`
+	Success         bool    `json:"success"`
+	Error           string  `json:"error,omitempty"`
+
+	action store.DecisionAction // Decision record of the order
+}
+
+func init() {
+	RegisterStrategyCycle("rebalance", StrategyCycle{Name: "Rebalance", Init: (*AutoTrader).InitializeRebalance, Run: (*AutoTrader).RunRebalanceCycle})
+}
+
+// InitializeRebalance restores the targets of the last rebalance and sets leverage
+func (at *AutoTrader) InitializeRebalance() error {
+	strategyConfig := at.strategyConfig()
+	if strategyConfig == nil || strategyConfig.RebalanceConfig == nil {
+		return fmt.Errorf("rebalance configuration not found")
+	}
+	cfg := strategyConfig.RebalanceConfig
+	at.rebalanceState = &RebalanceState{Config: cfg}
+
+	if at.store != nil {
+		last, err := at.store.Rebalance().GetLatest(at.id)
+		if err != nil {
+			return fmt.Errorf("failed to load rebalance history: %w", err)
+		}
+		if last != nil {
+			at.rebalanceState.Context.LastRebalanceAt = last.Timestamp
+			if cfg.AITargets && last.AITargets {
+				var targets map[string]float64
+				if err := json.Unmarshal([]byte(last.TargetsJSON), &targets); err == nil {
+					at.rebalanceState.Context.Targets = targets
+					at.rebalanceState.Context.TargetsUpdatedAt = last.Timestamp
+				}
+			}
+			logger.Infof("⚖️ [Rebalance] Restored last rebalance at %s", last.Timestamp.Format("2006-01-02 15:04"))
+		}
+	}
+
+	for _, symbol := range kernel.RebalanceSymbols(cfg) {
+		if err := at.trader.SetLeverage(symbol, cfg.Leverage); err != nil {
+			logger.Warnf("[Rebalance] Failed to set leverage %dx for %s on exchange: %v", cfg.Leverage, symbol, err)
+		}
+	}
+
+	at.rebalanceState.IsInitialized = true
+	logger.Infof("⚖️ [Rebalance] Initialized: %d symbols, band %.2f pts, min notional %.2f USDT, AI targets %v",
+		len(cfg.Targets), kernel.RebalanceDriftBand(cfg), kernel.RebalanceMinNotional(cfg), cfg.AITargets)
+	return nil
+}
+
+// RunRebalanceCycle executes one rebalance cycle
+func (at *AutoTrader) RunRebalanceCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Rebalance] Trader is stopped, aborting rebalance cycle")
+		return nil
+	}
+
+	if at.rebalanceState == nil || !at.rebalanceState.IsInitialized {
+		if err := at.InitializeRebalance(); err != nil {
+			return fmt.Errorf("failed to initialize rebalance: %w", err)
+		}
+	}
+	state := at.rebalanceState
+	cfg := state.Config
+
+	equity, unrealized, marginUsed, raw, err := at.accountState()
+	if err != nil {
+		return err
+	}
+	positions := rebalancePositions(cfg, raw)
+
+	prices := make(map[string]float64, len(cfg.Targets))
+	for _, symbol := range kernel.RebalanceSymbols(cfg) {
+		price, err := at.trader.GetMarketPrice(symbol)
+		if err != nil || price <= 0 {
+			return fmt.Errorf("failed to get price of %s: %v", symbol, err)
+		}
+		prices[symbol] = price
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.Context.Prices = prices
+	state.Context.Now = time.Now().UTC()
+	state.Context.Plan = nil
+
+	ctx := &kernel.Context{
+		TraderID:    at.id,
+		CurrentTime: state.Context.Now.Format("2006-01-02 15:04:05 UTC"),
+		Account: kernel.AccountInfo{
+			TotalEquity:   equity,
+			UnrealizedPnL: unrealized,
+			MarginUsed:    marginUsed,
+			PositionCount: len(positions),
+		},
+		Positions: positions,
+		Rebalance: &state.Context,
+	}
+	decision, err := kernel.RunStrategy(at.runningStrategy().Strategy, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get rebalance decisions: %w", err)
+	}
+	plan := state.Context.Plan
+	if plan == nil || len(plan.Orders) == 0 {
+		return nil
+	}
+
+	results := at.executeRebalanceOrders(plan.Orders, positions, prices)
+	at.saveRebalanceRun(plan, results)
+	for _, r := range results {
+		if r.Success {
+			state.Context.LastRebalanceAt = state.Context.Now
+			break
+		}
+	}
+
+	actions := make([]store.DecisionAction, 0, len(results))
+	for _, r := range results {
+		actions = append(actions, r.action)
+	}
+	at.saveStrategyDecisionRecord("Rebalance", decision, actions)
+	return nil
+}
+
+// rebalancePositions converts the exchange positions of the basket symbols
+func rebalancePositions(cfg *store.RebalanceStrategyConfig, raw []map[string]interface{}) []kernel.PositionInfo {
+	var positions []kernel.PositionInfo
+	for _, pos := range raw {
+		symbol, _ := pos["symbol"].(string)
+		if _, ok := cfg.Targets[symbol]; !ok {
+			continue
+		}
+		size, _ := pos["positionAmt"].(float64)
+		if size == 0 {
+			continue
+		}
+		side, _ := pos["side"].(string)
+		entry, _ := pos["entryPrice"].(float64)
+		mark, _ := pos["markPrice"].(float64)
+		positions = append(positions, kernel.PositionInfo{
+			Symbol:     symbol,
+			Side:       side,
+			EntryPrice: entry,
+			MarkPrice:  mark,
+			Quantity:   math.Abs(size),
+			Leverage:   cfg.Leverage,
+		})
+	}
+	return positions
+}
+
+// executeRebalanceOrders sends the orders in batches, reductions before increases, and returns
+// the result of each order
+func (at *AutoTrader) executeRebalanceOrders(orders []kernel.Decision, positions []kernel.PositionInfo,
+	prices map[string]float64) []rebalanceOrderResult {
+	var reductions, increases []kernel.Decision
+	for _, d := range orders {
+		if strings.HasPrefix(d.Action, "close_") {
+			reductions = append(reductions, d)
+		} else {
+			increases = append(increases, d)
+		}
+	}
+
+	batchSize := kernel.RebalanceBatchSize(at.rebalanceState.Config)
+	results := make([]rebalanceOrderResult, 0, len(orders))
+	first := true
+	for _, group := range [][]kernel.Decision{reductions, increases} {
+		for start := 0; start < len(group); start += batchSize {
+			at.isRunningMutex.RLock()
+			running := at.isRunning
+			at.isRunningMutex.RUnlock()
+			if !running {
+				logger.Infof("[Rebalance] Trader stopped, skipping remaining orders")
+				return results
+			}
+			if !first {
+				time.Sleep(rebalanceBatchPause)
+			}
+			first = false
+
+			batch := group[start:min(start+batchSize, len(group))]
+			batchResults := make([]rebalanceOrderResult, len(batch))
+			var wg sync.WaitGroup
+			for i := range batch {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					batchResults[i] = at.executeRebalanceOrder(&batch[i], positions, prices[batch[i].Symbol])
+				}(i)
+			}
+			wg.Wait()
+			results = append(results, batchResults...)
+		}
+	}
+	return results
+}
+
+// executeRebalanceOrder sends one rebalance order at market
+func (at *AutoTrader) executeRebalanceOrder(d *kernel.Decision, positions []kernel.PositionInfo, price float64) rebalanceOrderResult {
+	result := rebalanceOrderResult{Symbol: d.Symbol, Action: d.Action, ClosePercentage: d.ClosePercentage, Price: price,
+		action: newStrategyAction(d)}
+	if price <= 0 {
+		result.Error = "no price"
+		finishStrategyAction(&result.action, fmt.Errorf("no price"))
+		return result
+	}
+
+	var order map[string]interface{}
+	var err error
+	switch d.Action {
+	case "open_long", "open_short":
+		result.Quantity = d.PositionSizeUSD / price
+		if d.Action == "open_long" {
+			order, err = at.trader.OpenLong(d.Symbol, result.Quantity, d.Leverage)
+		} else {
+			order, err = at.trader.OpenShort(d.Symbol, result.Quantity, d.Leverage)
+		}
+	case "close_long", "close_short":
+		side := strings.TrimPrefix(d.Action, "close_")
+		for _, pos := range positions {
+			if pos.Symbol == d.Symbol && pos.Side == side {
+				result.Quantity = pos.Quantity
+			}
+		}
+		// Quantity 0 closes the whole position
+		quantity := 0.0
+		if d.ClosePercentage > 0 && d.ClosePercentage < 1 {
+			quantity = result.Quantity * d.ClosePercentage
+			result.Quantity = quantity
+		}
+		if side == "long" {
+			order, err = at.trader.CloseLong(d.Symbol, quantity)
+		} else {
+			order, err = at.trader.CloseShort(d.Symbol, quantity)
+		}
+	default:
+		err = fmt.Errorf("unknown action: %s", d.Action)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		finishStrategyAction(&result.action, err)
+		logger.Warnf("[Rebalance] %s %s failed: %v", d.Action, d.Symbol, err)
+		return result
+	}
+
+	fill := confirmOrderFill(at.trader, d.Symbol, order, result.Quantity, price)
+	result.Quantity, result.Price = fill.Quantity, fill.Price
+	setActionFill(&result.action, fill)
+	finishStrategyAction(&result.action, nil)
+	result.Success = true
+	result.NotionalUSD = result.Quantity * price
+	logger.Infof("⚖️ [Rebalance] %s %.6f %s (%.2f USDT): %s", d.Action, result.Quantity, d.Symbol, result.NotionalUSD, d.Reasoning)
+	return result
+}
+
+// saveRebalanceRun stores the executed rebalance with its turnover
+func (at *AutoTrader) saveRebalanceRun(plan *kernel.RebalancePlan, results []rebalanceOrderResult) {
+	if at.store == nil {
+		return
+	}
+	run := &store.RebalanceRun{
+		TraderID:   at.id,
+		Timestamp:  at.rebalanceState.Context.Now,
+		Equity:     plan.Equity,
+		MaxDrift:   plan.MaxDrift,
+		OrderCount: len(results),
+		AITargets:  at.rebalanceState.Config.AITargets && at.rebalanceState.Context.Targets != nil,
+	}
+	for _, r := range results {
+		if r.Success {
+			run.TurnoverUSD += r.NotionalUSD
+		} else {
+			run.FailedCount++
+		}
+	}
+	if plan.Equity > 0 {
+		run.TurnoverPct = run.TurnoverUSD / plan.Equity * 100
+	}
+	targets, _ := json.Marshal(plan.Targets)
+	weights, _ := json.Marshal(plan.Weights)
+	orders, _ := json.Marshal(results)
+	run.TargetsJSON, run.WeightsJSON, run.OrdersJSON = string(targets), string(weights), string(orders)
+
+	if err := at.store.Rebalance().SaveRun(run); err != nil {
+		logger.Warnf("[Rebalance] Failed to save rebalance run: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+func TestRunRebalanceCycle(t *testing.T) {
+	withFastFills(t)
+	st, err := store.New(filepath.Join(t.TempDir(), "rebalance.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	cfg.StrategyType = "rebalance"
+	cfg.RebalanceConfig = &store.RebalanceStrategyConfig{Targets: map[string]float64{"BTCUSDT": 50, "ETHUSDT": -30}, Leverage: 2}
+	strategy, err := kernel.NewStrategy(kernel.StrategyEnv{Config: &cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 100000, "ETHUSDT": 2500})
+	fake.fail["open_short ETHUSDT"] = fmt.Errorf("leverage not set")
+	at := &AutoTrader{id: "rebalance", trader: fake, store: st, strategy: strategy, isRunning: true,
+		config: AutoTraderConfig{StrategyConfig: &cfg}}
+
+	// The BTC order fills, the ETH order is rejected
+	if err := at.RunRebalanceCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if fake.positions["BTCUSDT long"] <= 0 || fake.positions["ETHUSDT short"] != 0 {
+		t.Fatalf("only BTC should be bought: %v", fake.positions)
+	}
+	run, err := st.Rebalance().GetLatest("rebalance")
+	if err != nil || run == nil || run.OrderCount != 2 || run.FailedCount != 1 {
+		t.Fatalf("the run should count the failed order: %+v (%v)", run, err)
+	}
+	records, _ := st.Decision().GetLatestRecords("rebalance", 1)
+	if len(records) != 1 {
+		t.Fatal("expected a decision record of the rebalance")
+	}
+	for _, a := range records[0].Decisions {
+		if (a.Symbol == "BTCUSDT") != a.Success {
+			t.Errorf("only the BTC order succeeded: %+v", a)
+		}
+	}
+
+	// Next cycle: BTC is on target, only the ETH short is retried
+	delete(fake.fail, "open_short ETHUSDT")
+	fake.orders = nil
+	if err := at.RunRebalanceCycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.orders) != 1 || fake.orders[0] != "open_short ETHUSDT" || fake.positions["ETHUSDT short"] <= 0 {
+		t.Errorf("expected only the ETH short, orders %v", fake.orders)
+	}
+}
+
+func TestRebalanceOrderRecordsExecution(t *testing.T) {
+	withFastFills(t)
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 101, "ETHUSDT": 10})
+	fake.fail["open_long ETHUSDT"] = fmt.Errorf("insufficient margin")
+	at := &AutoTrader{trader: fake}
+
+	ok := at.executeRebalanceOrder(&kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 200, Leverage: 2}, nil, 100)
+	if !ok.Success || ok.Price != 101 || !ok.action.Success || ok.action.Price != 101 || ok.action.Quantity != 2 {
+		t.Errorf("the result should carry the fill at 101: %+v", ok)
+	}
+	failed := at.executeRebalanceOrder(&kernel.Decision{Symbol: "ETHUSDT", Action: "open_long", PositionSizeUSD: 100}, nil, 10)
+	if failed.Success || failed.action.Success || failed.action.Error != "insufficient margin" {
+		t.Errorf("the failed order should be recorded as failed: %+v", failed.action)
+	}
+}
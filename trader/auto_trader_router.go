@@ -0,0 +1,415 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"sync"
+)
+
+// ============================================================================
+// Regime Router
+// ============================================================================
+//
+// The router classifies the regime of one symbol every cycle (box breakouts and ADX for
+// trends, classifyRegimeLevel for the volatility of ranging markets) and runs the strategy
+// routed to it. A new regime takes over only after it was seen for ConfirmCycles consecutive
+// cycles; on a strategy switch open orders are cancelled and positions are closed or handed
+// over to the next strategy. Every confirmed regime change is stored in regime_switches.
+
+// regimeTimeframe timeframe of the regime indicators
+const regimeTimeframe = "1h"
+
+// regimeReading one regime classification of the router symbol
+type regimeReading struct {
+	Regime         market.RegimeLevel
+	BollingerWidth float64 // Bollinger band width as percentage of the middle band
+	ATRPct         float64 // ATR14 as percentage of price
+	ADX            float64
+	Breakout       market.BreakoutLevel
+	Direction      string
+}
+
+// classifyMarketRegime classifies the regime from a kline series and the box data: a mid or long
+// box breakout, or ADX at trendADX, is trending; otherwise the volatility level decides
+func classifyMarketRegime(series *market.TimeframeSeriesData, box *market.BoxData, trendADX float64) (*regimeReading, error) {
+	if series == nil || len(series.Klines) == 0 || len(series.BOLLMiddle) == 0 {
+		return nil, fmt.Errorf("no kline series")
+	}
+	n := len(series.BOLLMiddle) - 1
+	middle := series.BOLLMiddle[n]
+	price := series.Klines[len(series.Klines)-1].Close
+	if middle <= 0 || price <= 0 {
+		return nil, fmt.Errorf("not enough bars for bollinger bands")
+	}
+
+	reading := &regimeReading{
+		BollingerWidth: (series.BOLLUpper[n] - series.BOLLLower[n]) / middle * 100,
+		ATRPct:         series.ATR14 / price * 100,
+		Breakout:       market.BreakoutNone,
+	}
+	if len(series.ADXValues) > 0 {
+		reading.ADX = series.ADXValues[len(series.ADXValues)-1]
+	}
+	if box != nil {
+		reading.Breakout, reading.Direction = detectBoxBreakout(box)
+	}
+
+	switch {
+	case reading.Breakout == market.BreakoutMid || reading.Breakout == market.BreakoutLong:
+		reading.Regime = market.RegimeLevelTrending
+	case reading.ADX >= trendADX:
+		reading.Regime = market.RegimeLevelTrending
+	default:
+		reading.Regime = classifyRegimeLevel(reading.BollingerWidth, reading.ATRPct)
+	}
+	return reading, nil
+}
+
+// regimeHysteresis confirms a regime change only after it was observed for a number of
+// consecutive cycles. Regimes routed to the same strategy count as one candidate, so flickering
+// between e.g. narrow and standard does not hold back a switch away from trending.
+type regimeHysteresis struct {
+	Current        market.RegimeLevel
+	Candidate      market.RegimeLevel
+	CandidateRoute string
+	Count          int
+}
+
+// observe records one classification and returns true when it confirms a new regime
+func (h *regimeHysteresis) observe(regime market.RegimeLevel, route string, confirm int) bool {
+	if regime == h.Current {
+		h.Candidate, h.CandidateRoute, h.Count = "", "", 0
+		return false
+	}
+	if h.Candidate != "" && route == h.CandidateRoute {
+		h.Candidate = regime
+		h.Count++
+	} else {
+		h.Candidate, h.CandidateRoute, h.Count = regime, route, 1
+	}
+	if h.Count < confirm {
+		return false
+	}
+	h.Current = h.Candidate
+	h.Candidate, h.CandidateRoute, h.Count = "", "", 0
+	return true
+}
+
+// RegimeRouterState holds the runtime state of the regime router
+type RegimeRouterState struct {
+	mu sync.Mutex
+
+	// Configuration
+	Config *store.RegimeRouterConfig
+
+	Hysteresis regimeHysteresis
+
+	// Routed strategy; the trader's own fields keep the router strategy, cycles and the API read
+	// the routed one through AutoTrader.runningStrategy
+	ActiveStrategyID   string
+	ActiveStrategyName string
+	activeCycle        func() error
+	routed             *loadedStrategy
+
+	LastReading *regimeReading
+
+	IsInitialized bool
+}
+
+func init() {
+	RegisterStrategyCycle("regime_router", StrategyCycle{Name: "Regime router", Init: (*AutoTrader).InitializeRegimeRouter, Run: (*AutoTrader).RunRegimeRouterCycle})
+}
+
+// InitializeRegimeRouter restores the last regime (or classifies the current one) and activates
+// the strategy routed to it
+func (at *AutoTrader) InitializeRegimeRouter() error {
+	if routed := at.routedStrategy(); routed != nil {
+		// Restart: the previously routed strategy is loaded again
+		if err := routed.Strategy.Close(); err != nil {
+			logger.Warnf("[Router] Failed to close previous strategy: %v", err)
+		}
+	}
+	if at.config.StrategyConfig == nil || at.config.StrategyConfig.RegimeRouterConfig == nil {
+		return fmt.Errorf("regime router configuration not found")
+	}
+	if at.store == nil {
+		return fmt.Errorf("regime router requires a store to load routed strategies")
+	}
+	routerConfig := at.config.StrategyConfig.RegimeRouterConfig
+	state := &RegimeRouterState{Config: routerConfig}
+
+	// Restore the regime of the last switch so a restart does not unwind positions
+	last, err := at.store.Regime().LoadLastSwitch(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load last regime switch: %w", err)
+	}
+	if last != nil {
+		state.Hysteresis.Current = market.RegimeLevel(last.ToRegime)
+		logger.Infof("🧭 [Router] Restored regime %s from %s", last.ToRegime, last.CreatedAt.Format("2006-01-02 15:04:05"))
+	} else {
+		reading, err := at.readMarketRegime(routerConfig)
+		if err != nil {
+			logger.Warnf("[Router] Failed to classify regime of %s: %v, starting as %s", routerConfig.Symbol, err, market.RegimeLevelStandard)
+			reading = &regimeReading{Regime: market.RegimeLevelStandard, Breakout: market.BreakoutNone}
+		}
+		state.Hysteresis.Current = reading.Regime
+		state.LastReading = reading
+		at.saveRegimeSwitch(&store.RegimeSwitchModel{
+			ToRegime:     string(reading.Regime),
+			ToStrategyID: kernel.ResolveRegimeRoute(routerConfig, string(reading.Regime)),
+			Handover:     "none",
+			Reason:       "initial regime",
+		}, routerConfig, reading)
+	}
+
+	target := kernel.ResolveRegimeRoute(routerConfig, string(state.Hysteresis.Current))
+	routed, err := at.loadUserStrategy(target)
+	if err != nil {
+		return fmt.Errorf("failed to load strategy %s for regime %s: %w", target, state.Hysteresis.Current, err)
+	}
+	at.setRouterState(state)
+	if err := at.activateRoutedStrategy(routed); err != nil {
+		at.setRouterState(nil)
+		return err
+	}
+
+	state.IsInitialized = true
+	logger.Infof("🧭 [Router] Initialized on %s: regime %s → strategy %s (%s), confirm %d cycles, handover %s",
+		routerConfig.Symbol, state.Hysteresis.Current, routed.Name, routed.ID,
+		kernel.RegimeConfirmCycles(routerConfig), kernel.RegimeHandover(routerConfig))
+	return nil
+}
+
+// RunRegimeRouterCycle classifies the regime, switches strategy on a confirmed change and runs
+// one cycle of the routed strategy
+func (at *AutoTrader) RunRegimeRouterCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Router] Trader is stopped, aborting router cycle")
+		return nil
+	}
+
+	if at.routerState == nil || !at.routerState.IsInitialized {
+		if err := at.InitializeRegimeRouter(); err != nil {
+			return fmt.Errorf("failed to initialize regime router: %w", err)
+		}
+	}
+	state := at.routerState
+	cfg := state.Config
+
+	reading, err := at.readMarketRegime(cfg)
+	if err != nil {
+		// Keep the routed strategy running on the last confirmed regime
+		logger.Warnf("[Router] Failed to classify regime of %s: %v", cfg.Symbol, err)
+	} else {
+		state.mu.Lock()
+		from := state.Hysteresis.Current
+		state.LastReading = reading
+		confirmed := state.Hysteresis.observe(reading.Regime, kernel.ResolveRegimeRoute(cfg, string(reading.Regime)), kernel.RegimeConfirmCycles(cfg))
+		pending, count := state.Hysteresis.Candidate, state.Hysteresis.Count
+		state.mu.Unlock()
+
+		if confirmed {
+			at.switchRegime(from, reading)
+		} else if pending != "" {
+			logger.Infof("🧭 [Router] %s regime %s pending (%d/%d), staying %s",
+				cfg.Symbol, pending, count, kernel.RegimeConfirmCycles(cfg), from)
+		}
+	}
+
+	state.mu.Lock()
+	cycle := state.activeCycle
+	state.mu.Unlock()
+	return cycle()
+}
+
+// readMarketRegime classifies the current regime of the router symbol
+func (at *AutoTrader) readMarketRegime(cfg *store.RegimeRouterConfig) (*regimeReading, error) {
+	data, err := market.GetWithTimeframes(cfg.Symbol, []string{regimeTimeframe}, regimeTimeframe, 100, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market data: %w", err)
+	}
+	box, err := market.GetBoxData(cfg.Symbol)
+	if err != nil {
+		// Breakouts are one input; ADX and volatility still classify the regime
+		logger.Warnf("[Router] Failed to get box data of %s: %v", cfg.Symbol, err)
+		box = nil
+	}
+	return classifyMarketRegime(data.TimeframeData[regimeTimeframe], box, kernel.RegimeTrendADX(cfg))
+}
+
+// switchRegime hands over to the strategy of a confirmed regime and records the switch
+func (at *AutoTrader) switchRegime(from market.RegimeLevel, reading *regimeReading) {
+	state := at.routerState
+	cfg := state.Config
+	target := kernel.ResolveRegimeRoute(cfg, string(reading.Regime))
+	event := &store.RegimeSwitchModel{
+		FromRegime:     string(from),
+		ToRegime:       string(reading.Regime),
+		FromStrategyID: state.ActiveStrategyID,
+		ToStrategyID:   target,
+		Handover:       "none",
+	}
+
+	if target == state.ActiveStrategyID {
+		event.Reason = "same strategy"
+		logger.Infof("🧭 [Router] Regime %s → %s, strategy %s unchanged", from, reading.Regime, state.ActiveStrategyName)
+		at.saveRegimeSwitch(event, cfg, reading)
+		return
+	}
+
+	// Load the next strategy before touching positions so a bad route keeps the current one
+	routed, err := at.loadUserStrategy(target)
+	if err != nil {
+		event.ToStrategyID = state.ActiveStrategyID
+		event.Reason = fmt.Sprintf("failed to load strategy %s: %v", target, err)
+		logger.Errorf("❌ [Router] Regime %s → %s: %s, keeping %s", from, reading.Regime, event.Reason, state.ActiveStrategyName)
+		at.saveRegimeSwitch(event, cfg, reading)
+		// Stay on the previous regime so the switch is retried once confirmed again
+		state.mu.Lock()
+		state.Hysteresis.Current = from
+		state.mu.Unlock()
+		return
+	}
+
+	event.Handover = kernel.RegimeHandover(cfg)
+	event.ClosedCount = at.handoverRegimePositions(event.Handover)
+	if err := at.activateRoutedStrategy(routed); err != nil {
+		event.Reason = fmt.Sprintf("failed to start strategy %s: %v", target, err)
+		logger.Errorf("❌ [Router] Regime %s → %s: %s", from, reading.Regime, event.Reason)
+	} else {
+		event.Reason = fmt.Sprintf("switched to %s", routed.Name)
+		logger.Infof("🧭 [Router] Regime %s → %s: switched to %s (%s), handover %s, %d positions closed",
+			from, reading.Regime, routed.Name, routed.ID, event.Handover, event.ClosedCount)
+	}
+	at.saveRegimeSwitch(event, cfg, reading)
+}
+
+// handoverRegimePositions cancels open orders and, with handover "close", closes all positions.
+// Returns the number of positions closed.
+func (at *AutoTrader) handoverRegimePositions(handover string) int {
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		logger.Warnf("[Router] Failed to get positions for handover: %v", err)
+	}
+
+	symbols := []string{at.routerState.Config.Symbol}
+	if grid := at.strategyConfig().GridConfig; grid != nil {
+		symbols = append(symbols, kernel.GridSymbolList(grid)...)
+	}
+	for _, pos := range positions {
+		if symbol, _ := pos["symbol"].(string); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	cancelled := make(map[string]bool)
+	for _, symbol := range symbols {
+		if cancelled[symbol] {
+			continue
+		}
+		cancelled[symbol] = true
+		if err := at.trader.CancelAllOrders(symbol); err != nil {
+			logger.Warnf("[Router] Failed to cancel orders of %s: %v", symbol, err)
+		}
+	}
+
+	if handover != "close" {
+		return 0
+	}
+	closed := 0
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if size, _ := pos["positionAmt"].(float64); symbol == "" || size == 0 {
+			continue
+		}
+		var err error
+		if side == "short" {
+			_, err = at.trader.CloseShort(symbol, 0)
+		} else {
+			_, err = at.trader.CloseLong(symbol, 0)
+		}
+		if err != nil {
+			logger.Warnf("[Router] Failed to close %s %s: %v", symbol, side, err)
+			continue
+		}
+		closed++
+	}
+	return closed
+}
+
+// activateRoutedStrategy makes a routed strategy the running strategy and initializes its cycle;
+// on failure the previous routed strategy keeps running
+func (at *AutoTrader) activateRoutedStrategy(routed *loadedStrategy) error {
+	state := at.routerState
+	state.mu.Lock()
+	previous := state.routed
+	state.routed = routed
+	state.mu.Unlock()
+	at.gridState, at.dcaState, at.carryState, at.pairsState, at.rebalanceState = nil, nil, nil, nil, nil
+	at.setGridStates(nil)
+
+	cycle, _, err := at.selectStrategyCycle()
+	if err != nil {
+		// The previous strategy's executor state is restored on its next cycle
+		state.mu.Lock()
+		state.routed = previous
+		state.mu.Unlock()
+		if closeErr := routed.Strategy.Close(); closeErr != nil {
+			logger.Warnf("[Router] Failed to close strategy %s: %v", routed.Name, closeErr)
+		}
+		return err
+	}
+	if previous != nil {
+		if err := previous.Strategy.Close(); err != nil {
+			logger.Warnf("[Router] Failed to close previous strategy: %v", err)
+		}
+	}
+	state.mu.Lock()
+	state.ActiveStrategyID, state.ActiveStrategyName, state.activeCycle = routed.ID, routed.Name, cycle
+	state.mu.Unlock()
+	return nil
+}
+
+// setRouterState replaces the regime router state (read by the API through routedStrategy)
+func (at *AutoTrader) setRouterState(state *RegimeRouterState) {
+	at.stateMu.Lock()
+	at.routerState = state
+	at.stateMu.Unlock()
+}
+
+// routedStrategy returns the strategy routed by the regime router (nil without a router)
+func (at *AutoTrader) routedStrategy() *loadedStrategy {
+	at.stateMu.RLock()
+	state := at.routerState
+	at.stateMu.RUnlock()
+	if state == nil {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.routed
+}
+
+// saveRegimeSwitch stores a regime switch event with the indicators behind it
+func (at *AutoTrader) saveRegimeSwitch(event *store.RegimeSwitchModel, cfg *store.RegimeRouterConfig, reading *regimeReading) {
+	event.TraderID = at.id
+	event.Symbol = cfg.Symbol
+	if reading != nil {
+		event.BollingerWidth = reading.BollingerWidth
+		event.ATRPct = reading.ATRPct
+		event.ADX = reading.ADX
+		event.Breakout = string(reading.Breakout)
+		if reading.Direction != "" {
+			event.Breakout += "_" + reading.Direction
+		}
+	}
+	if err := at.store.Regime().SaveSwitch(event); err != nil {
+		logger.Warnf("[Router] Failed to save regime switch: %v", err)
+	}
+}
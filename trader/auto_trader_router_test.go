@@ -0,0 +1,169 @@
+package trader
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func routerTestSeries(bollWidthPct, atrPct, adx float64) *market.TimeframeSeriesData {
+	price := 100.0
+	return &market.TimeframeSeriesData{
+		Klines:     []market.KlineBar{{Close: price}},
+		BOLLUpper:  []float64{price * (1 + bollWidthPct/200)},
+		BOLLMiddle: []float64{price},
+		BOLLLower:  []float64{price * (1 - bollWidthPct/200)},
+		ATR14:      price * atrPct / 100,
+		ADXValues:  []float64{adx},
+	}
+}
+
+func TestClassifyMarketRegime(t *testing.T) {
+	inBox := &market.BoxData{
+		ShortUpper: 105, ShortLower: 95,
+		MidUpper: 110, MidLower: 90,
+		LongUpper: 120, LongLower: 80,
+		CurrentPrice: 100,
+	}
+	midBreakout := *inBox
+	midBreakout.CurrentPrice = 112
+
+	tests := []struct {
+		name   string
+		series *market.TimeframeSeriesData
+		box    *market.BoxData
+		want   market.RegimeLevel
+	}{
+		{"narrow range", routerTestSeries(1.5, 0.5, 15), inBox, market.RegimeLevelNarrow},
+		{"wide range", routerTestSeries(3.5, 2.5, 15), inBox, market.RegimeLevelWide},
+		{"volatile range", routerTestSeries(6, 4, 20), inBox, market.RegimeLevelVolatile},
+		{"strong ADX", routerTestSeries(2.5, 1.5, 32), inBox, market.RegimeLevelTrending},
+		{"mid box breakout", routerTestSeries(2.5, 1.5, 15), &midBreakout, market.RegimeLevelTrending},
+		{"no box data", routerTestSeries(2.5, 1.5, 15), nil, market.RegimeLevelStandard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reading, err := classifyMarketRegime(tt.series, tt.box, 25)
+			if err != nil {
+				t.Fatalf("classifyMarketRegime() error: %v", err)
+			}
+			if reading.Regime != tt.want {
+				t.Errorf("regime = %s, want %s (%+v)", reading.Regime, tt.want, reading)
+			}
+		})
+	}
+
+	if _, err := classifyMarketRegime(&market.TimeframeSeriesData{}, nil, 25); err == nil {
+		t.Error("empty series should fail")
+	}
+}
+
+func TestRegimeHysteresis(t *testing.T) {
+	h := regimeHysteresis{Current: market.RegimeLevelTrending}
+
+	// A single ranging reading between trending ones does not switch
+	if h.observe(market.RegimeLevelNarrow, "grid", 3) || h.observe(market.RegimeLevelTrending, "ai", 3) {
+		t.Fatal("switched before confirmation")
+	}
+	if h.Count != 0 {
+		t.Errorf("candidate not reset on return to current regime: %+v", h)
+	}
+
+	// Regimes with the same route confirm together; the latest one is taken
+	h.observe(market.RegimeLevelNarrow, "grid", 3)
+	h.observe(market.RegimeLevelStandard, "grid", 3)
+	if !h.observe(market.RegimeLevelStandard, "grid", 3) {
+		t.Fatalf("not switched after 3 ranging readings: %+v", h)
+	}
+	if h.Current != market.RegimeLevelStandard {
+		t.Errorf("current = %s, want standard", h.Current)
+	}
+
+	// A different route restarts the count
+	h.observe(market.RegimeLevelTrending, "ai", 2)
+	h.observe(market.RegimeLevelVolatile, "defensive", 2)
+	if h.Count != 1 || h.Candidate != market.RegimeLevelVolatile {
+		t.Errorf("candidate = %+v, want volatile with count 1", h)
+	}
+}
+
+func TestRegimeHandoverToRoutedStrategy(t *testing.T) {
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 100000, "ETHUSDT": 2500})
+	fake.positions["BTCUSDT long"] = 0.01
+	fake.positions["ETHUSDT short"] = 0.4
+	at := &AutoTrader{
+		trader: fake, strategy: &scriptedStrategy{},
+		config:         AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "rebalance"}},
+		routerState:    &RegimeRouterState{Config: &store.RegimeRouterConfig{Symbol: "BTCUSDT"}},
+		rebalanceState: &RebalanceState{IsInitialized: true},
+	}
+
+	if closed := at.handoverRegimePositions("close"); closed != 2 {
+		t.Errorf("closed = %d, want both positions", closed)
+	}
+	if fake.positions["BTCUSDT long"] != 0 || fake.positions["ETHUSDT short"] != 0 {
+		t.Errorf("positions should be flat after a close handover: %v", fake.positions)
+	}
+	if !slices.Contains(fake.orders, "cancel_all BTCUSDT") || !slices.Contains(fake.orders, "cancel_all ETHUSDT") {
+		t.Errorf("orders of the router symbol and held symbols should be cancelled: %v", fake.orders)
+	}
+
+	routed := &loadedStrategy{ID: "s-pairs", Name: "Pairs", Strategy: &scriptedStrategy{}, Config: &store.StrategyConfig{
+		StrategyType: "pairs_trading",
+		PairsConfig:  &store.PairsStrategyConfig{SymbolA: "BTCUSDT", SymbolB: "ETHUSDT", Leverage: 2},
+	}}
+	if err := at.activateRoutedStrategy(routed); err != nil {
+		t.Fatal(err)
+	}
+	if at.rebalanceState != nil || at.pairsState == nil || !at.pairsState.IsInitialized {
+		t.Error("the previous strategy's state should be dropped and the routed one initialized")
+	}
+	if at.routerState.ActiveStrategyID != "s-pairs" || at.routerState.activeCycle == nil {
+		t.Errorf("the routed cycle should be active: %+v", at.routerState)
+	}
+}
+
+func TestRoutedStrategyReadDuringSwitch(t *testing.T) {
+	at := &AutoTrader{
+		trader: newFakeTrader(map[string]float64{"BTCUSDT": 100000, "ETHUSDT": 2500}), strategy: &scriptedStrategy{},
+		config:      AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "regime_router"}},
+		routerState: &RegimeRouterState{Config: &store.RegimeRouterConfig{Symbol: "BTCUSDT"}},
+	}
+	routed := func(id string) *loadedStrategy {
+		return &loadedStrategy{ID: id, Name: id, Strategy: &scriptedStrategy{}, Config: &store.StrategyConfig{
+			StrategyType: "pairs_trading",
+			PairsConfig:  &store.PairsStrategyConfig{SymbolA: "BTCUSDT", SymbolB: "ETHUSDT", Leverage: 2},
+		}}
+	}
+
+	// The API reads the routed strategy while the cycle switches it (run with -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := at.activateRoutedStrategy(routed(fmt.Sprintf("s-%d", i))); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+			at.GetStatus()
+			at.GetGridRiskInfo()
+		}
+	}
+
+	if got := at.GetStatus()["strategy_type"]; got != "pairs_trading" {
+		t.Errorf("status strategy_type = %v, want the routed pairs_trading", got)
+	}
+	if at.config.StrategyConfig.StrategyType != "regime_router" {
+		t.Error("the trader's own strategy config should not be replaced")
+	}
+}
@@ -0,0 +1,515 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Shadow Strategies
+// ============================================================================
+//
+// Shadow strategies run next to the live strategy of a trader: after every live decision cycle
+// each shadow gets the same trading context (market data, candidates, prompts) with its own
+// simulated account, and its decisions are filled at live prices with fees in a paper book.
+// Nothing is sent to the exchange. Shadows run on the trader's decision cycle only (not inside
+// sleeves or self-executing strategies).
+
+// shadowBook the simulated account of a shadow strategy
+type shadowBook struct {
+	Shadow   *store.ShadowStrategy
+	Open     []*store.ShadowTrade
+	Realized float64 // Realized PnL of closed trades, net of fees
+}
+
+// shadowRunner a shadow strategy with its loaded decision maker and paper book
+type shadowRunner struct {
+	Strategy *loadedStrategy
+	Book     *shadowBook
+}
+
+// ShadowSet holds the shadow strategies of a trader
+type ShadowSet struct {
+	Runners       []*shadowRunner
+	IsInitialized bool
+}
+
+// InitializeShadows loads the enabled shadow strategies and their open simulated trades
+func (at *AutoTrader) InitializeShadows() error {
+	set := &ShadowSet{}
+	shadows, err := at.store.Shadow().ListEnabled(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load shadow strategies: %w", err)
+	}
+	for _, shadow := range shadows {
+		loaded, err := at.loadUserStrategy(shadow.StrategyID)
+		if err != nil {
+			logger.Warnf("[Shadow] %s skipped: %v", shadow.Name, err)
+			continue
+		}
+		if !DecisionStrategySupported(loaded.Config.StrategyType) {
+			loaded.Strategy.Close()
+			logger.Warnf("[Shadow] %s skipped: %s strategies cannot run in shadow", shadow.Name, loaded.Config.StrategyType)
+			continue
+		}
+		open, err := at.store.Shadow().GetOpenTrades(shadow.ID)
+		if err != nil {
+			return err
+		}
+		realized, err := at.store.Shadow().GetRealizedPnL(shadow.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load shadow PnL: %w", err)
+		}
+		set.Runners = append(set.Runners, &shadowRunner{
+			Strategy: loaded,
+			Book:     &shadowBook{Shadow: shadow, Open: open, Realized: realized},
+		})
+		logger.Infof("👻 [Shadow] %s: strategy %s, %d open trades, realized %.2f USDT", shadow.Name, loaded.Name, len(open), realized)
+	}
+	set.IsInitialized = true
+	at.shadowSet = set
+	return nil
+}
+
+// startShadowCycle runs the shadow strategies in the background so that their decision calls
+// never delay the live trader; a cycle is skipped while the previous one is still running
+func (at *AutoTrader) startShadowCycle(ctx *kernel.Context) {
+	if at.store == nil || at.activeSleeve() != nil {
+		return
+	}
+	if !at.shadowRunning.CompareAndSwap(false, true) {
+		logger.Warnf("[Shadow] Previous shadow cycle still running, skipping this one")
+		return
+	}
+	go func() {
+		defer at.shadowRunning.Store(false)
+		at.runShadowCycle(ctx)
+	}()
+}
+
+// runShadowCycle runs every shadow strategy on the context of the live cycle
+func (at *AutoTrader) runShadowCycle(ctx *kernel.Context) {
+	if at.shadowSet == nil || !at.shadowSet.IsInitialized {
+		if err := at.InitializeShadows(); err != nil {
+			logger.Warnf("[Shadow] %v", err)
+			return
+		}
+	}
+	for _, runner := range at.shadowSet.Runners {
+		at.isRunningMutex.RLock()
+		running := at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			return
+		}
+		at.runShadowStrategy(runner, ctx)
+	}
+}
+
+// runShadowStrategy runs one shadow decision cycle and simulates its decisions
+func (at *AutoTrader) runShadowStrategy(runner *shadowRunner, ctx *kernel.Context) {
+	book := runner.Book
+	now := time.Now().UTC()
+	record := &store.ShadowDecision{ShadowID: book.Shadow.ID, TraderID: at.id, Timestamp: now, Success: true}
+	var execLog []string
+
+	prices := at.shadowPrices(ctx, book)
+	for _, trade := range book.checkStops(prices, now.UnixMilli()) {
+		execLog = append(execLog, fmt.Sprintf("✓ %s %s closed by %s at %.4f", trade.Symbol, strings.ToLower(trade.Side), trade.CloseReason, trade.ExitPrice))
+		at.saveShadowTrade(trade)
+	}
+
+	shadowCtx := *ctx
+	shadowCtx.Account, shadowCtx.Positions = book.context(prices)
+	shadowCtx.StrategyConfig = runner.Strategy.Config
+	shadowCtx.TradingStats = nil
+	shadowCtx.RecentOrders = nil
+	shadowCtx.DecisionCalibration = nil
+	shadowCtx.CalibratedMinConfidence = 0
+
+	decision, err := kernel.RunStrategy(runner.Strategy.Strategy, &shadowCtx)
+	if decision != nil {
+		record.CoTTrace = decision.CoTTrace
+		if len(decision.Decisions) > 0 {
+			decisionJSON, _ := json.MarshalIndent(decision.Decisions, "", "  ")
+			record.DecisionJSON = string(decisionJSON)
+		}
+	}
+	if err != nil {
+		record.Success = false
+		record.ErrorMessage = err.Error()
+		logger.Warnf("[Shadow] %s decision failed: %v", book.Shadow.Name, err)
+	} else {
+		for _, d := range sortDecisionsByPriority(decision.Decisions) {
+			if prices[d.Symbol] <= 0 {
+				if data, err := market.GetWithExchange(d.Symbol, at.exchange); err == nil {
+					prices[d.Symbol] = data.CurrentPrice
+				}
+			}
+			trades, err := book.apply(&d, prices, now.UnixMilli())
+			if err != nil {
+				execLog = append(execLog, fmt.Sprintf("❌ %s %s failed: %v", d.Symbol, d.Action, err))
+				continue
+			}
+			for _, trade := range trades {
+				at.saveShadowTrade(trade)
+			}
+			if len(trades) > 0 {
+				execLog = append(execLog, fmt.Sprintf("✓ %s %s simulated at %.4f", d.Symbol, d.Action, prices[d.Symbol]))
+			}
+		}
+	}
+
+	logJSON, _ := json.Marshal(execLog)
+	record.ExecutionLog = string(logJSON)
+	if err := at.store.Shadow().SaveDecision(record); err != nil {
+		logger.Warnf("[Shadow] %v", err)
+	}
+
+	account, _ := book.context(prices)
+	snapshot := &store.ShadowEquitySnapshot{
+		ShadowID:      book.Shadow.ID,
+		Timestamp:     now,
+		TotalEquity:   account.TotalEquity,
+		UnrealizedPnL: account.UnrealizedPnL,
+		PositionCount: account.PositionCount,
+	}
+	if err := at.store.Shadow().SaveEquity(snapshot); err != nil {
+		logger.Warnf("[Shadow] %v", err)
+	}
+	logger.Infof("👻 [Shadow] %s: equity %.2f USDT, %d positions, %d actions", book.Shadow.Name, account.TotalEquity, account.PositionCount, len(execLog))
+}
+
+// saveShadowTrade persists a simulated trade
+func (at *AutoTrader) saveShadowTrade(trade *store.ShadowTrade) {
+	if err := at.store.Shadow().SaveTrade(trade); err != nil {
+		logger.Warnf("[Shadow] %v", err)
+	}
+}
+
+// shadowPrices returns the live prices of the cycle's symbols and the shadow's open trades
+func (at *AutoTrader) shadowPrices(ctx *kernel.Context, book *shadowBook) map[string]float64 {
+	prices := make(map[string]float64)
+	for symbol, data := range ctx.MarketDataMap {
+		if data != nil && data.CurrentPrice > 0 {
+			prices[symbol] = data.CurrentPrice
+		}
+	}
+	for _, trade := range book.Open {
+		if prices[trade.Symbol] > 0 {
+			continue
+		}
+		if data, err := market.GetWithExchange(trade.Symbol, at.exchange); err == nil {
+			prices[trade.Symbol] = data.CurrentPrice
+		}
+	}
+	return prices
+}
+
+// findOpen returns the open trade of a symbol ("" side = either side)
+func (b *shadowBook) findOpen(symbol, side string) *store.ShadowTrade {
+	for _, trade := range b.Open {
+		if trade.Symbol == symbol && (side == "" || trade.Side == side) {
+			return trade
+		}
+	}
+	return nil
+}
+
+// shadowUnrealized returns the unrealized PnL of an open trade at a price
+func shadowUnrealized(trade *store.ShadowTrade, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	if trade.Side == "SHORT" {
+		return (trade.EntryPrice - price) * trade.Quantity
+	}
+	return (price - trade.EntryPrice) * trade.Quantity
+}
+
+// context returns the simulated account and positions of the book
+func (b *shadowBook) context(prices map[string]float64) (kernel.AccountInfo, []kernel.PositionInfo) {
+	var unrealized, openFees, margin float64
+	positions := make([]kernel.PositionInfo, 0, len(b.Open))
+	for _, trade := range b.Open {
+		price := prices[trade.Symbol]
+		if price <= 0 {
+			price = trade.EntryPrice
+		}
+		pnl := shadowUnrealized(trade, price)
+		used := trade.Quantity * trade.EntryPrice / float64(max(trade.Leverage, 1))
+		unrealized += pnl
+		openFees += trade.Fee
+		margin += used
+		pos := kernel.PositionInfo{
+			Symbol:        trade.Symbol,
+			Side:          strings.ToLower(trade.Side),
+			EntryPrice:    trade.EntryPrice,
+			MarkPrice:     price,
+			Quantity:      trade.Quantity,
+			Leverage:      trade.Leverage,
+			StopLoss:      trade.StopLoss,
+			TakeProfit:    trade.TakeProfit,
+			UnrealizedPnL: pnl,
+			MarginUsed:    used,
+			UpdateTime:    trade.EntryTime,
+		}
+		if used > 0 {
+			pos.UnrealizedPnLPct = pnl / used * 100
+		}
+		positions = append(positions, pos)
+	}
+
+	equity := b.Shadow.InitialBalance + b.Realized - openFees + unrealized
+	account := kernel.AccountInfo{
+		TotalEquity:      equity,
+		AvailableBalance: math.Max(0, equity-margin),
+		UnrealizedPnL:    unrealized,
+		TotalPnL:         equity - b.Shadow.InitialBalance,
+		MarginUsed:       margin,
+		PositionCount:    len(positions),
+	}
+	if b.Shadow.InitialBalance > 0 {
+		account.TotalPnLPct = account.TotalPnL / b.Shadow.InitialBalance * 100
+	}
+	if equity > 0 {
+		account.MarginUsedPct = margin / equity * 100
+	}
+	return account, positions
+}
+
+// apply simulates a decision at the live prices and returns the trades it changed (none = nothing to do)
+func (b *shadowBook) apply(d *kernel.Decision, prices map[string]float64, nowMs int64) ([]*store.ShadowTrade, error) {
+	price := prices[d.Symbol]
+	switch d.Action {
+	case "open_long", "open_short":
+		if price <= 0 {
+			return nil, fmt.Errorf("no price")
+		}
+		side := strings.ToUpper(strings.TrimPrefix(d.Action, "open_"))
+		if existing := b.findOpen(d.Symbol, ""); existing != nil && existing.Side != side {
+			return nil, fmt.Errorf("%s already has a %s position", d.Symbol, strings.ToLower(existing.Side))
+		}
+		leverage := max(d.Leverage, 1)
+		account, _ := b.context(prices)
+		size := math.Min(d.PositionSizeUSD, account.AvailableBalance*float64(leverage))
+		if size <= 0 {
+			return nil, fmt.Errorf("insufficient simulated balance")
+		}
+		quantity := size / price
+		fee := size * b.Shadow.Fee()
+
+		if existing := b.findOpen(d.Symbol, side); existing != nil {
+			// Pyramiding: average into the open trade
+			total := existing.Quantity + quantity
+			existing.EntryPrice = (existing.EntryPrice*existing.Quantity + price*quantity) / total
+			existing.Quantity = total
+			existing.Fee += fee
+			existing.Leverage = leverage
+			if d.StopLoss > 0 {
+				existing.StopLoss = d.StopLoss
+			}
+			if d.TakeProfit > 0 {
+				existing.TakeProfit = d.TakeProfit
+			}
+			return []*store.ShadowTrade{existing}, nil
+		}
+		trade := &store.ShadowTrade{
+			ShadowID:   b.Shadow.ID,
+			TraderID:   b.Shadow.TraderID,
+			Symbol:     d.Symbol,
+			Side:       side,
+			Quantity:   quantity,
+			Leverage:   leverage,
+			EntryPrice: price,
+			EntryTime:  nowMs,
+			StopLoss:   d.StopLoss,
+			TakeProfit: d.TakeProfit,
+			Fee:        fee,
+			Status:     "OPEN",
+		}
+		b.Open = append(b.Open, trade)
+		return []*store.ShadowTrade{trade}, nil
+
+	case "close_long", "close_short", "partial_close", "PARTIAL_CLOSE":
+		side := ""
+		if strings.HasPrefix(d.Action, "close_") {
+			side = strings.ToUpper(strings.TrimPrefix(d.Action, "close_"))
+		}
+		trade := b.findOpen(d.Symbol, side)
+		if trade == nil {
+			return nil, fmt.Errorf("no simulated position")
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("no price")
+		}
+		if d.Action == "partial_close" || d.Action == "PARTIAL_CLOSE" {
+			if d.ClosePercentage <= 0 || d.ClosePercentage > 1 {
+				return nil, fmt.Errorf("invalid close percentage: %.2f", d.ClosePercentage)
+			}
+			if d.ClosePercentage < 1 {
+				// The closed share becomes its own closed trade; stops move on the rest
+				closed := b.split(trade, d.ClosePercentage)
+				b.close(closed, price, "strategy", nowMs)
+				if d.StopLoss > 0 {
+					trade.StopLoss = d.StopLoss
+				}
+				if d.TakeProfit > 0 {
+					trade.TakeProfit = d.TakeProfit
+				}
+				return []*store.ShadowTrade{trade, closed}, nil
+			}
+		}
+		b.close(trade, price, "strategy", nowMs)
+		return []*store.ShadowTrade{trade}, nil
+
+	case "hold", "wait":
+		trade := b.findOpen(d.Symbol, "")
+		if trade == nil || (d.StopLoss <= 0 && d.TakeProfit <= 0) {
+			return nil, nil
+		}
+		if d.StopLoss > 0 {
+			trade.StopLoss = d.StopLoss
+		}
+		if d.TakeProfit > 0 {
+			trade.TakeProfit = d.TakeProfit
+		}
+		return []*store.ShadowTrade{trade}, nil
+	}
+	return nil, fmt.Errorf("unsupported action: %s", d.Action)
+}
+
+// split takes a share of an open trade (with its share of the entry fee) out as a new trade
+func (b *shadowBook) split(trade *store.ShadowTrade, share float64) *store.ShadowTrade {
+	part := *trade
+	part.ID = 0
+	part.Quantity = trade.Quantity * share
+	part.Fee = trade.Fee * share
+	trade.Quantity -= part.Quantity
+	trade.Fee -= part.Fee
+	return &part
+}
+
+// close closes an open trade at a price
+func (b *shadowBook) close(trade *store.ShadowTrade, price float64, reason string, nowMs int64) {
+	trade.ExitPrice = price
+	trade.ExitTime = nowMs
+	trade.RealizedPnL = shadowUnrealized(trade, price)
+	trade.Fee += trade.Quantity * price * b.Shadow.Fee()
+	trade.Status = "CLOSED"
+	trade.CloseReason = reason
+	b.Realized += trade.RealizedPnL - trade.Fee
+
+	for i, open := range b.Open {
+		if open == trade {
+			b.Open = append(b.Open[:i], b.Open[i+1:]...)
+			break
+		}
+	}
+}
+
+// checkStops closes the open trades whose stop loss or take profit was crossed (filled at the
+// trigger price) and returns them
+func (b *shadowBook) checkStops(prices map[string]float64, nowMs int64) []*store.ShadowTrade {
+	var closed []*store.ShadowTrade
+	for _, trade := range append([]*store.ShadowTrade(nil), b.Open...) {
+		price := prices[trade.Symbol]
+		if price <= 0 {
+			continue
+		}
+		long := trade.Side == "LONG"
+		switch {
+		case trade.StopLoss > 0 && (long && price <= trade.StopLoss || !long && price >= trade.StopLoss):
+			b.close(trade, trade.StopLoss, "stop_loss", nowMs)
+		case trade.TakeProfit > 0 && (long && price >= trade.TakeProfit || !long && price <= trade.TakeProfit):
+			b.close(trade, trade.TakeProfit, "take_profit", nowMs)
+		default:
+			continue
+		}
+		closed = append(closed, trade)
+	}
+	return closed
+}
+
+// ============================================================================
+// Shadow vs Live Comparison
+// ============================================================================
+
+// ShadowPerformance performance of the live trader or a shadow strategy over a period
+type ShadowPerformance struct {
+	StartEquity float64 `json:"start_equity"`
+	EndEquity   float64 `json:"end_equity"`
+	ReturnPct   float64 `json:"return_pct"`
+	TradeCount  int     `json:"trade_count"` // Closed trades
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"win_rate"` // %
+	NetPnL      float64 `json:"net_pnl"`  // Realized PnL of closed trades, net of fees
+	Fees        float64 `json:"fees"`
+}
+
+// ShadowComparison live vs shadow performance over the same period
+type ShadowComparison struct {
+	Start          time.Time         `json:"start"`
+	End            time.Time         `json:"end"`
+	Live           ShadowPerformance `json:"live"`
+	Shadow         ShadowPerformance `json:"shadow"`
+	ReturnDiffPct  float64           `json:"return_diff_pct"` // Shadow − live return
+	WinRateDiffPct float64           `json:"win_rate_diff_pct"`
+	TradeCountDiff int               `json:"trade_count_diff"`
+}
+
+// CompareShadow compares the live trader with a shadow strategy (curves ascending, trades closed
+// within the period)
+func CompareShadow(start, end time.Time, liveCurve []*store.EquitySnapshot, liveTrades []*store.TraderPosition,
+	shadowCurve []*store.ShadowEquitySnapshot, shadowTrades []*store.ShadowTrade) *ShadowComparison {
+	cmp := &ShadowComparison{Start: start, End: end}
+
+	if n := len(liveCurve); n > 0 {
+		cmp.Live.StartEquity = liveCurve[0].TotalEquity
+		cmp.Live.EndEquity = liveCurve[n-1].TotalEquity
+	}
+	for _, pos := range liveTrades {
+		net := pos.RealizedPnL - pos.Fee
+		cmp.Live.addTrade(net, pos.Fee)
+	}
+	cmp.Live.finish()
+
+	if n := len(shadowCurve); n > 0 {
+		cmp.Shadow.StartEquity = shadowCurve[0].TotalEquity
+		cmp.Shadow.EndEquity = shadowCurve[n-1].TotalEquity
+	}
+	for _, trade := range shadowTrades {
+		cmp.Shadow.addTrade(trade.RealizedPnL-trade.Fee, trade.Fee)
+	}
+	cmp.Shadow.finish()
+
+	cmp.ReturnDiffPct = cmp.Shadow.ReturnPct - cmp.Live.ReturnPct
+	cmp.WinRateDiffPct = cmp.Shadow.WinRate - cmp.Live.WinRate
+	cmp.TradeCountDiff = cmp.Shadow.TradeCount - cmp.Live.TradeCount
+	return cmp
+}
+
+func (p *ShadowPerformance) addTrade(net, fee float64) {
+	p.TradeCount++
+	if net > 0 {
+		p.Wins++
+	}
+	p.NetPnL += net
+	p.Fees += fee
+}
+
+func (p *ShadowPerformance) finish() {
+	if p.StartEquity > 0 {
+		p.ReturnPct = (p.EndEquity/p.StartEquity - 1) * 100
+	}
+	if p.TradeCount > 0 {
+		p.WinRate = float64(p.Wins) / float64(p.TradeCount) * 100
+	}
+}
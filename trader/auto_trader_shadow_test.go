@@ -0,0 +1,88 @@
+package trader
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+func TestShadowBook(t *testing.T) {
+	book := &shadowBook{Shadow: &store.ShadowStrategy{ID: "s1", InitialBalance: 1000, FeeRate: 0.001}}
+	prices := map[string]float64{"BTCUSDT": 100}
+
+	open := &kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", Leverage: 5, PositionSizeUSD: 2000, StopLoss: 90}
+	trades, err := book.apply(open, prices, 1)
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("open failed: %v", err)
+	}
+	trade := trades[0]
+	if trade.Quantity != 20 || math.Abs(trade.Fee-2) > 1e-9 {
+		t.Errorf("open = %.4f @ fee %.4f, want 20 @ 2", trade.Quantity, trade.Fee)
+	}
+	if _, err := book.apply(&kernel.Decision{Symbol: "BTCUSDT", Action: "open_short", PositionSizeUSD: 100}, prices, 2); err == nil {
+		t.Error("opposite side open should fail")
+	}
+
+	// +10% on 2000 notional, entry fee 2
+	prices["BTCUSDT"] = 110
+	account, positions := book.context(prices)
+	if math.Abs(account.TotalEquity-1198) > 1e-9 || len(positions) != 1 || positions[0].Side != "long" {
+		t.Errorf("equity = %.4f positions = %+v, want 1198 and one long", account.TotalEquity, positions)
+	}
+	if math.Abs(account.MarginUsed-400) > 1e-9 {
+		t.Errorf("margin = %.4f, want 400", account.MarginUsed)
+	}
+
+	// Closing a quarter books its share of the profit and of the entry fee
+	trades, err = book.apply(&kernel.Decision{Symbol: "BTCUSDT", Action: "partial_close", ClosePercentage: 0.25, StopLoss: 100}, prices, 3)
+	if err != nil || len(trades) != 2 {
+		t.Fatalf("partial close = %d trades, %v; want the rest and the closed part", len(trades), err)
+	}
+	part := trades[1]
+	if part.Status != "CLOSED" || part.Quantity != 5 || trade.Quantity != 15 || trade.StopLoss != 100 {
+		t.Errorf("partial close left %.4f open (stop %.2f) and closed %+v, want 15 (stop 100) and 5", trade.Quantity, trade.StopLoss, part)
+	}
+	// 50 profit − 0.5 entry fee − 0.55 exit fee
+	if len(book.Open) != 1 || math.Abs(book.Realized-48.95) > 1e-9 {
+		t.Errorf("realized = %.4f with %d open, want 48.95 and one", book.Realized, len(book.Open))
+	}
+	if _, err := book.apply(&kernel.Decision{Symbol: "BTCUSDT", Action: "partial_close"}, prices, 3); err == nil {
+		t.Error("a partial close without percentage should fail")
+	}
+
+	if _, err := book.apply(&kernel.Decision{Symbol: "BTCUSDT", Action: "close_long"}, prices, 3); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	// 200 profit − 2 entry fee − 2.2 exit fee
+	if len(book.Open) != 0 || math.Abs(book.Realized-195.8) > 1e-9 {
+		t.Errorf("realized = %.4f with %d open, want 195.8 and none", book.Realized, len(book.Open))
+	}
+
+	// Stop loss fills at the trigger price
+	book.apply(&kernel.Decision{Symbol: "BTCUSDT", Action: "open_short", Leverage: 2, PositionSizeUSD: 110, StopLoss: 120}, prices, 4)
+	closed := book.checkStops(map[string]float64{"BTCUSDT": 125}, 5)
+	if len(closed) != 1 || closed[0].ExitPrice != 120 || closed[0].CloseReason != "stop_loss" {
+		t.Errorf("stopped = %+v, want short closed at 120 by stop_loss", closed)
+	}
+}
+
+func TestCompareShadow(t *testing.T) {
+	now := time.Now()
+	liveCurve := []*store.EquitySnapshot{{Timestamp: now, TotalEquity: 1000}, {Timestamp: now.Add(time.Hour), TotalEquity: 1050}}
+	shadowCurve := []*store.ShadowEquitySnapshot{{Timestamp: now, TotalEquity: 1000}, {Timestamp: now.Add(time.Hour), TotalEquity: 1100}}
+	liveTrades := []*store.TraderPosition{{RealizedPnL: 60, Fee: 1}, {RealizedPnL: -5, Fee: 1}}
+	shadowTrades := []*store.ShadowTrade{{RealizedPnL: 50, Fee: 1}, {RealizedPnL: 30, Fee: 1}, {RealizedPnL: 0.5, Fee: 1}}
+
+	cmp := CompareShadow(now, now.Add(time.Hour), liveCurve, liveTrades, shadowCurve, shadowTrades)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if !near(cmp.Live.ReturnPct, 5) || !near(cmp.Shadow.ReturnPct, 10) || !near(cmp.ReturnDiffPct, 5) {
+		t.Errorf("returns = %.2f / %.2f (diff %.2f), want 5 / 10 (5)", cmp.Live.ReturnPct, cmp.Shadow.ReturnPct, cmp.ReturnDiffPct)
+	}
+	// A trade whose fee exceeds its profit is a loss
+	if cmp.Live.WinRate != 50 || !near(cmp.Shadow.WinRate, 200.0/3) || cmp.TradeCountDiff != 1 {
+		t.Errorf("win rates = %.2f / %.2f, trade diff %d", cmp.Live.WinRate, cmp.Shadow.WinRate, cmp.TradeCountDiff)
+	}
+}
@@ -0,0 +1,493 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Strategy Sleeves
+// ============================================================================
+//
+// A trader with sleeves runs several strategies on one exchange account. Every sleeve gets its
+// own capital (fixed USDT or % of the trader's equity) and only sees and trades the positions it
+// owns; the trader's own strategy supplies the account-wide risk control, so the combined margin
+// of all sleeves stays within its MaxMarginUsage.
+
+// defaultMaxMarginUsage account margin cap when the trader's strategy does not set one
+const defaultMaxMarginUsage = 0.9
+
+// DecisionStrategySupported returns true if a strategy type only makes decisions (it can then run
+// as a sleeve or in shadow). Types with a registered StrategyCycle manage their own orders and
+// state on the whole account.
+func DecisionStrategySupported(strategyType string) bool {
+	strategyCyclesMu.RLock()
+	defer strategyCyclesMu.RUnlock()
+	_, selfExecuting := strategyCycles[strategyType]
+	return !selfExecuting
+}
+
+// sleeveRunner a sleeve with its loaded strategy
+type sleeveRunner struct {
+	Sleeve   *store.TraderSleeve
+	Strategy *loadedStrategy
+	Capital  float64 // Capital of the current cycle
+}
+
+// SleeveSet holds the runtime state of a trader's sleeves
+type SleeveSet struct {
+	mu sync.Mutex
+
+	Sleeves []*sleeveRunner
+
+	// Owner sleeve of each open position (symbol_side → sleeve ID)
+	Owners map[string]string
+	// Positions opened by a sleeve that are not attributed in trader_positions yet
+	pending map[string]string
+
+	// Sleeve of the running cycle (nil between sleeve cycles); its strategy is the running
+	// strategy (AutoTrader.runningStrategy), the trader's own supplies the account-wide risk control
+	active *sleeveRunner
+
+	IsInitialized bool
+}
+
+// sleevePositionKey key of a position in SleeveSet.Owners
+func sleevePositionKey(symbol, side string) string {
+	return symbol + "_" + strings.ToLower(side)
+}
+
+// HasSleeves returns true if the trader runs strategy sleeves
+func (at *AutoTrader) HasSleeves() bool {
+	if at.currentSleeveSet() != nil {
+		return true
+	}
+	if at.store == nil {
+		return false
+	}
+	sleeves, err := at.store.Sleeve().ListEnabled(at.id)
+	if err != nil {
+		logger.Warnf("[Sleeves] Failed to load sleeves: %v", err)
+		return false
+	}
+	return len(sleeves) > 0
+}
+
+// InitializeSleeves loads the enabled sleeves and restores position ownership
+func (at *AutoTrader) InitializeSleeves() error {
+	if at.store == nil {
+		return fmt.Errorf("sleeves require a store")
+	}
+	if at.sleeveSet != nil {
+		for _, r := range at.sleeveSet.Sleeves {
+			r.Strategy.Strategy.Close()
+		}
+	}
+
+	set := &SleeveSet{
+		Owners:  make(map[string]string),
+		pending: make(map[string]string),
+	}
+	sleeves, err := at.store.Sleeve().ListEnabled(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load sleeves: %w", err)
+	}
+	for _, sleeve := range sleeves {
+		loaded, err := at.loadUserStrategy(sleeve.StrategyID)
+		if err != nil {
+			logger.Warnf("[Sleeves] Sleeve %s skipped: %v", sleeve.Name, err)
+			continue
+		}
+		if !DecisionStrategySupported(loaded.Config.StrategyType) {
+			loaded.Strategy.Close()
+			logger.Warnf("[Sleeves] Sleeve %s skipped: strategy type %s cannot run as a sleeve", sleeve.Name, loaded.Config.StrategyType)
+			continue
+		}
+		set.Sleeves = append(set.Sleeves, &sleeveRunner{Sleeve: sleeve, Strategy: loaded})
+	}
+	if len(set.Sleeves) == 0 {
+		return fmt.Errorf("no runnable sleeves")
+	}
+
+	positions, err := at.store.Position().GetOpenPositions(at.id)
+	if err != nil {
+		return fmt.Errorf("failed to load open positions: %w", err)
+	}
+	for _, pos := range positions {
+		if pos.SleeveID != "" {
+			set.Owners[sleevePositionKey(pos.Symbol, pos.Side)] = pos.SleeveID
+		}
+	}
+
+	set.IsInitialized = true
+	at.stateMu.Lock()
+	at.sleeveSet = set
+	at.stateMu.Unlock()
+	for _, r := range set.Sleeves {
+		logger.Infof("🧩 [Sleeves] %s: strategy %s (%s), allocation %s", r.Sleeve.Name, r.Strategy.Name,
+			r.Strategy.Config.StrategyType, describeSleeveAllocation(r.Sleeve))
+	}
+	logger.Infof("🧩 [Sleeves] Initialized %d sleeves, %d owned positions restored", len(set.Sleeves), len(set.Owners))
+	return nil
+}
+
+// describeSleeveAllocation formats the allocation of a sleeve for logs
+func describeSleeveAllocation(sleeve *store.TraderSleeve) string {
+	if sleeve.AllocationType == store.SleeveAllocationUSD {
+		return fmt.Sprintf("%.2f USDT", sleeve.Allocation)
+	}
+	return fmt.Sprintf("%.1f%% of equity", sleeve.Allocation)
+}
+
+// RunSleevesCycle runs one decision cycle of every sleeve
+func (at *AutoTrader) RunSleevesCycle() error {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		logger.Infof("[Sleeves] Trader is stopped, aborting sleeves cycle")
+		return nil
+	}
+
+	if at.sleeveSet == nil || !at.sleeveSet.IsInitialized {
+		if err := at.InitializeSleeves(); err != nil {
+			return fmt.Errorf("failed to initialize sleeves: %w", err)
+		}
+	}
+	set := at.sleeveSet
+	at.attributeSleevePositions()
+
+	// Percentages are of the trader's equity baseline so a sleeve's PnL does not move its own allocation
+	base := at.initialBalance
+	if base <= 0 {
+		if equity, _, _, _, err := at.accountState(); err == nil {
+			base = equity
+		}
+	}
+
+	defer at.endSleeveCycle()
+	for _, r := range set.Sleeves {
+		at.isRunningMutex.RLock()
+		running := at.isRunning
+		at.isRunningMutex.RUnlock()
+		if !running {
+			break
+		}
+
+		r.Capital = r.Sleeve.Capital(base)
+		set.mu.Lock()
+		set.active = r
+		set.mu.Unlock()
+
+		logger.Infof("🧩 [Sleeves] Running sleeve %s (%s, capital %.2f USDT)", r.Sleeve.Name, r.Strategy.Name, r.Capital)
+		if err := at.runCycle(); err != nil {
+			logger.Warnf("[Sleeves] Sleeve %s cycle failed: %v", r.Sleeve.Name, err)
+		}
+	}
+	at.endSleeveCycle()
+
+	at.saveAccountEquitySnapshot()
+	return nil
+}
+
+// endSleeveCycle makes the trader's own strategy the running strategy again after a sleeve cycle
+func (at *AutoTrader) endSleeveCycle() {
+	set := at.sleeveSet
+	set.mu.Lock()
+	set.active = nil
+	set.mu.Unlock()
+}
+
+// currentSleeveSet returns the trader's sleeves (nil without sleeves; safe to call from the API)
+func (at *AutoTrader) currentSleeveSet() *SleeveSet {
+	at.stateMu.RLock()
+	defer at.stateMu.RUnlock()
+	return at.sleeveSet
+}
+
+// activeSleeve returns the sleeve of the running cycle (nil outside sleeve cycles)
+func (at *AutoTrader) activeSleeve() *sleeveRunner {
+	set := at.currentSleeveSet()
+	if set == nil {
+		return nil
+	}
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return set.active
+}
+
+// applySleeveScope limits a trading context to the positions and capital of the active sleeve
+func (at *AutoTrader) applySleeveScope(ctx *kernel.Context) {
+	r := at.activeSleeve()
+	if r == nil {
+		return
+	}
+	set := at.currentSleeveSet()
+
+	var positions []kernel.PositionInfo
+	var margin, unrealized float64
+	set.mu.Lock()
+	for _, pos := range ctx.Positions {
+		if set.Owners[sleevePositionKey(pos.Symbol, pos.Side)] != r.Sleeve.ID {
+			continue
+		}
+		positions = append(positions, pos)
+		margin += pos.MarginUsed
+		unrealized += pos.UnrealizedPnL
+	}
+	set.mu.Unlock()
+
+	realized, err := at.store.Position().GetSleeveRealizedPnL(at.id, r.Sleeve.ID)
+	if err != nil {
+		logger.Warnf("[Sleeves] Failed to get realized PnL of %s: %v", r.Sleeve.Name, err)
+	}
+	equity := r.Capital + realized + unrealized
+	available := math.Max(0, math.Min(equity-margin, ctx.Account.AvailableBalance))
+
+	account := kernel.AccountInfo{
+		TotalEquity:      equity,
+		AvailableBalance: available,
+		UnrealizedPnL:    unrealized,
+		TotalPnL:         realized + unrealized,
+		MarginUsed:       margin,
+		PositionCount:    len(positions),
+	}
+	if r.Capital > 0 {
+		account.TotalPnLPct = account.TotalPnL / r.Capital * 100
+	}
+	if equity > 0 {
+		account.MarginUsedPct = margin / equity * 100
+	}
+	ctx.Account = account
+	ctx.Positions = positions
+}
+
+// checkSleeveDecision rejects decisions on positions of other sleeves and caps opens to the
+// sleeve's capital and the account-wide margin limit
+func (at *AutoTrader) checkSleeveDecision(d *kernel.Decision) error {
+	r := at.activeSleeve()
+	if r == nil {
+		return nil
+	}
+	set := at.currentSleeveSet()
+	id := r.Sleeve.ID
+
+	set.mu.Lock()
+	longOwner := set.Owners[sleevePositionKey(d.Symbol, "long")]
+	shortOwner := set.Owners[sleevePositionKey(d.Symbol, "short")]
+	set.mu.Unlock()
+
+	switch d.Action {
+	case "close_long":
+		if longOwner != id {
+			return fmt.Errorf("%s long is not held by sleeve %s", d.Symbol, r.Sleeve.Name)
+		}
+		return nil
+	case "close_short":
+		if shortOwner != id {
+			return fmt.Errorf("%s short is not held by sleeve %s", d.Symbol, r.Sleeve.Name)
+		}
+		return nil
+	case "open_long", "open_short":
+	default:
+		if longOwner != id && shortOwner != id {
+			return fmt.Errorf("%s has no position of sleeve %s", d.Symbol, r.Sleeve.Name)
+		}
+		return nil
+	}
+
+	// Opens: the symbol must not be traded by another sleeve (positions are netted per symbol)
+	for _, owner := range []string{longOwner, shortOwner} {
+		if owner != "" && owner != id {
+			return fmt.Errorf("%s is held by another sleeve", d.Symbol)
+		}
+	}
+
+	equity, _, accountMargin, positions, err := at.accountState()
+	if err != nil {
+		return err
+	}
+	var sleeveMargin, sleeveUnrealized float64
+	set.mu.Lock()
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if set.Owners[sleevePositionKey(symbol, side)] == id {
+			margin, unrealized := positionMargin(pos)
+			sleeveMargin += margin
+			sleeveUnrealized += unrealized
+		}
+	}
+	set.mu.Unlock()
+	realized, _ := at.store.Position().GetSleeveRealizedPnL(at.id, id)
+
+	maxUsage := at.traderStrategy().Config.RiskControl.MaxMarginUsage
+	if maxUsage <= 0 {
+		maxUsage = defaultMaxMarginUsage
+	}
+	accountRoom := equity*maxUsage - accountMargin
+	sleeveRoom := r.Capital + realized + sleeveUnrealized - sleeveMargin
+	room := math.Min(accountRoom, sleeveRoom)
+	if room <= 0 {
+		return fmt.Errorf("sleeve %s has no margin left (sleeve room %.2f, account room %.2f USDT)", r.Sleeve.Name, sleeveRoom, accountRoom)
+	}
+
+	leverage := d.Leverage
+	if leverage < 1 {
+		leverage = 1
+	}
+	if margin := d.PositionSizeUSD / float64(leverage); margin > room {
+		capped := room * float64(leverage)
+		logger.Infof("⚠️ [Sleeves] %s %s capped from %.2f to %.2f USDT (sleeve room %.2f, account room %.2f)",
+			d.Action, d.Symbol, d.PositionSizeUSD, capped, sleeveRoom, accountRoom)
+		d.PositionSizeUSD = capped
+	}
+	return nil
+}
+
+// claimSleevePosition makes the active sleeve the owner of a position it opened
+func (at *AutoTrader) claimSleevePosition(d *kernel.Decision) {
+	r := at.activeSleeve()
+	if r == nil || !strings.HasPrefix(d.Action, "open_") {
+		return
+	}
+	key := sleevePositionKey(d.Symbol, strings.TrimPrefix(d.Action, "open_"))
+	set := at.currentSleeveSet()
+	set.mu.Lock()
+	set.Owners[key] = r.Sleeve.ID
+	set.pending[key] = r.Sleeve.ID
+	set.mu.Unlock()
+	at.attributeSleevePositions()
+}
+
+// attributeSleevePositions attributes sleeve positions once they are recorded in trader_positions
+// (OrderSync may record them after the order), and forgets owners of closed positions
+func (at *AutoTrader) attributeSleevePositions() {
+	set := at.currentSleeveSet()
+	set.mu.Lock()
+	pending := make(map[string]string, len(set.pending))
+	for key, id := range set.pending {
+		pending[key] = id
+	}
+	set.mu.Unlock()
+
+	for key, id := range pending {
+		i := strings.LastIndex(key, "_")
+		posID, err := at.store.Position().AssignSleeve(at.id, key[:i], key[i+1:], id)
+		if err != nil {
+			logger.Warnf("[Sleeves] Failed to attribute %s: %v", key, err)
+			continue
+		}
+		if posID > 0 {
+			set.mu.Lock()
+			delete(set.pending, key)
+			set.mu.Unlock()
+		}
+	}
+
+	positions, err := at.trader.GetPositions()
+	if err != nil {
+		return
+	}
+	open := make(map[string]bool)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if size, _ := pos["positionAmt"].(float64); size != 0 {
+			open[sleevePositionKey(symbol, side)] = true
+		}
+	}
+	set.mu.Lock()
+	for key := range set.Owners {
+		if !open[key] {
+			delete(set.Owners, key)
+			delete(set.pending, key)
+		}
+	}
+	set.mu.Unlock()
+}
+
+// accountState returns the account equity, unrealized PnL, margin used and raw positions
+func (at *AutoTrader) accountState() (equity, unrealized, marginUsed float64, positions []map[string]interface{}, err error) {
+	balance, err := at.trader.GetBalance()
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	wallet, _ := balance["totalWalletBalance"].(float64)
+	unrealized, _ = balance["totalUnrealizedProfit"].(float64)
+	if eq, ok := balance["totalEquity"].(float64); ok && eq > 0 {
+		equity = eq
+	} else {
+		equity = wallet + unrealized
+	}
+
+	positions, err = at.trader.GetPositions()
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	for _, pos := range positions {
+		margin, _ := positionMargin(pos)
+		marginUsed += margin
+	}
+	return equity, unrealized, marginUsed, positions, nil
+}
+
+// positionMargin returns the estimated margin and unrealized PnL of an exchange position
+func positionMargin(pos map[string]interface{}) (margin, unrealized float64) {
+	quantity, _ := pos["positionAmt"].(float64)
+	markPrice, _ := pos["markPrice"].(float64)
+	unrealized, _ = pos["unRealizedProfit"].(float64)
+	leverage := 10.0 // Same default as buildTradingContext
+	if lev, ok := pos["leverage"].(float64); ok && lev > 0 {
+		leverage = lev
+	}
+	return math.Abs(quantity) * markPrice / leverage, unrealized
+}
+
+// saveSleeveEquity saves the equity of the active sleeve from its scoped context
+func (at *AutoTrader) saveSleeveEquity(ctx *kernel.Context) {
+	r := at.activeSleeve()
+	if r == nil || at.store == nil {
+		return
+	}
+	snapshot := &store.SleeveEquitySnapshot{
+		TraderID:      at.id,
+		SleeveID:      r.Sleeve.ID,
+		Timestamp:     time.Now().UTC(),
+		Capital:       r.Capital,
+		RealizedPnL:   ctx.Account.TotalPnL - ctx.Account.UnrealizedPnL,
+		UnrealizedPnL: ctx.Account.UnrealizedPnL,
+		TotalEquity:   ctx.Account.TotalEquity,
+		MarginUsed:    ctx.Account.MarginUsed,
+		PositionCount: ctx.Account.PositionCount,
+	}
+	if err := at.store.Sleeve().SaveEquity(snapshot); err != nil {
+		logger.Infof("⚠️ Failed to save sleeve equity snapshot: %v", err)
+	}
+}
+
+// saveAccountEquitySnapshot saves the account-wide equity (for cycles without a trading context)
+func (at *AutoTrader) saveAccountEquitySnapshot() {
+	equity, unrealized, margin, positions, err := at.accountState()
+	if err != nil {
+		logger.Infof("⚠️ Failed to get account state for equity snapshot: %v", err)
+		return
+	}
+	count := 0
+	for _, pos := range positions {
+		if size, _ := pos["positionAmt"].(float64); size != 0 {
+			count++
+		}
+	}
+	account := kernel.AccountInfo{TotalEquity: equity, UnrealizedPnL: unrealized, MarginUsed: margin, PositionCount: count}
+	if equity > 0 {
+		account.MarginUsedPct = margin / equity * 100
+	}
+	at.saveEquitySnapshot(&kernel.Context{Account: account})
+}
@@ -0,0 +1,144 @@
+package trader
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+func TestCheckSleeveDecisionOwnership(t *testing.T) {
+	trend := &sleeveRunner{Sleeve: &store.TraderSleeve{ID: "trend", Name: "trend"}}
+	at := &AutoTrader{sleeveSet: &SleeveSet{
+		Owners: map[string]string{
+			sleevePositionKey("BTCUSDT", "LONG"):  "trend",
+			sleevePositionKey("ETHUSDT", "short"): "meanrev",
+		},
+		pending: map[string]string{},
+		active:  trend,
+	}}
+
+	tests := []struct {
+		action  string
+		symbol  string
+		wantErr bool
+	}{
+		{"close_long", "BTCUSDT", false},
+		{"close_short", "BTCUSDT", true},
+		{"hold", "BTCUSDT", false},
+		{"partial_close", "BTCUSDT", false},
+		{"close_short", "ETHUSDT", true},
+		{"hold", "ETHUSDT", true},
+		{"open_long", "ETHUSDT", true}, // Symbol traded by another sleeve
+	}
+	for _, tt := range tests {
+		err := at.checkSleeveDecision(&kernel.Decision{Symbol: tt.symbol, Action: tt.action})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %s: err = %v, wantErr %v", tt.action, tt.symbol, err, tt.wantErr)
+		}
+	}
+
+	// Outside sleeve cycles every decision passes
+	at.sleeveSet.active = nil
+	if err := at.checkSleeveDecision(&kernel.Decision{Symbol: "ETHUSDT", Action: "close_short"}); err != nil {
+		t.Errorf("no active sleeve: %v", err)
+	}
+}
+
+func TestDecisionStrategySupported(t *testing.T) {
+	for _, st := range []string{"", "ai_trading", "rule_based"} {
+		if !DecisionStrategySupported(st) {
+			t.Errorf("%q should run as a sleeve", st)
+		}
+	}
+	for _, st := range []string{"grid_trading", "dca", "funding_carry", "pairs_trading", "regime_router"} {
+		if DecisionStrategySupported(st) {
+			t.Errorf("%q should not run as a sleeve", st)
+		}
+	}
+}
+
+func TestSelectStrategyCycleUsesRegisteredCycle(t *testing.T) {
+	var inits, runs int
+	RegisterStrategyCycle("test_cycle", StrategyCycle{
+		Name: "Test",
+		Init: func(at *AutoTrader) error { inits++; return nil },
+		Run:  func(at *AutoTrader) error { runs++; return nil },
+	})
+	if DecisionStrategySupported("test_cycle") {
+		t.Error("a type with its own cycle should not run as a sleeve")
+	}
+
+	at := &AutoTrader{name: "test", config: AutoTraderConfig{StrategyConfig: &store.StrategyConfig{StrategyType: "test_cycle"}}}
+	cycle, _, err := at.selectStrategyCycle()
+	if err != nil {
+		t.Fatalf("selectStrategyCycle() error: %v", err)
+	}
+	if err := cycle(); err != nil || inits != 1 || runs != 1 {
+		t.Errorf("cycle() = %v, inits = %d, runs = %d, want the registered cycle initialized and run once", err, inits, runs)
+	}
+}
+
+func TestGetStatusDuringSleeveCycle(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "sleeves.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	// Static coin sources without candidates keep the cycles offline
+	strategyConfig := func(strategyType string) *store.StrategyConfig {
+		cfg := &store.StrategyConfig{StrategyType: strategyType}
+		cfg.CoinSource.SourceType = "static"
+		return cfg
+	}
+	baseConfig, sleeveConfig := strategyConfig("ai_trading"), strategyConfig("rule_based")
+	at := &AutoTrader{
+		id: "sleeves", store: st, isRunning: true, lastResetTime: time.Now(), initialBalance: 1000,
+		trader:         newFakeTrader(map[string]float64{"BTCUSDT": 100000}),
+		config:         AutoTraderConfig{StrategyConfig: baseConfig},
+		strategyEngine: kernel.NewStrategyEngine(baseConfig),
+		strategy:       &scriptedStrategy{},
+		sleeveSet: &SleeveSet{
+			Sleeves: []*sleeveRunner{{
+				Sleeve: &store.TraderSleeve{ID: "trend", Name: "trend", AllocationType: store.SleeveAllocationUSD, Allocation: 500},
+				Strategy: &loadedStrategy{ID: "s-trend", Name: "Trend", Config: sleeveConfig,
+					Engine: kernel.NewStrategyEngine(sleeveConfig), Strategy: &scriptedStrategy{}},
+			}},
+			Owners:        map[string]string{},
+			pending:       map[string]string{},
+			IsInitialized: true,
+		},
+	}
+
+	// The API reads the trader's status while sleeve cycles run (run with -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := at.RunSleevesCycle(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+			if got := at.GetStatus()["strategy_type"]; got != "ai_trading" {
+				t.Fatalf("status strategy_type = %v, want the trader's ai_trading", got)
+			}
+		}
+	}
+
+	if at.callCount.Load() != 20 {
+		t.Errorf("call count = %d, want one cycle per run", at.callCount.Load())
+	}
+	if at.runningStrategy().Config != baseConfig {
+		t.Error("the trader's strategy should be running again after the sleeve cycles")
+	}
+}
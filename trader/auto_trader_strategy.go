@@ -0,0 +1,143 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/store"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Execution records of strategies with their own cycle (DCA, funding carry, pairs, rebalance)
+// ============================================================================
+
+// orderFillPolls / orderFillPollInterval bound the wait for a market order fill report
+var (
+	orderFillPolls        = 5
+	orderFillPollInterval = 500 * time.Millisecond
+)
+
+// orderFill execution of a market order; fields the exchange did not report keep the requested
+// quantity and reference price (fee 0)
+type orderFill struct {
+	OrderID  string
+	Price    float64
+	Quantity float64
+	Fee      float64
+}
+
+// confirmOrderFill polls the status of a market order for its average price, executed quantity
+// and commission
+func confirmOrderFill(t Trader, symbol string, order map[string]interface{}, quantity, price float64) orderFill {
+	fill := orderFill{OrderID: orderIDOf(order), Price: price, Quantity: quantity}
+	if fill.OrderID == "" || fill.OrderID == "0" {
+		return fill
+	}
+	for i := 0; i < orderFillPolls; i++ {
+		time.Sleep(orderFillPollInterval)
+		status, err := t.GetOrderStatus(symbol, fill.OrderID)
+		if err != nil {
+			continue
+		}
+		if state, _ := SafeString(status, "status"); state != "FILLED" {
+			continue
+		}
+		if avgPrice, err := SafeFloat64(status, "avgPrice"); err == nil && avgPrice > 0 {
+			fill.Price = avgPrice
+		}
+		if executedQty, err := SafeFloat64(status, "executedQty"); err == nil && executedQty > 0 {
+			fill.Quantity = executedQty
+		}
+		if commission, err := SafeFloat64(status, "commission"); err == nil {
+			fill.Fee = commission
+		}
+		break
+	}
+	return fill
+}
+
+// orderIDOf returns the order ID of an order result ("" if missing)
+func orderIDOf(order map[string]interface{}) string {
+	switch v := order["orderId"].(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// newStrategyAction starts the record of a strategy decision; the executor fills in what was
+// actually sent and filled
+func newStrategyAction(d *kernel.Decision) store.DecisionAction {
+	return store.DecisionAction{
+		Action:          d.Action,
+		Symbol:          d.Symbol,
+		Leverage:        d.Leverage,
+		StopLoss:        d.StopLoss,
+		TakeProfit:      d.TakeProfit,
+		ClosePercentage: d.ClosePercentage,
+		Confidence:      d.Confidence,
+		Reasoning:       d.Reasoning,
+		Timestamp:       time.Now().UTC(),
+	}
+}
+
+// setActionFill records the executed quantity, price and order of an action
+func setActionFill(action *store.DecisionAction, fill orderFill) {
+	action.Quantity = fill.Quantity
+	action.Price = fill.Price
+	action.OrderID, _ = strconv.ParseInt(fill.OrderID, 10, 64)
+}
+
+// finishStrategyAction sets the outcome of an action
+func finishStrategyAction(action *store.DecisionAction, err error) {
+	if err != nil {
+		action.Success = false
+		action.Error = err.Error()
+		return
+	}
+	action.Success = true
+	action.Error = ""
+}
+
+// saveStrategyDecisionRecord saves the decision of a strategy with its own cycle together with
+// the execution result of each action
+func (at *AutoTrader) saveStrategyDecisionRecord(name string, decision *kernel.FullDecision, actions []store.DecisionAction) {
+	if at.store == nil || len(decision.Decisions) == 0 {
+		return
+	}
+
+	at.cycleNumber++
+	record := &store.DecisionRecord{
+		TraderID:     at.id,
+		CycleNumber:  at.cycleNumber,
+		Timestamp:    time.Now().UTC(),
+		SystemPrompt: decision.SystemPrompt,
+		CoTTrace:     decision.CoTTrace,
+		Success:      true,
+		Decisions:    actions,
+	}
+	decisionJSON, _ := json.MarshalIndent(decision.Decisions, "", "  ")
+	record.DecisionJSON = string(decisionJSON)
+	for _, a := range actions {
+		if a.Success {
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s succeeded", a.Symbol, a.Action))
+		} else {
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s failed: %s", a.Symbol, a.Action, a.Error))
+		}
+	}
+	record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("%s cycle completed with %d decisions", name, len(decision.Decisions)))
+
+	if err := at.store.Decision().LogDecision(record); err != nil {
+		logger.Warnf("[%s] Failed to save decision record: %v", name, err)
+	}
+}
@@ -0,0 +1,189 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/store"
+	"nofx/trader/types"
+)
+
+// fakeTrader exchange double for executor tests: market orders fill at the configured price with
+// a commission of 0.1% and move the positions; fail makes an order fail by "<action> <symbol>"
+type fakeTrader struct {
+	types.Trader
+
+	mu        sync.Mutex
+	prices    map[string]float64
+	positions map[string]float64 // "<symbol> <side>" → quantity
+	fail      map[string]error
+	orders    []string
+	fills     map[string]map[string]interface{}
+}
+
+func newFakeTrader(prices map[string]float64) *fakeTrader {
+	return &fakeTrader{
+		prices:    prices,
+		positions: make(map[string]float64),
+		fail:      make(map[string]error),
+		fills:     make(map[string]map[string]interface{}),
+	}
+}
+
+func (f *fakeTrader) order(action, symbol, side string, quantity float64) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail[action+" "+symbol]; err != nil {
+		return nil, err
+	}
+	key := symbol + " " + side
+	if action == "close_"+side {
+		if quantity == 0 || quantity > f.positions[key] {
+			quantity = f.positions[key]
+		}
+		f.positions[key] -= quantity
+	} else {
+		f.positions[key] += quantity
+	}
+	f.orders = append(f.orders, action+" "+symbol)
+	id := strconv.Itoa(len(f.orders))
+	price := f.prices[symbol]
+	f.fills[id] = map[string]interface{}{
+		"status":      "FILLED",
+		"avgPrice":    price,
+		"executedQty": quantity,
+		"commission":  price * quantity * 0.001,
+	}
+	return map[string]interface{}{"orderId": id}, nil
+}
+
+func (f *fakeTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return f.order("open_long", symbol, "long", quantity)
+}
+
+func (f *fakeTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	return f.order("open_short", symbol, "short", quantity)
+}
+
+func (f *fakeTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.order("close_long", symbol, "long", quantity)
+}
+
+func (f *fakeTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+	return f.order("close_short", symbol, "short", quantity)
+}
+
+func (f *fakeTrader) GetMarketPrice(symbol string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if price, ok := f.prices[symbol]; ok {
+		return price, nil
+	}
+	return 0, fmt.Errorf("no price for %s", symbol)
+}
+
+func (f *fakeTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fill, ok := f.fills[orderID]; ok {
+		return fill, nil
+	}
+	return nil, fmt.Errorf("unknown order %s", orderID)
+}
+
+func (f *fakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var positions []map[string]interface{}
+	for key, qty := range f.positions {
+		if qty <= 0 {
+			continue
+		}
+		var symbol, side string
+		fmt.Sscanf(key, "%s %s", &symbol, &side)
+		amt := qty
+		if side == "short" {
+			amt = -qty
+		}
+		positions = append(positions, map[string]interface{}{
+			"symbol": symbol, "side": side, "positionAmt": amt,
+			"entryPrice": f.prices[symbol], "markPrice": f.prices[symbol],
+		})
+	}
+	return positions, nil
+}
+
+func (f *fakeTrader) GetBalance() (map[string]interface{}, error) {
+	return map[string]interface{}{"totalWalletBalance": 10000.0, "availableBalance": 10000.0, "totalUnrealizedProfit": 0.0}, nil
+}
+
+func (f *fakeTrader) SetLeverage(symbol string, leverage int) error { return nil }
+
+func (f *fakeTrader) CancelAllOrders(symbol string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orders = append(f.orders, "cancel_all "+symbol)
+	return nil
+}
+
+func (f *fakeTrader) SetMarginMode(symbol string, isCrossMargin bool) error { return nil }
+
+// withFastFills makes confirmOrderFill poll without waiting
+func withFastFills(t *testing.T) {
+	interval := orderFillPollInterval
+	orderFillPollInterval = 0
+	t.Cleanup(func() { orderFillPollInterval = interval })
+}
+
+func TestCloseDCADealNetOfFees(t *testing.T) {
+	withFastFills(t)
+	fake := newFakeTrader(map[string]float64{"BTCUSDT": 110})
+	fake.positions["BTCUSDT long"] = 1
+	cfg := &store.DCAStrategyConfig{Symbol: "BTCUSDT", Leverage: 2, BaseOrderUSD: 100, TakeProfitPct: 1}
+	deal := kernel.NewDCADeal(cfg, 100, 1, time.Now())
+	deal.Fees = 0.05 // Base order
+	at := &AutoTrader{trader: fake, dcaState: &DCAState{Config: cfg, Deal: deal}}
+
+	action := store.DecisionAction{}
+	if err := at.closeDCADeal(&kernel.Decision{Symbol: "BTCUSDT", Action: "close_long"}, 109, &action); err != nil {
+		t.Fatal(err)
+	}
+	// Filled at 110 (not the 109 decision price), 0.11 close commission
+	if action.Price != 110 || action.Quantity != 1 || action.OrderID != 1 {
+		t.Errorf("action should carry the fill: %+v", action)
+	}
+	if math.Abs(deal.Fees-0.16) > 1e-9 || math.Abs(deal.RealizedPnL-(10-0.16)) > 1e-9 {
+		t.Errorf("fees = %.4f, pnl = %.4f, want 0.16 and 9.84", deal.Fees, deal.RealizedPnL)
+	}
+	if at.dcaState.Deal != nil {
+		t.Error("the deal should be closed")
+	}
+}
+
+func TestSaveStrategyDecisionRecordKeepsFailures(t *testing.T) {
+	st, err := store.New(t.TempDir() + "/strategy.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	at := &AutoTrader{id: "t1", store: st}
+
+	ok := store.DecisionAction{Symbol: "BTCUSDT", Action: "open_long", Success: true}
+	failed := store.DecisionAction{Symbol: "ETHUSDT", Action: "open_short", Error: "rejected"}
+	at.saveStrategyDecisionRecord("Pairs", &kernel.FullDecision{Decisions: []kernel.Decision{{}, {}}},
+		[]store.DecisionAction{ok, failed})
+
+	records, err := st.Decision().GetLatestRecords("t1", 1)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one record, got %d (%v)", len(records), err)
+	}
+	actions := records[0].Decisions
+	if len(actions) != 2 || !actions[0].Success || actions[1].Success || actions[1].Error != "rejected" {
+		t.Errorf("actions should keep their outcome: %+v", actions)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -60,6 +61,10 @@ type FuturesTrader struct {
 
 	// Cache validity period (15 seconds)
 	cacheDuration time.Duration
+
+	// Spot client on the same account (created on first use)
+	spotClient *binance.Client
+	spotOnce   sync.Once
 }
 
 // NewFuturesTrader creates futures trader
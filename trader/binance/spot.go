@@ -0,0 +1,140 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"nofx/trader/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+)
+
+// spot returns the spot client of the futures account's API key
+func (t *FuturesTrader) spot() *binance.Client {
+	t.spotOnce.Do(func() {
+		t.spotClient = binance.NewClient(t.client.APIKey, t.client.SecretKey)
+	})
+	return t.spotClient
+}
+
+// GetFundingFees returns the funding settlements of a symbol since startTime
+func (t *FuturesTrader) GetFundingFees(symbol string, startTime time.Time) ([]types.FundingFee, error) {
+	incomes, err := t.client.NewGetIncomeHistoryService().
+		Symbol(symbol).
+		IncomeType("FUNDING_FEE").
+		StartTime(startTime.UnixMilli()).
+		Limit(1000).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get funding history: %w", err)
+	}
+
+	fees := make([]types.FundingFee, 0, len(incomes))
+	for _, income := range incomes {
+		amount, err := strconv.ParseFloat(income.Income, 64)
+		if err != nil {
+			continue
+		}
+		fees = append(fees, types.FundingFee{
+			Symbol: income.Symbol,
+			Amount: amount,
+			Time:   time.UnixMilli(income.Time).UTC(),
+		})
+	}
+	return fees, nil
+}
+
+// SpotBuy buys quantity of the base asset of symbol at market on the spot account
+func (t *FuturesTrader) SpotBuy(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.spotMarketOrder(symbol, binance.SideTypeBuy, quantity)
+}
+
+// SpotSell sells quantity of the base asset of symbol at market on the spot account
+func (t *FuturesTrader) SpotSell(symbol string, quantity float64) (map[string]interface{}, error) {
+	return t.spotMarketOrder(symbol, binance.SideTypeSell, quantity)
+}
+
+// spotQuantityPrecision returns the quantity precision of a spot symbol (LOT_SIZE step)
+func (t *FuturesTrader) spotQuantityPrecision(symbol string) (int, error) {
+	info, err := t.spot().NewExchangeInfoService().Symbol(symbol).Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spot trading rules: %w", err)
+	}
+	for i := range info.Symbols {
+		if info.Symbols[i].Symbol == symbol {
+			if filter := info.Symbols[i].LotSizeFilter(); filter != nil {
+				return calculatePrecision(filter.StepSize), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("spot symbol %s not found", symbol)
+}
+
+// spotMarketOrder places a spot market order; the quantity is truncated (never rounded up) so that
+// a sell never exceeds the balance
+func (t *FuturesTrader) spotMarketOrder(symbol string, side binance.SideType, quantity float64) (map[string]interface{}, error) {
+	precision, err := t.spotQuantityPrecision(symbol)
+	if err != nil {
+		return nil, err
+	}
+	factor := math.Pow(10, float64(precision))
+	quantityStr := strconv.FormatFloat(math.Floor(quantity*factor)/factor, 'f', precision, 64)
+	if q, _ := strconv.ParseFloat(quantityStr, 64); q <= 0 {
+		return nil, fmt.Errorf("spot order size too small, rounded to 0 (original: %.8f)", quantity)
+	}
+
+	order, err := t.spot().NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(binance.OrderTypeMarket).
+		Quantity(quantityStr).
+		NewOrderRespType(binance.NewOrderRespTypeFULL).
+		Do(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to place spot %s order: %w", strings.ToLower(string(side)), err)
+	}
+
+	// Market orders fill immediately: the fills carry the average price and the commission
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	quoteQty, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
+	result := map[string]interface{}{
+		"orderId":     order.OrderID,
+		"symbol":      order.Symbol,
+		"status":      string(order.Status),
+		"executedQty": executedQty,
+	}
+	if executedQty > 0 {
+		avgPrice := quoteQty / executedQty
+		result["avgPrice"] = avgPrice
+		var commission float64
+		for _, fill := range order.Fills {
+			fee, _ := strconv.ParseFloat(fill.Commission, 64)
+			switch fill.CommissionAsset {
+			case "USDT":
+				commission += fee
+			case strings.TrimSuffix(symbol, "USDT"):
+				commission += fee * avgPrice
+			}
+		}
+		result["commission"] = commission
+	}
+	return result, nil
+}
+
+// GetSpotBalance returns the free spot balance of the base asset of symbol
+func (t *FuturesTrader) GetSpotBalance(symbol string) (float64, error) {
+	account, err := t.spot().NewGetAccountService().Do(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spot account: %w", err)
+	}
+	asset := strings.TrimSuffix(symbol, "USDT")
+	for _, b := range account.Balances {
+		if b.Asset == asset {
+			return strconv.ParseFloat(b.Free, 64)
+		}
+	}
+	return 0, nil
+}
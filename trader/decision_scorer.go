@@ -0,0 +1,386 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultScoringTimeframe   = "15m"
+	defaultScoringHorizonBars = 16
+	defaultScoringLookback    = 14
+	defaultTargetHitRate      = 55.0
+	defaultScoringMinSamples  = 10
+
+	// scoringBatchSize decision records loaded per query
+	scoringBatchSize = 100
+	// scoringMaxActionsPerPass actions scored per pass, so a backlog is worked off over several cycles
+	scoringMaxActionsPerPass = 50
+	// scoringMaxAttempts failed kline fetches before an action is given up
+	scoringMaxAttempts = 3
+)
+
+// KlineRangeFetcher fetches klines within a time range (market.GetKlinesRange by default)
+type KlineRangeFetcher func(symbol, timeframe string, start, end time.Time) ([]market.Kline, error)
+
+// DecisionScorer evaluates past decisions against the price action that followed them
+type DecisionScorer struct {
+	store    *store.Store
+	traderID string
+	aiModel  string
+	config   store.ScoringConfig
+	horizon  time.Duration
+
+	fetchKlines  KlineRangeFetcher
+	lastRecordID int64 // Cursor of the last fully processed record, -1 until loaded from store
+
+	mu       sync.Mutex     // Serializes scoring passes
+	running  atomic.Bool    // A background pass is in progress
+	attempts map[string]int // Failed fetches per record action (in memory)
+}
+
+// NewDecisionScorer creates a decision scorer, filling unset config fields with defaults
+func NewDecisionScorer(st *store.Store, traderID, aiModel string, cfg store.ScoringConfig) (*DecisionScorer, error) {
+	if cfg.Timeframe == "" {
+		cfg.Timeframe = defaultScoringTimeframe
+	}
+	if cfg.HorizonBars <= 0 {
+		cfg.HorizonBars = defaultScoringHorizonBars
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = defaultScoringLookback
+	}
+	if cfg.TargetHitRate <= 0 {
+		cfg.TargetHitRate = defaultTargetHitRate
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaultScoringMinSamples
+	}
+
+	tfDuration, err := market.TFDuration(cfg.Timeframe)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scoring timeframe %q: %w", cfg.Timeframe, err)
+	}
+
+	return &DecisionScorer{
+		store:        st,
+		traderID:     traderID,
+		aiModel:      aiModel,
+		config:       cfg,
+		horizon:      tfDuration * time.Duration(cfg.HorizonBars),
+		fetchKlines:  market.GetKlinesRange,
+		lastRecordID: -1,
+		attempts:     make(map[string]int),
+	}, nil
+}
+
+// Config returns the effective scoring configuration
+func (s *DecisionScorer) Config() store.ScoringConfig {
+	return s.config
+}
+
+// ScorePendingAsync runs ScorePending in the background so scoring never delays the trading cycle.
+// A pass still running is not doubled.
+func (s *DecisionScorer) ScorePendingAsync(now time.Time) {
+	if !s.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.running.Store(false)
+		if scored, err := s.ScorePending(now); err != nil {
+			logger.Infof("⚠️ [%s] Decision scoring failed: %v", s.traderID, err)
+		} else if scored > 0 {
+			logger.Infof("🎯 [%s] Scored %d decisions", s.traderID, scored)
+		}
+	}()
+}
+
+// ScorePending scores decisions whose evaluation horizon has elapsed, at most scoringMaxActionsPerPass
+// actions per call, and settles the realized PnL of linked positions that closed since.
+// Returns the number of decision actions scored
+func (s *DecisionScorer) ScorePending(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadCursor(); err != nil {
+		return 0, err
+	}
+	if _, err := s.store.DecisionScore().SettleRealizedPnL(s.traderID); err != nil {
+		logger.Warnf("⚠️ [%s] %v", s.traderID, err)
+	}
+
+	scored := 0
+	for {
+		records, err := s.store.Decision().GetRecordsAfterID(s.traderID, s.lastRecordID, scoringBatchSize)
+		if err != nil {
+			return scored, err
+		}
+		if len(records) == 0 {
+			return scored, nil
+		}
+
+		for _, record := range records {
+			// Records are in ID order: stop at the first one still inside its horizon
+			if record.Timestamp.Add(s.horizon).After(now) {
+				return scored, nil
+			}
+			// Records are processed whole, so the cap is checked between records
+			if scored >= scoringMaxActionsPerPass {
+				return scored, nil
+			}
+
+			for _, action := range record.Decisions {
+				score, err := s.scoreAction(record, action)
+				if err != nil {
+					key := fmt.Sprintf("%d_%s_%s", record.ID, action.Symbol, action.Action)
+					s.attempts[key]++
+					if s.attempts[key] < scoringMaxAttempts {
+						// Retry the record on the next pass (already saved actions are not duplicated)
+						logger.Warnf("⚠️ [%s] Failed to score %s %s (record %d), will retry: %v", s.traderID, action.Symbol, action.Action, record.ID, err)
+						return scored, nil
+					}
+					// Give up rather than block the cursor (e.g. symbol without Binance klines)
+					logger.Warnf("⚠️ [%s] Giving up scoring %s %s (record %d): %v", s.traderID, action.Symbol, action.Action, record.ID, err)
+					delete(s.attempts, key)
+					continue
+				}
+				if score == nil {
+					continue
+				}
+				if err := s.store.DecisionScore().Save(score); err != nil {
+					logger.Warnf("⚠️ [%s] %v", s.traderID, err)
+					continue
+				}
+				scored++
+			}
+			if err := s.store.DecisionScore().SaveCursor(s.traderID, record.ID); err != nil {
+				return scored, err
+			}
+			s.lastRecordID = record.ID
+		}
+
+		if len(records) < scoringBatchSize {
+			return scored, nil
+		}
+	}
+}
+
+// loadCursor loads the scoring cursor once. A trader scored before cursors were saved resumes after its
+// last scored record; a trader enabling scoring starts at its latest record instead of backfilling history.
+func (s *DecisionScorer) loadCursor() error {
+	if s.lastRecordID >= 0 {
+		return nil
+	}
+	lastID, ok, err := s.store.DecisionScore().GetCursor(s.traderID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if lastID, err = s.store.DecisionScore().GetLastScoredRecordID(s.traderID); err != nil {
+			return fmt.Errorf("failed to load scoring cursor: %w", err)
+		}
+		if lastID == 0 {
+			if lastID, err = s.store.Decision().GetLastRecordID(s.traderID); err != nil {
+				return fmt.Errorf("failed to load scoring cursor: %w", err)
+			}
+		}
+		if err := s.store.DecisionScore().SaveCursor(s.traderID, lastID); err != nil {
+			return err
+		}
+	}
+	s.lastRecordID = lastID
+	return nil
+}
+
+// scoreAction scores a single decision action, nil for actions that cannot be scored
+func (s *DecisionScorer) scoreAction(record *store.DecisionRecord, action store.DecisionAction) (*store.DecisionScore, error) {
+	direction := actionDirection(action.Action)
+	if direction == 0 || action.Symbol == "" {
+		return nil, nil
+	}
+
+	decisionTime := record.Timestamp
+	if !action.Timestamp.IsZero() {
+		decisionTime = action.Timestamp
+	}
+
+	klines, err := s.fetchKlines(action.Symbol, s.config.Timeframe, decisionTime, decisionTime.Add(s.horizon))
+	if err != nil {
+		return nil, err
+	}
+	// Only bars opening after the decision are tradeable
+	bars := make([]market.Kline, 0, len(klines))
+	for _, k := range klines {
+		if k.OpenTime >= decisionTime.UnixMilli() {
+			bars = append(bars, k)
+		}
+	}
+	if len(bars) > s.config.HorizonBars {
+		bars = bars[:s.config.HorizonBars]
+	}
+	if len(bars) == 0 {
+		return nil, nil
+	}
+
+	score := &store.DecisionScore{
+		TraderID:         s.traderID,
+		DecisionRecordID: record.ID,
+		CycleNumber:      record.CycleNumber,
+		Symbol:           action.Symbol,
+		Action:           action.Action,
+		Confidence:       action.Confidence,
+		AIModel:          s.aiModel,
+		Executed:         action.Success,
+		DecisionTime:     decisionTime.UTC().UnixMilli(),
+		EntryPrice:       action.Price,
+		Timeframe:        s.config.Timeframe,
+		HorizonBars:      len(bars),
+	}
+	if score.EntryPrice <= 0 {
+		score.EntryPrice = bars[0].Open
+	}
+	if action.Action == "open_long" || action.Action == "open_short" {
+		score.StopLoss = action.StopLoss
+		score.TakeProfit = action.TakeProfit
+	}
+
+	evaluateForward(score, bars, direction)
+
+	// Link realized PnL for executed opening decisions
+	if action.Success && (action.Action == "open_long" || action.Action == "open_short") {
+		side := "LONG"
+		if action.Action == "open_short" {
+			side = "SHORT"
+		}
+		orderID := ""
+		if action.OrderID > 0 {
+			orderID = strconv.FormatInt(action.OrderID, 10)
+		}
+		pos, err := s.store.Position().FindPositionForDecision(s.traderID, action.Symbol, side, orderID,
+			score.DecisionTime-int64(time.Minute/time.Millisecond), score.DecisionTime+s.horizon.Milliseconds())
+		if err != nil {
+			logger.Warnf("⚠️ [%s] Failed to link position for %s: %v", s.traderID, action.Symbol, err)
+		} else if pos != nil {
+			score.PositionID = pos.ID
+			// Positions still open are settled by later passes once they close
+			if pos.Status == "CLOSED" {
+				score.RealizedPnL = pos.RealizedPnL
+				score.PnLSettled = true
+			}
+		}
+	}
+
+	return score, nil
+}
+
+// evaluateForward fills forward return, excursions, SL/TP hits and correctness
+// direction: +1 when the decision profits from rising prices, -1 when from falling prices
+func evaluateForward(score *store.DecisionScore, bars []market.Kline, direction float64) {
+	entry := score.EntryPrice
+	if entry <= 0 {
+		return
+	}
+
+	for _, bar := range bars {
+		favorable := direction * (bar.High - entry) / entry * 100
+		adverse := direction * (bar.Low - entry) / entry * 100
+		if direction < 0 {
+			favorable, adverse = adverse, favorable
+		}
+		score.MaxFavorablePct = math.Max(score.MaxFavorablePct, favorable)
+		score.MaxAdversePct = math.Min(score.MaxAdversePct, adverse)
+
+		slHit, tpHit := barHitsStops(bar, direction, score.StopLoss, score.TakeProfit)
+		score.HitStopLoss = score.HitStopLoss || slHit
+		score.HitTakeProfit = score.HitTakeProfit || tpHit
+		if score.FirstHit == "" {
+			// Both touched within one bar: assume the stop was hit first (conservative)
+			if slHit {
+				score.FirstHit = "stop_loss"
+			} else if tpHit {
+				score.FirstHit = "take_profit"
+			}
+		}
+	}
+
+	last := bars[len(bars)-1].Close
+	score.ForwardReturnPct = direction * (last - entry) / entry * 100
+
+	switch score.FirstHit {
+	case "take_profit":
+		score.Correct = true
+	case "stop_loss":
+		score.Correct = false
+	default:
+		score.Correct = score.ForwardReturnPct > 0
+	}
+}
+
+// barHitsStops checks whether a bar touched the stop loss or take profit level
+func barHitsStops(bar market.Kline, direction, stopLoss, takeProfit float64) (slHit, tpHit bool) {
+	if direction > 0 {
+		slHit = stopLoss > 0 && bar.Low <= stopLoss
+		tpHit = takeProfit > 0 && bar.High >= takeProfit
+	} else {
+		slHit = stopLoss > 0 && bar.High >= stopLoss
+		tpHit = takeProfit > 0 && bar.Low <= takeProfit
+	}
+	return slHit, tpHit
+}
+
+// actionDirection returns +1 for actions that profit from rising prices, -1 for falling, 0 if not scorable
+func actionDirection(action string) float64 {
+	switch action {
+	case "open_long", "close_short":
+		return 1
+	case "open_short", "close_long":
+		return -1
+	default:
+		return 0
+	}
+}
+
+// CalibrationReport returns the calibration report over the configured lookback window
+func (s *DecisionScorer) CalibrationReport(now time.Time) (*store.CalibrationReport, error) {
+	since := now.Add(-time.Duration(s.config.LookbackDays) * 24 * time.Hour)
+	return s.store.DecisionScore().GetCalibrationReport(s.traderID, since.UTC().UnixMilli())
+}
+
+// updateDecisionCalibration scores pending decisions and attaches the calibration report to the context
+func (at *AutoTrader) updateDecisionCalibration(ctx *kernel.Context) {
+	if at.decisionScorer == nil {
+		return
+	}
+
+	// The report below uses the scores saved so far
+	now := time.Now()
+	at.decisionScorer.ScorePendingAsync(now)
+
+	report, err := at.decisionScorer.CalibrationReport(now)
+	if err != nil {
+		logger.Infof("⚠️ [%s] Failed to build calibration report: %v", at.name, err)
+		return
+	}
+
+	cfg := at.decisionScorer.Config()
+	if cfg.DynamicMinConfidence {
+		base := at.runningStrategy().Engine.GetRiskControlConfig().MinConfidence
+		at.calibratedMinConf = report.SuggestMinConfidence(base, cfg.TargetHitRate, cfg.MinSamples)
+		if at.calibratedMinConf != base {
+			logger.Infof("🎯 [%s] Calibrated min confidence: %d (configured %d)", at.name, at.calibratedMinConf, base)
+		}
+	}
+
+	if cfg.IncludeInPrompt && report.Overall.Count > 0 {
+		ctx.DecisionCalibration = report
+		ctx.CalibratedMinConfidence = at.calibratedMinConf
+	}
+}
@@ -0,0 +1,233 @@
+package trader
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+func TestEvaluateForward(t *testing.T) {
+	bars := []market.Kline{
+		{Open: 100, High: 101, Low: 99, Close: 100.5},
+		{Open: 100.5, High: 103, Low: 100, Close: 102.5},
+		{Open: 102.5, High: 104, Low: 102, Close: 103},
+	}
+
+	t.Run("long hits take profit", func(t *testing.T) {
+		score := &store.DecisionScore{EntryPrice: 100, StopLoss: 98, TakeProfit: 102.8}
+		evaluateForward(score, bars, 1)
+		if score.FirstHit != "take_profit" || !score.HitTakeProfit || score.HitStopLoss {
+			t.Fatalf("unexpected hits: first=%s tp=%v sl=%v", score.FirstHit, score.HitTakeProfit, score.HitStopLoss)
+		}
+		if !score.Correct {
+			t.Error("take profit hit should be correct")
+		}
+		if score.ForwardReturnPct < 2.99 || score.ForwardReturnPct > 3.01 {
+			t.Errorf("ForwardReturnPct = %.4f, want 3", score.ForwardReturnPct)
+		}
+		if score.MaxFavorablePct < 3.99 || score.MaxAdversePct > -0.99 {
+			t.Errorf("excursions = %.2f / %.2f, want 4 / -1", score.MaxFavorablePct, score.MaxAdversePct)
+		}
+	})
+
+	t.Run("short hits stop loss", func(t *testing.T) {
+		score := &store.DecisionScore{EntryPrice: 100, StopLoss: 102, TakeProfit: 95}
+		evaluateForward(score, bars, -1)
+		if score.FirstHit != "stop_loss" || score.Correct {
+			t.Fatalf("first=%s correct=%v, want stop_loss/false", score.FirstHit, score.Correct)
+		}
+		if score.ForwardReturnPct > -2.99 {
+			t.Errorf("ForwardReturnPct = %.4f, want -3", score.ForwardReturnPct)
+		}
+		if score.MaxFavorablePct < 0.99 || score.MaxAdversePct > -3.99 {
+			t.Errorf("excursions = %.2f / %.2f, want 1 / -4", score.MaxFavorablePct, score.MaxAdversePct)
+		}
+	})
+
+	t.Run("both levels in one bar counts stop first", func(t *testing.T) {
+		score := &store.DecisionScore{EntryPrice: 100, StopLoss: 99.5, TakeProfit: 100.8}
+		evaluateForward(score, bars[:1], 1)
+		if score.FirstHit != "stop_loss" || !score.HitTakeProfit {
+			t.Fatalf("first=%s tp=%v, want stop_loss/true", score.FirstHit, score.HitTakeProfit)
+		}
+	})
+}
+
+func TestCalibrationSuggestMinConfidence(t *testing.T) {
+	var scores []*store.DecisionScore
+	addScores := func(confidence, total, correct int) {
+		for i := 0; i < total; i++ {
+			scores = append(scores, &store.DecisionScore{Confidence: confidence, Correct: i < correct, Symbol: "BTCUSDT", Action: "open_long"})
+		}
+	}
+	addScores(65, 10, 3) // 30%
+	addScores(75, 10, 6) // 60%
+	addScores(85, 10, 8) // 80%
+	addScores(95, 2, 0)  // too few samples
+
+	report := store.BuildCalibrationReport(scores)
+	if report.Overall.Count != 32 {
+		t.Fatalf("Overall.Count = %d, want 32", report.Overall.Count)
+	}
+	if len(report.ByConfidence) != 4 || report.ByConfidence[0].Key != "60-69" || report.ByConfidence[3].Key != "90+" {
+		t.Fatalf("unexpected buckets: %+v", report.ByConfidence)
+	}
+
+	if got := report.SuggestMinConfidence(60, 55, 5); got != 70 {
+		t.Errorf("SuggestMinConfidence(60) = %d, want 70", got)
+	}
+	if got := report.SuggestMinConfidence(75, 55, 5); got != 75 {
+		t.Errorf("SuggestMinConfidence(75) = %d, want configured 75", got)
+	}
+	if got := report.SuggestMinConfidence(60, 90, 5); got != 60 {
+		t.Errorf("SuggestMinConfidence with unreachable target = %d, want 60", got)
+	}
+}
+
+func TestDecisionScorerScorePending(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "scores.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	decisionTime := time.Now().UTC().Add(-6 * time.Hour).Truncate(time.Hour)
+	history := &store.DecisionRecord{
+		TraderID:  "trader-1",
+		Timestamp: decisionTime.Add(-time.Hour),
+		Decisions: []store.DecisionAction{{Action: "open_short", Symbol: "ETHUSDT", Confidence: 60}},
+	}
+	if err := st.Decision().LogDecision(history); err != nil {
+		t.Fatalf("LogDecision() error: %v", err)
+	}
+
+	scorer, err := NewDecisionScorer(st, "trader-1", "deepseek", store.ScoringConfig{Enabled: true, Timeframe: "1h", HorizonBars: 3})
+	if err != nil {
+		t.Fatalf("NewDecisionScorer() error: %v", err)
+	}
+	fetches, failFetches := 0, 0
+	scorer.fetchKlines = func(symbol, timeframe string, start, end time.Time) ([]market.Kline, error) {
+		fetches++
+		if failFetches > 0 {
+			failFetches--
+			return nil, fmt.Errorf("exchange unavailable")
+		}
+		var bars []market.Kline
+		for i := 0; i < 4; i++ {
+			price := 100 + float64(i)*2
+			bars = append(bars, market.Kline{
+				OpenTime: start.Add(time.Duration(i) * time.Hour).UnixMilli(),
+				Open:     price, High: price + 1, Low: price - 1, Close: price + 1,
+			})
+		}
+		return bars, nil
+	}
+
+	// Enabling scoring starts at the latest record instead of backfilling history
+	if scored, err := scorer.ScorePending(time.Now()); err != nil || scored != 0 || fetches != 0 {
+		t.Fatalf("first ScorePending() = %d, %v with %d fetches; want no backfill", scored, err, fetches)
+	}
+
+	old := &store.DecisionRecord{
+		TraderID:    "trader-1",
+		CycleNumber: 1,
+		Timestamp:   decisionTime,
+		Decisions: []store.DecisionAction{
+			{Action: "open_long", Symbol: "BTCUSDT", Price: 100, StopLoss: 95, TakeProfit: 110, Confidence: 80, Timestamp: decisionTime, Success: true},
+			{Action: "hold", Symbol: "ETHUSDT", Timestamp: decisionTime},
+		},
+	}
+	recent := &store.DecisionRecord{
+		TraderID:    "trader-1",
+		CycleNumber: 2,
+		Timestamp:   time.Now().UTC(),
+		Decisions:   []store.DecisionAction{{Action: "open_short", Symbol: "BTCUSDT", Confidence: 70}},
+	}
+	for _, r := range []*store.DecisionRecord{old, recent} {
+		if err := st.Decision().LogDecision(r); err != nil {
+			t.Fatalf("LogDecision() error: %v", err)
+		}
+	}
+	pos := &store.TraderPosition{TraderID: "trader-1", Symbol: "BTCUSDT", Side: "LONG", Quantity: 1, EntryPrice: 100,
+		EntryTime: decisionTime.Add(time.Minute).UnixMilli(), Status: "OPEN"}
+	if err := st.Position().Create(pos); err != nil {
+		t.Fatalf("failed to create position: %v", err)
+	}
+
+	// A failed fetch keeps the record for the next pass
+	failFetches = 1
+	if scored, err := scorer.ScorePending(time.Now()); err != nil || scored != 0 {
+		t.Fatalf("ScorePending() with failed fetch = %d, %v; want 0, nil", scored, err)
+	}
+	scored, err := scorer.ScorePending(time.Now())
+	if err != nil || scored != 1 {
+		t.Fatalf("ScorePending() = %d, %v; want 1, nil", scored, err)
+	}
+	// Second run must not rescore, even with a fresh scorer resuming from the saved cursor
+	restarted, _ := NewDecisionScorer(st, "trader-1", "deepseek", store.ScoringConfig{Enabled: true, Timeframe: "1h", HorizonBars: 3})
+	restarted.fetchKlines = scorer.fetchKlines
+	if scored, _ := restarted.ScorePending(time.Now()); scored != 0 || fetches != 2 {
+		t.Fatalf("restarted ScorePending() scored %d with %d fetches, want 0 and 2", scored, fetches)
+	}
+
+	// The position was open when scored: its PnL is linked once it closes
+	if err := st.Position().ClosePosition(pos.ID, 108, "exit-1", 8, 0.1, "take_profit"); err != nil {
+		t.Fatalf("failed to close position: %v", err)
+	}
+	if _, err := restarted.ScorePending(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	scores, _ := st.DecisionScore().GetScores("trader-1", 0)
+	if len(scores) != 1 || scores[0].PositionID != pos.ID || scores[0].RealizedPnL != 8 || !scores[0].PnLSettled {
+		t.Fatalf("expected the realized PnL linked after close: %+v", scores)
+	}
+
+	report, err := scorer.CalibrationReport(time.Now())
+	if err != nil {
+		t.Fatalf("CalibrationReport() error: %v", err)
+	}
+	if report.Overall.Count != 1 || report.Overall.Correct != 1 {
+		t.Fatalf("unexpected overall stats: %+v", report.Overall)
+	}
+	if len(report.ByModel) != 1 || report.ByModel[0].Key != "deepseek" {
+		t.Errorf("unexpected model stats: %+v", report.ByModel)
+	}
+	if report.ByConfidence[0].Key != "80-89" {
+		t.Errorf("unexpected confidence bucket: %s", report.ByConfidence[0].Key)
+	}
+}
+
+func TestDecisionScorerCapsPass(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "scores.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	scorer, _ := NewDecisionScorer(st, "trader-1", "deepseek", store.ScoringConfig{Enabled: true, Timeframe: "1h", HorizonBars: 1})
+	scorer.fetchKlines = func(symbol, timeframe string, start, end time.Time) ([]market.Kline, error) {
+		return []market.Kline{{OpenTime: start.UnixMilli(), Open: 100, High: 101, Low: 99, Close: 100.5}}, nil
+	}
+	if _, err := scorer.ScorePending(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	decisionTime := time.Now().UTC().Add(-48 * time.Hour)
+	for i := 0; i < scoringMaxActionsPerPass+10; i++ {
+		rec := &store.DecisionRecord{TraderID: "trader-1", CycleNumber: i + 1, Timestamp: decisionTime.Add(time.Duration(i) * time.Minute),
+			Decisions: []store.DecisionAction{{Action: "open_long", Symbol: "BTCUSDT", Price: 100}}}
+		if err := st.Decision().LogDecision(rec); err != nil {
+			t.Fatalf("LogDecision() error: %v", err)
+		}
+	}
+	if scored, _ := scorer.ScorePending(time.Now()); scored != scoringMaxActionsPerPass {
+		t.Fatalf("first pass scored %d, want the cap of %d", scored, scoringMaxActionsPerPass)
+	}
+	if scored, _ := scorer.ScorePending(time.Now()); scored != 10 {
+		t.Fatalf("second pass scored %d, want the remaining 10", scored)
+	}
+}
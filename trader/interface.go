@@ -15,6 +15,8 @@ type (
 	LimitOrderRequest = types.LimitOrderRequest
 	LimitOrderResult  = types.LimitOrderResult
 	GridTrader        = types.GridTrader
+	FundingFeeTrader  = types.FundingFeeTrader
+	SpotTrader        = types.SpotTrader
 )
 
 // GridTraderAdapter wraps a basic Trader to provide GridTrader interface
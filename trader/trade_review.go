@@ -0,0 +1,273 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/mcp"
+	"nofx/store"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultReviewIntervalHours = 24
+	defaultReviewLookbackHours = 24
+	defaultMaxActiveLessons    = 10
+
+	// reviewPositionLimit max closed positions fetched for one review
+	reviewPositionLimit = 200
+	// reviewEntryWindow decision records this long before a position's entry are searched for its reasoning
+	reviewEntryWindow = 10 * time.Minute
+)
+
+// TradeReviewer periodically asks the trader's model for a post-mortem of closed trades
+type TradeReviewer struct {
+	store    *store.Store
+	traderID string
+	client   mcp.AIClient
+	lang     kernel.Language
+	config   store.ReviewConfig
+
+	running int32 // 1 while a review is in flight
+}
+
+// NewTradeReviewer creates a trade reviewer, filling unset config fields with defaults
+func NewTradeReviewer(st *store.Store, traderID string, client mcp.AIClient, lang kernel.Language, cfg store.ReviewConfig) *TradeReviewer {
+	if cfg.IntervalHours <= 0 {
+		cfg.IntervalHours = defaultReviewIntervalHours
+	}
+	if cfg.LookbackHours <= 0 {
+		cfg.LookbackHours = defaultReviewLookbackHours
+	}
+	if cfg.MaxActiveLessons <= 0 {
+		cfg.MaxActiveLessons = defaultMaxActiveLessons
+	}
+	return &TradeReviewer{
+		store:    st,
+		traderID: traderID,
+		client:   client,
+		lang:     lang,
+		config:   cfg,
+	}
+}
+
+// Due reports whether the review interval has elapsed since the last successful review
+// Failed reviews are kept for the history but retried on the next cycle
+func (r *TradeReviewer) Due(now time.Time) (bool, error) {
+	if atomic.LoadInt32(&r.running) == 1 {
+		return false, nil
+	}
+	last, err := r.store.Lesson().GetLatestSuccessfulReview(r.traderID)
+	if err != nil {
+		return false, err
+	}
+	if last == nil {
+		return true, nil
+	}
+	return now.Sub(last.CreatedAt) >= time.Duration(r.config.IntervalHours)*time.Hour, nil
+}
+
+// ActiveLessons gets the lesson texts to inject into the system prompt
+func (r *TradeReviewer) ActiveLessons() ([]string, error) {
+	lessons, err := r.store.Lesson().GetActiveLessons(r.traderID, r.config.MaxActiveLessons)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(lessons))
+	for i, l := range lessons {
+		texts[i] = l.Content
+	}
+	return texts, nil
+}
+
+// Run reviews the trades closed within the lookback window and stores the proposed lessons
+// A review record is saved even when there is nothing to review, so the interval is respected
+func (r *TradeReviewer) Run(now time.Time) (*store.TradeReview, error) {
+	if !atomic.CompareAndSwapInt32(&r.running, 0, 1) {
+		return nil, fmt.Errorf("review already in progress")
+	}
+	defer atomic.StoreInt32(&r.running, 0)
+
+	since := now.Add(-time.Duration(r.config.LookbackHours) * time.Hour)
+	review := &store.TradeReview{
+		TraderID:    r.traderID,
+		PeriodStart: since.UTC(),
+		PeriodEnd:   now.UTC(),
+	}
+
+	trades, err := r.collectTrades(since)
+	if err != nil {
+		return nil, err
+	}
+	review.PositionCount = len(trades)
+	for _, t := range trades {
+		review.TotalPnL += t.Position.RealizedPnL
+	}
+
+	if len(trades) == 0 {
+		review.Success = true
+		review.Summary = "No closed trades in review period"
+		return review, r.store.Lesson().SaveReview(review)
+	}
+
+	existing, err := r.ActiveLessons()
+	if err != nil {
+		return nil, err
+	}
+	systemPrompt, userPrompt := kernel.BuildTradeReviewPrompt(trades, existing, r.lang)
+
+	response, err := r.client.CallWithMessages(systemPrompt, userPrompt)
+	review.RawResponse = response
+	if err != nil {
+		review.ErrorMessage = fmt.Sprintf("AI call failed: %v", err)
+		if saveErr := r.store.Lesson().SaveReview(review); saveErr != nil {
+			logger.Warnf("⚠️ Failed to save trade review: %v", saveErr)
+		}
+		return review, fmt.Errorf("review AI call failed: %w", err)
+	}
+
+	result, err := kernel.ParseTradeReviewResponse(response)
+	if err != nil {
+		review.ErrorMessage = err.Error()
+		if saveErr := r.store.Lesson().SaveReview(review); saveErr != nil {
+			logger.Warnf("⚠️ Failed to save trade review: %v", saveErr)
+		}
+		return review, err
+	}
+
+	review.Success = true
+	review.Summary = result.Summary
+	if err := r.store.Lesson().SaveReview(review); err != nil {
+		return review, err
+	}
+
+	status := store.LessonStatusPending
+	if r.config.AutoApprove {
+		status = store.LessonStatusActive
+	}
+	groups := []struct {
+		category string
+		items    []string
+	}{
+		{store.LessonCategoryMistake, result.Mistakes},
+		{store.LessonCategoryRule, result.Rules},
+	}
+	for _, group := range groups {
+		for _, content := range group.items {
+			lesson := &store.TradingLesson{
+				TraderID: r.traderID,
+				ReviewID: review.ID,
+				Category: group.category,
+				Content:  content,
+				Status:   status,
+			}
+			if err := r.store.Lesson().CreateLesson(lesson); err != nil {
+				return review, err
+			}
+		}
+	}
+
+	return review, nil
+}
+
+// collectTrades pairs positions closed since the given time with the reasoning that opened them
+func (r *TradeReviewer) collectTrades(since time.Time) ([]kernel.ReviewTrade, error) {
+	positions, err := r.store.Position().GetClosedPositions(r.traderID, reviewPositionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	sinceMs := since.UTC().UnixMilli()
+	var closed []*store.TraderPosition
+	earliestEntry := int64(0)
+	for _, pos := range positions {
+		if pos.ExitTime < sinceMs {
+			continue
+		}
+		closed = append(closed, pos)
+		if earliestEntry == 0 || pos.EntryTime < earliestEntry {
+			earliestEntry = pos.EntryTime
+		}
+	}
+	if len(closed) == 0 {
+		return nil, nil
+	}
+
+	records, err := r.store.Decision().GetRecordsInRange(r.traderID,
+		time.UnixMilli(earliestEntry).Add(-reviewEntryWindow).UTC(), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first for the prompt
+	trades := make([]kernel.ReviewTrade, 0, len(closed))
+	for i := len(closed) - 1; i >= 0; i-- {
+		pos := closed[i]
+		trade := kernel.ReviewTrade{Position: pos}
+		if action := findEntryDecision(records, pos); action != nil {
+			trade.EntryReasoning = action.Reasoning
+			trade.EntryConfidence = action.Confidence
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// findEntryDecision finds the latest successful open decision for the position's symbol/side around its entry time
+func findEntryDecision(records []*store.DecisionRecord, pos *store.TraderPosition) *store.DecisionAction {
+	action := "open_long"
+	if pos.Side == "SHORT" {
+		action = "open_short"
+	}
+	from := time.UnixMilli(pos.EntryTime).Add(-reviewEntryWindow)
+	to := time.UnixMilli(pos.EntryTime).Add(reviewEntryWindow)
+
+	var found *store.DecisionAction
+	for _, record := range records {
+		if record.Timestamp.Before(from) || record.Timestamp.After(to) {
+			continue
+		}
+		for i := range record.Decisions {
+			d := &record.Decisions[i]
+			if d.Symbol == pos.Symbol && d.Action == action && d.Success {
+				found = d
+			}
+		}
+	}
+	return found
+}
+
+// updateTradeLessons injects active lessons into the engine and starts a review when one is due
+func (at *AutoTrader) updateTradeLessons() {
+	if at.tradeReviewer == nil {
+		return
+	}
+
+	lessons, err := at.tradeReviewer.ActiveLessons()
+	if err != nil {
+		logger.Infof("⚠️ [%s] Failed to load trade lessons: %v", at.name, err)
+	} else {
+		at.runningStrategy().Engine.SetActiveLessons(lessons)
+	}
+
+	due, err := at.tradeReviewer.Due(time.Now())
+	if err != nil {
+		logger.Infof("⚠️ [%s] Failed to check trade review schedule: %v", at.name, err)
+		return
+	}
+	if !due {
+		return
+	}
+
+	// Review runs in background so the trading cycle is not delayed by the extra AI call
+	go func() {
+		review, err := at.tradeReviewer.Run(time.Now())
+		if err != nil {
+			logger.Warnf("⚠️ [%s] Trade review failed: %v", at.name, err)
+			return
+		}
+		logger.Infof("📝 [%s] Trade review completed: %d positions, %+.2f USDT | %s",
+			at.name, review.PositionCount, review.TotalPnL, review.Summary)
+	}()
+}
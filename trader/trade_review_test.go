@@ -0,0 +1,145 @@
+package trader
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// stubAIClient returns a fixed response and records the prompts it received
+type stubAIClient struct {
+	response   string
+	err        error
+	userPrompt string
+}
+
+func (c *stubAIClient) SetAPIKey(apiKey string, customURL string, customModel string) {}
+func (c *stubAIClient) SetTimeout(timeout time.Duration)                              {}
+func (c *stubAIClient) CallWithRequest(req *mcp.Request) (string, error)              { return c.response, nil }
+func (c *stubAIClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	c.userPrompt = userPrompt
+	return c.response, c.err
+}
+
+func TestTradeReviewerRun(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	entry := time.Now().UTC().Add(-3 * time.Hour)
+	record := &store.DecisionRecord{
+		TraderID:    "trader-1",
+		CycleNumber: 1,
+		Timestamp:   entry,
+		Decisions: []store.DecisionAction{
+			{Action: "open_long", Symbol: "SOLUSDT", Confidence: 85, Reasoning: "breakout above range high", Success: true, Timestamp: entry},
+		},
+	}
+	if err := st.Decision().LogDecision(record); err != nil {
+		t.Fatalf("LogDecision() error: %v", err)
+	}
+	pos := &store.TraderPosition{
+		TraderID:   "trader-1",
+		Symbol:     "SOLUSDT",
+		Side:       "LONG",
+		Quantity:   10,
+		EntryPrice: 150,
+		EntryTime:  entry.Add(time.Minute).UnixMilli(),
+		Leverage:   3,
+		Status:     "OPEN",
+	}
+	if err := st.Position().Create(pos); err != nil {
+		t.Fatalf("failed to create position: %v", err)
+	}
+	if err := st.Position().ClosePosition(pos.ID, 145, "exit-1", -50, 1, "stop_loss"); err != nil {
+		t.Fatalf("failed to close position: %v", err)
+	}
+
+	client := &stubAIClient{response: "Review:\n```json\n" +
+		`{"summary": "Chased a breakout", "mistakes": ["Entered breakout without volume", ""], "rules": ["Require volume confirmation on breakouts", "Require volume confirmation on breakouts"]}` +
+		"\n```"}
+	reviewer := NewTradeReviewer(st, "trader-1", client, kernel.LangEnglish, store.ReviewConfig{Enabled: true})
+
+	if due, err := reviewer.Due(time.Now()); err != nil || !due {
+		t.Fatalf("Due() = %v, %v; want true before first review", due, err)
+	}
+	// A failed AI call is recorded but does not wait out the interval
+	client.err = errors.New("model unavailable")
+	if _, err := reviewer.Run(time.Now()); err == nil {
+		t.Fatal("Run() should fail when the AI call fails")
+	}
+	if due, err := reviewer.Due(time.Now()); err != nil || !due {
+		t.Fatalf("Due() = %v, %v; want true after a failed review", due, err)
+	}
+	client.err = nil
+	review, err := reviewer.Run(time.Now())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if review.PositionCount != 1 || review.Summary != "Chased a breakout" {
+		t.Fatalf("unexpected review: %+v", review)
+	}
+	if !strings.Contains(client.userPrompt, "breakout above range high") {
+		t.Errorf("review prompt missing entry reasoning:\n%s", client.userPrompt)
+	}
+	if due, _ := reviewer.Due(time.Now()); due {
+		t.Error("Due() should be false right after a review")
+	}
+
+	pending, err := st.Lesson().ListLessons("trader-1", store.LessonStatusPending)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("pending lessons = %d, %v; want 2, nil", len(pending), err)
+	}
+	if active, _ := reviewer.ActiveLessons(); len(active) != 0 {
+		t.Fatalf("pending lessons must not be active: %v", active)
+	}
+
+	// Approve, then edit: the edit becomes a new active version
+	var rule *store.TradingLesson
+	for _, l := range pending {
+		if l.Category == store.LessonCategoryRule {
+			rule = l
+		}
+	}
+	if err := st.Lesson().ApproveLesson("trader-1", rule.ID); err != nil {
+		t.Fatalf("ApproveLesson() error: %v", err)
+	}
+	edited, err := st.Lesson().UpdateLesson("trader-1", rule.ID, "Require 2x average volume on breakouts", "", "user-1")
+	if err != nil {
+		t.Fatalf("UpdateLesson() error: %v", err)
+	}
+	if edited.Version != 2 || edited.Status != store.LessonStatusActive || edited.LessonKey != rule.LessonKey {
+		t.Fatalf("unexpected edited lesson: %+v", edited)
+	}
+	history, _ := st.Lesson().GetLessonHistory("trader-1", rule.LessonKey)
+	if len(history) != 2 || history[0].Status != store.LessonStatusSuperseded {
+		t.Fatalf("unexpected lesson history: %+v", history)
+	}
+
+	active, err := reviewer.ActiveLessons()
+	if err != nil || len(active) != 1 || active[0] != "Require 2x average volume on breakouts" {
+		t.Fatalf("ActiveLessons() = %v, %v", active, err)
+	}
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	engine := kernel.NewStrategyEngine(&cfg)
+	engine.SetActiveLessons(active)
+	if prompt := engine.BuildSystemPrompt(1000, "balanced"); !strings.Contains(prompt, "1. Require 2x average volume on breakouts") {
+		t.Error("system prompt missing active lesson")
+	}
+
+	if err := st.Lesson().DeleteLesson("trader-1", edited.ID); err != nil {
+		t.Fatalf("DeleteLesson() error: %v", err)
+	}
+	if history, _ := st.Lesson().GetLessonHistory("trader-1", rule.LessonKey); len(history) != 0 {
+		t.Errorf("DeleteLesson() should remove all versions, %d left", len(history))
+	}
+}
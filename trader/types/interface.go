@@ -228,3 +228,35 @@ func (a *GridTraderAdapter) GetOrderBook(symbol string, depth int) (bids, asks [
 	// Not supported, return empty
 	return nil, nil, nil
 }
+
+// FundingFee one funding settlement of a perpetual position
+type FundingFee struct {
+	Symbol string
+	Amount float64 // Received (+) or paid (-) funding in USDT
+	Time   time.Time
+}
+
+// FundingFeeTrader extends Trader with the funding settlement history
+// Exchanges that report settled funding should implement this interface
+type FundingFeeTrader interface {
+	Trader
+
+	// GetFundingFees returns the funding settlements of a symbol since startTime
+	GetFundingFees(symbol string, startTime time.Time) ([]FundingFee, error)
+}
+
+// SpotTrader extends Trader with spot market orders on the same account
+// Used as the stable hedge of perpetual positions (e.g. funding carry)
+type SpotTrader interface {
+	Trader
+
+	// SpotBuy buys quantity of the base asset of symbol at market
+	// Returns: orderId, avgPrice, executedQty, commission (in USDT when known)
+	SpotBuy(symbol string, quantity float64) (map[string]interface{}, error)
+
+	// SpotSell sells quantity of the base asset of symbol at market
+	SpotSell(symbol string, quantity float64) (map[string]interface{}, error)
+
+	// GetSpotBalance returns the free spot balance of the base asset of symbol
+	GetSpotBalance(symbol string) (float64, error)
+}